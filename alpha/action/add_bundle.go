@@ -0,0 +1,187 @@
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/image"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+// AddBundle renders a bundle and adds it to an existing file-based catalog
+// directory. The bundle is added to the file containing its olm.package blob,
+// and an entry for it is added to each of the requested channels.
+type AddBundle struct {
+	CatalogDir string
+	BundleRef  string
+
+	// Channels are the channels to add the bundle to. If empty, the bundle
+	// is added to the package's default channel.
+	Channels []string
+
+	// Replaces, Skips, and SkipRange override the upgrade edges of the new
+	// channel entries. Any that are unset are read from the bundle's CSV. If
+	// neither the flags nor the CSV define any upgrade edges, the new entry
+	// replaces the current head of each channel.
+	Replaces  string
+	Skips     []string
+	SkipRange string
+
+	Registry image.Registry
+}
+
+func (a AddBundle) Run(ctx context.Context) error {
+	r := Render{
+		Refs:           []string{a.BundleRef},
+		Registry:       a.Registry,
+		AllowedRefMask: RefBundleImage | RefBundleDir,
+	}
+	rendered, err := r.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("render bundle %q: %v", a.BundleRef, err)
+	}
+	if len(rendered.Bundles) != 1 {
+		return fmt.Errorf("bundle reference %q resulted in %d bundles, expected 1", a.BundleRef, len(rendered.Bundles))
+	}
+	bundle := rendered.Bundles[0]
+
+	files, err := loadCatalogFiles(a.CatalogDir)
+	if err != nil {
+		return err
+	}
+
+	pkgFile := files.packageFile(bundle.Package)
+	if pkgFile == "" {
+		if len(a.Channels) == 0 {
+			return fmt.Errorf("package %q does not exist in catalog %q: at least one channel must be specified to create it", bundle.Package, a.CatalogDir)
+		}
+		pkgFile = filepath.Join(bundle.Package, "catalog.json")
+		// The file may already exist, e.g. with channels of the package, in
+		// which case the package is added to its content.
+		if _, ok := files[pkgFile]; !ok {
+			files[pkgFile] = &declcfg.DeclarativeConfig{}
+		}
+		files[pkgFile].Packages = append(files[pkgFile].Packages, declcfg.Package{
+			Schema:         declcfg.SchemaPackage,
+			Name:           bundle.Package,
+			DefaultChannel: a.Channels[0],
+		})
+	}
+	if files.hasBundle(bundle.Package, bundle.Name) {
+		return fmt.Errorf("bundle %q already exists in package %q", bundle.Name, bundle.Package)
+	}
+
+	entry, err := a.channelEntry(bundle)
+	if err != nil {
+		return err
+	}
+
+	channels := a.Channels
+	if len(channels) == 0 {
+		channels = []string{files[pkgFile].Packages[0].DefaultChannel}
+	}
+
+	modified := map[string]struct{}{pkgFile: {}}
+	files[pkgFile].Bundles = append(files[pkgFile].Bundles, bundle)
+	for _, chName := range channels {
+		chFile, ch := files.channel(bundle.Package, chName)
+		if ch == nil {
+			chFile = pkgFile
+			files[pkgFile].Channels = append(files[pkgFile].Channels, declcfg.Channel{
+				Schema:  declcfg.SchemaChannel,
+				Package: bundle.Package,
+				Name:    chName,
+			})
+			ch = &files[pkgFile].Channels[len(files[pkgFile].Channels)-1]
+		}
+		chEntry := entry
+		if !hasUpgradeEdges(chEntry) {
+			chEntry.Replaces, err = channelHead(*ch)
+			if err != nil {
+				return fmt.Errorf("compute replaces for channel %q: %v", chName, err)
+			}
+		}
+		ch.Entries = append(ch.Entries, chEntry)
+		modified[chFile] = struct{}{}
+	}
+
+	// Validate the entire catalog with the new bundle before writing anything.
+	if _, err := declcfg.ConvertToModel(files.merged()); err != nil {
+		return fmt.Errorf("catalog is invalid after adding bundle %q: %v", bundle.Name, err)
+	}
+
+	for path := range modified {
+		if err := writeCatalogFile(filepath.Join(a.CatalogDir, path), *files[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a AddBundle) channelEntry(b declcfg.Bundle) (declcfg.ChannelEntry, error) {
+	entry := declcfg.ChannelEntry{Name: b.Name}
+	if b.CsvJSON != "" {
+		var csv registry.ClusterServiceVersion
+		if err := json.Unmarshal([]byte(b.CsvJSON), &csv); err != nil {
+			return entry, fmt.Errorf("parse csv for bundle %q: %v", b.Name, err)
+		}
+		replaces, err := csv.GetReplaces()
+		if err != nil {
+			return entry, fmt.Errorf("get replaces for bundle %q: %v", b.Name, err)
+		}
+		skips, err := csv.GetSkips()
+		if err != nil {
+			return entry, fmt.Errorf("get skips for bundle %q: %v", b.Name, err)
+		}
+		entry.Replaces = replaces
+		entry.Skips = skips
+		entry.SkipRange = csv.GetSkipRange()
+	}
+	if a.Replaces != "" {
+		entry.Replaces = a.Replaces
+	}
+	if len(a.Skips) > 0 {
+		entry.Skips = a.Skips
+	}
+	if a.SkipRange != "" {
+		entry.SkipRange = a.SkipRange
+	}
+	return entry, nil
+}
+
+func hasUpgradeEdges(e declcfg.ChannelEntry) bool {
+	return e.Replaces != "" || len(e.Skips) > 0 || e.SkipRange != ""
+}
+
+// channelHead returns the name of the entry in the channel that is not
+// replaced or skipped by any other entry, or an empty string if the channel
+// has no entries.
+func channelHead(ch declcfg.Channel) (string, error) {
+	if len(ch.Entries) == 0 {
+		return "", nil
+	}
+	incoming := map[string]struct{}{}
+	for _, e := range ch.Entries {
+		if e.Replaces != "" {
+			incoming[e.Replaces] = struct{}{}
+		}
+		for _, s := range e.Skips {
+			incoming[s] = struct{}{}
+		}
+	}
+	var heads []string
+	for _, e := range ch.Entries {
+		if _, ok := incoming[e.Name]; !ok {
+			heads = append(heads, e.Name)
+		}
+	}
+	if len(heads) != 1 {
+		sort.Strings(heads)
+		return "", fmt.Errorf("expected exactly one channel head, found %d %v", len(heads), heads)
+	}
+	return heads[0], nil
+}
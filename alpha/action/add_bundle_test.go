@@ -0,0 +1,213 @@
+package action_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	dircopy "github.com/otiai10/copy"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+func TestAddBundle(t *testing.T) {
+	type spec struct {
+		name            string
+		add             action.AddBundle
+		existingFiles   map[string]string
+		expectedFile    string
+		expectedEntries map[string][]declcfg.ChannelEntry
+		expectedBundles []string
+		expectErr       string
+	}
+
+	// a copy of foo.v0.2.0 with no upgrade edges in its CSV
+	noEdgesBundle := filepath.Join(t.TempDir(), "foo-bundle-v0.2.0-no-edges")
+	require.NoError(t, dircopy.Copy("testdata/foo-bundle-v0.2.0", noEdgesBundle))
+	csvPath := filepath.Join(noEdgesBundle, "manifests", "foo.v0.2.0.csv.yaml")
+	csv, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	noEdgesCSV := strings.NewReplacer(
+		"    olm.skipRange: <0.2.0\n", "",
+		"  replaces: foo.v0.1.0\n", "",
+		"  skips:\n    - foo.v0.1.1\n    - foo.v0.1.2\n", "",
+	).Replace(string(csv))
+	require.NoError(t, os.WriteFile(csvPath, []byte(noEdgesCSV), 0600))
+
+	specs := []spec{
+		{
+			name: "Success/EdgesFromCSV",
+			add: action.AddBundle{
+				BundleRef: "testdata/foo-bundle-v0.2.0",
+				Channels:  []string{"beta"},
+			},
+			expectedFile: "foo/catalog.yaml",
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"beta": {
+					{Name: "foo.v0.1.0"},
+					{Name: "foo.v0.2.0", Replaces: "foo.v0.1.0", Skips: []string{"foo.v0.1.1", "foo.v0.1.2"}, SkipRange: "<0.2.0"},
+				},
+			},
+			expectedBundles: []string{"foo.v0.1.0", "foo.v0.2.0"},
+		},
+		{
+			name: "Success/ProvidedEdges",
+			add: action.AddBundle{
+				BundleRef: "testdata/foo-bundle-v0.2.0",
+				Channels:  []string{"beta"},
+				Replaces:  "foo.v0.1.0",
+				Skips:     []string{"foo.v0.1.0-rc1"},
+				SkipRange: "<0.1.5",
+			},
+			expectedFile: "foo/catalog.yaml",
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"beta": {
+					{Name: "foo.v0.1.0"},
+					{Name: "foo.v0.2.0", Replaces: "foo.v0.1.0", Skips: []string{"foo.v0.1.0-rc1"}, SkipRange: "<0.1.5"},
+				},
+			},
+			expectedBundles: []string{"foo.v0.1.0", "foo.v0.2.0"},
+		},
+		{
+			name: "Success/ComputedReplacesInDefaultChannel",
+			add: action.AddBundle{
+				BundleRef: noEdgesBundle,
+			},
+			expectedFile: "foo/catalog.yaml",
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"beta": {
+					{Name: "foo.v0.1.0"},
+					{Name: "foo.v0.2.0", Replaces: "foo.v0.1.0"},
+				},
+			},
+			expectedBundles: []string{"foo.v0.1.0", "foo.v0.2.0"},
+		},
+		{
+			name: "Success/NewChannel",
+			add: action.AddBundle{
+				BundleRef: noEdgesBundle,
+				Channels:  []string{"beta", "stable"},
+			},
+			expectedFile: "foo/catalog.yaml",
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"beta": {
+					{Name: "foo.v0.1.0"},
+					{Name: "foo.v0.2.0", Replaces: "foo.v0.1.0"},
+				},
+				"stable": {
+					{Name: "foo.v0.2.0"},
+				},
+			},
+			expectedBundles: []string{"foo.v0.1.0", "foo.v0.2.0"},
+		},
+		{
+			name: "Success/NewPackage",
+			add: action.AddBundle{
+				BundleRef: "testdata/bar-bundle-v0.1.0",
+				Channels:  []string{"alpha"},
+			},
+			expectedFile: "bar/catalog.json",
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"alpha": {
+					{Name: "bar.v0.1.0"},
+				},
+			},
+			expectedBundles: []string{"bar.v0.1.0"},
+		},
+		{
+			name: "Success/NewPackageInExistingFile",
+			add: action.AddBundle{
+				BundleRef: "testdata/bar-bundle-v0.1.0",
+				Channels:  []string{"alpha"},
+			},
+			existingFiles: map[string]string{
+				"bar/catalog.json": `{"schema": "olm.channel", "package": "bar", "name": "stable", "entries": [{"name": "bar.v0.1.0"}]}`,
+			},
+			expectedFile: "bar/catalog.json",
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"alpha": {
+					{Name: "bar.v0.1.0"},
+				},
+				"stable": {
+					{Name: "bar.v0.1.0"},
+				},
+			},
+			expectedBundles: []string{"bar.v0.1.0"},
+		},
+		{
+			name: "Error/NewPackageWithoutChannels",
+			add: action.AddBundle{
+				BundleRef: "testdata/bar-bundle-v0.1.0",
+			},
+			expectErr: `package "bar" does not exist in catalog`,
+		},
+		{
+			name: "Error/BundleExists",
+			add: action.AddBundle{
+				BundleRef: "testdata/foo-bundle-v0.1.0",
+				Channels:  []string{"beta"},
+			},
+			expectErr: `bundle "foo.v0.1.0" already exists in package "foo"`,
+		},
+	}
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			s.add.CatalogDir = newAddBundleCatalog(t)
+			for path, content := range s.existingFiles {
+				require.NoError(t, os.MkdirAll(filepath.Join(s.add.CatalogDir, filepath.Dir(path)), 0777))
+				require.NoError(t, os.WriteFile(filepath.Join(s.add.CatalogDir, path), []byte(content), 0600))
+			}
+			err := s.add.Run(context.Background())
+			if s.expectErr != "" {
+				require.ErrorContains(t, err, s.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			cfg, err := declcfg.LoadFile(os.DirFS(s.add.CatalogDir), s.expectedFile)
+			require.NoError(t, err)
+			require.Len(t, cfg.Packages, 1)
+
+			actualEntries := map[string][]declcfg.ChannelEntry{}
+			for _, ch := range cfg.Channels {
+				actualEntries[ch.Name] = ch.Entries
+			}
+			require.Equal(t, s.expectedEntries, actualEntries)
+
+			var actualBundles []string
+			for _, b := range cfg.Bundles {
+				actualBundles = append(actualBundles, b.Name)
+			}
+			require.ElementsMatch(t, s.expectedBundles, actualBundles)
+		})
+	}
+}
+
+func newAddBundleCatalog(t *testing.T) string {
+	t.Helper()
+	r := action.Render{
+		Refs:           []string{"testdata/foo-bundle-v0.1.0"},
+		AllowedRefMask: action.RefBundleDir,
+	}
+	cfg, err := r.Run(context.Background())
+	require.NoError(t, err)
+	cfg.Packages = []declcfg.Package{{Schema: declcfg.SchemaPackage, Name: "foo", DefaultChannel: "beta"}}
+	cfg.Channels = []declcfg.Channel{{
+		Schema:  declcfg.SchemaChannel,
+		Package: "foo",
+		Name:    "beta",
+		Entries: []declcfg.ChannelEntry{{Name: "foo.v0.1.0"}},
+	}}
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "foo"), 0777))
+	f, err := os.Create(filepath.Join(dir, "foo", "catalog.yaml"))
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, declcfg.WriteYAML(*cfg, f))
+	return dir
+}
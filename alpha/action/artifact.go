@@ -0,0 +1,248 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+	"oras.land/oras-go/v2/registry/remote/retry"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/image"
+)
+
+const (
+	// ArtifactType is the artifact type of the OCI artifacts that file-based
+	// catalogs are pushed as.
+	ArtifactType = "application/vnd.operatorframework.fbc.v1"
+
+	// ArtifactRefPrefix is the prefix of the references that Render reads
+	// as file-based catalog artifacts rather than as images.
+	ArtifactRefPrefix = "oci-artifact://"
+
+	artifactMediaTypePrefix = "application/vnd.operatorframework.fbc."
+	artifactMediaTypeSuffix = ".v1+json"
+	// artifactMetaLayer is the layer of the blobs whose schema has no layer
+	// of its own.
+	artifactMetaLayer = "meta"
+)
+
+// artifactSchemas are the schemas that have a layer of their own in a catalog
+// artifact, in the order of the layers.
+var artifactSchemas = []string{
+	declcfg.SchemaPackage,
+	declcfg.SchemaChannel,
+	declcfg.SchemaBundle,
+	declcfg.SchemaDeprecation,
+	declcfg.SchemaIcon,
+	declcfg.SchemaCatalog,
+}
+
+// ArtifactLayerMediaType returns the media type of the layer of a catalog
+// artifact that holds the blobs of schema.
+func ArtifactLayerMediaType(schema string) string {
+	for _, s := range artifactSchemas {
+		if s == schema {
+			return artifactMediaTypePrefix + schema + artifactMediaTypeSuffix
+		}
+	}
+	return artifactMediaTypePrefix + artifactMetaLayer + artifactMediaTypeSuffix
+}
+
+// PushArtifact pushes a rendered declarative config to a registry as an OCI
+// artifact, so that catalogs can be distributed without building images.
+//
+// The artifact has an empty config and one layer per schema, with media type
+// application/vnd.operatorframework.fbc.<schema>.v1+json, that holds the JSON
+// stream of the blobs of that schema. Blobs of schemas other than the olm.*
+// schemas are stored together in the application/vnd.operatorframework.fbc.meta.v1+json
+// layer. Artifacts are rendered by prefixing their reference with
+// ArtifactRefPrefix.
+type PushArtifact struct {
+	// Refs are the references rendered into the declarative config that is
+	// pushed.
+	Refs []string
+	// Reference is the reference of the artifact, which must have a tag or a
+	// digest. Credentials are read from the docker config file.
+	Reference     string
+	Registry      image.Registry
+	SkipTLSVerify bool
+	PlainHTTP     bool
+}
+
+// Run pushes the artifact and returns its manifest descriptor.
+func (p PushArtifact) Run(ctx context.Context) (*ocispec.Descriptor, error) {
+	repo, err := newArtifactRepository(p.Reference, p.SkipTLSVerify, p.PlainHTTP)
+	if err != nil {
+		return nil, err
+	}
+	if repo.Reference.Reference == "" {
+		return nil, fmt.Errorf("artifact reference %q must have a tag or a digest", p.Reference)
+	}
+
+	r := Render{
+		Refs:          p.Refs,
+		Registry:      p.Registry,
+		SkipTLSVerify: p.SkipTLSVerify,
+		PlainHTTP:     p.PlainHTTP,
+	}
+	cfg, err := r.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+	layers, err := artifactLayers(*cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var layerDescs []ocispec.Descriptor
+	for _, l := range layers {
+		desc := content.NewDescriptorFromBytes(l.mediaType, l.data)
+		if err := pushIfNotExist(ctx, repo, desc, l.data); err != nil {
+			return nil, fmt.Errorf("push layer %s: %v", l.mediaType, err)
+		}
+		layerDescs = append(layerDescs, desc)
+	}
+	desc, err := oras.PackManifest(ctx, repo, oras.PackManifestVersion1_1, ArtifactType, oras.PackManifestOptions{Layers: layerDescs})
+	if err != nil {
+		return nil, fmt.Errorf("push manifest: %v", err)
+	}
+	if err := repo.Tag(ctx, desc, repo.Reference.Reference); err != nil {
+		return nil, fmt.Errorf("tag artifact %q: %v", p.Reference, err)
+	}
+	return &desc, nil
+}
+
+type artifactLayer struct {
+	mediaType string
+	data      []byte
+}
+
+// artifactLayers splits the blobs of cfg into the layers of its artifact.
+// Layers without blobs are omitted.
+func artifactLayers(cfg declcfg.DeclarativeConfig) ([]artifactLayer, error) {
+	var buf bytes.Buffer
+	if err := declcfg.WriteJSON(cfg, &buf); err != nil {
+		return nil, err
+	}
+	data := map[string]*bytes.Buffer{}
+	if err := declcfg.WalkMetasReader(&buf, func(meta *declcfg.Meta, err error) error {
+		if err != nil {
+			return err
+		}
+		mediaType := ArtifactLayerMediaType(meta.Schema)
+		if data[mediaType] == nil {
+			data[mediaType] = &bytes.Buffer{}
+		}
+		if err := json.Compact(data[mediaType], meta.Blob); err != nil {
+			return err
+		}
+		return data[mediaType].WriteByte('\n')
+	}); err != nil {
+		return nil, err
+	}
+
+	var layers []artifactLayer
+	mediaTypes := make([]string, 0, len(artifactSchemas)+1)
+	for _, schema := range artifactSchemas {
+		mediaTypes = append(mediaTypes, ArtifactLayerMediaType(schema))
+	}
+	mediaTypes = append(mediaTypes, ArtifactLayerMediaType(artifactMetaLayer))
+	for _, mediaType := range mediaTypes {
+		if d, ok := data[mediaType]; ok {
+			layers = append(layers, artifactLayer{mediaType: mediaType, data: d.Bytes()})
+		}
+	}
+	return layers, nil
+}
+
+// artifactToDeclcfg pulls the catalog artifact at ref and loads its blobs.
+func (r Render) artifactToDeclcfg(ctx context.Context, ref string) (*declcfg.DeclarativeConfig, error) {
+	repo, err := newArtifactRepository(ref, r.SkipTLSVerify, r.PlainHTTP)
+	if err != nil {
+		return nil, err
+	}
+	_, manifestData, err := oras.FetchBytes(ctx, repo, repo.Reference.String(), oras.DefaultFetchBytesOptions)
+	if err != nil {
+		return nil, fmt.Errorf("fetch artifact %q: %v", ref, err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("parse manifest of artifact %q: %v", ref, err)
+	}
+	if manifest.MediaType != ocispec.MediaTypeImageManifest || manifest.ArtifactType != ArtifactType {
+		return nil, fmt.Errorf("%q is not a file-based catalog artifact: artifact type %q, expected %q", ref, manifest.ArtifactType, ArtifactType)
+	}
+
+	var metas []*declcfg.Meta
+	for _, layer := range manifest.Layers {
+		if !strings.HasPrefix(layer.MediaType, artifactMediaTypePrefix) || !strings.HasSuffix(layer.MediaType, artifactMediaTypeSuffix) {
+			return nil, fmt.Errorf("artifact %q has unsupported layer media type %q", ref, layer.MediaType)
+		}
+		data, err := content.FetchAll(ctx, repo, layer)
+		if err != nil {
+			return nil, fmt.Errorf("fetch layer %s of artifact %q: %v", layer.Digest, ref, err)
+		}
+		if err := declcfg.WalkMetasReader(bytes.NewReader(data), func(meta *declcfg.Meta, err error) error {
+			if err != nil {
+				return err
+			}
+			metas = append(metas, meta)
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("parse layer %s of artifact %q: %v", layer.Digest, ref, err)
+		}
+	}
+	return declcfg.LoadSlice(metas)
+}
+
+func newArtifactRepository(ref string, skipTLSVerify, plainHTTP bool) (*remote.Repository, error) {
+	repo, err := remote.NewRepository(strings.TrimPrefix(ref, ArtifactRefPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("parse artifact reference %q: %v", ref, err)
+	}
+	credStore, err := credentials.NewStoreFromDocker(credentials.StoreOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("load registry credentials: %v", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if skipTLSVerify {
+		// nolint:gosec
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	repo.Client = &auth.Client{
+		Client:     &http.Client{Transport: retry.NewTransport(transport)},
+		Cache:      auth.NewCache(),
+		Credential: credentials.Credential(credStore),
+	}
+	repo.PlainHTTP = plainHTTP
+	return repo, nil
+}
+
+func pushIfNotExist(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor, data []byte) error {
+	exists, err := repo.Exists(ctx, desc)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	err = repo.Push(ctx, desc, bytes.NewReader(data))
+	if errors.Is(err, errdef.ErrAlreadyExists) {
+		return nil
+	}
+	return err
+}
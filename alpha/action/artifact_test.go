@@ -0,0 +1,111 @@
+package action_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/internal/testutil/image"
+)
+
+const artifactCatalog = `---
+schema: olm.package
+name: foo
+defaultChannel: stable
+---
+schema: olm.channel
+package: foo
+name: stable
+entries:
+  - name: foo.v0.1.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.1.0
+image: test.registry/foo-operator/foo-bundle:v0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.1.0
+---
+schema: custom.schema
+package: foo
+name: custom
+data: value
+`
+
+func TestPushArtifact(t *testing.T) {
+	ctx := context.Background()
+	server := image.RunDockerRegistry(ctx, "")
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	catalogDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(catalogDir, "catalog.yaml"), []byte(artifactCatalog), 0600))
+	expected, err := action.Render{Refs: []string{catalogDir}}.Run(ctx)
+	require.NoError(t, err)
+
+	t.Run("Success/PushAndRender", func(t *testing.T) {
+		ref := host + "/catalogs/foo:v1"
+		desc, err := action.PushArtifact{Refs: []string{catalogDir}, Reference: ref, SkipTLSVerify: true}.Run(ctx)
+		require.NoError(t, err)
+		require.Equal(t, ocispec.MediaTypeImageManifest, desc.MediaType)
+		require.Equal(t, action.ArtifactType, desc.ArtifactType)
+
+		manifest := fetchManifest(t, server, "/v2/catalogs/foo/manifests/v1")
+		require.Equal(t, ocispec.DescriptorEmptyJSON.MediaType, manifest.Config.MediaType)
+		var mediaTypes []string
+		for _, l := range manifest.Layers {
+			mediaTypes = append(mediaTypes, l.MediaType)
+		}
+		require.Equal(t, []string{
+			"application/vnd.operatorframework.fbc.olm.package.v1+json",
+			"application/vnd.operatorframework.fbc.olm.channel.v1+json",
+			"application/vnd.operatorframework.fbc.olm.bundle.v1+json",
+			"application/vnd.operatorframework.fbc.meta.v1+json",
+		}, mediaTypes)
+
+		actual, err := action.Render{Refs: []string{action.ArtifactRefPrefix + ref}, SkipTLSVerify: true}.Run(ctx)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("Error/NoTag", func(t *testing.T) {
+		_, err := action.PushArtifact{Refs: []string{catalogDir}, Reference: host + "/catalogs/foo", SkipTLSVerify: true}.Run(ctx)
+		require.EqualError(t, err, `artifact reference "`+host+`/catalogs/foo" must have a tag or a digest`)
+	})
+
+	t.Run("Error/RenderNotAllowed", func(t *testing.T) {
+		_, err := action.Render{Refs: []string{action.ArtifactRefPrefix + host + "/catalogs/foo:v1"}, AllowedRefMask: action.RefDCImage}.Run(ctx)
+		require.ErrorIs(t, err, action.ErrNotAllowed)
+	})
+
+	t.Run("Error/RenderNotFound", func(t *testing.T) {
+		_, err := action.Render{Refs: []string{action.ArtifactRefPrefix + host + "/catalogs/foo:unknown"}, SkipTLSVerify: true}.Run(ctx)
+		require.ErrorContains(t, err, "fetch artifact")
+	})
+}
+
+func fetchManifest(t *testing.T, server *httptest.Server, path string) ocispec.Manifest {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", ocispec.MediaTypeImageManifest)
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var manifest ocispec.Manifest
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&manifest))
+	return manifest
+}
@@ -0,0 +1,281 @@
+package action
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/containers/image/v5/copy"
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/docker/reference"
+	ociarchive "github.com/containers/image/v5/oci/archive"
+	"github.com/containers/image/v5/oci/layout"
+	"github.com/containers/image/v5/signature"
+	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/oci"
+
+	"github.com/operator-framework/operator-registry/pkg/cache"
+	"github.com/operator-framework/operator-registry/pkg/containertools"
+)
+
+const (
+	buildCatalogConfigsDir = "configs"
+	buildCatalogCacheDir   = "tmp/cache"
+	buildCatalogLayoutTag  = "catalog"
+)
+
+// BuildCatalog builds a binary-less image of a file-based catalog without a
+// container build tool. The image has a single layer with the catalog at
+// /configs and its pre-built serve cache at /tmp/cache, like the images built
+// from `opm generate dockerfile` with a scratch base image.
+//
+// The image is written to an OCI archive, pushed to a registry, or both.
+// OLMv0 CatalogSources that use binary-less images must set:
+//
+//	spec:
+//	  grpcPodConfig:
+//	    extractContent:
+//	      catalogDir: /configs
+//	      cacheDir: /tmp/cache
+type BuildCatalog struct {
+	CatalogDir string
+	// OutputFile is the path of the OCI archive the image is written to.
+	OutputFile string
+	// Push is the reference of the image to push to a registry. Credentials
+	// are read from the default locations used by podman and docker.
+	Push string
+	// Architecture is the architecture of the image. It defaults to amd64.
+	Architecture  string
+	ExtraLabels   map[string]string
+	SkipTLSVerify bool
+}
+
+func (b BuildCatalog) Run(ctx context.Context) error {
+	if b.CatalogDir == "" {
+		return errors.New("catalog directory is unset")
+	}
+	if b.OutputFile == "" && b.Push == "" {
+		return errors.New("at least one of output file and push reference must be set")
+	}
+	if s, err := os.Stat(b.CatalogDir); err != nil {
+		return err
+	} else if !s.IsDir() {
+		return fmt.Errorf("catalog %q is not a directory", b.CatalogDir)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "opm-build-catalog-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cacheDir := filepath.Join(tmpDir, "cache")
+	c, err := cache.New(cacheDir)
+	if err != nil {
+		return fmt.Errorf("create cache: %v", err)
+	}
+	if err := c.Build(ctx, os.DirFS(b.CatalogDir)); err != nil {
+		return fmt.Errorf("build cache: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		return fmt.Errorf("close cache: %v", err)
+	}
+
+	layoutDir := filepath.Join(tmpDir, "layout")
+	if err := b.writeImage(ctx, layoutDir, cacheDir); err != nil {
+		return err
+	}
+	srcRef, err := layout.NewReference(layoutDir, buildCatalogLayoutTag)
+	if err != nil {
+		return err
+	}
+
+	if b.OutputFile != "" {
+		destRef, err := ociarchive.NewReference(b.OutputFile, b.Push)
+		if err != nil {
+			return err
+		}
+		if err := copyImage(ctx, srcRef, destRef, nil); err != nil {
+			return fmt.Errorf("write image to %q: %v", b.OutputFile, err)
+		}
+	}
+	if b.Push != "" {
+		named, err := reference.ParseNormalizedNamed(b.Push)
+		if err != nil {
+			return fmt.Errorf("parse push reference: %v", err)
+		}
+		destRef, err := docker.NewReference(reference.TagNameOnly(named))
+		if err != nil {
+			return err
+		}
+		destCtx := &types.SystemContext{DockerInsecureSkipTLSVerify: types.NewOptionalBool(b.SkipTLSVerify)}
+		if err := copyImage(ctx, srcRef, destRef, destCtx); err != nil {
+			return fmt.Errorf("push image to %q: %v", b.Push, err)
+		}
+	}
+	return nil
+}
+
+// writeImage writes the image to an OCI layout in layoutDir, tagged with
+// buildCatalogLayoutTag.
+func (b BuildCatalog) writeImage(ctx context.Context, layoutDir, cacheDir string) error {
+	store, err := oci.NewWithContext(ctx, layoutDir)
+	if err != nil {
+		return fmt.Errorf("create oci layout: %v", err)
+	}
+
+	var layer bytes.Buffer
+	diffID := sha256.New()
+	gzw := gzip.NewWriter(&layer)
+	tw := tar.NewWriter(io.MultiWriter(gzw, diffID))
+	if err := writeTarTree(tw, b.CatalogDir, buildCatalogConfigsDir); err != nil {
+		return fmt.Errorf("write catalog to layer: %v", err)
+	}
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "tmp/", Mode: 0o1777}); err != nil {
+		return err
+	}
+	if err := writeTarTree(tw, cacheDir, buildCatalogCacheDir); err != nil {
+		return fmt.Errorf("write cache to layer: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gzw.Close(); err != nil {
+		return err
+	}
+	layerDesc, err := pushBlob(ctx, store, ocispec.MediaTypeImageLayerGzip, layer.Bytes())
+	if err != nil {
+		return err
+	}
+
+	labels := map[string]string{containertools.ConfigsLocationLabel: "/" + buildCatalogConfigsDir}
+	for k, v := range b.ExtraLabels {
+		labels[k] = v
+	}
+	arch := b.Architecture
+	if arch == "" {
+		arch = "amd64"
+	}
+	config, err := json.Marshal(ocispec.Image{
+		Platform: ocispec.Platform{OS: "linux", Architecture: arch},
+		Config:   ocispec.ImageConfig{Labels: labels},
+		RootFS: ocispec.RootFS{
+			Type:    "layers",
+			DiffIDs: []digest.Digest{digest.NewDigestFromBytes(digest.SHA256, diffID.Sum(nil))},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	configDesc, err := pushBlob(ctx, store, ocispec.MediaTypeImageConfig, config)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    configDesc,
+		Layers:    []ocispec.Descriptor{layerDesc},
+	})
+	if err != nil {
+		return err
+	}
+	manifestDesc, err := pushBlob(ctx, store, ocispec.MediaTypeImageManifest, manifest)
+	if err != nil {
+		return err
+	}
+	return store.Tag(ctx, manifestDesc, buildCatalogLayoutTag)
+}
+
+func pushBlob(ctx context.Context, store *oci.Store, mediaType string, data []byte) (ocispec.Descriptor, error) {
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(data),
+		Size:      int64(len(data)),
+	}
+	if err := store.Push(ctx, desc, bytes.NewReader(data)); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("write %s: %v", mediaType, err)
+	}
+	return desc, nil
+}
+
+// writeTarTree writes the tree rooted at root to tw under prefix. Timestamps
+// and owners are reset so that the layer is reproducible, but file modes are
+// kept, since they are part of the digest of the catalog that the serve cache
+// is checked against. Symlinks are skipped, as they are when the digest is
+// computed.
+func writeTarTree(tw *tar.Writer, root, prefix string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&os.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		h, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		h.Name = prefix
+		if rel != "." {
+			h.Name = prefix + "/" + filepath.ToSlash(rel)
+		}
+		if d.IsDir() {
+			h.Name = strings.TrimSuffix(h.Name, "/") + "/"
+		}
+		h.Uid, h.Gid, h.Uname, h.Gname = 0, 0, "", ""
+		h.ModTime, h.AccessTime, h.ChangeTime = time.Time{}, time.Time{}, time.Time{}
+		if err := tw.WriteHeader(h); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
+
+func copyImage(ctx context.Context, src, dest types.ImageReference, destCtx *types.SystemContext) error {
+	// The source is the image that was just built, so there are no
+	// signatures to verify.
+	policyContext, err := signature.NewPolicyContext(&signature.Policy{
+		Default: []signature.PolicyRequirement{signature.NewPRInsecureAcceptAnything()},
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = policyContext.Destroy()
+	}()
+	_, err = copy.Image(ctx, policyContext, dest, src, &copy.Options{DestinationCtx: destCtx})
+	return err
+}
@@ -0,0 +1,104 @@
+package action_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ociarchive "github.com/containers/image/v5/oci/archive"
+	"github.com/containers/image/v5/pkg/blobinfocache/none"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/pkg/cache"
+	"github.com/operator-framework/operator-registry/pkg/containertools"
+)
+
+func TestBuildCatalog(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success/OutputFile", func(t *testing.T) {
+		outputFile := filepath.Join(t.TempDir(), "catalog.tar")
+		build := action.BuildCatalog{
+			CatalogDir:   "testdata/list-index",
+			OutputFile:   outputFile,
+			Architecture: "arm64",
+			ExtraLabels:  map[string]string{"key1": "value1"},
+		}
+		require.NoError(t, build.Run(ctx))
+
+		ref, err := ociarchive.NewReference(outputFile, "")
+		require.NoError(t, err)
+		img, err := ref.NewImage(ctx, nil)
+		require.NoError(t, err)
+		defer img.Close()
+		config, err := img.OCIConfig(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "linux", config.OS)
+		require.Equal(t, "arm64", config.Architecture)
+		require.Equal(t, map[string]string{containertools.ConfigsLocationLabel: "/configs", "key1": "value1"}, config.Config.Labels)
+
+		// The image's cache must be valid for the image's catalog.
+		src, err := ref.NewImageSource(ctx, nil)
+		require.NoError(t, err)
+		defer src.Close()
+		layers := img.LayerInfos()
+		require.Len(t, layers, 1)
+		blob, _, err := src.GetBlob(ctx, layers[0], none.NoCache)
+		require.NoError(t, err)
+		defer blob.Close()
+		rootDir := t.TempDir()
+		extractLayer(t, blob, rootDir)
+
+		c, err := cache.New(filepath.Join(rootDir, "tmp/cache"))
+		require.NoError(t, err)
+		defer c.Close()
+		require.NoError(t, c.CheckIntegrity(ctx, os.DirFS(filepath.Join(rootDir, "configs"))))
+		expected, err := os.ReadFile("testdata/list-index/foo/index.yaml")
+		require.NoError(t, err)
+		actual, err := os.ReadFile(filepath.Join(rootDir, "configs/foo/index.yaml"))
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("Error/NoOutput", func(t *testing.T) {
+		err := action.BuildCatalog{CatalogDir: "testdata/list-index"}.Run(ctx)
+		require.EqualError(t, err, "at least one of output file and push reference must be set")
+	})
+
+	t.Run("Error/InvalidCatalog", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "catalog.json"), []byte(`{"schema":"olm.package"}`), 0600))
+		err := action.BuildCatalog{CatalogDir: dir, OutputFile: filepath.Join(t.TempDir(), "catalog.tar")}.Run(ctx)
+		require.ErrorContains(t, err, "build cache")
+	})
+}
+
+func extractLayer(t *testing.T, r io.Reader, dir string) {
+	t.Helper()
+	gzr, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	tr := tar.NewReader(gzr)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		require.NoError(t, err)
+		path := filepath.Join(dir, h.Name)
+		switch h.Typeflag {
+		case tar.TypeDir:
+			require.NoError(t, os.MkdirAll(path, 0700))
+			require.NoError(t, os.Chmod(path, h.FileInfo().Mode()))
+		case tar.TypeReg:
+			data, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(path, data, h.FileInfo().Mode()))
+		}
+	}
+}
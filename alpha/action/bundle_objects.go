@@ -0,0 +1,134 @@
+package action
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+// BundleObjects is how Render outputs the objects of the rendered bundles,
+// which are held by their olm.bundle.object properties.
+type BundleObjects string
+
+const (
+	// BundleObjectsInline keeps the data of the objects in the properties.
+	BundleObjectsInline BundleObjects = "inline"
+	// BundleObjectsOmit removes the properties, so that the rendered
+	// catalog has no objects.
+	BundleObjectsOmit BundleObjects = "omit"
+	// BundleObjectsReference writes each object to a file in the objects
+	// directory, and replaces the data of its property with a reference to
+	// the file, which is resolved when the catalog is loaded.
+	BundleObjectsReference BundleObjects = "reference"
+)
+
+// ParseBundleObjects parses the name of a BundleObjects mode.
+func ParseBundleObjects(mode string) (BundleObjects, error) {
+	switch m := BundleObjects(mode); m {
+	case BundleObjectsInline, BundleObjectsOmit, BundleObjectsReference:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid bundle objects mode %q, expected (%s|%s|%s)", mode, BundleObjectsOmit, BundleObjectsReference, BundleObjectsInline)
+}
+
+// objectsIgnoreFile keeps the objects directory from being loaded as part of
+// the catalog, since it holds bundle objects rather than catalog files.
+const objectsIgnoreFile = ".indexignore"
+
+// outputBundleObjects applies mode to the olm.bundle.object properties of
+// the bundles of cfg. References are written to objectsDir, which must be a
+// local relative path, since references are relative to the catalog file.
+func outputBundleObjects(cfg *declcfg.DeclarativeConfig, mode BundleObjects, objectsDir string) error {
+	switch mode {
+	case "", BundleObjectsInline:
+		return nil
+	case BundleObjectsOmit:
+		for i := range cfg.Bundles {
+			b := &cfg.Bundles[i]
+			props := b.Properties[:0]
+			for _, p := range b.Properties {
+				if p.Type != property.TypeBundleObject {
+					props = append(props, p)
+				}
+			}
+			b.Properties = props
+		}
+		return nil
+	case BundleObjectsReference:
+		return writeBundleObjectRefs(cfg, objectsDir)
+	}
+	return fmt.Errorf("invalid bundle objects mode %q", mode)
+}
+
+func writeBundleObjectRefs(cfg *declcfg.DeclarativeConfig, objectsDir string) error {
+	if !filepath.IsLocal(objectsDir) {
+		return fmt.Errorf("objects directory %q must be a relative path within the directory of the catalog", objectsDir)
+	}
+	if err := os.MkdirAll(objectsDir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(objectsDir, objectsIgnoreFile), []byte("*\n"), 0644); err != nil {
+		return err
+	}
+
+	for i := range cfg.Bundles {
+		b := &cfg.Bundles[i]
+		bundleDir := path.Join(filepath.ToSlash(objectsDir), b.Package, b.Name)
+		used := map[string]int{}
+		for j, p := range b.Properties {
+			if p.Type != property.TypeBundleObject {
+				continue
+			}
+			var obj property.BundleObject
+			if err := json.Unmarshal(p.Value, &obj); err != nil {
+				return fmt.Errorf("bundle %q: parse property at index %d as bundle object: %v", b.Name, j, err)
+			}
+			if obj.Ref != "" {
+				continue
+			}
+			name := objectFileName(obj.Data)
+			if n := used[name]; n > 0 {
+				name = fmt.Sprintf("%s-%d", name, n)
+			}
+			used[name]++
+			ext := ".yaml"
+			if json.Valid(obj.Data) {
+				ext = ".json"
+			}
+			ref := path.Join(bundleDir, name+ext)
+
+			if err := os.MkdirAll(filepath.FromSlash(bundleDir), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(filepath.FromSlash(ref), obj.Data, 0644); err != nil {
+				return err
+			}
+			b.Properties[j] = property.MustBuild(&property.BundleObject{Ref: ref})
+		}
+	}
+	return nil
+}
+
+// objectFileName returns the name of the file that the object with the given
+// data is written to, without its extension: its lowercased kind and its
+// name.
+func objectFileName(data []byte) string {
+	var u unstructured.Unstructured
+	if err := yaml.Unmarshal(data, &u.Object); err != nil || u.GetKind() == "" {
+		return "object"
+	}
+	name := strings.ToLower(u.GetKind())
+	if u.GetName() != "" {
+		name += "-" + u.GetName()
+	}
+	return strings.NewReplacer("/", "_", ":", "_").Replace(name)
+}
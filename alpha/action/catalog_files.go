@@ -0,0 +1,151 @@
+package action
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+// catalogFiles maps the path of each file in a catalog directory to its content.
+type catalogFiles map[string]*declcfg.DeclarativeConfig
+
+func loadCatalogFiles(dir string) (catalogFiles, error) {
+	files := catalogFiles{}
+	if err := declcfg.WalkFS(os.DirFS(dir), func(path string, cfg *declcfg.DeclarativeConfig, err error) error {
+		if err != nil {
+			return err
+		}
+		files[path] = cfg
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("load catalog %q: %v", dir, err)
+	}
+	return files, nil
+}
+
+func (f catalogFiles) packageFile(pkgName string) string {
+	for path, cfg := range f {
+		for _, p := range cfg.Packages {
+			if p.Name == pkgName {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+func (f catalogFiles) hasBundle(pkgName, bundleName string) bool {
+	for _, cfg := range f {
+		for _, b := range cfg.Bundles {
+			if b.Package == pkgName && b.Name == bundleName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f catalogFiles) channel(pkgName, chName string) (string, *declcfg.Channel) {
+	for path, cfg := range f {
+		for i := range cfg.Channels {
+			if cfg.Channels[i].Package == pkgName && cfg.Channels[i].Name == chName {
+				return path, &cfg.Channels[i]
+			}
+		}
+	}
+	return "", nil
+}
+
+func writeCatalogFile(path string, cfg declcfg.DeclarativeConfig) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return catalogFileWriteFunc(path)(cfg, f)
+}
+
+// catalogFileWriteFunc returns the function that writes a catalog file in the
+// format of its extension: JSON, or YAML for ".yaml" and ".yml" files, and
+// compressed for gzip files, like "catalog.yaml.gz".
+func catalogFileWriteFunc(path string) declcfg.WriteFunc {
+	name := strings.TrimSuffix(path, declcfg.GzipExt)
+	writeFunc := declcfg.WriteJSON
+	switch filepath.Ext(name) {
+	case ".yaml", ".yml":
+		writeFunc = declcfg.WriteYAML
+	}
+	if name != path {
+		writeFunc = declcfg.WriteGzip(writeFunc)
+	}
+	return writeFunc
+}
+
+// writeModifiedFiles writes the modified files of a catalog directory, and
+// removes the files that no longer have any content.
+func writeModifiedFiles(dir string, files catalogFiles, modified sets.Set[string]) error {
+	for _, path := range sets.List(modified) {
+		fullPath := filepath.Join(dir, path)
+		if isEmptyConfig(*files[path]) {
+			if err := os.Remove(fullPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeCatalogFile(fullPath, *files[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merged returns the content of all of the catalog's files.
+func (f catalogFiles) merged() declcfg.DeclarativeConfig {
+	var merged declcfg.DeclarativeConfig
+	for _, cfg := range f {
+		merged.Merge(cfg)
+	}
+	return merged
+}
+
+// CatalogBundle identifies a bundle reported by an action that edits a
+// catalog directory.
+type CatalogBundle struct {
+	Package string
+	Name    string
+	Image   string
+}
+
+func sortCatalogBundles(bundles []CatalogBundle) {
+	sort.Slice(bundles, func(i, j int) bool {
+		if bundles[i].Package != bundles[j].Package {
+			return bundles[i].Package < bundles[j].Package
+		}
+		return bundles[i].Name < bundles[j].Name
+	})
+}
+
+// WriteBundles writes a table of bundles to w.
+func WriteBundles(w io.Writer, bundles []CatalogBundle) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "PACKAGE\tBUNDLE\tIMAGE"); err != nil {
+		return err
+	}
+	for _, b := range bundles {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", b.Package, b.Name, b.Image); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
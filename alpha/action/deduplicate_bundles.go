@@ -0,0 +1,86 @@
+package action
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+// DuplicateBundle describes a bundle whose content was rendered more than
+// once, and whose duplicates were removed from the rendered config.
+type DuplicateBundle struct {
+	Package string
+	Name    string
+	// Digest is the hex-encoded sha256 digest of the canonical JSON of the
+	// bundle.
+	Digest string
+	// Refs are the refs the bundle was rendered from, in order. The bundle
+	// rendered from the first ref is kept.
+	Refs []string
+}
+
+func (d DuplicateBundle) String() string {
+	return fmt.Sprintf("bundle %q of package %q (sha256:%s) is rendered from refs %q: keeping the bundle rendered from %q", d.Name, d.Package, d.Digest, d.Refs, d.Refs[0])
+}
+
+// deduplicateBundles removes each bundle of cfgs, rendered from the ref of
+// the same index, whose content is identical to an earlier bundle, and returns
+// the duplicated bundles in the order they were first rendered.
+func deduplicateBundles(refs []string, cfgs []declcfg.DeclarativeConfig) ([]DuplicateBundle, error) {
+	type occurrence struct {
+		ref       int
+		duplicate int
+	}
+	var duplicates []DuplicateBundle
+	seen := map[string]*occurrence{}
+	for i := range cfgs {
+		bundles := cfgs[i].Bundles[:0]
+		for _, b := range cfgs[i].Bundles {
+			digest, err := bundleDigest(b)
+			if err != nil {
+				return nil, fmt.Errorf("compute digest of bundle %q: %v", b.Name, err)
+			}
+			o, ok := seen[digest]
+			if !ok {
+				seen[digest] = &occurrence{ref: i, duplicate: -1}
+				bundles = append(bundles, b)
+				continue
+			}
+			if o.duplicate < 0 {
+				o.duplicate = len(duplicates)
+				duplicates = append(duplicates, DuplicateBundle{
+					Package: b.Package,
+					Name:    b.Name,
+					Digest:  digest,
+					Refs:    []string{refs[o.ref]},
+				})
+			}
+			duplicates[o.duplicate].Refs = append(duplicates[o.duplicate].Refs, refs[i])
+		}
+		cfgs[i].Bundles = bundles
+	}
+	return duplicates, nil
+}
+
+// bundleDigest returns the digest of the canonical JSON of b, in which the
+// keys of objects are sorted and insignificant whitespace is removed, so that
+// bundles rendered from different sources have the same digest if their
+// content is identical.
+func bundleDigest(b declcfg.Bundle) (string, error) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return "", err
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return "", err
+	}
+	if data, err = json.Marshal(v); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
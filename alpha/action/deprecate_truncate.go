@@ -0,0 +1,288 @@
+package action
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+// DeprecateTruncate deprecates bundles of a file-based catalog directory and
+// truncates the upgrade graph below them, as `opm index deprecatetruncate`
+// does for sqlite-based indexes.
+//
+// Each deprecated bundle is given an olm.deprecated property, and the entries
+// that it transitively replaces or skips are removed from each of its
+// channels. Entries that are still referenced by an entry outside of the
+// truncated part of the graph are kept. The deprecated bundle's entries no
+// longer replace or skip anything, and bundles that are no longer in any
+// channel are removed from the catalog.
+//
+// Deprecating the head of a package's default channel is refused, unless
+// AllowPackageRemoval is set and the heads of all of the package's channels
+// are deprecated, in which case the package is removed.
+type DeprecateTruncate struct {
+	CatalogDir string
+	// Bundles are the images or names of the bundles to deprecate. Bundles
+	// that were already removed by the truncation of a previous bundle are
+	// ignored.
+	Bundles             []string
+	AllowPackageRemoval bool
+}
+
+// DeprecateTruncateResult summarizes the changes made by DeprecateTruncate.
+type DeprecateTruncateResult struct {
+	Deprecated      []CatalogBundle
+	Removed         []CatalogBundle
+	RemovedPackages []string
+}
+
+func (d DeprecateTruncate) Run(_ context.Context) (*DeprecateTruncateResult, error) {
+	if len(d.Bundles) == 0 {
+		return nil, fmt.Errorf("at least one bundle must be specified")
+	}
+	files, err := loadCatalogFiles(d.CatalogDir)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := sets.New(d.Bundles...)
+	found := sets.New[string]()
+	byPackage := map[string][]declcfg.Bundle{}
+	var deprecate []declcfg.Bundle
+	for _, cfg := range files {
+		for _, b := range cfg.Bundles {
+			byPackage[b.Package] = append(byPackage[b.Package], b)
+		}
+	}
+	for _, ref := range d.Bundles {
+		for _, bundles := range byPackage {
+			for _, b := range bundles {
+				if b.Name == ref || b.Image == ref {
+					deprecate = append(deprecate, b)
+					found.Insert(ref)
+				}
+			}
+		}
+	}
+	if missing := refs.Difference(found); missing.Len() > 0 {
+		return nil, fmt.Errorf("bundles %v not found in catalog %q", sets.List(missing), d.CatalogDir)
+	}
+
+	result := &DeprecateTruncateResult{}
+	modified := sets.New[string]()
+	deprecateNames := map[string]sets.Set[string]{}
+	for _, b := range deprecate {
+		if _, ok := deprecateNames[b.Package]; !ok {
+			deprecateNames[b.Package] = sets.New[string]()
+		}
+		deprecateNames[b.Package].Insert(b.Name)
+	}
+
+	removedPackages := sets.New[string]()
+	for _, pkg := range sets.List(sets.KeySet(deprecateNames)) {
+		remove, err := d.checkDefaultChannelHead(files, pkg, deprecateNames[pkg])
+		if err != nil {
+			return nil, err
+		}
+		if !remove {
+			continue
+		}
+		e := &catalogEdit{files: files, pkg: pkg, modified: modified}
+		e.removePackage()
+		removedPackages.Insert(pkg)
+		for _, b := range byPackage[pkg] {
+			result.Removed = append(result.Removed, CatalogBundle{Package: pkg, Name: b.Name, Image: b.Image})
+		}
+	}
+
+	for _, b := range deprecate {
+		if removedPackages.Has(b.Package) {
+			continue
+		}
+		e := &catalogEdit{files: files, pkg: b.Package, modified: modified}
+		deprecated, removed := e.deprecateTruncate(b.Name)
+		if !deprecated {
+			// The bundle was removed by the truncation of a previously
+			// deprecated bundle.
+			continue
+		}
+		result.Deprecated = append(result.Deprecated, CatalogBundle{Package: b.Package, Name: b.Name, Image: b.Image})
+		for _, name := range sets.List(removed) {
+			for _, rb := range byPackage[b.Package] {
+				if rb.Name == name {
+					result.Removed = append(result.Removed, CatalogBundle{Package: b.Package, Name: name, Image: rb.Image})
+				}
+			}
+		}
+	}
+
+	if _, err := declcfg.ConvertToModel(files.merged()); err != nil {
+		return nil, fmt.Errorf("catalog is invalid after deprecating bundles: %v", err)
+	}
+	if err := writeModifiedFiles(d.CatalogDir, files, modified); err != nil {
+		return nil, err
+	}
+
+	// A deprecated bundle may have been removed by the truncation of a bundle
+	// that was deprecated after it.
+	result.Deprecated = filterSlice(result.Deprecated, func(b CatalogBundle) bool {
+		for _, r := range result.Removed {
+			if r == b {
+				return false
+			}
+		}
+		return true
+	})
+	sortCatalogBundles(result.Deprecated)
+	sortCatalogBundles(result.Removed)
+	if removedPackages.Len() > 0 {
+		result.RemovedPackages = sets.List(removedPackages)
+	}
+	return result, nil
+}
+
+// checkDefaultChannelHead returns whether pkg must be removed because the head
+// of its default channel is deprecated. It fails if the package may not be
+// removed.
+func (d DeprecateTruncate) checkDefaultChannelHead(files catalogFiles, pkg string, names sets.Set[string]) (bool, error) {
+	var defaultChannel string
+	for _, cfg := range files {
+		for _, p := range cfg.Packages {
+			if p.Name == pkg {
+				defaultChannel = p.DefaultChannel
+			}
+		}
+	}
+	_, ch := files.channel(pkg, defaultChannel)
+	if ch == nil {
+		return false, nil
+	}
+	head, err := channelHead(*ch)
+	if err != nil {
+		return false, fmt.Errorf("package %q channel %q: %v", pkg, ch.Name, err)
+	}
+	if !names.Has(head) {
+		return false, nil
+	}
+	if !d.AllowPackageRemoval {
+		return false, fmt.Errorf("cannot deprecate bundle %q: it is the head of the default channel %q of package %q", head, defaultChannel, pkg)
+	}
+	for _, cfg := range files {
+		for _, ch := range cfg.Channels {
+			if ch.Package != pkg {
+				continue
+			}
+			head, err := channelHead(ch)
+			if err != nil {
+				return false, fmt.Errorf("package %q channel %q: %v", pkg, ch.Name, err)
+			}
+			if !names.Has(head) {
+				return false, fmt.Errorf("cannot deprecate default channel head from package without removing all other channel heads in package %q: must deprecate %q, head of channel %q", pkg, head, ch.Name)
+			}
+		}
+	}
+	return true, nil
+}
+
+// deprecateTruncate deprecates the bundle and truncates the graph below it in
+// each of its channels. It returns whether the bundle was found in a channel,
+// and the names of the bundles that were removed from the catalog.
+func (e *catalogEdit) deprecateTruncate(bundleName string) (bool, sets.Set[string]) {
+	truncated := sets.New[string]()
+	found := false
+	for path, cfg := range e.files {
+		for i := range cfg.Channels {
+			ch := &cfg.Channels[i]
+			if ch.Package != e.pkg {
+				continue
+			}
+			removed, ok := truncateChannel(ch, bundleName)
+			if !ok {
+				continue
+			}
+			found = true
+			truncated = truncated.Union(removed)
+			e.modified.Insert(path)
+		}
+	}
+	if !found {
+		return false, nil
+	}
+
+	for path, cfg := range e.files {
+		for i := range cfg.Bundles {
+			b := &cfg.Bundles[i]
+			if b.Package != e.pkg || b.Name != bundleName || isDeprecated(*b) {
+				continue
+			}
+			b.Properties = append(b.Properties, property.MustBuildDeprecated())
+			e.modified.Insert(path)
+		}
+	}
+
+	removed := e.pruneBundles(truncated)
+	e.pruneDeprecations(sets.New[string](), removed)
+	return true, removed
+}
+
+// truncateChannel removes the entries of ch that are transitively replaced or
+// skipped by bundleName, and clears the upgrade edges of bundleName's entry.
+// Entries that are referenced by entries outside of the truncated part of the
+// graph are kept. It returns the names of the removed entries, and whether
+// bundleName is in the channel.
+func truncateChannel(ch *declcfg.Channel, bundleName string) (sets.Set[string], bool) {
+	byName := make(map[string]*declcfg.ChannelEntry, len(ch.Entries))
+	for i := range ch.Entries {
+		byName[ch.Entries[i].Name] = &ch.Entries[i]
+	}
+	head, ok := byName[bundleName]
+	if !ok {
+		return nil, false
+	}
+
+	tail := sets.New[string]()
+	queue := []*declcfg.ChannelEntry{head}
+	for len(queue) > 0 {
+		entry := queue[0]
+		queue = queue[1:]
+		for _, name := range append([]string{entry.Replaces}, entry.Skips...) {
+			next, ok := byName[name]
+			if !ok || name == bundleName || tail.Has(name) {
+				continue
+			}
+			tail.Insert(name)
+			queue = append(queue, next)
+		}
+	}
+
+	keep := sets.New[string]()
+	for _, entry := range ch.Entries {
+		if entry.Name == bundleName || tail.Has(entry.Name) {
+			continue
+		}
+		for _, name := range append([]string{entry.Replaces}, entry.Skips...) {
+			if tail.Has(name) {
+				keep.Insert(name)
+			}
+		}
+	}
+	removed := tail.Difference(keep)
+
+	head.Replaces = ""
+	head.Skips = nil
+	ch.Entries = filterSlice(ch.Entries, func(entry declcfg.ChannelEntry) bool { return !removed.Has(entry.Name) })
+	return removed, true
+}
+
+func isDeprecated(b declcfg.Bundle) bool {
+	for _, p := range b.Properties {
+		if p.Type == property.TypeDeprecated {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,232 @@
+package action_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+const deprecateFooCatalog = `---
+schema: olm.package
+name: foo
+defaultChannel: stable
+---
+schema: olm.channel
+package: foo
+name: stable
+entries:
+  - name: foo.v0.1.0
+  - name: foo.v0.2.0
+    replaces: foo.v0.1.0
+  - name: foo.v0.3.0
+    replaces: foo.v0.2.0
+  - name: foo.v0.4.0
+    replaces: foo.v0.3.0
+    skips:
+      - foo.v0.1.0
+---
+schema: olm.channel
+package: foo
+name: fast
+entries:
+  - name: foo.v0.3.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.1.0
+image: test.registry/foo-operator/foo-bundle:v0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.1.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.2.0
+image: test.registry/foo-operator/foo-bundle:v0.2.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.2.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.3.0
+image: test.registry/foo-operator/foo-bundle:v0.3.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.3.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.4.0
+image: test.registry/foo-operator/foo-bundle:v0.4.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.4.0
+---
+schema: olm.deprecations
+package: foo
+entries:
+  - reference:
+      schema: olm.bundle
+      name: foo.v0.2.0
+    message: foo.v0.2.0 is deprecated
+`
+
+func TestDeprecateTruncate(t *testing.T) {
+	fooBundle := func(version string) action.CatalogBundle {
+		return action.CatalogBundle{Package: "foo", Name: "foo." + version, Image: "test.registry/foo-operator/foo-bundle:" + version}
+	}
+
+	type spec struct {
+		name                 string
+		deprecate            action.DeprecateTruncate
+		expectedResult       *action.DeprecateTruncateResult
+		expectedEntries      map[string][]declcfg.ChannelEntry
+		expectedDeprecated   []string
+		expectedDeprecations []string
+		expectErr            string
+	}
+
+	specs := []spec{
+		{
+			name:      "Success/ByImage",
+			deprecate: action.DeprecateTruncate{Bundles: []string{"test.registry/foo-operator/foo-bundle:v0.3.0"}},
+			expectedResult: &action.DeprecateTruncateResult{
+				Deprecated: []action.CatalogBundle{fooBundle("v0.3.0")},
+				Removed:    []action.CatalogBundle{fooBundle("v0.2.0")},
+			},
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"stable": {
+					{Name: "foo.v0.1.0"},
+					{Name: "foo.v0.3.0"},
+					{Name: "foo.v0.4.0", Replaces: "foo.v0.3.0", Skips: []string{"foo.v0.1.0"}},
+				},
+				"fast": {{Name: "foo.v0.3.0"}},
+			},
+			expectedDeprecated: []string{"foo.v0.3.0"},
+		},
+		{
+			name:      "Success/KeepsReferencedEntries",
+			deprecate: action.DeprecateTruncate{Bundles: []string{"foo.v0.2.0"}},
+			expectedResult: &action.DeprecateTruncateResult{
+				Deprecated: []action.CatalogBundle{fooBundle("v0.2.0")},
+			},
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"stable": {
+					{Name: "foo.v0.1.0"},
+					{Name: "foo.v0.2.0"},
+					{Name: "foo.v0.3.0", Replaces: "foo.v0.2.0"},
+					{Name: "foo.v0.4.0", Replaces: "foo.v0.3.0", Skips: []string{"foo.v0.1.0"}},
+				},
+				"fast": {{Name: "foo.v0.3.0"}},
+			},
+			expectedDeprecated:   []string{"foo.v0.2.0"},
+			expectedDeprecations: []string{"foo.v0.2.0"},
+		},
+		{
+			name:      "Success/DeprecatedBundleRemovedByLaterBundle",
+			deprecate: action.DeprecateTruncate{Bundles: []string{"foo.v0.2.0", "foo.v0.3.0"}},
+			expectedResult: &action.DeprecateTruncateResult{
+				Deprecated: []action.CatalogBundle{fooBundle("v0.3.0")},
+				Removed:    []action.CatalogBundle{fooBundle("v0.2.0")},
+			},
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"stable": {
+					{Name: "foo.v0.1.0"},
+					{Name: "foo.v0.3.0"},
+					{Name: "foo.v0.4.0", Replaces: "foo.v0.3.0", Skips: []string{"foo.v0.1.0"}},
+				},
+				"fast": {{Name: "foo.v0.3.0"}},
+			},
+			expectedDeprecated: []string{"foo.v0.3.0"},
+		},
+		{
+			name:      "Success/RemovesPackage",
+			deprecate: action.DeprecateTruncate{Bundles: []string{"foo.v0.4.0", "foo.v0.3.0"}, AllowPackageRemoval: true},
+			expectedResult: &action.DeprecateTruncateResult{
+				Removed:         []action.CatalogBundle{fooBundle("v0.1.0"), fooBundle("v0.2.0"), fooBundle("v0.3.0"), fooBundle("v0.4.0")},
+				RemovedPackages: []string{"foo"},
+			},
+		},
+		{
+			name:      "Error/DefaultChannelHead",
+			deprecate: action.DeprecateTruncate{Bundles: []string{"foo.v0.4.0"}},
+			expectErr: `cannot deprecate bundle "foo.v0.4.0": it is the head of the default channel "stable" of package "foo"`,
+		},
+		{
+			name:      "Error/OtherChannelHeadNotDeprecated",
+			deprecate: action.DeprecateTruncate{Bundles: []string{"foo.v0.4.0"}, AllowPackageRemoval: true},
+			expectErr: `must deprecate "foo.v0.3.0", head of channel "fast"`,
+		},
+		{
+			name:      "Error/NotFound",
+			deprecate: action.DeprecateTruncate{Bundles: []string{"foo.v0.5.0"}},
+			expectErr: `bundles [foo.v0.5.0] not found in catalog`,
+		},
+	}
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "foo"), 0777))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "foo/catalog.yaml"), []byte(deprecateFooCatalog), 0600))
+
+			s.deprecate.CatalogDir = dir
+			result, err := s.deprecate.Run(context.Background())
+			if s.expectErr != "" {
+				require.ErrorContains(t, err, s.expectErr)
+				actual, err := os.ReadFile(filepath.Join(dir, "foo/catalog.yaml"))
+				require.NoError(t, err)
+				require.Equal(t, deprecateFooCatalog, string(actual), "catalog must not be modified on error")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, s.expectedResult, result)
+
+			if len(s.expectedResult.RemovedPackages) > 0 {
+				require.NoFileExists(t, filepath.Join(dir, "foo/catalog.yaml"))
+				return
+			}
+
+			cfg, err := declcfg.LoadFile(os.DirFS(dir), "foo/catalog.yaml")
+			require.NoError(t, err)
+			actualEntries := map[string][]declcfg.ChannelEntry{}
+			for _, ch := range cfg.Channels {
+				actualEntries[ch.Name] = ch.Entries
+			}
+			require.Equal(t, s.expectedEntries, actualEntries)
+
+			var actualDeprecated []string
+			for _, b := range cfg.Bundles {
+				for _, p := range b.Properties {
+					if p.Type == registry.DeprecatedType {
+						actualDeprecated = append(actualDeprecated, b.Name)
+					}
+				}
+			}
+			require.ElementsMatch(t, s.expectedDeprecated, actualDeprecated)
+
+			var actualDeprecations []string
+			for _, d := range cfg.Deprecations {
+				for _, e := range d.Entries {
+					actualDeprecations = append(actualDeprecations, e.Reference.Name)
+				}
+			}
+			require.ElementsMatch(t, s.expectedDeprecations, actualDeprecations)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/pkg/api"
+)
+
+// Dump reconstructs the file-based catalog that a registry serves from its
+// registry API, whatever the catalog was built from, and writes it to
+// OutputDir.
+//
+// The API does not serve everything that a file-based catalog holds, so the
+// catalog is equivalent to the served one rather than identical: it has no
+// package descriptions or unrecognized schemas, and bundles that have an
+// image have no olm.bundle.object or olm.csv.metadata properties, which are
+// rendered from the image when needed.
+type Dump struct {
+	Registry api.RegistryClient
+
+	OutputDir string
+	WriteFunc declcfg.WriteFunc
+	FileExt   string
+}
+
+func (d Dump) Run(ctx context.Context) error {
+	entries, err := os.ReadDir(d.OutputDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("output dir %q must be empty", d.OutputDir)
+	}
+
+	cfg, err := d.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	return declcfg.WriteFS(*cfg, d.OutputDir, d.WriteFunc, d.FileExt)
+}
+
+func (d Dump) catalog(ctx context.Context) (*declcfg.DeclarativeConfig, error) {
+	packages, err := d.Registry.ListPackages(ctx, &api.ListPackageRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("list packages: %v", err)
+	}
+	var names []string
+	for {
+		p, err := packages.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list packages: %v", err)
+		}
+		names = append(names, p.GetName())
+	}
+	sort.Strings(names)
+
+	cfg := &declcfg.DeclarativeConfig{}
+	channels := map[string]map[string]*declcfg.Channel{}
+	deprecations := map[string]*declcfg.Deprecation{}
+	for _, name := range names {
+		p, err := d.Registry.GetPackage(ctx, &api.GetPackageRequest{Name: name})
+		if err != nil {
+			return nil, fmt.Errorf("get package %q: %v", name, err)
+		}
+		pkg := declcfg.Package{
+			Schema:         declcfg.SchemaPackage,
+			Name:           p.GetName(),
+			DefaultChannel: p.GetDefaultChannelName(),
+			Properties:     dumpProperties(p.GetProperties()),
+		}
+		if icon := p.GetIcon(); len(icon.GetBase64Data()) > 0 {
+			pkg.Icon = &declcfg.Icon{Data: icon.GetBase64Data(), MediaType: icon.GetMediatype()}
+		}
+		cfg.Packages = append(cfg.Packages, pkg)
+
+		dep := &declcfg.Deprecation{Schema: declcfg.SchemaDeprecation, Package: name}
+		deprecations[name] = dep
+		if p.GetDeprecation() != nil {
+			dep.Entries = append(dep.Entries, declcfg.DeprecationEntry{
+				Reference: declcfg.PackageScopedReference{Schema: declcfg.SchemaPackage},
+				Message:   p.GetDeprecation().GetMessage(),
+			})
+		}
+
+		channels[name] = map[string]*declcfg.Channel{}
+		for _, ch := range p.GetChannels() {
+			channels[name][ch.GetName()] = &declcfg.Channel{
+				Schema:     declcfg.SchemaChannel,
+				Package:    name,
+				Name:       ch.GetName(),
+				Priority:   int(ch.GetPriority()),
+				Properties: dumpProperties(ch.GetProperties()),
+			}
+			if ch.GetDeprecation() != nil {
+				dep.Entries = append(dep.Entries, declcfg.DeprecationEntry{
+					Reference: declcfg.PackageScopedReference{Schema: declcfg.SchemaChannel, Name: ch.GetName()},
+					Message:   ch.GetDeprecation().GetMessage(),
+				})
+			}
+		}
+	}
+
+	// Bundles are sent once for each of their channels, with the edges of
+	// their entry in the channel.
+	bundles, err := d.Registry.ListBundles(ctx, &api.ListBundlesRequest{IncludeEdges: true})
+	if err != nil {
+		return nil, fmt.Errorf("list bundles: %v", err)
+	}
+	seen := map[string]map[string]struct{}{}
+	for {
+		b, err := bundles.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list bundles: %v", err)
+		}
+		ch, ok := channels[b.GetPackageName()][b.GetChannelName()]
+		if !ok {
+			return nil, fmt.Errorf("bundle %q is in unknown channel %q of package %q", b.GetCsvName(), b.GetChannelName(), b.GetPackageName())
+		}
+		ch.Entries = append(ch.Entries, declcfg.ChannelEntry{
+			Name:      b.GetCsvName(),
+			Replaces:  b.GetReplaces(),
+			Skips:     b.GetSkips(),
+			SkipRange: b.GetSkipRange(),
+		})
+
+		if seen[b.GetPackageName()] == nil {
+			seen[b.GetPackageName()] = map[string]struct{}{}
+		}
+		if _, ok := seen[b.GetPackageName()][b.GetCsvName()]; ok {
+			continue
+		}
+		seen[b.GetPackageName()][b.GetCsvName()] = struct{}{}
+
+		mb, err := api.ConvertAPIBundleToModelBundle(b)
+		if err != nil {
+			return nil, fmt.Errorf("convert bundle %q: %v", b.GetCsvName(), err)
+		}
+		cfg.Bundles = append(cfg.Bundles, declcfg.Bundle{
+			Schema:     declcfg.SchemaBundle,
+			Name:       b.GetCsvName(),
+			Package:    b.GetPackageName(),
+			Image:      b.GetBundlePath(),
+			Properties: mb.Properties,
+		})
+		if b.GetDeprecation() != nil {
+			dep := deprecations[b.GetPackageName()]
+			dep.Entries = append(dep.Entries, declcfg.DeprecationEntry{
+				Reference: declcfg.PackageScopedReference{Schema: declcfg.SchemaBundle, Name: b.GetCsvName()},
+				Message:   b.GetDeprecation().GetMessage(),
+			})
+		}
+	}
+	sort.Slice(cfg.Bundles, func(i, j int) bool {
+		if cfg.Bundles[i].Package != cfg.Bundles[j].Package {
+			return cfg.Bundles[i].Package < cfg.Bundles[j].Package
+		}
+		return cfg.Bundles[i].Name < cfg.Bundles[j].Name
+	})
+
+	for _, name := range names {
+		chNames := make([]string, 0, len(channels[name]))
+		for chName := range channels[name] {
+			chNames = append(chNames, chName)
+		}
+		sort.Strings(chNames)
+		for _, chName := range chNames {
+			ch := channels[name][chName]
+			sort.Slice(ch.Entries, func(i, j int) bool { return ch.Entries[i].Name < ch.Entries[j].Name })
+			cfg.Channels = append(cfg.Channels, *ch)
+		}
+		if dep := deprecations[name]; len(dep.Entries) > 0 {
+			cfg.Deprecations = append(cfg.Deprecations, *dep)
+		}
+	}
+	return cfg, nil
+}
+
+func dumpProperties(props []*api.Property) []property.Property {
+	// nolint:prealloc
+	var out []property.Property
+	for _, p := range props {
+		out = append(out, property.Property{Type: p.GetType(), Value: json.RawMessage(p.GetValue())})
+	}
+	return out
+}
@@ -0,0 +1,86 @@
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/pkg/api/apitest"
+)
+
+func TestDump(t *testing.T) {
+	bundle := func(pkg, version string, props ...property.Property) declcfg.Bundle {
+		return declcfg.Bundle{
+			Schema:     declcfg.SchemaBundle,
+			Name:       pkg + ".v" + version,
+			Package:    pkg,
+			Image:      "quay.io/example/" + pkg + "-bundle:v" + version,
+			Properties: append(props, property.MustBuildPackage(pkg, version)),
+		}
+	}
+	owner := property.Property{Type: "example.com/owner", Value: json.RawMessage(`{"team":"storage"}`)}
+
+	cfg := declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{
+			{Schema: declcfg.SchemaPackage, Name: "bar", DefaultChannel: "stable"},
+			{
+				Schema:         declcfg.SchemaPackage,
+				Name:           "foo",
+				DefaultChannel: "stable",
+				Icon:           &declcfg.Icon{Data: []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), MediaType: "image/svg+xml"},
+				Properties:     []property.Property{owner},
+			},
+		},
+		Channels: []declcfg.Channel{
+			{Schema: declcfg.SchemaChannel, Package: "bar", Name: "stable", Entries: []declcfg.ChannelEntry{{Name: "bar.v1.0.0"}}},
+			{Schema: declcfg.SchemaChannel, Package: "foo", Name: "fast", Entries: []declcfg.ChannelEntry{
+				{Name: "foo.v0.2.0"},
+			}},
+			{Schema: declcfg.SchemaChannel, Package: "foo", Name: "stable", Priority: 10, Properties: []property.Property{owner}, Entries: []declcfg.ChannelEntry{
+				{Name: "foo.v0.1.0"},
+				{Name: "foo.v0.1.1", Replaces: "foo.v0.1.0", Skips: []string{"foo.v0.0.9"}},
+				{Name: "foo.v0.2.0", Replaces: "foo.v0.1.1", SkipRange: "<0.2.0"},
+			}},
+		},
+		Bundles: []declcfg.Bundle{
+			bundle("bar", "1.0.0"),
+			bundle("foo", "0.1.0"),
+			bundle("foo", "0.1.1"),
+			bundle("foo", "0.2.0", owner),
+		},
+		Deprecations: []declcfg.Deprecation{
+			{Schema: declcfg.SchemaDeprecation, Package: "foo", Entries: []declcfg.DeprecationEntry{
+				{Reference: declcfg.PackageScopedReference{Schema: declcfg.SchemaChannel, Name: "fast"}, Message: "use stable"},
+				{Reference: declcfg.PackageScopedReference{Schema: declcfg.SchemaBundle, Name: "foo.v0.1.0"}, Message: "upgrade"},
+			}},
+		},
+	}
+
+	ctx := context.Background()
+	c, err := apitest.NewClient(ctx, cfg)
+	require.NoError(t, err)
+	defer c.Close()
+
+	d := Dump{Registry: c}
+	actual, err := d.catalog(ctx)
+	require.NoError(t, err)
+	require.Equal(t, cfg, *actual)
+
+	d.OutputDir = t.TempDir()
+	d.WriteFunc = declcfg.WriteYAML
+	d.FileExt = ".yaml"
+	require.NoError(t, d.Run(ctx))
+	loaded, err := declcfg.LoadFS(ctx, os.DirFS(d.OutputDir))
+	require.NoError(t, err)
+	require.Len(t, loaded.Packages, 2)
+	require.Len(t, loaded.Bundles, 4)
+
+	require.FileExists(t, filepath.Join(d.OutputDir, "foo", "catalog.yaml"))
+	require.ErrorContains(t, d.Run(ctx), "must be empty")
+}
@@ -0,0 +1,86 @@
+package action
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+// Fmt rewrites the files of a file-based catalog in a canonical form, so that
+// the diffs of a catalog only show changes to its content. The blobs of each
+// file are normalized with declcfg.Normalize and written in the order of the
+// declcfg write functions, in the format of the file.
+//
+// Each file is rewritten in place, and only if its content changes. With
+// DryRun, no file is rewritten.
+type Fmt struct {
+	CatalogDir string
+	DryRun     bool
+}
+
+// Run returns the paths, relative to CatalogDir, of the files that are not in
+// canonical form.
+func (f Fmt) Run() ([]string, error) {
+	if f.CatalogDir == "" {
+		return nil, errors.New("catalog directory is unset")
+	}
+	var unformatted []string
+	if err := declcfg.WalkFS(os.DirFS(f.CatalogDir), func(path string, cfg *declcfg.DeclarativeConfig, err error) error {
+		if err != nil {
+			return err
+		}
+		filename := filepath.Join(f.CatalogDir, filepath.FromSlash(path))
+		current, err := readCatalogFile(filename)
+		if err != nil {
+			return fmt.Errorf("read %q: %v", path, err)
+		}
+
+		declcfg.Normalize(cfg)
+		var formatted bytes.Buffer
+		if err := catalogFileWriteFunc(strings.TrimSuffix(path, declcfg.GzipExt))(*cfg, &formatted); err != nil {
+			return fmt.Errorf("format %q: %v", path, err)
+		}
+		if bytes.Equal(current, formatted.Bytes()) {
+			return nil
+		}
+		unformatted = append(unformatted, path)
+		if f.DryRun {
+			return nil
+		}
+
+		var buf bytes.Buffer
+		if err := catalogFileWriteFunc(path)(*cfg, &buf); err != nil {
+			return fmt.Errorf("write %q: %v", path, err)
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filename, buf.Bytes(), info.Mode())
+	}); err != nil {
+		return nil, fmt.Errorf("format catalog %q: %v", f.CatalogDir, err)
+	}
+	return unformatted, nil
+}
+
+// readCatalogFile returns the content of a catalog file, decompressed if its
+// name ends with declcfg.GzipExt.
+func readCatalogFile(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil || !strings.HasSuffix(filename, declcfg.GzipExt) {
+		return data, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
@@ -0,0 +1,110 @@
+package action_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+)
+
+func TestFmt(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"foo/catalog.yaml": `---
+schema: olm.bundle
+package: foo
+name: foo.v0.2.0
+image: test.registry/foo-operator/foo-bundle:v0.2.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.2.0
+  - type: olm.gvk
+    value: {"group": "test.foo", "kind": "Foo", "version": "v1"}
+relatedImages:
+  - name: operator
+    image: test.registry/foo-operator/foo:v0.2.0
+  - image: test.registry/foo-operator/foo-bundle:v0.2.0
+---
+schema: olm.channel
+package: foo
+name: stable
+entries:
+  - name: foo.v0.2.0
+    replaces: foo.v0.1.0
+    skips: [foo.v0.1.1, foo.v0.1.0-rc]
+  - name: foo.v0.1.0
+---
+schema: olm.package
+name: foo
+defaultChannel: stable
+`,
+		"bar/catalog.json": `{
+    "schema": "olm.package",
+    "name": "bar",
+    "defaultChannel": "stable"
+}
+`,
+	}
+	for path, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0777))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0600))
+	}
+
+	unformatted, err := action.Fmt{CatalogDir: dir, DryRun: true}.Run()
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo/catalog.yaml"}, unformatted)
+	data, err := os.ReadFile(filepath.Join(dir, "foo/catalog.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, files["foo/catalog.yaml"], string(data))
+
+	unformatted, err = action.Fmt{CatalogDir: dir}.Run()
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo/catalog.yaml"}, unformatted)
+	data, err = os.ReadFile(filepath.Join(dir, "foo/catalog.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, `---
+defaultChannel: stable
+name: foo
+schema: olm.package
+---
+entries:
+- name: foo.v0.1.0
+- name: foo.v0.2.0
+  replaces: foo.v0.1.0
+  skips:
+  - foo.v0.1.0-rc
+  - foo.v0.1.1
+name: stable
+package: foo
+schema: olm.channel
+---
+image: test.registry/foo-operator/foo-bundle:v0.2.0
+name: foo.v0.2.0
+package: foo
+properties:
+- type: olm.gvk
+  value:
+    group: test.foo
+    kind: Foo
+    version: v1
+- type: olm.package
+  value:
+    packageName: foo
+    version: 0.2.0
+relatedImages:
+- image: test.registry/foo-operator/foo-bundle:v0.2.0
+  name: ""
+- image: test.registry/foo-operator/foo:v0.2.0
+  name: operator
+schema: olm.bundle
+`, string(data))
+
+	unformatted, err = action.Fmt{CatalogDir: dir}.Run()
+	require.NoError(t, err)
+	require.Empty(t, unformatted)
+}
@@ -13,7 +13,17 @@ type GenerateDockerfile struct {
 	BuilderImage string
 	IndexDir     string
 	ExtraLabels  map[string]string
-	Writer       io.Writer
+	// BuildArgs declares the builder and base images as the BUILDER_IMAGE and
+	// BASE_IMAGE build arguments, defaulting to BuilderImage and BaseImage, so
+	// that they can be pinned to digests when the image is built.
+	BuildArgs bool
+	// CACertFile is the path, relative to the build context, of a PEM file of
+	// CA certificates to add to the trusted certificates of the image.
+	CACertFile string
+	// SingleStage builds the catalog in the base image, which must contain
+	// opm, without a builder stage. The serve cache is still pre-built.
+	SingleStage bool
+	Writer      io.Writer
 }
 
 func (i GenerateDockerfile) Run() error {
@@ -37,18 +47,43 @@ func (i GenerateDockerfile) validate() error {
 	if i.IndexDir == "" {
 		return fmt.Errorf("index directory is unset")
 	}
+	if i.SingleStage && i.BaseImage == "scratch" {
+		return fmt.Errorf("single-stage builds require a base image that contains opm, not %q", i.BaseImage)
+	}
 	return nil
 }
 
-const dockerfileTmpl = `# The builder image is expected to contain
+const dockerfileTmpl = `
+{{- if .BuildArgs -}}
+# The images can be overridden with build arguments, e.g. to pin them to digests
+{{- if not .SingleStage }}
+ARG BUILDER_IMAGE={{.BuilderImage}}
+{{- end }}
+ARG BASE_IMAGE={{.BaseImage}}
+
+{{ end -}}
+{{- if .SingleStage -}}
+FROM {{if .BuildArgs}}${BASE_IMAGE}{{else}}{{.BaseImage}}{{end}}
+# The base image is expected to contain
+# /bin/opm (with serve subcommand) and /bin/grpc_health_probe
+
+# Copy FBC root into image at /configs and pre-populate serve cache
+ADD {{.IndexDir}} /configs
+RUN ["/bin/opm", "serve", "/configs", "--cache-dir=/tmp/cache", "--cache-only"]
+
+# Configure the entrypoint and command
+ENTRYPOINT ["/bin/opm"]
+CMD ["serve", "/configs", "--cache-dir=/tmp/cache"]
+{{- else -}}
+# The builder image is expected to contain
 # /bin/opm (with serve subcommand)
-FROM {{.BuilderImage}} as builder
+FROM {{if .BuildArgs}}${BUILDER_IMAGE}{{else}}{{.BuilderImage}}{{end}} as builder
 
 # Copy FBC root into image at /configs and pre-populate serve cache
 ADD {{.IndexDir}} /configs
 RUN ["/bin/opm", "serve", "/configs", "--cache-dir=/tmp/cache", "--cache-only"]
 
-FROM {{.BaseImage}}
+FROM {{if .BuildArgs}}${BASE_IMAGE}{{else}}{{.BaseImage}}{{end}}
 
 {{- if ne .BaseImage "scratch" }}
 # The base image is expected to contain
@@ -68,6 +103,12 @@ CMD ["serve", "/configs", "--cache-dir=/tmp/cache"]
 
 COPY --from=builder /configs /configs
 COPY --from=builder /tmp/cache /tmp/cache
+{{- end }}
+{{- if .CACertFile }}
+
+# Add CA certificates to the trusted certificates of the image
+COPY {{.CACertFile}} /etc/ssl/certs/catalog-ca.crt
+{{- end }}
 
 # Set FBC-specific label for the location of the FBC root directory
 # in the image
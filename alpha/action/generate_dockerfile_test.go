@@ -203,6 +203,89 @@ LABEL "key1"="value1"
 LABEL "key2"="value2"
 `,
 		},
+		{
+			name: "BuildArgs/Success/WithCACertFile",
+			gen: GenerateDockerfile{
+				BuilderImage: "foo",
+				BaseImage:    "scratch",
+				IndexDir:     "bar",
+				BuildArgs:    true,
+				CACertFile:   "ca.pem",
+			},
+			expectedDockerfile: `# The images can be overridden with build arguments, e.g. to pin them to digests
+ARG BUILDER_IMAGE=foo
+ARG BASE_IMAGE=scratch
+
+# The builder image is expected to contain
+# /bin/opm (with serve subcommand)
+FROM ${BUILDER_IMAGE} as builder
+
+# Copy FBC root into image at /configs and pre-populate serve cache
+ADD bar /configs
+RUN ["/bin/opm", "serve", "/configs", "--cache-dir=/tmp/cache", "--cache-only"]
+
+FROM ${BASE_IMAGE}
+# OLMv0 CatalogSources that use binary-less images must set:
+# spec:
+#   grpcPodConfig:
+#     extractContent:
+#       catalogDir: /configs
+#       cacheDir: /tmp/cache
+
+COPY --from=builder /configs /configs
+COPY --from=builder /tmp/cache /tmp/cache
+
+# Add CA certificates to the trusted certificates of the image
+COPY ca.pem /etc/ssl/certs/catalog-ca.crt
+
+# Set FBC-specific label for the location of the FBC root directory
+# in the image
+LABEL operators.operatorframework.io.index.configs.v1=/configs
+`,
+		},
+		{
+			name: "SingleStage/Success/WithBuildArgs",
+			gen: GenerateDockerfile{
+				BaseImage:   "foo",
+				IndexDir:    "bar",
+				BuildArgs:   true,
+				SingleStage: true,
+				ExtraLabels: map[string]string{
+					"key1": "value1",
+				},
+			},
+			expectedDockerfile: `# The images can be overridden with build arguments, e.g. to pin them to digests
+ARG BASE_IMAGE=foo
+
+FROM ${BASE_IMAGE}
+# The base image is expected to contain
+# /bin/opm (with serve subcommand) and /bin/grpc_health_probe
+
+# Copy FBC root into image at /configs and pre-populate serve cache
+ADD bar /configs
+RUN ["/bin/opm", "serve", "/configs", "--cache-dir=/tmp/cache", "--cache-only"]
+
+# Configure the entrypoint and command
+ENTRYPOINT ["/bin/opm"]
+CMD ["serve", "/configs", "--cache-dir=/tmp/cache"]
+
+# Set FBC-specific label for the location of the FBC root directory
+# in the image
+LABEL operators.operatorframework.io.index.configs.v1=/configs
+
+# Set other custom labels
+LABEL "key1"="value1"
+`,
+		},
+		{
+			name: "SingleStage/Fail/ScratchBaseImage",
+			gen: GenerateDockerfile{
+				BaseImage:   "scratch",
+				IndexDir:    "bar",
+				SingleStage: true,
+			},
+			expectedErr: `single-stage builds require a base image that contains opm, not "scratch"`,
+		},
 	}
 
 	for _, s := range specs {
@@ -0,0 +1,160 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+// GitRefPrefix is the prefix of the references that Render reads from git
+// repositories, as git+<repository URL>#<path>@<ref>, e.g.
+// git+https://github.com/example/catalog#catalog@v1.2.0.
+const GitRefPrefix = "git+"
+
+// GitRef is a path in a git repository at a revision.
+type GitRef struct {
+	// URL is the URL of the repository, in any form that git clones.
+	URL string
+	// Path is the slash-separated path in the repository. It is empty for
+	// the root of the repository.
+	Path string
+	// Revision is the branch, tag or commit that Path is read at. It is
+	// empty for the default branch of the repository.
+	Revision string
+}
+
+// ParseGitRef parses a reference of the form git+<URL>[#<path>][@<revision>].
+// The path and revision are both in the URL fragment, since URLs of
+// repositories may contain '@'.
+func ParseGitRef(ref string) (*GitRef, error) {
+	if !strings.HasPrefix(ref, GitRefPrefix) {
+		return nil, fmt.Errorf("git reference %q must start with %q", ref, GitRefPrefix)
+	}
+	url, fragment, _ := strings.Cut(strings.TrimPrefix(ref, GitRefPrefix), "#")
+	if url == "" {
+		return nil, fmt.Errorf("git reference %q has no repository URL", ref)
+	}
+	p, revision := fragment, ""
+	if i := strings.LastIndex(fragment, "@"); i >= 0 {
+		p, revision = fragment[:i], fragment[i+1:]
+	}
+	p = strings.Trim(p, "/")
+	if p != "" {
+		p = path.Clean(p)
+		if p == ".." || strings.HasPrefix(p, "../") {
+			return nil, fmt.Errorf("git reference %q has path %q outside of the repository", ref, p)
+		}
+		if p == "." {
+			p = ""
+		}
+	}
+	return &GitRef{URL: url, Path: p, Revision: revision}, nil
+}
+
+// Checkout clones the repository of the reference into dir, which must be
+// empty or not exist, and checks out its path at its revision. Only the
+// directory of the path is checked out, with a sparse checkout, so that large
+// repositories are cheap to read from, and the repository is stored apart
+// from the checkout, so that rendering the root of a repository doesn't read
+// its git metadata. It returns the local path of the path of the reference.
+func (g GitRef) Checkout(ctx context.Context, dir string) (string, error) {
+	worktreeDir := filepath.Join(dir, "worktree")
+	storage := filesystem.NewStorage(osfs.New(filepath.Join(dir, "git")), cache.NewObjectLRUDefault())
+	repo, err := git.CloneContext(ctx, storage, osfs.New(worktreeDir), &git.CloneOptions{
+		URL:        g.URL,
+		NoCheckout: true,
+		Tags:       git.AllTags,
+	})
+	if err != nil {
+		return "", fmt.Errorf("clone %q: %v", g.URL, err)
+	}
+
+	hash, err := resolveGitRevision(repo, g.Revision)
+	if err != nil {
+		return "", fmt.Errorf("resolve revision %q of %q: %v", g.Revision, g.URL, err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	opts := &git.CheckoutOptions{Hash: hash, Force: true}
+	if g.Path != "" {
+		opts.SparseCheckoutDirectories = []string{g.Path}
+		if commit, err := repo.CommitObject(hash); err == nil {
+			// Sparse checkouts are of directories, so a file is checked
+			// out with its directory.
+			if _, err := commit.File(g.Path); err == nil {
+				opts.SparseCheckoutDirectories = []string{path.Dir(g.Path)}
+			}
+		}
+	}
+	if err := wt.Checkout(opts); err != nil {
+		return "", fmt.Errorf("checkout %s of %q: %v", hash, g.URL, err)
+	}
+	// The clone links the checkout to the repository with a .git file,
+	// which is not needed once it is checked out.
+	if err := os.Remove(filepath.Join(worktreeDir, git.GitDirName)); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	local := filepath.Join(worktreeDir, filepath.FromSlash(g.Path))
+	if _, err := os.Stat(local); err != nil {
+		return "", fmt.Errorf("path %q not found in %q at %s", g.Path, g.URL, hash)
+	}
+	return local, nil
+}
+
+// resolveGitRevision returns the commit of a branch, tag or commit of a
+// cloned repository, whose branches are remote-tracking branches of origin.
+func resolveGitRevision(repo *git.Repository, revision string) (plumbing.Hash, error) {
+	if revision == "" {
+		head, err := repo.Head()
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		return head.Hash(), nil
+	}
+	var firstErr error
+	for _, rev := range []string{revision, "origin/" + revision} {
+		hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+		if err == nil {
+			return *hash, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return plumbing.ZeroHash, firstErr
+}
+
+// gitToDeclcfg checks out the path of the git reference in a temporary
+// directory and renders it like any local reference, e.g. as a declarative
+// config or bundle directory.
+func (r Render) gitToDeclcfg(ctx context.Context, ref string) (*declcfg.DeclarativeConfig, error) {
+	g, err := ParseGitRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "opm-render-git-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	local, err := g.Checkout(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	return r.renderReference(ctx, local)
+}
@@ -0,0 +1,122 @@
+package action_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+)
+
+func TestParseGitRef(t *testing.T) {
+	type spec struct {
+		name      string
+		ref       string
+		expected  *action.GitRef
+		assertion require.ErrorAssertionFunc
+	}
+	for _, s := range []spec{
+		{
+			name:      "PathAndRevision",
+			ref:       "git+https://github.com/example/catalog#catalog/foo@v1.2.0",
+			expected:  &action.GitRef{URL: "https://github.com/example/catalog", Path: "catalog/foo", Revision: "v1.2.0"},
+			assertion: require.NoError,
+		},
+		{
+			name:      "UserInURL",
+			ref:       "git+ssh://git@github.com/example/catalog.git#/catalog/@main",
+			expected:  &action.GitRef{URL: "ssh://git@github.com/example/catalog.git", Path: "catalog", Revision: "main"},
+			assertion: require.NoError,
+		},
+		{
+			name:      "Root",
+			ref:       "git+https://github.com/example/catalog#@0123abc",
+			expected:  &action.GitRef{URL: "https://github.com/example/catalog", Revision: "0123abc"},
+			assertion: require.NoError,
+		},
+		{
+			name:      "DefaultBranch",
+			ref:       "git+https://github.com/example/catalog",
+			expected:  &action.GitRef{URL: "https://github.com/example/catalog"},
+			assertion: require.NoError,
+		},
+		{name: "NotGit", ref: "https://github.com/example/catalog", assertion: require.Error},
+		{name: "NoURL", ref: "git+#catalog@main", assertion: require.Error},
+		{name: "OutsideRepository", ref: "git+https://github.com/example/catalog#catalog/../..@main", assertion: require.Error},
+	} {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := action.ParseGitRef(s.ref)
+			s.assertion(t, err)
+			require.Equal(t, s.expected, actual)
+		})
+	}
+}
+
+func TestRenderGit(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+
+	commit := func(defaultChannel string) plumbing.Hash {
+		t.Helper()
+		catalog := "schema: olm.package\nname: foo\ndefaultChannel: " + defaultChannel + "\n"
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "catalog", "foo"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "catalog", "foo", "catalog.yaml"), []byte(catalog), 0600))
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "README.md"), []byte("# catalog\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".indexignore"), []byte("docs\n"), 0600))
+		_, err := wt.Add(".")
+		require.NoError(t, err)
+		hash, err := wt.Commit("set default channel to "+defaultChannel, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+		return hash
+	}
+	first := commit("alpha")
+	_, err = repo.CreateTag("v1.0.0", first, nil)
+	require.NoError(t, err)
+	second := commit("stable")
+
+	url := action.GitRefPrefix + "file://" + dir
+	for _, tt := range []struct {
+		name           string
+		ref            string
+		defaultChannel string
+	}{
+		{name: "Tag", ref: url + "#catalog@v1.0.0", defaultChannel: "alpha"},
+		{name: "Commit", ref: url + "#catalog@" + first.String(), defaultChannel: "alpha"},
+		{name: "DefaultBranch", ref: url + "#catalog", defaultChannel: "stable"},
+		{name: "Branch", ref: url + "#catalog/foo@master", defaultChannel: "stable"},
+		{name: "Root", ref: url + "#@" + second.String(), defaultChannel: "stable"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := action.Render{Refs: []string{tt.ref}, AllowedRefMask: action.RefGit | action.RefDCDir}.Run(context.Background())
+			require.NoError(t, err)
+			require.Len(t, cfg.Packages, 1)
+			require.Equal(t, tt.defaultChannel, cfg.Packages[0].DefaultChannel)
+		})
+	}
+
+	// Only the directory of the path is checked out.
+	local, err := action.GitRef{URL: "file://" + dir, Path: "catalog/foo/catalog.yaml"}.Checkout(context.Background(), t.TempDir())
+	require.NoError(t, err)
+	require.FileExists(t, local)
+	require.NoDirExists(t, filepath.Join(filepath.Dir(local), "..", "..", "docs"))
+
+	_, err = action.Render{Refs: []string{url + "#catalog@v2.0.0"}}.Run(context.Background())
+	require.ErrorContains(t, err, "resolve revision")
+	_, err = action.Render{Refs: []string{url + "#missing"}}.Run(context.Background())
+	require.ErrorContains(t, err, "not found")
+	_, err = action.Render{Refs: []string{url + "#catalog"}, AllowedRefMask: action.RefDCDir}.Run(context.Background())
+	require.ErrorIs(t, err, action.ErrNotAllowed)
+}
@@ -0,0 +1,122 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
+)
+
+const (
+	// HelmChartRefPrefix is the prefix of the references of Helm charts
+	// pushed to registries, as with `helm push`.
+	HelmChartRefPrefix = "oci://"
+
+	helmChartConfigMediaType  = "application/vnd.cncf.helm.config.v1+json"
+	helmChartContentMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+)
+
+// helmChartToDeclcfg pulls the Helm chart at ref and renders it into a
+// helm+v3 bundle, whose image is the reference of the chart.
+func (r Render) helmChartToDeclcfg(ctx context.Context, ref string) (*declcfg.DeclarativeConfig, error) {
+	repo, err := newArtifactRepository(strings.TrimPrefix(ref, HelmChartRefPrefix), r.SkipTLSVerify, r.PlainHTTP)
+	if err != nil {
+		return nil, err
+	}
+	_, manifestData, err := oras.FetchBytes(ctx, repo, repo.Reference.String(), oras.DefaultFetchBytesOptions)
+	if err != nil {
+		return nil, fmt.Errorf("fetch helm chart %q: %v", ref, err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("parse manifest of helm chart %q: %v", ref, err)
+	}
+	if manifest.Config.MediaType != helmChartConfigMediaType {
+		return nil, fmt.Errorf("%q is not a helm chart: config media type %q, expected %q", ref, manifest.Config.MediaType, helmChartConfigMediaType)
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != helmChartContentMediaType {
+			continue
+		}
+		data, err := content.FetchAll(ctx, repo, layer)
+		if err != nil {
+			return nil, fmt.Errorf("fetch content of helm chart %q: %v", ref, err)
+		}
+		chart, err := bundle.ChartArchiveMetadata(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("helm chart %q: %v", ref, err)
+		}
+		return helmChartBundle(chart, repo.Reference.String()), nil
+	}
+	return nil, fmt.Errorf("helm chart %q has no layer with media type %q", ref, helmChartContentMediaType)
+}
+
+// helmChartArchiveToDeclcfg renders the Helm chart archive at path into a
+// helm+v3 bundle. Clients can't pull a chart from a local file, so the image
+// of the bundle is the reference that the image reference template of the
+// render produces for the chart.
+func (r Render) helmChartArchiveToDeclcfg(path string) (*declcfg.DeclarativeConfig, error) {
+	if r.ImageRefTemplate == nil {
+		return nil, fmt.Errorf("rendering helm chart archive %q requires an image reference template", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	chart, err := bundle.ChartArchiveMetadata(f)
+	if err != nil {
+		return nil, fmt.Errorf("helm chart archive %q: %v", path, err)
+	}
+
+	var buf strings.Builder
+	if err := r.ImageRefTemplate.Execute(&buf, imageReferenceTemplateData{
+		Package: chart.Name,
+		Name:    helmChartBundleName(chart),
+		Version: chart.Version,
+	}); err != nil {
+		return nil, fmt.Errorf("failed templating image reference from helm chart archive %q: %v", path, err)
+	}
+	return helmChartBundle(chart, buf.String()), nil
+}
+
+// helmChartBundle returns a declarative config with the helm+v3 bundle of
+// chart, in the package named after the chart.
+func helmChartBundle(chart *bundle.Metadata, image string) *declcfg.DeclarativeConfig {
+	return &declcfg.DeclarativeConfig{Bundles: []declcfg.Bundle{{
+		Schema:  declcfg.SchemaBundle,
+		Name:    helmChartBundleName(chart),
+		Package: chart.Name,
+		Image:   image,
+		Properties: []property.Property{
+			property.MustBuildPackage(chart.Name, chart.Version),
+			property.MustBuildBundleMediaType(property.MediaTypeHelmV3),
+			property.MustBuildHelmChart(property.HelmChart{
+				Name:        chart.Name,
+				Version:     chart.Version,
+				AppVersion:  chart.AppVersion,
+				Description: chart.Description,
+				KubeVersion: chart.KubeVersion,
+				Home:        chart.Home,
+				Icon:        chart.Icon,
+				Deprecated:  chart.Deprecated,
+			}),
+		},
+		RelatedImages: []declcfg.RelatedImage{{Image: image}},
+	}}}
+}
+
+func helmChartBundleName(chart *bundle.Metadata) string {
+	return fmt.Sprintf("%s.v%s", chart.Name, chart.Version)
+}
@@ -0,0 +1,130 @@
+package action_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry/remote"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/internal/testutil/image"
+)
+
+const fooChartfile = `apiVersion: v2
+name: foo
+version: 1.2.3
+appVersion: "4.5"
+description: The foo chart
+kubeVersion: ">=1.25.0-0"
+`
+
+func fooChartArchive(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range map[string]string{
+		"foo/Chart.yaml":  fooChartfile,
+		"foo/values.yaml": "replicas: 1\n",
+	} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data))}))
+		_, err := tw.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func fooChartBundle(image string) declcfg.Bundle {
+	return declcfg.Bundle{
+		Schema:  declcfg.SchemaBundle,
+		Name:    "foo.v1.2.3",
+		Package: "foo",
+		Image:   image,
+		Properties: []property.Property{
+			property.MustBuildPackage("foo", "1.2.3"),
+			property.MustBuildBundleMediaType(property.MediaTypeHelmV3),
+			property.MustBuildHelmChart(property.HelmChart{
+				Name:        "foo",
+				Version:     "1.2.3",
+				AppVersion:  "4.5",
+				Description: "The foo chart",
+				KubeVersion: ">=1.25.0-0",
+			}),
+		},
+		RelatedImages: []declcfg.RelatedImage{{Image: image}},
+	}
+}
+
+func TestRenderHelmChartArchive(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "foo-1.2.3.tgz")
+	require.NoError(t, os.WriteFile(archive, fooChartArchive(t), 0600))
+
+	t.Run("Success", func(t *testing.T) {
+		render := action.Render{
+			Refs:             []string{archive},
+			AllowedRefMask:   action.RefHelmChart,
+			ImageRefTemplate: template.Must(template.New("image").Parse("quay.io/charts/{{.Package}}:{{.Version}}")),
+		}
+		cfg, err := render.Run(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []declcfg.Bundle{fooChartBundle("quay.io/charts/foo:1.2.3")}, cfg.Bundles)
+	})
+	t.Run("Error/NoImageRefTemplate", func(t *testing.T) {
+		_, err := action.Render{Refs: []string{archive}, AllowedRefMask: action.RefHelmChart}.Run(context.Background())
+		require.ErrorContains(t, err, "requires an image reference template")
+	})
+	t.Run("Error/NotAllowed", func(t *testing.T) {
+		_, err := action.Render{Refs: []string{archive}, AllowedRefMask: action.RefSqliteFile}.Run(context.Background())
+		require.ErrorIs(t, err, action.ErrNotAllowed)
+	})
+}
+
+func TestRenderHelmChart(t *testing.T) {
+	ctx := context.Background()
+	server := image.RunDockerRegistry(ctx, "")
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	push := func(t *testing.T, repository, configMediaType string) {
+		t.Helper()
+		repo, err := remote.NewRepository(host + "/" + repository)
+		require.NoError(t, err)
+		repo.Client = server.Client()
+		config, err := oras.PushBytes(ctx, repo, configMediaType, []byte(`{"name":"foo","version":"1.2.3"}`))
+		require.NoError(t, err)
+		layer, err := oras.PushBytes(ctx, repo, "application/vnd.cncf.helm.chart.content.v1.tar+gzip", fooChartArchive(t))
+		require.NoError(t, err)
+		manifest, err := oras.PackManifest(ctx, repo, oras.PackManifestVersion1_1, "", oras.PackManifestOptions{
+			ConfigDescriptor: &config,
+			Layers:           []ocispec.Descriptor{layer},
+		})
+		require.NoError(t, err)
+		require.NoError(t, repo.Tag(ctx, manifest, "1.2.3"))
+	}
+	push(t, "charts/foo", "application/vnd.cncf.helm.config.v1+json")
+	push(t, "charts/notchart", "application/vnd.example.config.v1+json")
+
+	t.Run("Success", func(t *testing.T) {
+		cfg, err := action.Render{Refs: []string{action.HelmChartRefPrefix + host + "/charts/foo:1.2.3"}, SkipTLSVerify: true}.Run(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []declcfg.Bundle{fooChartBundle(host + "/charts/foo:1.2.3")}, cfg.Bundles)
+	})
+	t.Run("Error/NotAChart", func(t *testing.T) {
+		_, err := action.Render{Refs: []string{action.HelmChartRefPrefix + host + "/charts/notchart:1.2.3"}, SkipTLSVerify: true}.Run(ctx)
+		require.ErrorContains(t, err, `is not a helm chart: config media type "application/vnd.example.config.v1+json"`)
+	})
+}
@@ -10,11 +10,14 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"k8s.io/apimachinery/pkg/util/sets"
+
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
 	"github.com/operator-framework/operator-registry/alpha/model"
 	"github.com/operator-framework/operator-registry/pkg/image"
+	"github.com/operator-framework/operator-registry/pkg/mirror"
 )
 
 type ListPackages struct {
@@ -183,6 +186,132 @@ func (r *ListBundlesResult) WriteColumns(w io.Writer) error {
 	return tw.Flush()
 }
 
+type ListImages struct {
+	IndexReference string
+	PackageName    string
+	// MirrorMap, if set, maps the listed images to their mirrors.
+	MirrorMap mirror.Mappings
+	Registry  image.Registry
+}
+
+func (l *ListImages) Run(ctx context.Context) (*ListImagesResult, error) {
+	m, err := indexRefToModel(ctx, l.IndexReference, l.Registry)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgs, err := getPackages(m, l.PackageName)
+	if err != nil {
+		return nil, err
+	}
+
+	images := []ListedImage{}
+	for _, pkg := range pkgs {
+		bundles := map[string]*model.Bundle{}
+		for _, ch := range pkg.Channels {
+			for _, b := range ch.Bundles {
+				bundles[b.Name] = b
+			}
+		}
+		for _, b := range bundles {
+			seen := sets.New[string]()
+			add := func(name, img string) {
+				if img == "" || seen.Has(img) {
+					return
+				}
+				seen.Insert(img)
+				mirrored, ok := l.MirrorMap.Mirror(img)
+				if !ok {
+					mirrored = ""
+				}
+				images = append(images, ListedImage{Package: pkg.Name, Bundle: b.Name, Name: name, Image: img, Mirror: mirrored})
+			}
+			for _, ri := range b.RelatedImages {
+				add(ri.Name, ri.Image)
+			}
+			add("", b.Image)
+		}
+	}
+
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].Package != images[j].Package {
+			return images[i].Package < images[j].Package
+		}
+		if images[i].Bundle != images[j].Bundle {
+			return images[i].Bundle < images[j].Bundle
+		}
+		return images[i].Image < images[j].Image
+	})
+	return &ListImagesResult{Images: images, MirrorMap: l.MirrorMap}, nil
+}
+
+// ListedImage is an image of a bundle. Name is the name of the related image
+// entry of the image, which is empty for the bundle image. Mirror is the
+// reference of the image in its mirror, if it has one.
+type ListedImage struct {
+	Package string
+	Bundle  string
+	Name    string
+	Image   string
+	Mirror  string
+}
+
+type ListImagesResult struct {
+	Images    []ListedImage
+	MirrorMap mirror.Mappings
+}
+
+func (r *ListImagesResult) WriteColumns(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	header := "PACKAGE\tBUNDLE\tNAME\tIMAGE"
+	if len(r.MirrorMap) > 0 {
+		header += "\tMIRROR"
+	}
+	if _, err := fmt.Fprintln(tw, header); err != nil {
+		return err
+	}
+	for _, img := range r.Images {
+		line := fmt.Sprintf("%s\t%s\t%s\t%s", img.Package, img.Bundle, img.Name, img.Image)
+		if len(r.MirrorMap) > 0 {
+			line += "\t" + img.Mirror
+		}
+		if _, err := fmt.Fprintln(tw, line); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// WriteICSP writes an ImageContentSourcePolicy named name that mirrors the
+// repositories of the listed images.
+func (r *ListImagesResult) WriteICSP(w io.Writer, name string) error {
+	data, err := r.MirrorMap.GenerateICSP(name, r.imageRefs())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// WriteIDMS writes an ImageDigestMirrorSet named name that mirrors the
+// repositories of the listed images.
+func (r *ListImagesResult) WriteIDMS(w io.Writer, name string) error {
+	data, err := r.MirrorMap.GenerateIDMS(name, r.imageRefs())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (r *ListImagesResult) imageRefs() []string {
+	refs := make([]string, 0, len(r.Images))
+	for _, img := range r.Images {
+		refs = append(refs, img.Image)
+	}
+	return refs
+}
+
 func indexRefToModel(ctx context.Context, ref string, reg image.Registry) (model.Model, error) {
 	render := Render{
 		Refs:           []string{ref},
@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/pkg/mirror"
 )
 
 func TestListPackages(t *testing.T) {
@@ -164,3 +166,90 @@ foo      stable   foo.v0.2.0  foo.v0.1.0  foo.v0.1.1,foo.v0.1.2  <0.2.0      tes
 		})
 	}
 }
+
+func TestListImages(t *testing.T) {
+	type spec struct {
+		name        string
+		list        ListImages
+		expectedOut string
+		expectedErr string
+	}
+	specs := []spec{
+		{
+			name: "Success/WithPackage",
+			list: ListImages{IndexReference: "testdata/list-index", PackageName: "foo"},
+			expectedOut: `PACKAGE  BUNDLE      NAME      IMAGE
+foo      foo.v0.1.0            test.registry/foo-operator/foo-bundle:v0.1.0
+foo      foo.v0.1.0  operator  test.registry/foo-operator/foo:v0.1.0
+foo      foo.v0.2.0            test.registry/foo-operator/foo-bundle:v0.2.0
+foo      foo.v0.2.0  operator  test.registry/foo-operator/foo:v0.2.0
+`,
+		},
+		{
+			name: "Success/WithMirrorMap",
+			list: ListImages{
+				IndexReference: "testdata/list-index",
+				MirrorMap:      mirror.Mappings{{Source: "test.registry/foo-operator", Mirror: "mirror.example.com/foo"}},
+			},
+			expectedOut: `PACKAGE  BUNDLE      NAME      IMAGE                                         MIRROR
+bar      bar.v0.1.0            test.registry/bar-operator/bar-bundle:v0.1.0  
+bar      bar.v0.1.0  operator  test.registry/bar-operator/bar:v0.1.0         
+bar      bar.v0.2.0            test.registry/bar-operator/bar-bundle:v0.2.0  
+bar      bar.v0.2.0  operator  test.registry/bar-operator/bar:v0.2.0         
+foo      foo.v0.1.0            test.registry/foo-operator/foo-bundle:v0.1.0  mirror.example.com/foo/foo-bundle:v0.1.0
+foo      foo.v0.1.0  operator  test.registry/foo-operator/foo:v0.1.0         mirror.example.com/foo/foo:v0.1.0
+foo      foo.v0.2.0            test.registry/foo-operator/foo-bundle:v0.2.0  mirror.example.com/foo/foo-bundle:v0.2.0
+foo      foo.v0.2.0  operator  test.registry/foo-operator/foo:v0.2.0         mirror.example.com/foo/foo:v0.2.0
+`,
+		},
+		{
+			name:        "Error/UnknownPackage",
+			list:        ListImages{IndexReference: "testdata/list-index", PackageName: "unknown"},
+			expectedErr: `package "unknown" not found`,
+		},
+	}
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			res, err := s.list.Run(context.Background())
+			if s.expectedErr != "" {
+				require.Nil(t, res)
+				require.EqualError(t, err, s.expectedErr)
+			} else {
+				require.NoError(t, err)
+
+				buf := &bytes.Buffer{}
+				err = res.WriteColumns(buf)
+				require.NoError(t, err)
+
+				require.Equal(t, s.expectedOut, buf.String())
+			}
+		})
+	}
+}
+
+func TestListImagesResultWriteIDMS(t *testing.T) {
+	res, err := (&ListImages{
+		IndexReference: "testdata/list-index",
+		PackageName:    "foo",
+		MirrorMap:      mirror.Mappings{{Source: "test.registry", Mirror: "mirror.example.com"}},
+	}).Run(context.Background())
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, res.WriteIDMS(buf, "foo-catalog"))
+	require.Equal(t, `apiVersion: config.openshift.io/v1
+kind: ImageDigestMirrorSet
+metadata:
+  labels:
+    operators.openshift.org/catalog: "true"
+  name: foo-catalog
+spec:
+  imageDigestMirrors:
+  - mirrors:
+    - mirror.example.com/foo-operator/foo
+    source: test.registry/foo-operator/foo
+  - mirrors:
+    - mirror.example.com/foo-operator/foo-bundle
+    source: test.registry/foo-operator/foo-bundle
+`, buf.String())
+}
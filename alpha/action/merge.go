@@ -0,0 +1,297 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/blang/semver/v4"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/pkg/image"
+)
+
+// MergeStrategy determines how Merge resolves a package that is defined in
+// more than one of the merged catalogs.
+type MergeStrategy string
+
+const (
+	// MergeStrategyError fails the merge if a package is defined in more than
+	// one catalog.
+	MergeStrategyError MergeStrategy = "error"
+	// MergeStrategyPreferFirst uses the package from the first catalog that
+	// defines it.
+	MergeStrategyPreferFirst MergeStrategy = "prefer-first"
+	// MergeStrategyPreferNewest uses the package from the catalog that
+	// contains its highest bundle version. Ties are resolved in favor of the
+	// earlier catalog.
+	MergeStrategyPreferNewest MergeStrategy = "prefer-newest"
+)
+
+// Merge combines the catalogs referenced by Refs into a single declarative
+// config. Each package in the result is taken in its entirety from exactly
+// one of the catalogs, as chosen by Strategy. The result is sorted so that
+// the output does not depend on the order in which the catalogs were loaded.
+// An olm.catalog blob is carried over only if all catalogs that define one
+// agree on its content.
+type Merge struct {
+	Refs     []string
+	Strategy MergeStrategy
+	Registry image.Registry
+}
+
+func (m Merge) Run(ctx context.Context) (*declcfg.DeclarativeConfig, error) {
+	if len(m.Refs) == 0 {
+		return nil, fmt.Errorf("at least one catalog must be specified")
+	}
+	strategy := m.Strategy
+	if strategy == "" {
+		strategy = MergeStrategyError
+	}
+	switch strategy {
+	case MergeStrategyError, MergeStrategyPreferFirst, MergeStrategyPreferNewest:
+	default:
+		return nil, fmt.Errorf("unknown merge strategy %q, expected one of %q, %q, or %q", strategy, MergeStrategyError, MergeStrategyPreferFirst, MergeStrategyPreferNewest)
+	}
+
+	cfgs := make([]declcfg.DeclarativeConfig, 0, len(m.Refs))
+	for _, ref := range m.Refs {
+		r := Render{
+			Refs:     []string{ref},
+			Registry: m.Registry,
+
+			// Only allow catalogs to be merged.
+			AllowedRefMask: RefSqliteImage | RefSqliteFile | RefDCImage | RefDCDir,
+		}
+		cfg, err := r.Run(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("render catalog %q: %v", ref, err)
+		}
+		cfgs = append(cfgs, *cfg)
+	}
+
+	merged, err := mergeConfigs(m.Refs, cfgs, strategy)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := declcfg.ConvertToModel(*merged); err != nil {
+		return nil, fmt.Errorf("merged catalog is invalid: %v", err)
+	}
+	return merged, nil
+}
+
+func mergeConfigs(refs []string, cfgs []declcfg.DeclarativeConfig, strategy MergeStrategy) (*declcfg.DeclarativeConfig, error) {
+	byPackage := make([]map[string]*declcfg.DeclarativeConfig, len(cfgs))
+	out := &declcfg.DeclarativeConfig{}
+	for i, cfg := range cfgs {
+		pkgs, global := splitByPackage(cfg)
+		byPackage[i] = pkgs
+		for _, o := range global {
+			if !containsMeta(out.Others, o) {
+				out.Others = append(out.Others, o)
+			}
+		}
+		for _, e := range cfg.Extensions {
+			if e.Package == "" && !containsMeta(extensionMetas(out.Extensions), e.Meta) {
+				out.Extensions = append(out.Extensions, e)
+			}
+		}
+		if err := mergeCatalogMetadata(out, refs, i, cfg.Catalogs); err != nil {
+			return nil, err
+		}
+	}
+
+	packageNames := sets.New[string]()
+	for _, pkgs := range byPackage {
+		for name := range pkgs {
+			packageNames.Insert(name)
+		}
+	}
+
+	for _, name := range sets.List(packageNames) {
+		var sources []int
+		for i, pkgs := range byPackage {
+			if _, ok := pkgs[name]; ok {
+				sources = append(sources, i)
+			}
+		}
+
+		chosen := sources[0]
+		if len(sources) > 1 {
+			switch strategy {
+			case MergeStrategyError:
+				return nil, fmt.Errorf("package %q is defined in both %q and %q", name, refs[sources[0]], refs[sources[1]])
+			case MergeStrategyPreferNewest:
+				var newest *semver.Version
+				for _, i := range sources {
+					v, err := maxBundleVersion(*byPackage[i][name])
+					if err != nil {
+						return nil, fmt.Errorf("package %q in %q: %v", name, refs[i], err)
+					}
+					if newest == nil || (v != nil && v.GT(*newest)) {
+						chosen, newest = i, v
+					}
+				}
+			}
+		}
+
+		pkg := byPackage[chosen][name]
+		out.Packages = append(out.Packages, pkg.Packages...)
+		out.Channels = append(out.Channels, pkg.Channels...)
+		out.Bundles = append(out.Bundles, pkg.Bundles...)
+		out.Deprecations = append(out.Deprecations, pkg.Deprecations...)
+		out.Icons = append(out.Icons, pkg.Icons...)
+		out.Extensions = append(out.Extensions, pkg.Extensions...)
+		out.Others = append(out.Others, pkg.Others...)
+	}
+
+	sortConfig(out)
+	return out, nil
+}
+
+// mergeCatalogMetadata adds the olm.catalog blobs of the catalog at index i to
+// out. The merged catalog keeps an olm.catalog blob only if every catalog that
+// defines one defines the same blob; catalogs with different metadata can not
+// be merged without deciding which metadata describes the result, so that is
+// an error.
+func mergeCatalogMetadata(out *declcfg.DeclarativeConfig, refs []string, i int, catalogs []declcfg.Catalog) error {
+	for _, c := range catalogs {
+		if len(out.Catalogs) == 0 {
+			out.Catalogs = append(out.Catalogs, c)
+			continue
+		}
+		if !reflect.DeepEqual(out.Catalogs[0], c) {
+			return fmt.Errorf("catalog %q defines %s blob %q, which conflicts with the %s blob %q of a previous catalog", refs[i], declcfg.SchemaCatalog, c.Name, declcfg.SchemaCatalog, out.Catalogs[0].Name)
+		}
+	}
+	return nil
+}
+
+// splitByPackage groups the blobs of cfg by package. Blobs of unknown schemas
+// that do not belong to a package are returned separately, and schema
+// extensions that do not belong to a package are left out.
+func splitByPackage(cfg declcfg.DeclarativeConfig) (map[string]*declcfg.DeclarativeConfig, []declcfg.Meta) {
+	pkgs := map[string]*declcfg.DeclarativeConfig{}
+	get := func(name string) *declcfg.DeclarativeConfig {
+		if _, ok := pkgs[name]; !ok {
+			pkgs[name] = &declcfg.DeclarativeConfig{}
+		}
+		return pkgs[name]
+	}
+	for _, p := range cfg.Packages {
+		get(p.Name).Packages = append(get(p.Name).Packages, p)
+	}
+	for _, c := range cfg.Channels {
+		get(c.Package).Channels = append(get(c.Package).Channels, c)
+	}
+	for _, b := range cfg.Bundles {
+		get(b.Package).Bundles = append(get(b.Package).Bundles, b)
+	}
+	for _, d := range cfg.Deprecations {
+		get(d.Package).Deprecations = append(get(d.Package).Deprecations, d)
+	}
+	for _, i := range cfg.Icons {
+		get(i.Package).Icons = append(get(i.Package).Icons, i)
+	}
+	for _, e := range cfg.Extensions {
+		if e.Package != "" {
+			get(e.Package).Extensions = append(get(e.Package).Extensions, e)
+		}
+	}
+	var global []declcfg.Meta
+	for _, o := range cfg.Others {
+		if o.Package == "" {
+			global = append(global, o)
+			continue
+		}
+		get(o.Package).Others = append(get(o.Package).Others, o)
+	}
+	return pkgs, global
+}
+
+// containsMeta reports whether metas contains a blob that is identical to m.
+func containsMeta(metas []declcfg.Meta, m declcfg.Meta) bool {
+	for _, o := range metas {
+		if o.Schema == m.Schema && o.Package == m.Package && o.Name == m.Name && bytes.Equal(o.Blob, m.Blob) {
+			return true
+		}
+	}
+	return false
+}
+
+func extensionMetas(extensions []declcfg.Extension) []declcfg.Meta {
+	metas := make([]declcfg.Meta, 0, len(extensions))
+	for _, e := range extensions {
+		metas = append(metas, e.Meta)
+	}
+	return metas
+}
+
+// maxBundleVersion returns the highest version of the bundles in cfg, or nil
+// if cfg has no bundles.
+func maxBundleVersion(cfg declcfg.DeclarativeConfig) (*semver.Version, error) {
+	var maxVersion *semver.Version
+	for _, b := range cfg.Bundles {
+		props, err := property.Parse(b.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("parse properties for bundle %q: %v", b.Name, err)
+		}
+		if len(props.Packages) != 1 {
+			return nil, fmt.Errorf("bundle %q has %d %q properties, expected exactly 1", b.Name, len(props.Packages), property.TypePackage)
+		}
+		v, err := semver.Parse(props.Packages[0].Version)
+		if err != nil {
+			return nil, fmt.Errorf("bundle %q has invalid version %q: %v", b.Name, props.Packages[0].Version, err)
+		}
+		if maxVersion == nil || v.GT(*maxVersion) {
+			maxVersion = &v
+		}
+	}
+	return maxVersion, nil
+}
+
+func sortConfig(cfg *declcfg.DeclarativeConfig) {
+	sort.SliceStable(cfg.Packages, func(i, j int) bool {
+		return cfg.Packages[i].Name < cfg.Packages[j].Name
+	})
+	sort.SliceStable(cfg.Channels, func(i, j int) bool {
+		if cfg.Channels[i].Package != cfg.Channels[j].Package {
+			return cfg.Channels[i].Package < cfg.Channels[j].Package
+		}
+		return cfg.Channels[i].Name < cfg.Channels[j].Name
+	})
+	sort.SliceStable(cfg.Bundles, func(i, j int) bool {
+		if cfg.Bundles[i].Package != cfg.Bundles[j].Package {
+			return cfg.Bundles[i].Package < cfg.Bundles[j].Package
+		}
+		return cfg.Bundles[i].Name < cfg.Bundles[j].Name
+	})
+	sort.SliceStable(cfg.Deprecations, func(i, j int) bool {
+		return cfg.Deprecations[i].Package < cfg.Deprecations[j].Package
+	})
+	sort.SliceStable(cfg.Icons, func(i, j int) bool {
+		return cfg.Icons[i].Package < cfg.Icons[j].Package
+	})
+	sort.SliceStable(cfg.Extensions, func(i, j int) bool {
+		if cfg.Extensions[i].Package != cfg.Extensions[j].Package {
+			return cfg.Extensions[i].Package < cfg.Extensions[j].Package
+		}
+		if cfg.Extensions[i].Schema != cfg.Extensions[j].Schema {
+			return cfg.Extensions[i].Schema < cfg.Extensions[j].Schema
+		}
+		return cfg.Extensions[i].Name < cfg.Extensions[j].Name
+	})
+	sort.SliceStable(cfg.Others, func(i, j int) bool {
+		if cfg.Others[i].Package != cfg.Others[j].Package {
+			return cfg.Others[i].Package < cfg.Others[j].Package
+		}
+		if cfg.Others[i].Schema != cfg.Others[j].Schema {
+			return cfg.Others[i].Schema < cfg.Others[j].Schema
+		}
+		return cfg.Others[i].Name < cfg.Others[j].Name
+	})
+}
@@ -0,0 +1,207 @@
+package action_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+)
+
+func mergeTestPackage(pkg, version string) string {
+	return fmt.Sprintf(`---
+schema: olm.package
+name: %[1]s
+defaultChannel: stable
+---
+schema: olm.channel
+package: %[1]s
+name: stable
+entries:
+  - name: %[1]s.v%[2]s
+---
+schema: olm.bundle
+package: %[1]s
+name: %[1]s.v%[2]s
+image: test.registry/%[1]s-operator/%[1]s-bundle:v%[2]s
+properties:
+  - type: olm.package
+    value:
+      packageName: %[1]s
+      version: %[2]s
+`, pkg, version)
+}
+
+func writeMergeTestCatalogs(t *testing.T, catalogs ...string) []string {
+	root := t.TempDir()
+	var refs []string
+	for i, catalog := range catalogs {
+		dir := filepath.Join(root, fmt.Sprintf("catalog-%d", i))
+		require.NoError(t, os.MkdirAll(dir, 0777))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "catalog.yaml"), []byte(catalog), 0600))
+		refs = append(refs, dir)
+	}
+	return refs
+}
+
+func TestMerge(t *testing.T) {
+	type spec struct {
+		name            string
+		strategy        action.MergeStrategy
+		expectedBundles []string
+		expectErr       string
+	}
+
+	specs := []spec{
+		{
+			name:      "Error/DefaultStrategy",
+			expectErr: `package "foo" is defined in both`,
+		},
+		{
+			name:            "Success/PreferFirst",
+			strategy:        action.MergeStrategyPreferFirst,
+			expectedBundles: []string{"bar.v0.1.0", "baz.v1.0.0", "foo.v0.2.0"},
+		},
+		{
+			name:            "Success/PreferNewest",
+			strategy:        action.MergeStrategyPreferNewest,
+			expectedBundles: []string{"bar.v0.1.0", "baz.v1.0.0", "foo.v0.10.0"},
+		},
+		{
+			name:      "Error/UnknownStrategy",
+			strategy:  "prefer-last",
+			expectErr: `unknown merge strategy "prefer-last"`,
+		},
+	}
+
+	catalogs := map[string]string{
+		"a": mergeTestPackage("foo", "0.2.0") + mergeTestPackage("bar", "0.1.0"),
+		"b": mergeTestPackage("foo", "0.10.0") + mergeTestPackage("baz", "1.0.0"),
+	}
+	root := t.TempDir()
+	var refs []string
+	for _, name := range []string{"a", "b"} {
+		dir := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(dir, 0777))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "catalog.yaml"), []byte(catalogs[name]), 0600))
+		refs = append(refs, dir)
+	}
+
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			m := action.Merge{Refs: refs, Strategy: s.strategy}
+			cfg, err := m.Run(context.Background())
+			if s.expectErr != "" {
+				require.ErrorContains(t, err, s.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var packages, bundles []string
+			for _, p := range cfg.Packages {
+				packages = append(packages, p.Name)
+			}
+			for _, b := range cfg.Bundles {
+				bundles = append(bundles, b.Name)
+			}
+			require.Equal(t, []string{"bar", "baz", "foo"}, packages)
+			require.Equal(t, s.expectedBundles, bundles)
+			require.Len(t, cfg.Channels, 3)
+		})
+	}
+}
+
+func TestMergeCatalogMetadata(t *testing.T) {
+	const (
+		catalogA = `---
+schema: olm.catalog
+name: catalog-a
+publisher: Example
+`
+		catalogB = `---
+schema: olm.catalog
+name: catalog-b
+publisher: Example
+`
+	)
+
+	type spec struct {
+		name             string
+		catalogs         []string
+		expectedCatalogs []string
+		expectErr        string
+	}
+
+	specs := []spec{
+		{
+			name:     "Success/NoMetadata",
+			catalogs: []string{mergeTestPackage("foo", "0.1.0"), mergeTestPackage("bar", "0.1.0")},
+		},
+		{
+			name:             "Success/OneCatalogHasMetadata",
+			catalogs:         []string{mergeTestPackage("foo", "0.1.0"), catalogA + mergeTestPackage("bar", "0.1.0")},
+			expectedCatalogs: []string{"catalog-a"},
+		},
+		{
+			name:             "Success/IdenticalMetadata",
+			catalogs:         []string{catalogA + mergeTestPackage("foo", "0.1.0"), catalogA + mergeTestPackage("bar", "0.1.0")},
+			expectedCatalogs: []string{"catalog-a"},
+		},
+		{
+			name:      "Error/ConflictingMetadata",
+			catalogs:  []string{catalogA + mergeTestPackage("foo", "0.1.0"), catalogB + mergeTestPackage("bar", "0.1.0")},
+			expectErr: `defines olm.catalog blob "catalog-b", which conflicts with the olm.catalog blob "catalog-a"`,
+		},
+	}
+
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			refs := writeMergeTestCatalogs(t, s.catalogs...)
+			cfg, err := action.Merge{Refs: refs}.Run(context.Background())
+			if s.expectErr != "" {
+				require.ErrorContains(t, err, s.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var catalogs []string
+			for _, c := range cfg.Catalogs {
+				catalogs = append(catalogs, c.Name)
+			}
+			require.Equal(t, s.expectedCatalogs, catalogs)
+		})
+	}
+}
+
+func TestMergeDeterministic(t *testing.T) {
+	const global = `---
+schema: example.global
+name: shared
+value: 1
+`
+	t.Run("Error/ReportsFirstConflictingPackage", func(t *testing.T) {
+		refs := writeMergeTestCatalogs(t,
+			mergeTestPackage("foo", "0.1.0")+mergeTestPackage("bar", "0.1.0"),
+			mergeTestPackage("foo", "0.2.0")+mergeTestPackage("bar", "0.2.0"),
+		)
+		for i := 0; i < 10; i++ {
+			_, err := action.Merge{Refs: refs}.Run(context.Background())
+			require.ErrorContains(t, err, `package "bar" is defined in both`)
+		}
+	})
+
+	t.Run("Success/DeduplicatesGlobalBlobs", func(t *testing.T) {
+		refs := writeMergeTestCatalogs(t,
+			global+mergeTestPackage("foo", "0.1.0"),
+			global+mergeTestPackage("bar", "0.1.0"),
+		)
+		cfg, err := action.Merge{Refs: refs}.Run(context.Background())
+		require.NoError(t, err)
+		require.Len(t, cfg.Others, 1)
+		require.Equal(t, "example.global", cfg.Others[0].Schema)
+	})
+}
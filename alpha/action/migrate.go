@@ -14,10 +14,19 @@ type Migrate struct {
 	CatalogRef string
 	OutputDir  string
 	Migrations *migrations.Migrations
+	// DeduplicateBundles and OnDuplicateBundle are passed to the Render
+	// that renders CatalogRef.
+	DeduplicateBundles bool
+	OnDuplicateBundle  func(DuplicateBundle)
+	// EdgeProvenance is passed to the Render that renders CatalogRef.
+	EdgeProvenance bool
 
 	WriteFunc declcfg.WriteFunc
 	FileExt   string
-	Registry  image.Registry
+	// WriteFSOptions configure the layout of the files of the migrated
+	// catalog, such as the sharding of the bundles of large packages.
+	WriteFSOptions []declcfg.WriteFSOption
+	Registry       image.Registry
 }
 
 func (m Migrate) Run(ctx context.Context) error {
@@ -33,6 +42,10 @@ func (m Migrate) Run(ctx context.Context) error {
 		Refs:       []string{m.CatalogRef},
 		Migrations: m.Migrations,
 
+		DeduplicateBundles: m.DeduplicateBundles,
+		OnDuplicateBundle:  m.OnDuplicateBundle,
+		EdgeProvenance:     m.EdgeProvenance,
+
 		// Only allow catalogs to be migrated.
 		AllowedRefMask: RefSqliteImage | RefSqliteFile | RefDCImage | RefDCDir,
 	}
@@ -45,5 +58,5 @@ func (m Migrate) Run(ctx context.Context) error {
 		return fmt.Errorf("render catalog image: %w", err)
 	}
 
-	return declcfg.WriteFS(*cfg, m.OutputDir, m.WriteFunc, m.FileExt)
+	return declcfg.WriteFS(*cfg, m.OutputDir, m.WriteFunc, m.FileExt, m.WriteFSOptions...)
 }
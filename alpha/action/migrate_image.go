@@ -0,0 +1,113 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/operator-framework/operator-registry/alpha/action/migrations"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/image"
+)
+
+const (
+	migrateImageCatalogDir = "catalog"
+	migrateImageDockerfile = "catalog.Dockerfile"
+)
+
+// ImageBuilder builds and pushes container images, e.g. with the docker or
+// podman CLI, as containertools.ContainerCommandRunner does.
+type ImageBuilder interface {
+	// BuildDir builds the image tagged tag from the dockerfile in contextDir.
+	BuildDir(contextDir, dockerfile, tag string) error
+	Push(image string) error
+}
+
+// MigrateImage migrates a sqlite-based index image to a file-based catalog
+// image in one step. It migrates the index to a file-based catalog, as Migrate
+// does, generates a Dockerfile that adds the catalog and its pre-built serve
+// cache to BaseImage, as GenerateDockerfile does, builds the image tagged
+// DestRef with Builder, and pushes it if Push is set.
+type MigrateImage struct {
+	SourceRef string
+	DestRef   string
+	// Migrations are run on the catalog, as they are by Migrate.
+	Migrations *migrations.Migrations
+
+	BaseImage    string
+	BuilderImage string
+	// ContextDir is the directory the build context, i.e. the catalog in
+	// catalog/ and its catalog.Dockerfile, is written to. It must not exist
+	// or be empty. If unset, the build context is written to a temporary
+	// directory that is removed once the image is built.
+	ContextDir string
+
+	Push     bool
+	Builder  ImageBuilder
+	Registry image.Registry
+}
+
+func (m MigrateImage) Run(ctx context.Context) error {
+	if m.SourceRef == "" || m.DestRef == "" {
+		return errors.New("source and destination references must be set")
+	}
+	if m.Builder == nil {
+		return errors.New("image builder must be set")
+	}
+
+	contextDir := m.ContextDir
+	if contextDir == "" {
+		var err error
+		if contextDir, err = os.MkdirTemp("", "opm-migrate-image-"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(contextDir)
+	} else if entries, err := os.ReadDir(contextDir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("context directory %q is not empty", contextDir)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	migrate := Migrate{
+		CatalogRef: m.SourceRef,
+		OutputDir:  filepath.Join(contextDir, migrateImageCatalogDir),
+		Migrations: m.Migrations,
+		WriteFunc:  declcfg.WriteJSON,
+		FileExt:    ".json",
+		Registry:   m.Registry,
+	}
+	if err := migrate.Run(ctx); err != nil {
+		return fmt.Errorf("migrate %q: %w", m.SourceRef, err)
+	}
+
+	dockerfile := filepath.Join(contextDir, migrateImageDockerfile)
+	f, err := os.Create(dockerfile)
+	if err != nil {
+		return err
+	}
+	gen := GenerateDockerfile{
+		BaseImage:    m.BaseImage,
+		BuilderImage: m.BuilderImage,
+		IndexDir:     migrateImageCatalogDir,
+		Writer:       f,
+	}
+	if err := gen.Run(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("generate dockerfile: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := m.Builder.BuildDir(contextDir, dockerfile, m.DestRef); err != nil {
+		return fmt.Errorf("build %q: %w", m.DestRef, err)
+	}
+	if m.Push {
+		if err := m.Builder.Push(m.DestRef); err != nil {
+			return fmt.Errorf("push %q: %w", m.DestRef, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package action_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/pkg/image"
+)
+
+type fakeImageBuilder struct {
+	contextDir, dockerfile, tag string
+	pushed                      []string
+	pushErr                     error
+}
+
+func (b *fakeImageBuilder) BuildDir(contextDir, dockerfile, tag string) error {
+	b.contextDir, b.dockerfile, b.tag = contextDir, dockerfile, tag
+	return nil
+}
+
+func (b *fakeImageBuilder) Push(image string) error {
+	b.pushed = append(b.pushed, image)
+	return b.pushErr
+}
+
+func TestMigrateImage(t *testing.T) {
+	sqliteBundles := map[image.Reference]string{
+		image.SimpleReference("test.registry/foo-operator/foo-bundle:v0.1.0"): "testdata/foo-bundle-v0.1.0",
+		image.SimpleReference("test.registry/foo-operator/foo-bundle:v0.2.0"): "testdata/foo-bundle-v0.2.0",
+		image.SimpleReference("test.registry/bar-operator/bar-bundle:v0.1.0"): "testdata/bar-bundle-v0.1.0",
+		image.SimpleReference("test.registry/bar-operator/bar-bundle:v0.2.0"): "testdata/bar-bundle-v0.2.0",
+	}
+	reg, err := newMigrateRegistry(t, sqliteBundles)
+	require.NoError(t, err)
+
+	const (
+		sourceRef = "test.registry/migrate/catalog:sqlite"
+		destRef   = "test.registry/migrate/catalog:fbc"
+	)
+
+	t.Run("ContextDir", func(t *testing.T) {
+		builder := &fakeImageBuilder{}
+		contextDir := filepath.Join(t.TempDir(), "context")
+		m := action.MigrateImage{
+			SourceRef:    sourceRef,
+			DestRef:      destRef,
+			BaseImage:    "quay.io/operator-framework/opm:latest",
+			BuilderImage: "quay.io/operator-framework/opm:latest",
+			ContextDir:   contextDir,
+			Push:         true,
+			Builder:      builder,
+			Registry:     reg,
+		}
+		require.NoError(t, m.Run(context.Background()))
+
+		require.Equal(t, contextDir, builder.contextDir)
+		require.Equal(t, filepath.Join(contextDir, "catalog.Dockerfile"), builder.dockerfile)
+		require.Equal(t, destRef, builder.tag)
+		require.Equal(t, []string{destRef}, builder.pushed)
+
+		dockerfile, err := os.ReadFile(builder.dockerfile)
+		require.NoError(t, err)
+		require.Contains(t, string(dockerfile), "ADD catalog /configs")
+		for _, pkg := range []string{"foo", "bar"} {
+			require.FileExists(t, filepath.Join(contextDir, "catalog", pkg, "catalog.json"))
+		}
+	})
+
+	t.Run("TemporaryContextDir", func(t *testing.T) {
+		builder := &fakeImageBuilder{}
+		m := action.MigrateImage{
+			SourceRef:    sourceRef,
+			DestRef:      destRef,
+			BaseImage:    "quay.io/operator-framework/opm:latest",
+			BuilderImage: "quay.io/operator-framework/opm:latest",
+			Builder:      builder,
+			Registry:     reg,
+		}
+		require.NoError(t, m.Run(context.Background()))
+		require.Equal(t, destRef, builder.tag)
+		require.Empty(t, builder.pushed)
+		require.NoDirExists(t, builder.contextDir)
+	})
+
+	t.Run("NonEmptyContextDir", func(t *testing.T) {
+		contextDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(contextDir, "file"), nil, 0600))
+		m := action.MigrateImage{
+			SourceRef:  sourceRef,
+			DestRef:    destRef,
+			BaseImage:  "quay.io/operator-framework/opm:latest",
+			ContextDir: contextDir,
+			Builder:    &fakeImageBuilder{},
+			Registry:   reg,
+		}
+		require.EqualError(t, m.Run(context.Background()), `context directory "`+contextDir+`" is not empty`)
+	})
+
+	t.Run("PushFailure", func(t *testing.T) {
+		pushErr := errors.New("unauthorized")
+		m := action.MigrateImage{
+			SourceRef:    sourceRef,
+			DestRef:      destRef,
+			BaseImage:    "quay.io/operator-framework/opm:latest",
+			BuilderImage: "quay.io/operator-framework/opm:latest",
+			Push:         true,
+			Builder:      &fakeImageBuilder{pushErr: pushErr},
+			Registry:     reg,
+		}
+		require.ErrorIs(t, m.Run(context.Background()), pushErr)
+	})
+}
@@ -141,6 +141,38 @@ func TestMigrate(t *testing.T) {
 	}
 }
 
+func TestMigrateEdgeProvenance(t *testing.T) {
+	sqliteBundles := map[image.Reference]string{
+		image.SimpleReference("test.registry/foo-operator/foo-bundle:v0.1.0"): "testdata/foo-bundle-v0.1.0",
+		image.SimpleReference("test.registry/foo-operator/foo-bundle:v0.2.0"): "testdata/foo-bundle-v0.2.0",
+	}
+	dbFile := filepath.Join(t.TempDir(), "index.db")
+	require.NoError(t, generateSqliteFile(dbFile, sqliteBundles))
+
+	outputDir := t.TempDir()
+	m := action.Migrate{
+		CatalogRef:     dbFile,
+		OutputDir:      outputDir,
+		EdgeProvenance: true,
+		WriteFunc:      declcfg.WriteJSON,
+		FileExt:        ".json",
+		Registry:       &image.MockRegistry{},
+	}
+	require.NoError(t, m.Run(context.Background()))
+
+	cfg, err := declcfg.LoadFS(context.Background(), os.DirFS(outputDir))
+	require.NoError(t, err)
+	require.Len(t, cfg.Channels, 2)
+	for _, ch := range cfg.Channels {
+		require.Len(t, ch.Properties, 1)
+		require.Equal(t, action.TypeEdgeProvenance, ch.Properties[0].Type)
+		require.JSONEq(t, `{"entries":[
+			{"name":"foo.v0.1.0","reachedBy":["replaces","skipRange"]},
+			{"name":"foo.v0.2.0","replaces":"csv"}
+		]}`, string(ch.Properties[0].Value))
+	}
+}
+
 func newMigrateRegistry(t *testing.T, imageMap map[image.Reference]string) (image.Registry, error) {
 	subSqliteImage, err := generateSqliteFS(t, imageMap)
 	if err != nil {
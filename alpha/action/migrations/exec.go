@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+// NewExecMigration returns a migration that pipes the catalog, as streamed
+// JSON blobs, through an external command, and replaces it with the
+// declarative config the command writes to its standard output. This allows
+// organization-specific migrations without changes to opm.
+//
+// The command is split on whitespace into an executable and its arguments;
+// it is not run in a shell. Its standard error is passed through.
+func NewExecMigration(command string) (Migration, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("migration command is empty")
+	}
+	return newMigration("exec:"+command, fmt.Sprintf("pipes the catalog through %q", command), func(cfg *declcfg.DeclarativeConfig) error {
+		var in, out bytes.Buffer
+		if err := declcfg.WriteJSON(*cfg, &in); err != nil {
+			return err
+		}
+		// nolint:gosec
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = &in
+		cmd.Stdout = &out
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("migration command %q: %v", command, err)
+		}
+		migrated, err := declcfg.LoadReader(&out)
+		if err != nil {
+			return fmt.Errorf("migration command %q: invalid output: %v", command, err)
+		}
+		*cfg = *migrated
+		return nil
+	}), nil
+}
+
+// WithExec returns m, which may be nil, followed by a migration that pipes the
+// catalog through command, as described by NewExecMigration.
+func WithExec(m *Migrations, command string) (*Migrations, error) {
+	em, err := NewExecMigration(command)
+	if err != nil {
+		return nil, err
+	}
+	out := &Migrations{}
+	if m != nil {
+		out.Migrations = append(out.Migrations, m.Migrations...)
+	}
+	out.Migrations = append(out.Migrations, em)
+	return out, nil
+}
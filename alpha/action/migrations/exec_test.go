@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+func TestExecMigration(t *testing.T) {
+	m, err := WithExec(nil, "sed s/stable/fast/g")
+	require.NoError(t, err)
+	require.Len(t, m.Migrations, 1)
+
+	cfg := declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{{Schema: declcfg.SchemaPackage, Name: "foo", DefaultChannel: "stable"}},
+		Channels: []declcfg.Channel{{Schema: declcfg.SchemaChannel, Package: "foo", Name: "stable", Entries: []declcfg.ChannelEntry{{Name: "foo.v0.1.0"}}}},
+	}
+	require.NoError(t, m.Migrate(&cfg))
+	require.Equal(t, "fast", cfg.Packages[0].DefaultChannel)
+	require.Equal(t, "fast", cfg.Channels[0].Name)
+
+	levels, err := NewMigrations(AllMigrations)
+	require.NoError(t, err)
+	m, err = WithExec(levels, "cat")
+	require.NoError(t, err)
+	require.Len(t, m.Migrations, len(allMigrations)+1)
+	require.Equal(t, MigrationToken("exec:cat"), m.Migrations[len(allMigrations)].Token())
+	require.Len(t, levels.Migrations, len(allMigrations))
+
+	_, err = NewExecMigration(" ")
+	require.EqualError(t, err, "migration command is empty")
+
+	failing, err := NewExecMigration("false")
+	require.NoError(t, err)
+	require.EqualError(t, failing.Migrate(&cfg), `migration command "false": exit status 1`)
+
+	invalid, err := NewExecMigration("echo not-a-catalog")
+	require.NoError(t, err)
+	require.ErrorContains(t, invalid.Migrate(&cfg), `migration command "echo not-a-catalog": invalid output`)
+}
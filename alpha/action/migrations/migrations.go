@@ -78,6 +78,33 @@ func NewMigrations(name string) (*Migrations, error) {
 	return &Migrations{Migrations: keep}, nil
 }
 
+// NewMigration returns the migration named by token, on its own, so that
+// migrations can be adopted one at a time.
+func NewMigration(token string) (*Migrations, error) {
+	for _, migration := range allMigrations {
+		if migration.Token() == MigrationToken(token) {
+			return &Migrations{Migrations: []Migration{migration}}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown migration %q", token)
+}
+
+// Description describes an available migration.
+type Description struct {
+	Token       MigrationToken `json:"token"`
+	Description string         `json:"description"`
+}
+
+// Describe returns the descriptions of the available migrations, in the order
+// in which they are run.
+func Describe() []Description {
+	descriptions := make([]Description, 0, len(allMigrations))
+	for _, migration := range allMigrations {
+		descriptions = append(descriptions, Description{Token: migration.Token(), Description: migration.Help()})
+	}
+	return descriptions
+}
+
 func HelpText() string {
 	var help strings.Builder
 	help.WriteString("\nThe migrator will run all migrations up to and including the selected level.\n\n")
@@ -71,6 +71,27 @@ func TestMigrations(t *testing.T) {
 	}
 }
 
+func TestNewMigration(t *testing.T) {
+	m, err := NewMigration("bundle-object-to-csv-metadata")
+	require.NoError(t, err)
+	require.Len(t, m.Migrations, 1)
+	require.Equal(t, MigrationToken("bundle-object-to-csv-metadata"), m.Migrations[0].Token())
+
+	config := unmigratedCatalogFBC()
+	require.NoError(t, m.Migrate(&config))
+	require.Empty(t, cmp.Diff(csvMetadataCatalogFBC(), config))
+
+	_, err = NewMigration(AllMigrations)
+	require.EqualError(t, err, `unknown migration "all"`)
+}
+
+func TestDescribe(t *testing.T) {
+	descriptions := Describe()
+	require.Len(t, descriptions, len(allMigrations))
+	require.Equal(t, Description{Token: MigrationToken(NoMigrations), Description: "do nothing"}, descriptions[0])
+	require.Equal(t, MigrationToken("bundle-object-to-csv-metadata"), descriptions[1].Token)
+}
+
 func mustBuildCSVMetadata(r io.Reader) property.Property {
 	var csv v1alpha1.ClusterServiceVersion
 	if err := json.NewDecoder(r).Decode(&csv); err != nil {
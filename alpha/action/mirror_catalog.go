@@ -0,0 +1,56 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/image"
+	"github.com/operator-framework/operator-registry/pkg/mirror"
+)
+
+// MirrorCatalog copies a catalog to OutputDir, with the bundle images and
+// related images that MirrorMap matches replaced by their mirrors.
+type MirrorCatalog struct {
+	IndexReference string
+	MirrorMap      mirror.Mappings
+	// OutputDir is the directory the catalog is written to. It must not
+	// exist or be empty.
+	OutputDir string
+	Registry  image.Registry
+}
+
+func (m MirrorCatalog) Run(ctx context.Context) error {
+	if m.OutputDir == "" {
+		return errors.New("output directory must be set")
+	}
+	if entries, err := os.ReadDir(m.OutputDir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("output directory %q is not empty", m.OutputDir)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	r := Render{
+		Refs:           []string{m.IndexReference},
+		AllowedRefMask: RefDCImage | RefDCDir | RefSqliteImage | RefSqliteFile,
+		Registry:       m.Registry,
+	}
+	cfg, err := r.Run(ctx)
+	if err != nil {
+		if errors.Is(err, ErrNotAllowed) {
+			return fmt.Errorf("cannot mirror non-index %q", m.IndexReference)
+		}
+		return err
+	}
+
+	for i := range cfg.Bundles {
+		b := &cfg.Bundles[i]
+		b.Image, _ = m.MirrorMap.Mirror(b.Image)
+		for j := range b.RelatedImages {
+			b.RelatedImages[j].Image, _ = m.MirrorMap.Mirror(b.RelatedImages[j].Image)
+		}
+	}
+	return declcfg.WriteFS(*cfg, m.OutputDir, declcfg.WriteYAML, ".yaml")
+}
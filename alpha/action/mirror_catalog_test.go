@@ -0,0 +1,51 @@
+package action_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/pkg/mirror"
+)
+
+func TestMirrorCatalog(t *testing.T) {
+	ctx := context.Background()
+	mirrorMap := mirror.Mappings{{Source: "test.registry/foo-operator", Mirror: "mirror.example.com/foo"}}
+
+	t.Run("Success", func(t *testing.T) {
+		outputDir := filepath.Join(t.TempDir(), "catalog")
+		err := action.MirrorCatalog{IndexReference: "testdata/list-index", MirrorMap: mirrorMap, OutputDir: outputDir}.Run(ctx)
+		require.NoError(t, err)
+
+		cfg, err := action.Render{Refs: []string{outputDir}}.Run(ctx)
+		require.NoError(t, err)
+		images := map[string][]string{}
+		for _, b := range cfg.Bundles {
+			images[b.Name] = append(images[b.Name], b.Image)
+			for _, ri := range b.RelatedImages {
+				images[b.Name] = append(images[b.Name], ri.Image)
+			}
+		}
+		require.ElementsMatch(t, []string{
+			"mirror.example.com/foo/foo-bundle:v0.1.0",
+			"mirror.example.com/foo/foo-bundle:v0.1.0",
+			"mirror.example.com/foo/foo:v0.1.0",
+		}, images["foo.v0.1.0"])
+		require.ElementsMatch(t, []string{
+			"test.registry/bar-operator/bar-bundle:v0.1.0",
+			"test.registry/bar-operator/bar-bundle:v0.1.0",
+			"test.registry/bar-operator/bar:v0.1.0",
+		}, images["bar.v0.1.0"])
+	})
+
+	t.Run("Error/OutputDirNotEmpty", func(t *testing.T) {
+		outputDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(outputDir, "file"), nil, 0600))
+		err := action.MirrorCatalog{IndexReference: "testdata/list-index", MirrorMap: mirrorMap, OutputDir: outputDir}.Run(ctx)
+		require.ErrorContains(t, err, "is not empty")
+	})
+}
@@ -0,0 +1,116 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/types"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+// Pin replaces the tag-based bundle images and related images of a
+// file-based catalog with references by digest, so that the catalog can be
+// mirrored for disconnected installs. The digests are resolved by querying
+// the images' registries, with the credentials used by podman and docker.
+//
+// The catalog's files are rewritten in place, in their original format. Files
+// without tag-based references are left untouched. References in the
+// bundles' olm.bundle.object properties are not pinned.
+type Pin struct {
+	CatalogDir    string
+	SkipTLSVerify bool
+}
+
+func (p Pin) Run(ctx context.Context) error {
+	if p.CatalogDir == "" {
+		return errors.New("catalog directory is unset")
+	}
+	sysCtx := &types.SystemContext{DockerInsecureSkipTLSVerify: types.NewOptionalBool(p.SkipTLSVerify)}
+
+	// Images are often referenced by several bundles, so each is resolved
+	// only once.
+	pinned := map[string]string{}
+	pin := func(img string) (string, error) {
+		if ref, ok := pinned[img]; ok {
+			return ref, nil
+		}
+		ref, err := pinImage(ctx, sysCtx, img)
+		if err != nil {
+			return "", err
+		}
+		pinned[img] = ref
+		return ref, nil
+	}
+
+	return declcfg.WalkFS(os.DirFS(p.CatalogDir), func(path string, cfg *declcfg.DeclarativeConfig, err error) error {
+		if err != nil {
+			return err
+		}
+		changed := false
+		for i := range cfg.Bundles {
+			b := &cfg.Bundles[i]
+			images := []*string{&b.Image}
+			for j := range b.RelatedImages {
+				images = append(images, &b.RelatedImages[j].Image)
+			}
+			for _, img := range images {
+				ref, err := pin(*img)
+				if err != nil {
+					return fmt.Errorf("bundle %q: %v", b.Name, err)
+				}
+				changed = changed || ref != *img
+				*img = ref
+			}
+		}
+		if !changed {
+			return nil
+		}
+
+		var buf bytes.Buffer
+		if err := catalogFileWriteFunc(path)(*cfg, &buf); err != nil {
+			return fmt.Errorf("write %q: %v", path, err)
+		}
+		filename := filepath.Join(p.CatalogDir, filepath.FromSlash(path))
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filename, buf.Bytes(), info.Mode())
+	})
+}
+
+// pinImage returns the reference by digest of img. Empty references and
+// references that already have a digest are returned as is.
+func pinImage(ctx context.Context, sysCtx *types.SystemContext, img string) (string, error) {
+	if img == "" {
+		return img, nil
+	}
+	named, err := reference.ParseNormalizedNamed(img)
+	if err != nil {
+		return "", fmt.Errorf("parse image %q: %v", img, err)
+	}
+	if _, ok := named.(reference.Canonical); ok {
+		return img, nil
+	}
+	named = reference.TagNameOnly(named)
+	ref, err := docker.NewReference(named)
+	if err != nil {
+		return "", err
+	}
+	dgst, err := docker.GetDigest(ctx, sysCtx, ref)
+	if err != nil {
+		return "", fmt.Errorf("resolve digest of image %q: %v", img, err)
+	}
+	canonical, err := reference.WithDigest(reference.TrimNamed(named), dgst)
+	if err != nil {
+		return "", err
+	}
+	return canonical.String(), nil
+}
@@ -0,0 +1,96 @@
+package action_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/internal/testutil/image"
+)
+
+const pinCatalog = `---
+schema: olm.bundle
+package: foo
+name: foo.v0.1.0
+image: {{host}}/foo/foo-bundle:v0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.1.0
+relatedImages:
+  - name: operator
+    image: {{host}}/foo/foo@sha256:0000000000000000000000000000000000000000000000000000000000000000
+  - image: {{host}}/foo/foo-bundle:v0.1.0
+`
+
+const pinPackage = `---
+schema: olm.package
+name: foo
+`
+
+func TestPin(t *testing.T) {
+	ctx := context.Background()
+	server := image.RunDockerRegistry(ctx, "")
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	// Push an image to pin to the registry.
+	store := memory.New()
+	desc, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, "application/vnd.example.test", oras.PackManifestOptions{})
+	require.NoError(t, err)
+	require.NoError(t, store.Tag(ctx, desc, "v0.1.0"))
+	_, err = oras.Copy(ctx, store, "v0.1.0", newTestRepository(t, server, host+"/foo/foo-bundle"), "v0.1.0", oras.DefaultCopyOptions)
+	require.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "bundles.yaml"), []byte(strings.ReplaceAll(pinCatalog, "{{host}}", host)), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "package.yaml"), []byte(pinPackage), 0600))
+
+		require.NoError(t, action.Pin{CatalogDir: dir, SkipTLSVerify: true}.Run(ctx))
+
+		cfg, err := declcfg.LoadFS(ctx, os.DirFS(dir))
+		require.NoError(t, err)
+		require.Len(t, cfg.Bundles, 1)
+		pinned := host + "/foo/foo-bundle@" + desc.Digest.String()
+		require.Equal(t, pinned, cfg.Bundles[0].Image)
+		require.Equal(t, []declcfg.RelatedImage{
+			{Name: "operator", Image: host + "/foo/foo@sha256:0000000000000000000000000000000000000000000000000000000000000000"},
+			{Image: pinned},
+		}, cfg.Bundles[0].RelatedImages)
+
+		// Files without tag-based references are left untouched.
+		data, err := os.ReadFile(filepath.Join(dir, "package.yaml"))
+		require.NoError(t, err)
+		require.Equal(t, pinPackage, string(data))
+	})
+
+	t.Run("Error/UnknownTag", func(t *testing.T) {
+		dir := t.TempDir()
+		catalog := strings.ReplaceAll(strings.ReplaceAll(pinCatalog, "{{host}}", host), "foo-bundle:v0.1.0", "foo-bundle:unknown")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "bundles.yaml"), []byte(catalog), 0600))
+
+		err := action.Pin{CatalogDir: dir, SkipTLSVerify: true}.Run(ctx)
+		require.ErrorContains(t, err, `bundle "foo.v0.1.0": resolve digest of image "`+host+`/foo/foo-bundle:unknown"`)
+	})
+}
+
+func newTestRepository(t *testing.T, server *httptest.Server, ref string) *remote.Repository {
+	t.Helper()
+	repo, err := remote.NewRepository(ref)
+	require.NoError(t, err)
+	repo.Client = &auth.Client{Client: server.Client()}
+	return repo
+}
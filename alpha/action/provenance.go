@@ -0,0 +1,144 @@
+package action
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/blang/semver/v4"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+// TypeEdgeProvenance is the type of the channel property that records where
+// the entries of a channel rendered from a sqlite database, and their edges,
+// came from, so that maintainers can audit the upgrade graphs that the
+// database generated before editing them.
+const TypeEdgeProvenance = "olm.migrate.edgeProvenance"
+
+// EdgeProvenance is the value of a TypeEdgeProvenance property.
+type EdgeProvenance struct {
+	Entries []EntryProvenance `json:"entries"`
+}
+
+// EntryProvenance records where a channel entry and its edges came from.
+type EntryProvenance struct {
+	Name string `json:"name"`
+	// ReachedBy lists the kinds of edges of other entries that lead to the
+	// entry: ReachedByReplaces, ReachedBySkips and ReachedBySkipRange. It is
+	// empty for the head of the channel.
+	ReachedBy []string `json:"reachedBy,omitempty"`
+	// Replaces is where the replaces edge of the entry came from: the CSV
+	// of the bundle (EdgeFromCSV), or the upgrade graph that the database
+	// generated (EdgeFromIndex). It is empty if the entry has no replaces
+	// edge.
+	Replaces string `json:"replaces,omitempty"`
+}
+
+const (
+	ReachedByReplaces  = "replaces"
+	ReachedBySkips     = "skips"
+	ReachedBySkipRange = "skipRange"
+
+	EdgeFromCSV   = "csv"
+	EdgeFromIndex = "index"
+)
+
+// populateDBEdgeProvenance adds a TypeEdgeProvenance property to each
+// channel of cfg, which is rendered from db.
+func populateDBEdgeProvenance(ctx context.Context, cfg *declcfg.DeclarativeConfig, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, "SELECT name, replaces FROM operatorbundle")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	declaredReplaces := map[string]string{}
+	for rows.Next() {
+		var name, replaces sql.NullString
+		if err := rows.Scan(&name, &replaces); err != nil {
+			return err
+		}
+		declaredReplaces[name.String] = replaces.String
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	versions := map[string]map[string]semver.Version{}
+	for _, b := range cfg.Bundles {
+		props, err := property.Parse(b.Properties)
+		if err != nil {
+			return fmt.Errorf("parse properties of bundle %q: %v", b.Name, err)
+		}
+		if len(props.Packages) != 1 {
+			continue
+		}
+		v, err := semver.Parse(props.Packages[0].Version)
+		if err != nil {
+			continue
+		}
+		if versions[b.Package] == nil {
+			versions[b.Package] = map[string]semver.Version{}
+		}
+		versions[b.Package][b.Name] = v
+	}
+
+	for i, ch := range cfg.Channels {
+		prov := channelEdgeProvenance(ch, declaredReplaces, versions[ch.Package])
+		value, err := json.Marshal(prov)
+		if err != nil {
+			return err
+		}
+		cfg.Channels[i].Properties = append(cfg.Channels[i].Properties, property.Property{Type: TypeEdgeProvenance, Value: value})
+	}
+	return nil
+}
+
+func channelEdgeProvenance(ch declcfg.Channel, declaredReplaces map[string]string, versions map[string]semver.Version) EdgeProvenance {
+	replaced := map[string]bool{}
+	skipped := map[string]bool{}
+	skipRanges := map[string]semver.Range{}
+	for _, e := range ch.Entries {
+		replaced[e.Replaces] = true
+		for _, s := range e.Skips {
+			skipped[s] = true
+		}
+		if r, err := semver.ParseRange(e.SkipRange); e.SkipRange != "" && err == nil {
+			skipRanges[e.Name] = r
+		}
+	}
+
+	prov := EdgeProvenance{Entries: make([]EntryProvenance, 0, len(ch.Entries))}
+	for _, e := range ch.Entries {
+		ep := EntryProvenance{Name: e.Name}
+		if replaced[e.Name] {
+			ep.ReachedBy = append(ep.ReachedBy, ReachedByReplaces)
+		}
+		if skipped[e.Name] {
+			ep.ReachedBy = append(ep.ReachedBy, ReachedBySkips)
+		}
+		if v, ok := versions[e.Name]; ok {
+			for name, skipRange := range skipRanges {
+				if name != e.Name && skipRange(v) {
+					ep.ReachedBy = append(ep.ReachedBy, ReachedBySkipRange)
+					break
+				}
+			}
+		}
+		if e.Replaces != "" {
+			ep.Replaces = EdgeFromIndex
+			if declaredReplaces[e.Name] == e.Replaces {
+				ep.Replaces = EdgeFromCSV
+			}
+		}
+		prov.Entries = append(prov.Entries, ep)
+	}
+	sort.Slice(prov.Entries, func(i, j int) bool {
+		return prov.Entries[i].Name < prov.Entries[j].Name
+	})
+	return prov
+}
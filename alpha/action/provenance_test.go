@@ -0,0 +1,43 @@
+package action
+
+import (
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+func TestChannelEdgeProvenance(t *testing.T) {
+	ch := declcfg.Channel{
+		Name:    "stable",
+		Package: "foo",
+		Entries: []declcfg.ChannelEntry{
+			{Name: "foo.v0.1.0"},
+			{Name: "foo.v0.1.1"},
+			{Name: "foo.v0.1.2", Replaces: "foo.v0.1.0"},
+			{Name: "foo.v0.2.0", Replaces: "foo.v0.1.2", Skips: []string{"foo.v0.1.1"}, SkipRange: "<0.2.0"},
+			{Name: "foo.v1.0.0", Replaces: "foo.v0.2.0", SkipRange: "<1.0.0"},
+		},
+	}
+	declaredReplaces := map[string]string{
+		"foo.v0.1.2": "foo.v0.1.0",
+		"foo.v0.2.0": "foo.v0.1.1",
+	}
+	versions := map[string]semver.Version{
+		"foo.v0.1.0": semver.MustParse("0.1.0"),
+		"foo.v0.1.1": semver.MustParse("0.1.1"),
+		"foo.v0.1.2": semver.MustParse("0.1.2"),
+		"foo.v0.2.0": semver.MustParse("0.2.0"),
+		"foo.v1.0.0": semver.MustParse("1.0.0"),
+	}
+
+	require.Equal(t, EdgeProvenance{Entries: []EntryProvenance{
+		{Name: "foo.v0.1.0", ReachedBy: []string{ReachedByReplaces, ReachedBySkipRange}},
+		{Name: "foo.v0.1.1", ReachedBy: []string{ReachedBySkips, ReachedBySkipRange}},
+		{Name: "foo.v0.1.2", ReachedBy: []string{ReachedByReplaces, ReachedBySkipRange}, Replaces: EdgeFromCSV},
+		{Name: "foo.v0.2.0", ReachedBy: []string{ReachedByReplaces, ReachedBySkipRange}, Replaces: EdgeFromIndex},
+		{Name: "foo.v1.0.0", Replaces: EdgeFromIndex},
+	}}, channelEdgeProvenance(ch, declaredReplaces, versions))
+}
@@ -0,0 +1,425 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/blang/semver/v4"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/pkg/image"
+)
+
+// PackageFilter selects content of a package to keep when pruning a catalog.
+type PackageFilter struct {
+	Name string
+	// Channels limits the kept content to the given channels. If empty, all
+	// channels of the package are kept.
+	Channels []string
+	// VersionRange limits the kept bundles to those whose version is in the
+	// semver range. If empty, all bundles are kept.
+	VersionRange string
+}
+
+// Prune writes a catalog which only contains the content selected by
+// Packages.
+//
+// If IncludeDependencies is set, the olm.package.required and
+// olm.gvk.required properties of the selected bundles are resolved so that
+// the resulting catalog remains installable. When a dependency is not
+// satisfied by the selected bundles, a bundle which satisfies it is added,
+// along with the channels that contain it. Bundles in their package's default
+// channel are preferred, followed by the highest version.
+type Prune struct {
+	CatalogRef          string
+	Packages            []PackageFilter
+	IncludeDependencies bool
+	OutputDir           string
+
+	WriteFunc declcfg.WriteFunc
+	FileExt   string
+	Registry  image.Registry
+}
+
+func (p Prune) Run(ctx context.Context) error {
+	entries, err := os.ReadDir(p.OutputDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("output dir %q must be empty", p.OutputDir)
+	}
+	if len(p.Packages) == 0 {
+		return fmt.Errorf("at least one package must be specified")
+	}
+
+	r := Render{
+		Refs:     []string{p.CatalogRef},
+		Registry: p.Registry,
+
+		// Only allow catalogs to be pruned.
+		AllowedRefMask: RefSqliteImage | RefSqliteFile | RefDCImage | RefDCDir,
+	}
+	cfg, err := r.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("render catalog: %v", err)
+	}
+
+	pruned, err := pruneConfig(*cfg, p.Packages, p.IncludeDependencies)
+	if err != nil {
+		return err
+	}
+	if _, err := declcfg.ConvertToModel(*pruned); err != nil {
+		return fmt.Errorf("pruned catalog is invalid: %v", err)
+	}
+	return declcfg.WriteFS(*pruned, p.OutputDir, p.WriteFunc, p.FileExt)
+}
+
+type pruneBundle struct {
+	bundle  declcfg.Bundle
+	version semver.Version
+	props   *property.Properties
+}
+
+type pruner struct {
+	packages map[string]declcfg.Package
+	channels map[string][]declcfg.Channel
+	bundles  map[string]map[string]*pruneBundle
+
+	keepBundles  map[string]sets.Set[string]
+	keepChannels map[string]sets.Set[string]
+	queue        []*pruneBundle
+
+	// keepEntries optionally restricts the entries kept in a channel, keyed
+	// by package and channel name. Channels without keepEntries keep every
+	// entry whose bundle is in keepBundles. Bundles added to satisfy
+	// dependencies are kept in every channel that contains them.
+	keepEntries  map[string]map[string]sets.Set[string]
+	dependencies map[string]sets.Set[string]
+}
+
+func pruneConfig(cfg declcfg.DeclarativeConfig, filters []PackageFilter, includeDependencies bool) (*declcfg.DeclarativeConfig, error) {
+	p, err := newPruner(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range filters {
+		if err := p.selectPackage(f); err != nil {
+			return nil, err
+		}
+	}
+	if includeDependencies {
+		if err := p.resolveDependencies(); err != nil {
+			return nil, err
+		}
+	}
+	return p.output(cfg), nil
+}
+
+func newPruner(cfg declcfg.DeclarativeConfig) (*pruner, error) {
+	p := &pruner{
+		packages:     map[string]declcfg.Package{},
+		channels:     map[string][]declcfg.Channel{},
+		bundles:      map[string]map[string]*pruneBundle{},
+		keepBundles:  map[string]sets.Set[string]{},
+		keepChannels: map[string]sets.Set[string]{},
+		keepEntries:  map[string]map[string]sets.Set[string]{},
+		dependencies: map[string]sets.Set[string]{},
+	}
+	for _, pkg := range cfg.Packages {
+		p.packages[pkg.Name] = pkg
+	}
+	for _, ch := range cfg.Channels {
+		p.channels[ch.Package] = append(p.channels[ch.Package], ch)
+	}
+	for _, b := range cfg.Bundles {
+		props, err := property.Parse(b.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("parse properties for bundle %q: %v", b.Name, err)
+		}
+		if len(props.Packages) != 1 {
+			return nil, fmt.Errorf("bundle %q has %d %q properties, expected exactly 1", b.Name, len(props.Packages), property.TypePackage)
+		}
+		v, err := semver.Parse(props.Packages[0].Version)
+		if err != nil {
+			return nil, fmt.Errorf("bundle %q has invalid version %q: %v", b.Name, props.Packages[0].Version, err)
+		}
+		if _, ok := p.bundles[b.Package]; !ok {
+			p.bundles[b.Package] = map[string]*pruneBundle{}
+		}
+		p.bundles[b.Package][b.Name] = &pruneBundle{bundle: b, version: v, props: props}
+	}
+	return p, nil
+}
+
+func (p *pruner) selectPackage(f PackageFilter) error {
+	if _, ok := p.packages[f.Name]; !ok {
+		return fmt.Errorf("package %q not found in catalog", f.Name)
+	}
+	inRange := func(semver.Version) bool { return true }
+	if f.VersionRange != "" {
+		r, err := semver.ParseRange(f.VersionRange)
+		if err != nil {
+			return fmt.Errorf("package %q: invalid version range %q: %v", f.Name, f.VersionRange, err)
+		}
+		inRange = r
+	}
+
+	channels := sets.New(f.Channels...)
+	found := sets.New[string]()
+	selected := 0
+	for _, ch := range p.channels[f.Name] {
+		if channels.Len() > 0 && !channels.Has(ch.Name) {
+			continue
+		}
+		found.Insert(ch.Name)
+		p.keepChannel(f.Name, ch.Name)
+		for _, e := range ch.Entries {
+			b, ok := p.bundles[f.Name][e.Name]
+			if ok && inRange(b.version) {
+				p.keepBundle(b)
+				selected++
+			}
+		}
+	}
+	if missing := channels.Difference(found); missing.Len() > 0 {
+		return fmt.Errorf("package %q: channels %v not found", f.Name, sets.List(missing))
+	}
+	if selected == 0 {
+		return fmt.Errorf("package %q: no bundles match the requested channels and version range", f.Name)
+	}
+	return nil
+}
+
+func (p *pruner) keepChannel(pkg, ch string) {
+	if _, ok := p.keepChannels[pkg]; !ok {
+		p.keepChannels[pkg] = sets.New[string]()
+	}
+	p.keepChannels[pkg].Insert(ch)
+}
+
+func (p *pruner) keepBundle(b *pruneBundle) {
+	if _, ok := p.keepBundles[b.bundle.Package]; !ok {
+		p.keepBundles[b.bundle.Package] = sets.New[string]()
+	}
+	if p.keepBundles[b.bundle.Package].Has(b.bundle.Name) {
+		return
+	}
+	p.keepBundles[b.bundle.Package].Insert(b.bundle.Name)
+	p.queue = append(p.queue, b)
+}
+
+// keepDependency keeps b along with every channel that contains it.
+func (p *pruner) keepDependency(b *pruneBundle) {
+	for _, ch := range p.channels[b.bundle.Package] {
+		for _, e := range ch.Entries {
+			if e.Name == b.bundle.Name {
+				p.keepChannel(ch.Package, ch.Name)
+			}
+		}
+	}
+	if _, ok := p.dependencies[b.bundle.Package]; !ok {
+		p.dependencies[b.bundle.Package] = sets.New[string]()
+	}
+	p.dependencies[b.bundle.Package].Insert(b.bundle.Name)
+	p.keepBundle(b)
+}
+
+func (p *pruner) keepEntry(ch declcfg.Channel, name string) bool {
+	if entries, ok := p.keepEntries[ch.Package][ch.Name]; ok {
+		return entries.Has(name) || p.dependencies[ch.Package].Has(name)
+	}
+	return p.keepBundles[ch.Package].Has(name)
+}
+
+func (p *pruner) resolveDependencies() error {
+	for len(p.queue) > 0 {
+		b := p.queue[0]
+		p.queue = p.queue[1:]
+
+		for _, req := range b.props.PackagesRequired {
+			r, err := semver.ParseRange(req.VersionRange)
+			if err != nil {
+				return fmt.Errorf("bundle %q: invalid version range %q for required package %q: %v", b.bundle.Name, req.VersionRange, req.PackageName, err)
+			}
+			match := func(c *pruneBundle) bool { return c.bundle.Package == req.PackageName && r(c.version) }
+			if p.isSatisfied(match) {
+				continue
+			}
+			dep := p.bestCandidate(match)
+			if dep == nil {
+				return fmt.Errorf("bundle %q requires package %q with version range %q, which no bundle in the catalog satisfies", b.bundle.Name, req.PackageName, req.VersionRange)
+			}
+			p.keepDependency(dep)
+		}
+
+		for _, req := range b.props.GVKsRequired {
+			match := func(c *pruneBundle) bool {
+				for _, gvk := range c.props.GVKs {
+					if gvk.Group == req.Group && gvk.Version == req.Version && gvk.Kind == req.Kind {
+						return true
+					}
+				}
+				return false
+			}
+			if p.isSatisfied(match) {
+				continue
+			}
+			dep := p.bestCandidate(match)
+			if dep == nil {
+				return fmt.Errorf("bundle %q requires %s/%s, Kind=%s, which no bundle in the catalog provides", b.bundle.Name, req.Group, req.Version, req.Kind)
+			}
+			p.keepDependency(dep)
+		}
+	}
+	return nil
+}
+
+func (p *pruner) isSatisfied(match func(*pruneBundle) bool) bool {
+	for pkg, names := range p.keepBundles {
+		for name := range names {
+			if match(p.bundles[pkg][name]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bestCandidate returns the matching bundle to add for an unsatisfied
+// dependency. Bundles in their package's default channel are preferred, then
+// higher versions, then the lowest package and bundle names for determinism.
+func (p *pruner) bestCandidate(match func(*pruneBundle) bool) *pruneBundle {
+	var (
+		best          *pruneBundle
+		bestInDefault bool
+	)
+	for _, bundles := range p.bundles {
+		for _, b := range bundles {
+			if !match(b) {
+				continue
+			}
+			inDefault := p.inDefaultChannel(b)
+			if best == nil || p.isBetterCandidate(b, inDefault, best, bestInDefault) {
+				best, bestInDefault = b, inDefault
+			}
+		}
+	}
+	return best
+}
+
+func (p *pruner) isBetterCandidate(b *pruneBundle, bInDefault bool, best *pruneBundle, bestInDefault bool) bool {
+	if bInDefault != bestInDefault {
+		return bInDefault
+	}
+	if !b.version.EQ(best.version) {
+		return b.version.GT(best.version)
+	}
+	if b.bundle.Package != best.bundle.Package {
+		return b.bundle.Package < best.bundle.Package
+	}
+	return b.bundle.Name < best.bundle.Name
+}
+
+func (p *pruner) inDefaultChannel(b *pruneBundle) bool {
+	defaultChannel := p.packages[b.bundle.Package].DefaultChannel
+	for _, ch := range p.channels[b.bundle.Package] {
+		if ch.Name != defaultChannel {
+			continue
+		}
+		for _, e := range ch.Entries {
+			if e.Name == b.bundle.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *pruner) output(in declcfg.DeclarativeConfig) *declcfg.DeclarativeConfig {
+	out := &declcfg.DeclarativeConfig{Catalogs: in.Catalogs}
+	keptChannels := map[string]sets.Set[string]{}
+	for _, ch := range in.Channels {
+		if !p.keepChannels[ch.Package].Has(ch.Name) {
+			continue
+		}
+		ch.Entries = append([]declcfg.ChannelEntry(nil), ch.Entries...)
+		var removed []string
+		for _, e := range ch.Entries {
+			if !p.keepEntry(ch, e.Name) {
+				removed = append(removed, e.Name)
+			}
+		}
+		for _, name := range removed {
+			removeChannelEntry(&ch, name)
+		}
+		if len(ch.Entries) == 0 {
+			continue
+		}
+		out.Channels = append(out.Channels, ch)
+		if _, ok := keptChannels[ch.Package]; !ok {
+			keptChannels[ch.Package] = sets.New[string]()
+		}
+		keptChannels[ch.Package].Insert(ch.Name)
+	}
+
+	for _, pkg := range in.Packages {
+		channels, ok := keptChannels[pkg.Name]
+		if !ok {
+			continue
+		}
+		if !channels.Has(pkg.DefaultChannel) {
+			pkg.DefaultChannel = sets.List(channels)[0]
+		}
+		out.Packages = append(out.Packages, pkg)
+	}
+
+	for _, b := range in.Bundles {
+		if p.keepBundles[b.Package].Has(b.Name) {
+			out.Bundles = append(out.Bundles, b)
+		}
+	}
+
+	for _, d := range in.Deprecations {
+		if _, ok := keptChannels[d.Package]; !ok {
+			continue
+		}
+		d.Entries = filterSlice(append([]declcfg.DeprecationEntry(nil), d.Entries...), func(e declcfg.DeprecationEntry) bool {
+			switch e.Reference.Schema {
+			case declcfg.SchemaChannel:
+				return keptChannels[d.Package].Has(e.Reference.Name)
+			case declcfg.SchemaBundle:
+				return p.keepBundles[d.Package].Has(e.Reference.Name)
+			}
+			return true
+		})
+		if len(d.Entries) > 0 {
+			out.Deprecations = append(out.Deprecations, d)
+		}
+	}
+
+	for _, i := range in.Icons {
+		if _, ok := keptChannels[i.Package]; ok {
+			out.Icons = append(out.Icons, i)
+		}
+	}
+
+	for _, e := range in.Extensions {
+		if _, ok := keptChannels[e.Package]; e.Package == "" || ok {
+			out.Extensions = append(out.Extensions, e)
+		}
+	}
+
+	for _, o := range in.Others {
+		if _, ok := keptChannels[o.Package]; o.Package == "" || ok {
+			out.Others = append(out.Others, o)
+		}
+	}
+
+	sort.SliceStable(out.Packages, func(i, j int) bool { return out.Packages[i].Name < out.Packages[j].Name })
+	return out
+}
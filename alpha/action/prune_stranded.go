@@ -0,0 +1,61 @@
+package action
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// PruneStranded removes stranded bundles from a file-based catalog directory.
+// A bundle is stranded if it is not an entry of any channel of its package,
+// so it can never be installed or upgraded to. Deprecation entries that
+// reference stranded bundles are removed along with them.
+//
+// If DryRun is set, the stranded bundles are reported but the catalog is not
+// modified.
+type PruneStranded struct {
+	CatalogDir string
+	DryRun     bool
+}
+
+// Run returns the stranded bundles found in the catalog, ordered by package
+// and bundle name.
+func (p PruneStranded) Run(_ context.Context) ([]CatalogBundle, error) {
+	files, err := loadCatalogFiles(p.CatalogDir)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := map[string]sets.Set[string]{}
+	images := map[string]map[string]string{}
+	for _, cfg := range files {
+		for _, b := range cfg.Bundles {
+			if _, ok := candidates[b.Package]; !ok {
+				candidates[b.Package] = sets.New[string]()
+				images[b.Package] = map[string]string{}
+			}
+			candidates[b.Package].Insert(b.Name)
+			images[b.Package][b.Name] = b.Image
+		}
+	}
+
+	var stranded []CatalogBundle
+	modified := sets.New[string]()
+	for pkg, names := range candidates {
+		e := &catalogEdit{files: files, pkg: pkg, modified: modified}
+		pruned := e.pruneBundles(names)
+		e.pruneDeprecations(sets.New[string](), pruned)
+		for name := range pruned {
+			stranded = append(stranded, CatalogBundle{Package: pkg, Name: name, Image: images[pkg][name]})
+		}
+	}
+	sortCatalogBundles(stranded)
+
+	if p.DryRun {
+		return stranded, nil
+	}
+	if err := writeModifiedFiles(p.CatalogDir, files, modified); err != nil {
+		return nil, err
+	}
+	return stranded, nil
+}
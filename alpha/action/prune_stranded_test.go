@@ -0,0 +1,122 @@
+package action_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+const strandedFooCatalog = `---
+schema: olm.package
+name: foo
+defaultChannel: stable
+---
+schema: olm.channel
+package: foo
+name: stable
+entries:
+  - name: foo.v0.2.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.1.0
+image: test.registry/foo-operator/foo-bundle:v0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.1.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.2.0
+image: test.registry/foo-operator/foo-bundle:v0.2.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.2.0
+---
+schema: olm.deprecations
+package: foo
+entries:
+  - reference:
+      schema: olm.bundle
+      name: foo.v0.1.0
+    message: foo.v0.1.0 is deprecated
+`
+
+const strandedBarBundle = `---
+schema: olm.bundle
+package: bar
+name: bar.v0.1.0
+image: test.registry/bar-operator/bar-bundle:v0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: bar
+      version: 0.1.0
+`
+
+func TestPruneStranded(t *testing.T) {
+	expected := []action.CatalogBundle{
+		{Package: "bar", Name: "bar.v0.1.0", Image: "test.registry/bar-operator/bar-bundle:v0.1.0"},
+		{Package: "foo", Name: "foo.v0.1.0", Image: "test.registry/foo-operator/foo-bundle:v0.1.0"},
+	}
+
+	setup := func(t *testing.T) string {
+		dir := t.TempDir()
+		for path, content := range map[string]string{"foo/catalog.yaml": strandedFooCatalog, "bar/bundle.yaml": strandedBarBundle} {
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0777))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0600))
+		}
+		return dir
+	}
+
+	t.Run("DryRun", func(t *testing.T) {
+		dir := setup(t)
+		stranded, err := action.PruneStranded{CatalogDir: dir, DryRun: true}.Run(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, expected, stranded)
+
+		actual, err := os.ReadFile(filepath.Join(dir, "foo/catalog.yaml"))
+		require.NoError(t, err)
+		require.Equal(t, strandedFooCatalog, string(actual))
+		require.FileExists(t, filepath.Join(dir, "bar/bundle.yaml"))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		dir := setup(t)
+		stranded, err := action.PruneStranded{CatalogDir: dir}.Run(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, expected, stranded)
+
+		require.NoFileExists(t, filepath.Join(dir, "bar/bundle.yaml"))
+		cfg, err := declcfg.LoadFile(os.DirFS(dir), "foo/catalog.yaml")
+		require.NoError(t, err)
+		require.Len(t, cfg.Bundles, 1)
+		require.Equal(t, "foo.v0.2.0", cfg.Bundles[0].Name)
+		require.Empty(t, cfg.Deprecations)
+
+		stranded, err = action.PruneStranded{CatalogDir: dir}.Run(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, stranded)
+	})
+}
+
+func TestWriteBundles(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, action.WriteBundles(&buf, []action.CatalogBundle{
+		{Package: "foo", Name: "foo.v0.1.0", Image: "test.registry/foo-operator/foo-bundle:v0.1.0"},
+	}))
+	require.Equal(t, `PACKAGE  BUNDLE      IMAGE
+foo      foo.v0.1.0  test.registry/foo-operator/foo-bundle:v0.1.0
+`, buf.String())
+}
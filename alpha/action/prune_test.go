@@ -0,0 +1,398 @@
+package action_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+const pruneCatalog = `---
+schema: olm.package
+name: foo
+defaultChannel: beta
+---
+schema: olm.channel
+package: foo
+name: beta
+entries:
+  - name: foo.v0.1.0
+  - name: foo.v0.2.0
+    replaces: foo.v0.1.0
+  - name: foo.v0.3.0
+    replaces: foo.v0.2.0
+---
+schema: olm.channel
+package: foo
+name: stable
+entries:
+  - name: foo.v0.2.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.1.0
+image: test.registry/foo-operator/foo-bundle:v0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.1.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.2.0
+image: test.registry/foo-operator/foo-bundle:v0.2.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.2.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.3.0
+image: test.registry/foo-operator/foo-bundle:v0.3.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.3.0
+  - type: olm.package.required
+    value:
+      packageName: bar
+      versionRange: <0.3.0
+  - type: olm.gvk.required
+    value:
+      group: test.baz
+      kind: Baz
+      version: v1
+---
+schema: olm.deprecations
+package: foo
+entries:
+  - reference:
+      schema: olm.channel
+      name: stable
+    message: stable is deprecated
+  - reference:
+      schema: olm.bundle
+      name: foo.v0.1.0
+    message: foo.v0.1.0 is deprecated
+---
+schema: olm.package
+name: bar
+defaultChannel: alpha
+---
+schema: olm.channel
+package: bar
+name: alpha
+entries:
+  - name: bar.v0.1.0
+  - name: bar.v0.2.0
+    replaces: bar.v0.1.0
+  - name: bar.v0.3.0
+    replaces: bar.v0.2.0
+---
+schema: olm.bundle
+package: bar
+name: bar.v0.1.0
+image: test.registry/bar-operator/bar-bundle:v0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: bar
+      version: 0.1.0
+---
+schema: olm.bundle
+package: bar
+name: bar.v0.2.0
+image: test.registry/bar-operator/bar-bundle:v0.2.0
+properties:
+  - type: olm.package
+    value:
+      packageName: bar
+      version: 0.2.0
+---
+schema: olm.bundle
+package: bar
+name: bar.v0.3.0
+image: test.registry/bar-operator/bar-bundle:v0.3.0
+properties:
+  - type: olm.package
+    value:
+      packageName: bar
+      version: 0.3.0
+---
+schema: olm.package
+name: baz
+defaultChannel: stable
+---
+schema: olm.channel
+package: baz
+name: stable
+entries:
+  - name: baz.v1.0.0
+---
+schema: olm.bundle
+package: baz
+name: baz.v1.0.0
+image: test.registry/baz-operator/baz-bundle:v1.0.0
+properties:
+  - type: olm.package
+    value:
+      packageName: baz
+      version: 1.0.0
+  - type: olm.gvk
+    value:
+      group: test.baz
+      kind: Baz
+      version: v1
+---
+schema: olm.package
+name: qux
+defaultChannel: stable
+---
+schema: olm.channel
+package: qux
+name: stable
+entries:
+  - name: qux.v1.0.0
+---
+schema: olm.channel
+package: qux
+name: fast
+entries:
+  - name: qux.v2.0.0
+---
+schema: olm.bundle
+package: qux
+name: qux.v1.0.0
+image: test.registry/qux-operator/qux-bundle:v1.0.0
+properties:
+  - type: olm.package
+    value:
+      packageName: qux
+      version: 1.0.0
+---
+schema: olm.bundle
+package: qux
+name: qux.v2.0.0
+image: test.registry/qux-operator/qux-bundle:v2.0.0
+properties:
+  - type: olm.package
+    value:
+      packageName: qux
+      version: 2.0.0
+  - type: olm.gvk
+    value:
+      group: test.baz
+      kind: Baz
+      version: v1
+`
+
+func TestPrune(t *testing.T) {
+	type spec struct {
+		name                   string
+		packages               []action.PackageFilter
+		includeDependencies    bool
+		catalogEdit            func(string) string
+		expectedDefaultChannel map[string]string
+		expectedEntries        map[string][]declcfg.ChannelEntry
+		expectedDeprecations   []string
+		expectErr              string
+	}
+
+	specs := []spec{
+		{
+			name:                "Success/WholePackageWithDependencies",
+			packages:            []action.PackageFilter{{Name: "foo"}},
+			includeDependencies: true,
+			expectedDefaultChannel: map[string]string{
+				"foo": "beta",
+				"bar": "alpha",
+				"baz": "stable",
+			},
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"foo/beta": {
+					{Name: "foo.v0.1.0"},
+					{Name: "foo.v0.2.0", Replaces: "foo.v0.1.0"},
+					{Name: "foo.v0.3.0", Replaces: "foo.v0.2.0"},
+				},
+				"foo/stable": {{Name: "foo.v0.2.0"}},
+				"bar/alpha":  {{Name: "bar.v0.2.0"}},
+				"baz/stable": {{Name: "baz.v1.0.0"}},
+			},
+			expectedDeprecations: []string{"stable", "foo.v0.1.0"},
+		},
+		{
+			name:     "Success/WithoutDependencies",
+			packages: []action.PackageFilter{{Name: "foo"}},
+			expectedDefaultChannel: map[string]string{
+				"foo": "beta",
+			},
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"foo/beta": {
+					{Name: "foo.v0.1.0"},
+					{Name: "foo.v0.2.0", Replaces: "foo.v0.1.0"},
+					{Name: "foo.v0.3.0", Replaces: "foo.v0.2.0"},
+				},
+				"foo/stable": {{Name: "foo.v0.2.0"}},
+			},
+			expectedDeprecations: []string{"stable", "foo.v0.1.0"},
+		},
+		{
+			name:                "Success/DependencyFromNonDefaultChannel",
+			packages:            []action.PackageFilter{{Name: "foo"}, {Name: "qux", Channels: []string{"fast"}}},
+			includeDependencies: true,
+			expectedDefaultChannel: map[string]string{
+				"foo": "beta",
+				"bar": "alpha",
+				"qux": "fast",
+			},
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"foo/beta": {
+					{Name: "foo.v0.1.0"},
+					{Name: "foo.v0.2.0", Replaces: "foo.v0.1.0"},
+					{Name: "foo.v0.3.0", Replaces: "foo.v0.2.0"},
+				},
+				"foo/stable": {{Name: "foo.v0.2.0"}},
+				"bar/alpha":  {{Name: "bar.v0.2.0"}},
+				"qux/fast":   {{Name: "qux.v2.0.0"}},
+			},
+			expectedDeprecations: []string{"stable", "foo.v0.1.0"},
+		},
+		{
+			name:     "Success/ChannelUpdatesDefaultChannel",
+			packages: []action.PackageFilter{{Name: "foo", Channels: []string{"stable"}}},
+			expectedDefaultChannel: map[string]string{
+				"foo": "stable",
+			},
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"foo/stable": {{Name: "foo.v0.2.0"}},
+			},
+			expectedDeprecations: []string{"stable"},
+		},
+		{
+			name:                "Success/VersionRangeRepairsReplaces",
+			packages:            []action.PackageFilter{{Name: "foo", Channels: []string{"beta"}, VersionRange: ">=0.2.0"}, {Name: "bar"}},
+			includeDependencies: true,
+			expectedDefaultChannel: map[string]string{
+				"foo": "beta",
+				"bar": "alpha",
+				"baz": "stable",
+			},
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"foo/beta": {
+					{Name: "foo.v0.2.0"},
+					{Name: "foo.v0.3.0", Replaces: "foo.v0.2.0"},
+				},
+				"bar/alpha": {
+					{Name: "bar.v0.1.0"},
+					{Name: "bar.v0.2.0", Replaces: "bar.v0.1.0"},
+					{Name: "bar.v0.3.0", Replaces: "bar.v0.2.0"},
+				},
+				"baz/stable": {{Name: "baz.v1.0.0"}},
+			},
+		},
+		{
+			name:      "Error/PackageNotFound",
+			packages:  []action.PackageFilter{{Name: "quux"}},
+			expectErr: `package "quux" not found in catalog`,
+		},
+		{
+			name:      "Error/ChannelNotFound",
+			packages:  []action.PackageFilter{{Name: "foo", Channels: []string{"fast"}}},
+			expectErr: `package "foo": channels [fast] not found`,
+		},
+		{
+			name:      "Error/NoMatchingBundles",
+			packages:  []action.PackageFilter{{Name: "foo", VersionRange: ">1.0.0"}},
+			expectErr: `package "foo": no bundles match the requested channels and version range`,
+		},
+		{
+			name:      "Error/InvalidVersionRange",
+			packages:  []action.PackageFilter{{Name: "foo", VersionRange: "latest"}},
+			expectErr: `package "foo": invalid version range "latest"`,
+		},
+		{
+			name: "Error/UnsatisfiableDependency",
+			packages: []action.PackageFilter{
+				{Name: "foo", Channels: []string{"beta"}, VersionRange: ">=0.3.0"},
+			},
+			includeDependencies: true,
+			catalogEdit: func(catalog string) string {
+				return strings.Replace(catalog, "versionRange: <0.3.0", "versionRange: '>=1.0.0'", 1)
+			},
+			expectErr: `bundle "foo.v0.3.0" requires package "bar" with version range ">=1.0.0", which no bundle in the catalog satisfies`,
+		},
+		{
+			name:      "Error/NoPackages",
+			expectErr: "at least one package must be specified",
+		},
+	}
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			catalogDir := t.TempDir()
+			catalog := pruneCatalog
+			if s.catalogEdit != nil {
+				catalog = s.catalogEdit(catalog)
+			}
+			require.NoError(t, os.WriteFile(filepath.Join(catalogDir, "catalog.yaml"), []byte(catalog), 0600))
+
+			p := action.Prune{
+				CatalogRef:          catalogDir,
+				Packages:            s.packages,
+				IncludeDependencies: s.includeDependencies,
+				OutputDir:           filepath.Join(t.TempDir(), "out"),
+				WriteFunc:           declcfg.WriteYAML,
+				FileExt:             ".yaml",
+			}
+			err := p.Run(context.Background())
+			if s.expectErr != "" {
+				require.ErrorContains(t, err, s.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			cfg, err := declcfg.LoadFS(context.Background(), os.DirFS(p.OutputDir))
+			require.NoError(t, err)
+
+			actualDefaultChannel := map[string]string{}
+			for _, pkg := range cfg.Packages {
+				actualDefaultChannel[pkg.Name] = pkg.DefaultChannel
+			}
+			require.Equal(t, s.expectedDefaultChannel, actualDefaultChannel)
+
+			actualEntries := map[string][]declcfg.ChannelEntry{}
+			bundlesInChannels := map[string]struct{}{}
+			for _, ch := range cfg.Channels {
+				actualEntries[ch.Package+"/"+ch.Name] = ch.Entries
+				for _, e := range ch.Entries {
+					bundlesInChannels[e.Name] = struct{}{}
+				}
+			}
+			require.Equal(t, s.expectedEntries, actualEntries)
+
+			var actualBundles []string
+			for _, b := range cfg.Bundles {
+				actualBundles = append(actualBundles, b.Name)
+				require.Contains(t, bundlesInChannels, b.Name)
+			}
+			require.Len(t, actualBundles, len(bundlesInChannels))
+
+			var actualDeprecations []string
+			for _, d := range cfg.Deprecations {
+				for _, e := range d.Entries {
+					actualDeprecations = append(actualDeprecations, e.Reference.Name)
+				}
+			}
+			require.ElementsMatch(t, s.expectedDeprecations, actualDeprecations)
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/api/pkg/lib/version"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/operator-registry/alpha/model"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/pkg/image"
+	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+// RebuildBundle reconstructs the directory of a registry+v1 bundle from its
+// olm.bundle entry in a catalog, so that the bundle can be patched and built
+// again. The directory contains:
+//
+//   - the bundle's manifests, from its olm.bundle.object properties,
+//   - its metadata: annotations.yaml with the bundle's package and channels,
+//     dependencies.yaml with its olm.package.required, olm.gvk.required and
+//     olm.constraint properties, and properties.yaml with its other declared
+//     properties,
+//   - a bundle.Dockerfile to build the bundle image.
+//
+// Bundles without objects, such as bundles rendered from sqlite catalogs, are
+// rebuilt with a ClusterServiceVersion generated from their olm.csv.metadata
+// property. This CSV has no install strategy, which must be added before the
+// bundle can be installed.
+type RebuildBundle struct {
+	IndexReference string
+	PackageName    string
+	BundleName     string
+	// OutputDir is the directory the bundle is written to. It must not exist
+	// or be empty.
+	OutputDir string
+	// BaseImage is the base image of the bundle.Dockerfile. It defaults to
+	// scratch.
+	BaseImage string
+	Registry  image.Registry
+}
+
+func (r RebuildBundle) Run(ctx context.Context) error {
+	if r.OutputDir == "" {
+		return errors.New("output directory must be set")
+	}
+	if entries, err := os.ReadDir(r.OutputDir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("output directory %q is not empty", r.OutputDir)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	m, err := indexRefToModel(ctx, r.IndexReference, r.Registry)
+	if err != nil {
+		return err
+	}
+	pkg, ok := m[r.PackageName]
+	if !ok {
+		return fmt.Errorf("package %q not found", r.PackageName)
+	}
+	var (
+		b        *model.Bundle
+		channels []string
+	)
+	for _, ch := range pkg.Channels {
+		if cb, ok := ch.Bundles[r.BundleName]; ok {
+			b = cb
+			channels = append(channels, ch.Name)
+		}
+	}
+	if b == nil {
+		return fmt.Errorf("bundle %q not found in package %q", r.BundleName, r.PackageName)
+	}
+	sort.Strings(channels)
+	var defaultChannel string
+	if pkg.DefaultChannel != nil {
+		if _, ok := pkg.DefaultChannel.Bundles[b.Name]; ok {
+			defaultChannel = pkg.DefaultChannel.Name
+		}
+	}
+
+	props, err := property.Parse(b.Properties)
+	if err != nil {
+		return fmt.Errorf("bundle %q: %v", b.Name, err)
+	}
+	manifests, err := bundleManifests(b, props)
+	if err != nil {
+		return fmt.Errorf("bundle %q: %v", b.Name, err)
+	}
+
+	manifestsDir := filepath.Join(r.OutputDir, bundle.ManifestsDir)
+	metadataDir := filepath.Join(r.OutputDir, bundle.MetadataDir)
+	for name, data := range manifests {
+		if err := bundle.WriteFile(name, manifestsDir, data); err != nil {
+			return err
+		}
+	}
+
+	annotations, err := bundle.GenerateAnnotations(bundle.RegistryV1Type, bundle.ManifestsDir, bundle.MetadataDir, pkg.Name, strings.Join(channels, ","), defaultChannel)
+	if err != nil {
+		return err
+	}
+	if err := bundle.WriteFile(bundle.AnnotationsFile, metadataDir, annotations); err != nil {
+		return err
+	}
+	deps, err := bundleDependencies(props)
+	if err != nil {
+		return err
+	}
+	if len(deps.Dependencies) > 0 {
+		if err := writeYAML(filepath.Join(metadataDir, "dependencies.yaml"), deps); err != nil {
+			return err
+		}
+	}
+	if len(props.Others) > 0 || len(props.Deprecations) > 0 {
+		pf := registry.PropertiesFile{}
+		for _, p := range props.Others {
+			pf.Properties = append(pf.Properties, registry.Property{Type: p.Type, Value: p.Value})
+		}
+		for range props.Deprecations {
+			p := property.MustBuildDeprecated()
+			pf.Properties = append(pf.Properties, registry.Property{Type: p.Type, Value: p.Value})
+		}
+		if err := writeYAML(filepath.Join(metadataDir, "properties.yaml"), pf); err != nil {
+			return err
+		}
+	}
+
+	baseImage := r.BaseImage
+	if baseImage == "" {
+		baseImage = "scratch"
+	}
+	dockerfile, err := bundle.GenerateDockerfile(bundle.RegistryV1Type, bundle.ManifestsDir, bundle.MetadataDir, manifestsDir, metadataDir, r.OutputDir, pkg.Name, strings.Join(channels, ","), defaultChannel, baseImage)
+	if err != nil {
+		return err
+	}
+	return bundle.WriteFile(bundle.DockerFile, r.OutputDir, dockerfile)
+}
+
+// bundleManifests returns the YAML manifests of b, by file name.
+func bundleManifests(b *model.Bundle, props *property.Properties) (map[string][]byte, error) {
+	objects := b.Objects
+	if len(objects) == 0 {
+		if len(props.CSVMetadatas) == 0 {
+			return nil, fmt.Errorf("bundle has neither %s nor %s properties to rebuild it from", property.TypeBundleObject, property.TypeCSVMetadata)
+		}
+		csv, err := csvFromMetadata(b, props.CSVMetadatas[0])
+		if err != nil {
+			return nil, err
+		}
+		objects = []string{csv}
+	}
+
+	manifests := map[string][]byte{}
+	for i, obj := range objects {
+		var meta struct {
+			metav1.TypeMeta   `json:",inline"`
+			metav1.ObjectMeta `json:"metadata"`
+		}
+		if err := json.Unmarshal([]byte(obj), &meta); err != nil {
+			return nil, fmt.Errorf("parse object %d: %v", i, err)
+		}
+		data, err := yaml.JSONToYAML([]byte(obj))
+		if err != nil {
+			return nil, fmt.Errorf("convert object %d to yaml: %v", i, err)
+		}
+		name := fmt.Sprintf("%s.%s.yaml", meta.Name, strings.ToLower(meta.Kind))
+		if _, ok := manifests[name]; ok {
+			name = fmt.Sprintf("%s.%s.%d.yaml", meta.Name, strings.ToLower(meta.Kind), i)
+		}
+		manifests[name] = data
+	}
+	return manifests, nil
+}
+
+// csvFromMetadata generates the ClusterServiceVersion of b from its CSV
+// metadata and upgrade edges.
+func csvFromMetadata(b *model.Bundle, meta property.CSVMetadata) (string, error) {
+	if b.Version.Equals(semver.Version{}) {
+		return "", errors.New("bundle version must be set")
+	}
+	csv := v1alpha1.ClusterServiceVersion{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       v1alpha1.ClusterServiceVersionKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.Name,
+			Annotations: meta.Annotations,
+			Labels:      meta.Labels,
+		},
+		Spec: v1alpha1.ClusterServiceVersionSpec{
+			APIServiceDefinitions:     meta.APIServiceDefinitions,
+			CustomResourceDefinitions: meta.CustomResourceDefinitions,
+			Description:               meta.Description,
+			DisplayName:               meta.DisplayName,
+			InstallModes:              meta.InstallModes,
+			Keywords:                  meta.Keywords,
+			Links:                     meta.Links,
+			Maintainers:               meta.Maintainers,
+			Maturity:                  meta.Maturity,
+			MinKubeVersion:            meta.MinKubeVersion,
+			NativeAPIs:                meta.NativeAPIs,
+			Provider:                  meta.Provider,
+			Replaces:                  b.Replaces,
+			Skips:                     b.Skips,
+			Version:                   version.OperatorVersion{Version: b.Version},
+		},
+	}
+	if b.SkipRange != "" {
+		if csv.Annotations == nil {
+			csv.Annotations = map[string]string{}
+		}
+		csv.Annotations["olm.skipRange"] = b.SkipRange
+	}
+	for _, ri := range b.RelatedImages {
+		csv.Spec.RelatedImages = append(csv.Spec.RelatedImages, v1alpha1.RelatedImage{Name: ri.Name, Image: ri.Image})
+	}
+	data, err := json.Marshal(csv)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// bundleDependencies returns the dependencies of a bundle declared by its
+// properties, in the format of a bundle's dependencies.yaml file.
+func bundleDependencies(props *property.Properties) (*registry.DependenciesFile, error) {
+	var values []interface{}
+	var types []string
+	for _, p := range props.PackagesRequired {
+		values = append(values, registry.PackageDependency{PackageName: p.PackageName, Version: p.VersionRange})
+		types = append(types, registry.PackageType)
+	}
+	for _, p := range props.GVKsRequired {
+		values = append(values, registry.GVKDependency{Group: p.Group, Kind: p.Kind, Version: p.Version})
+		types = append(types, registry.GVKType)
+	}
+	for _, c := range props.Constraints {
+		values = append(values, c)
+		types = append(types, registry.ConstraintType)
+	}
+
+	deps := &registry.DependenciesFile{}
+	for i, v := range values {
+		value, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		deps.Dependencies = append(deps.Dependencies, registry.Dependency{Type: types[i], Value: value})
+	}
+	return deps, nil
+}
+
+func writeYAML(path string, v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return bundle.WriteFile(filepath.Base(path), filepath.Dir(path), data)
+}
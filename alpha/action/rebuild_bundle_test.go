@@ -0,0 +1,99 @@
+package action_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+func TestRebuildBundle(t *testing.T) {
+	ctx := context.Background()
+
+	// Render a bundle directory into a catalog, and check that the bundle
+	// rebuilt from the catalog renders to the same bundle.
+	original, err := action.Render{Refs: []string{"testdata/foo-bundle-v0.2.0"}}.Run(ctx)
+	require.NoError(t, err)
+	require.Len(t, original.Bundles, 1)
+	fooBundle := original.Bundles[0]
+
+	csvOnly := declcfg.Bundle{
+		Schema:  declcfg.SchemaBundle,
+		Package: "foo",
+		Name:    "foo.v0.1.0",
+		Image:   "test.registry/foo-operator/foo-bundle:v0.1.0",
+		Properties: []property.Property{
+			property.MustBuildPackage("foo", "0.1.0"),
+			property.MustBuild(&property.CSVMetadata{DisplayName: "Foo Operator", Keywords: []string{"foo"}}),
+		},
+	}
+	catalog := declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{{Schema: declcfg.SchemaPackage, Name: "foo", DefaultChannel: "beta"}},
+		Channels: []declcfg.Channel{
+			{Schema: declcfg.SchemaChannel, Package: "foo", Name: "beta", Entries: []declcfg.ChannelEntry{{Name: "foo.v0.1.0"}, {Name: "foo.v0.2.0", Replaces: "foo.v0.1.0"}}},
+			{Schema: declcfg.SchemaChannel, Package: "foo", Name: "stable", Entries: []declcfg.ChannelEntry{{Name: "foo.v0.2.0"}}},
+		},
+		Bundles: []declcfg.Bundle{csvOnly, fooBundle},
+	}
+	catalogDir := t.TempDir()
+	var buf bytes.Buffer
+	require.NoError(t, declcfg.WriteYAML(catalog, &buf))
+	require.NoError(t, os.WriteFile(filepath.Join(catalogDir, "catalog.yaml"), buf.Bytes(), 0600))
+
+	t.Run("Success/FromObjects", func(t *testing.T) {
+		outputDir := filepath.Join(t.TempDir(), "bundle")
+		err := action.RebuildBundle{IndexReference: catalogDir, PackageName: "foo", BundleName: "foo.v0.2.0", OutputDir: outputDir}.Run(ctx)
+		require.NoError(t, err)
+
+		rebuilt, err := action.Render{Refs: []string{outputDir}}.Run(ctx)
+		require.NoError(t, err)
+		require.Len(t, rebuilt.Bundles, 1)
+		require.Equal(t, fooBundle.Package, rebuilt.Bundles[0].Package)
+		require.Equal(t, fooBundle.Name, rebuilt.Bundles[0].Name)
+		require.ElementsMatch(t, fooBundle.Properties, rebuilt.Bundles[0].Properties)
+		require.ElementsMatch(t, fooBundle.RelatedImages, rebuilt.Bundles[0].RelatedImages)
+
+		annotations, err := os.ReadFile(filepath.Join(outputDir, "metadata/annotations.yaml"))
+		require.NoError(t, err)
+		require.Contains(t, string(annotations), "operators.operatorframework.io.bundle.channels.v1: beta,stable")
+		require.Contains(t, string(annotations), "operators.operatorframework.io.bundle.channel.default.v1: beta")
+		require.FileExists(t, filepath.Join(outputDir, "bundle.Dockerfile"))
+	})
+
+	t.Run("Success/FromCSVMetadata", func(t *testing.T) {
+		outputDir := t.TempDir()
+		err := action.RebuildBundle{IndexReference: catalogDir, PackageName: "foo", BundleName: "foo.v0.1.0", OutputDir: outputDir}.Run(ctx)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(filepath.Join(outputDir, "manifests/foo.v0.1.0.clusterserviceversion.yaml"))
+		require.NoError(t, err)
+		var csv v1alpha1.ClusterServiceVersion
+		require.NoError(t, yaml.Unmarshal(data, &csv))
+		require.Equal(t, "foo.v0.1.0", csv.Name)
+		require.Equal(t, "0.1.0", csv.Spec.Version.String())
+		require.Equal(t, "Foo Operator", csv.Spec.DisplayName)
+		require.Equal(t, []string{"foo"}, csv.Spec.Keywords)
+	})
+
+	t.Run("Error/OutputDirNotEmpty", func(t *testing.T) {
+		outputDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(outputDir, "file"), nil, 0600))
+		err := action.RebuildBundle{IndexReference: catalogDir, PackageName: "foo", BundleName: "foo.v0.2.0", OutputDir: outputDir}.Run(ctx)
+		require.ErrorContains(t, err, "is not empty")
+	})
+
+	t.Run("Error/BundleNotFound", func(t *testing.T) {
+		err := action.RebuildBundle{IndexReference: catalogDir, PackageName: "foo", BundleName: "foo.v0.3.0", OutputDir: t.TempDir()}.Run(ctx)
+		require.EqualError(t, err, `bundle "foo.v0.3.0" not found in package "foo"`)
+	})
+}
@@ -0,0 +1,264 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+// Remove removes a package, a channel, or a bundle from a file-based catalog
+// directory.
+//
+// If only Package is set, the package and all of its content is removed. If
+// Channel is set, the channel is removed along with any bundles that are no
+// longer in a channel as a result. If Bundle is set, its entry is removed from
+// Channel (or from every channel in the package if Channel is not set), and
+// entries that replaced it are updated to replace the bundle it replaced. The
+// bundle itself is removed if it is no longer in any channel.
+//
+// Remove fails with ErrInvalidRemoval if the resulting catalog would be invalid
+// unless Force is set.
+type Remove struct {
+	CatalogDir string
+	Package    string
+	Channel    string
+	Bundle     string
+	Force      bool
+}
+
+// ErrInvalidRemoval is returned by Remove when the removal would leave the
+// catalog invalid.
+var ErrInvalidRemoval = errors.New("the resulting catalog would be invalid")
+
+func (r Remove) Run(_ context.Context) error {
+	if r.Package == "" {
+		return fmt.Errorf("package name must be specified")
+	}
+	files, err := loadCatalogFiles(r.CatalogDir)
+	if err != nil {
+		return err
+	}
+	if files.packageFile(r.Package) == "" {
+		return fmt.Errorf("package %q not found in catalog %q", r.Package, r.CatalogDir)
+	}
+
+	e := &catalogEdit{files: files, pkg: r.Package, modified: sets.New[string]()}
+	switch {
+	case r.Bundle != "":
+		err = e.removeBundle(r.Channel, r.Bundle)
+	case r.Channel != "":
+		err = e.removeChannel(r.Channel)
+	default:
+		e.removePackage()
+	}
+	if err != nil {
+		return err
+	}
+
+	if !r.Force {
+		if _, err := declcfg.ConvertToModel(files.merged()); err != nil {
+			return fmt.Errorf("refusing to remove %q: %w: %v", r.ref(), ErrInvalidRemoval, err)
+		}
+	}
+
+	return writeModifiedFiles(r.CatalogDir, files, e.modified)
+}
+
+func (r Remove) ref() string {
+	switch {
+	case r.Bundle != "":
+		return r.Package + ":" + r.Channel + ":" + r.Bundle
+	case r.Channel != "":
+		return r.Package + ":" + r.Channel
+	}
+	return r.Package
+}
+
+type catalogEdit struct {
+	files    catalogFiles
+	pkg      string
+	modified sets.Set[string]
+}
+
+func (e *catalogEdit) removePackage() {
+	for path, cfg := range e.files {
+		n := configLen(*cfg)
+		cfg.Packages = filterSlice(cfg.Packages, func(p declcfg.Package) bool { return p.Name != e.pkg })
+		cfg.Channels = filterSlice(cfg.Channels, func(c declcfg.Channel) bool { return c.Package != e.pkg })
+		cfg.Bundles = filterSlice(cfg.Bundles, func(b declcfg.Bundle) bool { return b.Package != e.pkg })
+		cfg.Deprecations = filterSlice(cfg.Deprecations, func(d declcfg.Deprecation) bool { return d.Package != e.pkg })
+		cfg.Icons = filterSlice(cfg.Icons, func(i declcfg.PackageIcon) bool { return i.Package != e.pkg })
+		cfg.Extensions = filterSlice(cfg.Extensions, func(x declcfg.Extension) bool { return x.Package != e.pkg })
+		cfg.Others = filterSlice(cfg.Others, func(m declcfg.Meta) bool { return m.Package != e.pkg })
+		if configLen(*cfg) != n {
+			e.modified.Insert(path)
+		}
+	}
+}
+
+func (e *catalogEdit) removeChannel(chName string) error {
+	path, ch := e.files.channel(e.pkg, chName)
+	if ch == nil {
+		return fmt.Errorf("channel %q not found in package %q", chName, e.pkg)
+	}
+	candidates := sets.New[string]()
+	for _, entry := range ch.Entries {
+		candidates.Insert(entry.Name)
+	}
+
+	cfg := e.files[path]
+	cfg.Channels = filterSlice(cfg.Channels, func(c declcfg.Channel) bool { return c.Package != e.pkg || c.Name != chName })
+	e.modified.Insert(path)
+
+	pruned := e.pruneBundles(candidates)
+	e.pruneDeprecations(sets.New(chName), pruned)
+	return nil
+}
+
+func (e *catalogEdit) removeBundle(chName, bundleName string) error {
+	type channelRef struct {
+		path string
+		ch   *declcfg.Channel
+	}
+	var channels []channelRef
+	if chName != "" {
+		path, ch := e.files.channel(e.pkg, chName)
+		if ch == nil {
+			return fmt.Errorf("channel %q not found in package %q", chName, e.pkg)
+		}
+		channels = append(channels, channelRef{path, ch})
+	} else {
+		for path, cfg := range e.files {
+			for i := range cfg.Channels {
+				if cfg.Channels[i].Package == e.pkg {
+					channels = append(channels, channelRef{path, &cfg.Channels[i]})
+				}
+			}
+		}
+	}
+
+	found := false
+	for _, c := range channels {
+		if removeChannelEntry(c.ch, bundleName) {
+			found = true
+			e.modified.Insert(c.path)
+		}
+	}
+	if !found {
+		if chName != "" {
+			return fmt.Errorf("bundle %q not found in channel %q of package %q", bundleName, chName, e.pkg)
+		}
+		return fmt.Errorf("bundle %q not found in any channel of package %q", bundleName, e.pkg)
+	}
+
+	pruned := e.pruneBundles(sets.New(bundleName))
+	e.pruneDeprecations(sets.New[string](), pruned)
+	return nil
+}
+
+// removeChannelEntry removes the entry for bundleName from ch, and repairs the
+// upgrade graph by making entries that replaced it replace its predecessor.
+func removeChannelEntry(ch *declcfg.Channel, bundleName string) bool {
+	idx := -1
+	for i, entry := range ch.Entries {
+		if entry.Name == bundleName {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return false
+	}
+	removed := ch.Entries[idx]
+	ch.Entries = append(ch.Entries[:idx], ch.Entries[idx+1:]...)
+
+	for i := range ch.Entries {
+		entry := &ch.Entries[i]
+		if entry.Replaces == bundleName {
+			entry.Replaces = removed.Replaces
+		}
+		entry.Skips = filterSlice(entry.Skips, func(s string) bool { return s != bundleName })
+	}
+	return true
+}
+
+// pruneBundles removes each of the candidate bundles that is no longer in
+// any channel of the package, and returns the names of the removed bundles.
+func (e *catalogEdit) pruneBundles(candidates sets.Set[string]) sets.Set[string] {
+	inChannel := sets.New[string]()
+	for _, cfg := range e.files {
+		for _, ch := range cfg.Channels {
+			if ch.Package != e.pkg {
+				continue
+			}
+			for _, entry := range ch.Entries {
+				inChannel.Insert(entry.Name)
+			}
+		}
+	}
+	orphans := candidates.Difference(inChannel)
+	if orphans.Len() == 0 {
+		return orphans
+	}
+
+	for path, cfg := range e.files {
+		n := len(cfg.Bundles)
+		cfg.Bundles = filterSlice(cfg.Bundles, func(b declcfg.Bundle) bool { return b.Package != e.pkg || !orphans.Has(b.Name) })
+		if len(cfg.Bundles) != n {
+			e.modified.Insert(path)
+		}
+	}
+	return orphans
+}
+
+// pruneDeprecations removes deprecation entries that reference removed
+// channels or bundles, and deprecation blobs that have no entries left.
+func (e *catalogEdit) pruneDeprecations(channels, bundles sets.Set[string]) {
+	for path, cfg := range e.files {
+		changed := false
+		for i := range cfg.Deprecations {
+			d := &cfg.Deprecations[i]
+			if d.Package != e.pkg {
+				continue
+			}
+			n := len(d.Entries)
+			d.Entries = filterSlice(d.Entries, func(entry declcfg.DeprecationEntry) bool {
+				switch entry.Reference.Schema {
+				case declcfg.SchemaChannel:
+					return !channels.Has(entry.Reference.Name)
+				case declcfg.SchemaBundle:
+					return !bundles.Has(entry.Reference.Name)
+				}
+				return true
+			})
+			changed = changed || len(d.Entries) != n
+		}
+		if !changed {
+			continue
+		}
+		cfg.Deprecations = filterSlice(cfg.Deprecations, func(d declcfg.Deprecation) bool { return d.Package != e.pkg || len(d.Entries) > 0 })
+		e.modified.Insert(path)
+	}
+}
+
+func filterSlice[T any](in []T, keep func(T) bool) []T {
+	out := in[:0]
+	for _, v := range in {
+		if keep(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func configLen(cfg declcfg.DeclarativeConfig) int {
+	return len(cfg.Packages) + len(cfg.Channels) + len(cfg.Bundles) + len(cfg.Deprecations) + len(cfg.Icons) + len(cfg.Catalogs) + len(cfg.Extensions) + len(cfg.Others)
+}
+
+func isEmptyConfig(cfg declcfg.DeclarativeConfig) bool {
+	return configLen(cfg) == 0
+}
@@ -0,0 +1,221 @@
+package action_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+const removeFooCatalog = `---
+schema: olm.package
+name: foo
+defaultChannel: beta
+---
+schema: olm.channel
+package: foo
+name: beta
+entries:
+  - name: foo.v0.1.0
+  - name: foo.v0.2.0
+    replaces: foo.v0.1.0
+---
+schema: olm.channel
+package: foo
+name: stable
+entries:
+  - name: foo.v0.2.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.1.0
+image: test.registry/foo-operator/foo-bundle:v0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.1.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.2.0
+image: test.registry/foo-operator/foo-bundle:v0.2.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.2.0
+---
+schema: olm.deprecations
+package: foo
+entries:
+  - reference:
+      schema: olm.channel
+      name: stable
+    message: stable is deprecated
+  - reference:
+      schema: olm.bundle
+      name: foo.v0.1.0
+    message: foo.v0.1.0 is deprecated
+`
+
+const removeBarCatalog = `---
+schema: olm.package
+name: bar
+defaultChannel: alpha
+---
+schema: olm.channel
+package: bar
+name: alpha
+entries:
+  - name: bar.v0.1.0
+---
+schema: olm.bundle
+package: bar
+name: bar.v0.1.0
+image: test.registry/bar-operator/bar-bundle:v0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: bar
+      version: 0.1.0
+`
+
+func TestRemove(t *testing.T) {
+	type spec struct {
+		name                 string
+		remove               action.Remove
+		expectFooRemoved     bool
+		expectedEntries      map[string][]declcfg.ChannelEntry
+		expectedBundles      []string
+		expectedDeprecations []string
+		expectErr            string
+	}
+
+	specs := []spec{
+		{
+			name:             "Package/Success",
+			remove:           action.Remove{Package: "foo"},
+			expectFooRemoved: true,
+		},
+		{
+			name:   "Channel/Success",
+			remove: action.Remove{Package: "foo", Channel: "stable"},
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"beta": {{Name: "foo.v0.1.0"}, {Name: "foo.v0.2.0", Replaces: "foo.v0.1.0"}},
+			},
+			expectedBundles:      []string{"foo.v0.1.0", "foo.v0.2.0"},
+			expectedDeprecations: []string{"foo.v0.1.0"},
+		},
+		{
+			name:   "Channel/ForcePrunesOrphanedBundles",
+			remove: action.Remove{Package: "foo", Channel: "beta", Force: true},
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"stable": {{Name: "foo.v0.2.0"}},
+			},
+			expectedBundles:      []string{"foo.v0.2.0"},
+			expectedDeprecations: []string{"stable"},
+		},
+		{
+			name:      "Channel/DefaultChannel",
+			remove:    action.Remove{Package: "foo", Channel: "beta"},
+			expectErr: `refusing to remove "foo:beta": the resulting catalog would be invalid`,
+		},
+		{
+			name:   "Bundle/RepairsReplaces",
+			remove: action.Remove{Package: "foo", Channel: "beta", Bundle: "foo.v0.1.0"},
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"beta":   {{Name: "foo.v0.2.0"}},
+				"stable": {{Name: "foo.v0.2.0"}},
+			},
+			expectedBundles:      []string{"foo.v0.2.0"},
+			expectedDeprecations: []string{"stable"},
+		},
+		{
+			name:   "Bundle/KeepsBundleInOtherChannels",
+			remove: action.Remove{Package: "foo", Channel: "beta", Bundle: "foo.v0.2.0"},
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"beta":   {{Name: "foo.v0.1.0"}},
+				"stable": {{Name: "foo.v0.2.0"}},
+			},
+			expectedBundles:      []string{"foo.v0.1.0", "foo.v0.2.0"},
+			expectedDeprecations: []string{"stable", "foo.v0.1.0"},
+		},
+		{
+			name:      "Bundle/AllChannelsEmptiesChannel",
+			remove:    action.Remove{Package: "foo", Bundle: "foo.v0.2.0"},
+			expectErr: `refusing to remove "foo::foo.v0.2.0": the resulting catalog would be invalid`,
+		},
+		{
+			name:      "Bundle/NotFound",
+			remove:    action.Remove{Package: "foo", Channel: "stable", Bundle: "foo.v0.1.0"},
+			expectErr: `bundle "foo.v0.1.0" not found in channel "stable" of package "foo"`,
+		},
+		{
+			name:      "Channel/NotFound",
+			remove:    action.Remove{Package: "foo", Channel: "fast"},
+			expectErr: `channel "fast" not found in package "foo"`,
+		},
+		{
+			name:      "Package/NotFound",
+			remove:    action.Remove{Package: "baz"},
+			expectErr: `package "baz" not found in catalog`,
+		},
+	}
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for path, content := range map[string]string{"foo/catalog.yaml": removeFooCatalog, "bar/catalog.yaml": removeBarCatalog} {
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0777))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0600))
+			}
+
+			s.remove.CatalogDir = dir
+			err := s.remove.Run(context.Background())
+			if s.expectErr != "" {
+				require.ErrorContains(t, err, s.expectErr)
+				actual, err := os.ReadFile(filepath.Join(dir, "foo/catalog.yaml"))
+				require.NoError(t, err)
+				require.Equal(t, removeFooCatalog, string(actual), "catalog must not be modified on error")
+				return
+			}
+			require.NoError(t, err)
+
+			barCfg, err := declcfg.LoadFile(os.DirFS(dir), "bar/catalog.yaml")
+			require.NoError(t, err)
+			require.Len(t, barCfg.Packages, 1)
+
+			if s.expectFooRemoved {
+				require.NoFileExists(t, filepath.Join(dir, "foo/catalog.yaml"))
+				return
+			}
+
+			cfg, err := declcfg.LoadFile(os.DirFS(dir), "foo/catalog.yaml")
+			require.NoError(t, err)
+			actualEntries := map[string][]declcfg.ChannelEntry{}
+			for _, ch := range cfg.Channels {
+				actualEntries[ch.Name] = ch.Entries
+			}
+			require.Equal(t, s.expectedEntries, actualEntries)
+
+			var actualBundles []string
+			for _, b := range cfg.Bundles {
+				actualBundles = append(actualBundles, b.Name)
+			}
+			require.ElementsMatch(t, s.expectedBundles, actualBundles)
+
+			var actualDeprecations []string
+			for _, d := range cfg.Deprecations {
+				for _, e := range d.Entries {
+					actualDeprecations = append(actualDeprecations, e.Reference.Name)
+				}
+			}
+			require.ElementsMatch(t, s.expectedDeprecations, actualDeprecations)
+		})
+	}
+}
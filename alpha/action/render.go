@@ -15,6 +15,9 @@ import (
 
 	"github.com/h2non/filetype"
 	"github.com/h2non/filetype/matchers"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/operator-framework/operator-registry/alpha/action/migrations"
@@ -24,12 +27,15 @@ import (
 	"github.com/operator-framework/operator-registry/pkg/image"
 	"github.com/operator-framework/operator-registry/pkg/image/containersimageregistry"
 	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	"github.com/operator-framework/operator-registry/pkg/lib/tracing"
 	"github.com/operator-framework/operator-registry/pkg/registry"
 	"github.com/operator-framework/operator-registry/pkg/sqlite"
 )
 
 var logDeprecationMessage sync.Once
 
+var tracer = otel.Tracer("github.com/operator-framework/operator-registry/alpha/action")
+
 type RefType uint
 
 const (
@@ -39,6 +45,9 @@ const (
 	RefDCImage
 	RefDCDir
 	RefBundleDir
+	RefDCArtifact
+	RefHelmChart
+	RefGit
 
 	RefAll = 0
 )
@@ -55,17 +64,56 @@ type Render struct {
 	AllowedRefMask   RefType
 	ImageRefTemplate *template.Template
 	Migrations       *migrations.Migrations
+	// ComputeBundleSize adds an olm.bundle.size property, computed from the
+	// bundle's objects, to rendered bundles that don't already have one.
+	ComputeBundleSize bool
+	// SkipTLSVerify and PlainHTTP configure the registry client of the refs
+	// with ArtifactRefPrefix. Images are pulled with Registry.
+	SkipTLSVerify bool
+	PlainHTTP     bool
+	// DeduplicateBundles removes the bundles whose content is identical to a
+	// bundle rendered earlier, from the same or a previous ref, so that refs
+	// that partially overlap can be combined. Each duplicated bundle is
+	// passed to OnDuplicateBundle, if set.
+	DeduplicateBundles bool
+	OnDuplicateBundle  func(DuplicateBundle)
+	// Objects is how the objects of the rendered bundles are output. It
+	// defaults to BundleObjectsInline. With BundleObjectsReference, objects
+	// are written to ObjectsDir, a relative path that the references are
+	// recorded with, so the rendered catalog must be written to the current
+	// directory for the references to resolve.
+	Objects    BundleObjects
+	ObjectsDir string
+	// EdgeProvenance adds a TypeEdgeProvenance property to the channels
+	// rendered from sqlite databases, which records how their entries are
+	// reached and whether their replaces edges were declared by CSVs.
+	EdgeProvenance bool
+	// Offline forbids network access, for hermetic builds: references that
+	// can only be read from the network, such as git references and OCI
+	// artifacts, are not allowed, and images are only read from the image
+	// cache of Registry or from OCI layouts. All the refs are rendered before
+	// the images that are not cached are reported together. Registry must be
+	// offline too, as with containersimageregistry.WithOffline, unless it is
+	// created by Run.
+	Offline bool
 
 	skipSqliteDeprecationLog bool
 }
 
-func (r Render) Run(ctx context.Context) (*declcfg.DeclarativeConfig, error) {
+func (r Render) Run(ctx context.Context) (_ *declcfg.DeclarativeConfig, err error) {
+	ctx, span := tracer.Start(ctx, "action.Render", trace.WithAttributes(attribute.StringSlice("refs", r.Refs)))
+	defer func() { tracing.End(span, err) }()
+
 	if r.skipSqliteDeprecationLog {
 		// exhaust once with a no-op function.
 		logDeprecationMessage.Do(func() {})
 	}
 	if r.Registry == nil {
-		reg, err := containersimageregistry.NewDefault()
+		var opts []containersimageregistry.Option
+		if r.Offline {
+			opts = append(opts, containersimageregistry.WithOffline())
+		}
+		reg, err := containersimageregistry.New(containersimageregistry.DefaultSystemContext, opts...)
 		if err != nil {
 			return nil, fmt.Errorf("create registry: %v", err)
 		}
@@ -76,12 +124,29 @@ func (r Render) Run(ctx context.Context) (*declcfg.DeclarativeConfig, error) {
 	}
 
 	// nolint:prealloc
-	var cfgs []declcfg.DeclarativeConfig
+	var (
+		cfgs    []declcfg.DeclarativeConfig
+		missing []string
+	)
 	for _, ref := range r.Refs {
-		cfg, err := r.renderReference(ctx, ref)
+		refCtx, refSpan := tracer.Start(ctx, "action.Render.reference", trace.WithAttributes(attribute.String("ref", ref)))
+		cfg, err := r.renderReference(refCtx, ref)
+		tracing.End(refSpan, err)
+		if r.Offline && errors.Is(err, image.ErrNotCached) {
+			missing = append(missing, ref)
+			continue
+		}
 		if err != nil {
 			return nil, fmt.Errorf("render reference %q: %w", ref, err)
 		}
+		if r.ComputeBundleSize {
+			if err := addBundleSizes(cfg); err != nil {
+				return nil, fmt.Errorf("render reference %q: %v", ref, err)
+			}
+		}
+		if err := validateClusterVersions(*cfg); err != nil {
+			return nil, fmt.Errorf("render reference %q: %v", ref, err)
+		}
 		moveBundleObjectsToEndOfPropertySlices(cfg)
 
 		for _, b := range cfg.Bundles {
@@ -96,11 +161,55 @@ func (r Render) Run(ctx context.Context) (*declcfg.DeclarativeConfig, error) {
 
 		cfgs = append(cfgs, *cfg)
 	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("cannot render offline, %d references are %w: %s", len(missing), image.ErrNotCached, strings.Join(missing, ", "))
+	}
+
+	if r.DeduplicateBundles {
+		duplicates, err := deduplicateBundles(r.Refs, cfgs)
+		if err != nil {
+			return nil, fmt.Errorf("deduplicate bundles: %v", err)
+		}
+		if r.OnDuplicateBundle != nil {
+			for _, d := range duplicates {
+				r.OnDuplicateBundle(d)
+			}
+		}
+	}
 
-	return combineConfigs(cfgs), nil
+	cfg := combineConfigs(cfgs)
+	if err := outputBundleObjects(cfg, r.Objects, r.ObjectsDir); err != nil {
+		return nil, fmt.Errorf("output bundle objects: %v", err)
+	}
+	return cfg, nil
 }
 
 func (r Render) renderReference(ctx context.Context, ref string) (*declcfg.DeclarativeConfig, error) {
+	if r.Offline {
+		for _, prefix := range []string{ArtifactRefPrefix, HelmChartRefPrefix, GitRefPrefix} {
+			if strings.HasPrefix(ref, prefix) {
+				return nil, fmt.Errorf("cannot render %q offline, %q references are read from the network: %w", ref, prefix, ErrNotAllowed)
+			}
+		}
+	}
+	if strings.HasPrefix(ref, ArtifactRefPrefix) {
+		if !r.AllowedRefMask.Allowed(RefDCArtifact) {
+			return nil, fmt.Errorf("cannot render declarative config artifact: %w", ErrNotAllowed)
+		}
+		return r.artifactToDeclcfg(ctx, ref)
+	}
+	if strings.HasPrefix(ref, HelmChartRefPrefix) {
+		if !r.AllowedRefMask.Allowed(RefHelmChart) {
+			return nil, fmt.Errorf("cannot render helm chart: %w", ErrNotAllowed)
+		}
+		return r.helmChartToDeclcfg(ctx, ref)
+	}
+	if strings.HasPrefix(ref, GitRefPrefix) {
+		if !r.AllowedRefMask.Allowed(RefGit) {
+			return nil, fmt.Errorf("cannot render git reference: %w", ErrNotAllowed)
+		}
+		return r.gitToDeclcfg(ctx, ref)
+	}
 	stat, err := os.Stat(ref)
 	if err != nil {
 		return r.imageToDeclcfg(ctx, ref)
@@ -125,8 +234,14 @@ func (r Render) renderReference(ctx context.Context, ref string) (*declcfg.Decla
 		}
 		return declcfg.LoadFS(ctx, os.DirFS(ref))
 	}
-	// The only supported file type is an sqlite DB file,
-	// since declarative configs will be in a directory.
+	// The supported file types are Helm chart archives and sqlite DB
+	// files, since declarative configs will be in a directory.
+	if typ, err := filetype.MatchFile(ref); err == nil && typ == matchers.TypeGz {
+		if !r.AllowedRefMask.Allowed(RefHelmChart) {
+			return nil, fmt.Errorf("cannot render helm chart archive: %w", ErrNotAllowed)
+		}
+		return r.helmChartArchiveToDeclcfg(ref)
+	}
 	if err := checkDBFile(ref); err != nil {
 		return nil, err
 	}
@@ -134,18 +249,18 @@ func (r Render) renderReference(ctx context.Context, ref string) (*declcfg.Decla
 		return nil, fmt.Errorf("cannot render sqlite file: %w", ErrNotAllowed)
 	}
 
-	db, err := sqlite.Open(ref)
+	db, err := sqlite.Open(ref, sqlite.WithIntegrityCheck())
 	if err != nil {
 		return nil, err
 	}
 	defer db.Close()
-	return sqliteToDeclcfg(ctx, db)
+	return r.sqliteToDeclcfg(ctx, db)
 }
 
 func (r Render) imageToDeclcfg(ctx context.Context, imageRef string) (*declcfg.DeclarativeConfig, error) {
 	ref := image.SimpleReference(imageRef)
 	if err := r.Registry.Pull(ctx, ref); err != nil {
-		return nil, fmt.Errorf("failed to pull image %q: %v", ref, err)
+		return nil, fmt.Errorf("failed to pull image %q: %w", ref, err)
 	}
 	labels, err := r.Registry.Labels(ctx, ref)
 	if err != nil {
@@ -166,12 +281,12 @@ func (r Render) imageToDeclcfg(ctx context.Context, imageRef string) (*declcfg.D
 		if !r.AllowedRefMask.Allowed(RefSqliteImage) {
 			return nil, fmt.Errorf("cannot render sqlite image: %w", ErrNotAllowed)
 		}
-		db, err := sqlite.Open(filepath.Join(tmpDir, dbFile))
+		db, err := sqlite.Open(filepath.Join(tmpDir, dbFile), sqlite.WithIntegrityCheck())
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("failed to open database of image %q: %v", ref, err)
 		}
 		defer db.Close()
-		cfg, err = sqliteToDeclcfg(ctx, db)
+		cfg, err = r.sqliteToDeclcfg(ctx, db)
 		if err != nil {
 			return nil, err
 		}
@@ -224,7 +339,7 @@ func checkDBFile(ref string) error {
 	return nil
 }
 
-func sqliteToDeclcfg(ctx context.Context, db *sql.DB) (*declcfg.DeclarativeConfig, error) {
+func (r Render) sqliteToDeclcfg(ctx context.Context, db *sql.DB) (*declcfg.DeclarativeConfig, error) {
 	logDeprecationMessage.Do(func() {
 		sqlite.LogSqliteDeprecation()
 	})
@@ -252,6 +367,11 @@ func sqliteToDeclcfg(ctx context.Context, db *sql.DB) (*declcfg.DeclarativeConfi
 	if err := populateDBRelatedImages(ctx, &cfg, db); err != nil {
 		return nil, err
 	}
+	if r.EdgeProvenance {
+		if err := populateDBEdgeProvenance(ctx, &cfg, db); err != nil {
+			return nil, fmt.Errorf("populate edge provenance: %v", err)
+		}
+	}
 
 	return &cfg, nil
 }
@@ -339,6 +459,15 @@ func getRelatedImages(b *registry.Bundle) ([]declcfg.RelatedImage, error) {
 		return nil, err
 	}
 
+	// Plain bundles have no CSV declaring images, so only the bundle image
+	// is related.
+	if csv == nil {
+		if b.BundleImage == "" {
+			return nil, nil
+		}
+		return []declcfg.RelatedImage{{Image: b.BundleImage}}, nil
+	}
+
 	var objmap map[string]*json.RawMessage
 	if err = json.Unmarshal(csv.Spec, &objmap); err != nil {
 		return nil, err
@@ -399,6 +528,49 @@ func moveBundleObjectsToEndOfPropertySlices(cfg *declcfg.DeclarativeConfig) {
 	}
 }
 
+func addBundleSizes(cfg *declcfg.DeclarativeConfig) error {
+	for bi, b := range cfg.Bundles {
+		if len(b.Objects) == 0 {
+			continue
+		}
+		props, err := property.Parse(b.Properties)
+		if err != nil {
+			return fmt.Errorf("parse properties for bundle %q: %v", b.Name, err)
+		}
+		if len(props.BundleSizes) > 0 {
+			continue
+		}
+		size, err := property.ComputeBundleSize(b.Objects)
+		if err != nil {
+			return fmt.Errorf("compute size of bundle %q: %v", b.Name, err)
+		}
+		cfg.Bundles[bi].Properties = append(b.Properties, property.MustBuildBundleSize(size.ManifestBytes, size.Objects, size.CRDs))
+	}
+	return nil
+}
+
+// validateClusterVersions checks that the olm.minKubeVersion and
+// olm.maxOpenShiftVersion properties of the bundles of cfg are valid versions,
+// so that they are reported when a catalog is rendered rather than when
+// clients filter bundles by cluster version.
+func validateClusterVersions(cfg declcfg.DeclarativeConfig) error {
+	for _, b := range cfg.Bundles {
+		for _, p := range b.Properties {
+			if p.Type != property.TypeMinKubeVersion && p.Type != property.TypeMaxOpenShiftVersion {
+				continue
+			}
+			var v string
+			if err := json.Unmarshal(p.Value, &v); err != nil {
+				return fmt.Errorf("parse %s property of bundle %q: %v", p.Type, b.Name, err)
+			}
+			if _, err := property.ParseClusterVersion(v); err != nil {
+				return fmt.Errorf("invalid %s property %q of bundle %q: %v", p.Type, v, b.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
 func (r Render) migrate(cfg *declcfg.DeclarativeConfig) error {
 	// If there are no migrations, do nothing.
 	if r.Migrations == nil {
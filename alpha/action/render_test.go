@@ -1196,6 +1196,54 @@ func TestRender(t *testing.T) {
 			},
 			assertion: require.NoError,
 		},
+		{
+			name: "Success/BundleDirectoryWithBundleSize",
+			render: action.Render{
+				Refs:              []string{"testdata/foo-bundle-v0.2.0"},
+				Registry:          reg,
+				ComputeBundleSize: true,
+			},
+			expectCfg: &declcfg.DeclarativeConfig{
+				Bundles: []declcfg.Bundle{
+					{
+						Schema:  "olm.bundle",
+						Name:    "foo.v0.2.0",
+						Package: "foo",
+						Properties: []property.Property{
+							property.MustBuildGVK("test.foo", "v1", "Foo"),
+							property.MustBuildGVKRequired("test.bar", "v1alpha1", "Bar"),
+							property.MustBuildPackage("foo", "0.2.0"),
+							property.MustBuildPackageRequired("bar", "<0.1.0"),
+							property.MustBuildBundleSize(int64(len(foov2csv)+len(foov2crd)), 2, 1),
+							property.MustBuildBundleObject(foov2crd),
+							property.MustBuildBundleObject(foov2csv),
+						},
+						Objects: []string{string(foov2csv), string(foov2crd)},
+						CsvJSON: string(foov2csv),
+						RelatedImages: []declcfg.RelatedImage{
+							{
+								Image: "test.registry/foo-operator/foo-2:v0.2.0",
+							},
+							{
+								Image: "test.registry/foo-operator/foo-init-2:v0.2.0",
+							},
+							{
+								Image: "test.registry/foo-operator/foo-init:v0.2.0",
+							},
+							{
+								Name:  "other",
+								Image: "test.registry/foo-operator/foo-other:v0.2.0",
+							},
+							{
+								Name:  "operator",
+								Image: "test.registry/foo-operator/foo:v0.2.0",
+							},
+						},
+					},
+				},
+			},
+			assertion: require.NoError,
+		},
 	}
 
 	for _, s := range specs {
@@ -1217,6 +1265,273 @@ func TestRender(t *testing.T) {
 	}
 }
 
+func TestRenderInvalidClusterVersion(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "catalog.yaml"), []byte(`---
+schema: olm.bundle
+package: foo
+name: foo.v0.1.0
+image: test.registry/foo-operator/foo-bundle:v0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.1.0
+  - type: olm.maxOpenShiftVersion
+    value: "4.x"
+`), 0600))
+
+	_, err := action.Render{Refs: []string{dir}, AllowedRefMask: action.RefDCDir}.Run(context.Background())
+	require.ErrorContains(t, err, `invalid olm.maxOpenShiftVersion property "4.x" of bundle "foo.v0.1.0": Invalid character(s) found in minor number "x"`)
+}
+
+func TestRenderDeduplicateBundles(t *testing.T) {
+	dir1, dir2 := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir1, "catalog.yaml"), []byte(`---
+schema: olm.bundle
+package: foo
+name: foo.v0.1.0
+image: test.registry/foo-operator/foo-bundle:v0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.1.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.2.0
+image: test.registry/foo-operator/foo-bundle:v0.2.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.2.0
+`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir2, "catalog.json"), []byte(`{
+  "schema": "olm.bundle",
+  "image": "test.registry/foo-operator/foo-bundle:v0.1.0",
+  "name": "foo.v0.1.0",
+  "package": "foo",
+  "properties": [{"value": {"version": "0.1.0", "packageName": "foo"}, "type": "olm.package"}]
+}
+{
+  "schema": "olm.bundle",
+  "package": "foo",
+  "name": "foo.v0.2.0",
+  "image": "other.registry/foo-operator/foo-bundle:v0.2.0",
+  "properties": [{"type": "olm.package", "value": {"packageName": "foo", "version": "0.2.0"}}]
+}
+`), 0600))
+
+	bundleImages := func(cfg *declcfg.DeclarativeConfig) []string {
+		var images []string
+		for _, b := range cfg.Bundles {
+			images = append(images, b.Image)
+		}
+		return images
+	}
+
+	refs := []string{dir1, dir2, dir1}
+	cfg, err := action.Render{Refs: refs, AllowedRefMask: action.RefDCDir}.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, cfg.Bundles, 6)
+
+	var duplicates []action.DuplicateBundle
+	cfg, err = action.Render{
+		Refs:               refs,
+		AllowedRefMask:     action.RefDCDir,
+		DeduplicateBundles: true,
+		OnDuplicateBundle: func(d action.DuplicateBundle) {
+			duplicates = append(duplicates, d)
+		},
+	}.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"test.registry/foo-operator/foo-bundle:v0.1.0",
+		"test.registry/foo-operator/foo-bundle:v0.2.0",
+		"other.registry/foo-operator/foo-bundle:v0.2.0",
+	}, bundleImages(cfg))
+	require.Len(t, duplicates, 2)
+	require.Equal(t, "foo.v0.1.0", duplicates[0].Name)
+	require.Equal(t, "foo", duplicates[0].Package)
+	require.Equal(t, []string{dir1, dir2, dir1}, duplicates[0].Refs)
+	require.Len(t, duplicates[0].Digest, 64)
+	require.Equal(t, "foo.v0.2.0", duplicates[1].Name)
+	require.Equal(t, []string{dir1, dir1}, duplicates[1].Refs)
+	require.Contains(t, duplicates[1].String(), fmt.Sprintf("keeping the bundle rendered from %q", dir1))
+}
+
+// offlineRegistry is a registry whose Pull fails for the images that it
+// doesn't have as an offline registry does.
+type offlineRegistry struct {
+	image.Registry
+}
+
+func (r offlineRegistry) Pull(ctx context.Context, ref image.Reference) error {
+	if err := r.Registry.Pull(ctx, ref); err != nil {
+		return fmt.Errorf("%v: %w", err, image.ErrNotCached)
+	}
+	return nil
+}
+
+func TestRenderOffline(t *testing.T) {
+	reg, err := newRegistry(t)
+	require.NoError(t, err)
+	render := action.Render{
+		Refs: []string{
+			"test.registry/foo-operator/foo-bundle:v0.1.0",
+			"test.registry/foo-operator/missing:v0.1.0",
+			"test.registry/foo-operator/foo-bundle:v0.2.0",
+			"test.registry/foo-operator/missing:v0.2.0",
+		},
+		Registry: offlineRegistry{reg},
+		Offline:  true,
+	}
+	_, err = render.Run(context.Background())
+	require.ErrorIs(t, err, image.ErrNotCached)
+	require.EqualError(t, err, "cannot render offline, 2 references are not in the local image cache: test.registry/foo-operator/missing:v0.1.0, test.registry/foo-operator/missing:v0.2.0")
+
+	render.Refs = []string{"test.registry/foo-operator/foo-bundle:v0.1.0", "test.registry/foo-operator/foo-bundle:v0.2.0"}
+	cfg, err := render.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, cfg.Bundles, 2)
+
+	for _, ref := range []string{
+		action.GitRefPrefix + "https://github.com/example/catalog#catalog@v1.2.0",
+		action.ArtifactRefPrefix + "test.registry/foo-operator/foo-catalog:v0.2.0",
+		action.HelmChartRefPrefix + "test.registry/foo-operator/foo-chart:0.1.0",
+	} {
+		_, err := action.Render{Refs: []string{ref}, Registry: offlineRegistry{reg}, Offline: true}.Run(context.Background())
+		require.ErrorIs(t, err, action.ErrNotAllowed)
+	}
+}
+
+func TestRenderPlainBundleDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"manifests/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+`,
+		"metadata/annotations.yaml": `annotations:
+  operators.operatorframework.io.bundle.mediatype.v1: plain+v0
+  operators.operatorframework.io.bundle.package.v1: foo
+  operators.operatorframework.io.bundle.channels.v1: stable
+`,
+		"metadata/properties.yaml": `properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.1.0
+`,
+	}
+	for name, data := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0600))
+	}
+
+	cfg, err := action.Render{Refs: []string{dir}, AllowedRefMask: action.RefBundleDir}.Run(context.Background())
+	require.NoError(t, err)
+	configMap := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo"}}`
+	require.Equal(t, []declcfg.Bundle{{
+		Schema:  "olm.bundle",
+		Name:    "foo.v0.1.0",
+		Package: "foo",
+		Properties: []property.Property{
+			property.MustBuildBundleMediaType(property.MediaTypePlainV0),
+			property.MustBuildPackage("foo", "0.1.0"),
+			property.MustBuildBundleObject([]byte(configMap)),
+		},
+		Objects: []string{configMap},
+	}}, cfg.Bundles)
+}
+
+func TestRenderBundleObjects(t *testing.T) {
+	bundleDir := t.TempDir()
+	files := map[string]string{
+		"manifests/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+`,
+		"metadata/annotations.yaml": `annotations:
+  operators.operatorframework.io.bundle.mediatype.v1: plain+v0
+  operators.operatorframework.io.bundle.package.v1: foo
+  operators.operatorframework.io.bundle.channels.v1: stable
+`,
+		"metadata/properties.yaml": `properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.1.0
+`,
+	}
+	for name, data := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(bundleDir, filepath.Dir(name)), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(bundleDir, name), []byte(data), 0600))
+	}
+	configMap := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo"}}`
+
+	t.Run("Omit", func(t *testing.T) {
+		cfg, err := action.Render{
+			Refs:           []string{bundleDir},
+			AllowedRefMask: action.RefBundleDir,
+			Objects:        action.BundleObjectsOmit,
+		}.Run(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []property.Property{
+			property.MustBuildBundleMediaType(property.MediaTypePlainV0),
+			property.MustBuildPackage("foo", "0.1.0"),
+		}, cfg.Bundles[0].Properties)
+	})
+
+	t.Run("Reference", func(t *testing.T) {
+		catalogDir := t.TempDir()
+		t.Chdir(catalogDir)
+
+		cfg, err := action.Render{
+			Refs:           []string{bundleDir},
+			AllowedRefMask: action.RefBundleDir,
+			Objects:        action.BundleObjectsReference,
+			ObjectsDir:     "objects",
+		}.Run(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []property.Property{
+			property.MustBuildBundleMediaType(property.MediaTypePlainV0),
+			property.MustBuildPackage("foo", "0.1.0"),
+			property.MustBuild(&property.BundleObject{Ref: "objects/foo/foo.v0.1.0/configmap-foo.json"}),
+		}, cfg.Bundles[0].Properties)
+		data, err := os.ReadFile(filepath.Join(catalogDir, "objects", "foo", "foo.v0.1.0", "configmap-foo.json"))
+		require.NoError(t, err)
+		require.Equal(t, configMap, string(data))
+
+		// the catalog, written next to the objects directory, loads with
+		// its objects
+		f, err := os.Create(filepath.Join(catalogDir, "catalog.json"))
+		require.NoError(t, err)
+		require.NoError(t, declcfg.WriteJSON(*cfg, f))
+		require.NoError(t, f.Close())
+		loaded, err := declcfg.LoadFS(context.Background(), os.DirFS(catalogDir))
+		require.NoError(t, err)
+		require.Len(t, loaded.Bundles, 1)
+		require.Equal(t, []string{configMap}, loaded.Bundles[0].Objects)
+		require.Contains(t, loaded.Bundles[0].Properties, property.MustBuildBundleObject([]byte(configMap)))
+	})
+
+	t.Run("ObjectsDirOutsideCatalog", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		_, err := action.Render{
+			Refs:           []string{bundleDir},
+			AllowedRefMask: action.RefBundleDir,
+			Objects:        action.BundleObjectsReference,
+			ObjectsDir:     "../objects",
+		}.Run(context.Background())
+		require.EqualError(t, err, `output bundle objects: objects directory "../objects" must be a relative path within the directory of the catalog`)
+	})
+}
+
 func TestAllowRefMask(t *testing.T) {
 	type spec struct {
 		name      string
@@ -0,0 +1,293 @@
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/blang/semver/v4"
+
+	"github.com/operator-framework/api/pkg/constraints"
+
+	"github.com/operator-framework/operator-registry/alpha/model"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/pkg/image"
+)
+
+// Resolve resolves the dependencies of a package's channel head against the
+// other packages of a catalog, and returns the bundles that an installation of
+// the package would need. It is meant to catch unsatisfiable dependencies when
+// a catalog is built, rather than when it is used on a cluster.
+//
+// The olm.package.required, olm.gvk.required and olm.constraint properties of
+// each resolved bundle are resolved in turn. A requirement is satisfied by a
+// bundle that is already resolved if there is one. Otherwise, it is satisfied
+// by a bundle of a package that isn't resolved yet, preferring the heads of
+// default channels, then higher versions. Only one bundle of each package is
+// resolved.
+type Resolve struct {
+	IndexReference string
+	PackageName    string
+	// ChannelName is the channel whose head is resolved. It defaults to the
+	// package's default channel.
+	ChannelName string
+	Registry    image.Registry
+}
+
+// Run returns the resolved bundles, ordered by package and bundle name.
+func (r Resolve) Run(ctx context.Context) ([]CatalogBundle, error) {
+	m, err := indexRefToModel(ctx, r.IndexReference, r.Registry)
+	if err != nil {
+		return nil, err
+	}
+	pkg, ok := m[r.PackageName]
+	if !ok {
+		return nil, fmt.Errorf("package %q not found", r.PackageName)
+	}
+	ch := pkg.DefaultChannel
+	if r.ChannelName != "" {
+		if ch, ok = pkg.Channels[r.ChannelName]; !ok {
+			return nil, fmt.Errorf("package %q has no channel %q", r.PackageName, r.ChannelName)
+		}
+	}
+	head, err := ch.Head()
+	if err != nil {
+		return nil, fmt.Errorf("package %q channel %q: %v", r.PackageName, ch.Name, err)
+	}
+
+	res := newResolver(m)
+	if err := res.resolve(head); err != nil {
+		return nil, err
+	}
+	// nolint:prealloc
+	var bundles []CatalogBundle
+	for _, b := range res.resolved {
+		bundles = append(bundles, CatalogBundle{Package: b.Package.Name, Name: b.Name, Image: b.Image})
+	}
+	sortCatalogBundles(bundles)
+	return bundles, nil
+}
+
+type resolver struct {
+	// candidates are the bundles of the catalog, with a single entry for
+	// bundles that are in several channels, in order of preference.
+	candidates []*model.Bundle
+	// resolved are the resolved bundles, by package.
+	resolved map[string]*model.Bundle
+	programs map[string]constraints.CelProgram
+}
+
+func newResolver(m model.Model) *resolver {
+	r := &resolver{resolved: map[string]*model.Bundle{}, programs: map[string]constraints.CelProgram{}}
+	defaultHeads := map[*model.Bundle]bool{}
+	seen := map[string]bool{}
+	for _, pkg := range m {
+		if pkg.DefaultChannel != nil {
+			if head, err := pkg.DefaultChannel.Head(); err == nil {
+				defaultHeads[head] = true
+				r.candidates = append(r.candidates, head)
+				seen[pkg.Name+"/"+head.Name] = true
+			}
+		}
+		for _, ch := range pkg.Channels {
+			for _, b := range ch.Bundles {
+				if !seen[pkg.Name+"/"+b.Name] {
+					seen[pkg.Name+"/"+b.Name] = true
+					r.candidates = append(r.candidates, b)
+				}
+			}
+		}
+	}
+	sort.Slice(r.candidates, func(i, j int) bool {
+		a, b := r.candidates[i], r.candidates[j]
+		if defaultHeads[a] != defaultHeads[b] {
+			return defaultHeads[a]
+		}
+		if c := a.Version.Compare(b.Version); c != 0 {
+			return c > 0
+		}
+		if a.Package.Name != b.Package.Name {
+			return a.Package.Name < b.Package.Name
+		}
+		return a.Name < b.Name
+	})
+	return r
+}
+
+// requirement is a dependency of a bundle, which is satisfied by the bundles
+// that match it.
+type requirement struct {
+	description    string
+	failureMessage string
+	match          func(*model.Bundle) (bool, error)
+}
+
+func (r *resolver) resolve(b *model.Bundle) error {
+	r.resolved[b.Package.Name] = b
+	reqs, err := r.requirements(b)
+	if err != nil {
+		return fmt.Errorf("bundle %q: %v", b.Name, err)
+	}
+	for _, req := range reqs {
+		satisfier, err := r.satisfy(req)
+		if err != nil {
+			return fmt.Errorf("bundle %q: requirement %s: %v", b.Name, req.description, err)
+		}
+		if satisfier == nil {
+			msg := fmt.Sprintf("bundle %q: requirement %s cannot be satisfied", b.Name, req.description)
+			if req.failureMessage != "" {
+				msg = fmt.Sprintf("%s: %s", msg, req.failureMessage)
+			}
+			return errors.New(msg)
+		}
+		if _, ok := r.resolved[satisfier.Package.Name]; ok {
+			continue
+		}
+		if err := r.resolve(satisfier); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// satisfy returns the bundle that satisfies req, or nil if there is none.
+func (r *resolver) satisfy(req requirement) (*model.Bundle, error) {
+	pkgs := make([]string, 0, len(r.resolved))
+	for pkg := range r.resolved {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	for _, pkg := range pkgs {
+		ok, err := req.match(r.resolved[pkg])
+		if err != nil || ok {
+			return r.resolved[pkg], err
+		}
+	}
+	for _, b := range r.candidates {
+		if _, ok := r.resolved[b.Package.Name]; ok {
+			continue
+		}
+		ok, err := req.match(b)
+		if err != nil || ok {
+			return b, err
+		}
+	}
+	return nil, nil
+}
+
+func (r *resolver) requirements(b *model.Bundle) ([]requirement, error) {
+	props, err := bundleProperties(b)
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint:prealloc
+	var reqs []requirement
+	for _, p := range props.PackagesRequired {
+		c := constraints.Constraint{Package: &constraints.PackageConstraint{PackageName: p.PackageName, VersionRange: p.VersionRange}}
+		reqs = append(reqs, requirement{
+			description: fmt.Sprintf("package %q with version in range %q", p.PackageName, p.VersionRange),
+			match:       func(b *model.Bundle) (bool, error) { return r.matches(c, b) },
+		})
+	}
+	for _, p := range props.GVKsRequired {
+		c := constraints.Constraint{GVK: &constraints.GVKConstraint{Group: p.Group, Version: p.Version, Kind: p.Kind}}
+		reqs = append(reqs, requirement{
+			description: fmt.Sprintf("gvk %s/%s, Kind=%s", p.Group, p.Version, p.Kind),
+			match:       func(b *model.Bundle) (bool, error) { return r.matches(c, b) },
+		})
+	}
+	for i, c := range props.Constraints {
+		reqs = append(reqs, requirement{
+			description:    fmt.Sprintf("%s[%d]", property.TypeConstraint, i),
+			failureMessage: c.FailureMessage,
+			match:          func(b *model.Bundle) (bool, error) { return r.matches(c, b) },
+		})
+	}
+	return reqs, nil
+}
+
+// matches returns whether bundle b satisfies constraint c.
+func (r *resolver) matches(c constraints.Constraint, b *model.Bundle) (bool, error) {
+	switch {
+	case c.Cel != nil:
+		prog, ok := r.programs[c.Cel.Rule]
+		if !ok {
+			var err error
+			if prog, err = constraints.NewCelEnvironment().Validate(c.Cel.Rule); err != nil {
+				return false, err
+			}
+			r.programs[c.Cel.Rule] = prog
+		}
+		props, err := celProperties(b)
+		if err != nil {
+			return false, err
+		}
+		return prog.Evaluate(map[string]interface{}{constraints.PropertiesKey: props})
+	case c.Package != nil:
+		if b.Package.Name != c.Package.PackageName {
+			return false, nil
+		}
+		versionRange, err := semver.ParseRange(c.Package.VersionRange)
+		if err != nil {
+			return false, err
+		}
+		return versionRange(b.Version), nil
+	case c.GVK != nil:
+		props, err := bundleProperties(b)
+		if err != nil {
+			return false, err
+		}
+		for _, p := range props.GVKs {
+			if p.Group == c.GVK.Group && p.Version == c.GVK.Version && p.Kind == c.GVK.Kind {
+				return true, nil
+			}
+		}
+		return false, nil
+	case c.All != nil:
+		for _, sub := range c.All.Constraints {
+			if ok, err := r.matches(sub, b); err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case c.Any != nil:
+		for _, sub := range c.Any.Constraints {
+			if ok, err := r.matches(sub, b); err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	case c.Not != nil:
+		for _, sub := range c.Not.Constraints {
+			if ok, err := r.matches(sub, b); err != nil || ok {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("unsupported constraint")
+}
+
+// celProperties returns the properties of b in the form expected by CEL
+// expressions, which is a list of type and value maps.
+func celProperties(b *model.Bundle) ([]interface{}, error) {
+	props := make([]interface{}, 0, len(b.Properties))
+	for _, p := range b.Properties {
+		var v interface{}
+		if err := json.Unmarshal(p.Value, &v); err != nil {
+			return nil, fmt.Errorf("parse property %q: %v", p.Type, err)
+		}
+		props = append(props, map[string]interface{}{"type": p.Type, "value": v})
+	}
+	return props, nil
+}
+
+func bundleProperties(b *model.Bundle) (*property.Properties, error) {
+	if b.PropertiesP != nil {
+		return b.PropertiesP, nil
+	}
+	return property.Parse(b.Properties)
+}
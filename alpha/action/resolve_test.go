@@ -0,0 +1,216 @@
+package action_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+)
+
+const resolveCatalog = `---
+schema: olm.package
+name: foo
+defaultChannel: stable
+---
+schema: olm.channel
+package: foo
+name: stable
+entries:
+  - name: foo.v0.1.0
+---
+schema: olm.channel
+package: foo
+name: edge
+entries:
+  - name: foo.v0.2.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.1.0
+image: test.registry/foo-operator/foo-bundle:v0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.1.0
+  - type: olm.package.required
+    value:
+      packageName: bar
+      versionRange: '>=0.2.0'
+  - type: olm.gvk.required
+    value:
+      group: baz.example.com
+      kind: Baz
+      version: v1
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.2.0
+image: test.registry/foo-operator/foo-bundle:v0.2.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.2.0
+  - type: olm.constraint
+    value:
+      failureMessage: requires a certified operator
+      cel:
+        rule: 'properties.exists(p, p.type == "certified")'
+---
+schema: olm.package
+name: bar
+defaultChannel: stable
+---
+schema: olm.channel
+package: bar
+name: stable
+entries:
+  - name: bar.v0.1.0
+  - name: bar.v0.2.0
+    replaces: bar.v0.1.0
+---
+schema: olm.bundle
+package: bar
+name: bar.v0.1.0
+image: test.registry/bar-operator/bar-bundle:v0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: bar
+      version: 0.1.0
+---
+schema: olm.bundle
+package: bar
+name: bar.v0.2.0
+image: test.registry/bar-operator/bar-bundle:v0.2.0
+properties:
+  - type: olm.package
+    value:
+      packageName: bar
+      version: 0.2.0
+  - type: olm.gvk.required
+    value:
+      group: baz.example.com
+      kind: Baz
+      version: v1
+---
+schema: olm.package
+name: baz
+defaultChannel: stable
+---
+schema: olm.channel
+package: baz
+name: stable
+entries:
+  - name: baz.v1.0.0
+---
+schema: olm.bundle
+package: baz
+name: baz.v1.0.0
+image: test.registry/baz-operator/baz-bundle:v1.0.0
+properties:
+  - type: olm.package
+    value:
+      packageName: baz
+      version: 1.0.0
+  - type: olm.gvk
+    value:
+      group: baz.example.com
+      kind: Baz
+      version: v1
+`
+
+const resolveCertifiedCatalog = `---
+schema: olm.package
+name: qux
+defaultChannel: stable
+---
+schema: olm.channel
+package: qux
+name: stable
+entries:
+  - name: qux.v1.0.0
+---
+schema: olm.bundle
+package: qux
+name: qux.v1.0.0
+image: test.registry/qux-operator/qux-bundle:v1.0.0
+properties:
+  - type: olm.package
+    value:
+      packageName: qux
+      version: 1.0.0
+  - type: certified
+    value: {}
+`
+
+func TestResolve(t *testing.T) {
+	bundle := func(pkg, version string) action.CatalogBundle {
+		return action.CatalogBundle{Package: pkg, Name: pkg + "." + version, Image: "test.registry/" + pkg + "-operator/" + pkg + "-bundle:" + version}
+	}
+
+	type spec struct {
+		name            string
+		resolve         action.Resolve
+		withCertified   bool
+		expectedBundles []action.CatalogBundle
+		expectErr       string
+	}
+
+	specs := []spec{
+		{
+			name:            "Success/DefaultChannel",
+			resolve:         action.Resolve{PackageName: "foo"},
+			expectedBundles: []action.CatalogBundle{bundle("bar", "v0.2.0"), bundle("baz", "v1.0.0"), bundle("foo", "v0.1.0")},
+		},
+		{
+			name:            "Success/NoDependencies",
+			resolve:         action.Resolve{PackageName: "baz"},
+			expectedBundles: []action.CatalogBundle{bundle("baz", "v1.0.0")},
+		},
+		{
+			name:            "Success/Constraint",
+			resolve:         action.Resolve{PackageName: "foo", ChannelName: "edge"},
+			withCertified:   true,
+			expectedBundles: []action.CatalogBundle{bundle("foo", "v0.2.0"), bundle("qux", "v1.0.0")},
+		},
+		{
+			name:      "Error/UnsatisfiableConstraint",
+			resolve:   action.Resolve{PackageName: "foo", ChannelName: "edge"},
+			expectErr: `bundle "foo.v0.2.0": requirement olm.constraint[0] cannot be satisfied: requires a certified operator`,
+		},
+		{
+			name:      "Error/PackageNotFound",
+			resolve:   action.Resolve{PackageName: "unknown"},
+			expectErr: `package "unknown" not found`,
+		},
+		{
+			name:      "Error/ChannelNotFound",
+			resolve:   action.Resolve{PackageName: "foo", ChannelName: "unknown"},
+			expectErr: `package "foo" has no channel "unknown"`,
+		},
+	}
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "catalog.yaml"), []byte(resolveCatalog), 0600))
+			if s.withCertified {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "qux.yaml"), []byte(resolveCertifiedCatalog), 0600))
+			}
+
+			s.resolve.IndexReference = dir
+			bundles, err := s.resolve.Run(context.Background())
+			if s.expectErr != "" {
+				require.EqualError(t, err, s.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, s.expectedBundles, bundles)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/operator-framework/operator-registry/alpha/model"
+	"github.com/operator-framework/operator-registry/pkg/api"
+	"github.com/operator-framework/operator-registry/pkg/image"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+// CatalogStats computes the statistics of a catalog that the GetCatalogStats
+// method of the registry API reports for it: the number of its packages,
+// channels and bundles, the size of the bundles' manifests, its largest
+// bundles and how many of its packages, channels and bundles are deprecated.
+type CatalogStats struct {
+	CatalogRef string
+	// LargestBundles is the number of largest bundles to report. If it is
+	// not positive, registry.DefaultLargestBundles are reported.
+	LargestBundles int
+	Registry       image.Registry
+}
+
+func (s CatalogStats) Run(ctx context.Context) (*CatalogStatsResult, error) {
+	m, err := indexRefToModel(ctx, s.CatalogRef, s.Registry)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := registry.NewCatalogStatsBuilder(s.LargestBundles)
+	for _, pkg := range m {
+		stats.AddPackage(modelPackageToAPIPackage(*pkg))
+		seen := map[string]struct{}{}
+		for _, ch := range pkg.Channels {
+			for _, b := range ch.Bundles {
+				if _, ok := seen[b.Name]; ok {
+					continue
+				}
+				seen[b.Name] = struct{}{}
+				apiBundle, err := api.ConvertModelBundleToAPIBundle(*b)
+				if err != nil {
+					return nil, fmt.Errorf("convert bundle %q: %v", b.Name, err)
+				}
+				if err := stats.Send(apiBundle); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return &CatalogStatsResult{Stats: stats.Stats()}, nil
+}
+
+// modelPackageToAPIPackage returns the parts of pkg that catalog statistics are
+// computed from: its channels and deprecations.
+func modelPackageToAPIPackage(pkg model.Package) *api.Package {
+	out := &api.Package{Name: pkg.Name}
+	if pkg.Deprecation != nil {
+		out.Deprecation = &api.Deprecation{Message: pkg.Deprecation.Message}
+	}
+	for _, ch := range pkg.Channels {
+		apiCh := &api.Channel{Name: ch.Name}
+		if ch.Deprecation != nil {
+			apiCh.Deprecation = &api.Deprecation{Message: ch.Deprecation.Message}
+		}
+		out.Channels = append(out.Channels, apiCh)
+	}
+	return out
+}
+
+type CatalogStatsResult struct {
+	Stats *api.CatalogStats
+}
+
+func (r *CatalogStatsResult) WriteColumns(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	deprecations := r.Stats.GetDeprecations()
+	if _, err := fmt.Fprintf(tw, "PACKAGES\tCHANNELS\tBUNDLES\tMANIFEST BYTES\n%d (%d deprecated)\t%d (%d deprecated)\t%d (%d deprecated)\t%d\n",
+		r.Stats.GetPackages(), deprecations.GetPackages(),
+		r.Stats.GetChannels(), deprecations.GetChannels(),
+		r.Stats.GetBundles(), deprecations.GetBundles(),
+		r.Stats.GetManifestBytes(),
+	); err != nil {
+		return err
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(r.Stats.GetLargestBundles()) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(tw, "PACKAGE\tLARGEST BUNDLES\tMANIFEST BYTES"); err != nil {
+		return err
+	}
+	for _, b := range r.Stats.GetLargestBundles() {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%d\n", b.GetPackageName(), b.GetCsvName(), b.GetManifestBytes()); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,26 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogStats(t *testing.T) {
+	stats := CatalogStats{CatalogRef: "testdata/list-index", LargestBundles: 3}
+	res, err := stats.Run(context.Background())
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, res.WriteColumns(buf))
+	require.Equal(t, `PACKAGES          CHANNELS          BUNDLES           MANIFEST BYTES
+2 (0 deprecated)  4 (0 deprecated)  4 (0 deprecated)  2576
+
+PACKAGE  LARGEST BUNDLES  MANIFEST BYTES
+bar      bar.v0.2.0       674
+foo      foo.v0.2.0       674
+bar      bar.v0.1.0       614
+`, buf.String())
+}
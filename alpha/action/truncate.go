@@ -0,0 +1,124 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/image"
+)
+
+// Truncate writes a catalog in which each channel is truncated to its head
+// and the Depth-1 entries before it in the channel's replaces chain. The
+// replaces and skips of the remaining entries are rewritten to skip over the
+// removed entries, and bundles that are no longer in any channel are removed.
+//
+// If IncludeDependencies is set, bundles that were removed but are required
+// to satisfy the olm.package.required and olm.gvk.required properties of the
+// remaining bundles are kept, as with Prune.
+type Truncate struct {
+	CatalogRef          string
+	Depth               int
+	IncludeDependencies bool
+	OutputDir           string
+
+	WriteFunc declcfg.WriteFunc
+	FileExt   string
+	Registry  image.Registry
+}
+
+func (t Truncate) Run(ctx context.Context) error {
+	entries, err := os.ReadDir(t.OutputDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("output dir %q must be empty", t.OutputDir)
+	}
+	if t.Depth < 1 {
+		return fmt.Errorf("depth must be at least 1, got %d", t.Depth)
+	}
+
+	r := Render{
+		Refs:     []string{t.CatalogRef},
+		Registry: t.Registry,
+
+		// Only allow catalogs to be truncated.
+		AllowedRefMask: RefSqliteImage | RefSqliteFile | RefDCImage | RefDCDir,
+	}
+	cfg, err := r.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("render catalog: %v", err)
+	}
+
+	truncated, err := truncateConfig(*cfg, t.Depth, t.IncludeDependencies)
+	if err != nil {
+		return err
+	}
+	if _, err := declcfg.ConvertToModel(*truncated); err != nil {
+		return fmt.Errorf("truncated catalog is invalid: %v", err)
+	}
+	return declcfg.WriteFS(*truncated, t.OutputDir, t.WriteFunc, t.FileExt)
+}
+
+func truncateConfig(cfg declcfg.DeclarativeConfig, depth int, includeDependencies bool) (*declcfg.DeclarativeConfig, error) {
+	p, err := newPruner(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range cfg.Channels {
+		chain, err := replacesChain(ch, depth)
+		if err != nil {
+			return nil, fmt.Errorf("package %q channel %q: %v", ch.Package, ch.Name, err)
+		}
+		if _, ok := p.keepEntries[ch.Package]; !ok {
+			p.keepEntries[ch.Package] = map[string]sets.Set[string]{}
+		}
+		p.keepEntries[ch.Package][ch.Name] = sets.New(chain...)
+		p.keepChannel(ch.Package, ch.Name)
+		for _, name := range chain {
+			b, ok := p.bundles[ch.Package][name]
+			if !ok {
+				return nil, fmt.Errorf("package %q channel %q: bundle %q not found", ch.Package, ch.Name, name)
+			}
+			p.keepBundle(b)
+		}
+	}
+	if includeDependencies {
+		if err := p.resolveDependencies(); err != nil {
+			return nil, err
+		}
+	}
+	return p.output(cfg), nil
+}
+
+// replacesChain returns the names of the channel head and up to depth-1
+// entries that it transitively replaces.
+func replacesChain(ch declcfg.Channel, depth int) ([]string, error) {
+	head, err := channelHead(ch)
+	if err != nil {
+		return nil, err
+	}
+	if head == "" {
+		return nil, nil
+	}
+	byName := make(map[string]declcfg.ChannelEntry, len(ch.Entries))
+	for _, e := range ch.Entries {
+		byName[e.Name] = e
+	}
+	var chain []string
+	visited := sets.New[string]()
+	for name := head; len(chain) < depth; {
+		e, ok := byName[name]
+		if !ok || visited.Has(name) {
+			break
+		}
+		visited.Insert(name)
+		chain = append(chain, name)
+		name = e.Replaces
+	}
+	return chain, nil
+}
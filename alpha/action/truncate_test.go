@@ -0,0 +1,124 @@
+package action_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+func TestTruncate(t *testing.T) {
+	type spec struct {
+		name                 string
+		truncate             action.Truncate
+		expectedEntries      map[string][]declcfg.ChannelEntry
+		expectedDeprecations []string
+		expectErr            string
+	}
+
+	specs := []spec{
+		{
+			name:     "Success/HeadOnly",
+			truncate: action.Truncate{Depth: 1},
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"foo/beta":   {{Name: "foo.v0.3.0"}},
+				"foo/stable": {{Name: "foo.v0.2.0"}},
+				"bar/alpha":  {{Name: "bar.v0.3.0"}},
+				"baz/stable": {{Name: "baz.v1.0.0"}},
+				"qux/stable": {{Name: "qux.v1.0.0"}},
+				"qux/fast":   {{Name: "qux.v2.0.0"}},
+			},
+			expectedDeprecations: []string{"stable"},
+		},
+		{
+			name:     "Success/Depth",
+			truncate: action.Truncate{Depth: 2},
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"foo/beta": {
+					{Name: "foo.v0.2.0"},
+					{Name: "foo.v0.3.0", Replaces: "foo.v0.2.0"},
+				},
+				"foo/stable": {{Name: "foo.v0.2.0"}},
+				"bar/alpha": {
+					{Name: "bar.v0.2.0"},
+					{Name: "bar.v0.3.0", Replaces: "bar.v0.2.0"},
+				},
+				"baz/stable": {{Name: "baz.v1.0.0"}},
+				"qux/stable": {{Name: "qux.v1.0.0"}},
+				"qux/fast":   {{Name: "qux.v2.0.0"}},
+			},
+			expectedDeprecations: []string{"stable"},
+		},
+		{
+			name:     "Success/IncludeDependencies",
+			truncate: action.Truncate{Depth: 1, IncludeDependencies: true},
+			expectedEntries: map[string][]declcfg.ChannelEntry{
+				"foo/beta":   {{Name: "foo.v0.3.0"}},
+				"foo/stable": {{Name: "foo.v0.2.0"}},
+				"bar/alpha": {
+					{Name: "bar.v0.2.0"},
+					{Name: "bar.v0.3.0", Replaces: "bar.v0.2.0"},
+				},
+				"baz/stable": {{Name: "baz.v1.0.0"}},
+				"qux/stable": {{Name: "qux.v1.0.0"}},
+				"qux/fast":   {{Name: "qux.v2.0.0"}},
+			},
+			expectedDeprecations: []string{"stable"},
+		},
+		{
+			name:      "Error/InvalidDepth",
+			truncate:  action.Truncate{},
+			expectErr: "depth must be at least 1, got 0",
+		},
+	}
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			catalogDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(catalogDir, "catalog.yaml"), []byte(pruneCatalog), 0600))
+
+			s.truncate.CatalogRef = catalogDir
+			s.truncate.OutputDir = filepath.Join(t.TempDir(), "out")
+			s.truncate.WriteFunc = declcfg.WriteYAML
+			s.truncate.FileExt = ".yaml"
+			err := s.truncate.Run(context.Background())
+			if s.expectErr != "" {
+				require.ErrorContains(t, err, s.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			cfg, err := declcfg.LoadFS(context.Background(), os.DirFS(s.truncate.OutputDir))
+			require.NoError(t, err)
+
+			actualEntries := map[string][]declcfg.ChannelEntry{}
+			bundlesInChannels := map[string]struct{}{}
+			for _, ch := range cfg.Channels {
+				actualEntries[ch.Package+"/"+ch.Name] = ch.Entries
+				for _, e := range ch.Entries {
+					bundlesInChannels[e.Name] = struct{}{}
+				}
+			}
+			require.Equal(t, s.expectedEntries, actualEntries)
+
+			var actualBundles []string
+			for _, b := range cfg.Bundles {
+				actualBundles = append(actualBundles, b.Name)
+				require.Contains(t, bundlesInChannels, b.Name)
+			}
+			require.Len(t, actualBundles, len(bundlesInChannels))
+
+			var actualDeprecations []string
+			for _, d := range cfg.Deprecations {
+				for _, e := range d.Entries {
+					actualDeprecations = append(actualDeprecations, e.Reference.Name)
+				}
+			}
+			require.ElementsMatch(t, s.expectedDeprecations, actualDeprecations)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/blang/semver/v4"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-registry/pkg/image"
+)
+
+// deprecatedAPI is a Kubernetes API version of a kind that is deprecated and
+// removed in the given Kubernetes minor versions.
+type deprecatedAPI struct {
+	deprecatedIn semver.Version
+	removedIn    semver.Version
+	replacement  string
+}
+
+// deprecatedAPIs are the deprecated APIs of the kinds that bundles may
+// contain, by apiVersion and kind, as listed in the Kubernetes deprecated API
+// migration guide.
+var deprecatedAPIs = map[metav1.TypeMeta]deprecatedAPI{
+	{APIVersion: "apiextensions.k8s.io/v1beta1", Kind: "CustomResourceDefinition"}:               {kubeMinor(1, 16), kubeMinor(1, 22), "apiextensions.k8s.io/v1"},
+	{APIVersion: "admissionregistration.k8s.io/v1beta1", Kind: "MutatingWebhookConfiguration"}:   {kubeMinor(1, 16), kubeMinor(1, 22), "admissionregistration.k8s.io/v1"},
+	{APIVersion: "admissionregistration.k8s.io/v1beta1", Kind: "ValidatingWebhookConfiguration"}: {kubeMinor(1, 16), kubeMinor(1, 22), "admissionregistration.k8s.io/v1"},
+	{APIVersion: "apiregistration.k8s.io/v1beta1", Kind: "APIService"}:                           {kubeMinor(1, 19), kubeMinor(1, 22), "apiregistration.k8s.io/v1"},
+	{APIVersion: "certificates.k8s.io/v1beta1", Kind: "CertificateSigningRequest"}:               {kubeMinor(1, 19), kubeMinor(1, 22), "certificates.k8s.io/v1"},
+	{APIVersion: "coordination.k8s.io/v1beta1", Kind: "Lease"}:                                   {kubeMinor(1, 19), kubeMinor(1, 22), "coordination.k8s.io/v1"},
+	{APIVersion: "extensions/v1beta1", Kind: "Ingress"}:                                          {kubeMinor(1, 14), kubeMinor(1, 22), "networking.k8s.io/v1"},
+	{APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress"}:                                   {kubeMinor(1, 19), kubeMinor(1, 22), "networking.k8s.io/v1"},
+	{APIVersion: "networking.k8s.io/v1beta1", Kind: "IngressClass"}:                              {kubeMinor(1, 19), kubeMinor(1, 22), "networking.k8s.io/v1"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "ClusterRole"}:                       {kubeMinor(1, 17), kubeMinor(1, 22), "rbac.authorization.k8s.io/v1"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "ClusterRoleBinding"}:                {kubeMinor(1, 17), kubeMinor(1, 22), "rbac.authorization.k8s.io/v1"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "Role"}:                              {kubeMinor(1, 17), kubeMinor(1, 22), "rbac.authorization.k8s.io/v1"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "RoleBinding"}:                       {kubeMinor(1, 17), kubeMinor(1, 22), "rbac.authorization.k8s.io/v1"},
+	{APIVersion: "scheduling.k8s.io/v1beta1", Kind: "PriorityClass"}:                             {kubeMinor(1, 14), kubeMinor(1, 22), "scheduling.k8s.io/v1"},
+	{APIVersion: "storage.k8s.io/v1beta1", Kind: "CSIDriver"}:                                    {kubeMinor(1, 19), kubeMinor(1, 22), "storage.k8s.io/v1"},
+	{APIVersion: "storage.k8s.io/v1beta1", Kind: "CSINode"}:                                      {kubeMinor(1, 17), kubeMinor(1, 22), "storage.k8s.io/v1"},
+	{APIVersion: "storage.k8s.io/v1beta1", Kind: "StorageClass"}:                                 {kubeMinor(1, 19), kubeMinor(1, 22), "storage.k8s.io/v1"},
+	{APIVersion: "storage.k8s.io/v1beta1", Kind: "VolumeAttachment"}:                             {kubeMinor(1, 19), kubeMinor(1, 22), "storage.k8s.io/v1"},
+	{APIVersion: "autoscaling/v2beta1", Kind: "HorizontalPodAutoscaler"}:                         {kubeMinor(1, 22), kubeMinor(1, 25), "autoscaling/v2"},
+	{APIVersion: "batch/v1beta1", Kind: "CronJob"}:                                               {kubeMinor(1, 21), kubeMinor(1, 25), "batch/v1"},
+	{APIVersion: "discovery.k8s.io/v1beta1", Kind: "EndpointSlice"}:                              {kubeMinor(1, 21), kubeMinor(1, 25), "discovery.k8s.io/v1"},
+	{APIVersion: "events.k8s.io/v1beta1", Kind: "Event"}:                                         {kubeMinor(1, 19), kubeMinor(1, 25), "events.k8s.io/v1"},
+	{APIVersion: "node.k8s.io/v1beta1", Kind: "RuntimeClass"}:                                    {kubeMinor(1, 20), kubeMinor(1, 25), "node.k8s.io/v1"},
+	{APIVersion: "policy/v1beta1", Kind: "PodDisruptionBudget"}:                                  {kubeMinor(1, 21), kubeMinor(1, 25), "policy/v1"},
+	{APIVersion: "policy/v1beta1", Kind: "PodSecurityPolicy"}:                                    {kubeMinor(1, 21), kubeMinor(1, 25), ""},
+	{APIVersion: "autoscaling/v2beta2", Kind: "HorizontalPodAutoscaler"}:                         {kubeMinor(1, 23), kubeMinor(1, 26), "autoscaling/v2"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta1", Kind: "FlowSchema"}:                     {kubeMinor(1, 23), kubeMinor(1, 26), "flowcontrol.apiserver.k8s.io/v1"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta1", Kind: "PriorityLevelConfiguration"}:     {kubeMinor(1, 23), kubeMinor(1, 26), "flowcontrol.apiserver.k8s.io/v1"},
+	{APIVersion: "storage.k8s.io/v1beta1", Kind: "CSIStorageCapacity"}:                           {kubeMinor(1, 24), kubeMinor(1, 27), "storage.k8s.io/v1"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta2", Kind: "FlowSchema"}:                     {kubeMinor(1, 26), kubeMinor(1, 29), "flowcontrol.apiserver.k8s.io/v1"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta2", Kind: "PriorityLevelConfiguration"}:     {kubeMinor(1, 26), kubeMinor(1, 29), "flowcontrol.apiserver.k8s.io/v1"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta3", Kind: "FlowSchema"}:                     {kubeMinor(1, 29), kubeMinor(1, 32), "flowcontrol.apiserver.k8s.io/v1"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta3", Kind: "PriorityLevelConfiguration"}:     {kubeMinor(1, 29), kubeMinor(1, 32), "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+func kubeMinor(major, minor uint64) semver.Version {
+	return semver.Version{Major: major, Minor: minor}
+}
+
+// ValidateAPIs reports the objects of the bundles of a catalog that use
+// Kubernetes APIs that are deprecated or removed in a target Kubernetes
+// version. Only the bundles' objects are inspected, so bundles rendered
+// without olm.bundle.object properties are reported as unchecked.
+type ValidateAPIs struct {
+	Refs []string
+	// KubeVersion is the target Kubernetes version, such as "1.25". Only its
+	// major and minor versions are compared.
+	KubeVersion string
+
+	Registry      image.Registry
+	SkipTLSVerify bool
+	PlainHTTP     bool
+}
+
+// DeprecatedAPIUsage is an object of a bundle that uses a deprecated API.
+type DeprecatedAPIUsage struct {
+	Package    string
+	Bundle     string
+	Kind       string
+	Name       string
+	APIVersion string
+	// Removed is true if the API is removed in the target Kubernetes version,
+	// and false if it is only deprecated.
+	Removed      bool
+	DeprecatedIn string
+	RemovedIn    string
+	Replacement  string
+}
+
+type ValidateAPIsResult struct {
+	KubeVersion string
+	// Usages are sorted by package, bundle, kind and name.
+	Usages []DeprecatedAPIUsage
+	// Unchecked are the names of the bundles without objects.
+	Unchecked []string
+}
+
+func (v ValidateAPIs) Run(ctx context.Context) (*ValidateAPIsResult, error) {
+	target, err := semver.ParseTolerant(v.KubeVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kubernetes version %q: %v", v.KubeVersion, err)
+	}
+	target = semver.Version{Major: target.Major, Minor: target.Minor}
+
+	r := Render{
+		Refs:          v.Refs,
+		Registry:      v.Registry,
+		SkipTLSVerify: v.SkipTLSVerify,
+		PlainHTTP:     v.PlainHTTP,
+	}
+	cfg, err := r.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ValidateAPIsResult{KubeVersion: fmt.Sprintf("%d.%d", target.Major, target.Minor)}
+	for _, b := range cfg.Bundles {
+		if len(b.Objects) == 0 {
+			result.Unchecked = append(result.Unchecked, b.Name)
+			continue
+		}
+		for i, obj := range b.Objects {
+			var o metav1.PartialObjectMetadata
+			if err := yaml.Unmarshal([]byte(obj), &o); err != nil {
+				return nil, fmt.Errorf("parse object %d of bundle %q: %v", i, b.Name, err)
+			}
+			api, ok := deprecatedAPIs[o.TypeMeta]
+			if !ok || target.LT(api.deprecatedIn) {
+				continue
+			}
+			result.Usages = append(result.Usages, DeprecatedAPIUsage{
+				Package:      b.Package,
+				Bundle:       b.Name,
+				Kind:         o.Kind,
+				Name:         o.Name,
+				APIVersion:   o.APIVersion,
+				Removed:      target.GTE(api.removedIn),
+				DeprecatedIn: fmt.Sprintf("%d.%d", api.deprecatedIn.Major, api.deprecatedIn.Minor),
+				RemovedIn:    fmt.Sprintf("%d.%d", api.removedIn.Major, api.removedIn.Minor),
+				Replacement:  api.replacement,
+			})
+		}
+	}
+	sort.Slice(result.Usages, func(i, j int) bool {
+		a, b := result.Usages[i], result.Usages[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.Bundle != b.Bundle {
+			return a.Bundle < b.Bundle
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	sort.Strings(result.Unchecked)
+	return result, nil
+}
+
+// HasRemoved returns true if any bundle uses an API that is removed in the
+// target Kubernetes version.
+func (r *ValidateAPIsResult) HasRemoved() bool {
+	for _, u := range r.Usages {
+		if u.Removed {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ValidateAPIsResult) WriteColumns(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "PACKAGE\tBUNDLE\tKIND\tNAME\tAPI VERSION\tSTATUS\tREPLACEMENT"); err != nil {
+		return err
+	}
+	for _, u := range r.Usages {
+		status := fmt.Sprintf("deprecated in %s, removed in %s", u.DeprecatedIn, u.RemovedIn)
+		if u.Removed {
+			status = fmt.Sprintf("removed in %s", u.RemovedIn)
+		}
+		replacement := u.Replacement
+		if replacement == "" {
+			replacement = "none"
+		}
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", u.Package, u.Bundle, u.Kind, u.Name, u.APIVersion, status, replacement); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
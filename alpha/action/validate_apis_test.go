@@ -0,0 +1,112 @@
+package action_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+func TestValidateAPIs(t *testing.T) {
+	bundle := func(pkg, version string, objs ...string) declcfg.Bundle {
+		b := declcfg.Bundle{
+			Schema:     declcfg.SchemaBundle,
+			Package:    pkg,
+			Name:       pkg + ".v" + version,
+			Image:      "test.registry/" + pkg + "-bundle:v" + version,
+			Properties: []property.Property{property.MustBuildPackage(pkg, version)},
+		}
+		for _, obj := range objs {
+			b.Properties = append(b.Properties, property.MustBuildBundleObject([]byte(obj)))
+		}
+		return b
+	}
+	const (
+		crdV1beta1 = `{"apiVersion":"apiextensions.k8s.io/v1beta1","kind":"CustomResourceDefinition","metadata":{"name":"foos.example.com"}}`
+		crdV1      = `{"apiVersion":"apiextensions.k8s.io/v1","kind":"CustomResourceDefinition","metadata":{"name":"foos.example.com"}}`
+		hpaV2beta2 = `{"apiVersion":"autoscaling/v2beta2","kind":"HorizontalPodAutoscaler","metadata":{"name":"bar"}}`
+	)
+	cfg := declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{
+			{Schema: declcfg.SchemaPackage, Name: "foo", DefaultChannel: "stable"},
+			{Schema: declcfg.SchemaPackage, Name: "bar", DefaultChannel: "stable"},
+		},
+		Channels: []declcfg.Channel{
+			{Schema: declcfg.SchemaChannel, Package: "foo", Name: "stable", Entries: []declcfg.ChannelEntry{{Name: "foo.v0.1.0"}, {Name: "foo.v0.2.0", Replaces: "foo.v0.1.0"}}},
+			{Schema: declcfg.SchemaChannel, Package: "bar", Name: "stable", Entries: []declcfg.ChannelEntry{{Name: "bar.v0.1.0"}, {Name: "bar.v0.2.0", Replaces: "bar.v0.1.0"}}},
+		},
+		Bundles: []declcfg.Bundle{
+			bundle("foo", "0.1.0", crdV1beta1),
+			bundle("foo", "0.2.0", crdV1),
+			bundle("bar", "0.1.0", hpaV2beta2),
+			bundle("bar", "0.2.0"),
+		},
+	}
+	catalogDir := t.TempDir()
+	var buf bytes.Buffer
+	require.NoError(t, declcfg.WriteYAML(cfg, &buf))
+	require.NoError(t, os.WriteFile(filepath.Join(catalogDir, "catalog.yaml"), buf.Bytes(), 0600))
+
+	fooUsage := action.DeprecatedAPIUsage{
+		Package:      "foo",
+		Bundle:       "foo.v0.1.0",
+		Kind:         "CustomResourceDefinition",
+		Name:         "foos.example.com",
+		APIVersion:   "apiextensions.k8s.io/v1beta1",
+		Removed:      true,
+		DeprecatedIn: "1.16",
+		RemovedIn:    "1.22",
+		Replacement:  "apiextensions.k8s.io/v1",
+	}
+	barUsage := action.DeprecatedAPIUsage{
+		Package:      "bar",
+		Bundle:       "bar.v0.1.0",
+		Kind:         "HorizontalPodAutoscaler",
+		Name:         "bar",
+		APIVersion:   "autoscaling/v2beta2",
+		DeprecatedIn: "1.23",
+		RemovedIn:    "1.26",
+		Replacement:  "autoscaling/v2",
+	}
+
+	t.Run("Success/Removed", func(t *testing.T) {
+		result, err := action.ValidateAPIs{Refs: []string{catalogDir}, KubeVersion: "v1.24.3"}.Run(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "1.24", result.KubeVersion)
+		require.Equal(t, []action.DeprecatedAPIUsage{barUsage, fooUsage}, result.Usages)
+		require.Equal(t, []string{"bar.v0.2.0"}, result.Unchecked)
+		require.True(t, result.HasRemoved())
+
+		var out bytes.Buffer
+		require.NoError(t, result.WriteColumns(&out))
+		require.Equal(t, `PACKAGE  BUNDLE      KIND                      NAME              API VERSION                   STATUS                               REPLACEMENT
+bar      bar.v0.1.0  HorizontalPodAutoscaler   bar               autoscaling/v2beta2           deprecated in 1.23, removed in 1.26  autoscaling/v2
+foo      foo.v0.1.0  CustomResourceDefinition  foos.example.com  apiextensions.k8s.io/v1beta1  removed in 1.22                      apiextensions.k8s.io/v1
+`, out.String())
+	})
+	t.Run("Success/Deprecated", func(t *testing.T) {
+		fooUsage := fooUsage
+		fooUsage.Removed = false
+		result, err := action.ValidateAPIs{Refs: []string{catalogDir}, KubeVersion: "1.16"}.Run(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []action.DeprecatedAPIUsage{fooUsage}, result.Usages)
+		require.False(t, result.HasRemoved())
+	})
+	t.Run("Success/None", func(t *testing.T) {
+		result, err := action.ValidateAPIs{Refs: []string{catalogDir}, KubeVersion: "1.15"}.Run(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, result.Usages)
+		require.False(t, result.HasRemoved())
+	})
+	t.Run("Error/InvalidKubeVersion", func(t *testing.T) {
+		_, err := action.ValidateAPIs{Refs: []string{catalogDir}, KubeVersion: "latest"}.Run(context.Background())
+		require.ErrorContains(t, err, `invalid Kubernetes version "latest"`)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"golang.org/x/text/cases"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -19,6 +20,8 @@ const (
 	SchemaChannel     = "olm.channel"
 	SchemaBundle      = "olm.bundle"
 	SchemaDeprecation = "olm.deprecations"
+	SchemaCatalog     = "olm.catalog"
+	SchemaIcon        = "olm.icon"
 )
 
 type DeclarativeConfig struct {
@@ -26,6 +29,9 @@ type DeclarativeConfig struct {
 	Channels     []Channel
 	Bundles      []Bundle
 	Deprecations []Deprecation
+	Icons        []PackageIcon
+	Catalogs     []Catalog
+	Extensions   []Extension
 	Others       []Meta
 }
 
@@ -43,10 +49,24 @@ type Icon struct {
 	MediaType string `json:"mediatype"`
 }
 
+// PackageIcon is an olm.icon blob, which defines the icon of a package
+// separately from its olm.package blob. A package's icon may be defined
+// either in its olm.package blob or in an olm.icon blob, but not both.
+type PackageIcon struct {
+	Schema    string `json:"schema"`
+	Package   string `json:"package"`
+	Data      []byte `json:"base64data"`
+	MediaType string `json:"mediatype"`
+}
+
 type Channel struct {
-	Schema     string              `json:"schema"`
-	Name       string              `json:"name"`
-	Package    string              `json:"package"`
+	Schema  string `json:"schema"`
+	Name    string `json:"name"`
+	Package string `json:"package"`
+	// Priority orders the channels of a package: channels with a higher
+	// priority come first, and channels with the same priority are ordered
+	// by name.
+	Priority   int                 `json:"priority,omitempty"`
 	Entries    []ChannelEntry      `json:"entries"`
 	Properties []property.Property `json:"properties,omitempty" hash:"set"`
 }
@@ -107,6 +127,18 @@ type PackageScopedReference struct {
 	Name   string `json:"name,omitempty"`
 }
 
+// Catalog describes a catalog as a whole, so that clients can present its
+// provenance. A catalog contains at most one olm.catalog blob.
+type Catalog struct {
+	Schema      string `json:"schema"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+	Publisher   string `json:"publisher,omitempty"`
+	// BuildTime is the time at which the catalog was built, in RFC 3339 format.
+	BuildTime  string   `json:"buildTime,omitempty"`
+	SourceRefs []string `json:"sourceRefs,omitempty"`
+}
+
 type Meta struct {
 	Schema  string
 	Package string
@@ -119,8 +151,12 @@ func (m Meta) MarshalJSON() ([]byte, error) {
 	return m.Blob, nil
 }
 
+// UnmarshalJSON sets the Blob of m to the compacted blob, with its top-level
+// keys sorted and without HTML escapes. Nested values, such as the values of
+// properties of types that opm does not know, keep their key order and number
+// formatting, so that they are served as they are written.
 func (m *Meta) UnmarshalJSON(blob []byte) error {
-	blobMap := map[string]interface{}{}
+	blobMap := map[string]json.RawMessage{}
 	if err := json.Unmarshal(blob, &blobMap); err != nil {
 		// TODO: unfortunately, there are libraries between here and the original caller
 		//   that eat our error type and return a generic error, such that we lose the
@@ -144,14 +180,50 @@ func (m *Meta) UnmarshalJSON(blob []byte) error {
 	if err := enc.Encode(blobMap); err != nil {
 		return err
 	}
-	m.Blob = buf.Bytes()
+	m.Blob = unescapeHTML(buf.Bytes())
 	return nil
 }
 
+// unescapeHTML replaces the \u003c, \u003e and \u0026 escapes of the JSON
+// data with the characters they escape. Blobs read from YAML are converted to
+// JSON with these escapes, which are kept by the raw values of the blobs.
+func unescapeHTML(data []byte) []byte {
+	if !bytes.Contains(data, []byte(`\u00`)) {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] != '\\' || i+1 == len(data) {
+			out = append(out, data[i])
+			continue
+		}
+		if i+6 <= len(data) && data[i+1] == 'u' {
+			switch strings.ToLower(string(data[i+2 : i+6])) {
+			case "003c":
+				out = append(out, '<')
+				i += 5
+				continue
+			case "003e":
+				out = append(out, '>')
+				i += 5
+				continue
+			case "0026":
+				out = append(out, '&')
+				i += 5
+				continue
+			}
+		}
+		// Keep other escapes, including escaped backslashes, as they are.
+		out = append(out, data[i], data[i+1])
+		i++
+	}
+	return out
+}
+
 // extractUniqueMetaKeys enables a case-insensitive key lookup for the schema, package, and name
 // fields of the Meta struct. If the blobMap contains duplicate keys (that is, keys have the same folded value),
 // an error is returned.
-func extractUniqueMetaKeys(blobMap map[string]any, m *Meta) error {
+func extractUniqueMetaKeys(blobMap map[string]json.RawMessage, m *Meta) error {
 	keySets := map[string]sets.Set[string]{}
 	folder := cases.Fold()
 	for key := range blobMap {
@@ -187,12 +259,13 @@ func extractUniqueMetaKeys(blobMap map[string]any, m *Meta) error {
 
 		// reset key to the unfolded key, which we know is the one that appears in the blobMap
 		key := keySets[foldedKey].UnsortedList()[0]
-		if _, ok := blobMap[key]; !ok {
+		raw, ok := blobMap[key]
+		if !ok || bytes.Equal(raw, []byte("null")) {
 			continue
 		}
-		v, ok := blobMap[key].(string)
-		if !ok {
-			return fmt.Errorf("expected value for key %q to be a string, got %t: %v", key, blobMap[key], blobMap[key])
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("expected value for key %q to be a string, got %s", key, raw)
 		}
 		*ptr = v
 	}
@@ -205,4 +278,7 @@ func (destination *DeclarativeConfig) Merge(src *DeclarativeConfig) {
 	destination.Bundles = append(destination.Bundles, src.Bundles...)
 	destination.Others = append(destination.Others, src.Others...)
 	destination.Deprecations = append(destination.Deprecations, src.Deprecations...)
+	destination.Icons = append(destination.Icons, src.Icons...)
+	destination.Catalogs = append(destination.Catalogs, src.Catalogs...)
+	destination.Extensions = append(destination.Extensions, src.Extensions...)
 }
@@ -2,6 +2,7 @@ package declcfg
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -31,6 +32,7 @@ func ConvertToModel(cfg DeclarativeConfig) (model.Model, error) {
 			Name:        p.Name,
 			Description: p.Description,
 			Channels:    map[string]*model.Channel{},
+			Properties:  p.Properties,
 		}
 		if p.Icon != nil {
 			mpkg.Icon = &model.Icon{
@@ -42,6 +44,20 @@ func ConvertToModel(cfg DeclarativeConfig) (model.Model, error) {
 		mpkgs[p.Name] = mpkg
 	}
 
+	for _, i := range cfg.Icons {
+		mpkg, ok := mpkgs[i.Package]
+		if !ok {
+			return nil, fmt.Errorf("unknown package %q for %s blob", i.Package, SchemaIcon)
+		}
+		if mpkg.Icon != nil {
+			return nil, fmt.Errorf("package %q defines more than one icon", i.Package)
+		}
+		mpkg.Icon = &model.Icon{
+			Data:      i.Data,
+			MediaType: i.MediaType,
+		}
+	}
+
 	channelDefinedEntries := map[string]sets.Set[string]{}
 	for _, c := range cfg.Channels {
 		mpkg, ok := mpkgs[c.Package]
@@ -58,12 +74,10 @@ func ConvertToModel(cfg DeclarativeConfig) (model.Model, error) {
 		}
 
 		mch := &model.Channel{
-			Package: mpkg,
-			Name:    c.Name,
-			Bundles: map[string]*model.Bundle{},
-			// NOTICE: The field Properties of the type Channel is for internal use only.
-			//   DO NOT use it for any public-facing functionalities.
-			//   This API is in alpha stage and it is subject to change.
+			Package:    mpkg,
+			Name:       c.Name,
+			Priority:   c.Priority,
+			Bundles:    map[string]*model.Bundle{},
 			Properties: c.Properties,
 		}
 
@@ -237,6 +251,31 @@ func ConvertToModel(cfg DeclarativeConfig) (model.Model, error) {
 		}
 	}
 
+	if err := validateCatalogs(cfg.Catalogs); err != nil {
+		return nil, err
+	}
+
+	// Extensions that don't belong to a package are validated, but like
+	// olm.catalog blobs they aren't part of the model.
+	if err := validateExtensions(cfg); err != nil {
+		return nil, err
+	}
+	for _, e := range cfg.Extensions {
+		if e.Package == "" {
+			continue
+		}
+		mpkg, ok := mpkgs[e.Package]
+		if !ok {
+			return nil, fmt.Errorf("unknown package %q for %s blob", e.Package, e.Schema)
+		}
+		mpkg.Extensions = append(mpkg.Extensions, model.Extension{
+			Schema: e.Schema,
+			Name:   e.Name,
+			Blob:   e.Blob,
+			Value:  e.Value,
+		})
+	}
+
 	if err := mpkgs.Validate(); err != nil {
 		return nil, err
 	}
@@ -244,6 +283,23 @@ func ConvertToModel(cfg DeclarativeConfig) (model.Model, error) {
 	return mpkgs, nil
 }
 
+func validateCatalogs(catalogs []Catalog) error {
+	if len(catalogs) > 1 {
+		return fmt.Errorf("expected at most one %q blob, found %d", SchemaCatalog, len(catalogs))
+	}
+	for _, c := range catalogs {
+		if c.Name == "" {
+			return fmt.Errorf("invalid %q blob: name must be set", SchemaCatalog)
+		}
+		if c.BuildTime != "" {
+			if _, err := time.Parse(time.RFC3339, c.BuildTime); err != nil {
+				return fmt.Errorf("invalid %q blob %q: buildTime must be in RFC 3339 format: %v", SchemaCatalog, c.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
 func relatedImagesToModelRelatedImages(in []RelatedImage) []model.RelatedImage {
 	// nolint:prealloc
 	var out []model.RelatedImage
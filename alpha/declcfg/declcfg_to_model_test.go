@@ -261,6 +261,37 @@ func TestConvertToModel(t *testing.T) {
 				Bundles:  []Bundle{newTestBundle("foo", "0.1.0")},
 			},
 		},
+		{
+			name:      "Success/ValidModelWithCatalog",
+			assertion: require.NoError,
+			cfg: DeclarativeConfig{
+				Packages: []Package{newTestPackage("foo", "alpha", svgSmallCircle)},
+				Channels: []Channel{newTestChannel("foo", "alpha", ChannelEntry{Name: "foo.v0.1.0"})},
+				Bundles:  []Bundle{newTestBundle("foo", "0.1.0")},
+				Catalogs: []Catalog{{Schema: SchemaCatalog, Name: "foo-catalog", Publisher: "Foo", BuildTime: "2024-01-02T15:04:05Z"}},
+			},
+		},
+		{
+			name:      "Error/MultipleCatalogs",
+			assertion: hasError(`expected at most one "olm.catalog" blob, found 2`),
+			cfg: DeclarativeConfig{
+				Catalogs: []Catalog{{Schema: SchemaCatalog, Name: "foo"}, {Schema: SchemaCatalog, Name: "bar"}},
+			},
+		},
+		{
+			name:      "Error/CatalogNoName",
+			assertion: hasError(`invalid "olm.catalog" blob: name must be set`),
+			cfg: DeclarativeConfig{
+				Catalogs: []Catalog{{Schema: SchemaCatalog}},
+			},
+		},
+		{
+			name:      "Error/CatalogInvalidBuildTime",
+			assertion: hasError(`invalid "olm.catalog" blob "foo": buildTime must be in RFC 3339 format: parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`),
+			cfg: DeclarativeConfig{
+				Catalogs: []Catalog{{Schema: SchemaCatalog, Name: "foo", BuildTime: "yesterday"}},
+			},
+		},
 		{
 			name:      "Success/ValidModelWithChannelProperties",
 			assertion: require.NoError,
@@ -442,6 +473,42 @@ func TestConvertToModel(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:      "Error/Icon/UnknownPackage",
+			assertion: hasError(`unknown package "bar" for olm.icon blob`),
+			cfg: DeclarativeConfig{
+				Packages: []Package{newTestPackage("foo", "alpha", svgSmallCircle)},
+				Channels: []Channel{newTestChannel("foo", "alpha", ChannelEntry{Name: "foo.v0.1.0"})},
+				Bundles:  []Bundle{newTestBundle("foo", "0.1.0")},
+				Icons: []PackageIcon{
+					{Schema: SchemaIcon, Package: "bar", Data: []byte(svgSmallCircle), MediaType: "image/svg+xml"},
+				},
+			},
+		},
+		{
+			name:      "Error/Icon/MultiplePerPackage",
+			assertion: hasError(`package "foo" defines more than one icon`),
+			cfg: DeclarativeConfig{
+				Packages: []Package{newTestPackage("foo", "alpha", svgSmallCircle)},
+				Channels: []Channel{newTestChannel("foo", "alpha", ChannelEntry{Name: "foo.v0.1.0"})},
+				Bundles:  []Bundle{newTestBundle("foo", "0.1.0")},
+				Icons: []PackageIcon{
+					{Schema: SchemaIcon, Package: "foo", Data: []byte(svgBigCircle), MediaType: "image/svg+xml"},
+				},
+			},
+		},
+		{
+			name:      "Success/Icon",
+			assertion: require.NoError,
+			cfg: DeclarativeConfig{
+				Packages: []Package{{Schema: SchemaPackage, Name: "foo", DefaultChannel: "alpha"}},
+				Channels: []Channel{newTestChannel("foo", "alpha", ChannelEntry{Name: "foo.v0.1.0"})},
+				Bundles:  []Bundle{newTestBundle("foo", "0.1.0")},
+				Icons: []PackageIcon{
+					{Schema: SchemaIcon, Package: "foo", Data: []byte(svgSmallCircle), MediaType: "image/svg+xml"},
+				},
+			},
+		},
 	}
 
 	for _, s := range specs {
@@ -486,8 +553,38 @@ func TestConvertToModelBundle(t *testing.T) {
 	assert.Equal(t, semver.MustParse("0.1.0"), b.Version)
 }
 
+func TestConvertToModelChannelPriority(t *testing.T) {
+	stable := newTestChannel("foo", "stable", ChannelEntry{Name: "foo.v0.1.0"})
+	stable.Priority = 2
+	cfg := DeclarativeConfig{
+		Packages: []Package{newTestPackage("foo", "alpha", svgSmallCircle)},
+		Channels: []Channel{
+			newTestChannel("foo", "alpha", ChannelEntry{Name: "foo.v0.1.0"}),
+			stable,
+		},
+		Bundles: []Bundle{newTestBundle("foo", "0.1.0")},
+	}
+	m, err := ConvertToModel(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 0, m["foo"].Channels["alpha"].Priority)
+	assert.Equal(t, 2, m["foo"].Channels["stable"].Priority)
+
+	priorities := map[string]int{}
+	for _, ch := range ConvertFromModel(m).Channels {
+		priorities[ch.Name] = ch.Priority
+	}
+	assert.Equal(t, map[string]int{"alpha": 0, "stable": 2}, priorities)
+
+	cfg.Channels[1].Priority = -1
+	_, err = ConvertToModel(cfg)
+	require.ErrorContains(t, err, "priority -1 must not be negative")
+}
+
 func TestConvertToModelRoundtrip(t *testing.T) {
 	expected := buildValidDeclarativeConfig(validDeclarativeConfigSpec{IncludeUnrecognized: true, IncludeDeprecations: false}) // TODO: turn on deprecation when we have model-->declcfg conversion
+	expected.Packages[0] = addPackageProperties(expected.Packages[0], []property.Property{
+		{Type: "owner", Value: json.RawMessage(`{"group":"abc.com","name":"admin"}`)},
+	})
 
 	m, err := ConvertToModel(expected)
 	require.NoError(t, err)
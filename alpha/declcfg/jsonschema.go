@@ -0,0 +1,149 @@
+package declcfg
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	openapierrors "k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+// JSONSchemaExt is the extension of the JSON Schema files of the declarative
+// config schemas.
+const JSONSchemaExt = ".schema.json"
+
+// The JSON Schemas are generated from the declarative config types. Run
+// `UPDATE_JSONSCHEMAS=1 go test ./alpha/declcfg -run TestJSONSchemas` to
+// regenerate them after changing the types.
+//
+//go:embed jsonschema/*.schema.json
+var jsonSchemaFS embed.FS
+
+var (
+	jsonSchemasOnce sync.Once
+	jsonSchemas     map[string]*spec.Schema
+	jsonSchemasErr  error
+)
+
+// JSONSchemas returns the sorted declarative config schemas that have a JSON
+// Schema.
+func JSONSchemas() []string {
+	entries, _ := fs.ReadDir(jsonSchemaFS, "jsonschema")
+	schemas := make([]string, 0, len(entries))
+	for _, e := range entries {
+		schemas = append(schemas, strings.TrimSuffix(e.Name(), JSONSchemaExt))
+	}
+	sort.Strings(schemas)
+	return schemas
+}
+
+// JSONSchema returns the JSON Schema of the blobs of the given declarative
+// config schema, such as "olm.bundle".
+func JSONSchema(schema string) ([]byte, error) {
+	data, err := jsonSchemaFS.ReadFile(path.Join("jsonschema", schema+JSONSchemaExt))
+	if err != nil {
+		return nil, fmt.Errorf("no JSON Schema for schema %q: known schemas are [%s]", schema, strings.Join(JSONSchemas(), ", "))
+	}
+	return data, nil
+}
+
+func loadJSONSchemas() (map[string]*spec.Schema, error) {
+	jsonSchemasOnce.Do(func() {
+		jsonSchemas = map[string]*spec.Schema{}
+		for _, schema := range JSONSchemas() {
+			data, err := JSONSchema(schema)
+			if err != nil {
+				jsonSchemasErr = err
+				return
+			}
+			var s spec.Schema
+			if err := json.Unmarshal(data, &s); err != nil {
+				jsonSchemasErr = fmt.Errorf("parse JSON Schema of schema %q: %v", schema, err)
+				return
+			}
+			jsonSchemas[schema] = &s
+		}
+	})
+	return jsonSchemas, jsonSchemasErr
+}
+
+// ValidateJSONSchemaFS checks every blob of the declarative config files in
+// root against the JSON Schema of its schema. Blobs of schemas without a JSON
+// Schema are not checked. Like LoadFS, it checks every file and returns an
+// aggregate of *LoadError values, sorted by path and line, that locate the
+// invalid blobs. Each error lists the paths of the invalid fields of its blob.
+func ValidateJSONSchemaFS(ctx context.Context, root fs.FS, opts ...LoadOption) error {
+	var (
+		errsMu sync.Mutex
+		errs   []error
+	)
+	collectErrors := func(o *LoadOptions) {
+		o.collectError = func(err error) {
+			errsMu.Lock()
+			defer errsMu.Unlock()
+			errs = append(errs, err)
+		}
+	}
+	if err := WalkMetasFS(ctx, root, func(_ string, meta *Meta, err error) error {
+		if err != nil {
+			return err
+		}
+		return ValidateJSONSchema(meta)
+	}, append(opts, collectErrors)...); err != nil {
+		return err
+	}
+	sortLoadErrors(errs)
+	return utilerrors.NewAggregate(errs)
+}
+
+// ValidateJSONSchema checks meta against the JSON Schema of its schema, and
+// returns an error listing the paths of its invalid fields. Blobs of schemas
+// without a JSON Schema are not checked.
+func ValidateJSONSchema(meta *Meta) error {
+	schemas, err := loadJSONSchemas()
+	if err != nil {
+		return err
+	}
+	s, ok := schemas[meta.Schema]
+	if !ok {
+		return nil
+	}
+	var obj interface{}
+	if err := json.Unmarshal(meta.Blob, &obj); err != nil {
+		return err
+	}
+	result := validate.NewSchemaValidator(s, nil, "", strfmt.Default).Validate(obj)
+	if result.IsValid() {
+		return nil
+	}
+	var errs []error
+	for _, err := range result.Errors {
+		errs = append(errs, jsonSchemaError(err))
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+	return utilerrors.NewAggregate(errs)
+}
+
+// jsonSchemaError rewords the errors of the validator, which describe fields
+// of a request body, like "entries[0].name in body is required".
+func jsonSchemaError(err error) error {
+	var verr *openapierrors.Validation
+	if !errors.As(err, &verr) {
+		return err
+	}
+	msg := strings.Replace(verr.Error(), " in body", "", 1)
+	return errors.New(strings.TrimPrefix(msg, "."))
+}
@@ -0,0 +1,189 @@
+package declcfg
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+// jsonSchemaBlobs are the declarative config schemas whose JSON Schemas are
+// generated, and the types of their blobs.
+var jsonSchemaBlobs = []struct {
+	schema      string
+	typ         reflect.Type
+	description string
+}{
+	{SchemaPackage, reflect.TypeOf(Package{}), "A package of a file-based catalog."},
+	{SchemaChannel, reflect.TypeOf(Channel{}), "A channel of a package, which defines the upgrade graph of its bundles."},
+	{SchemaBundle, reflect.TypeOf(Bundle{}), "A bundle of a package."},
+	{SchemaDeprecation, reflect.TypeOf(Deprecation{}), "The deprecations of a package, its channels and its bundles."},
+	{SchemaIcon, reflect.TypeOf(PackageIcon{}), "The icon of a package."},
+	{SchemaCatalog, reflect.TypeOf(Catalog{}), "The metadata of a file-based catalog as a whole."},
+}
+
+// jsonSchemaRequired are the required fields of the declarative config types,
+// which can't be derived from their JSON tags.
+var jsonSchemaRequired = map[reflect.Type][]string{
+	reflect.TypeOf(Package{}):                {"schema", "name", "defaultChannel"},
+	reflect.TypeOf(Icon{}):                   {"base64data", "mediatype"},
+	reflect.TypeOf(PackageIcon{}):            {"schema", "package", "base64data", "mediatype"},
+	reflect.TypeOf(Channel{}):                {"schema", "name", "package", "entries"},
+	reflect.TypeOf(ChannelEntry{}):           {"name"},
+	reflect.TypeOf(Bundle{}):                 {"schema", "name", "package"},
+	reflect.TypeOf(RelatedImage{}):           {"image"},
+	reflect.TypeOf(Deprecation{}):            {"schema", "package", "entries"},
+	reflect.TypeOf(DeprecationEntry{}):       {"reference", "message"},
+	reflect.TypeOf(PackageScopedReference{}): {"schema"},
+	reflect.TypeOf(Catalog{}):                {"schema", "name"},
+	reflect.TypeOf(property.Property{}):      {"type", "value"},
+}
+
+// jsonSchemaEnums are the allowed values of fields of the declarative config
+// types, other than the schema fields of blobs.
+var jsonSchemaEnums = map[reflect.Type]map[string][]string{
+	reflect.TypeOf(PackageScopedReference{}): {"schema": {SchemaPackage, SchemaChannel, SchemaBundle}},
+}
+
+func generateJSONSchema(schema string, typ reflect.Type, description string) ([]byte, error) {
+	s := typeJSONSchema(typ)
+	s["$schema"] = "http://json-schema.org/draft-07/schema#"
+	s["title"] = schema
+	s["description"] = description
+	s["properties"].(map[string]interface{})["schema"] = map[string]interface{}{
+		"type": "string",
+		"enum": []string{schema},
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func typeJSONSchema(typ reflect.Type) map[string]interface{} {
+	if typ == reflect.TypeOf(json.RawMessage{}) {
+		return map[string]interface{}{}
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return typeJSONSchema(typ.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": typeJSONSchema(typ.Elem())}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" || !f.IsExported() {
+				continue
+			}
+			p := typeJSONSchema(f.Type)
+			if enum, ok := jsonSchemaEnums[typ][name]; ok {
+				p["enum"] = enum
+			}
+			properties[name] = p
+		}
+		s := map[string]interface{}{"type": "object", "properties": properties}
+		if required, ok := jsonSchemaRequired[typ]; ok {
+			s["required"] = required
+		}
+		return s
+	}
+	panic("unsupported type " + typ.String())
+}
+
+func TestJSONSchemas(t *testing.T) {
+	var schemas []string
+	for _, b := range jsonSchemaBlobs {
+		schemas = append(schemas, b.schema)
+		expected, err := generateJSONSchema(b.schema, b.typ, b.description)
+		require.NoError(t, err)
+		if os.Getenv("UPDATE_JSONSCHEMAS") != "" {
+			require.NoError(t, os.WriteFile(filepath.Join("jsonschema", b.schema+JSONSchemaExt), expected, 0644))
+			continue
+		}
+		actual, err := JSONSchema(b.schema)
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(actual), "JSON Schema of %q is out of date: regenerate it with UPDATE_JSONSCHEMAS=1", b.schema)
+	}
+	require.ElementsMatch(t, schemas, JSONSchemas())
+
+	_, err := JSONSchema("olm.packge")
+	require.ErrorContains(t, err, `no JSON Schema for schema "olm.packge"`)
+}
+
+func TestValidateJSONSchemaFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"foo/catalog.yaml": &fstest.MapFile{Data: []byte(`---
+schema: olm.package
+name: foo
+defaultChannel: stable
+---
+schema: olm.channel
+package: foo
+name: stable
+entries:
+  - name: foo.v0.1.0
+  - replaces: foo.v0.1.0
+    skips: [1]
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.1.0
+image: quay.io/example/foo:v0.1.0
+properties:
+  - type: olm.package
+  - value: {}
+relatedImages:
+  - name: foo
+---
+schema: example.com.custom
+anything: goes
+`)},
+		"bar/catalog.json": &fstest.MapFile{Data: []byte(`{
+  "schema": "olm.package",
+  "name": "bar",
+  "icon": {"base64data": 1}
+}
+{
+  "schema": "olm.deprecations",
+  "package": "bar",
+  "entries": [{"reference": {"schema": "olm.operator"}, "message": "deprecated"}]
+}
+`)},
+	}
+
+	err := ValidateJSONSchemaFS(context.Background(), fsys)
+	require.Error(t, err)
+	require.EqualError(t, err, `[`+strings.Join([]string{
+		`bar/catalog.json:1: schema "olm.package": [defaultChannel is required, icon.base64data must be of type string: "number", icon.mediatype is required]`,
+		`bar/catalog.json:6: schema "olm.deprecations": entries[0].reference.schema should be one of [olm.package olm.channel olm.bundle]`,
+		`foo/catalog.yaml:6: schema "olm.channel": [entries[1].name is required, entries[1].skips[0] must be of type string: "number"]`,
+		`foo/catalog.yaml:14: schema "olm.bundle": [properties[0].value is required, properties[1].type is required, relatedImages[0].image is required]`,
+	}, ", ")+`]`)
+
+	valid := buildValidDeclarativeConfig(validDeclarativeConfigSpec{IncludeUnrecognized: true, IncludeDeprecations: true})
+	valid.Catalogs = []Catalog{{Schema: SchemaCatalog, Name: "test-catalog"}}
+	valid.Icons = []PackageIcon{{Schema: SchemaIcon, Package: "anakin", Data: []byte("<svg></svg>"), MediaType: "image/svg+xml"}}
+	rootDir := t.TempDir()
+	require.NoError(t, WriteFS(valid, rootDir, WriteYAML, ".yaml"))
+	require.NoError(t, ValidateJSONSchemaFS(context.Background(), os.DirFS(rootDir)))
+}
@@ -1,6 +1,7 @@
 package declcfg
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
@@ -8,11 +9,13 @@ import (
 	"io"
 	"io/fs"
 	"runtime"
+	"strings"
 	"sync"
 
 	"github.com/joelanford/ignore"
 	"golang.org/x/sync/errgroup"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/operator-framework/api/pkg/operators"
@@ -22,6 +25,11 @@ import (
 
 const (
 	indexIgnoreFilename = ".indexignore"
+
+	// GzipExt is the extension of gzip-compressed declarative config files,
+	// like "catalog.json.gz". They are decompressed transparently when they
+	// are loaded.
+	GzipExt = ".gz"
 )
 
 type WalkMetasFSFunc func(path string, meta *Meta, err error) error
@@ -29,6 +37,9 @@ type WalkMetasFSFunc func(path string, meta *Meta, err error) error
 // WalkMetasFS walks the filesystem rooted at root and calls walkFn for each individual meta object found in the root.
 // By default, WalkMetasFS is not thread-safe because it invokes walkFn concurrently. In order to make it thread-safe,
 // use the WithConcurrency(1) to avoid concurrent invocations of walkFn.
+//
+// Errors parsing a file, and errors returned by walkFn, are *LoadError values
+// that locate the offending object in its file.
 func WalkMetasFS(ctx context.Context, root fs.FS, walkFn WalkMetasFSFunc, opts ...LoadOption) error {
 	if root == nil {
 		return fmt.Errorf("no declarative config filesystem provided")
@@ -40,6 +51,9 @@ func WalkMetasFS(ctx context.Context, root fs.FS, walkFn WalkMetasFSFunc, opts .
 	for _, opt := range opts {
 		opt(&options)
 	}
+	if options.concurrency <= 0 {
+		options.concurrency = runtime.NumCPU()
+	}
 
 	pathChan := make(chan string, options.concurrency)
 
@@ -131,10 +145,17 @@ func walkFiles(root fs.FS, fn func(root fs.FS, path string, err error) error) er
 
 type LoadOptions struct {
 	concurrency int
+
+	// collectError, if set, is called with the errors returned by walkFn,
+	// and the walk carries on with the next object or file rather than
+	// stopping.
+	collectError func(error)
 }
 
 type LoadOption func(*LoadOptions)
 
+// WithConcurrency sets the number of files parsed concurrently. It defaults
+// to the number of CPUs.
 func WithConcurrency(concurrency int) LoadOption {
 	return func(opts *LoadOptions) {
 		opts.concurrency = concurrency
@@ -144,18 +165,34 @@ func WithConcurrency(concurrency int) LoadOption {
 // LoadFS loads a declarative config from the provided root FS. LoadFS walks the
 // filesystem from root and uses a gitignore-style filename matcher to skip files
 // that match patterns found in .indexignore files found throughout the filesystem.
-// If LoadFS encounters an error loading or parsing any file, the error will be
-// immediately returned.
+// Files are parsed concurrently. If LoadFS encounters errors parsing files, it
+// loads the remaining files and returns an aggregate of every *LoadError,
+// sorted by path and line.
 func LoadFS(ctx context.Context, root fs.FS, opts ...LoadOption) (*DeclarativeConfig, error) {
 	builder := fbcBuilder{}
+	var (
+		errsMu sync.Mutex
+		errs   []error
+	)
+	collectErrors := func(o *LoadOptions) {
+		o.collectError = func(err error) {
+			errsMu.Lock()
+			defer errsMu.Unlock()
+			errs = append(errs, err)
+		}
+	}
 	if err := WalkMetasFS(ctx, root, func(path string, meta *Meta, err error) error {
 		if err != nil {
 			return err
 		}
 		return builder.addMeta(meta)
-	}, opts...); err != nil {
+	}, append(opts, collectErrors)...); err != nil {
 		return nil, err
 	}
+	if len(errs) > 0 {
+		sortLoadErrors(errs)
+		return nil, utilerrors.NewAggregate(errs)
+	}
 	return &builder.cfg, nil
 }
 
@@ -174,7 +211,7 @@ func sendPaths(ctx context.Context, root fs.FS, pathChan chan<- string) error {
 	})
 }
 
-func parseMetaPaths(ctx context.Context, root fs.FS, pathChan <-chan string, walkFn WalkMetasFSFunc, _ LoadOptions) error {
+func parseMetaPaths(ctx context.Context, root fs.FS, pathChan <-chan string, walkFn WalkMetasFSFunc, options LoadOptions) error {
 	for {
 		select {
 		case <-ctx.Done(): // don't block on receiving from pathChan
@@ -184,14 +221,35 @@ func parseMetaPaths(ctx context.Context, root fs.FS, pathChan <-chan string, wal
 				return nil
 			}
 			err := func() error { // using closure to ensure file is closed immediately after use
-				file, err := root.Open(path)
+				// walk passes an object, or an error reading the file, to
+				// walkFn, and collects or returns the error it returns.
+				index := 0
+				walk := func(meta *Meta, err error) error {
+					defer func() { index++ }()
+					if err != nil {
+						err = newLoadError(root, path, index, meta, err)
+					}
+					if err := walkFn(path, meta, err); err != nil {
+						err = newLoadError(root, path, index, meta, err)
+						if options.collectError == nil {
+							return err
+						}
+						options.collectError(err)
+					}
+					return nil
+				}
+
+				file, err := openFile(root, path)
 				if err != nil {
-					return err
+					return walk(nil, err)
 				}
 				defer file.Close()
 
 				return WalkMetasReader(file, func(meta *Meta, err error) error {
-					return walkFn(path, meta, err)
+					if err == nil {
+						err = resolveBundleObjectRefs(root, path, meta)
+					}
+					return walk(meta, err)
 				})
 			}()
 			if err != nil {
@@ -201,15 +259,49 @@ func parseMetaPaths(ctx context.Context, root fs.FS, pathChan <-chan string, wal
 	}
 }
 
+// openFile opens the file at path in root, decompressing it if its name ends
+// with GzipExt.
+func openFile(root fs.FS, path string) (io.ReadCloser, error) {
+	file, err := root.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, GzipExt) {
+		return file, nil
+	}
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("decompress: %v", err)
+	}
+	return gzipFile{Reader: zr, file: file}, nil
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	file fs.File
+}
+
+func (f gzipFile) Close() error {
+	if err := f.Reader.Close(); err != nil {
+		f.file.Close()
+		return err
+	}
+	return f.file.Close()
+}
+
 func readBundleObjects(b *Bundle) error {
-	var obj property.BundleObject
 	for i, props := range b.Properties {
 		if props.Type != property.TypeBundleObject {
 			continue
 		}
+		var obj property.BundleObject
 		if err := json.Unmarshal(props.Value, &obj); err != nil {
 			return fmt.Errorf("package %q, bundle %q: parse property at index %d as bundle object: %v", b.Package, b.Name, i, err)
 		}
+		if obj.Ref != "" {
+			return fmt.Errorf("package %q, bundle %q: bundle object property at index %d references file %q, which can only be read when the catalog is loaded from a filesystem", b.Package, b.Name, i, obj.Ref)
+		}
 		objJSON, err := yaml.ToJSON(obj.Data)
 		if err != nil {
 			return fmt.Errorf("package %q, bundle %q: convert bundle object property at index %d to JSON: %v", b.Package, b.Name, i, err)
@@ -250,7 +342,7 @@ func LoadReader(r io.Reader) (*DeclarativeConfig, error) {
 // LoadFile will unmarshall declarative config components from a single filename provided in 'path'
 // located at a filesystem hierarchy 'root'
 func LoadFile(root fs.FS, path string) (*DeclarativeConfig, error) {
-	file, err := root.Open(path)
+	file, err := openFile(root, path)
 	if err != nil {
 		return nil, err
 	}
@@ -282,6 +374,9 @@ type fbcBuilder struct {
 	channelsMu     sync.Mutex
 	bundlesMu      sync.Mutex
 	deprecationsMu sync.Mutex
+	iconsMu        sync.Mutex
+	catalogsMu     sync.Mutex
+	extensionsMu   sync.Mutex
 	othersMu       sync.Mutex
 }
 
@@ -322,9 +417,35 @@ func (c *fbcBuilder) addMeta(in *Meta) error {
 		c.deprecationsMu.Lock()
 		c.cfg.Deprecations = append(c.cfg.Deprecations, d)
 		c.deprecationsMu.Unlock()
+	case SchemaIcon:
+		var i PackageIcon
+		if err := json.Unmarshal(in.Blob, &i); err != nil {
+			return fmt.Errorf("parse icon: %w", err)
+		}
+		c.iconsMu.Lock()
+		c.cfg.Icons = append(c.cfg.Icons, i)
+		c.iconsMu.Unlock()
+	case SchemaCatalog:
+		var cat Catalog
+		if err := json.Unmarshal(in.Blob, &cat); err != nil {
+			return fmt.Errorf("parse catalog: %w", err)
+		}
+		c.catalogsMu.Lock()
+		c.cfg.Catalogs = append(c.cfg.Catalogs, cat)
+		c.catalogsMu.Unlock()
 	case "":
 		return fmt.Errorf("object '%s' is missing root schema field", string(in.Blob))
 	default:
+		if ext, ok := lookupSchemaExtension(in.Schema); ok {
+			e, err := parseExtension(ext, *in)
+			if err != nil {
+				return fmt.Errorf("parse %s: %w", in.Schema, err)
+			}
+			c.extensionsMu.Lock()
+			c.cfg.Extensions = append(c.cfg.Extensions, *e)
+			c.extensionsMu.Unlock()
+			return nil
+		}
 		c.othersMu.Lock()
 		c.cfg.Others = append(c.cfg.Others, *in)
 		c.othersMu.Unlock()
@@ -0,0 +1,130 @@
+package declcfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+)
+
+// LoadError is an error loading an object of a declarative config file.
+type LoadError struct {
+	Path string
+	// Line is the line of the file where the object starts, or 0 if it
+	// can't be determined.
+	Line int
+	// Schema is the schema of the object, or empty if the object couldn't
+	// be parsed.
+	Schema string
+	Err    error
+}
+
+func (e *LoadError) Error() string {
+	location := e.Path
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d", e.Path, e.Line)
+	}
+	if e.Schema == "" {
+		return fmt.Sprintf("%s: %v", location, e.Err)
+	}
+	return fmt.Sprintf("%s: schema %q: %v", location, e.Schema, e.Err)
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// newLoadError wraps err in a *LoadError for the object at index in the file
+// at path, unless err already is one. The file is only read again to locate
+// the object once an error occurs.
+func newLoadError(root fs.FS, path string, index int, meta *Meta, err error) error {
+	var loadErr *LoadError
+	if errors.As(err, &loadErr) {
+		return err
+	}
+	loadErr = &LoadError{Path: path, Line: metaLine(root, path, index), Err: err}
+	if meta != nil {
+		loadErr.Schema = meta.Schema
+	}
+	return loadErr
+}
+
+// sortLoadErrors sorts errs by path and line.
+func sortLoadErrors(errs []error) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		var a, b *LoadError
+		if !errors.As(errs[i], &a) || !errors.As(errs[j], &b) {
+			return false
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+}
+
+// metaLine returns the line of the file at path where the object at index
+// starts, splitting the file into objects as WalkMetasReader does: as a
+// stream of JSON objects if it starts with "{", and as YAML documents
+// otherwise. It returns 0 if the object can't be found.
+func metaLine(root fs.FS, path string, index int) int {
+	file, err := openFile(root, path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return 0
+	}
+	offset := yamlDocumentOffset(data, index)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		offset = jsonObjectOffset(data, index)
+	}
+	if offset < 0 {
+		return 0
+	}
+	return 1 + bytes.Count(data[:offset], []byte("\n"))
+}
+
+func jsonObjectOffset(data []byte, index int) int {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for i := 0; i < index; i++ {
+		var obj json.RawMessage
+		if err := dec.Decode(&obj); err != nil {
+			return -1
+		}
+	}
+	offset := int(dec.InputOffset())
+	for offset < len(data) && bytes.IndexByte([]byte(" \t\r\n"), data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// yamlDocumentOffset follows the rules of the YAML reader of
+// k8s.io/apimachinery: lines starting with "---" separate documents, and
+// separators that don't end a document are skipped.
+func yamlDocumentOffset(data []byte, index int) int {
+	inDocument := false
+	for offset := 0; offset < len(data); {
+		next := len(data)
+		if i := bytes.IndexByte(data[offset:], '\n'); i >= 0 {
+			next = offset + i + 1
+		}
+		if bytes.HasPrefix(data[offset:next], []byte("---")) {
+			inDocument = false
+		} else if !inDocument {
+			if index == 0 {
+				return offset
+			}
+			index--
+			inDocument = true
+		}
+		offset = next
+	}
+	return -1
+}
@@ -1,18 +1,22 @@
 package declcfg
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/fs"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/operator-framework/operator-registry/alpha/property"
@@ -1030,3 +1034,217 @@ func TestLoadFile(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadReaderCatalog(t *testing.T) {
+	const input = `---
+schema: olm.catalog
+name: foo-catalog
+displayName: Foo Catalog
+publisher: Foo
+buildTime: "2024-01-02T15:04:05Z"
+sourceRefs:
+  - quay.io/foo/catalog-source:latest
+`
+	cfg, err := LoadReader(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Empty(t, cfg.Others)
+	require.Equal(t, []Catalog{{
+		Schema:      SchemaCatalog,
+		Name:        "foo-catalog",
+		DisplayName: "Foo Catalog",
+		Publisher:   "Foo",
+		BuildTime:   "2024-01-02T15:04:05Z",
+		SourceRefs:  []string{"quay.io/foo/catalog-source:latest"},
+	}}, cfg.Catalogs)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteYAML(*cfg, &buf))
+	require.Equal(t, `---
+buildTime: "2024-01-02T15:04:05Z"
+displayName: Foo Catalog
+name: foo-catalog
+publisher: Foo
+schema: olm.catalog
+sourceRefs:
+- quay.io/foo/catalog-source:latest
+`, buf.String())
+}
+
+func TestLoadReaderIcon(t *testing.T) {
+	const input = `---
+base64data: PHN2Zz48L3N2Zz4=
+mediatype: image/svg+xml
+package: foo
+schema: olm.icon
+`
+	cfg, err := LoadReader(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Empty(t, cfg.Others)
+	require.Equal(t, []PackageIcon{{
+		Schema:    SchemaIcon,
+		Package:   "foo",
+		Data:      []byte("<svg></svg>"),
+		MediaType: "image/svg+xml",
+	}}, cfg.Icons)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteYAML(*cfg, &buf))
+	require.Equal(t, input, buf.String())
+}
+
+func TestLoadReaderUnknownPropertyValues(t *testing.T) {
+	type spec struct {
+		name     string
+		input    string
+		expected string
+	}
+	specs := []spec{
+		{
+			name: "JSON",
+			input: `{"schema": "olm.bundle", "package": "foo", "name": "foo.v1.0.0", "properties": [
+	{"type": "example.com/experimental", "value": { "z": 1.50, "a": 12345678901234567890, "range": "<2.0.0", "path": "c:\\u003c" }}
+]}`,
+			expected: `{"z":1.50,"a":12345678901234567890,"range":"<2.0.0","path":"c:\\u003c"}`,
+		},
+		{
+			name: "YAML",
+			input: `---
+schema: olm.bundle
+package: foo
+name: foo.v1.0.0
+properties:
+- type: example.com/experimental
+  value:
+    range: <2.0.0 & >=1.0.0
+`,
+			expected: `{"range":"<2.0.0 & >=1.0.0"}`,
+		},
+	}
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			cfg, err := LoadReader(strings.NewReader(s.input))
+			require.NoError(t, err)
+			require.Len(t, cfg.Bundles, 1)
+			require.Equal(t, []property.Property{{Type: "example.com/experimental", Value: json.RawMessage(s.expected)}}, cfg.Bundles[0].Properties)
+		})
+	}
+}
+
+func TestLoadFSErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"valid.yaml": &fstest.MapFile{Data: []byte(`---
+schema: olm.package
+name: foo
+`)},
+		"bad-bundle.yaml": &fstest.MapFile{Data: []byte(`---
+schema: olm.package
+name: bar
+---
+# the bundle's properties must be a list
+schema: olm.bundle
+package: bar
+name: bar.v0.1.0
+properties: {}
+---
+schema: olm.channel
+package: bar
+name: stable
+entries: 1
+`)},
+		"bad-syntax.json": &fstest.MapFile{Data: []byte(`{"schema": "olm.package", "name": "baz"}
+{"schema": "olm.bundle", "package": "baz",
+`)},
+		"dir/no-schema.yaml": &fstest.MapFile{Data: []byte(`name: qux
+`)},
+		"compressed.yaml.gz": &fstest.MapFile{Data: gzipData(t, `---
+schema: olm.package
+name: quux
+---
+schema: olm.bundle
+package: quux
+name: quux.v0.1.0
+properties: {}
+`)},
+		"not-compressed.json.gz": &fstest.MapFile{Data: []byte(`{"schema": "olm.package", "name": "corge"}`)},
+	}
+
+	for _, concurrency := range []int{1, 4} {
+		t.Run(fmt.Sprintf("Concurrency%d", concurrency), func(t *testing.T) {
+			_, err := LoadFS(context.Background(), fsys, WithConcurrency(concurrency))
+			var agg utilerrors.Aggregate
+			require.ErrorAs(t, err, &agg)
+
+			type location struct {
+				Path   string
+				Line   int
+				Schema string
+			}
+			var locations []location
+			for _, err := range agg.Errors() {
+				var loadErr *LoadError
+				require.ErrorAs(t, err, &loadErr)
+				locations = append(locations, location{loadErr.Path, loadErr.Line, loadErr.Schema})
+			}
+			require.Equal(t, []location{
+				{"bad-bundle.yaml", 5, SchemaBundle},
+				{"bad-bundle.yaml", 11, SchemaChannel},
+				{"bad-syntax.json", 2, ""},
+				{"compressed.yaml.gz", 5, SchemaBundle},
+				{"dir/no-schema.yaml", 1, ""},
+				{"not-compressed.json.gz", 0, ""},
+			}, locations)
+			require.Contains(t, err.Error(), `bad-bundle.yaml:5: schema "olm.bundle": parse bundle: `)
+		})
+	}
+}
+
+func gzipData(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestLoadFSBundleObjectRefs(t *testing.T) {
+	configMap := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo"}}`
+	bundle := func(ref string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(`{"schema": "olm.bundle", "package": "foo", "name": "foo.v0.1.0", "properties": [
+  {"type": "olm.package", "value": {"packageName": "foo", "version": "0.1.0"}},
+  {"type": "olm.bundle.object", "value": {"ref": "` + ref + `"}}
+]}`)}
+	}
+
+	t.Run("Resolved", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"foo/catalog.json":                    bundle("objects/configmap-foo.json"),
+			"foo/objects/.indexignore":            &fstest.MapFile{Data: []byte("*\n")},
+			"foo/objects/configmap-foo.json":      &fstest.MapFile{Data: []byte(configMap)},
+			"foo/objects/unused-not-a-catalog.js": &fstest.MapFile{Data: []byte("{")},
+		}
+		cfg, err := LoadFS(context.Background(), fsys)
+		require.NoError(t, err)
+		require.Len(t, cfg.Bundles, 1)
+		require.Equal(t, []string{configMap}, cfg.Bundles[0].Objects)
+		require.Equal(t, property.MustBuildBundleObject([]byte(configMap)), cfg.Bundles[0].Properties[1])
+	})
+
+	t.Run("OutsideCatalog", func(t *testing.T) {
+		fsys := fstest.MapFS{"foo/catalog.json": bundle("../../configmap-foo.json")}
+		_, err := LoadFS(context.Background(), fsys)
+		require.ErrorContains(t, err, `bundle object reference "../../configmap-foo.json" at index 1 is not a relative path within the catalog`)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		fsys := fstest.MapFS{"foo/catalog.json": bundle("objects/configmap-foo.json")}
+		_, err := LoadFS(context.Background(), fsys)
+		require.ErrorContains(t, err, `read bundle object reference "objects/configmap-foo.json" at index 1`)
+	})
+
+	t.Run("Reader", func(t *testing.T) {
+		_, err := LoadReader(bytes.NewReader(bundle("objects/configmap-foo.json").Data))
+		require.ErrorContains(t, err, `references file "objects/configmap-foo.json", which can only be read when the catalog is loaded from a filesystem`)
+	})
+}
@@ -0,0 +1,234 @@
+package declcfg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// NamedFS is a declarative config root with a name that identifies it in
+// error messages, typically the path of the directory it was loaded from.
+type NamedFS struct {
+	Name string
+	FS   fs.FS
+}
+
+// MergeFS returns a filesystem that contains each of the provided roots
+// in its own top-level directory, so that several independently-built
+// declarative config roots can be loaded as one logical catalog.
+//
+// Each package must be defined in exactly one root. MergeFS reads the blobs
+// of the roots to check this, and returns an error if a package is defined in
+// more than one root, so that every use of the merged filesystem, including
+// serving a cache that was built from it without loading it again, relies on
+// the check.
+func MergeFS(ctx context.Context, roots ...NamedFS) (fs.FS, error) {
+	m := &mergedFS{}
+	packageRoots := map[string]int{}
+	for i, root := range roots {
+		m.roots = append(m.roots, mergedRoot{dir: strconv.Itoa(i), name: root.Name, fsys: root.FS})
+
+		packages, err := rootPackages(ctx, root.FS)
+		if err != nil {
+			return nil, fmt.Errorf("read packages of %q: %w", root.Name, err)
+		}
+		for _, packageName := range packages {
+			if other, ok := packageRoots[packageName]; ok {
+				return nil, fmt.Errorf("package %q is defined in both %q and %q", packageName, roots[other].Name, root.Name)
+			}
+			packageRoots[packageName] = i
+		}
+	}
+	return m, nil
+}
+
+// rootPackages returns the sorted names of the packages that the blobs of
+// fsys belong to.
+func rootPackages(ctx context.Context, fsys fs.FS) ([]string, error) {
+	var (
+		packages   = sets.New[string]()
+		packagesMu sync.Mutex
+	)
+	if err := WalkMetasFS(ctx, fsys, func(_ string, meta *Meta, err error) error {
+		if err != nil {
+			return err
+		}
+		packageName := meta.Package
+		if meta.Schema == SchemaPackage {
+			packageName = meta.Name
+		}
+		if packageName == "" {
+			return nil
+		}
+		packagesMu.Lock()
+		defer packagesMu.Unlock()
+		packages.Insert(packageName)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return sets.List(packages), nil
+}
+
+type mergedRoot struct {
+	dir  string
+	name string
+	fsys fs.FS
+}
+
+type mergedFS struct {
+	roots []mergedRoot
+}
+
+var (
+	_ fs.ReadDirFS = &mergedFS{}
+	_ fs.StatFS    = &mergedFS{}
+)
+
+// split returns the root that contains name and the path of name within it.
+func (m *mergedFS) split(op, name string) (fs.FS, string, error) {
+	if !fs.ValidPath(name) {
+		return nil, "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	dir, rest, _ := strings.Cut(name, "/")
+	if rest == "" {
+		rest = "."
+	}
+	for _, root := range m.roots {
+		if root.dir == dir {
+			return root.fsys, rest, nil
+		}
+	}
+	return nil, "", &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+func (m *mergedFS) Open(name string) (fs.File, error) {
+	if name == "." {
+		return &mergedDir{entries: m.entries()}, nil
+	}
+	fsys, rest, err := m.split("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := fsys.Open(rest)
+	if err != nil || rest != "." {
+		return f, err
+	}
+	return &rootDir{File: f, name: name}, nil
+}
+
+func (m *mergedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == "." {
+		return m.entries(), nil
+	}
+	fsys, rest, err := m.split("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadDir(fsys, rest)
+}
+
+func (m *mergedFS) Stat(name string) (fs.FileInfo, error) {
+	if name == "." {
+		return mergedDirInfo("."), nil
+	}
+	fsys, rest, err := m.split("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := fs.Stat(fsys, rest)
+	if err != nil || rest != "." {
+		return info, err
+	}
+	return renamedInfo{FileInfo: info, name: name}, nil
+}
+
+func (m *mergedFS) entries() []fs.DirEntry {
+	entries := make([]fs.DirEntry, 0, len(m.roots))
+	for _, root := range m.roots {
+		var info fs.FileInfo = mergedDirInfo(root.dir)
+		if rootInfo, err := fs.Stat(root.fsys, "."); err == nil {
+			info = renamedInfo{FileInfo: rootInfo, name: root.dir}
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	// ReadDir must return entries sorted by name, and the numeric directory
+	// names are not in lexical order beyond 10 roots.
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries
+}
+
+// mergedDir is the synthetic top-level directory of a mergedFS.
+type mergedDir struct {
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *mergedDir) Stat() (fs.FileInfo, error) { return mergedDirInfo("."), nil }
+func (d *mergedDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: ".", Err: fs.ErrInvalid}
+}
+func (d *mergedDir) Close() error { return nil }
+
+func (d *mergedDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+	return remaining[:n], nil
+}
+
+// rootDir is the top-level directory of one of the roots of a mergedFS. It
+// reports the name of its directory in the mergedFS rather than ".".
+type rootDir struct {
+	fs.File
+	name string
+}
+
+func (d *rootDir) Stat() (fs.FileInfo, error) {
+	info, err := d.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return renamedInfo{FileInfo: info, name: d.name}, nil
+}
+
+func (d *rootDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rd, ok := d.File.(fs.ReadDirFile)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: fs.ErrInvalid}
+	}
+	return rd.ReadDir(n)
+}
+
+type renamedInfo struct {
+	fs.FileInfo
+	name string
+}
+
+func (i renamedInfo) Name() string { return i.name }
+
+type mergedDirInfo string
+
+func (i mergedDirInfo) Name() string       { return string(i) }
+func (i mergedDirInfo) Size() int64        { return 0 }
+func (i mergedDirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0555 }
+func (i mergedDirInfo) ModTime() time.Time { return time.Time{} }
+func (i mergedDirInfo) IsDir() bool        { return true }
+func (i mergedDirInfo) Sys() any           { return nil }
@@ -0,0 +1,55 @@
+package declcfg
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeFS(t *testing.T) {
+	fooFS := fstest.MapFS{
+		"foo/catalog.yaml": &fstest.MapFile{Data: []byte(`---
+schema: olm.package
+name: foo
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.1.0
+`)},
+		"foo/.indexignore": &fstest.MapFile{Data: []byte("ignored.yaml\n")},
+		"foo/ignored.yaml": &fstest.MapFile{Data: []byte("not a declarative config")},
+	}
+	barFS := fstest.MapFS{
+		"catalog.json": &fstest.MapFile{Data: []byte(`{"schema": "olm.package", "name": "bar"}`)},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		merged, err := MergeFS(context.Background(), NamedFS{Name: "foo", FS: fooFS}, NamedFS{Name: "bar", FS: barFS})
+		require.NoError(t, err)
+		require.NoError(t, fstest.TestFS(merged, "0/foo/catalog.yaml", "1/catalog.json"))
+
+		cfg, err := LoadFS(context.Background(), merged)
+		require.NoError(t, err)
+		var packages []string
+		for _, p := range cfg.Packages {
+			packages = append(packages, p.Name)
+		}
+		assert.ElementsMatch(t, []string{"foo", "bar"}, packages)
+		assert.Len(t, cfg.Bundles, 1)
+	})
+
+	t.Run("PackageCollision", func(t *testing.T) {
+		otherFooFS := fstest.MapFS{
+			"catalog.yaml": &fstest.MapFile{Data: []byte(`---
+schema: olm.bundle
+package: foo
+name: foo.v0.2.0
+`)},
+		}
+		_, err := MergeFS(context.Background(), NamedFS{Name: "a", FS: fooFS}, NamedFS{Name: "b", FS: barFS}, NamedFS{Name: "c", FS: otherFooFS})
+		require.EqualError(t, err, `package "foo" is defined in both "a" and "c"`)
+	})
+}
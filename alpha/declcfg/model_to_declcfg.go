@@ -29,9 +29,21 @@ func ConvertFromModel(mpkgs model.Model) DeclarativeConfig {
 			DefaultChannel: defaultChannel,
 			Icon:           i,
 			Description:    mpkg.Description,
+			Properties:     mpkg.Properties,
 		})
 		cfg.Channels = append(cfg.Channels, channels...)
 		cfg.Bundles = append(cfg.Bundles, bundles...)
+		for _, e := range mpkg.Extensions {
+			cfg.Extensions = append(cfg.Extensions, Extension{
+				Meta: Meta{
+					Schema:  e.Schema,
+					Package: mpkg.Name,
+					Name:    e.Name,
+					Blob:    e.Blob,
+				},
+				Value: e.Value,
+			})
+		}
 	}
 
 	sort.Slice(cfg.Packages, func(i, j int) bool {
@@ -49,6 +61,15 @@ func ConvertFromModel(mpkgs model.Model) DeclarativeConfig {
 		}
 		return cfg.Bundles[i].Name < cfg.Bundles[j].Name
 	})
+	sort.SliceStable(cfg.Extensions, func(i, j int) bool {
+		if cfg.Extensions[i].Package != cfg.Extensions[j].Package {
+			return cfg.Extensions[i].Package < cfg.Extensions[j].Package
+		}
+		if cfg.Extensions[i].Schema != cfg.Extensions[j].Schema {
+			return cfg.Extensions[i].Schema < cfg.Extensions[j].Schema
+		}
+		return cfg.Extensions[i].Name < cfg.Extensions[j].Name
+	})
 
 	return cfg
 }
@@ -60,13 +81,11 @@ func traverseModelChannels(mpkg model.Package) ([]Channel, []Bundle) {
 	for _, ch := range mpkg.Channels {
 		// initialize channel
 		c := Channel{
-			Schema:  SchemaChannel,
-			Name:    ch.Name,
-			Package: ch.Package.Name,
-			Entries: []ChannelEntry{},
-			// NOTICE: The field Properties of the type Channel is for internal use only.
-			//   DO NOT use it for any public-facing functionalities.
-			//   This API is in alpha stage and it is subject to change.
+			Schema:     SchemaChannel,
+			Name:       ch.Name,
+			Package:    ch.Package.Name,
+			Priority:   ch.Priority,
+			Entries:    []ChannelEntry{},
 			Properties: ch.Properties,
 		}
 
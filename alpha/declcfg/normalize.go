@@ -0,0 +1,127 @@
+package declcfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"sort"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+// Normalize sorts the lists of cfg whose order has no meaning, so that configs
+// with the same content are written identically. Properties are sorted by type
+// and value, with olm.bundle.object properties last; channel entries by name,
+// and their skips; related images by image and name; and deprecation entries
+// by reference. The order of packages, channels and bundles is normalized by
+// the write functions.
+func Normalize(cfg *DeclarativeConfig) {
+	for i := range cfg.Packages {
+		sortProperties(cfg.Packages[i].Properties)
+	}
+	for i := range cfg.Channels {
+		c := &cfg.Channels[i]
+		sortProperties(c.Properties)
+		sort.SliceStable(c.Entries, func(i, j int) bool {
+			return c.Entries[i].Name < c.Entries[j].Name
+		})
+		for _, e := range c.Entries {
+			sort.Strings(e.Skips)
+		}
+	}
+	for i := range cfg.Bundles {
+		b := &cfg.Bundles[i]
+		sortProperties(b.Properties)
+		sort.SliceStable(b.RelatedImages, func(i, j int) bool {
+			if b.RelatedImages[i].Image != b.RelatedImages[j].Image {
+				return b.RelatedImages[i].Image < b.RelatedImages[j].Image
+			}
+			return b.RelatedImages[i].Name < b.RelatedImages[j].Name
+		})
+	}
+	for i := range cfg.Deprecations {
+		entries := cfg.Deprecations[i].Entries
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].Reference.Schema != entries[j].Reference.Schema {
+				return entries[i].Reference.Schema < entries[j].Reference.Schema
+			}
+			return entries[i].Reference.Name < entries[j].Reference.Name
+		})
+	}
+}
+
+// sortProperties sorts properties by type and compacted value, with the
+// olm.bundle.object properties, which hold whole manifests, last.
+func sortProperties(properties []property.Property) {
+	sort.SliceStable(properties, func(i, j int) bool {
+		pi, pj := properties[i], properties[j]
+		if (pi.Type == property.TypeBundleObject) != (pj.Type == property.TypeBundleObject) {
+			return pj.Type == property.TypeBundleObject
+		}
+		if pi.Type != pj.Type {
+			return pi.Type < pj.Type
+		}
+		return bytes.Compare(compactJSON(pi.Value), compactJSON(pj.Value)) < 0
+	})
+}
+
+// compactJSON returns data without insignificant whitespace, or as is if it
+// isn't valid JSON.
+func compactJSON(data []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return data
+	}
+	return buf.Bytes()
+}
+
+// NormalizedCopy returns a normalized copy of cfg, leaving cfg as is. Besides
+// the lists sorted by Normalize, catalogs are sorted by name and the blobs of
+// unrecognized schemas by package, schema and name, so that blobs whose order
+// the write functions keep are also written in a stable order.
+func NormalizedCopy(cfg DeclarativeConfig) DeclarativeConfig {
+	out := DeclarativeConfig{
+		Catalogs:     slices.Clone(cfg.Catalogs),
+		Packages:     slices.Clone(cfg.Packages),
+		Channels:     slices.Clone(cfg.Channels),
+		Bundles:      slices.Clone(cfg.Bundles),
+		Deprecations: slices.Clone(cfg.Deprecations),
+		Icons:        slices.Clone(cfg.Icons),
+		Extensions:   slices.Clone(cfg.Extensions),
+		Others:       slices.Clone(cfg.Others),
+	}
+	for i := range out.Packages {
+		out.Packages[i].Properties = slices.Clone(out.Packages[i].Properties)
+	}
+	for i := range out.Channels {
+		c := &out.Channels[i]
+		c.Properties = slices.Clone(c.Properties)
+		c.Entries = slices.Clone(c.Entries)
+		for j := range c.Entries {
+			c.Entries[j].Skips = slices.Clone(c.Entries[j].Skips)
+		}
+	}
+	for i := range out.Bundles {
+		out.Bundles[i].Properties = slices.Clone(out.Bundles[i].Properties)
+		out.Bundles[i].RelatedImages = slices.Clone(out.Bundles[i].RelatedImages)
+	}
+	for i := range out.Deprecations {
+		out.Deprecations[i].Entries = slices.Clone(out.Deprecations[i].Entries)
+	}
+
+	Normalize(&out)
+	sort.SliceStable(out.Catalogs, func(i, j int) bool {
+		return out.Catalogs[i].Name < out.Catalogs[j].Name
+	})
+	sort.SliceStable(out.Others, func(i, j int) bool {
+		oi, oj := out.Others[i], out.Others[j]
+		if oi.Package != oj.Package {
+			return oi.Package < oj.Package
+		}
+		if oi.Schema != oj.Schema {
+			return oi.Schema < oj.Schema
+		}
+		return oi.Name < oj.Name
+	})
+	return out
+}
@@ -0,0 +1,63 @@
+package declcfg
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+func TestNormalize(t *testing.T) {
+	cfg := DeclarativeConfig{
+		Packages: []Package{{Schema: SchemaPackage, Name: "foo", Properties: []property.Property{
+			{Type: "b", Value: json.RawMessage(`"2"`)},
+			{Type: "a", Value: json.RawMessage(`"1"`)},
+		}}},
+		Channels: []Channel{{Schema: SchemaChannel, Package: "foo", Name: "stable", Entries: []ChannelEntry{
+			{Name: "foo.v0.2.0", Replaces: "foo.v0.1.0", Skips: []string{"foo.v0.1.1", "foo.v0.1.0-rc"}},
+			{Name: "foo.v0.1.0"},
+		}}},
+		Bundles: []Bundle{{Schema: SchemaBundle, Package: "foo", Name: "foo.v0.1.0",
+			Properties: []property.Property{
+				{Type: property.TypeBundleObject, Value: json.RawMessage(`{"data":"Yg=="}`)},
+				{Type: property.TypePackage, Value: json.RawMessage(`{"packageName":"foo","version":"0.1.0"}`)},
+				{Type: property.TypeBundleObject, Value: json.RawMessage(`{"data":"YQ=="}`)},
+				{Type: property.TypeGVK, Value: json.RawMessage(`{"group":"foo.io", "kind":"Foo","version":"v1"}`)},
+				{Type: property.TypeGVK, Value: json.RawMessage(`{"group":"bar.io","kind":"Bar","version":"v1"}`)},
+			},
+			RelatedImages: []RelatedImage{{Name: "b", Image: "img"}, {Name: "operator", Image: "foo"}, {Name: "a", Image: "img"}},
+		}},
+		Deprecations: []Deprecation{{Schema: SchemaDeprecation, Package: "foo", Entries: []DeprecationEntry{
+			{Reference: PackageScopedReference{Schema: SchemaPackage}, Message: "package"},
+			{Reference: PackageScopedReference{Schema: SchemaChannel, Name: "stable"}, Message: "stable"},
+			{Reference: PackageScopedReference{Schema: SchemaBundle, Name: "foo.v0.1.0"}, Message: "bundle"},
+			{Reference: PackageScopedReference{Schema: SchemaChannel, Name: "beta"}, Message: "beta"},
+		}}},
+	}
+
+	Normalize(&cfg)
+
+	require.Equal(t, []property.Property{
+		{Type: "a", Value: json.RawMessage(`"1"`)},
+		{Type: "b", Value: json.RawMessage(`"2"`)},
+	}, cfg.Packages[0].Properties)
+	require.Equal(t, []ChannelEntry{
+		{Name: "foo.v0.1.0"},
+		{Name: "foo.v0.2.0", Replaces: "foo.v0.1.0", Skips: []string{"foo.v0.1.0-rc", "foo.v0.1.1"}},
+	}, cfg.Channels[0].Entries)
+	require.Equal(t, []property.Property{
+		{Type: property.TypeGVK, Value: json.RawMessage(`{"group":"bar.io","kind":"Bar","version":"v1"}`)},
+		{Type: property.TypeGVK, Value: json.RawMessage(`{"group":"foo.io", "kind":"Foo","version":"v1"}`)},
+		{Type: property.TypePackage, Value: json.RawMessage(`{"packageName":"foo","version":"0.1.0"}`)},
+		{Type: property.TypeBundleObject, Value: json.RawMessage(`{"data":"YQ=="}`)},
+		{Type: property.TypeBundleObject, Value: json.RawMessage(`{"data":"Yg=="}`)},
+	}, cfg.Bundles[0].Properties)
+	require.Equal(t, []RelatedImage{{Name: "operator", Image: "foo"}, {Name: "a", Image: "img"}, {Name: "b", Image: "img"}}, cfg.Bundles[0].RelatedImages)
+	var messages []string
+	for _, e := range cfg.Deprecations[0].Entries {
+		messages = append(messages, e.Message)
+	}
+	require.Equal(t, []string{"bundle", "beta", "stable", "package"}, messages)
+}
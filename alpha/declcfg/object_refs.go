@@ -0,0 +1,69 @@
+package declcfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+// resolveBundleObjectRefs replaces the olm.bundle.object properties of the
+// bundle in meta that reference files, relative to the directory of the
+// catalog file at filePath in root, with properties that hold the data of
+// the files, so that the rest of the catalog's consumers only see data.
+func resolveBundleObjectRefs(root fs.FS, filePath string, meta *Meta) error {
+	if meta.Schema != SchemaBundle || !bytes.Contains(meta.Blob, []byte(`"ref"`)) {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(meta.Blob, &fields); err != nil {
+		return fmt.Errorf("parse bundle: %v", err)
+	}
+	var props []property.Property
+	if err := json.Unmarshal(fields["properties"], &props); err != nil {
+		return fmt.Errorf("parse bundle properties: %v", err)
+	}
+
+	resolved := false
+	for i, p := range props {
+		if p.Type != property.TypeBundleObject {
+			continue
+		}
+		var obj property.BundleObject
+		if err := json.Unmarshal(p.Value, &obj); err != nil {
+			return fmt.Errorf("parse property at index %d as bundle object: %v", i, err)
+		}
+		if obj.Ref == "" {
+			continue
+		}
+		refPath := path.Join(path.Dir(filePath), obj.Ref)
+		if path.IsAbs(obj.Ref) || !fs.ValidPath(refPath) {
+			return fmt.Errorf("bundle object reference %q at index %d is not a relative path within the catalog", obj.Ref, i)
+		}
+		data, err := fs.ReadFile(root, refPath)
+		if err != nil {
+			return fmt.Errorf("read bundle object reference %q at index %d: %v", obj.Ref, i, err)
+		}
+		props[i] = property.MustBuildBundleObject(data)
+		resolved = true
+	}
+	if !resolved {
+		return nil
+	}
+
+	propsJSON, err := json.Marshal(props)
+	if err != nil {
+		return err
+	}
+	fields["properties"] = propsJSON
+	blob, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	meta.Blob = blob
+	return nil
+}
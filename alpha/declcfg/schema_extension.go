@@ -0,0 +1,124 @@
+package declcfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// SchemaExtension defines a custom schema of declarative config blobs. The
+// blobs of registered schemas are loaded into DeclarativeConfig.Extensions
+// rather than into DeclarativeConfig.Others, and are validated when the
+// config is converted to a model.
+type SchemaExtension struct {
+	// Schema is the schema of the blobs, such as "example.com.tags".
+	Schema string
+	// Parse, if set, parses a blob when it is loaded. Its result is the
+	// Value of the blob's Extension, and its error fails the load.
+	Parse func(blob json.RawMessage) (interface{}, error)
+	// Validate, if set, validates a blob against the declarative config
+	// that contains it when the config is converted to a model.
+	Validate func(ext Extension, cfg DeclarativeConfig) error
+}
+
+// Extension is a blob of a registered schema extension.
+type Extension struct {
+	Meta
+	// Value is the blob parsed by the Parse function of its schema
+	// extension, or nil if it has none.
+	Value interface{}
+}
+
+var builtinSchemas = map[string]struct{}{
+	SchemaPackage:     {},
+	SchemaChannel:     {},
+	SchemaBundle:      {},
+	SchemaDeprecation: {},
+	SchemaCatalog:     {},
+	SchemaIcon:        {},
+}
+
+var (
+	schemaExtensionsMu sync.RWMutex
+	schemaExtensions   = map[string]SchemaExtension{}
+)
+
+// RegisterSchemaExtension registers ext, typically from an init function. It
+// panics if ext has no schema, or if its schema is built in or already
+// registered.
+func RegisterSchemaExtension(ext SchemaExtension) {
+	if ext.Schema == "" {
+		panic("schema extension must have a schema")
+	}
+	if _, ok := builtinSchemas[ext.Schema]; ok {
+		panic(fmt.Sprintf("schema %q is built in", ext.Schema))
+	}
+	schemaExtensionsMu.Lock()
+	defer schemaExtensionsMu.Unlock()
+	if _, ok := schemaExtensions[ext.Schema]; ok {
+		panic(fmt.Sprintf("schema extension %q already registered", ext.Schema))
+	}
+	schemaExtensions[ext.Schema] = ext
+}
+
+// BuiltinSchemas returns the sorted schemas of the blobs that declarative
+// configs are made of, not counting schema extensions.
+func BuiltinSchemas() []string {
+	schemas := make([]string, 0, len(builtinSchemas))
+	for schema := range builtinSchemas {
+		schemas = append(schemas, schema)
+	}
+	sort.Strings(schemas)
+	return schemas
+}
+
+// RegisteredSchemaExtensions returns the sorted schemas of the registered
+// schema extensions.
+func RegisteredSchemaExtensions() []string {
+	schemaExtensionsMu.RLock()
+	defer schemaExtensionsMu.RUnlock()
+	schemas := make([]string, 0, len(schemaExtensions))
+	for schema := range schemaExtensions {
+		schemas = append(schemas, schema)
+	}
+	sort.Strings(schemas)
+	return schemas
+}
+
+func lookupSchemaExtension(schema string) (SchemaExtension, bool) {
+	schemaExtensionsMu.RLock()
+	defer schemaExtensionsMu.RUnlock()
+	ext, ok := schemaExtensions[schema]
+	return ext, ok
+}
+
+// parseExtension parses meta with the Parse function of ext.
+func parseExtension(ext SchemaExtension, meta Meta) (*Extension, error) {
+	out := &Extension{Meta: meta}
+	if ext.Parse == nil {
+		return out, nil
+	}
+	value, err := ext.Parse(meta.Blob)
+	if err != nil {
+		return nil, err
+	}
+	out.Value = value
+	return out, nil
+}
+
+// validateExtensions validates the extensions of cfg with the Validate
+// functions of their schema extensions. Extensions of schemas that are no
+// longer registered are not validated.
+func validateExtensions(cfg DeclarativeConfig) error {
+	for _, e := range cfg.Extensions {
+		ext, ok := lookupSchemaExtension(e.Schema)
+		if !ok || ext.Validate == nil {
+			continue
+		}
+		if err := ext.Validate(e, cfg); err != nil {
+			return fmt.Errorf("invalid %q blob %q: %v", e.Schema, e.Name, err)
+		}
+	}
+	return nil
+}
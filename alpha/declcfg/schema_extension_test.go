@@ -0,0 +1,210 @@
+package declcfg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testTagsSchema = "example.com.tags"
+
+type testTags struct {
+	Tags []string `json:"tags"`
+}
+
+// registerTestTagsExtension registers testTagsSchema, whose blobs must have
+// at least one tag, for the duration of t.
+func registerTestTagsExtension(t *testing.T) {
+	t.Helper()
+	RegisterSchemaExtension(SchemaExtension{
+		Schema: testTagsSchema,
+		Parse: func(blob json.RawMessage) (interface{}, error) {
+			var tags testTags
+			if err := json.Unmarshal(blob, &tags); err != nil {
+				return nil, err
+			}
+			return tags, nil
+		},
+		Validate: func(ext Extension, _ DeclarativeConfig) error {
+			if len(ext.Value.(testTags).Tags) == 0 {
+				return errors.New("tags must not be empty")
+			}
+			return nil
+		},
+	})
+	t.Cleanup(func() {
+		schemaExtensionsMu.Lock()
+		defer schemaExtensionsMu.Unlock()
+		delete(schemaExtensions, testTagsSchema)
+	})
+}
+
+func newTestTagsExtension(pkg, name string, tags ...string) Extension {
+	blob, _ := json.Marshal(map[string]interface{}{
+		"schema":  testTagsSchema,
+		"package": pkg,
+		"name":    name,
+		"tags":    tags,
+	})
+	return Extension{
+		Meta:  Meta{Schema: testTagsSchema, Package: pkg, Name: name, Blob: blob},
+		Value: testTags{Tags: tags},
+	}
+}
+
+// requireEqualExtensions compares extensions ignoring the formatting of their
+// blobs.
+func requireEqualExtensions(t *testing.T, expected, actual []Extension) {
+	t.Helper()
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		require.Equal(t, expected[i].Schema, actual[i].Schema)
+		require.Equal(t, expected[i].Package, actual[i].Package)
+		require.Equal(t, expected[i].Name, actual[i].Name)
+		require.JSONEq(t, string(expected[i].Blob), string(actual[i].Blob))
+		require.Equal(t, expected[i].Value, actual[i].Value)
+	}
+}
+
+func TestBuiltinSchemas(t *testing.T) {
+	require.Equal(t, []string{SchemaBundle, SchemaCatalog, SchemaChannel, SchemaDeprecation, SchemaIcon, SchemaPackage}, BuiltinSchemas())
+}
+
+func TestRegisterSchemaExtension(t *testing.T) {
+	registerTestTagsExtension(t)
+	require.Equal(t, []string{testTagsSchema}, RegisteredSchemaExtensions())
+
+	require.PanicsWithValue(t, "schema extension must have a schema", func() {
+		RegisterSchemaExtension(SchemaExtension{})
+	})
+	require.PanicsWithValue(t, `schema "olm.bundle" is built in`, func() {
+		RegisterSchemaExtension(SchemaExtension{Schema: SchemaBundle})
+	})
+	require.PanicsWithValue(t, `schema extension "example.com.tags" already registered`, func() {
+		RegisterSchemaExtension(SchemaExtension{Schema: testTagsSchema})
+	})
+}
+
+func TestLoadReaderSchemaExtension(t *testing.T) {
+	registerTestTagsExtension(t)
+
+	const input = `---
+name: foo-tags
+package: foo
+schema: example.com.tags
+tags:
+- a
+- b
+---
+schema: example.com.unregistered
+`
+	cfg, err := LoadReader(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, cfg.Extensions, 1)
+	require.Equal(t, testTagsSchema, cfg.Extensions[0].Schema)
+	require.Equal(t, "foo", cfg.Extensions[0].Package)
+	require.Equal(t, "foo-tags", cfg.Extensions[0].Name)
+	require.Equal(t, testTags{Tags: []string{"a", "b"}}, cfg.Extensions[0].Value)
+	require.Len(t, cfg.Others, 1)
+	require.Equal(t, "example.com.unregistered", cfg.Others[0].Schema)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteYAML(*cfg, &buf))
+	require.Equal(t, input, buf.String())
+
+	_, err = LoadReader(strings.NewReader(`{"schema": "example.com.tags", "tags": "a"}`))
+	require.ErrorContains(t, err, `parse example.com.tags: json: cannot unmarshal string`)
+}
+
+func TestWriteFSSchemaExtension(t *testing.T) {
+	registerTestTagsExtension(t)
+
+	cfg := buildValidDeclarativeConfig(validDeclarativeConfigSpec{})
+	cfg.Extensions = []Extension{
+		newTestTagsExtension("anakin", "anakin-tags", "a"),
+		newTestTagsExtension("", "global-tags", "b"),
+	}
+
+	rootDir := t.TempDir()
+	require.NoError(t, WriteFS(cfg, rootDir, WriteJSON, ".json"))
+
+	rootCfg, err := LoadFile(os.DirFS(rootDir), "catalog.json")
+	require.NoError(t, err)
+	requireEqualExtensions(t, cfg.Extensions[1:], rootCfg.Extensions)
+
+	pkgCfg, err := LoadFile(os.DirFS(rootDir), "anakin/catalog.json")
+	require.NoError(t, err)
+	requireEqualExtensions(t, cfg.Extensions[:1], pkgCfg.Extensions)
+
+	actual, err := LoadFS(context.Background(), os.DirFS(rootDir))
+	require.NoError(t, err)
+	sortExtensions(actual.Extensions)
+	requireEqualExtensions(t, []Extension{cfg.Extensions[0], cfg.Extensions[1]}, actual.Extensions)
+	require.Empty(t, actual.Others)
+}
+
+func TestConvertToModelSchemaExtension(t *testing.T) {
+	registerTestTagsExtension(t)
+
+	type spec struct {
+		name      string
+		ext       Extension
+		assertion require.ErrorAssertionFunc
+	}
+	for _, s := range []spec{
+		{
+			name:      "Success/PackageScoped",
+			ext:       newTestTagsExtension("anakin", "anakin-tags", "a"),
+			assertion: require.NoError,
+		},
+		{
+			name:      "Success/Global",
+			ext:       newTestTagsExtension("", "global-tags", "a"),
+			assertion: require.NoError,
+		},
+		{
+			name: "Error/InvalidBlob",
+			ext:  newTestTagsExtension("anakin", "anakin-tags"),
+			assertion: func(t require.TestingT, err error, _ ...interface{}) {
+				require.EqualError(t, err, `invalid "example.com.tags" blob "anakin-tags": tags must not be empty`)
+			},
+		},
+		{
+			name: "Error/UnknownPackage",
+			ext:  newTestTagsExtension("yoda", "yoda-tags", "a"),
+			assertion: func(t require.TestingT, err error, _ ...interface{}) {
+				require.EqualError(t, err, `unknown package "yoda" for example.com.tags blob`)
+			},
+		},
+	} {
+		t.Run(s.name, func(t *testing.T) {
+			cfg := buildValidDeclarativeConfig(validDeclarativeConfigSpec{})
+			cfg.Extensions = []Extension{s.ext}
+			_, err := ConvertToModel(cfg)
+			s.assertion(t, err)
+		})
+	}
+}
+
+func TestConvertFromModelSchemaExtension(t *testing.T) {
+	registerTestTagsExtension(t)
+
+	cfg := buildValidDeclarativeConfig(validDeclarativeConfigSpec{})
+	cfg.Extensions = []Extension{
+		newTestTagsExtension("boba-fett", "boba-fett-tags", "b"),
+		newTestTagsExtension("anakin", "anakin-tags", "a"),
+	}
+	m, err := ConvertToModel(cfg)
+	require.NoError(t, err)
+	require.Len(t, m["anakin"].Extensions, 1)
+	require.Equal(t, testTags{Tags: []string{"a"}}, m["anakin"].Extensions[0].Value)
+
+	actual := ConvertFromModel(m)
+	require.Equal(t, []Extension{cfg.Extensions[1], cfg.Extensions[0]}, actual.Extensions)
+}
@@ -2,6 +2,7 @@ package declcfg
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/blang/semver/v4"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -363,6 +365,61 @@ func WriteYAML(cfg DeclarativeConfig, w io.Writer) error {
 	return writeToEncoder(cfg, enc)
 }
 
+// WriteYAMLCanonical writes cfg as YAML documents in a canonical form, so
+// that catalogs kept in version control only show changes to their content.
+// Keys are sorted, and the blobs and their lists are written in the order
+// that Normalize and NormalizedCopy give them. cfg is not modified.
+func WriteYAMLCanonical(cfg DeclarativeConfig, w io.Writer) error {
+	return WriteYAML(NormalizedCopy(cfg), w)
+}
+
+// WriteTable writes a table that summarizes the packages of cfg, with one
+// row per channel, sorted by package and channel name. The head of a channel
+// is the entry that no other entry replaces or skips; channels with several
+// such entries list all of them.
+func WriteTable(cfg DeclarativeConfig, w io.Writer) error {
+	defaultChannels := map[string]string{}
+	for _, p := range cfg.Packages {
+		defaultChannels[p.Name] = p.DefaultChannel
+	}
+	channels := append([]Channel(nil), cfg.Channels...)
+	sort.Slice(channels, func(i, j int) bool {
+		if channels[i].Package != channels[j].Package {
+			return channels[i].Package < channels[j].Package
+		}
+		return channels[i].Name < channels[j].Name
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "PACKAGE\tCHANNEL\tDEFAULT\tHEAD\tENTRIES"); err != nil {
+		return err
+	}
+	for _, c := range channels {
+		isDefault := defaultChannels[c.Package] == c.Name
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%t\t%s\t%d\n", c.Package, c.Name, isDefault, strings.Join(channelHeads(c), ","), len(c.Entries)); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// channelHeads returns the sorted names of the entries of c that no other
+// entry replaces or skips.
+func channelHeads(c Channel) []string {
+	replaced := sets.NewString()
+	for _, e := range c.Entries {
+		replaced.Insert(e.Replaces)
+		replaced.Insert(e.Skips...)
+	}
+	heads := sets.NewString()
+	for _, e := range c.Entries {
+		if !replaced.Has(e.Name) {
+			heads.Insert(e.Name)
+		}
+	}
+	return heads.List()
+}
+
 type yamlEncoder struct {
 	w          io.Writer
 	escapeHTML bool
@@ -423,12 +480,30 @@ func writeToEncoder(cfg DeclarativeConfig, enc encoder) error {
 		pkgNames.Insert(pkgName)
 		othersByPackage[pkgName] = append(othersByPackage[pkgName], o)
 	}
+	extensionsByPackage := map[string][]Extension{}
+	for _, e := range cfg.Extensions {
+		pkgName := e.Package
+		pkgNames.Insert(pkgName)
+		extensionsByPackage[pkgName] = append(extensionsByPackage[pkgName], e)
+	}
 	deprecationsByPackage := map[string][]Deprecation{}
 	for _, d := range cfg.Deprecations {
 		pkgName := d.Package
 		pkgNames.Insert(pkgName)
 		deprecationsByPackage[pkgName] = append(deprecationsByPackage[pkgName], d)
 	}
+	iconsByPackage := map[string][]PackageIcon{}
+	for _, i := range cfg.Icons {
+		pkgName := i.Package
+		pkgNames.Insert(pkgName)
+		iconsByPackage[pkgName] = append(iconsByPackage[pkgName], i)
+	}
+
+	for _, c := range cfg.Catalogs {
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+	}
 
 	for _, pName := range pkgNames.List() {
 		if len(pName) == 0 {
@@ -441,6 +516,12 @@ func writeToEncoder(cfg DeclarativeConfig, enc encoder) error {
 			}
 		}
 
+		for _, i := range iconsByPackage[pName] {
+			if err := enc.Encode(i); err != nil {
+				return err
+			}
+		}
+
 		channels := channelsByPackage[pName]
 		sort.Slice(channels, func(i, j int) bool {
 			return channels[i].Name < channels[j].Name
@@ -461,6 +542,14 @@ func writeToEncoder(cfg DeclarativeConfig, enc encoder) error {
 			}
 		}
 
+		extensions := extensionsByPackage[pName]
+		sortExtensions(extensions)
+		for _, e := range extensions {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+
 		others := othersByPackage[pName]
 		sort.SliceStable(others, func(i, j int) bool {
 			return others[i].Schema < others[j].Schema
@@ -489,6 +578,14 @@ func writeToEncoder(cfg DeclarativeConfig, enc encoder) error {
 		}
 	}
 
+	extensions := extensionsByPackage[""]
+	sortExtensions(extensions)
+	for _, e := range extensions {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+
 	for _, o := range othersByPackage[""] {
 		if err := enc.Encode(o); err != nil {
 			return err
@@ -498,9 +595,43 @@ func writeToEncoder(cfg DeclarativeConfig, enc encoder) error {
 	return nil
 }
 
+// sortExtensions sorts extensions by schema and name.
+func sortExtensions(extensions []Extension) {
+	sort.SliceStable(extensions, func(i, j int) bool {
+		if extensions[i].Schema != extensions[j].Schema {
+			return extensions[i].Schema < extensions[j].Schema
+		}
+		return extensions[i].Name < extensions[j].Name
+	})
+}
+
 type WriteFunc func(config DeclarativeConfig, w io.Writer) error
 
-func WriteFS(cfg DeclarativeConfig, rootDir string, writeFunc WriteFunc, fileExt string) error {
+// WriteGzip returns a WriteFunc that compresses the output of writeFunc with
+// gzip, for files with the GzipExt extension.
+func WriteGzip(writeFunc WriteFunc) WriteFunc {
+	return func(cfg DeclarativeConfig, w io.Writer) error {
+		zw := gzip.NewWriter(w)
+		if err := writeFunc(cfg, zw); err != nil {
+			return err
+		}
+		return zw.Close()
+	}
+}
+
+// WriteFS writes cfg to rootDir, with the blobs of each package in a file in a
+// directory named after the package. If fileExt ends with GzipExt, like
+// ".json.gz", the files are compressed. The files are named "catalog", unless
+// WithFileName is given, and the bundles of large packages can be split across
+// several files of their package's directory with WithBundlesPerFile and
+// WithBundleShards. Since the blobs of a catalog are grouped by package when
+// it is loaded, the files can be loaded like any other catalog.
+func WriteFS(cfg DeclarativeConfig, rootDir string, writeFunc WriteFunc, fileExt string, opts ...WriteFSOption) error {
+	options := WriteFSOptions{fileName: "catalog"}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	channelsByPackage := map[string][]Channel{}
 	for _, c := range cfg.Channels {
 		channelsByPackage[c.Package] = append(channelsByPackage[c.Package], c)
@@ -509,31 +640,80 @@ func WriteFS(cfg DeclarativeConfig, rootDir string, writeFunc WriteFunc, fileExt
 	for _, b := range cfg.Bundles {
 		bundlesByPackage[b.Package] = append(bundlesByPackage[b.Package], b)
 	}
+	deprecationsByPackage := map[string][]Deprecation{}
+	for _, d := range cfg.Deprecations {
+		deprecationsByPackage[d.Package] = append(deprecationsByPackage[d.Package], d)
+	}
+	iconsByPackage := map[string][]PackageIcon{}
+	for _, i := range cfg.Icons {
+		iconsByPackage[i.Package] = append(iconsByPackage[i.Package], i)
+	}
+	othersByPackage := map[string][]Meta{}
+	for _, o := range cfg.Others {
+		othersByPackage[o.Package] = append(othersByPackage[o.Package], o)
+	}
+	extensionsByPackage := map[string][]Extension{}
+	for _, e := range cfg.Extensions {
+		extensionsByPackage[e.Package] = append(extensionsByPackage[e.Package], e)
+	}
 
 	if err := os.MkdirAll(rootDir, 0777); err != nil {
 		return err
 	}
 
+	// Blobs that do not belong to a package are written to a file at the
+	// root of the catalog.
+	if len(cfg.Catalogs) > 0 || len(extensionsByPackage[""]) > 0 || len(othersByPackage[""]) > 0 {
+		rootCfg := DeclarativeConfig{
+			Catalogs:   cfg.Catalogs,
+			Extensions: extensionsByPackage[""],
+			Others:     othersByPackage[""],
+		}
+		filename := filepath.Join(rootDir, options.fileName+fileExt)
+		if err := writeFile(rootCfg, filename, writeFunc); err != nil {
+			return err
+		}
+	}
+
 	for _, p := range cfg.Packages {
+		shards, err := options.shardBundles(bundlesByPackage[p.Name])
+		if err != nil {
+			return fmt.Errorf("shard bundles of package %q: %v", p.Name, err)
+		}
 		fcfg := DeclarativeConfig{
-			Packages: []Package{p},
-			Channels: channelsByPackage[p.Name],
-			Bundles:  bundlesByPackage[p.Name],
+			Packages:     []Package{p},
+			Channels:     channelsByPackage[p.Name],
+			Deprecations: deprecationsByPackage[p.Name],
+			Icons:        iconsByPackage[p.Name],
+			Extensions:   extensionsByPackage[p.Name],
+			Others:       othersByPackage[p.Name],
+		}
+		if shards == nil {
+			fcfg.Bundles = bundlesByPackage[p.Name]
 		}
 		pkgDir := filepath.Join(rootDir, p.Name)
 		if err := os.MkdirAll(pkgDir, 0777); err != nil {
 			return err
 		}
-		filename := filepath.Join(pkgDir, fmt.Sprintf("catalog%s", fileExt))
+		filename := filepath.Join(pkgDir, options.fileName+fileExt)
 		if err := writeFile(fcfg, filename, writeFunc); err != nil {
 			return err
 		}
+		for _, shard := range shards {
+			filename := filepath.Join(pkgDir, fmt.Sprintf("%s-bundles-%s%s", options.fileName, shard.name, fileExt))
+			if err := writeFile(DeclarativeConfig{Bundles: shard.bundles}, filename, writeFunc); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
 func writeFile(cfg DeclarativeConfig, filename string, writeFunc WriteFunc) error {
 	buf := &bytes.Buffer{}
+	if strings.HasSuffix(filename, GzipExt) {
+		writeFunc = WriteGzip(writeFunc)
+	}
 	if err := writeFunc(cfg, buf); err != nil {
 		return fmt.Errorf("write to buffer for %q: %v", filename, err)
 	}
@@ -0,0 +1,137 @@
+package declcfg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+)
+
+// WriteFSOptions configure the layout of the files written by WriteFS.
+type WriteFSOptions struct {
+	fileName       string
+	bundlesPerFile int
+	bundleShard    BundleShardFunc
+}
+
+type WriteFSOption func(*WriteFSOptions)
+
+// WithFileName sets the name, without extension, of the files that WriteFS
+// writes. It defaults to "catalog".
+func WithFileName(name string) WriteFSOption {
+	return func(opts *WriteFSOptions) {
+		opts.fileName = name
+	}
+}
+
+// WithBundlesPerFile splits the bundles of each package, ordered by version,
+// into files of at most n bundles, named after the file of the package with
+// a "-bundles-<N>" suffix. With WithBundleShards, each shard is split.
+func WithBundlesPerFile(n int) WriteFSOption {
+	return func(opts *WriteFSOptions) {
+		opts.bundlesPerFile = n
+	}
+}
+
+// WithBundleShards writes the bundles of each package to one file per shard
+// that shard returns for them, named after the file of the package with a
+// "-bundles-<shard>" suffix.
+func WithBundleShards(shard BundleShardFunc) WriteFSOption {
+	return func(opts *WriteFSOptions) {
+		opts.bundleShard = shard
+	}
+}
+
+// BundleShardFunc returns the name of the shard of a bundle. Shard names are
+// used in file names, so they must not be empty or contain path separators.
+type BundleShardFunc func(b Bundle) (string, error)
+
+// ShardByMajorVersion shards bundles by the major version of their package,
+// as "v<major>".
+func ShardByMajorVersion(b Bundle) (string, error) {
+	v, err := parseVersionProperty(&b)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("v%d", v.Major), nil
+}
+
+// ShardByMinorVersion shards bundles by the major and minor versions of their
+// package, as "v<major>.<minor>".
+func ShardByMinorVersion(b Bundle) (string, error) {
+	v, err := parseVersionProperty(&b)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("v%d.%d", v.Major, v.Minor), nil
+}
+
+type bundleShard struct {
+	name    string
+	bundles []Bundle
+}
+
+// shardBundles returns the shards of the bundles of a package, in version
+// order, or nil if the bundles are written with their package.
+func (o WriteFSOptions) shardBundles(bundles []Bundle) ([]bundleShard, error) {
+	if o.bundleShard == nil && o.bundlesPerFile <= 0 {
+		return nil, nil
+	}
+
+	versions := make(map[string]semver.Version, len(bundles))
+	for i := range bundles {
+		v, err := parseVersionProperty(&bundles[i])
+		if err != nil {
+			return nil, err
+		}
+		versions[bundles[i].Name] = *v
+	}
+	sorted := append([]Bundle(nil), bundles...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if c := versions[sorted[i].Name].Compare(versions[sorted[j].Name]); c != 0 {
+			return c < 0
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	shards := []bundleShard{{bundles: sorted}}
+	if o.bundleShard != nil {
+		shards = nil
+		index := map[string]int{}
+		for _, b := range sorted {
+			name, err := o.bundleShard(b)
+			if err != nil {
+				return nil, fmt.Errorf("shard bundle %q: %v", b.Name, err)
+			}
+			if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+				return nil, fmt.Errorf("bundle %q has invalid shard name %q", b.Name, name)
+			}
+			i, ok := index[name]
+			if !ok {
+				i = len(shards)
+				index[name] = i
+				shards = append(shards, bundleShard{name: name})
+			}
+			shards[i].bundles = append(shards[i].bundles, b)
+		}
+	}
+
+	if o.bundlesPerFile <= 0 {
+		return shards, nil
+	}
+	var split []bundleShard
+	for _, s := range shards {
+		n := (len(s.bundles) + o.bundlesPerFile - 1) / o.bundlesPerFile
+		width := len(fmt.Sprint(n))
+		for i := 0; i < n; i++ {
+			name := fmt.Sprintf("%0*d", width, i+1)
+			if s.name != "" {
+				name = s.name + "-" + name
+			}
+			end := min((i+1)*o.bundlesPerFile, len(s.bundles))
+			split = append(split, bundleShard{name: name, bundles: s.bundles[i*o.bundlesPerFile : end]})
+		}
+	}
+	return split, nil
+}
@@ -2,7 +2,14 @@ package declcfg
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -496,6 +503,200 @@ schema: custom.2
 	}
 }
 
+func TestWriteYAMLCanonical(t *testing.T) {
+	cfg := buildValidDeclarativeConfig(validDeclarativeConfigSpec{IncludeUnrecognized: true, IncludeDeprecations: true})
+	cfg.Catalogs = []Catalog{{Schema: SchemaCatalog, Name: "b"}, {Schema: SchemaCatalog, Name: "a"}}
+	var expected bytes.Buffer
+	require.NoError(t, WriteYAMLCanonical(cfg, &expected))
+
+	// Reordering the lists whose order has no meaning does not change the
+	// output, and the written config is left as is.
+	shuffled := NormalizedCopy(cfg)
+	slices.Reverse(shuffled.Catalogs)
+	slices.Reverse(shuffled.Others)
+	for i := range shuffled.Channels {
+		slices.Reverse(shuffled.Channels[i].Entries)
+	}
+	for i := range shuffled.Bundles {
+		slices.Reverse(shuffled.Bundles[i].Properties)
+		slices.Reverse(shuffled.Bundles[i].RelatedImages)
+	}
+	for i := range shuffled.Deprecations {
+		slices.Reverse(shuffled.Deprecations[i].Entries)
+	}
+	properties := slices.Clone(shuffled.Bundles[0].Properties)
+
+	var actual bytes.Buffer
+	require.NoError(t, WriteYAMLCanonical(shuffled, &actual))
+	require.Equal(t, expected.String(), actual.String())
+	require.Equal(t, properties, shuffled.Bundles[0].Properties)
+
+	actualCfg, err := LoadReader(&actual)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, []string{actualCfg.Catalogs[0].Name, actualCfg.Catalogs[1].Name})
+	require.Len(t, actualCfg.Bundles, len(cfg.Bundles))
+}
+
+func TestWriteFS(t *testing.T) {
+	cfg := buildValidDeclarativeConfig(validDeclarativeConfigSpec{IncludeUnrecognized: true, IncludeDeprecations: true})
+	cfg.Catalogs = []Catalog{{Schema: SchemaCatalog, Name: "test-catalog"}}
+
+	rootDir := t.TempDir()
+	require.NoError(t, WriteFS(cfg, rootDir, WriteJSON, ".json"))
+
+	// Each package is written to its own directory, and blobs that do not
+	// belong to a package are written to the root of the catalog.
+	var files []string
+	require.NoError(t, fs.WalkDir(os.DirFS(rootDir), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		files = append(files, path)
+		return nil
+	}))
+	require.ElementsMatch(t, []string{"catalog.json", "anakin/catalog.json", "boba-fett/catalog.json"}, files)
+
+	rootCfg, err := LoadFile(os.DirFS(rootDir), "catalog.json")
+	require.NoError(t, err)
+	require.Equal(t, cfg.Catalogs, rootCfg.Catalogs)
+	require.Len(t, rootCfg.Others, 2)
+	require.Empty(t, rootCfg.Packages)
+
+	actual, err := LoadFS(context.Background(), os.DirFS(rootDir))
+	require.NoError(t, err)
+	removeJSONWhitespace(&cfg)
+	removeJSONWhitespace(actual)
+	require.ElementsMatch(t, cfg.Packages, actual.Packages)
+	require.ElementsMatch(t, cfg.Channels, actual.Channels)
+	require.Len(t, actual.Bundles, len(cfg.Bundles))
+	require.ElementsMatch(t, cfg.Deprecations, actual.Deprecations)
+	require.ElementsMatch(t, cfg.Catalogs, actual.Catalogs)
+	require.ElementsMatch(t, cfg.Others, actual.Others)
+}
+
+func TestWriteFSShards(t *testing.T) {
+	type spec struct {
+		name     string
+		opts     []WriteFSOption
+		expected []string
+	}
+	specs := []spec{
+		{
+			name: "FileName",
+			opts: []WriteFSOption{WithFileName("index")},
+			expected: []string{
+				"index.json", "anakin/index.json", "boba-fett/index.json",
+			},
+		},
+		{
+			name: "BundlesPerFile",
+			opts: []WriteFSOption{WithBundlesPerFile(2)},
+			expected: []string{
+				"catalog.json",
+				"anakin/catalog.json", "anakin/catalog-bundles-1.json", "anakin/catalog-bundles-2.json",
+				"boba-fett/catalog.json", "boba-fett/catalog-bundles-1.json",
+			},
+		},
+		{
+			name: "MinorVersion",
+			opts: []WriteFSOption{WithBundleShards(ShardByMinorVersion)},
+			expected: []string{
+				"catalog.json",
+				"anakin/catalog.json", "anakin/catalog-bundles-v0.0.json", "anakin/catalog-bundles-v0.1.json",
+				"boba-fett/catalog.json", "boba-fett/catalog-bundles-v1.0.json", "boba-fett/catalog-bundles-v2.0.json",
+			},
+		},
+		{
+			name: "MajorVersionAndBundlesPerFile",
+			opts: []WriteFSOption{WithBundleShards(ShardByMajorVersion), WithBundlesPerFile(2)},
+			expected: []string{
+				"catalog.json",
+				"anakin/catalog.json", "anakin/catalog-bundles-v0-1.json", "anakin/catalog-bundles-v0-2.json",
+				"boba-fett/catalog.json", "boba-fett/catalog-bundles-v1-1.json", "boba-fett/catalog-bundles-v2-1.json",
+			},
+		},
+	}
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			cfg := buildValidDeclarativeConfig(validDeclarativeConfigSpec{IncludeUnrecognized: true, IncludeDeprecations: true})
+			rootDir := t.TempDir()
+			require.NoError(t, WriteFS(cfg, rootDir, WriteJSON, ".json", s.opts...))
+
+			var files []string
+			require.NoError(t, fs.WalkDir(os.DirFS(rootDir), ".", func(path string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				files = append(files, path)
+				return nil
+			}))
+			require.ElementsMatch(t, s.expected, files)
+
+			actual, err := LoadFS(context.Background(), os.DirFS(rootDir))
+			require.NoError(t, err)
+			removeJSONWhitespace(&cfg)
+			removeJSONWhitespace(actual)
+			require.ElementsMatch(t, cfg.Packages, actual.Packages)
+			require.ElementsMatch(t, cfg.Channels, actual.Channels)
+			require.ElementsMatch(t, bundleNames(cfg.Bundles), bundleNames(actual.Bundles))
+			require.ElementsMatch(t, cfg.Others, actual.Others)
+
+			_, err = ConvertToModel(*actual)
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("InvalidShardName", func(t *testing.T) {
+		cfg := buildValidDeclarativeConfig(validDeclarativeConfigSpec{})
+		shard := func(Bundle) (string, error) { return "../v1", nil }
+		require.ErrorContains(t, WriteFS(cfg, t.TempDir(), WriteJSON, ".json", WithBundleShards(shard)), `invalid shard name "../v1"`)
+	})
+}
+
+func bundleNames(bundles []Bundle) []string {
+	names := make([]string, 0, len(bundles))
+	for _, b := range bundles {
+		names = append(names, b.Name)
+	}
+	return names
+}
+
+func TestWriteFSGzip(t *testing.T) {
+	cfg := buildValidDeclarativeConfig(validDeclarativeConfigSpec{IncludeUnrecognized: true, IncludeDeprecations: true})
+
+	for _, ext := range []string{".json.gz", ".yaml.gz"} {
+		t.Run(ext, func(t *testing.T) {
+			writeFunc := WriteJSON
+			if ext == ".yaml.gz" {
+				writeFunc = WriteYAML
+			}
+			rootDir := t.TempDir()
+			require.NoError(t, WriteFS(cfg, rootDir, writeFunc, ext))
+
+			data, err := os.ReadFile(filepath.Join(rootDir, "anakin", "catalog"+ext))
+			require.NoError(t, err)
+			zr, err := gzip.NewReader(bytes.NewReader(data))
+			require.NoError(t, err)
+			_, err = io.ReadAll(zr)
+			require.NoError(t, err)
+
+			expected := cfg
+			actual, err := LoadFS(context.Background(), os.DirFS(rootDir))
+			require.NoError(t, err)
+			removeJSONWhitespace(&expected)
+			removeJSONWhitespace(actual)
+			require.ElementsMatch(t, expected.Packages, actual.Packages)
+			require.ElementsMatch(t, expected.Channels, actual.Channels)
+			require.Len(t, actual.Bundles, len(expected.Bundles))
+			require.ElementsMatch(t, expected.Others, actual.Others)
+
+			fileCfg, err := LoadFile(os.DirFS(rootDir), "anakin/catalog"+ext)
+			require.NoError(t, err)
+			require.Len(t, fileCfg.Packages, 1)
+		})
+	}
+}
+
 func removeJSONWhitespace(cfg *DeclarativeConfig) {
 	for ib := range cfg.Bundles {
 		for ip := range cfg.Bundles[ib].Properties {
@@ -511,6 +712,28 @@ func removeJSONWhitespace(cfg *DeclarativeConfig) {
 	}
 }
 
+func TestWriteTable(t *testing.T) {
+	cfg := buildValidDeclarativeConfig(validDeclarativeConfigSpec{IncludeUnrecognized: true, IncludeDeprecations: true})
+	cfg.Channels = append(cfg.Channels, Channel{
+		Schema:  SchemaChannel,
+		Package: "boba-fett",
+		Name:    "forked",
+		Entries: []ChannelEntry{
+			{Name: "boba-fett.v1.0.0"},
+			{Name: "boba-fett.v2.0.0"},
+		},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTable(cfg, &buf))
+	require.Equal(t, `PACKAGE    CHANNEL  DEFAULT  HEAD                               ENTRIES
+anakin     dark     true     anakin.v0.1.1                      3
+anakin     light    false    anakin.v0.1.0                      2
+boba-fett  forked   false    boba-fett.v1.0.0,boba-fett.v2.0.0  2
+boba-fett  mando    true     boba-fett.v2.0.0                   2
+`, buf.String())
+}
+
 func TestWriteMermaidChannels(t *testing.T) {
 	type spec struct {
 		name          string
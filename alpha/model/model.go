@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
@@ -50,6 +51,12 @@ type Package struct {
 	DefaultChannel *Channel
 	Channels       map[string]*Channel
 	Deprecation    *Deprecation
+	// Properties are the properties of the package, which are opaque to
+	// the model and served with it.
+	Properties []property.Property
+	// Extensions are the blobs of the package's registered declarative
+	// config schema extensions.
+	Extensions []Extension
 }
 
 func (m *Package) Validate() error {
@@ -91,6 +98,10 @@ func (m *Package) Validate() error {
 		result.subErrors = append(result.subErrors, err)
 	}
 
+	if err := m.validateUniqueChannelPriorities(); err != nil {
+		result.subErrors = append(result.subErrors, err)
+	}
+
 	if m.DefaultChannel != nil && !foundDefault {
 		result.subErrors = append(result.subErrors, fmt.Errorf("default channel %q not found in channels list", m.DefaultChannel.Name))
 	}
@@ -132,11 +143,51 @@ func (m *Package) validateUniqueBundleVersions() error {
 	return nil
 }
 
+// validateUniqueChannelPriorities checks that the channels that set a priority
+// don't share it, so that the order of the channels doesn't depend on their
+// names.
+func (m *Package) validateUniqueChannelPriorities() error {
+	channelsWithPriority := map[int][]string{}
+	for _, ch := range m.Channels {
+		if ch.Priority == 0 {
+			continue
+		}
+		channelsWithPriority[ch.Priority] = append(channelsWithPriority[ch.Priority], ch.Name)
+	}
+
+	priorities := maps.Keys(channelsWithPriority)
+	sort.Ints(priorities)
+
+	var errs []error
+	for _, p := range priorities {
+		channels := channelsWithPriority[p]
+		if len(channels) > 1 {
+			sort.Strings(channels)
+			errs = append(errs, fmt.Errorf("{%d: [%s]}", p, strings.Join(channels, ", ")))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("duplicate priorities found in channels: %v", errs)
+	}
+	return nil
+}
+
 type Icon struct {
 	Data      []byte `json:"base64data"`
 	MediaType string `json:"mediatype"`
 }
 
+// Extension is a blob of a registered declarative config schema extension.
+type Extension struct {
+	Schema string
+	Name   string
+	Blob   json.RawMessage
+	// Value is the blob as parsed by its schema extension, if it has a
+	// parser.
+	Value interface{}
+}
+
 func (i *Icon) Validate() error {
 	if i == nil {
 		return nil
@@ -179,11 +230,11 @@ func (i *Icon) validateData() error {
 type Channel struct {
 	Package     *Package
 	Name        string
+	Priority    int
 	Bundles     map[string]*Bundle
 	Deprecation *Deprecation
-	// NOTICE: The field Properties of the type Channel is for internal use only.
-	//   DO NOT use it for any public-facing functionalities.
-	//   This API is in alpha stage and it is subject to change.
+	// Properties are the properties of the channel, which are opaque to
+	// the model and served with it.
 	Properties []property.Property
 }
 
@@ -232,6 +283,10 @@ func (c *Channel) Validate() error {
 		result.subErrors = append(result.subErrors, errors.New("package must be set"))
 	}
 
+	if c.Priority < 0 {
+		result.subErrors = append(result.subErrors, fmt.Errorf("priority %d must not be negative", c.Priority))
+	}
+
 	if len(c.Bundles) == 0 {
 		result.subErrors = append(result.subErrors, fmt.Errorf("channel must contain at least one bundle"))
 	}
@@ -366,6 +421,45 @@ func (b *Bundle) Validate() error {
 	if props != nil && len(props.Packages) != 1 {
 		result.subErrors = append(result.subErrors, fmt.Errorf("must be exactly one property with type %q", property.TypePackage))
 	}
+	if props != nil {
+		for i, c := range props.Constraints {
+			if err := property.ValidateConstraint(c); err != nil {
+				result.subErrors = append(result.subErrors, fmt.Errorf("invalid %s property %d: %v", property.TypeConstraint, i, err))
+			}
+		}
+		if len(props.MinKubeVersions) > 1 {
+			result.subErrors = append(result.subErrors, fmt.Errorf("must be at most one property with type %q", property.TypeMinKubeVersion))
+		}
+		for _, v := range props.MinKubeVersions {
+			if err := v.Validate(); err != nil {
+				result.subErrors = append(result.subErrors, fmt.Errorf("invalid %s property %q: %v", property.TypeMinKubeVersion, v, err))
+			}
+		}
+		if len(props.MaxOpenShiftVersions) > 1 {
+			result.subErrors = append(result.subErrors, fmt.Errorf("must be at most one property with type %q", property.TypeMaxOpenShiftVersion))
+		}
+		for _, v := range props.MaxOpenShiftVersions {
+			if err := v.Validate(); err != nil {
+				result.subErrors = append(result.subErrors, fmt.Errorf("invalid %s property %q: %v", property.TypeMaxOpenShiftVersion, v, err))
+			}
+		}
+		if len(props.BundleMediaTypes) > 1 {
+			result.subErrors = append(result.subErrors, fmt.Errorf("must be at most one property with type %q", property.TypeBundleMediaType))
+		}
+		isHelmChart := false
+		for _, v := range props.BundleMediaTypes {
+			if err := v.Validate(); err != nil {
+				result.subErrors = append(result.subErrors, fmt.Errorf("invalid %s property %q: %v", property.TypeBundleMediaType, v, err))
+			}
+			isHelmChart = isHelmChart || v == property.MediaTypeHelmV3
+		}
+		switch {
+		case isHelmChart && len(props.HelmCharts) != 1:
+			result.subErrors = append(result.subErrors, fmt.Errorf("must be exactly one property with type %q for %s bundles", property.TypeHelmChart, property.MediaTypeHelmV3))
+		case !isHelmChart && len(props.HelmCharts) > 0:
+			result.subErrors = append(result.subErrors, fmt.Errorf("property with type %q is only valid for %s bundles", property.TypeHelmChart, property.MediaTypeHelmV3))
+		}
+	}
 
 	if b.Image == "" && len(b.Objects) == 0 {
 		result.subErrors = append(result.subErrors, errors.New("bundle image must be set"))
@@ -397,6 +491,11 @@ func (m Model) Normalize() {
 			for _, b := range ch.Bundles {
 				for i := range b.Properties {
 					// Ensure property value is encoded in a standard way.
+					// Values of unknown types are passed through as is, so
+					// that experimental properties are served unchanged.
+					if !property.IsRegisteredType(b.Properties[i].Type) {
+						continue
+					}
 					if normalized, err := property.Build(&b.Properties[i]); err == nil {
 						b.Properties[i] = *normalized
 					}
@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/operator-framework/api/pkg/constraints"
+
 	"github.com/operator-framework/operator-registry/alpha/property"
 )
 
@@ -58,14 +60,21 @@ func TestNormalize(t *testing.T) {
 
 	t.Run("Success/RemoveSpaces", func(t *testing.T) {
 		withWhitespace := json.RawMessage(`  {  
-  "foo": "bar"   
+  "packageName": "foo"   
   
   }  `)
-		expected := json.RawMessage(`{"foo":"bar"}`)
-		b.Properties = []property.Property{{Value: withWhitespace}}
+		expected := json.RawMessage(`{"packageName":"foo"}`)
+		b.Properties = []property.Property{{Type: property.TypePackage, Value: withWhitespace}}
 		pkgs.Normalize()
 		assert.Equal(t, expected, b.Properties[0].Value)
 	})
+
+	t.Run("Success/UnknownTypeUnchanged", func(t *testing.T) {
+		unknown := json.RawMessage(`  { "foo": "<bar>" }  `)
+		b.Properties = []property.Property{{Type: "example.com/experimental", Value: unknown}}
+		pkgs.Normalize()
+		assert.Equal(t, unknown, b.Properties[0].Value)
+	})
 }
 
 func TestChannelHead(t *testing.T) {
@@ -280,6 +289,19 @@ func TestValidators(t *testing.T) {
 			},
 			assertion: hasError(`duplicate versions found in bundles: [{0.0.1: [anakin.v0.0.1, anakin.v0.0.2]} {1.0.1: [anakin.v1.0.1, anakin.v1.0.2]}]`),
 		},
+		{
+			name: "Package/Error/DuplicateChannelPriorities",
+			v: &Package{
+				Name: "anakin",
+				Channels: map[string]*Channel{
+					"light":  {Package: pkg, Name: "light", Priority: 1},
+					"dark":   {Package: pkg, Name: "dark", Priority: 1},
+					"grey":   {Package: pkg, Name: "grey"},
+					"purple": {Package: pkg, Name: "purple"},
+				},
+			},
+			assertion: hasError(`duplicate priorities found in channels: [{1: [dark, light]}]`),
+		},
 		{
 			name: "Package/Error/NoDefaultChannel",
 			v: &Package{
@@ -410,6 +432,15 @@ func TestValidators(t *testing.T) {
 			},
 			assertion: hasError(`channel must contain at least one bundle`),
 		},
+		{
+			name: "Channel/Error/NegativePriority",
+			v: &Channel{
+				Package:  pkg,
+				Name:     "light",
+				Priority: -1,
+			},
+			assertion: hasError(`priority -1 must not be negative`),
+		},
 		{
 			name: "Channel/Error/InvalidHead",
 			v: &Channel{
@@ -602,6 +633,184 @@ func TestValidators(t *testing.T) {
 			},
 			assertion: hasError(`must be exactly one property with type "olm.package"`),
 		},
+		{
+			name: "Bundle/Success/ValidConstraint",
+			v: &Bundle{
+				Package: pkg,
+				Channel: ch,
+				Name:    "anakin.v0.1.0",
+				Image:   "registry.io/image",
+				Properties: []property.Property{
+					property.MustBuildPackage("anakin", "0.1.0"),
+					property.MustBuildConstraint(property.Constraint{
+						All: &constraints.CompoundConstraint{Constraints: []constraints.Constraint{
+							{Cel: &constraints.Cel{Rule: `properties.exists(p, p.type == "certified")`}},
+							{Package: &constraints.PackageConstraint{PackageName: "obi-wan", VersionRange: ">=1.0.0"}},
+						}},
+					}),
+				},
+			},
+			assertion: require.NoError,
+		},
+		{
+			name: "Bundle/Error/InvalidConstraintCEL",
+			v: &Bundle{
+				Package: pkg,
+				Channel: ch,
+				Name:    "anakin.v0.1.0",
+				Image:   "registry.io/image",
+				Properties: []property.Property{
+					property.MustBuildPackage("anakin", "0.1.0"),
+					property.MustBuildConstraint(property.Constraint{Cel: &constraints.Cel{Rule: `properties.exists(p, `}}),
+				},
+			},
+			assertion: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, `invalid olm.constraint property 0: invalid cel rule "properties.exists(p, ": ERROR: <input>:1:22: Syntax error`)
+			},
+		},
+		{
+			name: "Bundle/Error/InvalidConstraintCompound",
+			v: &Bundle{
+				Package: pkg,
+				Channel: ch,
+				Name:    "anakin.v0.1.0",
+				Image:   "registry.io/image",
+				Properties: []property.Property{
+					property.MustBuildPackage("anakin", "0.1.0"),
+					property.MustBuildConstraint(property.Constraint{Any: &constraints.CompoundConstraint{}}),
+				},
+			},
+			assertion: hasError(`invalid olm.constraint property 0: any must contain at least one constraint`),
+		},
+		{
+			name: "Bundle/Success/ValidClusterVersions",
+			v: &Bundle{
+				Package: pkg,
+				Channel: ch,
+				Name:    "anakin.v0.1.0",
+				Image:   "registry.io/image",
+				Properties: []property.Property{
+					property.MustBuildPackage("anakin", "0.1.0"),
+					property.MustBuildMinKubeVersion("1.25.0"),
+					property.MustBuildMaxOpenShiftVersion("4.15"),
+				},
+			},
+			assertion: require.NoError,
+		},
+		{
+			name: "Bundle/Error/InvalidMinKubeVersion",
+			v: &Bundle{
+				Package: pkg,
+				Channel: ch,
+				Name:    "anakin.v0.1.0",
+				Image:   "registry.io/image",
+				Properties: []property.Property{
+					property.MustBuildPackage("anakin", "0.1.0"),
+					property.MustBuildMinKubeVersion("1.x"),
+				},
+			},
+			assertion: hasError(`invalid olm.minKubeVersion property "1.x": Invalid character(s) found in minor number "x"`),
+		},
+		{
+			name: "Bundle/Error/MultipleMaxOpenShiftVersions",
+			v: &Bundle{
+				Package: pkg,
+				Channel: ch,
+				Name:    "anakin.v0.1.0",
+				Image:   "registry.io/image",
+				Properties: []property.Property{
+					property.MustBuildPackage("anakin", "0.1.0"),
+					property.MustBuildMaxOpenShiftVersion("4.15"),
+					property.MustBuildMaxOpenShiftVersion("4.16"),
+				},
+			},
+			assertion: hasError(`must be at most one property with type "olm.maxOpenShiftVersion"`),
+		},
+		{
+			name: "Bundle/Success/PlainMediaType",
+			v: &Bundle{
+				Package: pkg,
+				Channel: ch,
+				Name:    "anakin.v0.1.0",
+				Image:   "registry.io/image",
+				Properties: []property.Property{
+					property.MustBuildPackage("anakin", "0.1.0"),
+					property.MustBuildBundleMediaType(property.MediaTypePlainV0),
+				},
+			},
+			assertion: require.NoError,
+		},
+		{
+			name: "Bundle/Success/HelmChart",
+			v: &Bundle{
+				Package: pkg,
+				Channel: ch,
+				Name:    "anakin.v0.1.0",
+				Image:   "registry.io/charts/anakin:0.1.0",
+				Properties: []property.Property{
+					property.MustBuildPackage("anakin", "0.1.0"),
+					property.MustBuildBundleMediaType(property.MediaTypeHelmV3),
+					property.MustBuildHelmChart(property.HelmChart{Name: "anakin", Version: "0.1.0"}),
+				},
+			},
+			assertion: require.NoError,
+		},
+		{
+			name: "Bundle/Error/HelmChartWithoutChartProperty",
+			v: &Bundle{
+				Package: pkg,
+				Channel: ch,
+				Name:    "anakin.v0.1.0",
+				Image:   "registry.io/charts/anakin:0.1.0",
+				Properties: []property.Property{
+					property.MustBuildPackage("anakin", "0.1.0"),
+					property.MustBuildBundleMediaType(property.MediaTypeHelmV3),
+				},
+			},
+			assertion: hasError(`must be exactly one property with type "olm.helm.chart" for helm+v3 bundles`),
+		},
+		{
+			name: "Bundle/Error/ChartPropertyWithoutHelmMediaType",
+			v: &Bundle{
+				Package: pkg,
+				Channel: ch,
+				Name:    "anakin.v0.1.0",
+				Image:   "registry.io/image",
+				Properties: []property.Property{
+					property.MustBuildPackage("anakin", "0.1.0"),
+					property.MustBuildHelmChart(property.HelmChart{Name: "anakin", Version: "0.1.0"}),
+				},
+			},
+			assertion: hasError(`property with type "olm.helm.chart" is only valid for helm+v3 bundles`),
+		},
+		{
+			name: "Bundle/Error/UnknownMediaType",
+			v: &Bundle{
+				Package: pkg,
+				Channel: ch,
+				Name:    "anakin.v0.1.0",
+				Image:   "registry.io/image",
+				Properties: []property.Property{
+					property.MustBuildPackage("anakin", "0.1.0"),
+					property.MustBuildBundleMediaType("helm+v2"),
+				},
+			},
+			assertion: hasError(`invalid olm.bundle.mediatype property "helm+v2": must be "registry+v1", "plain+v0" or "helm+v3"`),
+		},
+		{
+			name: "Bundle/Error/UnknownConstraint",
+			v: &Bundle{
+				Package: pkg,
+				Channel: ch,
+				Name:    "anakin.v0.1.0",
+				Image:   "registry.io/image",
+				Properties: []property.Property{
+					property.MustBuildPackage("anakin", "0.1.0"),
+					{Type: property.TypeConstraint, Value: json.RawMessage(`{"label":{"label":"foo"}}`)},
+				},
+			},
+			assertion: hasError(`parse property[1] of type "olm.constraint": json: unknown field "label"`),
+		},
 		{
 			name: "RelatedImage/Success/Valid",
 			v: RelatedImage{
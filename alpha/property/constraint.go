@@ -0,0 +1,77 @@
+package property
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/blang/semver/v4"
+
+	"github.com/operator-framework/api/pkg/constraints"
+)
+
+// Constraint is the value of an olm.constraint property. It declares a
+// dependency of a bundle that is either a CEL expression evaluated against the
+// properties of other bundles, a package or GVK requirement, or a compound of
+// other constraints.
+type Constraint = constraints.Constraint
+
+func MustBuildConstraint(c Constraint) Property {
+	return MustBuild(&c)
+}
+
+// ValidateConstraint checks that exactly one kind of constraint is set in c,
+// and that it is well formed. CEL expressions are compiled, so that syntax
+// errors are reported when a catalog is built rather than when it is used to
+// resolve dependencies.
+func ValidateConstraint(c Constraint) error {
+	set := 0
+	for _, isSet := range []bool{c.Cel != nil, c.Package != nil, c.GVK != nil, c.All != nil, c.Any != nil, c.Not != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of cel, package, gvk, all, any or not must be set, found %d", set)
+	}
+
+	switch {
+	case c.Cel != nil:
+		if c.Cel.Rule == "" {
+			return errors.New("cel rule must be set")
+		}
+		if _, err := constraints.NewCelEnvironment().Validate(c.Cel.Rule); err != nil {
+			return fmt.Errorf("invalid cel rule %q: %v", c.Cel.Rule, err)
+		}
+	case c.Package != nil:
+		if c.Package.PackageName == "" {
+			return errors.New("package name must be set")
+		}
+		if _, err := semver.ParseRange(c.Package.VersionRange); err != nil {
+			return fmt.Errorf("invalid package version range %q: %v", c.Package.VersionRange, err)
+		}
+	case c.GVK != nil:
+		if c.GVK.Version == "" || c.GVK.Kind == "" {
+			return errors.New("gvk version and kind must be set")
+		}
+	default:
+		var op string
+		var compound *constraints.CompoundConstraint
+		switch {
+		case c.All != nil:
+			op, compound = "all", c.All
+		case c.Any != nil:
+			op, compound = "any", c.Any
+		default:
+			op, compound = "not", c.Not
+		}
+		if len(compound.Constraints) == 0 {
+			return fmt.Errorf("%s must contain at least one constraint", op)
+		}
+		for i, sub := range compound.Constraints {
+			if err := ValidateConstraint(sub); err != nil {
+				return fmt.Errorf("%s constraint %d: %v", op, i, err)
+			}
+		}
+	}
+	return nil
+}
@@ -7,8 +7,11 @@ import (
 	"fmt"
 	"reflect"
 
+	"github.com/blang/semver/v4"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
 
+	"github.com/operator-framework/api/pkg/constraints"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 )
 
@@ -67,8 +70,13 @@ type GVKRequired struct {
 	Version string `json:"version"`
 }
 
+// BundleObject holds an object of a bundle. Ref, if set, is the path of a
+// file holding the object instead, relative to the directory of the catalog
+// file that holds the property. Catalogs loaded from a filesystem have their
+// references replaced with the data of the files they reference.
 type BundleObject struct {
-	Data []byte `json:"data"`
+	Data []byte `json:"data,omitempty"`
+	Ref  string `json:"ref,omitempty"`
 }
 
 type CSVMetadata struct {
@@ -88,6 +96,60 @@ type CSVMetadata struct {
 	Provider                  v1alpha1.AppLink                   `json:"provider,omitempty"`
 }
 
+// BundleSize describes the size of a bundle's manifests. Unlike the other
+// properties, it is not declared by bundle authors, but computed from the
+// bundle's objects when a catalog is rendered.
+type BundleSize struct {
+	ManifestBytes int64 `json:"manifestBytes"`
+	Objects       int   `json:"objects"`
+	CRDs          int   `json:"crds"`
+}
+
+// MinKubeVersion is the value of an olm.minKubeVersion property: the oldest
+// Kubernetes version that a bundle can be installed on, such as "1.25.0".
+type MinKubeVersion string
+
+// MaxOpenShiftVersion is the value of an olm.maxOpenShiftVersion property: the
+// latest OpenShift version that a bundle can be installed on, such as "4.15".
+// Clusters are not upgraded past this version while the bundle is installed.
+type MaxOpenShiftVersion string
+
+// BundleMediaType is the value of an olm.bundle.mediatype property: the format
+// of a bundle's manifests. Bundles without the property are registry+v1
+// bundles.
+type BundleMediaType string
+
+const (
+	// MediaTypeRegistryV1 bundles are operators packaged with a
+	// ClusterServiceVersion.
+	MediaTypeRegistryV1 BundleMediaType = "registry+v1"
+	// MediaTypePlainV0 bundles are plain Kubernetes manifests, without a
+	// ClusterServiceVersion.
+	MediaTypePlainV0 BundleMediaType = "plain+v0"
+	// MediaTypeHelmV3 bundles are Helm 3 charts. Their image is the chart's
+	// reference, and their olm.helm.chart property describes the chart.
+	MediaTypeHelmV3 BundleMediaType = "helm+v3"
+)
+
+// Deprecated is the value of an olm.deprecated property, which marks a bundle
+// as deprecated. Its value is an empty object.
+type Deprecated struct{}
+
+// HelmChart is the value of an olm.helm.chart property: the metadata of the
+// Chart.yaml file of a helm+v3 bundle's chart.
+type HelmChart struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	AppVersion  string `json:"appVersion,omitempty"`
+	Description string `json:"description,omitempty"`
+	// KubeVersion is the semver constraint of the Kubernetes versions that
+	// the chart can be installed on, such as ">=1.25.0-0".
+	KubeVersion string `json:"kubeVersion,omitempty"`
+	Home        string `json:"home,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	Deprecated  bool   `json:"deprecated,omitempty"`
+}
+
 type Properties struct {
 	Packages         []Package         `hash:"set"`
 	PackagesRequired []PackageRequired `hash:"set"`
@@ -96,6 +158,14 @@ type Properties struct {
 	BundleObjects    []BundleObject    `hash:"set"`
 	Channels         []Channel         `hash:"set"`
 	CSVMetadatas     []CSVMetadata     `hash:"set"`
+	BundleSizes      []BundleSize      `hash:"set"`
+	Constraints      []Constraint      `hash:"set"`
+
+	MinKubeVersions      []MinKubeVersion      `hash:"set"`
+	MaxOpenShiftVersions []MaxOpenShiftVersion `hash:"set"`
+	BundleMediaTypes     []BundleMediaType     `hash:"set"`
+	HelmCharts           []HelmChart           `hash:"set"`
+	Deprecations         []Deprecated          `hash:"set"`
 
 	Others []Property `hash:"set"`
 }
@@ -107,8 +177,15 @@ const (
 	TypeGVKRequired     = "olm.gvk.required"
 	TypeBundleObject    = "olm.bundle.object"
 	TypeCSVMetadata     = "olm.csv.metadata"
+	TypeBundleSize      = "olm.bundle.size"
 	TypeConstraint      = "olm.constraint"
 	TypeChannel         = "olm.channel"
+
+	TypeMinKubeVersion      = "olm.minKubeVersion"
+	TypeMaxOpenShiftVersion = "olm.maxOpenShiftVersion"
+	TypeBundleMediaType     = "olm.bundle.mediatype"
+	TypeHelmChart           = "olm.helm.chart"
+	TypeDeprecated          = "olm.deprecated"
 )
 
 func Parse(in []Property) (*Properties, error) {
@@ -151,6 +228,48 @@ func Parse(in []Property) (*Properties, error) {
 				return nil, ParseError{Idx: i, Typ: prop.Type, Err: err}
 			}
 			out.CSVMetadatas = append(out.CSVMetadatas, p)
+		case TypeBundleSize:
+			var p BundleSize
+			if err := json.Unmarshal(prop.Value, &p); err != nil {
+				return nil, ParseError{Idx: i, Typ: prop.Type, Err: err}
+			}
+			out.BundleSizes = append(out.BundleSizes, p)
+		case TypeConstraint:
+			p, err := constraints.Parse(prop.Value)
+			if err != nil {
+				return nil, ParseError{Idx: i, Typ: prop.Type, Err: err}
+			}
+			out.Constraints = append(out.Constraints, p)
+		case TypeMinKubeVersion:
+			var p MinKubeVersion
+			if err := json.Unmarshal(prop.Value, &p); err != nil {
+				return nil, ParseError{Idx: i, Typ: prop.Type, Err: err}
+			}
+			out.MinKubeVersions = append(out.MinKubeVersions, p)
+		case TypeMaxOpenShiftVersion:
+			var p MaxOpenShiftVersion
+			if err := json.Unmarshal(prop.Value, &p); err != nil {
+				return nil, ParseError{Idx: i, Typ: prop.Type, Err: err}
+			}
+			out.MaxOpenShiftVersions = append(out.MaxOpenShiftVersions, p)
+		case TypeBundleMediaType:
+			var p BundleMediaType
+			if err := json.Unmarshal(prop.Value, &p); err != nil {
+				return nil, ParseError{Idx: i, Typ: prop.Type, Err: err}
+			}
+			out.BundleMediaTypes = append(out.BundleMediaTypes, p)
+		case TypeHelmChart:
+			var p HelmChart
+			if err := json.Unmarshal(prop.Value, &p); err != nil {
+				return nil, ParseError{Idx: i, Typ: prop.Type, Err: err}
+			}
+			out.HelmCharts = append(out.HelmCharts, p)
+		case TypeDeprecated:
+			var p Deprecated
+			if err := json.Unmarshal(prop.Value, &p); err != nil {
+				return nil, ParseError{Idx: i, Typ: prop.Type, Err: err}
+			}
+			out.Deprecations = append(out.Deprecations, p)
 		// NOTICE: The Channel properties are for internal use only.
 		//   DO NOT use it for any public-facing functionalities.
 		//   This API is in alpha stage and it is subject to change.
@@ -279,6 +398,103 @@ func MustBuildCSVMetadata(csv v1alpha1.ClusterServiceVersion) Property {
 	})
 }
 
+func MustBuildBundleSize(manifestBytes int64, objects, crds int) Property {
+	return MustBuild(&BundleSize{ManifestBytes: manifestBytes, Objects: objects, CRDs: crds})
+}
+
+// ComputeBundleSize computes the size of a bundle from its objects, which may
+// be JSON or YAML manifests.
+func ComputeBundleSize(objects []string) (*BundleSize, error) {
+	size := &BundleSize{Objects: len(objects)}
+	for i, obj := range objects {
+		size.ManifestBytes += int64(len(obj))
+		var meta metav1.TypeMeta
+		if err := yaml.Unmarshal([]byte(obj), &meta); err != nil {
+			return nil, fmt.Errorf("parse object %d: %v", i, err)
+		}
+		if meta.Kind == "CustomResourceDefinition" {
+			size.CRDs++
+		}
+	}
+	return size, nil
+}
+
+func MustBuildMinKubeVersion(version string) Property {
+	v := MinKubeVersion(version)
+	return MustBuild(&v)
+}
+
+func MustBuildMaxOpenShiftVersion(version string) Property {
+	v := MaxOpenShiftVersion(version)
+	return MustBuild(&v)
+}
+
+func MustBuildBundleMediaType(mediaType BundleMediaType) Property {
+	return MustBuild(&mediaType)
+}
+
+func MustBuildHelmChart(chart HelmChart) Property {
+	return MustBuild(&chart)
+}
+
+func MustBuildDeprecated() Property {
+	return MustBuild(&Deprecated{})
+}
+
+// ParseClusterVersion parses the version of an olm.minKubeVersion or
+// olm.maxOpenShiftVersion property. As in OLM, versions are parsed
+// tolerantly, so that "v1.25" and "4.15" are valid versions.
+func ParseClusterVersion(version string) (semver.Version, error) {
+	if version == "" {
+		return semver.Version{}, errors.New("version must be set")
+	}
+	return semver.ParseTolerant(version)
+}
+
+// Validate checks that v is a valid cluster version.
+func (v MinKubeVersion) Validate() error {
+	_, err := ParseClusterVersion(string(v))
+	return err
+}
+
+// Validate checks that v is a valid cluster version.
+func (v MaxOpenShiftVersion) Validate() error {
+	_, err := ParseClusterVersion(string(v))
+	return err
+}
+
+// Validate checks that t is a known bundle media type.
+func (t BundleMediaType) Validate() error {
+	switch t {
+	case MediaTypeRegistryV1, MediaTypePlainV0, MediaTypeHelmV3:
+		return nil
+	}
+	return fmt.Errorf("must be %q, %q or %q", MediaTypeRegistryV1, MediaTypePlainV0, MediaTypeHelmV3)
+}
+
+// Validate checks the minimum Kubernetes version and the install modes of m,
+// which OLM requires to be valid to install a bundle.
+func (m CSVMetadata) Validate() error {
+	if m.MinKubeVersion != "" {
+		if _, err := ParseClusterVersion(m.MinKubeVersion); err != nil {
+			return fmt.Errorf("invalid minKubeVersion %q: %v", m.MinKubeVersion, err)
+		}
+	}
+	seen := map[v1alpha1.InstallModeType]bool{}
+	for _, mode := range m.InstallModes {
+		switch mode.Type {
+		case v1alpha1.InstallModeTypeOwnNamespace, v1alpha1.InstallModeTypeSingleNamespace, v1alpha1.InstallModeTypeMultiNamespace, v1alpha1.InstallModeTypeAllNamespaces:
+		default:
+			return fmt.Errorf("invalid install mode type %q", mode.Type)
+		}
+		if seen[mode.Type] {
+			return fmt.Errorf("duplicate install mode type %q", mode.Type)
+		}
+		seen[mode.Type] = true
+	}
+	return nil
+}
+
 // NOTICE: The Channel properties are for internal use only.
 //
 //	DO NOT use it for any public-facing functionalities.
@@ -6,6 +6,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/api/pkg/constraints"
 )
 
 func TestValidate(t *testing.T) {
@@ -108,6 +110,48 @@ func TestParse(t *testing.T) {
 			},
 			assertion: assert.Error,
 		},
+		{
+			name: "Error/InvalidBundleSize",
+			input: []Property{
+				{Type: TypeBundleSize, Value: json.RawMessage(`{"objects":"1"}`)},
+			},
+			assertion: assert.Error,
+		},
+		{
+			name: "Error/InvalidConstraint",
+			input: []Property{
+				{Type: TypeConstraint, Value: json.RawMessage(`{"unknown":{}}`)},
+			},
+			assertion: assert.Error,
+		},
+		{
+			name: "Error/InvalidMinKubeVersion",
+			input: []Property{
+				{Type: TypeMinKubeVersion, Value: json.RawMessage(`{"version":"1.25.0"}`)},
+			},
+			assertion: assert.Error,
+		},
+		{
+			name: "Error/InvalidBundleMediaType",
+			input: []Property{
+				{Type: TypeBundleMediaType, Value: json.RawMessage(`{"mediatype":"plain+v0"}`)},
+			},
+			assertion: assert.Error,
+		},
+		{
+			name: "Error/InvalidHelmChart",
+			input: []Property{
+				{Type: TypeHelmChart, Value: json.RawMessage(`{"name":["chart"]}`)},
+			},
+			assertion: assert.Error,
+		},
+		{
+			name: "Error/InvalidDeprecated",
+			input: []Property{
+				{Type: TypeDeprecated, Value: json.RawMessage(`true`)},
+			},
+			assertion: assert.Error,
+		},
 		{
 			name: "Error/InvalidOther",
 			input: []Property{
@@ -127,6 +171,13 @@ func TestParse(t *testing.T) {
 				MustBuildGVKRequired("other", "v2", "Kind3"),
 				MustBuildGVKRequired("other", "v2", "Kind4"),
 				MustBuildBundleObject([]byte("testdata2")),
+				MustBuildBundleSize(1024, 3, 1),
+				MustBuildConstraint(Constraint{FailureMessage: "no foo", Cel: &constraints.Cel{Rule: `properties.exists(p, p.type == "foo")`}}),
+				MustBuildMinKubeVersion("1.25.0"),
+				MustBuildMaxOpenShiftVersion("4.15"),
+				MustBuildBundleMediaType(MediaTypePlainV0),
+				MustBuildHelmChart(HelmChart{Name: "chart", Version: "1.0.0", AppVersion: "2.0.0"}),
+				MustBuildDeprecated(),
 				{Type: "otherType1", Value: json.RawMessage(`{"v":"otherValue1"}`)},
 				{Type: "otherType2", Value: json.RawMessage(`["otherValue2"]`)},
 			},
@@ -150,6 +201,17 @@ func TestParse(t *testing.T) {
 				BundleObjects: []BundleObject{
 					{Data: []byte("testdata2")},
 				},
+				BundleSizes: []BundleSize{
+					{ManifestBytes: 1024, Objects: 3, CRDs: 1},
+				},
+				Constraints: []Constraint{
+					{FailureMessage: "no foo", Cel: &constraints.Cel{Rule: `properties.exists(p, p.type == "foo")`}},
+				},
+				MinKubeVersions:      []MinKubeVersion{"1.25.0"},
+				MaxOpenShiftVersions: []MaxOpenShiftVersion{"4.15"},
+				BundleMediaTypes:     []BundleMediaType{MediaTypePlainV0},
+				HelmCharts:           []HelmChart{{Name: "chart", Version: "1.0.0", AppVersion: "2.0.0"}},
+				Deprecations:         []Deprecated{{}},
 				Others: []Property{
 					{Type: "otherType1", Value: json.RawMessage(`{"v":"otherValue1"}`)},
 					{Type: "otherType2", Value: json.RawMessage(`["otherValue2"]`)},
@@ -234,6 +296,18 @@ func TestBuild(t *testing.T) {
 			assertion:        require.NoError,
 			expectedProperty: propPtr(MustBuildBundleObject([]byte("test"))),
 		},
+		{
+			name:             "Success/BundleSize",
+			input:            &BundleSize{ManifestBytes: 1024, Objects: 3, CRDs: 1},
+			assertion:        require.NoError,
+			expectedProperty: &Property{Type: TypeBundleSize, Value: json.RawMessage(`{"manifestBytes":1024,"objects":3,"crds":1}`)},
+		},
+		{
+			name:             "Success/MaxOpenShiftVersion",
+			input:            func() *MaxOpenShiftVersion { v := MaxOpenShiftVersion("4.15"); return &v }(),
+			assertion:        require.NoError,
+			expectedProperty: &Property{Type: TypeMaxOpenShiftVersion, Value: json.RawMessage(`"4.15"`)},
+		},
 		{
 			name:             "Success/Property",
 			input:            &Property{Type: "foo", Value: json.RawMessage(`"bar"`)},
@@ -265,6 +339,22 @@ func TestBuild(t *testing.T) {
 	}
 }
 
+func TestComputeBundleSize(t *testing.T) {
+	crd := `{"apiVersion":"apiextensions.k8s.io/v1","kind":"CustomResourceDefinition","metadata":{"name":"foos.example.com"}}`
+	csv := "apiVersion: operators.coreos.com/v1alpha1\nkind: ClusterServiceVersion\nmetadata:\n  name: foo.v0.1.0\n"
+
+	size, err := ComputeBundleSize([]string{crd, csv})
+	require.NoError(t, err)
+	assert.Equal(t, &BundleSize{ManifestBytes: int64(len(crd) + len(csv)), Objects: 2, CRDs: 1}, size)
+
+	size, err = ComputeBundleSize(nil)
+	require.NoError(t, err)
+	assert.Equal(t, &BundleSize{}, size)
+
+	_, err = ComputeBundleSize([]string{"{"})
+	require.Error(t, err)
+}
+
 func TestMustBuild(t *testing.T) {
 	assert.NotPanics(t, func() { MustBuild(&Package{}) })
 	assert.Panics(t, func() { MustBuild(Package{}) })
@@ -273,3 +363,75 @@ func TestMustBuild(t *testing.T) {
 func propPtr(in Property) *Property {
 	return &in
 }
+
+func TestValidateConstraint(t *testing.T) {
+	type spec struct {
+		name      string
+		c         Constraint
+		expectErr string
+	}
+	specs := []spec{
+		{
+			name: "Success/Cel",
+			c:    Constraint{Cel: &constraints.Cel{Rule: `properties.exists(p, p.type == "foo")`}},
+		},
+		{
+			name: "Success/Compound",
+			c: Constraint{Not: &constraints.CompoundConstraint{Constraints: []Constraint{
+				{GVK: &constraints.GVKConstraint{Group: "example.com", Version: "v1", Kind: "Foo"}},
+				{Package: &constraints.PackageConstraint{PackageName: "bar", VersionRange: "<1.0.0"}},
+			}}},
+		},
+		{
+			name:      "Error/NoneSet",
+			c:         Constraint{FailureMessage: "message only"},
+			expectErr: "exactly one of cel, package, gvk, all, any or not must be set, found 0",
+		},
+		{
+			name: "Error/MultipleSet",
+			c: Constraint{
+				Cel: &constraints.Cel{Rule: "true"},
+				GVK: &constraints.GVKConstraint{Version: "v1", Kind: "Foo"},
+			},
+			expectErr: "exactly one of cel, package, gvk, all, any or not must be set, found 2",
+		},
+		{
+			name:      "Error/EmptyCel",
+			c:         Constraint{Cel: &constraints.Cel{}},
+			expectErr: "cel rule must be set",
+		},
+		{
+			name:      "Error/InvalidCel",
+			c:         Constraint{Cel: &constraints.Cel{Rule: `properties.exists(p, `}},
+			expectErr: `invalid cel rule "properties.exists(p, "`,
+		},
+		{
+			name:      "Error/InvalidVersionRange",
+			c:         Constraint{Package: &constraints.PackageConstraint{PackageName: "bar", VersionRange: "not a range"}},
+			expectErr: `invalid package version range "not a range"`,
+		},
+		{
+			name:      "Error/IncompleteGVK",
+			c:         Constraint{GVK: &constraints.GVKConstraint{Group: "example.com"}},
+			expectErr: "gvk version and kind must be set",
+		},
+		{
+			name: "Error/InvalidNested",
+			c: Constraint{All: &constraints.CompoundConstraint{Constraints: []Constraint{
+				{Cel: &constraints.Cel{Rule: "true"}},
+				{Any: &constraints.CompoundConstraint{}},
+			}}},
+			expectErr: "all constraint 1: any must contain at least one constraint",
+		},
+	}
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			err := ValidateConstraint(s.c)
+			if s.expectErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, s.expectErr)
+		})
+	}
+}
@@ -3,16 +3,24 @@ package property
 import (
 	"fmt"
 	"reflect"
+	"sort"
 )
 
 func init() {
 	scheme = map[reflect.Type]string{
-		reflect.TypeOf(&Package{}):         TypePackage,
-		reflect.TypeOf(&PackageRequired{}): TypePackageRequired,
-		reflect.TypeOf(&GVK{}):             TypeGVK,
-		reflect.TypeOf(&GVKRequired{}):     TypeGVKRequired,
-		reflect.TypeOf(&BundleObject{}):    TypeBundleObject,
-		reflect.TypeOf(&CSVMetadata{}):     TypeCSVMetadata,
+		reflect.TypeOf(&Package{}):               TypePackage,
+		reflect.TypeOf(&PackageRequired{}):       TypePackageRequired,
+		reflect.TypeOf(&GVK{}):                   TypeGVK,
+		reflect.TypeOf(&GVKRequired{}):           TypeGVKRequired,
+		reflect.TypeOf(&BundleObject{}):          TypeBundleObject,
+		reflect.TypeOf(&CSVMetadata{}):           TypeCSVMetadata,
+		reflect.TypeOf(&BundleSize{}):            TypeBundleSize,
+		reflect.TypeOf(&Constraint{}):            TypeConstraint,
+		reflect.TypeOf(new(MinKubeVersion)):      TypeMinKubeVersion,
+		reflect.TypeOf(new(MaxOpenShiftVersion)): TypeMaxOpenShiftVersion,
+		reflect.TypeOf(new(BundleMediaType)):     TypeBundleMediaType,
+		reflect.TypeOf(&HelmChart{}):             TypeHelmChart,
+		reflect.TypeOf(&Deprecated{}):            TypeDeprecated,
 		// NOTICE: The Channel properties are for internal use only.
 		//   DO NOT use it for any public-facing functionalities.
 		//   This API is in alpha stage and it is subject to change.
@@ -32,3 +40,25 @@ func AddToScheme(typ string, p interface{}) {
 	}
 	scheme[t] = typ
 }
+
+// IsRegisteredType reports whether typ is a property type registered in the
+// scheme, including with AddToScheme.
+func IsRegisteredType(typ string) bool {
+	for _, t := range scheme {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisteredTypes returns the sorted property types registered in the scheme,
+// including those added with AddToScheme.
+func RegisteredTypes() []string {
+	types := make([]string, 0, len(scheme))
+	for _, typ := range scheme {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
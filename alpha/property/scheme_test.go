@@ -44,3 +44,11 @@ func TestAddToScheme(t *testing.T) {
 		})
 	}
 }
+
+func TestRegisteredTypes(t *testing.T) {
+	types := RegisteredTypes()
+	assert.IsNonDecreasing(t, types)
+	assert.Contains(t, types, TypePackage)
+	assert.Contains(t, types, TypeHelmChart)
+	assert.NotContains(t, types, "olm.packge")
+}
@@ -0,0 +1,93 @@
+package property
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Get returns the values of type T of props. T is either the type of the
+// values of one of the fields of Properties, like GVK, or a type added to the
+// scheme with AddToScheme, whose values are parsed from props.Others and
+// validated as with ParseValue.
+func Get[T any](props *Properties) ([]T, error) {
+	v := reflect.ValueOf(props).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if values, ok := v.Field(i).Interface().([]T); ok {
+			return append([]T(nil), values...), nil
+		}
+	}
+	typ, err := schemeType[T]()
+	if err != nil {
+		return nil, err
+	}
+	var out []T
+	for _, p := range props.Others {
+		if p.Type != typ {
+			continue
+		}
+		value, err := ParseValue[T](p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, value)
+	}
+	return out, nil
+}
+
+// ParseValue parses the value of p, whose type must be the property type of
+// T in the scheme, and validates it: constraints with ValidateConstraint, and
+// values that have a Validate method, like MaxOpenShiftVersion, with it.
+func ParseValue[T any](p Property) (T, error) {
+	var value T
+	typ, err := schemeType[T]()
+	if err != nil {
+		return value, err
+	}
+	if p.Type != typ {
+		return value, fmt.Errorf("property has type %q, not %q", p.Type, typ)
+	}
+	if err := json.Unmarshal(p.Value, &value); err != nil {
+		return value, fmt.Errorf("parse %s property: %v", typ, err)
+	}
+	if err := validateValue(&value); err != nil {
+		return value, fmt.Errorf("invalid %s property: %v", typ, err)
+	}
+	return value, nil
+}
+
+// BuildValue validates value as ParseValue does, and builds its property.
+// Unlike Build, it fails for invalid values.
+func BuildValue[T any](value T) (Property, error) {
+	typ, err := schemeType[T]()
+	if err != nil {
+		return Property{}, err
+	}
+	if err := validateValue(&value); err != nil {
+		return Property{}, fmt.Errorf("invalid %s property: %v", typ, err)
+	}
+	p, err := Build(&value)
+	if err != nil {
+		return Property{}, err
+	}
+	return *p, nil
+}
+
+func schemeType[T any]() (string, error) {
+	t := reflect.TypeOf(new(T))
+	typ, ok := scheme[t]
+	if !ok {
+		return "", fmt.Errorf("%s not a known property type registered with the scheme", t)
+	}
+	return typ, nil
+}
+
+func validateValue(value interface{}) error {
+	switch v := value.(type) {
+	case *Constraint:
+		return ValidateConstraint(*v)
+	case interface{ Validate() error }:
+		return v.Validate()
+	}
+	return nil
+}
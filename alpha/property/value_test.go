@@ -0,0 +1,155 @@
+package property
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/api/pkg/constraints"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+type testValue struct {
+	Name string `json:"name"`
+}
+
+func TestGet(t *testing.T) {
+	if _, ok := scheme[reflect.TypeOf(&testValue{})]; !ok {
+		AddToScheme("test.value", &testValue{})
+	}
+
+	props, err := Parse([]Property{
+		MustBuildGVK("group", "v1", "Kind1"),
+		MustBuildMaxOpenShiftVersion("4.15"),
+		MustBuildDeprecated(),
+		{Type: "test.value", Value: json.RawMessage(`{"name":"foo"}`)},
+		{Type: "other", Value: json.RawMessage(`{"name":"bar"}`)},
+	})
+	require.NoError(t, err)
+
+	gvks, err := Get[GVK](props)
+	require.NoError(t, err)
+	require.Equal(t, []GVK{{Group: "group", Kind: "Kind1", Version: "v1"}}, gvks)
+
+	versions, err := Get[MaxOpenShiftVersion](props)
+	require.NoError(t, err)
+	require.Equal(t, []MaxOpenShiftVersion{"4.15"}, versions)
+
+	deprecations, err := Get[Deprecated](props)
+	require.NoError(t, err)
+	require.Len(t, deprecations, 1)
+
+	constraints, err := Get[Constraint](props)
+	require.NoError(t, err)
+	require.Empty(t, constraints)
+
+	values, err := Get[testValue](props)
+	require.NoError(t, err)
+	require.Equal(t, []testValue{{Name: "foo"}}, values)
+
+	_, err = Get[struct{ Unknown bool }](props)
+	require.ErrorContains(t, err, "not a known property type registered with the scheme")
+}
+
+func TestParseValue(t *testing.T) {
+	type spec struct {
+		name      string
+		parse     func() (interface{}, error)
+		expect    interface{}
+		expectErr string
+	}
+	specs := []spec{
+		{
+			name: "Success/Constraint",
+			parse: func() (interface{}, error) {
+				return ParseValue[Constraint](MustBuildConstraint(Constraint{Cel: &constraints.Cel{Rule: `properties.exists(p, p.type == "foo")`}}))
+			},
+			expect: Constraint{Cel: &constraints.Cel{Rule: `properties.exists(p, p.type == "foo")`}},
+		},
+		{
+			name: "Success/Deprecated",
+			parse: func() (interface{}, error) {
+				return ParseValue[Deprecated](MustBuildDeprecated())
+			},
+			expect: Deprecated{},
+		},
+		{
+			name: "Success/BundleMediaType",
+			parse: func() (interface{}, error) {
+				return ParseValue[BundleMediaType](MustBuildBundleMediaType(MediaTypeHelmV3))
+			},
+			expect: MediaTypeHelmV3,
+		},
+		{
+			name: "Error/WrongType",
+			parse: func() (interface{}, error) {
+				return ParseValue[MinKubeVersion](MustBuildMaxOpenShiftVersion("4.15"))
+			},
+			expectErr: `property has type "olm.maxOpenShiftVersion", not "olm.minKubeVersion"`,
+		},
+		{
+			name: "Error/InvalidConstraint",
+			parse: func() (interface{}, error) {
+				return ParseValue[Constraint](MustBuildConstraint(Constraint{Any: &constraints.CompoundConstraint{}}))
+			},
+			expectErr: "invalid olm.constraint property: any must contain at least one constraint",
+		},
+		{
+			name: "Error/InvalidMaxOpenShiftVersion",
+			parse: func() (interface{}, error) {
+				return ParseValue[MaxOpenShiftVersion](MustBuildMaxOpenShiftVersion("4.x"))
+			},
+			expectErr: "invalid olm.maxOpenShiftVersion property",
+		},
+		{
+			name: "Error/InvalidBundleMediaType",
+			parse: func() (interface{}, error) {
+				return ParseValue[BundleMediaType](MustBuildBundleMediaType("helm+v2"))
+			},
+			expectErr: `invalid olm.bundle.mediatype property: must be "registry+v1", "plain+v0" or "helm+v3"`,
+		},
+		{
+			name: "Error/InvalidCSVMetadata",
+			parse: func() (interface{}, error) {
+				return ParseValue[CSVMetadata](MustBuild(&CSVMetadata{InstallModes: []v1alpha1.InstallMode{{Type: "OwnNamespace", Supported: true}, {Type: "AllNamespace"}}}))
+			},
+			expectErr: `invalid olm.csv.metadata property: invalid install mode type "AllNamespace"`,
+		},
+		{
+			name: "Error/InvalidDeprecated",
+			parse: func() (interface{}, error) {
+				return ParseValue[Deprecated](Property{Type: TypeDeprecated, Value: json.RawMessage(`"yes"`)})
+			},
+			expectErr: "parse olm.deprecated property",
+		},
+	}
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := s.parse()
+			if s.expectErr != "" {
+				require.ErrorContains(t, err, s.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, s.expect, actual)
+		})
+	}
+}
+
+func TestBuildValue(t *testing.T) {
+	p, err := BuildValue(MaxOpenShiftVersion("4.15"))
+	require.NoError(t, err)
+	require.Equal(t, MustBuildMaxOpenShiftVersion("4.15"), p)
+
+	p, err = BuildValue(Deprecated{})
+	require.NoError(t, err)
+	require.Equal(t, Property{Type: TypeDeprecated, Value: json.RawMessage(`{}`)}, p)
+
+	_, err = BuildValue(CSVMetadata{MinKubeVersion: "latest"})
+	require.ErrorContains(t, err, `invalid olm.csv.metadata property: invalid minKubeVersion "latest"`)
+
+	_, err = BuildValue(Constraint{})
+	require.ErrorContains(t, err, "invalid olm.constraint property: exactly one of cel, package, gvk, all, any or not must be set, found 0")
+}
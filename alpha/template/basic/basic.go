@@ -15,6 +15,9 @@ const schema string = "olm.template.basic"
 
 type Template struct {
 	RenderBundle func(context.Context, string) (*declcfg.DeclarativeConfig, error)
+	// Values, if set, are made available to the Go templates in the string
+	// fields of the template's entries as `.Values`.
+	Values map[string]interface{}
 }
 
 type BasicTemplate struct {
@@ -47,11 +50,23 @@ func (t Template) Render(ctx context.Context, reader io.Reader) (*declcfg.Declar
 	if err != nil {
 		return nil, err
 	}
-	cfg, err := declcfg.LoadSlice(bt.Entries)
+	return t.RenderEntries(ctx, bt.Entries)
+}
+
+// RenderEntries renders a set of basic template entries, replacing each bundle
+// image reference with the rendered bundle
+func (t Template) RenderEntries(ctx context.Context, entries []*declcfg.Meta) (*declcfg.DeclarativeConfig, error) {
+	if t.Values != nil {
+		if err := expandValues(entries, t.Values); err != nil {
+			return nil, err
+		}
+	}
+	cfg, err := declcfg.LoadSlice(entries)
 	if err != nil {
 		return cfg, err
 	}
 
+	rendered := make(map[string][]declcfg.Bundle, len(cfg.Bundles))
 	outb := cfg.Bundles[:0]
 	for _, b := range cfg.Bundles {
 		if !isBundleTemplate(&b) {
@@ -61,10 +76,14 @@ func (t Template) Render(ctx context.Context, reader io.Reader) (*declcfg.Declar
 		if err != nil {
 			return nil, err
 		}
+		rendered[b.Image] = contributor.Bundles
 		outb = append(outb, contributor.Bundles...)
 	}
 
 	cfg.Bundles = outb
+	if err := resolveBundleRefs(cfg.Channels, rendered, t.Values); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
@@ -0,0 +1,100 @@
+package basic
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+func renderBundle(_ context.Context, image string) (*declcfg.DeclarativeConfig, error) {
+	version := image[strings.LastIndex(image, ":v")+2:]
+	return &declcfg.DeclarativeConfig{
+		Bundles: []declcfg.Bundle{{
+			Schema:  declcfg.SchemaBundle,
+			Package: "foo",
+			Name:    "foo.v" + version,
+			Image:   image,
+			Properties: []property.Property{
+				property.MustBuildPackage("foo", version),
+			},
+			CsvJSON: `{"metadata":{"annotations":{"olm.skipRange":"<` + version + `"}}}`,
+		}},
+	}, nil
+}
+
+func TestRenderBundleRefs(t *testing.T) {
+	tmpl := `---
+schema: olm.template.basic
+entries:
+  - schema: olm.package
+    name: foo
+    defaultChannel: stable
+  - schema: olm.channel
+    package: foo
+    name: stable
+    entries:
+      - name: '{{ .Bundle "quay.io/foo/foo:v0.1.0" "name" }}'
+      - name: '{{ .Bundle "quay.io/foo/foo:v0.2.0" "name" }}'
+        replaces: '{{ .Bundle "quay.io/foo/foo:v0.1.0" "name" }}'
+        skipRange: '{{ .Bundle "quay.io/foo/foo:v0.2.0" "skipRange" }}'
+        skips:
+          - 'foo.v{{ .Bundle "quay.io/foo/foo:v0.1.0" "version" }}-rc1'
+  - schema: olm.bundle
+    image: quay.io/foo/foo:v0.1.0
+  - schema: olm.bundle
+    image: quay.io/foo/foo:v0.2.0
+`
+	cfg, err := Template{RenderBundle: renderBundle}.Render(context.Background(), strings.NewReader(tmpl))
+	require.NoError(t, err)
+	require.Len(t, cfg.Channels, 1)
+	require.Equal(t, []declcfg.ChannelEntry{
+		{Name: "foo.v0.1.0"},
+		{Name: "foo.v0.2.0", Replaces: "foo.v0.1.0", SkipRange: "<0.2.0", Skips: []string{"foo.v0.1.0-rc1"}},
+	}, cfg.Channels[0].Entries)
+}
+
+func TestRenderBundleRefsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		entry  string
+		errMsg string
+	}{
+		{
+			name:   "UnreferencedImage",
+			entry:  `{{ .Bundle "quay.io/foo/foo:v9.9.9" "name" }}`,
+			errMsg: `bundle image "quay.io/foo/foo:v9.9.9" is not referenced by an olm.bundle entry in the template`,
+		},
+		{
+			name:   "UnknownField",
+			entry:  `{{ .Bundle "quay.io/foo/foo:v0.1.0" "description" }}`,
+			errMsg: `unknown bundle field "description"`,
+		},
+		{
+			name:   "InvalidTemplate",
+			entry:  `{{ .Bundle "quay.io/foo/foo:v0.1.0" "name"`,
+			errMsg: `package "foo" channel "stable" entry 0: parse`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := `---
+schema: olm.template.basic
+entries:
+  - schema: olm.channel
+    package: foo
+    name: stable
+    entries:
+      - name: '` + tt.entry + `'
+  - schema: olm.bundle
+    image: quay.io/foo/foo:v0.1.0
+`
+			_, err := Template{RenderBundle: renderBundle}.Render(context.Background(), strings.NewReader(tmpl))
+			require.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
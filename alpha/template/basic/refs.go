@@ -0,0 +1,142 @@
+package basic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/alpha/template/internal/funcs"
+)
+
+const skipRangeAnnotation = "olm.skipRange"
+
+// bundleRefs is the data passed to channel entry field templates. It allows a
+// channel entry to refer to metadata of a bundle rendered from the template,
+// e.g. `{{ .Bundle "quay.io/foo/bar:v1.0.0" "name" }}`, so that bundle names and
+// versions do not have to be duplicated by hand.
+type bundleRefs struct {
+	rendered map[string][]declcfg.Bundle
+
+	// Values are the values of the template.
+	Values map[string]interface{}
+}
+
+// Bundle returns the requested field of the bundle rendered from image. Supported
+// fields are "name", "package", "image", "version" and "skipRange".
+func (r bundleRefs) Bundle(image, field string) (string, error) {
+	bundles, ok := r.rendered[image]
+	if !ok {
+		return "", fmt.Errorf("bundle image %q is not referenced by an olm.bundle entry in the template", image)
+	}
+	if len(bundles) != 1 {
+		return "", fmt.Errorf("bundle image %q resulted in %d bundles, expected 1", image, len(bundles))
+	}
+	b := bundles[0]
+
+	switch field {
+	case "name":
+		return b.Name, nil
+	case "package":
+		return b.Package, nil
+	case "image":
+		return b.Image, nil
+	case "version":
+		props, err := property.Parse(b.Properties)
+		if err != nil {
+			return "", fmt.Errorf("parse properties for bundle %q: %v", b.Name, err)
+		}
+		if len(props.Packages) != 1 {
+			return "", fmt.Errorf("bundle %q has %d %q properties, expected exactly 1", b.Name, len(props.Packages), property.TypePackage)
+		}
+		return props.Packages[0].Version, nil
+	case "skipRange":
+		return bundleSkipRange(b)
+	default:
+		return "", fmt.Errorf("unknown bundle field %q: must be one of name, package, image, version, or skipRange", field)
+	}
+}
+
+// bundleSkipRange returns the skipRange annotation of the bundle's CSV, or an empty
+// string if the CSV does not have one.
+func bundleSkipRange(b declcfg.Bundle) (string, error) {
+	props, err := property.Parse(b.Properties)
+	if err != nil {
+		return "", fmt.Errorf("parse properties for bundle %q: %v", b.Name, err)
+	}
+	for _, md := range props.CSVMetadatas {
+		if sr, ok := md.Annotations[skipRangeAnnotation]; ok {
+			return sr, nil
+		}
+	}
+	if b.CsvJSON == "" {
+		return "", nil
+	}
+	var csv struct {
+		Metadata struct {
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal([]byte(b.CsvJSON), &csv); err != nil {
+		return "", fmt.Errorf("parse csv for bundle %q: %v", b.Name, err)
+	}
+	return csv.Metadata.Annotations[skipRangeAnnotation], nil
+}
+
+// resolveBundleRefs expands any bundle references in the fields of the channels' entries.
+func resolveBundleRefs(channels []declcfg.Channel, rendered map[string][]declcfg.Bundle, values map[string]interface{}) error {
+	refs := bundleRefs{rendered: rendered, Values: values}
+	for i := range channels {
+		ch := &channels[i]
+		for j := range ch.Entries {
+			e := &ch.Entries[j]
+			fields := append([]*string{&e.Name, &e.Replaces, &e.SkipRange}, skipPointers(e.Skips)...)
+			for _, f := range fields {
+				v, err := expandBundleRefs(*f, refs)
+				if err != nil {
+					return fmt.Errorf("package %q channel %q entry %d: %v", ch.Package, ch.Name, j, err)
+				}
+				*f = v
+			}
+		}
+	}
+	return nil
+}
+
+func skipPointers(skips []string) []*string {
+	out := make([]*string, 0, len(skips))
+	for i := range skips {
+		out = append(out, &skips[i])
+	}
+	return out
+}
+
+func expandBundleRefs(s string, refs bundleRefs) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	tmpl, err := parseTemplate(s)
+	if err != nil {
+		return "", err
+	}
+	return executeTemplate(s, tmpl, refs)
+}
+
+func parseTemplate(s string) (*template.Template, error) {
+	tmpl := template.New("entry")
+	if _, err := tmpl.Funcs(funcs.FuncMap(tmpl)).Parse(s); err != nil {
+		return nil, fmt.Errorf("parse %q: %v", s, err)
+	}
+	return tmpl, nil
+}
+
+func executeTemplate(s string, tmpl *template.Template, refs bundleRefs) (string, error) {
+	var out strings.Builder
+	if err := tmpl.Execute(&out, refs); err != nil {
+		return "", fmt.Errorf("expand %q: %v", s, err)
+	}
+	// match Helm, which renders missing values as empty rather than "<no value>"
+	return strings.ReplaceAll(out.String(), "<no value>", ""), nil
+}
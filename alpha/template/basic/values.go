@@ -0,0 +1,155 @@
+package basic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template/parse"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+// SetValue sets a value from a Helm-style assignment, like "image.tag=v1",
+// where dots in the key separate the keys of nested maps. The value is set
+// as a string.
+func SetValue(values map[string]interface{}, assignment string) error {
+	key, value, ok := strings.Cut(assignment, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("invalid value assignment %q: must be key=value", assignment)
+	}
+	keys := strings.Split(key, ".")
+	m := values
+	for i, k := range keys[:len(keys)-1] {
+		if k == "" {
+			return fmt.Errorf("invalid value assignment %q: empty key", assignment)
+		}
+		next, ok := m[k].(map[string]interface{})
+		if !ok {
+			if _, exists := m[k]; exists {
+				return fmt.Errorf("invalid value assignment %q: %q is not a map", assignment, strings.Join(keys[:i+1], "."))
+			}
+			next = map[string]interface{}{}
+			m[k] = next
+		}
+		m = next
+	}
+	last := keys[len(keys)-1]
+	if last == "" {
+		return fmt.Errorf("invalid value assignment %q: empty key", assignment)
+	}
+	m[last] = value
+	return nil
+}
+
+// expandValues expands the Go templates in the string fields of the entries
+// with the template's values, available as `.Values`. Templates that refer to
+// `.Bundle` are expanded once the bundles are rendered, with the channel
+// entries.
+func expandValues(entries []*declcfg.Meta, values map[string]interface{}) error {
+	for _, e := range entries {
+		var blob interface{}
+		if err := json.Unmarshal(e.Blob, &blob); err != nil {
+			return err
+		}
+		expanded, err := expandStrings(blob, func(s string) (string, error) {
+			return expandValuesString(s, values)
+		})
+		if err != nil {
+			return fmt.Errorf("%s entry %q: %v", e.Schema, e.Name, err)
+		}
+		data, err := json.Marshal(expanded)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandStrings returns v with expand applied to each of its strings, other
+// than the keys of its objects.
+func expandStrings(v interface{}, expand func(string) (string, error)) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return expand(v)
+	case []interface{}:
+		for i := range v {
+			e, err := expandStrings(v[i], expand)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = e
+		}
+	case map[string]interface{}:
+		for k := range v {
+			e, err := expandStrings(v[k], expand)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = e
+		}
+	}
+	return v, nil
+}
+
+func expandValuesString(s string, values map[string]interface{}) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	tmpl, err := parseTemplate(s)
+	if err != nil {
+		return "", err
+	}
+	if referencesBundle(tmpl.Root) {
+		return s, nil
+	}
+	return executeTemplate(s, tmpl, bundleRefs{Values: values})
+}
+
+// referencesBundle reports whether the template rooted at node refers to
+// `.Bundle`.
+func referencesBundle(node parse.Node) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, c := range n.Nodes {
+			if referencesBundle(c) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return referencesBundle(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, c := range n.Cmds {
+			if referencesBundle(c) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			if referencesBundle(a) {
+				return true
+			}
+		}
+	case *parse.FieldNode:
+		return len(n.Ident) > 0 && n.Ident[0] == "Bundle"
+	case *parse.ChainNode:
+		return referencesBundle(n.Node)
+	case *parse.IfNode:
+		return referencesBundle(n.Pipe) || referencesBundle(n.List) || referencesBundle(n.ElseList)
+	case *parse.RangeNode:
+		return referencesBundle(n.Pipe) || referencesBundle(n.List) || referencesBundle(n.ElseList)
+	case *parse.WithNode:
+		return referencesBundle(n.Pipe) || referencesBundle(n.List) || referencesBundle(n.ElseList)
+	case *parse.TemplateNode:
+		return referencesBundle(n.Pipe)
+	}
+	return false
+}
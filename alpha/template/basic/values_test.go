@@ -0,0 +1,92 @@
+package basic
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+func TestRenderValues(t *testing.T) {
+	tmpl := `---
+schema: olm.template.basic
+entries:
+  - schema: olm.package
+    name: foo
+    defaultChannel: '{{ .Values.channel | default "stable" }}'
+    description: 'foo for {{ .Values.env | upper }}'
+  - schema: olm.channel
+    package: foo
+    name: '{{ .Values.channel | default "stable" }}'
+    entries:
+      - name: '{{ .Bundle (printf "%s/foo:v0.1.0" .Values.registry) "name" }}'
+  - schema: olm.bundle
+    image: '{{ .Values.registry }}/foo:v0.1.0'
+`
+	for _, tt := range []struct {
+		name     string
+		values   map[string]interface{}
+		image    string
+		channel  string
+		envUpper string
+	}{
+		{
+			name:     "Dev",
+			values:   map[string]interface{}{"env": "dev", "registry": "quay.io/dev"},
+			image:    "quay.io/dev/foo:v0.1.0",
+			channel:  "stable",
+			envUpper: "DEV",
+		},
+		{
+			name:     "Prod",
+			values:   map[string]interface{}{"env": "prod", "registry": "registry.example.com/prod", "channel": "fast"},
+			image:    "registry.example.com/prod/foo:v0.1.0",
+			channel:  "fast",
+			envUpper: "PROD",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Template{RenderBundle: renderBundle, Values: tt.values}.Render(context.Background(), strings.NewReader(tmpl))
+			require.NoError(t, err)
+			require.Len(t, cfg.Packages, 1)
+			require.Equal(t, tt.channel, cfg.Packages[0].DefaultChannel)
+			require.Equal(t, "foo for "+tt.envUpper, cfg.Packages[0].Description)
+			require.Len(t, cfg.Channels, 1)
+			require.Equal(t, tt.channel, cfg.Channels[0].Name)
+			require.Equal(t, []declcfg.ChannelEntry{{Name: "foo.v0.1.0"}}, cfg.Channels[0].Entries)
+			require.Len(t, cfg.Bundles, 1)
+			require.Equal(t, tt.image, cfg.Bundles[0].Image)
+		})
+	}
+
+	_, err := Template{RenderBundle: renderBundle, Values: map[string]interface{}{}}.Render(context.Background(), strings.NewReader(`---
+schema: olm.template.basic
+entries:
+  - schema: olm.package
+    name: foo
+    defaultChannel: '{{ required "channel is required" .Values.channel }}'
+`))
+	require.ErrorContains(t, err, `olm.package entry "foo": expand "{{ required \"channel is required\" .Values.channel }}": template: entry:1:3: executing "entry" at <required "channel is required" .Values.channel>: error calling required: channel is required`)
+}
+
+func TestSetValue(t *testing.T) {
+	values := map[string]interface{}{}
+	require.NoError(t, SetValue(values, "env=prod"))
+	require.NoError(t, SetValue(values, "image.registry=quay.io/foo"))
+	require.NoError(t, SetValue(values, "image.tag=v1=rc"))
+	require.Equal(t, map[string]interface{}{
+		"env": "prod",
+		"image": map[string]interface{}{
+			"registry": "quay.io/foo",
+			"tag":      "v1=rc",
+		},
+	}, values)
+
+	require.EqualError(t, SetValue(values, "env"), `invalid value assignment "env": must be key=value`)
+	require.EqualError(t, SetValue(values, "=prod"), `invalid value assignment "=prod": must be key=value`)
+	require.EqualError(t, SetValue(values, "image..tag=v1"), `invalid value assignment "image..tag=v1": empty key`)
+	require.EqualError(t, SetValue(values, "env.name=prod"), `invalid value assignment "env.name=prod": "env" is not a map`)
+}
@@ -0,0 +1,79 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/template/basic"
+	"github.com/operator-framework/operator-registry/alpha/template/internal/funcs"
+)
+
+type Template struct {
+	RenderBundle func(context.Context, string) (*declcfg.DeclarativeConfig, error)
+	// Values are made available to the template as `.Values`
+	Values map[string]interface{}
+}
+
+// ReadValues parses a Helm-style values file.
+func ReadValues(reader io.Reader) (map[string]interface{}, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("parsing values: %v", err)
+	}
+	return values, nil
+}
+
+// Render executes the Go template read from reader with the template's values,
+// and renders the resulting FBC content as a basic template: every olm.bundle
+// which only specifies an image is replaced by the rendered bundle.
+func (t Template) Render(ctx context.Context, reader io.Reader) (*declcfg.DeclarativeConfig, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading template: %v", err)
+	}
+
+	tmpl := template.New("template")
+	tmpl = tmpl.Funcs(funcs.FuncMap(tmpl))
+	if _, err := tmpl.Parse(string(data)); err != nil {
+		return nil, fmt.Errorf("parsing template: %v", err)
+	}
+
+	values := t.Values
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, map[string]interface{}{"Values": values}); err != nil {
+		return nil, fmt.Errorf("executing template: %v", err)
+	}
+	// match Helm, which renders missing values as empty rather than "<no value>"
+	expanded := strings.ReplaceAll(out.String(), "<no value>", "")
+
+	var entries []*declcfg.Meta
+	if err := declcfg.WalkMetasReader(strings.NewReader(expanded), func(meta *declcfg.Meta, err error) error {
+		if err != nil {
+			return err
+		}
+		// conditionally rendered content commonly results in empty documents, which Helm ignores
+		if blob := string(bytes.TrimSpace(meta.Blob)); blob == "" || blob == "null" || blob == "{}" {
+			return nil
+		}
+		entries = append(entries, meta)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("parsing expanded template: %v", err)
+	}
+
+	return basic.Template{RenderBundle: t.RenderBundle}.RenderEntries(ctx, entries)
+}
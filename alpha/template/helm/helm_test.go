@@ -0,0 +1,134 @@
+package helm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+func renderBundle(_ context.Context, image string) (*declcfg.DeclarativeConfig, error) {
+	name := image[strings.LastIndex(image, "/")+1:]
+	return &declcfg.DeclarativeConfig{
+		Bundles: []declcfg.Bundle{{
+			Schema:     declcfg.SchemaBundle,
+			Package:    "foo",
+			Name:       name,
+			Image:      image,
+			Properties: []property.Property{property.MustBuildPackage("foo", strings.TrimPrefix(name, "foo.v"))},
+		}},
+	}, nil
+}
+
+func TestRender(t *testing.T) {
+	tmpl := `---
+schema: olm.package
+name: {{ required "package name is required" .Values.package }}
+defaultChannel: {{ .Values.defaultChannel | default "stable" }}
+{{- range .Values.channels }}
+---
+schema: olm.channel
+package: {{ $.Values.package }}
+name: {{ .name }}
+entries:
+{{ toYaml .entries | indent 2 }}
+{{- end }}
+{{- range .Values.bundles }}
+---
+schema: olm.bundle
+image: {{ $.Values.registry }}/{{ . }}
+{{- end }}
+`
+	values, err := ReadValues(strings.NewReader(`
+package: foo
+registry: quay.io/example
+channels:
+  - name: stable
+    entries:
+      - name: foo.v0.1.0
+      - name: foo.v0.2.0
+        replaces: foo.v0.1.0
+bundles:
+  - foo.v0.1.0
+  - foo.v0.2.0
+`))
+	require.NoError(t, err)
+
+	tpl := Template{RenderBundle: renderBundle, Values: values}
+	cfg, err := tpl.Render(context.Background(), strings.NewReader(tmpl))
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Packages, 1)
+	require.Equal(t, "foo", cfg.Packages[0].Name)
+	require.Equal(t, "stable", cfg.Packages[0].DefaultChannel)
+	require.Len(t, cfg.Channels, 1)
+	require.Equal(t, []declcfg.ChannelEntry{
+		{Name: "foo.v0.1.0"},
+		{Name: "foo.v0.2.0", Replaces: "foo.v0.1.0"},
+	}, cfg.Channels[0].Entries)
+	require.Len(t, cfg.Bundles, 2)
+	require.Equal(t, "quay.io/example/foo.v0.1.0", cfg.Bundles[0].Image)
+	require.Equal(t, "foo", cfg.Bundles[0].Package)
+	require.Equal(t, "quay.io/example/foo.v0.2.0", cfg.Bundles[1].Image)
+}
+
+func TestRenderNamedTemplates(t *testing.T) {
+	tmpl := `{{- define "bundle" }}
+schema: olm.bundle
+image: quay.io/example/{{ . }}
+{{- end }}
+{{- range .Values.bundles }}
+---
+{{- include "bundle" . }}
+{{- end }}
+`
+	tpl := Template{RenderBundle: renderBundle, Values: map[string]interface{}{"bundles": []interface{}{"foo.v0.1.0"}}}
+	cfg, err := tpl.Render(context.Background(), strings.NewReader(tmpl))
+	require.NoError(t, err)
+	require.Len(t, cfg.Bundles, 1)
+	require.Equal(t, "quay.io/example/foo.v0.1.0", cfg.Bundles[0].Image)
+}
+
+func TestRenderErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		values   map[string]interface{}
+		errMsg   string
+	}{
+		{
+			name:     "RequiredValueMissing",
+			template: `name: {{ required "package name is required" .Values.package }}`,
+			errMsg:   "package name is required",
+		},
+		{
+			name:     "InvalidTemplate",
+			template: `name: {{ .Values.package`,
+			errMsg:   "parsing template",
+		},
+		{
+			name:     "UndefinedNamedTemplate",
+			template: `{{ include "missing" . }}`,
+			errMsg:   "executing template",
+		},
+		{
+			name: "UnrenderableBundle",
+			template: `schema: olm.bundle
+package: foo
+image: quay.io/example/foo.v0.1.0
+`,
+			errMsg: "unexpected fields present in basic template bundle",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl := Template{RenderBundle: renderBundle, Values: tt.values}
+			_, err := tpl.Render(context.Background(), strings.NewReader(tt.template))
+			require.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
 	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/pkg/registry"
 )
 
 func (t Template) Render(ctx context.Context) (*declcfg.DeclarativeConfig, error) {
@@ -48,7 +49,18 @@ func (t Template) Render(ctx context.Context) (*declcfg.DeclarativeConfig, error
 		return nil, fmt.Errorf("render: unable to post-process bundle info: %v", err)
 	}
 
+	// drop any rendered bundles which the pre-release policy excluded from all channels
+	out.Bundles = channelBundles(out.Bundles, channelBundleVersions)
+	if len(out.Bundles) == 0 {
+		return nil, fmt.Errorf("render: no bundles remain after applying pre-release channel policy %q", sv.PreReleaseChannelPolicy)
+	}
+
 	channels := sv.generateChannels(channelBundleVersions)
+	if t.GraphStrategy != nil {
+		if err := relinkChannels(channels, channelBundleVersions, t.GraphStrategy); err != nil {
+			return nil, fmt.Errorf("render: %v", err)
+		}
+	}
 	out.Channels = channels
 	out.Packages[0].DefaultChannel = sv.defaultChannel
 
@@ -109,6 +121,14 @@ func readFile(reader io.Reader) (*semverTemplate, error) {
 		return nil, fmt.Errorf("unknown DefaultChannelTypePreference: %q\nValid values are 'major' or 'minor'", sv.DefaultChannelTypePreference)
 	}
 
+	switch sv.PreReleaseChannelPolicy {
+	case "":
+		sv.PreReleaseChannelPolicy = includePreReleasePolicy
+	case includePreReleasePolicy, excludePreReleasePolicy, candidatePreReleasePolicy:
+	default:
+		return nil, fmt.Errorf("unknown PreReleaseChannelPolicy: %q\nValid values are 'include', 'exclude', or 'candidate'", sv.PreReleaseChannelPolicy)
+	}
+
 	return &sv, nil
 }
 
@@ -142,9 +162,51 @@ func (sv *semverTemplate) getVersionsFromStandardChannels(cfg *declcfg.Declarati
 	}
 	versions[stableChannelArchetype] = bdm
 
+	if err = sv.applyPreReleasePolicy(&versions); err != nil {
+		return nil, err
+	}
+
 	return &versions, nil
 }
 
+// applyPreReleasePolicy relocates or removes the pre-release versions of the fast and stable channels
+// according to the template's PreReleaseChannelPolicy. Candidate channel versions are never affected.
+func (sv *semverTemplate) applyPreReleasePolicy(versions *bundleVersions) error {
+	if sv.PreReleaseChannelPolicy != excludePreReleasePolicy && sv.PreReleaseChannelPolicy != candidatePreReleasePolicy {
+		return nil
+	}
+
+	for _, archetype := range []channelArchetype{fastChannelArchetype, stableChannelArchetype} {
+		for b, v := range (*versions)[archetype] {
+			if len(v.Pre) == 0 {
+				continue
+			}
+			delete((*versions)[archetype], b)
+			if sv.PreReleaseChannelPolicy == candidatePreReleasePolicy {
+				(*versions)[candidateChannelArchetype][b] = v
+			}
+		}
+	}
+
+	// moving versions into the candidate channel may introduce new build metadata conflicts
+	bdm := (*versions)[candidateChannelArchetype]
+	return validateVersions(&bdm)
+}
+
+// channelBundles returns the bundles which are members of at least one channel archetype
+func channelBundles(bundles []declcfg.Bundle, versions *bundleVersions) []declcfg.Bundle {
+	out := make([]declcfg.Bundle, 0, len(bundles))
+	for _, b := range bundles {
+		for _, vs := range *versions {
+			if _, ok := vs[b.Name]; ok {
+				out = append(out, b)
+				break
+			}
+		}
+	}
+	return out
+}
+
 func (sv *semverTemplate) getVersionsFromChannel(semverBundles []semverTemplateBundleEntry, bundleDict map[string]string, cfg *declcfg.DeclarativeConfig) (map[string]semver.Version, error) {
 	entries := make(map[string]semver.Version)
 
@@ -285,15 +347,7 @@ func (sv *semverTemplate) generateChannels(semverChannels *bundleVersions) []dec
 func (sv *semverTemplate) linkChannels(unlinkedChannels map[string]*declcfg.Channel, harvestedVersions *bundleVersions) []declcfg.Channel {
 	channels := []declcfg.Channel{}
 
-	// bundle --> version lookup
-	bundleVersions := make(map[string]semver.Version)
-	for _, vs := range *harvestedVersions {
-		for b, v := range vs {
-			if _, ok := bundleVersions[b]; !ok {
-				bundleVersions[b] = v
-			}
-		}
-	}
+	bundleVersions := versionsByBundle(harvestedVersions)
 
 	for _, channel := range unlinkedChannels {
 		entries := &channel.Entries
@@ -353,6 +407,41 @@ func (sv *semverTemplate) linkChannels(unlinkedChannels map[string]*declcfg.Chan
 	return channels
 }
 
+// relinkChannels replaces the edges of the entries of channels with the ones
+// that strategy computes for them.
+func relinkChannels(channels []declcfg.Channel, harvestedVersions *bundleVersions, strategy registry.GraphStrategy) error {
+	bundleVersions := versionsByBundle(harvestedVersions)
+	for i := range channels {
+		entries := channels[i].Entries
+		bundles := make([]registry.GraphBundle, 0, len(entries))
+		for _, e := range entries {
+			bundles = append(bundles, registry.GraphBundle{Name: e.Name, Version: bundleVersions[e.Name]})
+		}
+		edges, err := registry.ChannelEdges(strategy, channels[i].Name, bundles)
+		if err != nil {
+			return err
+		}
+		for j := range entries {
+			edge := edges[entries[j].Name]
+			entries[j].Replaces, entries[j].Skips = edge.Replaces, edge.Skips
+		}
+	}
+	return nil
+}
+
+// versionsByBundle returns the bundle --> version lookup of harvestedVersions
+func versionsByBundle(harvestedVersions *bundleVersions) map[string]semver.Version {
+	bundleVersions := make(map[string]semver.Version)
+	for _, vs := range *harvestedVersions {
+		for b, v := range vs {
+			if _, ok := bundleVersions[b]; !ok {
+				bundleVersions[b] = v
+			}
+		}
+	}
+	return bundleVersions
+}
+
 func channelNameFromMinor(prefix channelArchetype, version semver.Version) string {
 	return fmt.Sprintf("%s-v%d.%d", prefix, version.Major, version.Minor)
 }
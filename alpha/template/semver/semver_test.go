@@ -10,6 +10,7 @@ import (
 
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
 	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/pkg/registry"
 )
 
 func TestLinkChannels(t *testing.T) {
@@ -255,6 +256,53 @@ func TestLinkChannels(t *testing.T) {
 	}
 }
 
+func TestRelinkChannels(t *testing.T) {
+	versions := bundleVersions{
+		"stable": {
+			"a-v1.0.0": semver.MustParse("1.0.0"),
+			"a-v1.1.0": semver.MustParse("1.1.0"),
+			"a-v1.1.1": semver.MustParse("1.1.1"),
+		},
+	}
+	channels := func() []declcfg.Channel {
+		return []declcfg.Channel{{
+			Schema:  "olm.channel",
+			Name:    "stable-v1",
+			Package: "a",
+			Entries: []declcfg.ChannelEntry{
+				{Name: "a-v1.0.0"},
+				{Name: "a-v1.1.0", Skips: []string{"a-v1.0.0"}},
+				{Name: "a-v1.1.1", Replaces: "a-v1.1.0"},
+			},
+		}}
+	}
+	// replacesPrevious makes each bundle replace the one before it
+	replacesPrevious := registry.GraphStrategyFunc(func(_ string, bundles []registry.GraphBundle) (map[string]registry.GraphEdges, error) {
+		edges := map[string]registry.GraphEdges{}
+		for i := 1; i < len(bundles); i++ {
+			edges[bundles[i].Name] = registry.GraphEdges{Replaces: bundles[i-1].Name}
+		}
+		return edges, nil
+	})
+
+	t.Run("Relinked", func(t *testing.T) {
+		out := channels()
+		require.NoError(t, relinkChannels(out, &versions, replacesPrevious))
+		require.Equal(t, []declcfg.ChannelEntry{
+			{Name: "a-v1.0.0"},
+			{Name: "a-v1.1.0", Replaces: "a-v1.0.0"},
+			{Name: "a-v1.1.1", Replaces: "a-v1.1.0"},
+		}, out[0].Entries)
+	})
+
+	t.Run("UnknownBundle", func(t *testing.T) {
+		unknownEdge := registry.GraphStrategyFunc(func(_ string, bundles []registry.GraphBundle) (map[string]registry.GraphEdges, error) {
+			return map[string]registry.GraphEdges{bundles[0].Name: {Skips: []string{"a-v0.1.0"}}}, nil
+		})
+		require.EqualError(t, relinkChannels(channels(), &versions, unknownEdge), `channel "stable-v1": bundle "a-v1.0.0" has an edge to unknown bundle "a-v0.1.0"`)
+	})
+}
+
 func TestGenerateChannels(t *testing.T) {
 	// type bundleVersions map[string]map[string]semver.Version // e.g. d["stable"]["example-operator.v1.0.0"] = 1.0.0
 	channelOperatorVersions := bundleVersions{
@@ -546,6 +594,101 @@ func TestGetVersionsFromStandardChannel(t *testing.T) {
 	}
 }
 
+func TestApplyPreReleasePolicy(t *testing.T) {
+	newVersions := func() bundleVersions {
+		return bundleVersions{
+			"candidate": {
+				"a-v1.0.0-rc1": semver.MustParse("1.0.0-rc1"),
+			},
+			"fast": {
+				"a-v1.0.0":       semver.MustParse("1.0.0"),
+				"a-v1.1.0-beta1": semver.MustParse("1.1.0-beta1"),
+			},
+			"stable": {
+				"a-v1.0.0":       semver.MustParse("1.0.0"),
+				"a-v1.1.0-beta1": semver.MustParse("1.1.0-beta1"),
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		policy      preReleasePolicy
+		outVersions bundleVersions
+	}{
+		{
+			name:        "include",
+			policy:      includePreReleasePolicy,
+			outVersions: newVersions(),
+		},
+		{
+			name:   "exclude",
+			policy: excludePreReleasePolicy,
+			outVersions: bundleVersions{
+				"candidate": {
+					"a-v1.0.0-rc1": semver.MustParse("1.0.0-rc1"),
+				},
+				"fast": {
+					"a-v1.0.0": semver.MustParse("1.0.0"),
+				},
+				"stable": {
+					"a-v1.0.0": semver.MustParse("1.0.0"),
+				},
+			},
+		},
+		{
+			name:   "candidate",
+			policy: candidatePreReleasePolicy,
+			outVersions: bundleVersions{
+				"candidate": {
+					"a-v1.0.0-rc1":   semver.MustParse("1.0.0-rc1"),
+					"a-v1.1.0-beta1": semver.MustParse("1.1.0-beta1"),
+				},
+				"fast": {
+					"a-v1.0.0": semver.MustParse("1.0.0"),
+				},
+				"stable": {
+					"a-v1.0.0": semver.MustParse("1.0.0"),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sv := semverTemplate{PreReleaseChannelPolicy: tt.policy}
+			versions := newVersions()
+			require.NoError(t, sv.applyPreReleasePolicy(&versions))
+			require.EqualValues(t, tt.outVersions, versions)
+		})
+	}
+
+	t.Run("excluded bundles are dropped from rendered bundles", func(t *testing.T) {
+		sv := semverTemplate{PreReleaseChannelPolicy: excludePreReleasePolicy}
+		versions := newVersions()
+		require.NoError(t, sv.applyPreReleasePolicy(&versions))
+		bundles := []declcfg.Bundle{
+			{Name: "a-v1.0.0-rc1"},
+			{Name: "a-v1.0.0"},
+			{Name: "a-v1.1.0-beta1"},
+		}
+		require.Equal(t, []declcfg.Bundle{{Name: "a-v1.0.0-rc1"}, {Name: "a-v1.0.0"}}, channelBundles(bundles, &versions))
+	})
+
+	t.Run("abort on build metadata conflict in candidate", func(t *testing.T) {
+		sv := semverTemplate{PreReleaseChannelPolicy: candidatePreReleasePolicy}
+		versions := bundleVersions{
+			"candidate": {
+				"a-v1.1.0-beta1+build1": semver.MustParse("1.1.0-beta1+build1"),
+			},
+			"fast": {},
+			"stable": {
+				"a-v1.1.0-beta1+build2": semver.MustParse("1.1.0-beta1+build2"),
+			},
+		}
+		require.Error(t, sv.applyPreReleasePolicy(&versions))
+	})
+}
+
 func TestBailOnVersionBuildMetadata(t *testing.T) {
 	sv := semverTemplate{
 		Stable: semverTemplateChannelBundles{
@@ -702,6 +845,30 @@ invalid:
 				require.ErrorContains(t, err, "unknown DefaultChannelTypePreference")
 			},
 		},
+		{
+			name:  "default prereleasechannelpolicy",
+			input: fmt.Sprintf(templateFstr, "true", "true", "minor"),
+			assertions: func(t *testing.T, template *semverTemplate, err error) {
+				require.NoError(t, err)
+				require.Equal(t, includePreReleasePolicy, template.PreReleaseChannelPolicy)
+			},
+		},
+		{
+			name:  "valid prereleasechannelpolicy",
+			input: fmt.Sprintf(templateFstr, "true", "true", "minor") + "preReleaseChannelPolicy: candidate\n",
+			assertions: func(t *testing.T, template *semverTemplate, err error) {
+				require.NoError(t, err)
+				require.Equal(t, candidatePreReleasePolicy, template.PreReleaseChannelPolicy)
+			},
+		},
+		{
+			name:  "unknown prereleasechannelpolicy",
+			input: fmt.Sprintf(templateFstr, "true", "true", "minor") + "preReleaseChannelPolicy: foo\n",
+			assertions: func(t *testing.T, template *semverTemplate, err error) {
+				require.Nil(t, template)
+				require.ErrorContains(t, err, "unknown PreReleaseChannelPolicy")
+			},
+		},
 	}
 
 	for _, tc := range testCases {
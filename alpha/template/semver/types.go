@@ -7,12 +7,16 @@ import (
 	"github.com/blang/semver/v4"
 
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/registry"
 )
 
 // data passed into this module externally
 type Template struct {
 	Data         io.Reader
 	RenderBundle func(context.Context, string) (*declcfg.DeclarativeConfig, error)
+	// GraphStrategy, if set, computes the edges of the entries of the
+	// generated channels instead of the semver rules of the template.
+	GraphStrategy registry.GraphStrategy
 }
 
 // IO structs -- BEGIN
@@ -29,6 +33,7 @@ type semverTemplate struct {
 	GenerateMajorChannels        bool                         `json:"generateMajorChannels,omitempty"`
 	GenerateMinorChannels        bool                         `json:"generateMinorChannels,omitempty"`
 	DefaultChannelTypePreference streamType                   `json:"defaultChannelTypePreference,omitempty"`
+	PreReleaseChannelPolicy      preReleasePolicy             `json:"preReleaseChannelPolicy,omitempty"`
 	Candidate                    semverTemplateChannelBundles `json:"candidate,omitempty"`
 	Fast                         semverTemplateChannelBundles `json:"fast,omitempty"`
 	Stable                       semverTemplateChannelBundles `json:"stable,omitempty"`
@@ -71,6 +76,19 @@ const majorStreamType streamType = "major"
 // general preference for minor channels
 var streamTypePriorities = map[streamType]int{minorStreamType: 2, majorStreamType: 1, defaultStreamType: 0}
 
+// policy for how pre-release bundle versions (e.g. 1.2.0-rc1) listed in the
+// fast and stable template channels map to generated channels
+type preReleasePolicy string
+
+const (
+	// pre-releases are included in the channels they are listed under (default)
+	includePreReleasePolicy preReleasePolicy = "include"
+	// pre-releases are dropped from the fast and stable channels
+	excludePreReleasePolicy preReleasePolicy = "exclude"
+	// pre-releases are moved from the fast and stable channels to candidate channels
+	candidatePreReleasePolicy preReleasePolicy = "candidate"
+)
+
 // map of archetypes --> bundles --> bundle-version from the input file
 type bundleVersions map[channelArchetype]map[string]semver.Version // e.g. srcv["stable"]["example-operator.v1.0.0"] = 1.0.0
 
@@ -0,0 +1,64 @@
+package add
+
+import (
+	"io"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+)
+
+func NewCmd() *cobra.Command {
+	var add action.AddBundle
+	logger := logrus.New()
+
+	cmd := &cobra.Command{
+		Use:   "add <catalogDir> <bundleRef>",
+		Short: "Add a bundle to a file-based catalog directory",
+		Long: `Add a bundle to a file-based catalog directory.
+
+The bundle is rendered and written to the file that contains its package's
+olm.package blob. If the package does not exist yet, it is created in
+<catalogDir>/<package>/catalog.json with the first channel as its default channel.
+
+An entry for the bundle is added to each channel given by --channels, or to the
+package's default channel if no channels are given. Channels that do not exist
+are created. The upgrade edges of the new entries are taken from --replaces,
+--skips, and --skip-range if set, and otherwise from the bundle's CSV. If
+neither defines any upgrade edges, the new entry replaces the current head of
+each channel.
+
+The catalog is validated before any files are written.
+`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			add.CatalogDir = args[0]
+			add.BundleRef = args[1]
+
+			// The bundle loading impl is somewhat verbose, even on the happy path,
+			// so discard all logrus default logger logs. Any important failures will be
+			// returned from add.Run and logged as fatal errors.
+			logrus.SetOutput(io.Discard)
+
+			reg, err := util.CreateCLIRegistry(cmd)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			defer func() {
+				_ = reg.Destroy()
+			}()
+			add.Registry = reg
+
+			if err := add.Run(cmd.Context()); err != nil {
+				logger.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().StringSliceVar(&add.Channels, "channels", nil, "channels to add the bundle to (default: the package's default channel)")
+	cmd.Flags().StringVar(&add.Replaces, "replaces", "", "name of the bundle replaced by the new bundle")
+	cmd.Flags().StringSliceVar(&add.Skips, "skips", nil, "names of the bundles skipped by the new bundle")
+	cmd.Flags().StringVar(&add.SkipRange, "skip-range", "", "semver range of bundle versions skipped by the new bundle")
+	return cmd
+}
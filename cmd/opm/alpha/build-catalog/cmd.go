@@ -0,0 +1,81 @@
+package buildcatalog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+)
+
+func NewCmd() *cobra.Command {
+	var (
+		build          action.BuildCatalog
+		extraLabelStrs []string
+	)
+	logger := logrus.New()
+
+	cmd := &cobra.Command{
+		Use:   "build-catalog <catalogDir>",
+		Short: "Build a binary-less catalog image without a container build tool",
+		Long: `Build a binary-less image of a file-based catalog without a container build tool.
+
+The image has a single layer with the catalog at /configs and its pre-built
+serve cache at /tmp/cache, like the images built from the Dockerfiles of
+"opm generate dockerfile" with a scratch base image. It is written to an OCI
+archive with --output, pushed to a registry with --push, or both. Registry
+credentials are read from the default locations used by podman and docker.
+
+OLMv0 CatalogSources that use binary-less images must set:
+spec:
+  grpcPodConfig:
+    extractContent:
+      catalogDir: /configs
+      cacheDir: /tmp/cache
+`,
+		Example: `
+#
+# Build a catalog image into an OCI archive
+#
+$ opm alpha build-catalog ./catalog --output catalog.tar
+
+#
+# Build a catalog image and push it to a registry
+#
+$ opm alpha build-catalog ./catalog --push quay.io/example/catalog:latest
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			extraLabels, err := parseLabels(extraLabelStrs)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			build.CatalogDir = args[0]
+			build.ExtraLabels = extraLabels
+			if err := build.Run(cmd.Context()); err != nil {
+				logger.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&build.OutputFile, "output", "o", "", "path of the OCI archive to write the image to")
+	cmd.Flags().StringVar(&build.Push, "push", "", "reference of the image to push to a registry")
+	cmd.Flags().StringVar(&build.Architecture, "arch", "amd64", "architecture of the image")
+	cmd.Flags().StringSliceVarP(&extraLabelStrs, "extra-labels", "l", []string{}, "extra labels of the image, of the form 'key=value'")
+	cmd.Flags().BoolVar(&build.SkipTLSVerify, "skip-tls-verify", false, "disable TLS verification when pushing the image")
+	cmd.MarkFlagsOneRequired("output", "push")
+	return cmd
+}
+
+func parseLabels(labelStrs []string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, l := range labelStrs {
+		key, value, ok := strings.Cut(l, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid label %q", l)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
@@ -17,6 +17,7 @@ func NewCmd() *cobra.Command {
 	runCmd.AddCommand(newBundleValidateCmd())
 	runCmd.AddCommand(extractCmd)
 	runCmd.AddCommand(newBundleUnpackCmd())
+	runCmd.AddCommand(newBundleRebuildCmd())
 
 	return runCmd
 }
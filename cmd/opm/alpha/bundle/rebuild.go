@@ -0,0 +1,61 @@
+package bundle
+
+import (
+	"io"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+)
+
+func newBundleRebuildCmd() *cobra.Command {
+	var rebuild action.RebuildBundle
+	logger := logrus.New()
+
+	cmd := &cobra.Command{
+		Use:   "rebuild <indexRef> <package> <bundle>",
+		Short: "Rebuild an operator bundle directory from its catalog entry",
+		Long: `Rebuild an operator bundle directory from its olm.bundle entry in a catalog.
+
+The bundle's manifests, metadata and bundle.Dockerfile are written to the
+output directory, so that the bundle can be patched and built again. The
+manifests are the bundle's olm.bundle.object properties. Bundles without
+objects are rebuilt with a ClusterServiceVersion generated from their
+olm.csv.metadata property, which has no install strategy.`,
+		Example: `
+#
+# Rebuild the etcdoperator.v0.9.4 bundle of the etcd package of a catalog
+#
+$ opm alpha bundle rebuild ./catalog etcd etcdoperator.v0.9.4 --output-dir ./etcd-bundle
+`,
+		Args: cobra.ExactArgs(3),
+		Run: func(cmd *cobra.Command, args []string) {
+			// The bundle loading impl is somewhat verbose, even on the happy path,
+			// so discard all logrus default logger logs. Any important failures will be
+			// returned from rebuild.Run and logged as fatal errors.
+			logrus.SetOutput(io.Discard)
+
+			reg, err := util.CreateCLIRegistry(cmd)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			defer func() {
+				_ = reg.Destroy()
+			}()
+
+			rebuild.IndexReference, rebuild.PackageName, rebuild.BundleName = args[0], args[1], args[2]
+			rebuild.Registry = reg
+			if err := rebuild.Run(cmd.Context()); err != nil {
+				logger.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&rebuild.OutputDir, "output-dir", "o", "", "directory to write the bundle to, which must not exist or be empty")
+	cmd.Flags().StringVar(&rebuild.BaseImage, "base-image", "scratch", "base image of the bundle.Dockerfile")
+	if err := cmd.MarkFlagRequired("output-dir"); err != nil {
+		logger.Panic(err)
+	}
+	return cmd
+}
@@ -3,13 +3,19 @@ package bundle
 import (
 	"context"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
 	dircopy "github.com/otiai10/copy"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/operator-framework/api/pkg/operators"
 
 	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
 	"github.com/operator-framework/operator-registry/pkg/image"
@@ -21,7 +27,11 @@ func newBundleUnpackCmd() *cobra.Command {
 	unpack := &cobra.Command{
 		Use:   "unpack BUNDLE_NAME[:TAG|@DIGEST]",
 		Short: "Unpacks the content of an operator bundle",
-		Long:  "Unpacks the content of an operator bundle into a directory",
+		Long: `Unpacks the content of an operator bundle into a directory
+
+The bundle image is pulled and its manifests and metadata directories are
+written to the output directory. With --csv-only, only the bundle's
+ClusterServiceVersion manifest is written.`,
 		Args: func(cmd *cobra.Command, args []string) error {
 			return cobra.ExactArgs(1)(cmd, args)
 		},
@@ -34,6 +44,7 @@ func newBundleUnpackCmd() *cobra.Command {
 	unpack.Flags().BoolP("skip-validation", "v", false, "disable bundle validation")
 	unpack.Flags().StringP("root-ca", "c", "", "file path of a root CA to use when communicating with image registries")
 	unpack.Flags().StringP("out", "o", "./", "directory in which to unpack operator bundle content")
+	unpack.Flags().Bool("csv-only", false, "only unpack the bundle's ClusterServiceVersion manifest")
 
 	if err := unpack.Flags().MarkDeprecated("skip-tls", "use --use-http and --skip-tls-verify instead"); err != nil {
 		logrus.Panic(err.Error())
@@ -92,6 +103,12 @@ func unpackBundle(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	var csvOnly bool
+	csvOnly, err = cmd.Flags().GetBool("csv-only")
+	if err != nil {
+		return err
+	}
+
 	var rootCA string
 	rootCA, err = cmd.Flags().GetString("root-ca")
 	if err != nil {
@@ -160,9 +177,70 @@ func unpackBundle(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if csvOnly {
+		return copyCSV(filepath.Join(dir, bundle.ManifestsDir), filepath.Join(out, bundle.ManifestsDir))
+	}
+
 	if err := dircopy.Copy(dir, out); err != nil {
 		return fmt.Errorf("failed to copy unpacked content to output directory: %s", err)
 	}
 
 	return nil
 }
+
+// copyCSV copies the ClusterServiceVersion manifest found in manifestsDir to outDir.
+func copyCSV(manifestsDir, outDir string) error {
+	entries, err := os.ReadDir(manifestsDir)
+	if err != nil {
+		return fmt.Errorf("error reading bundle manifests directory: %v", err)
+	}
+
+	var csvFile string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		isCSV, err := isCSVManifest(filepath.Join(manifestsDir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("error reading bundle manifest %q: %v", e.Name(), err)
+		}
+		if !isCSV {
+			continue
+		}
+		if csvFile != "" {
+			return fmt.Errorf("more than one ClusterServiceVersion is found in bundle")
+		}
+		csvFile = e.Name()
+	}
+	if csvFile == "" {
+		return fmt.Errorf("no ClusterServiceVersion is found in bundle")
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+	if err := dircopy.Copy(filepath.Join(manifestsDir, csvFile), filepath.Join(outDir, csvFile)); err != nil {
+		return fmt.Errorf("failed to copy ClusterServiceVersion to output directory: %s", err)
+	}
+	return nil
+}
+
+// isCSVManifest reports whether the manifest at path is a ClusterServiceVersion.
+// Manifests that can not be decoded are reported as errors rather than being
+// skipped, so that a corrupt CSV is not mistaken for a missing one.
+func isCSVManifest(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	var typeMeta metav1.TypeMeta
+	if err := yaml.NewYAMLOrJSONDecoder(f, 30).Decode(&typeMeta); err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	return typeMeta.Kind == operators.ClusterServiceVersionKind, nil
+}
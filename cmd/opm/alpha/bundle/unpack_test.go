@@ -0,0 +1,90 @@
+package bundle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCSVManifest = `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: etcdoperator.v0.9.4
+`
+	testCRDManifest = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: etcdclusters.etcd.database.coreos.com
+`
+)
+
+func TestCopyCSV(t *testing.T) {
+	type spec struct {
+		name        string
+		manifests   map[string]string
+		expectedCSV string
+		expectErr   string
+	}
+
+	specs := []spec{
+		{
+			name: "Success/OneCSV",
+			manifests: map[string]string{
+				"etcdoperator.clusterserviceversion.yaml": testCSVManifest,
+				"etcdclusters.crd.yaml":                   testCRDManifest,
+			},
+			expectedCSV: "etcdoperator.clusterserviceversion.yaml",
+		},
+		{
+			name: "Error/NoCSV",
+			manifests: map[string]string{
+				"etcdclusters.crd.yaml": testCRDManifest,
+			},
+			expectErr: "no ClusterServiceVersion is found in bundle",
+		},
+		{
+			name: "Error/MultipleCSVs",
+			manifests: map[string]string{
+				"a.clusterserviceversion.yaml": testCSVManifest,
+				"b.clusterserviceversion.yaml": testCSVManifest,
+			},
+			expectErr: "more than one ClusterServiceVersion is found in bundle",
+		},
+		{
+			name: "Error/CorruptManifest",
+			manifests: map[string]string{
+				"etcdoperator.clusterserviceversion.yaml": "kind: [ClusterServiceVersion",
+			},
+			expectErr: `error reading bundle manifest "etcdoperator.clusterserviceversion.yaml"`,
+		},
+	}
+
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			manifestsDir := t.TempDir()
+			for name, content := range s.manifests {
+				require.NoError(t, os.WriteFile(filepath.Join(manifestsDir, name), []byte(content), 0600))
+			}
+			outDir := filepath.Join(t.TempDir(), "manifests")
+
+			err := copyCSV(manifestsDir, outDir)
+			if s.expectErr != "" {
+				require.ErrorContains(t, err, s.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			entries, err := os.ReadDir(outDir)
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			require.Equal(t, s.expectedCSV, entries[0].Name())
+
+			actual, err := os.ReadFile(filepath.Join(outDir, s.expectedCSV))
+			require.NoError(t, err)
+			require.Equal(t, s.manifests[s.expectedCSV], string(actual))
+		})
+	}
+}
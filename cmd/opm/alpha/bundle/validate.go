@@ -1,6 +1,7 @@
 package bundle
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -16,7 +17,8 @@ import (
 )
 
 var (
-	optional string
+	optional  string
+	externals []string
 )
 
 func newBundleValidateCmd() *cobra.Command {
@@ -29,11 +31,22 @@ Required validators. These validators will run by default on every invocation of
  * CSV validator - validates the CSV name and replaces fields.
  * CRD validator - validates the CRDs OpenAPI V3 schema. 
  * Bundle validator - validates the bundle format and annotations.yaml file as well as the optional dependencies.yaml file. 
+   It also checks that the CSV supports an install mode matching its olm.targetNamespaces annotation, that the service
+   accounts of its deployments are created, and that its webhooks are served by its deployments on valid ports.
 
-Optional validators. These validators are disabled by default and can be enabled via the --optional-validators flag. 
- * Operatorhub validator - performs operatorhub.io validation. To validate a bundle using custom categories use with the OPERATOR_BUNDLE_CATEGORIES environmental variable to point to a json-encoded categories file.
+Optional validators. These validators are disabled by default and can be enabled via the --validators flag. 
+ * Operatorhub validator - performs operatorhub.io validation: checks the provider, maintainer emails, links, capability 
+   level and categories annotations, icon media type and size, and description lengths of the CSV. To validate a bundle 
+   using custom categories use with the OPERATOR_BUNDLE_CATEGORIES environmental variable to point to a json-encoded categories file.
  * Bundle objects validator - performs validation on resources like PodDisruptionBudgets and PriorityClasses. 
 
+External validators. Executables passed with the --external flag validate the unpacked bundle, and their errors are
+reported along with those of the other validators. Each executable reads a JSON request from its standard input:
+  {"protocolVersion": "v1alpha1", "bundleDir": "...", "manifestsDir": "...", "metadataDir": "..."}
+and writes the issues it finds in the bundle as a JSON response to its standard output:
+  {"results": [{"name": "...", "errors": [{"type": "...", "field": "...", "badValue": ..., "detail": "..."}], "warnings": [...]}]}
+A non-zero exit status reports that the executable failed to validate the bundle.
+
 See https://olm.operatorframework.io/docs/tasks/validate-package/#validation for more info.
 
 Note that this subcommand is deprecated and will be removed in a future release. Migrate to operator-sdk bundle validate.`,
@@ -50,7 +63,13 @@ Note that this subcommand is deprecated and will be removed in a future release.
 	}
 
 	bundleValidateCmd.Flags().StringVarP(&containerTool, "image-builder", "b", "docker", "Tool used to pull and unpack bundle images. One of: [none, docker, podman]")
+	bundleValidateCmd.Flags().StringVar(&optional, "validators", "", "Specifies optional validations to be run. One or more of: [operatorhub, bundle-objects]")
 	bundleValidateCmd.Flags().StringVarP(&optional, "optional-validators", "o", "", "Specifies optional validations to be run. One or more of: [operatorhub, bundle-objects]")
+	if err := bundleValidateCmd.Flags().MarkDeprecated("optional-validators", "use --validators instead"); err != nil {
+		log.Fatalf("Failed to mark `optional-validators` flag for `validate` subcommand as deprecated")
+	}
+	bundleValidateCmd.MarkFlagsMutuallyExclusive("validators", "optional-validators")
+	bundleValidateCmd.Flags().StringArrayVar(&externals, "external", nil, "Path of an executable that validates the unpacked bundle. Can be specified multiple times")
 
 	return bundleValidateCmd
 }
@@ -103,7 +122,14 @@ func validateFunc(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	err = imageValidator.ValidateBundleContent(filepath.Join(dir, bundle.ManifestsDir))
+	var validators []bundle.ExternalValidator
+	for _, path := range externals {
+		validators = append(validators, bundle.ExecValidator{Path: path})
+	}
+	err = mergeValidationErrors(
+		imageValidator.ValidateBundleContent(filepath.Join(dir, bundle.ManifestsDir)),
+		bundle.ValidateExternal(cmd.Context(), logger, dir, validators...),
+	)
 	if err != nil {
 		return err
 	}
@@ -112,3 +138,23 @@ func validateFunc(cmd *cobra.Command, _ []string) error {
 
 	return nil
 }
+
+// mergeValidationErrors aggregates the bundle.ValidationErrors of errs into a
+// single report. Any other error is returned as is.
+func mergeValidationErrors(errs ...error) error {
+	var merged []error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var verr bundle.ValidationError
+		if !errors.As(err, &verr) {
+			return err
+		}
+		merged = append(merged, verr.Errors...)
+	}
+	if len(merged) > 0 {
+		return bundle.NewValidationError(merged)
+	}
+	return nil
+}
@@ -3,11 +3,28 @@ package alpha
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/operator-framework/operator-registry/cmd/opm/alpha/add"
+	buildcatalog "github.com/operator-framework/operator-registry/cmd/opm/alpha/build-catalog"
 	"github.com/operator-framework/operator-registry/cmd/opm/alpha/bundle"
 	converttemplate "github.com/operator-framework/operator-registry/cmd/opm/alpha/convert-template"
+	deprecatetruncate "github.com/operator-framework/operator-registry/cmd/opm/alpha/deprecate-truncate"
+	"github.com/operator-framework/operator-registry/cmd/opm/alpha/dump"
+	format "github.com/operator-framework/operator-registry/cmd/opm/alpha/fmt"
 	"github.com/operator-framework/operator-registry/cmd/opm/alpha/list"
+	"github.com/operator-framework/operator-registry/cmd/opm/alpha/merge"
+	"github.com/operator-framework/operator-registry/cmd/opm/alpha/pin"
+	"github.com/operator-framework/operator-registry/cmd/opm/alpha/prune"
+	prunestranded "github.com/operator-framework/operator-registry/cmd/opm/alpha/prune-stranded"
+	"github.com/operator-framework/operator-registry/cmd/opm/alpha/push"
 	rendergraph "github.com/operator-framework/operator-registry/cmd/opm/alpha/render-graph"
+	"github.com/operator-framework/operator-registry/cmd/opm/alpha/resolve"
+	"github.com/operator-framework/operator-registry/cmd/opm/alpha/rm"
+	"github.com/operator-framework/operator-registry/cmd/opm/alpha/schema"
+	"github.com/operator-framework/operator-registry/cmd/opm/alpha/stats"
 	"github.com/operator-framework/operator-registry/cmd/opm/alpha/template"
+	"github.com/operator-framework/operator-registry/cmd/opm/alpha/truncate"
+	validateapis "github.com/operator-framework/operator-registry/cmd/opm/alpha/validate-apis"
+	verifyimage "github.com/operator-framework/operator-registry/cmd/opm/alpha/verify-image"
 )
 
 func NewCmd(showAlphaHelp bool) *cobra.Command {
@@ -28,6 +45,23 @@ func NewCmd(showAlphaHelp bool) *cobra.Command {
 		rendergraph.NewCmd(),
 		template.NewCmd(),
 		converttemplate.NewCmd(),
+		verifyimage.NewCmd(),
+		add.NewCmd(),
+		rm.NewCmd(),
+		prune.NewCmd(),
+		prunestranded.NewCmd(),
+		truncate.NewCmd(),
+		deprecatetruncate.NewCmd(),
+		merge.NewCmd(),
+		resolve.NewCmd(),
+		buildcatalog.NewCmd(),
+		push.NewCmd(),
+		pin.NewCmd(),
+		validateapis.NewCmd(),
+		schema.NewCmd(),
+		format.NewCmd(),
+		stats.NewCmd(),
+		dump.NewCmd(),
 	)
 	return runCmd
 }
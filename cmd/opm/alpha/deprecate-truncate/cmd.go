@@ -0,0 +1,60 @@
+package deprecatetruncate
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+)
+
+func NewCmd() *cobra.Command {
+	var deprecate action.DeprecateTruncate
+	logger := logrus.New()
+
+	cmd := &cobra.Command{
+		Use:   "deprecate-truncate <catalogDir>",
+		Short: "Deprecate bundles of a file-based catalog directory and truncate the upgrade graph below them",
+		Long: `Deprecate bundles of a file-based catalog directory and truncate the upgrade graph below them.
+
+Each bundle given by --bundles, either by name or by image, is given an
+olm.deprecated property. The entries it transitively replaces or skips are
+removed from each of its channels, unless another entry still upgrades from
+them, and bundles that are no longer in any channel are removed from the
+catalog. The deprecated bundles and the removed bundles are printed.
+
+Deprecating the head of a package's default channel is not allowed unless
+--allow-package-removal is set and the heads of all of the package's channels
+are deprecated, in which case the package is removed from the catalog.
+
+The resulting catalog is validated before any files are written.
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			deprecate.CatalogDir = args[0]
+			result, err := deprecate.Run(cmd.Context())
+			if err != nil {
+				logger.Fatal(err)
+			}
+			out := cmd.OutOrStdout()
+			for _, pkg := range result.RemovedPackages {
+				fmt.Fprintf(out, "Removed package %q\n", pkg)
+			}
+			fmt.Fprintln(out, "Deprecated bundles:")
+			if err := action.WriteBundles(out, result.Deprecated); err != nil {
+				logger.Fatal(err)
+			}
+			fmt.Fprintln(out, "\nRemoved bundles:")
+			if err := action.WriteBundles(out, result.Removed); err != nil {
+				logger.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().StringSliceVarP(&deprecate.Bundles, "bundles", "b", nil, "comma separated list of the names or images of the bundles to deprecate")
+	cmd.Flags().BoolVar(&deprecate.AllowPackageRemoval, "allow-package-removal", false, "remove the package if the heads of all of its channels are deprecated")
+	if err := cmd.MarkFlagRequired("bundles"); err != nil {
+		logger.Panic(err)
+	}
+	return cmd
+}
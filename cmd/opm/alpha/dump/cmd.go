@@ -0,0 +1,73 @@
+package dump
+
+import (
+	"log"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/client"
+)
+
+func NewCmd() *cobra.Command {
+	var (
+		dump   action.Dump
+		addr   string
+		output string
+	)
+	cmd := &cobra.Command{
+		Use:   "dump <outputDir>",
+		Short: "Dump the catalog served by a registry to a file-based catalog",
+		Long: `Dump the catalog served by a registry to a file-based catalog.
+
+The catalog is reconstructed from the packages and bundles that the registry
+API of the server at --addr lists, so it works with any running registry,
+whether it serves a file-based catalog or a sqlite database, e.g. to back up or
+inspect a live catalog.
+
+The registry API does not serve everything that a file-based catalog holds, so
+the dumped catalog is equivalent to the served one rather than identical: it
+has no package descriptions or unrecognized schemas, and bundles that have an
+image have no olm.bundle.object or olm.csv.metadata properties, which are
+rendered from the image when needed.`,
+		Example: `# Dump the catalog served on localhost:50051 as YAML
+opm alpha dump --addr localhost:50051 -o yaml ./catalog`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			dump.OutputDir = args[0]
+
+			switch output {
+			case "yaml":
+				dump.WriteFunc = declcfg.WriteYAML
+				dump.FileExt = ".yaml"
+			case "yaml-canonical":
+				dump.WriteFunc = declcfg.WriteYAMLCanonical
+				dump.FileExt = ".yaml"
+			case "json":
+				dump.WriteFunc = declcfg.WriteJSON
+				dump.FileExt = ".json"
+			default:
+				log.Fatalf("invalid --output value %q, expected (json|yaml|yaml-canonical)", output)
+			}
+
+			c, err := client.NewClient(addr, client.WithRetries(3))
+			if err != nil {
+				log.Fatalf("connect to %q: %v", addr, err)
+			}
+			defer c.Close()
+			dump.Registry = c.Registry
+
+			logrus.Infof("dumping catalog served on %q as file-based catalog", addr)
+			if err := dump.Run(cmd.Context()); err != nil {
+				logrus.New().Fatal(err)
+			}
+			logrus.Infof("wrote dumped file-based catalog to %q", dump.OutputDir)
+		},
+	}
+	cmd.Flags().StringVar(&addr, "addr", "", "Address of the registry gRPC server, as host:port")
+	cmd.Flags().StringVarP(&output, "output", "o", "json", "Output format (json|yaml|yaml-canonical)")
+	_ = cmd.MarkFlagRequired("addr")
+	return cmd
+}
@@ -0,0 +1,66 @@
+package format
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+)
+
+func NewCmd() *cobra.Command {
+	logger := logrus.New()
+	var (
+		list  bool
+		check bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "fmt <catalogDir>",
+		Short: "Rewrite the files of a file-based catalog in canonical form",
+		Long: `Rewrite the files of a file-based catalog in canonical form, so that the git
+diffs of the catalog only show changes to its content.
+
+The blobs of each file are ordered by package, with each package followed by
+its icon, channels, bundles and other blobs, and channels and bundles sorted by
+name. Properties are sorted by type and value, with olm.bundle.object
+properties last, channel entries and their skips by name, related images by
+image, and deprecation entries by reference.
+
+Each file is rewritten in place, in its format, and only if its content
+changes. With --check, no file is rewritten: the files that are not in
+canonical form are listed, and the command fails if there are any.
+`,
+		Example: `
+#
+# Format a catalog
+#
+$ opm alpha fmt ./catalog
+
+#
+# Fail if a catalog is not formatted, for example in CI
+#
+$ opm alpha fmt --check ./catalog
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			f := action.Fmt{CatalogDir: args[0], DryRun: check}
+			files, err := f.Run()
+			if err != nil {
+				logger.Fatal(err)
+			}
+			if list || check {
+				for _, file := range files {
+					fmt.Println(file)
+				}
+			}
+			if check && len(files) > 0 {
+				logger.Fatalf("%d file(s) of catalog %q are not in canonical form", len(files), args[0])
+			}
+		},
+	}
+	cmd.Flags().BoolVarP(&list, "list", "l", false, "list the files that are rewritten")
+	cmd.Flags().BoolVar(&check, "check", false, "list the files that are not in canonical form, without rewriting them, and fail if there are any")
+	return cmd
+}
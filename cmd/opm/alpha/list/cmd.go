@@ -1,6 +1,8 @@
 package list
 
 import (
+	"bytes"
+	"errors"
 	"os"
 
 	"github.com/sirupsen/logrus"
@@ -8,6 +10,7 @@ import (
 
 	"github.com/operator-framework/operator-registry/alpha/action"
 	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+	"github.com/operator-framework/operator-registry/pkg/mirror"
 )
 
 const humanReadabilityOnlyNote = `NOTE: This is meant to be used for convenience and human-readability only. The
@@ -24,7 +27,7 @@ func NewCmd() *cobra.Command {
 ` + humanReadabilityOnlyNote,
 	}
 
-	list.AddCommand(newPackagesCmd(), newChannelsCmd(), newBundlesCmd())
+	list.AddCommand(newPackagesCmd(), newChannelsCmd(), newBundlesCmd(), newImagesCmd())
 	return list
 }
 
@@ -128,3 +131,99 @@ for each channel in which the bundle is present).
 		},
 	}
 }
+
+func newImagesCmd() *cobra.Command {
+	logger := logrus.New()
+
+	var (
+		mirrorMapStrs []string
+		policyName    string
+		icspFile      string
+		idmsFile      string
+		catalogDir    string
+	)
+	cmd := &cobra.Command{
+		Use:   "images <indexRef> [packageName]",
+		Short: "List bundle and related images in an index",
+		Long: `The "images" command lists the bundle images and related images of the
+bundles from the specified index and package.
+
+With --mirror-map, the mirror of each image is listed too, and the command can
+write an ImageContentSourcePolicy (--icsp-file) or an ImageDigestMirrorSet
+(--idms-file) that mirrors the repositories of the images, in the format of
+"oc adm catalog mirror", and a copy of the index with the images replaced by
+their mirrors (--mirrored-catalog-dir). Mirror mappings have the form
+<source>=<mirror>, and match whole path components of the images' repositories.
+
+` + humanReadabilityOnlyNote,
+		Example: `
+#
+# List the images of an index, with their mirrors, and generate an
+# ImageDigestMirrorSet and a mirrored copy of the index
+#
+$ opm alpha list images ./catalog --mirror-map quay.io/example=mirror.example.com/example \
+    --idms-file idms.yaml --mirrored-catalog-dir ./mirrored-catalog
+`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mirrorMap, err := mirror.ParseMappings(mirrorMapStrs)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			if len(mirrorMap) == 0 && (icspFile != "" || idmsFile != "" || catalogDir != "") {
+				logger.Fatal(errors.New("--icsp-file, --idms-file and --mirrored-catalog-dir require --mirror-map"))
+			}
+
+			reg, err := util.CreateCLIRegistry(cmd)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			defer func() {
+				_ = reg.Destroy()
+			}()
+			li := action.ListImages{IndexReference: args[0], MirrorMap: mirrorMap, Registry: reg}
+			if len(args) > 1 {
+				li.PackageName = args[1]
+			}
+			res, err := li.Run(cmd.Context())
+			if err != nil {
+				logger.Fatal(err)
+			}
+			if err := res.WriteColumns(os.Stdout); err != nil {
+				logger.Fatal(err)
+			}
+
+			if icspFile != "" {
+				buf := &bytes.Buffer{}
+				if err := res.WriteICSP(buf, policyName); err != nil {
+					logger.Fatal(err)
+				}
+				if err := os.WriteFile(icspFile, buf.Bytes(), 0600); err != nil {
+					logger.Fatal(err)
+				}
+			}
+			if idmsFile != "" {
+				buf := &bytes.Buffer{}
+				if err := res.WriteIDMS(buf, policyName); err != nil {
+					logger.Fatal(err)
+				}
+				if err := os.WriteFile(idmsFile, buf.Bytes(), 0600); err != nil {
+					logger.Fatal(err)
+				}
+			}
+			if catalogDir != "" {
+				mc := action.MirrorCatalog{IndexReference: args[0], MirrorMap: mirrorMap, OutputDir: catalogDir, Registry: reg}
+				if err := mc.Run(cmd.Context()); err != nil {
+					logger.Fatal(err)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&mirrorMapStrs, "mirror-map", nil, "mapping of a source registry or repository to its mirror, of the form <source>=<mirror>")
+	cmd.Flags().StringVar(&policyName, "mirror-policy-name", "operator-catalog", "name of the generated ImageContentSourcePolicy and ImageDigestMirrorSet")
+	cmd.Flags().StringVar(&icspFile, "icsp-file", "", "path of the ImageContentSourcePolicy to write")
+	cmd.Flags().StringVar(&idmsFile, "idms-file", "", "path of the ImageDigestMirrorSet to write")
+	cmd.Flags().StringVar(&catalogDir, "mirrored-catalog-dir", "", "directory to write a copy of the index with mirrored image references to")
+	return cmd
+}
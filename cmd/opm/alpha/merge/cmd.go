@@ -0,0 +1,82 @@
+package merge
+
+import (
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+)
+
+func NewCmd() *cobra.Command {
+	var (
+		merge    action.Merge
+		strategy string
+		output   string
+	)
+	logger := logrus.New()
+
+	cmd := &cobra.Command{
+		Use:   "merge <catalogRef> <catalogRef>...",
+		Short: "Merge catalogs into a single file-based catalog",
+		Long: `Merge catalogs into a single file-based catalog and write it to stdout.
+
+Each catalog reference may be a sqlite-based index image or database file, or
+a file-based catalog image or directory. Each package in the merged catalog
+is taken in its entirety from one of the catalogs. If a package is defined in
+more than one catalog, --strategy determines which catalog it is taken from:
+
+  error          fail the merge (default)
+  prefer-first   use the first catalog, in the order given, that defines it
+  prefer-newest  use the catalog that contains the highest bundle version of
+                 the package, preferring earlier catalogs on ties
+
+The merged catalog is validated, and its output is sorted so that it does not
+depend on the order in which catalog content was loaded.
+`,
+		Args: cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			merge.Refs = args
+			merge.Strategy = action.MergeStrategy(strategy)
+
+			var write func(declcfg.DeclarativeConfig, io.Writer) error
+			switch output {
+			case "yaml":
+				write = declcfg.WriteYAML
+			case "json":
+				write = declcfg.WriteJSON
+			default:
+				logger.Fatalf("invalid --output value %q, expected (json|yaml)", output)
+			}
+
+			// The catalog loading impl is somewhat verbose, even on the happy path,
+			// so discard all logrus default logger logs. Any important failures will be
+			// returned from merge.Run and logged as fatal errors.
+			logrus.SetOutput(io.Discard)
+
+			reg, err := util.CreateCLIRegistry(cmd)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			defer func() {
+				_ = reg.Destroy()
+			}()
+			merge.Registry = reg
+
+			cfg, err := merge.Run(cmd.Context())
+			if err != nil {
+				logger.Fatal(err)
+			}
+			if err := write(*cfg, os.Stdout); err != nil {
+				logger.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&strategy, "strategy", string(action.MergeStrategyError), "how to resolve packages defined in more than one catalog (error|prefer-first|prefer-newest)")
+	cmd.Flags().StringVarP(&output, "output", "o", "json", "Output format (json|yaml)")
+	return cmd
+}
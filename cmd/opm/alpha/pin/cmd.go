@@ -0,0 +1,44 @@
+package pin
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+)
+
+func NewCmd() *cobra.Command {
+	logger := logrus.New()
+
+	cmd := &cobra.Command{
+		Use:   "pin <catalogDir>",
+		Short: "Pin the images of a file-based catalog to their digests",
+		Long: `Replace the tag-based bundle images and related images of a file-based
+catalog with references by digest, as required to mirror the catalog for
+disconnected installs. The digests are resolved by querying the images'
+registries, with the credentials used by podman and docker.
+
+The catalog's files are rewritten in place. Files without tag-based references
+are left untouched.
+`,
+		Example: `
+#
+# Pin the images of a catalog
+#
+$ opm alpha pin ./catalog
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			skipTLSVerify, useHTTP, err := util.GetTLSOptions(cmd)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			pin := action.Pin{CatalogDir: args[0], SkipTLSVerify: skipTLSVerify || useHTTP}
+			if err := pin.Run(cmd.Context()); err != nil {
+				logger.Fatal(err)
+			}
+		},
+	}
+	return cmd
+}
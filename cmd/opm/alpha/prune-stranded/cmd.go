@@ -0,0 +1,41 @@
+package prunestranded
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+)
+
+func NewCmd() *cobra.Command {
+	var prune action.PruneStranded
+	logger := logrus.New()
+
+	cmd := &cobra.Command{
+		Use:   "prune-stranded <catalogDir>",
+		Short: "Remove stranded bundles from a file-based catalog directory",
+		Long: `Remove stranded bundles from a file-based catalog directory.
+
+A bundle is stranded if it is not an entry of any channel of its package, so
+it can never be installed or upgraded to. Stranded bundles are removed along
+with any deprecation entries that reference them, and a table of the removed
+bundles is printed.
+
+If --dry-run is set, the stranded bundles are printed but the catalog is not
+modified.
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			prune.CatalogDir = args[0]
+			stranded, err := prune.Run(cmd.Context())
+			if err != nil {
+				logger.Fatal(err)
+			}
+			if err := action.WriteBundles(cmd.OutOrStdout(), stranded); err != nil {
+				logger.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&prune.DryRun, "dry-run", false, "print the stranded bundles without removing them")
+	return cmd
+}
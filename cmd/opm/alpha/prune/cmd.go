@@ -0,0 +1,131 @@
+package prune
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+)
+
+func NewCmd() *cobra.Command {
+	var (
+		prune    action.Prune
+		packages []string
+		output   string
+	)
+	logger := logrus.New()
+
+	cmd := &cobra.Command{
+		Use:   "prune <indexRef> <outputDir>",
+		Short: "Write a file-based catalog containing only the selected packages",
+		Long: `Write a file-based catalog containing only the selected packages.
+
+The index reference may be a sqlite-based index image or database file, or a
+file-based catalog image or directory. Each --package flag selects content to
+keep using the format:
+
+  <package>[:<channel>[,<channel>...]][@<versionRange>]
+
+If no channels are given, all channels of the package are kept. If a version
+range is given, only bundles whose version is in the range are kept, and the
+upgrade edges of the remaining channel entries are updated accordingly.
+
+If --include-dependencies is set (the default), the olm.package.required and
+olm.gvk.required properties of the kept bundles are resolved, and bundles that
+satisfy them are added along with the channels that contain them, so that the
+resulting catalog remains installable. Bundles in their package's default
+channel are preferred, followed by the highest version.
+
+If a package's default channel is not kept, the first remaining channel (in
+lexical order) becomes its default channel.
+
+The resulting catalog is validated and written to <outputDir>, which must be
+empty or not exist.
+
+NOTE: the --output=json format produces streamable, concatenated JSON files.
+These are suitable to opm and jq, but may not be supported by arbitrary JSON
+parsers that assume that a file contains exactly one valid JSON object.
+`,
+		Example: `  opm alpha prune quay.io/example/index:latest ./catalog --package foo --package bar:stable@">=1.2.0"`,
+		Args:    cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			prune.CatalogRef = args[0]
+			prune.OutputDir = args[1]
+
+			switch output {
+			case "yaml":
+				prune.WriteFunc = declcfg.WriteYAML
+				prune.FileExt = ".yaml"
+			case "json":
+				prune.WriteFunc = declcfg.WriteJSON
+				prune.FileExt = ".json"
+			default:
+				logger.Fatalf("invalid --output value %q, expected (json|yaml)", output)
+			}
+
+			for _, p := range packages {
+				f, err := parsePackageFilter(p)
+				if err != nil {
+					logger.Fatal(err)
+				}
+				prune.Packages = append(prune.Packages, f)
+			}
+
+			// The catalog loading impl is somewhat verbose, even on the happy path,
+			// so discard all logrus default logger logs. Any important failures will be
+			// returned from prune.Run and logged as fatal errors.
+			logrus.SetOutput(io.Discard)
+
+			reg, err := util.CreateCLIRegistry(cmd)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			defer func() {
+				_ = reg.Destroy()
+			}()
+			prune.Registry = reg
+
+			if err := prune.Run(cmd.Context()); err != nil {
+				logger.Fatal(err)
+			}
+			logger.Infof("wrote pruned file-based catalog to %q", prune.OutputDir)
+		},
+	}
+	cmd.Flags().StringArrayVarP(&packages, "package", "p", nil, "package to keep, as <package>[:<channel>[,<channel>...]][@<versionRange>] (may be repeated)")
+	cmd.Flags().BoolVar(&prune.IncludeDependencies, "include-dependencies", true, "add bundles that satisfy the package and GVK dependencies of the kept bundles")
+	cmd.Flags().StringVarP(&output, "output", "o", "json", "Output format (json|yaml)")
+	_ = cmd.MarkFlagRequired("package")
+	return cmd
+}
+
+func parsePackageFilter(s string) (action.PackageFilter, error) {
+	var f action.PackageFilter
+	ref := s
+	if i := strings.Index(ref, "@"); i >= 0 {
+		f.VersionRange = ref[i+1:]
+		ref = ref[:i]
+		if f.VersionRange == "" {
+			return f, fmt.Errorf("invalid package %q: version range must not be empty", s)
+		}
+	}
+	name, channels, hasChannels := strings.Cut(ref, ":")
+	if name == "" {
+		return f, fmt.Errorf("invalid package %q: package name must not be empty", s)
+	}
+	f.Name = name
+	if hasChannels {
+		for _, ch := range strings.Split(channels, ",") {
+			if ch == "" {
+				return f, fmt.Errorf("invalid package %q: channel names must not be empty", s)
+			}
+			f.Channels = append(f.Channels, ch)
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,72 @@
+package push
+
+import (
+	"io"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+)
+
+func NewCmd() *cobra.Command {
+	logger := logrus.New()
+
+	cmd := &cobra.Command{
+		Use:   "push <artifactRef> <ref>...",
+		Short: "Push a file-based catalog to a registry as an OCI artifact",
+		Long: `Render catalogs and bundles into a file-based catalog, and push it to a
+registry as an OCI artifact rather than as an image.
+
+The artifact has the artifact type ` + action.ArtifactType + `, an
+empty config, and one layer per schema with the media type
+application/vnd.operatorframework.fbc.<schema>.v1+json. Blobs of schemas other
+than the olm.* schemas are stored in the
+application/vnd.operatorframework.fbc.meta.v1+json layer. Registry credentials
+are read from the docker config file.
+
+Artifacts are rendered by prefixing their reference with ` + action.ArtifactRefPrefix + `.
+`,
+		Example: `
+#
+# Push a catalog directory as an artifact, and render it
+#
+$ opm alpha push quay.io/example/catalog:latest ./catalog
+$ opm render ` + action.ArtifactRefPrefix + `quay.io/example/catalog:latest
+`,
+		Args: cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			// The bundle loading impl is somewhat verbose, even on the happy path,
+			// so discard all logrus default logger logs. Any important failures will be
+			// returned from push.Run and logged as fatal errors.
+			logrus.SetOutput(io.Discard)
+
+			skipTLSVerify, useHTTP, err := util.GetTLSOptions(cmd)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			reg, err := util.CreateCLIRegistry(cmd)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			defer func() {
+				_ = reg.Destroy()
+			}()
+
+			push := action.PushArtifact{
+				Refs:          args[1:],
+				Reference:     args[0],
+				Registry:      reg,
+				SkipTLSVerify: skipTLSVerify,
+				PlainHTTP:     useHTTP,
+			}
+			desc, err := push.Run(cmd.Context())
+			if err != nil {
+				logger.Fatal(err)
+			}
+			logger.Infof("pushed %s@%s", args[0], desc.Digest)
+		},
+	}
+	return cmd
+}
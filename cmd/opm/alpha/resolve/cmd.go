@@ -0,0 +1,67 @@
+package resolve
+
+import (
+	"io"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+)
+
+func NewCmd() *cobra.Command {
+	logger := logrus.New()
+
+	cmd := &cobra.Command{
+		Use:   "resolve <indexRef> <package>[:<channel>]",
+		Short: "Resolve the dependencies of a package in an index",
+		Long: `Resolve the dependencies of a package in an index, and print the bundles that
+an installation of the package would need.
+
+The head of the package's channel, or of its default channel if no channel is
+specified, is resolved along with its olm.package.required, olm.gvk.required and
+olm.constraint requirements, which are in turn resolved against the other
+packages of the index. The command fails if a requirement cannot be satisfied,
+so that unsatisfiable dependencies are caught when the index is built.
+`,
+		Example: `
+#
+# Resolve the dependencies of the default channel of the etcd package
+#
+$ opm alpha resolve ./catalog etcd
+
+#
+# Resolve the dependencies of the stable channel of the etcd package
+#
+$ opm alpha resolve quay.io/operatorhubio/catalog:latest etcd:stable
+`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			// The bundle loading impl is somewhat verbose, even on the happy path,
+			// so discard all logrus default logger logs. Any important failures will be
+			// returned from resolve.Run and logged as fatal errors.
+			logrus.SetOutput(io.Discard)
+
+			reg, err := util.CreateCLIRegistry(cmd)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			defer func() {
+				_ = reg.Destroy()
+			}()
+
+			resolve := action.Resolve{IndexReference: args[0], Registry: reg}
+			resolve.PackageName, resolve.ChannelName, _ = strings.Cut(args[1], ":")
+			bundles, err := resolve.Run(cmd.Context())
+			if err != nil {
+				logger.Fatal(err)
+			}
+			if err := action.WriteBundles(cmd.OutOrStdout(), bundles); err != nil {
+				logger.Fatal(err)
+			}
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,73 @@
+package rm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+)
+
+func NewCmd() *cobra.Command {
+	var rm action.Remove
+	logger := logrus.New()
+
+	cmd := &cobra.Command{
+		Use:   "rm <catalogDir> <package>[:<channel>][:<bundle>]",
+		Short: "Remove a package, channel, or bundle from a file-based catalog directory",
+		Long: `Remove a package, channel, or bundle from a file-based catalog directory.
+
+  <package>                    removes the package and all of its content
+  <package>:<channel>          removes the channel, and any bundles that are not
+                               in another channel of the package
+  <package>:<channel>:<bundle> removes the bundle from the channel
+  <package>::<bundle>          removes the bundle from every channel of the package
+
+When a bundle is removed from a channel, entries that replaced it are updated
+to replace the bundle it replaced, and the bundle is removed from the catalog
+if it is no longer in any channel. Deprecations that reference removed channels
+or bundles are removed as well.
+
+The resulting catalog is validated before any files are written. If it would
+be invalid, for example because the package's default channel was removed or a
+channel would be left without entries, nothing is removed unless --force is set.
+`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			rm.CatalogDir = args[0]
+			var err error
+			rm.Package, rm.Channel, rm.Bundle, err = parseRef(args[1])
+			if err != nil {
+				logger.Fatal(err)
+			}
+			if err := rm.Run(cmd.Context()); err != nil {
+				if errors.Is(err, action.ErrInvalidRemoval) {
+					logger.Fatalf("%v (use --force to remove anyway)", err)
+				}
+				logger.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&rm.Force, "force", false, "remove even if the resulting catalog is invalid")
+	return cmd
+}
+
+func parseRef(ref string) (string, string, string, error) {
+	parts := strings.Split(ref, ":")
+	if len(parts) > 3 || parts[0] == "" {
+		return "", "", "", fmt.Errorf("invalid reference %q: expected <package>[:<channel>][:<bundle>]", ref)
+	}
+	if len(parts) == 2 && parts[1] == "" {
+		return "", "", "", fmt.Errorf("invalid reference %q: channel name must not be empty", ref)
+	}
+	if len(parts) == 3 && parts[2] == "" {
+		return "", "", "", fmt.Errorf("invalid reference %q: bundle name must not be empty", ref)
+	}
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+	return parts[0], parts[1], parts[2], nil
+}
@@ -0,0 +1,84 @@
+package rm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRef(t *testing.T) {
+	type spec struct {
+		name            string
+		ref             string
+		expectedPackage string
+		expectedChannel string
+		expectedBundle  string
+		expectErr       string
+	}
+
+	specs := []spec{
+		{
+			name:            "Package",
+			ref:             "foo",
+			expectedPackage: "foo",
+		},
+		{
+			name:            "Channel",
+			ref:             "foo:stable",
+			expectedPackage: "foo",
+			expectedChannel: "stable",
+		},
+		{
+			name:            "BundleInChannel",
+			ref:             "foo:stable:foo.v0.1.0",
+			expectedPackage: "foo",
+			expectedChannel: "stable",
+			expectedBundle:  "foo.v0.1.0",
+		},
+		{
+			name:            "BundleInAllChannels",
+			ref:             "foo::foo.v0.1.0",
+			expectedPackage: "foo",
+			expectedBundle:  "foo.v0.1.0",
+		},
+		{
+			name:      "Error/EmptyPackage",
+			ref:       ":stable",
+			expectErr: "expected <package>[:<channel>][:<bundle>]",
+		},
+		{
+			name:      "Error/EmptyChannel",
+			ref:       "foo:",
+			expectErr: "channel name must not be empty",
+		},
+		{
+			name:      "Error/EmptyBundle",
+			ref:       "foo::",
+			expectErr: "bundle name must not be empty",
+		},
+		{
+			name:      "Error/EmptyBundleInChannel",
+			ref:       "foo:stable:",
+			expectErr: "bundle name must not be empty",
+		},
+		{
+			name:      "Error/TooManySegments",
+			ref:       "foo:stable:foo.v0.1.0:extra",
+			expectErr: "expected <package>[:<channel>][:<bundle>]",
+		},
+	}
+
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			pkg, channel, bundle, err := parseRef(s.ref)
+			if s.expectErr != "" {
+				require.ErrorContains(t, err, s.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, s.expectedPackage, pkg)
+			require.Equal(t, s.expectedChannel, channel)
+			require.Equal(t, s.expectedBundle, bundle)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Work with the JSON Schemas of file-based catalog blobs",
+		Long: `The schema subcommands publish the JSON Schemas of the blobs of file-based
+catalogs, for editors and CI systems to validate catalog files, and validate
+catalog files against them.
+
+The JSON Schemas check the structure of each blob on its own. They do not
+replace "opm validate", which also checks the catalog as a whole.`,
+		Args: cobra.NoArgs,
+	}
+	cmd.AddCommand(newDumpCmd(), newValidateCmd())
+	return cmd
+}
+
+func newDumpCmd() *cobra.Command {
+	logger := logrus.New()
+	var outputDir string
+
+	cmd := &cobra.Command{
+		Use:   "dump [<schema>...]",
+		Short: "Print the JSON Schemas of file-based catalog blobs",
+		Long: fmt.Sprintf(`Print the JSON Schema of the given file-based catalog schema, or, with
+--output-dir, write the JSON Schemas of the given schemas, or of all schemas if
+none are given, to files named "<schema>%s" in the directory.
+
+JSON Schemas are available for the schemas %v.`, declcfg.JSONSchemaExt, declcfg.JSONSchemas()),
+		Example: `
+#
+# Print the JSON Schema of olm.bundle blobs
+#
+$ opm alpha schema dump olm.bundle
+
+#
+# Write the JSON Schemas of all blobs to ./schemas
+#
+$ opm alpha schema dump --output-dir ./schemas
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if outputDir == "" {
+				if len(args) != 1 {
+					logger.Fatal("exactly one schema must be given unless --output-dir is set")
+				}
+				data, err := declcfg.JSONSchema(args[0])
+				if err != nil {
+					logger.Fatal(err)
+				}
+				if _, err := os.Stdout.Write(data); err != nil {
+					logger.Fatal(err)
+				}
+				return
+			}
+
+			schemas := args
+			if len(schemas) == 0 {
+				schemas = declcfg.JSONSchemas()
+			}
+			if err := os.MkdirAll(outputDir, 0777); err != nil {
+				logger.Fatal(err)
+			}
+			for _, schema := range schemas {
+				data, err := declcfg.JSONSchema(schema)
+				if err != nil {
+					logger.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(outputDir, schema+declcfg.JSONSchemaExt), data, 0666); err != nil {
+					logger.Fatal(err)
+				}
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&outputDir, "output-dir", "o", "", "directory to write the JSON Schemas to")
+	return cmd
+}
+
+func newValidateCmd() *cobra.Command {
+	logger := logrus.New()
+	var loadConcurrency int
+
+	cmd := &cobra.Command{
+		Use:   "validate <directory>",
+		Short: "Validate the blobs of a file-based catalog against their JSON Schemas",
+		Long: `Validate every blob of the file-based catalog in the given directory against
+the JSON Schema of its schema. Every invalid blob is reported with the line of
+its file where it starts and the paths of its invalid fields. Blobs of schemas
+without a JSON Schema are not validated.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			s, err := os.Stat(args[0])
+			if err != nil {
+				logger.Fatal(err)
+			}
+			if !s.IsDir() {
+				logger.Fatalf("%q is not a directory", args[0])
+			}
+			if err := declcfg.ValidateJSONSchemaFS(cmd.Context(), os.DirFS(args[0]), declcfg.WithConcurrency(loadConcurrency)); err != nil {
+				logger.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().IntVar(&loadConcurrency, "load-concurrency", 0, "number of catalog files parsed concurrently (default: number of CPUs)")
+	return cmd
+}
@@ -0,0 +1,76 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+func NewCmd() *cobra.Command {
+	var (
+		stats  action.CatalogStats
+		output string
+	)
+	logger := logrus.New()
+
+	cmd := &cobra.Command{
+		Use:   "stats <catalog-image | catalog-directory | sqlite-image | sqlite-file>",
+		Short: "Report the size of a catalog",
+		Long: `Report the number of packages, channels and bundles of a catalog, the size of
+the manifests of its bundles, its largest bundles, and how many of its packages,
+channels and bundles are deprecated, e.g. to plan the resources of the pods that
+serve it.
+
+The statistics are the ones reported by the GetCatalogStats method of the
+registry API of a server that serves the catalog.`,
+		Example: `# Report the statistics of a catalog image as JSON
+opm alpha stats quay.io/example/catalog:latest -o json`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			stats.CatalogRef = args[0]
+
+			// The catalog loading impl is somewhat verbose, even on the happy path,
+			// so discard all logrus default logger logs.
+			logrus.SetOutput(io.Discard)
+
+			reg, err := util.CreateCLIRegistry(cmd)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			defer func() {
+				_ = reg.Destroy()
+			}()
+			stats.Registry = reg
+
+			res, err := stats.Run(cmd.Context())
+			if err != nil {
+				logger.Fatal(err)
+			}
+			switch output {
+			case "table":
+				err = res.WriteColumns(os.Stdout)
+			case "json":
+				var b []byte
+				if b, err = (protojson.MarshalOptions{Multiline: true}).Marshal(res.Stats); err == nil {
+					_, err = fmt.Println(string(b))
+				}
+			default:
+				logger.Fatalf("invalid --output value %q, expected (table|json)", output)
+			}
+			if err != nil {
+				logger.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().IntVar(&stats.LargestBundles, "largest-bundles", registry.DefaultLargestBundles, "Number of largest bundles to report")
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format (table|json)")
+	return cmd
+}
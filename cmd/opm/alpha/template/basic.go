@@ -13,6 +13,7 @@ import (
 	"github.com/operator-framework/operator-registry/alpha/action/migrations"
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
 	"github.com/operator-framework/operator-registry/alpha/template/basic"
+	"github.com/operator-framework/operator-registry/alpha/template/helm"
 	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
 )
 
@@ -20,20 +21,41 @@ func newBasicTemplateCmd() *cobra.Command {
 	var (
 		template     basic.Template
 		migrateLevel string
+		valuesFile   string
+		setValues    []string
 	)
 	cmd := &cobra.Command{
 		Use: "basic basic-template-file",
 		Short: `Generate a file-based catalog from a single 'basic template' file
-When FILE is '-' or not provided, the template is read from standard input`,
+When FILE is '-' or not provided, the template is read from standard input,
+and when it is an http or https URL, the template is downloaded`,
 		Long: `Generate a file-based catalog from a single 'basic template' file
-When FILE is '-' or not provided, the template is read from standard input`,
+When FILE is '-' or not provided, the template is read from standard input,
+and when it is an http or https URL, the template is downloaded. A template
+in a git repository is read at a branch, tag or commit with a reference like
+git+https://github.com/example/catalog#templates/basic.yaml@v1.2.0
+
+Channel entry fields may refer to metadata of bundles rendered from the template
+with '{{ .Bundle "<image>" "<field>" }}', where field is one of name, package,
+image, version, or skipRange.
+
+With --values or --set, the string fields of the template are Go templates
+executed with the values available as '.Values', so that one template can
+produce the catalogs of several environments. The templates may use a subset
+of the Helm template functions (required, default, toYaml, toJson, quote,
+indent, nindent, trim, lower, upper). Values set with --set override those of
+the --values file.`,
 		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			// Handle different input argument types
 			// When no arguments or "-" is passed to the command,
 			// assume input is coming from stdin
-			// Otherwise open the file passed to the command
-			data, source, err := util.OpenFileOrStdin(cmd, args)
+			// Otherwise open the file or download the URL passed to the command
+			checksum, err := cmd.Flags().GetString("checksum")
+			if err != nil {
+				log.Fatalf("unable to determine checksum")
+			}
+			data, source, err := util.OpenFileStdinOrURL(cmd, args, checksum)
 			if err != nil {
 				log.Fatalf("unable to open %q: %v", source, err)
 			}
@@ -47,10 +69,32 @@ When FILE is '-' or not provided, the template is read from standard input`,
 			switch output {
 			case "yaml":
 				write = declcfg.WriteYAML
+			case "yaml-canonical":
+				write = declcfg.WriteYAMLCanonical
 			case "json":
 				write = declcfg.WriteJSON
 			default:
-				log.Fatalf("invalid --output value %q, expected (json|yaml)", output)
+				log.Fatalf("invalid --output value %q, expected (json|yaml|yaml-canonical)", output)
+			}
+
+			if valuesFile != "" || len(setValues) > 0 {
+				template.Values = map[string]interface{}{}
+			}
+			if valuesFile != "" {
+				f, err := os.Open(valuesFile)
+				if err != nil {
+					log.Fatalf("unable to open values %q: %v", valuesFile, err)
+				}
+				defer f.Close()
+				template.Values, err = helm.ReadValues(f)
+				if err != nil {
+					log.Fatalf("values %q: %v", valuesFile, err)
+				}
+			}
+			for _, v := range setValues {
+				if err := basic.SetValue(template.Values, v); err != nil {
+					log.Fatal(err)
+				}
 			}
 
 			// The bundle loading impl is somewhat verbose, even on the happy path,
@@ -97,6 +141,8 @@ When FILE is '-' or not provided, the template is read from standard input`,
 		},
 	}
 
+	cmd.Flags().StringVarP(&valuesFile, "values", "f", "", "Path to a values file made available to the template as '.Values'")
+	cmd.Flags().StringArrayVar(&setValues, "set", nil, "Set a value made available to the template as '.Values', as key=value (can be repeated; dots in keys separate nested keys)")
 	cmd.Flags().StringVar(&migrateLevel, "migrate-level", "", "Name of the last migration to run (default: none)\n"+migrations.HelpText())
 
 	return cmd
@@ -21,7 +21,11 @@ func NewCmd() *cobra.Command {
 	// sc.Hidden = true
 	runCmd.AddCommand(sc)
 
-	runCmd.PersistentFlags().StringVarP(&output, "output", "o", "json", "Output format (json|yaml)")
+	hc := newHelmTemplateCmd()
+	runCmd.AddCommand(hc)
+
+	runCmd.PersistentFlags().StringVarP(&output, "output", "o", "json", "Output format (json|yaml|yaml-canonical)")
+	runCmd.PersistentFlags().String("checksum", "", "Digest that the template must have, as <algorithm>:<hex> (e.g. sha256:<hex>), to verify templates downloaded from URLs")
 
 	return runCmd
 }
@@ -0,0 +1,136 @@
+package template
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/action/migrations"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/template/helm"
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+)
+
+func newHelmTemplateCmd() *cobra.Command {
+	var (
+		valuesFile   string
+		migrateLevel string
+	)
+
+	cmd := &cobra.Command{
+		Use: "helm [FILE]",
+		Short: `Generate a file-based catalog from a single 'helm template' file
+When FILE is '-' or not provided, the template is read from standard input,
+and when it is an http or https URL, the template is downloaded`,
+		Long: `Generate a file-based catalog from a single 'helm template' file
+When FILE is '-' or not provided, the template is read from standard input,
+and when it is an http or https URL, the template is downloaded. A template
+in a git repository is read at a branch, tag or commit with a reference like
+git+https://github.com/example/catalog#templates/helm.yaml@v1.2.0
+
+A helm template is a Go template of file-based catalog content. It is executed
+with the contents of the --values file available as '.Values', using a subset
+of the Helm template functions (include, required, default, toYaml, toJson,
+quote, indent, nindent, trim, lower, upper). The resulting content is then
+rendered as a basic template, so any olm.bundle which only specifies an image
+is replaced by the rendered bundle.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Handle different input argument types
+			// When no arguments or "-" is passed to the command,
+			// assume input is coming from stdin
+			// Otherwise open the file or download the URL passed to the command
+			checksum, err := cmd.Flags().GetString("checksum")
+			if err != nil {
+				log.Fatalf("unable to determine checksum")
+			}
+			data, source, err := util.OpenFileStdinOrURL(cmd, args, checksum)
+			if err != nil {
+				log.Fatalf("unable to open %q: %v", source, err)
+			}
+			defer data.Close()
+
+			var write func(declcfg.DeclarativeConfig, io.Writer) error
+			output, err := cmd.Flags().GetString("output")
+			if err != nil {
+				log.Fatalf("unable to determine output format")
+			}
+			switch output {
+			case "json":
+				write = declcfg.WriteJSON
+			case "yaml":
+				write = declcfg.WriteYAML
+			case "yaml-canonical":
+				write = declcfg.WriteYAMLCanonical
+			default:
+				log.Fatalf("invalid --output value %q, expected (json|yaml|yaml-canonical)", output)
+			}
+
+			var values map[string]interface{}
+			if valuesFile != "" {
+				f, err := os.Open(valuesFile)
+				if err != nil {
+					log.Fatalf("unable to open values %q: %v", valuesFile, err)
+				}
+				defer f.Close()
+				values, err = helm.ReadValues(f)
+				if err != nil {
+					log.Fatalf("values %q: %v", valuesFile, err)
+				}
+			}
+
+			// The bundle loading impl is somewhat verbose, even on the happy path,
+			// so discard all logrus default logger logs. Any important failures will be
+			// returned from template.Render and logged as fatal errors.
+			logrus.SetOutput(io.Discard)
+
+			reg, err := util.CreateCLIRegistry(cmd)
+			if err != nil {
+				log.Fatalf("creating containerd registry: %v", err)
+			}
+			defer func() {
+				_ = reg.Destroy()
+			}()
+
+			var m *migrations.Migrations
+			if migrateLevel != "" {
+				m, err = migrations.NewMigrations(migrateLevel)
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+
+			template := helm.Template{
+				Values: values,
+				RenderBundle: func(ctx context.Context, ref string) (*declcfg.DeclarativeConfig, error) {
+					renderer := action.Render{
+						Refs:           []string{ref},
+						Registry:       reg,
+						AllowedRefMask: action.RefBundleImage,
+						Migrations:     m,
+					}
+					return renderer.Run(ctx)
+				},
+			}
+
+			out, err := template.Render(cmd.Context(), data)
+			if err != nil {
+				log.Fatalf("helm %q: %v", source, err)
+			}
+
+			if err := write(*out, os.Stdout); err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&valuesFile, "values", "f", "", "Path to a values file made available to the template as '.Values'")
+	cmd.Flags().StringVar(&migrateLevel, "migrate-level", "", "Name of the last migration to run (default: none)\n"+migrations.HelpText())
+
+	return cmd
+}
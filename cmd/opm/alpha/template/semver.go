@@ -25,16 +25,24 @@ func newSemverTemplateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use: "semver [FILE]",
 		Short: `Generate a file-based catalog from a single 'semver template' file
-When FILE is '-' or not provided, the template is read from standard input`,
+When FILE is '-' or not provided, the template is read from standard input,
+and when it is an http or https URL, the template is downloaded`,
 		Long: `Generate a file-based catalog from a single 'semver template' file
-When FILE is '-' or not provided, the template is read from standard input`,
+When FILE is '-' or not provided, the template is read from standard input,
+and when it is an http or https URL, the template is downloaded. A template
+in a git repository is read at a branch, tag or commit with a reference like
+git+https://github.com/example/catalog#templates/semver.yaml@v1.2.0`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Handle different input argument types
 			// When no arguments or "-" is passed to the command,
 			// assume input is coming from stdin
-			// Otherwise open the file passed to the command
-			data, source, err := util.OpenFileOrStdin(cmd, args)
+			// Otherwise open the file or download the URL passed to the command
+			checksum, err := cmd.Flags().GetString("checksum")
+			if err != nil {
+				return err
+			}
+			data, source, err := util.OpenFileStdinOrURL(cmd, args, checksum)
 			if err != nil {
 				return err
 			}
@@ -50,6 +58,8 @@ When FILE is '-' or not provided, the template is read from standard input`,
 				write = declcfg.WriteJSON
 			case "yaml":
 				write = declcfg.WriteYAML
+			case "yaml-canonical":
+				write = declcfg.WriteYAMLCanonical
 			case "mermaid":
 				write = func(cfg declcfg.DeclarativeConfig, writer io.Writer) error {
 					mermaidWriter := declcfg.NewMermaidWriter()
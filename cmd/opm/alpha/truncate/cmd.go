@@ -0,0 +1,86 @@
+package truncate
+
+import (
+	"io"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+)
+
+func NewCmd() *cobra.Command {
+	var (
+		truncate action.Truncate
+		output   string
+	)
+	logger := logrus.New()
+
+	cmd := &cobra.Command{
+		Use:   "truncate <indexRef> <outputDir>",
+		Short: "Write a file-based catalog containing only the latest entries of each channel",
+		Long: `Write a file-based catalog containing only the latest entries of each channel.
+
+The index reference may be a sqlite-based index image or database file, or a
+file-based catalog image or directory. Each channel is truncated to its head
+and the --depth-1 entries before it in the channel's replaces chain. The
+replaces and skips of the remaining entries are rewritten to skip over the
+removed entries, and bundles that are no longer in any channel are removed.
+
+This produces slim catalogs for disconnected environments where historical
+upgrade paths are not needed.
+
+If --include-dependencies is set, removed bundles that are required to satisfy
+the olm.package.required and olm.gvk.required properties of the remaining
+bundles are kept.
+
+The resulting catalog is validated and written to <outputDir>, which must be
+empty or not exist.
+
+NOTE: the --output=json format produces streamable, concatenated JSON files.
+These are suitable to opm and jq, but may not be supported by arbitrary JSON
+parsers that assume that a file contains exactly one valid JSON object.
+`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			truncate.CatalogRef = args[0]
+			truncate.OutputDir = args[1]
+
+			switch output {
+			case "yaml":
+				truncate.WriteFunc = declcfg.WriteYAML
+				truncate.FileExt = ".yaml"
+			case "json":
+				truncate.WriteFunc = declcfg.WriteJSON
+				truncate.FileExt = ".json"
+			default:
+				logger.Fatalf("invalid --output value %q, expected (json|yaml)", output)
+			}
+
+			// The catalog loading impl is somewhat verbose, even on the happy path,
+			// so discard all logrus default logger logs. Any important failures will be
+			// returned from truncate.Run and logged as fatal errors.
+			logrus.SetOutput(io.Discard)
+
+			reg, err := util.CreateCLIRegistry(cmd)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			defer func() {
+				_ = reg.Destroy()
+			}()
+			truncate.Registry = reg
+
+			if err := truncate.Run(cmd.Context()); err != nil {
+				logger.Fatal(err)
+			}
+			logger.Infof("wrote truncated file-based catalog to %q", truncate.OutputDir)
+		},
+	}
+	cmd.Flags().IntVar(&truncate.Depth, "depth", 1, "number of entries to keep in each channel, starting from the channel head")
+	cmd.Flags().BoolVar(&truncate.IncludeDependencies, "include-dependencies", true, "keep bundles that satisfy the package and GVK dependencies of the remaining bundles")
+	cmd.Flags().StringVarP(&output, "output", "o", "json", "Output format (json|yaml)")
+	return cmd
+}
@@ -0,0 +1,78 @@
+package validateapis
+
+import (
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+)
+
+func NewCmd() *cobra.Command {
+	var validate action.ValidateAPIs
+	logger := logrus.New()
+
+	cmd := &cobra.Command{
+		Use:   "validate-apis [catalog-image | catalog-directory | bundle-image | bundle-directory | sqlite-file]...",
+		Short: "Report bundles that use deprecated or removed Kubernetes APIs",
+		Long: `Report the objects of the bundles of catalogs and bundles that use Kubernetes
+APIs that are deprecated or removed in the Kubernetes version given with
+--kube-version, such as apiextensions.k8s.io/v1beta1 CustomResourceDefinitions,
+which are removed in Kubernetes 1.22.
+
+The command exits with an error if a bundle uses an API that is removed in the
+target Kubernetes version. Bundles rendered without their objects, as from
+catalogs built by older opm versions, can't be checked and are listed as
+unchecked.
+`,
+		Example: `
+#
+# Check a catalog image against Kubernetes 1.25
+#
+$ opm alpha validate-apis quay.io/example/catalog:latest --kube-version 1.25
+`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			validate.Refs = args
+
+			// The bundle loading impl is somewhat verbose, even on the happy path,
+			// so discard all logrus default logger logs.
+			logrus.SetOutput(io.Discard)
+
+			reg, err := util.CreateCLIRegistry(cmd)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			defer func() {
+				_ = reg.Destroy()
+			}()
+			validate.Registry = reg
+			validate.SkipTLSVerify, validate.PlainHTTP, err = util.GetTLSOptions(cmd)
+			if err != nil {
+				logger.Fatal(err)
+			}
+
+			result, err := validate.Run(cmd.Context())
+			if err != nil {
+				logger.Fatal(err)
+			}
+			if len(result.Usages) > 0 {
+				if err := result.WriteColumns(os.Stdout); err != nil {
+					logger.Fatal(err)
+				}
+			}
+			for _, b := range result.Unchecked {
+				logger.Warnf("bundle %q has no objects, its APIs were not checked", b)
+			}
+			if result.HasRemoved() {
+				logger.Fatalf("bundles use APIs removed in Kubernetes %s", result.KubeVersion)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&validate.KubeVersion, "kube-version", "", "Target Kubernetes version, such as 1.25")
+	_ = cmd.MarkFlagRequired("kube-version")
+	return cmd
+}
@@ -0,0 +1,171 @@
+package verifyimage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+	"github.com/operator-framework/operator-registry/pkg/cache"
+	"github.com/operator-framework/operator-registry/pkg/containertools"
+	"github.com/operator-framework/operator-registry/pkg/image"
+	"github.com/operator-framework/operator-registry/pkg/server"
+)
+
+const defaultServeCacheDir = "/tmp/cache"
+
+type verifyImage struct {
+	imageRef      string
+	configsDir    string
+	serveCacheDir string
+
+	registry image.Registry
+	logger   *logrus.Entry
+}
+
+func NewCmd() *cobra.Command {
+	logger := logrus.New()
+	v := verifyImage{
+		logger: logrus.NewEntry(logger),
+	}
+	cmd := &cobra.Command{
+		Use:   "verify-image <image> <configs-dir>",
+		Short: "Verify that a catalog image serves the same content as its source FBC",
+		Long: `Verify that a catalog image serves the same content as its source file-based catalog.
+
+The image is pulled and its declarative configs and pre-built serve cache are
+extracted. The image content and the source declarative config directory are
+then both served in-process, and every registry RPC is issued against each of
+them. Any difference in the responses is reported and causes the command to
+exit with a non-zero status.
+
+If the image does not contain a serve cache at --serve-cache-dir, a cache is
+built from the configs found in the image.
+`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			v.imageRef = args[0]
+			v.configsDir = args[1]
+
+			// The image registry and cache implementations are verbose, even on the
+			// happy path, so discard default logger logs and only surface warnings
+			// from the cache. Any important failures will be returned from v.run
+			// and logged as fatal errors.
+			logrus.SetOutput(io.Discard)
+			logger.SetLevel(logrus.WarnLevel)
+
+			reg, err := util.CreateCLIRegistry(cmd)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			defer func() {
+				_ = reg.Destroy()
+			}()
+			v.registry = reg
+
+			diffs, err := v.run(cmd.Context())
+			if err != nil {
+				logger.Fatal(err)
+			}
+			if len(diffs) > 0 {
+				logger.Fatalf("image %q does not serve the same content as %q:\n%s", v.imageRef, v.configsDir, strings.Join(diffs, "\n"))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "image %q serves the same content as %q\n", v.imageRef, v.configsDir)
+		},
+	}
+	cmd.Flags().StringVar(&v.serveCacheDir, "serve-cache-dir", defaultServeCacheDir, "path of the pre-built serve cache inside of the image")
+	return cmd
+}
+
+func (v *verifyImage) run(ctx context.Context) ([]string, error) {
+	s, err := os.Stat(v.configsDir)
+	if err != nil {
+		return nil, err
+	}
+	if !s.IsDir() {
+		return nil, fmt.Errorf("%q is not a directory", v.configsDir)
+	}
+
+	ref := image.SimpleReference(v.imageRef)
+	if err := v.registry.Pull(ctx, ref); err != nil {
+		return nil, fmt.Errorf("failed to pull image %q: %v", ref, err)
+	}
+	labels, err := v.registry.Labels(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get labels for image %q: %v", ref, err)
+	}
+	imageConfigsDir, ok := labels[containertools.ConfigsLocationLabel]
+	if !ok {
+		return nil, fmt.Errorf("image %q is not a file-based catalog image: label %q not found", ref, containertools.ConfigsLocationLabel)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "opm-verify-image-")
+	if err != nil {
+		return nil, fmt.Errorf("create tempdir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	unpackDir := filepath.Join(tmpDir, "image")
+	if err := v.registry.Unpack(ctx, ref, unpackDir); err != nil {
+		return nil, fmt.Errorf("failed to unpack image %q: %v", ref, err)
+	}
+
+	imageStore, err := v.loadImageStore(ctx, filepath.Join(unpackDir, imageConfigsDir), filepath.Join(unpackDir, v.serveCacheDir), filepath.Join(tmpDir, "image-cache"))
+	if err != nil {
+		return nil, err
+	}
+	defer imageStore.Close()
+
+	sourceStore, err := cache.New(filepath.Join(tmpDir, "source-cache"), cache.WithLog(v.logger))
+	if err != nil {
+		return nil, err
+	}
+	defer sourceStore.Close()
+	if err := cache.LoadOrRebuild(ctx, sourceStore, os.DirFS(v.configsDir)); err != nil {
+		return nil, fmt.Errorf("failed to build cache for %q: %v", v.configsDir, err)
+	}
+
+	return server.Compare(ctx, sourceStore, imageStore)
+}
+
+// loadImageStore loads the serve cache shipped in the image, failing if it
+// does not match the configs shipped alongside it. If the image has no
+// cache, one is built from the image's configs in fallbackCacheDir.
+func (v *verifyImage) loadImageStore(ctx context.Context, configsDir, cacheDir, fallbackCacheDir string) (cache.Cache, error) {
+	if _, err := os.Stat(cacheDir); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		v.logger.WithField("cache", v.serveCacheDir).Warn("image does not contain a serve cache, building one from image configs")
+		store, err := cache.New(fallbackCacheDir, cache.WithLog(v.logger))
+		if err != nil {
+			return nil, err
+		}
+		if err := cache.LoadOrRebuild(ctx, store, os.DirFS(configsDir)); err != nil {
+			store.Close()
+			return nil, fmt.Errorf("failed to build cache for image configs: %v", err)
+		}
+		return store, nil
+	}
+
+	store, err := cache.New(cacheDir, cache.WithLog(v.logger))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image cache: %v", err)
+	}
+	if err := store.CheckIntegrity(ctx, os.DirFS(configsDir)); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("image cache integrity check failed: %v", err)
+	}
+	if err := store.Load(ctx); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to load image cache: %v", err)
+	}
+	return store, nil
+}
@@ -0,0 +1,42 @@
+package verifyimage_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+	"github.com/operator-framework/operator-registry/cmd/opm/root"
+	"github.com/operator-framework/operator-registry/pkg/image"
+)
+
+func TestImageCacheDir(t *testing.T) {
+	ctx := context.Background()
+
+	// Store an image in the image cache, which an offline registry only
+	// finds if it uses the cache.
+	cacheDir := t.TempDir()
+	store, err := oci.NewWithContext(ctx, filepath.Join(cacheDir, "oci-layout"))
+	require.NoError(t, err)
+	desc, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, "application/vnd.example.test", oras.PackManifestOptions{})
+	require.NoError(t, err)
+	ref := "example.com/foo/foo-index:v0.1.0"
+	require.NoError(t, store.Tag(ctx, desc, ref))
+
+	cmd, _, err := root.NewCmd(false).Find([]string{"alpha", "verify-image"})
+	require.NoError(t, err)
+	require.NoError(t, cmd.ParseFlags([]string{"--image-cache-dir", cacheDir, "--offline"}))
+	serveCacheDir, err := cmd.Flags().GetString("serve-cache-dir")
+	require.NoError(t, err)
+	require.Equal(t, "/tmp/cache", serveCacheDir)
+
+	reg, err := util.CreateCLIRegistry(cmd)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, reg.Destroy()) }()
+	require.NoError(t, reg.Pull(ctx, image.SimpleReference(ref)))
+	require.ErrorIs(t, reg.Pull(ctx, image.SimpleReference("example.com/foo/foo-index:v0.2.0")), image.ErrNotCached)
+}
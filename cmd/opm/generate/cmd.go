@@ -30,6 +30,9 @@ func newDockerfileCmd() *cobra.Command {
 		baseImage      string
 		builderImage   string
 		extraLabelStrs []string
+		buildArgs      bool
+		caCertFile     string
+		singleStage    bool
 	)
 	cmd := &cobra.Command{
 		Use:   "dockerfile <fbcRootDir>",
@@ -45,6 +48,16 @@ When specifying extra labels, note that if duplicate keys exist, only the last
 value of each duplicate key will be added to the generated Dockerfile.
 
 A separate builder and base image can be specified. The builder image may not be "scratch".
+
+With --single-stage, the catalog and its serve cache are built directly in the
+base image, which must then contain opm.
+
+With --build-args, the builder and base images are declared as the
+BUILDER_IMAGE and BASE_IMAGE build arguments, so that they can be overridden,
+e.g. to pin them to digests, when the image is built.
+
+A file of CA certificates, relative to the build context, can be added to the
+trusted certificates of the image with --ca-cert-file.
 `,
 		RunE: func(inCmd *cobra.Command, args []string) error {
 			fromDir := filepath.Clean(args[0])
@@ -87,6 +100,9 @@ A separate builder and base image can be specified. The builder image may not be
 				BuilderImage: builderImage,
 				IndexDir:     indexName,
 				ExtraLabels:  extraLabels,
+				BuildArgs:    buildArgs,
+				CACertFile:   caCertFile,
+				SingleStage:  singleStage,
 				Writer:       f,
 			}
 			if err := gen.Run(); err != nil {
@@ -99,8 +115,12 @@ A separate builder and base image can be specified. The builder image may not be
 	cmd.Flags().StringVarP(&baseImage, "base-image", "i", containertools.DefaultBinarySourceImage, "Image base to use to build catalog.")
 	cmd.Flags().StringVarP(&builderImage, "builder-image", "b", containertools.DefaultBinarySourceImage, "Image to use as a build stage.")
 	cmd.Flags().StringSliceVarP(&extraLabelStrs, "extra-labels", "l", []string{}, "Extra labels to include in the generated Dockerfile. Labels should be of the form 'key=value'.")
+	cmd.Flags().BoolVar(&buildArgs, "build-args", false, "Declare the builder and base images as build arguments, so that they can be overridden when building the image.")
+	cmd.Flags().StringVar(&caCertFile, "ca-cert-file", "", "Path, relative to the build context, of a file of CA certificates to add to the image.")
+	cmd.Flags().BoolVar(&singleStage, "single-stage", false, "Build the catalog in the base image without a builder stage. The base image must contain opm.")
 	_ = cmd.Flags().MarkDeprecated("binary-image", "use --base-image instead")
 	cmd.MarkFlagsMutuallyExclusive("binary-image", "base-image")
+	cmd.MarkFlagsMutuallyExclusive("single-stage", "builder-image")
 	return cmd
 }
 
@@ -27,6 +27,8 @@ var (
 		0.1.1 -> 0.1.2 -> 0.1.2-1  
 		will be pruned on add to:  
 		0.1.1 -> 0.1.2
+
+		If --from-index is a file-based catalog image, each bundle is added to the channels listed in its annotations, with upgrade edges read from its CSV, and a file-based catalog image is built. Only '--mode=replaces' is supported for file-based catalogs.
 `) + "\n\n" + sqlite.DeprecationMessage
 
 	addExample = templates.Examples(`
@@ -167,6 +169,12 @@ func runIndexAddCmdFunc(cmd *cobra.Command, _ []string) error {
 		containertools.NewContainerTool(pullTool, containertools.NoneTool),
 		logger)
 
+	reg, destroy, err := offlineRegistry(cmd, logger)
+	if err != nil {
+		return err
+	}
+	defer destroy()
+
 	request := indexer.AddToIndexRequest{
 		Generate:          generate,
 		FromIndex:         fromIndex,
@@ -180,6 +188,7 @@ func runIndexAddCmdFunc(cmd *cobra.Command, _ []string) error {
 		PlainHTTP:         useHTTP,
 		Overwrite:         overwrite,
 		EnableAlpha:       enableAlpha,
+		Registry:          reg,
 	}
 
 	err = indexAdder.AddToIndex(request)
@@ -1,9 +1,13 @@
 package index
 
 import (
+	"fmt"
+
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+	"github.com/operator-framework/operator-registry/pkg/image"
 	"github.com/operator-framework/operator-registry/pkg/sqlite"
 )
 
@@ -22,11 +26,12 @@ func AddCommand(parent *cobra.Command, showAlphaHelp bool) {
 			}
 			return nil
 		},
-		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
 			sqlite.LogSqliteDeprecation()
 			if skipTLS, err := cmd.Flags().GetBool("skip-tls"); err == nil && skipTLS {
 				logrus.Warn("--skip-tls flag is set: this mode is insecure and meant for development purposes only.")
 			}
+			return checkOffline(cmd)
 		},
 		Args: cobra.NoArgs,
 	}
@@ -40,3 +45,37 @@ func AddCommand(parent *cobra.Command, showAlphaHelp bool) {
 	cmd.AddCommand(newIndexDeprecateTruncateCmd())
 	cmd.AddCommand(newIndexPruneStrandedCmd())
 }
+
+// checkOffline returns an error if cmd can't run with --offline. Offline, the
+// index and bundle images are pulled from the image cache of opm, but images
+// are still built and exported with the container tool, which doesn't read
+// from it.
+func checkOffline(cmd *cobra.Command) error {
+	if !util.IsOffline(cmd) {
+		return nil
+	}
+	if cmd.Name() == "export" {
+		return fmt.Errorf("--offline is not supported by index export, which pulls bundles with the container tool")
+	}
+	if generate, err := cmd.Flags().GetBool("generate"); err == nil && !generate {
+		return fmt.Errorf("--offline requires --generate, the index image is built with the container tool")
+	}
+	return nil
+}
+
+// offlineRegistry returns the registry of the image cache if --offline is set,
+// or nil to pull with the pull tool, and a func to destroy it.
+func offlineRegistry(cmd *cobra.Command, logger *logrus.Entry) (image.Registry, func(), error) {
+	if !util.IsOffline(cmd) {
+		return nil, func() {}, nil
+	}
+	reg, err := util.CreateCLIRegistry(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reg, func() {
+		if err := reg.Destroy(); err != nil {
+			logger.WithError(err).Warn("error destroying local cache")
+		}
+	}, nil
+}
@@ -0,0 +1,49 @@
+package index_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/cmd/opm/root"
+	"github.com/operator-framework/operator-registry/pkg/image"
+)
+
+func TestOffline(t *testing.T) {
+	type spec struct {
+		name        string
+		args        []string
+		expectedErr string
+		expectedIs  error
+	}
+	specs := []spec{
+		{
+			name:        "Export",
+			args:        []string{"index", "export", "--offline", "--index", "example.com/foo/index:v1"},
+			expectedErr: "--offline is not supported by index export",
+		},
+		{
+			name:        "NoGenerate",
+			args:        []string{"index", "add", "--offline", "--bundles", "example.com/foo/bundle:v1"},
+			expectedErr: "--offline requires --generate",
+		},
+		{
+			name:       "NotCached",
+			args:       []string{"index", "rm", "--offline", "--generate", "--from-index", "example.com/foo/index:v1", "--operators", "foo"},
+			expectedIs: image.ErrNotCached,
+		},
+	}
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			cmd := root.NewCmd(false)
+			cmd.SetArgs(append(s.args, "--image-cache-dir", t.TempDir()))
+			err := cmd.Execute()
+			if s.expectedIs != nil {
+				require.ErrorIs(t, err, s.expectedIs)
+			} else {
+				require.ErrorContains(t, err, s.expectedErr)
+			}
+		})
+	}
+}
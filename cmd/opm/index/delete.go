@@ -16,6 +16,9 @@ func newIndexDeleteCmd() *cobra.Command {
 		Short: "delete an entire operator from an index",
 		Long: `delete an entire operator from an index
 
+If --from-index is a file-based catalog image, the operators are removed from
+its declarative configs and a file-based catalog image is built.
+
 ` + sqlite.DeprecationMessage,
 
 		PreRunE: func(cmd *cobra.Command, _ []string) error {
@@ -109,6 +112,12 @@ func runIndexDeleteCmdFunc(cmd *cobra.Command, _ []string) error {
 		containertools.NewContainerTool(pullTool, containertools.NoneTool),
 		logger)
 
+	reg, destroy, err := offlineRegistry(cmd, logger)
+	if err != nil {
+		return err
+	}
+	defer destroy()
+
 	request := indexer.DeleteFromIndexRequest{
 		Generate:          generate,
 		FromIndex:         fromIndex,
@@ -119,6 +128,7 @@ func runIndexDeleteCmdFunc(cmd *cobra.Command, _ []string) error {
 		Permissive:        permissive,
 		SkipTLSVerify:     skipTLSVerify,
 		PlainHTTP:         useHTTP,
+		Registry:          reg,
 	}
 
 	err = indexDeleter.DeleteFromIndex(request)
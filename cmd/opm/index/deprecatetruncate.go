@@ -30,6 +30,8 @@ var deprecateLong = templates.LongDesc(`
 	Deprecating a bundle that removes the default channel is not allowed unless the head(s) of all channels are being deprecated (the package is subsequently removed from the index). 
     This behavior can be enabled via the allow-package-removal flag. 
     Changing the default channel prior to deprecation is possible by publishing a new bundle to the index.
+
+	If --from-index is a file-based catalog image, the bundles are deprecated with an olm.deprecated property, its declarative configs are truncated in the same way, and a file-based catalog image is built.
 	`) + "\n\n" + sqlite.DeprecationMessage
 
 func newIndexDeprecateTruncateCmd() *cobra.Command {
@@ -130,6 +132,12 @@ func runIndexDeprecateTruncateCmdFunc(cmd *cobra.Command, _ []string) error {
 		containertools.NewContainerTool(pullTool, containertools.NoneTool),
 		logger)
 
+	reg, destroy, err := offlineRegistry(cmd, logger)
+	if err != nil {
+		return err
+	}
+	defer destroy()
+
 	request := indexer.DeprecateFromIndexRequest{
 		Generate:            generate,
 		FromIndex:           fromIndex,
@@ -141,6 +149,7 @@ func runIndexDeprecateTruncateCmdFunc(cmd *cobra.Command, _ []string) error {
 		SkipTLSVerify:       skipTLSVerify,
 		PlainHTTP:           useHTTP,
 		AllowPackageRemoval: allowPackageRemoval,
+		Registry:            reg,
 	}
 
 	err = indexDeprecator.DeprecateFromIndex(request)
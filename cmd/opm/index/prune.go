@@ -18,6 +18,9 @@ func newIndexPruneCmd() *cobra.Command {
 		Short: "prune an index of all but specified packages",
 		Long: `prune an index of all but specified packages
 
+If --from-index is a file-based catalog image, its declarative configs are
+pruned and a file-based catalog image is built.
+
 ` + sqlite.DeprecationMessage,
 
 		PreRunE: func(cmd *cobra.Command, _ []string) error {
@@ -110,6 +113,12 @@ func runIndexPruneCmdFunc(cmd *cobra.Command, _ []string) error {
 
 	indexPruner := indexer.NewIndexPruner(containertools.NewContainerTool(containerTool, containertools.PodmanTool), logger)
 
+	reg, destroy, err := offlineRegistry(cmd, logger)
+	if err != nil {
+		return err
+	}
+	defer destroy()
+
 	request := indexer.PruneFromIndexRequest{
 		Generate:          generate,
 		FromIndex:         fromIndex,
@@ -120,6 +129,7 @@ func runIndexPruneCmdFunc(cmd *cobra.Command, _ []string) error {
 		Permissive:        permissive,
 		SkipTLSVerify:     skipTLSVerify,
 		PlainHTTP:         useHTTP,
+		Registry:          reg,
 	}
 
 	err = indexPruner.PruneFromIndex(request)
@@ -18,6 +18,10 @@ func newIndexPruneStrandedCmd() *cobra.Command {
 		Short: "prune an index of stranded bundles",
 		Long: `prune an index of stranded bundles - bundles that are not associated with a particular package
 
+If --from-index is a file-based catalog image, bundles that are not an entry of
+any channel are removed from its declarative configs and a file-based catalog
+image is built.
+
 ` + sqlite.DeprecationMessage,
 
 		PreRunE: func(cmd *cobra.Command, _ []string) error {
@@ -95,6 +99,12 @@ func runIndexPruneStrandedCmdFunc(cmd *cobra.Command, _ []string) error {
 
 	indexPruner := indexer.NewIndexStrandedPruner(containertools.NewContainerTool(containerTool, containertools.PodmanTool), logger)
 
+	reg, destroy, err := offlineRegistry(cmd, logger)
+	if err != nil {
+		return err
+	}
+	defer destroy()
+
 	request := indexer.PruneStrandedFromIndexRequest{
 		Generate:          generate,
 		FromIndex:         fromIndex,
@@ -103,6 +113,7 @@ func runIndexPruneStrandedCmdFunc(cmd *cobra.Command, _ []string) error {
 		Tag:               tag,
 		SkipTLSVerify:     skipTLSVerify,
 		PlainHTTP:         useHTTP,
+		Registry:          reg,
 	}
 
 	err = indexPruner.PruneStrandedFromIndex(request)
@@ -1,12 +1,19 @@
 package util
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"strings"
 
+	"github.com/opencontainers/go-digest"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
+	"github.com/operator-framework/operator-registry/alpha/action"
 	"github.com/operator-framework/operator-registry/pkg/image"
 	"github.com/operator-framework/operator-registry/pkg/image/containersimageregistry"
 )
@@ -50,10 +57,30 @@ func CreateCLIRegistry(cmd *cobra.Command) (image.Registry, error) {
 	if err != nil {
 		return nil, err
 	}
-	return containersimageregistry.New(
-		containersimageregistry.DefaultSystemContext,
+	opts := []containersimageregistry.Option{
 		containersimageregistry.WithInsecureSkipTLSVerify(skipTLSVerify || useHTTP),
-	)
+	}
+	if f := rootFlag(cmd, "image-cache-dir"); f != nil && f.Value.String() != "" {
+		opts = append(opts, containersimageregistry.WithImageCacheDir(f.Value.String()))
+	}
+	if IsOffline(cmd) {
+		opts = append(opts, containersimageregistry.WithOffline())
+	}
+	return containersimageregistry.New(containersimageregistry.DefaultSystemContext, opts...)
+}
+
+// rootFlag returns the persistent flag of the root command of cmd, or nil for
+// commands that are not under the root command, e.g. in tests. Subcommands
+// may have local flags of the same name, which must not be read instead.
+func rootFlag(cmd *cobra.Command, name string) *pflag.Flag {
+	return cmd.Root().PersistentFlags().Lookup(name)
+}
+
+// IsOffline returns whether the --offline flag of the root command is set, in
+// which case commands must not access the network.
+func IsOffline(cmd *cobra.Command) bool {
+	f := rootFlag(cmd, "offline")
+	return f != nil && f.Value.String() == "true"
 }
 
 func OpenFileOrStdin(cmd *cobra.Command, args []string) (io.ReadCloser, string, error) {
@@ -63,3 +90,95 @@ func OpenFileOrStdin(cmd *cobra.Command, args []string) (io.ReadCloser, string,
 	reader, err := os.Open(args[0])
 	return reader, args[0], err
 }
+
+// OpenFileStdinOrURL opens the input named by args like OpenFileOrStdin, and
+// also downloads it when it is an http or https URL, or reads it from a git
+// repository when it is a reference that action.ParseGitRef parses. When
+// checksum is set, it is the digest that the input must have, as
+// "<algorithm>:<hex>", and the input is read in full and verified before it
+// is returned. URLs and git references are not opened when IsOffline.
+func OpenFileStdinOrURL(cmd *cobra.Command, args []string, checksum string) (io.ReadCloser, string, error) {
+	if len(args) > 0 && IsOffline(cmd) {
+		for _, prefix := range []string{"http://", "https://", action.GitRefPrefix} {
+			if strings.HasPrefix(args[0], prefix) {
+				return nil, args[0], fmt.Errorf("cannot open %q offline", args[0])
+			}
+		}
+	}
+
+	var expected digest.Digest
+	if checksum != "" {
+		var err error
+		if expected, err = digest.Parse(checksum); err != nil {
+			return nil, checksum, fmt.Errorf("invalid checksum %q: %v", checksum, err)
+		}
+	}
+
+	var (
+		reader io.ReadCloser
+		source string
+		err    error
+	)
+	if len(args) > 0 && (strings.HasPrefix(args[0], "http://") || strings.HasPrefix(args[0], "https://")) {
+		source = args[0]
+		reader, err = openURL(cmd, source)
+	} else if len(args) > 0 && strings.HasPrefix(args[0], action.GitRefPrefix) {
+		source = args[0]
+		reader, err = openGitRef(cmd, source)
+	} else {
+		reader, source, err = OpenFileOrStdin(cmd, args)
+	}
+	if err != nil || expected == "" {
+		return reader, source, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, source, err
+	}
+	if actual := expected.Algorithm().FromBytes(data); actual != expected {
+		return nil, source, fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+	return io.NopCloser(bytes.NewReader(data)), source, nil
+}
+
+// openGitRef reads the file of a git reference from a checkout in a temporary
+// directory, which is removed before it returns.
+func openGitRef(cmd *cobra.Command, ref string) (io.ReadCloser, error) {
+	g, err := action.ParseGitRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "opm-git-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	local, err := g.Checkout(cmd.Context(), dir)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(local)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func openURL(cmd *cobra.Command, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected response status %q", resp.Status)
+	}
+	return resp.Body, nil
+}
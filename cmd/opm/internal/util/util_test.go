@@ -0,0 +1,106 @@
+package util
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenFileStdinOrURL(t *testing.T) {
+	const template = "schema: olm.template.basic\nentries: []\n"
+	checksum := digest.FromString(template).String()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/template.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, template)
+	}))
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "template.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(template), 0600))
+
+	type spec struct {
+		name      string
+		args      []string
+		checksum  string
+		offline   bool
+		assertion require.ErrorAssertionFunc
+	}
+	for _, s := range []spec{
+		{name: "URL", args: []string{srv.URL + "/template.yaml"}, assertion: require.NoError},
+		{name: "URL/Checksum", args: []string{srv.URL + "/template.yaml"}, checksum: checksum, assertion: require.NoError},
+		{name: "URL/ChecksumMismatch", args: []string{srv.URL + "/template.yaml"}, checksum: digest.FromString("other").String(), assertion: require.Error},
+		{name: "URL/NotFound", args: []string{srv.URL + "/missing.yaml"}, assertion: require.Error},
+		{name: "File/Checksum", args: []string{file}, checksum: checksum, assertion: require.NoError},
+		{name: "Stdin/Checksum", args: []string{"-"}, checksum: checksum, assertion: require.NoError},
+		{name: "InvalidChecksum", args: []string{file}, checksum: "sha256:abc", assertion: require.Error},
+		{name: "URL/Offline", args: []string{srv.URL + "/template.yaml"}, offline: true, assertion: require.Error},
+		{name: "Git/Offline", args: []string{"git+" + srv.URL + "#template.yaml"}, offline: true, assertion: require.Error},
+		{name: "File/Offline", args: []string{file}, offline: true, assertion: require.NoError},
+	} {
+		t.Run(s.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.PersistentFlags().Bool("offline", s.offline, "")
+			cmd.SetContext(context.Background())
+			stdin, err := os.Open(file)
+			require.NoError(t, err)
+			defer stdin.Close()
+			cmd.SetIn(stdin)
+
+			r, _, err := OpenFileStdinOrURL(cmd, s.args, s.checksum)
+			s.assertion(t, err)
+			if err != nil {
+				return
+			}
+			defer r.Close()
+			data, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, template, string(data))
+		})
+	}
+}
+
+func TestOpenFileStdinOrURLGit(t *testing.T) {
+	const template = "schema: olm.template.basic\nentries: []\n"
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "templates"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "basic.yaml"), []byte(template), 0600))
+	_, err = wt.Add(".")
+	require.NoError(t, err)
+	hash, err := wt.Commit("add template", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	ref := "git+file://" + dir + "#templates/basic.yaml@" + hash.String()
+	r, source, err := OpenFileStdinOrURL(cmd, []string{ref}, digest.FromString(template).String())
+	require.NoError(t, err)
+	defer r.Close()
+	require.Equal(t, ref, source)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, template, string(data))
+
+	_, _, err = OpenFileStdinOrURL(cmd, []string{"git+file://" + dir + "#templates/missing.yaml"}, "")
+	require.Error(t, err)
+}
@@ -7,9 +7,11 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/sirupsen/logrus"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"github.com/operator-framework/operator-registry/cmd/opm/root"
+	"github.com/operator-framework/operator-registry/pkg/lib/tracing"
 	registrylib "github.com/operator-framework/operator-registry/pkg/registry"
 )
 
@@ -20,7 +22,17 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	if err := cmd.ExecuteContext(ctx); err != nil {
+	shutdownTracing, err := tracing.Setup(ctx, "opm")
+	if err != nil {
+		logrus.Warnf("tracing disabled: %v", err)
+		shutdownTracing = func(context.Context) error { return nil }
+	}
+
+	err = cmd.ExecuteContext(ctx)
+	if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+		logrus.Warnf("flush traces: %v", shutdownErr)
+	}
+	if err != nil {
 		var agg utilerrors.Aggregate
 		if !errors.As(err, &agg) {
 			os.Exit(1)
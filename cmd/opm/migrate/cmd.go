@@ -1,10 +1,13 @@
 package migrate
 
 import (
+	"encoding/json"
 	"log"
+	"os"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 
 	"github.com/operator-framework/operator-registry/alpha/action"
 	"github.com/operator-framework/operator-registry/alpha/action/migrations"
@@ -14,9 +17,13 @@ import (
 
 func NewCmd() *cobra.Command {
 	var (
-		migrate      action.Migrate
-		migrateLevel string
-		output       string
+		migrate        action.Migrate
+		migrateLevel   string
+		migration      string
+		migrateExec    string
+		output         string
+		shardBundles   string
+		bundlesPerFile int
 	)
 	cmd := &cobra.Command{
 		Use:   "migrate <indexRef> <outputDir>",
@@ -27,6 +34,23 @@ NOTE: the --output=json format produces streamable, concatenated JSON files.
 These are suitable to opm and jq, but may not be supported by arbitrary JSON
 parsers that assume that a file contains exactly one valid JSON object.
 
+The migrations of the file-based catalog to newer conventions are selected
+with --level, which runs every migration up to and including the named one,
+or with --migration, which runs only the named migration. The available
+migrations are listed by "opm migrate list-migrations".
+
+Organization-specific migrations can be run with --migrate-exec, which pipes
+the catalog, as streamed JSON, through the given command after the other
+migrations, and replaces it with the catalog the command writes to its
+standard output. The command is split on whitespace and is not run in a
+shell.
+
+Packages with many bundles can be split across several files of their
+directory, with --shard-bundles, which writes the bundles of each major or
+minor version to their own file, and --bundles-per-file, which splits the
+bundles, ordered by version, into files of at most that many bundles. The
+files are loaded like any other file-based catalog.
+
 ` + sqlite.DeprecationMessage,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -37,19 +61,49 @@ parsers that assume that a file contains exactly one valid JSON object.
 			case "yaml":
 				migrate.WriteFunc = declcfg.WriteYAML
 				migrate.FileExt = ".yaml"
+			case "yaml-canonical":
+				migrate.WriteFunc = declcfg.WriteYAMLCanonical
+				migrate.FileExt = ".yaml"
 			case "json":
 				migrate.WriteFunc = declcfg.WriteJSON
 				migrate.FileExt = ".json"
 			default:
-				log.Fatalf("invalid --output value %q, expected (json|yaml)", output)
+				log.Fatalf("invalid --output value %q, expected (json|yaml|yaml-canonical)", output)
+			}
+
+			switch shardBundles {
+			case "":
+			case "major":
+				migrate.WriteFSOptions = append(migrate.WriteFSOptions, declcfg.WithBundleShards(declcfg.ShardByMajorVersion))
+			case "minor":
+				migrate.WriteFSOptions = append(migrate.WriteFSOptions, declcfg.WithBundleShards(declcfg.ShardByMinorVersion))
+			default:
+				log.Fatalf("invalid --shard-bundles value %q, expected (major|minor)", shardBundles)
+			}
+			if bundlesPerFile > 0 {
+				migrate.WriteFSOptions = append(migrate.WriteFSOptions, declcfg.WithBundlesPerFile(bundlesPerFile))
 			}
 
-			if migrateLevel != "" {
-				m, err := migrations.NewMigrations(migrateLevel)
-				if err != nil {
+			var (
+				m   *migrations.Migrations
+				err error
+			)
+			if migration != "" {
+				m, err = migrations.NewMigration(migration)
+			} else if migrateLevel != "" {
+				m, err = migrations.NewMigrations(migrateLevel)
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+			if migrateExec != "" {
+				if m, err = migrations.WithExec(m, migrateExec); err != nil {
 					log.Fatal(err)
 				}
-				migrate.Migrations = m
+			}
+			migrate.Migrations = m
+			migrate.OnDuplicateBundle = func(d action.DuplicateBundle) {
+				logrus.Warn(d)
 			}
 
 			logrus.Infof("rendering index %q as file-based catalog", migrate.CatalogRef)
@@ -60,8 +114,53 @@ parsers that assume that a file contains exactly one valid JSON object.
 			return nil
 		},
 	}
-	cmd.Flags().StringVarP(&output, "output", "o", "json", "Output format (json|yaml)")
-	cmd.Flags().StringVar(&migrateLevel, "migrate-level", "", "Name of the last migration to run (default: none)\n"+migrations.HelpText())
+	cmd.Flags().StringVarP(&output, "output", "o", "json", "Output format (json|yaml|yaml-canonical)")
+	cmd.Flags().StringVar(&migrateLevel, "level", "", "Name of the last migration to run (default: none)\n"+migrations.HelpText())
+	cmd.Flags().StringVar(&migrateLevel, "migrate-level", "", "Name of the last migration to run (default: none)")
+	_ = cmd.Flags().MarkDeprecated("migrate-level", "use --level instead")
+	cmd.Flags().StringVar(&migration, "migration", "", "Name of the only migration to run")
+	cmd.Flags().StringVar(&migrateExec, "migrate-exec", "", "Command to pipe the catalog through, as streamed JSON, after the other migrations; the catalog is replaced by its output")
+	cmd.Flags().BoolVar(&migrate.DeduplicateBundles, "deduplicate-bundles", false, "Remove bundles whose content is identical to another bundle of the catalog, and warn about them")
+	cmd.Flags().BoolVar(&migrate.EdgeProvenance, "edge-provenance", false, "Add an "+action.TypeEdgeProvenance+" property to each channel migrated from a sqlite index, recording how its entries are reached and whether their replaces edges were declared by their CSVs or generated by the index")
+	cmd.Flags().StringVar(&shardBundles, "shard-bundles", "", "Write the bundles of each package to one file per version of the package (major|minor)")
+	cmd.Flags().IntVar(&bundlesPerFile, "bundles-per-file", 0, "Split the bundles of each package, ordered by version, into files of at most this many bundles (default: no limit)")
+	cmd.MarkFlagsMutuallyExclusive("level", "migration")
+	cmd.MarkFlagsMutuallyExclusive("migrate-level", "migration")
+
+	cmd.AddCommand(newListMigrationsCmd(), newImageCmd())
+	return cmd
+}
 
+func newListMigrationsCmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "list-migrations",
+		Short: "List the available file-based catalog migrations",
+		Long: `List the migrations available to "opm migrate --level" and "opm migrate
+--migration", in the order in which they are run, with their descriptions.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			var (
+				out []byte
+				err error
+			)
+			switch output {
+			case "json":
+				out, err = json.MarshalIndent(migrations.Describe(), "", "    ")
+				out = append(out, '\n')
+			case "yaml":
+				out, err = yaml.Marshal(migrations.Describe())
+			default:
+				log.Fatalf("invalid --output value %q, expected (json|yaml)", output)
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+			if _, err := os.Stdout.Write(out); err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "json", "Output format (json|yaml)")
 	return cmd
 }
@@ -0,0 +1,103 @@
+package migrate
+
+import (
+	"log"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/action/migrations"
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+	"github.com/operator-framework/operator-registry/pkg/containertools"
+)
+
+func newImageCmd() *cobra.Command {
+	var (
+		migrate      action.MigrateImage
+		migrateLevel string
+		migration    string
+		buildTool    string
+	)
+	cmd := &cobra.Command{
+		Use:   "image <sqlite-index-ref> <dest-ref>",
+		Short: "Migrate a sqlite-based index image to a file-based catalog image",
+		Long: `Migrate a sqlite-based index image to a file-based catalog image in one step.
+
+The index image is pulled and migrated to a file-based catalog, as "opm migrate"
+does. A Dockerfile that adds the catalog and its pre-built serve cache to the
+base image is generated, as "opm generate dockerfile" does, and the image is
+built with the build tool, tagged with the destination reference. With --push,
+the image is then pushed to its registry.
+
+The build context, i.e. the catalog in catalog/ and its catalog.Dockerfile, is
+kept in --context-dir if set, so that the catalog can be committed to source
+control and the image rebuilt from it.`,
+		Example: `# Migrate an index image and push the file-based catalog image
+opm migrate image quay.io/example/index:v1 quay.io/example/catalog:v1 --push`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			migrate.SourceRef = args[0]
+			migrate.DestRef = args[1]
+
+			var (
+				m   *migrations.Migrations
+				err error
+			)
+			if migration != "" {
+				m, err = migrations.NewMigration(migration)
+			} else if migrateLevel != "" {
+				m, err = migrations.NewMigrations(migrateLevel)
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+			migrate.Migrations = m
+
+			var tool containertools.ContainerTool
+			switch buildTool {
+			case "docker":
+				tool = containertools.DockerTool
+			case "podman":
+				tool = containertools.PodmanTool
+			default:
+				log.Fatalf("invalid --build-tool value %q, expected (docker|podman)", buildTool)
+			}
+			skipTLSVerify, _, err := util.GetTLSOptions(cmd)
+			if err != nil {
+				log.Fatal(err)
+			}
+			logger := logrus.WithFields(logrus.Fields{"source": migrate.SourceRef, "destination": migrate.DestRef})
+			migrate.Builder = containertools.NewCommandRunner(tool, logger, containertools.SkipTLS(skipTLSVerify))
+
+			reg, err := util.CreateCLIRegistry(cmd)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer func() {
+				_ = reg.Destroy()
+			}()
+			migrate.Registry = reg
+
+			logger.Info("migrating index image to file-based catalog image")
+			if err := migrate.Run(cmd.Context()); err != nil {
+				logrus.New().Fatal(err)
+			}
+			if migrate.Push {
+				logger.Info("built and pushed file-based catalog image")
+			} else {
+				logger.Info("built file-based catalog image")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&migrateLevel, "level", "", "Name of the last migration to run (default: none)\n"+migrations.HelpText())
+	cmd.Flags().StringVar(&migration, "migration", "", "Name of the only migration to run")
+	cmd.MarkFlagsMutuallyExclusive("level", "migration")
+	cmd.Flags().StringVarP(&migrate.BaseImage, "base-image", "i", containertools.DefaultBinarySourceImage, "Image base to use to build catalog.")
+	cmd.Flags().StringVarP(&migrate.BuilderImage, "builder-image", "b", containertools.DefaultBinarySourceImage, "Image to use as a build stage.")
+	cmd.Flags().StringVarP(&buildTool, "build-tool", "u", "podman", "Tool to build and push the image. One of: [docker, podman]")
+	cmd.Flags().StringVar(&migrate.ContextDir, "context-dir", "", "Directory to keep the build context in (default: a temporary directory)")
+	cmd.Flags().BoolVar(&migrate.Push, "push", false, "Push the image once it is built")
+	return cmd
+}
@@ -16,10 +16,15 @@ import (
 	health "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/cmd/opm/version"
 	"github.com/operator-framework/operator-registry/pkg/api"
+	"github.com/operator-framework/operator-registry/pkg/cache"
 	"github.com/operator-framework/operator-registry/pkg/lib/dns"
 	"github.com/operator-framework/operator-registry/pkg/lib/log"
 	"github.com/operator-framework/operator-registry/pkg/lib/tmp"
+	"github.com/operator-framework/operator-registry/pkg/registry"
 	"github.com/operator-framework/operator-registry/pkg/server"
 	"github.com/operator-framework/operator-registry/pkg/sqlite"
 )
@@ -30,6 +35,12 @@ func newRegistryServeCmd() *cobra.Command {
 		Short: "serve an operator-registry database",
 		Long: `serve an operator-registry database that is queriable using grpc
 
+Each call served from the database is logged and counted in the
+` + server.LegacyCallsMetric + ` metric, to find the clients that still depend on
+it. With --convert-on-start, the database is instead migrated to a file-based
+catalog in a temporary directory when the command starts, and the file-based
+catalog is served, as "opm serve" would.
+
 ` + sqlite.DeprecationMessage,
 
 		PreRunE: func(cmd *cobra.Command, _ []string) error {
@@ -49,6 +60,10 @@ func newRegistryServeCmd() *cobra.Command {
 	rootCmd.Flags().StringP("termination-log", "t", "/dev/termination-log", "path to a container termination log file")
 	rootCmd.Flags().Bool("skip-migrate", false, "do  not attempt to migrate to the latest db revision when starting")
 	rootCmd.Flags().String("timeout-seconds", "infinite", "Timeout in seconds. This flag will be removed later.")
+	rootCmd.Flags().Bool("convert-on-start", false, "migrate the db to a file-based catalog in a temporary directory when starting, and serve the file-based catalog")
+	rootCmd.Flags().Bool("normalize-properties", false, "serve the properties that bundles of file-based catalogs have, by deriving required properties from bundle dependencies")
+	rootCmd.Flags().StringSlice("exclude-property-types", nil, "remove the properties of these types from the bundles that are served")
+	rootCmd.Flags().StringSlice("synthetic-replaces", []string{registry.UpgradeEdgeSkips}, fmt.Sprintf("the edges, besides replaces, that GetBundleThatReplaces follows to find the bundle that replaces another one (%s|%s)", registry.UpgradeEdgeSkips, registry.UpgradeEdgeSkipRange))
 
 	return rootCmd
 }
@@ -84,6 +99,55 @@ func serveFunc(cmd *cobra.Command, _ []string) error {
 
 	logger := logrus.WithFields(logrus.Fields{"database": dbName, "port": port})
 
+	convert, err := cmd.Flags().GetBool("convert-on-start")
+	if err != nil {
+		return err
+	}
+	excludedPropertyTypes, err := cmd.Flags().GetStringSlice("exclude-property-types")
+	if err != nil {
+		return err
+	}
+	syntheticReplaces, err := cmd.Flags().GetStringSlice("synthetic-replaces")
+	if err != nil {
+		return err
+	}
+	replacementPolicy, err := registry.ParseReplacementPolicy(syntheticReplaces)
+	if err != nil {
+		return fmt.Errorf("--synthetic-replaces: %v", err)
+	}
+	timeout, err := cmd.Flags().GetString("timeout-seconds")
+	if err != nil {
+		return err
+	}
+
+	logger.Printf("Keeping server open for %s seconds", timeout)
+	if timeout != "infinite" {
+		timeoutInputSeconds, err := strconv.ParseUint(timeout, 10, 16)
+		if err != nil {
+			return err
+		}
+		// duration is a signed int, so capping it to prevent overflow
+		if timeoutInputSeconds > math.MaxInt64 {
+			timeoutInputSeconds = math.MaxInt64
+			logger.Infof("Timeout value too large. Capping to %v.", math.MaxInt64)
+		}
+		// having capped the value to safe ranges, quiet the linter
+		// nolint:gosec
+		timeoutSeconds := int64(timeoutInputSeconds)
+
+		timeoutDuration := time.Duration(timeoutSeconds) * time.Second
+		timer := time.AfterFunc(timeoutDuration, func() {
+			logger.Info("Timeout expired. Gracefully stopping.")
+			cancel()
+		})
+		defer timer.Stop()
+	}
+
+	if convert {
+		return serveConverted(ctx, logger, dbName, port, excludedPropertyTypes, replacementPolicy)
+	}
+	logger.Warn(`serving a sqlite database is deprecated: migrate it to a file-based catalog with "opm migrate" and serve it with "opm serve", or pass --convert-on-start`)
+
 	// make a writable copy of the db for migrations
 	tmpdb, err := tmp.CopyTmpDB(dbName)
 	if err != nil {
@@ -96,6 +160,11 @@ func serveFunc(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
+	// fail fast on corrupted databases rather than serving errors
+	if err := sqlite.CheckIntegrity(ctx, db); err != nil {
+		return fmt.Errorf("database %q: %v", dbName, err)
+	}
+
 	if _, err := db.ExecContext(ctx, `PRAGMA soft_heap_limit=1`); err != nil {
 		logger.WithError(err).Warnf("error setting soft heap limit for sqlite")
 	}
@@ -109,7 +178,19 @@ func serveFunc(cmd *cobra.Command, _ []string) error {
 		logger.WithError(err).Warnf("couldn't migrate db")
 	}
 
-	store := sqlite.NewSQLLiteQuerierFromDb(db, sqlite.OmitManifests(true))
+	normalize, err := cmd.Flags().GetBool("normalize-properties")
+	if err != nil {
+		return err
+	}
+	var serverOpts []server.RegistryServerOption
+	if normalize {
+		serverOpts = append(serverOpts, server.WithNormalizedProperties())
+	}
+	if len(excludedPropertyTypes) > 0 {
+		serverOpts = append(serverOpts, server.WithExcludedPropertyTypes(excludedPropertyTypes...))
+	}
+
+	store := sqlite.NewSQLLiteQuerierFromDb(db, sqlite.OmitManifests(true), sqlite.WithReplacementPolicy(replacementPolicy))
 
 	// sanity check that the db is available
 	tables, err := store.ListTables(ctx)
@@ -125,36 +206,12 @@ func serveFunc(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("failed to listen: %s", err)
 	}
 
-	timeout, err := cmd.Flags().GetString("timeout-seconds")
-	if err != nil {
-		return err
-	}
-
-	s := grpc.NewServer()
-	logger.Printf("Keeping server open for %s seconds", timeout)
-	if timeout != "infinite" {
-		timeoutInputSeconds, err := strconv.ParseUint(timeout, 10, 16)
-		if err != nil {
-			return err
-		}
-		// duration is a signed int, so capping it to prevent overflow
-		if timeoutInputSeconds > math.MaxInt64 {
-			timeoutInputSeconds = math.MaxInt64
-			logger.Infof("Timeout value too large. Capping to %v.", math.MaxInt64)
-		}
-		// having capped the value to safe ranges, quiet the linter
-		// nolint:gosec
-		timeoutSeconds := int64(timeoutInputSeconds)
-
-		timeoutDuration := time.Duration(timeoutSeconds) * time.Second
-		timer := time.AfterFunc(timeoutDuration, func() {
-			logger.Info("Timeout expired. Gracefully stopping.")
-			s.GracefulStop()
-		})
-		defer timer.Stop()
-	}
-
-	api.RegisterRegistryServer(s, server.NewRegistryServer(store))
+	usage := server.NewLegacyUsage(logger)
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(usage.UnaryInterceptor),
+		grpc.ChainStreamInterceptor(usage.StreamInterceptor),
+	)
+	api.RegisterRegistryServer(s, server.NewRegistryServer(store, serverOpts...))
 	health.RegisterHealthServer(s, server.NewHealthServer())
 	reflection.Register(s)
 
@@ -168,6 +225,43 @@ func serveFunc(cmd *cobra.Command, _ []string) error {
 	return s.Serve(lis)
 }
 
+// serveConverted migrates the sqlite database dbName to a file-based catalog
+// in a temporary directory, and serves the file-based catalog until ctx is
+// done.
+func serveConverted(ctx context.Context, logger *logrus.Entry, dbName, port string, excludedPropertyTypes []string, replacementPolicy registry.ReplacementPolicy) error {
+	configDir, err := os.MkdirTemp("", "opm-registry-serve-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(configDir)
+
+	logger.Infof("migrating database to file-based catalog in %s", configDir)
+	m := action.Migrate{
+		CatalogRef: dbName,
+		OutputDir:  configDir,
+		WriteFunc:  declcfg.WriteJSON,
+		FileExt:    ".json",
+	}
+	if err := m.Run(ctx); err != nil {
+		return fmt.Errorf("migrate database %q: %v", dbName, err)
+	}
+
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %s", err)
+	}
+	return server.Run(ctx, server.Options{
+		ConfigDir:    configDir,
+		CacheOptions: []cache.CacheOption{cache.WithReplacementPolicy(replacementPolicy)},
+		Listener:     lis,
+		OpmVersion:   version.OpmVersion(),
+		Log:          logger,
+		RegistryServerOptions: []server.RegistryServerOption{
+			server.WithExcludedPropertyTypes(excludedPropertyTypes...),
+		},
+	})
+}
+
 func migrate(ctx context.Context, shouldSkipMigrate bool, db *sql.DB) error {
 	if shouldSkipMigrate {
 		return nil
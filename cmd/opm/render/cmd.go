@@ -13,6 +13,7 @@ import (
 	"github.com/operator-framework/operator-registry/alpha/action/migrations"
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
 	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+	"github.com/operator-framework/operator-registry/pkg/image/containersimageregistry"
 	"github.com/operator-framework/operator-registry/pkg/sqlite"
 )
 
@@ -22,15 +23,52 @@ func NewCmd(showAlphaHelp bool) *cobra.Command {
 		output           string
 		imageRefTemplate string
 
+		objects string
+
 		oldMigrateAllFlag bool
 		migrateLevel      string
+		migrateExec       string
 	)
 	cmd := &cobra.Command{
-		Use:   "render [catalog-image | catalog-directory | bundle-image | bundle-directory | sqlite-file]...",
+		Use:   "render [catalog-image | catalog-directory | catalog-artifact | bundle-image | bundle-directory | helm-chart | helm-chart-archive | sqlite-file | git-reference]...",
 		Short: "Generate a stream of file-based catalog objects from catalogs and bundles",
 		Long: `Generate a stream of file-based catalog objects to stdout from the provided
 catalog images, file-based catalog directories, bundle images, and sqlite
 database files.
+
+File-based catalogs pushed as OCI artifacts with "opm alpha push" are rendered
+by prefixing their reference with ` + action.ArtifactRefPrefix + `.
+
+Helm charts are rendered into helm+v3 bundles, with an olm.helm.chart property
+describing the chart. Charts pushed to registries are rendered from their
+` + action.HelmChartRefPrefix + ` reference, and chart archives created with "helm package"
+from their path, with the --alpha-image-ref-template flag providing the
+reference that the chart will be pulled from.
+
+Catalogs, bundles and the other local references above are also rendered from
+git repositories, at a branch, tag or commit, with references like
+` + action.GitRefPrefix + `https://github.com/example/catalog#catalog@v1.2.0. Only the path after '#'
+is checked out. Without a path, the root of the repository is rendered, and
+without '@', its default branch.
+
+With --offline, nothing is read from the network, for hermetic builds: images
+are only read from the image cache (see --image-cache-dir), populated by
+earlier runs, and from OCI layouts referenced as
+` + containersimageregistry.OCILayoutRefPrefix + `<path>[:<tag>]. The images that are missing are all listed
+before render fails.
+
+The table and mermaid outputs summarize the rendered catalog for inspection
+rather than stream its objects: "-o table" lists the packages, channels and
+channel heads, and "-o mermaid" outputs the channels' upgrade graphs, as with
+"opm alpha render-graph". "-o yaml-canonical" outputs YAML in a canonical
+form, with the lists whose order has no meaning sorted, so that catalogs kept
+in version control only show changes to their content when re-rendered.
+
+Bundle objects make up most of a rendered catalog. With --objects=reference,
+each object is written to its own file in --objects-dir, and the catalog only
+records its path, which keeps the catalog reviewable while catalogs loaded
+from directories, by "opm serve" or "opm validate" for example, still see the
+objects. With --objects=omit, they are left out of the catalog entirely.
 `,
 		Args: cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
@@ -40,10 +78,16 @@ database files.
 			switch output {
 			case "yaml":
 				write = declcfg.WriteYAML
+			case "yaml-canonical":
+				write = declcfg.WriteYAMLCanonical
 			case "json":
 				write = declcfg.WriteJSON
+			case "table":
+				write = declcfg.WriteTable
+			case "mermaid":
+				write = declcfg.NewMermaidWriter().WriteChannels
 			default:
-				log.Fatalf("invalid --output value %q, expected (json|yaml)", output)
+				log.Fatalf("invalid --output value %q, expected (json|yaml|yaml-canonical|table|mermaid)", output)
 			}
 
 			// The bundle loading impl is somewhat verbose, even on the happy path,
@@ -60,6 +104,11 @@ database files.
 			}()
 
 			render.Registry = reg
+			render.Offline = util.IsOffline(cmd)
+			render.SkipTLSVerify, render.PlainHTTP, err = util.GetTLSOptions(cmd)
+			if err != nil {
+				log.Fatal(err)
+			}
 
 			if imageRefTemplate != "" {
 				tmpl, err := template.New("image-ref-template").Parse(imageRefTemplate)
@@ -79,7 +128,18 @@ database files.
 			if err != nil {
 				log.Fatal(err)
 			}
+			if migrateExec != "" {
+				if m, err = migrations.WithExec(m, migrateExec); err != nil {
+					log.Fatal(err)
+				}
+			}
 			render.Migrations = m
+			if render.Objects, err = action.ParseBundleObjects(objects); err != nil {
+				log.Fatal(err)
+			}
+			render.OnDuplicateBundle = func(d action.DuplicateBundle) {
+				log.Printf("warning: %s", d)
+			}
 
 			cfg, err := render.Run(cmd.Context())
 			if err != nil {
@@ -91,11 +151,16 @@ database files.
 			}
 		},
 	}
-	cmd.Flags().StringVarP(&output, "output", "o", "json", "Output format of the streamed file-based catalog objects (json|yaml)")
+	cmd.Flags().StringVarP(&output, "output", "o", "json", "Output format of the streamed file-based catalog objects (json|yaml|yaml-canonical), or of a summary of the rendered catalog: a table of its packages, channels and channel heads (table) or its upgrade graph in mermaid format (mermaid)")
 
 	cmd.Flags().StringVar(&migrateLevel, "migrate-level", "", "Name of the last migration to run (default: none)\n"+migrations.HelpText())
 	cmd.Flags().BoolVar(&oldMigrateAllFlag, "migrate", false, "Perform all available schema migrations on the rendered FBC")
 	cmd.MarkFlagsMutuallyExclusive("migrate", "migrate-level")
+	cmd.Flags().StringVar(&migrateExec, "migrate-exec", "", "Command to pipe the rendered catalog through, as streamed JSON, after the other migrations; the catalog is replaced by its output")
+	cmd.Flags().BoolVar(&render.DeduplicateBundles, "deduplicate-bundles", false, "Remove bundles whose content is identical to a bundle rendered earlier, and warn about them")
+	cmd.Flags().BoolVar(&render.ComputeBundleSize, "compute-bundle-size", false, "Add an olm.bundle.size property with the size of their manifests to the rendered bundles")
+	cmd.Flags().StringVar(&objects, "objects", string(action.BundleObjectsInline), "How the objects of the rendered bundles are output: inline in their olm.bundle.object properties (inline), removed (omit), or written to files in --objects-dir that the properties reference (reference). References are relative to the rendered catalog file, which must be written to the current directory")
+	cmd.Flags().StringVar(&render.ObjectsDir, "objects-dir", "objects", "Directory, relative to the current directory, that bundle objects are written to with --objects=reference")
 
 	// Alpha flags
 	cmd.Flags().StringVar(&imageRefTemplate, "alpha-image-ref-template", "", "When bundle image reference information is unavailable, populate it with this template")
@@ -103,7 +168,7 @@ database files.
 	if showAlphaHelp {
 		cmd.Long += `
 If rendering sources that do not carry bundle image reference information
-(e.g. bundle directories), the --alpha-image-ref-template flag can be used to
+(e.g. bundle directories and helm chart archives), the --alpha-image-ref-template flag can be used to
 generate image references for the rendered file-based catalog objects.
 This is useful when generating a catalog with image references prior to
 those images actually existing. Available template variables are:
@@ -40,6 +40,8 @@ To view help related to alpha features, set HELP_ALPHA=true in the environment.`
 	cmd.PersistentFlags().Bool("skip-tls", false, "skip TLS certificate verification for container image registries while pulling bundles or index")
 	cmd.PersistentFlags().Bool("skip-tls-verify", false, "skip TLS certificate verification for container image registries while pulling bundles")
 	cmd.PersistentFlags().Bool("use-http", false, "use plain HTTP for container image registries while pulling bundles")
+	cmd.PersistentFlags().String("image-cache-dir", "", "directory to cache pulled container images in across runs, instead of a temporary directory (defaults to $OLM_CACHE_DIR/images when OLM_CACHE_DIR is set)")
+	cmd.PersistentFlags().Bool("offline", false, "forbid network access: read container images only from the image cache (see --image-cache-dir) or from local OCI layouts referenced as oci:<path>[:<tag>], and fail with the list of images that are missing")
 	if err := cmd.PersistentFlags().MarkDeprecated("skip-tls", "use --use-http and --skip-tls-verify instead"); err != nil {
 		logrus.Panic(err.Error())
 	}
@@ -0,0 +1,123 @@
+package serve
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// accessLog logs, for a sample of the calls of the server, the number and the
+// size of the messages received and sent, so that the clients that drive the
+// bandwidth of a catalog can be identified. Sizes are those of the serialized
+// messages, before compression.
+type accessLog struct {
+	logger     *logrus.Entry
+	sampleRate float64
+	// sample returns a number in [0.0, 1.0), compared with sampleRate.
+	sample func() float64
+}
+
+func newAccessLog(logger *logrus.Entry, sampleRate float64) (*accessLog, error) {
+	if sampleRate < 0 || sampleRate > 1 {
+		return nil, fmt.Errorf("access log sample rate %v must be between 0 and 1", sampleRate)
+	}
+	return &accessLog{logger: logger, sampleRate: sampleRate, sample: rand.Float64}, nil
+}
+
+// messageCounts counts the messages of a call, and their sizes.
+type messageCounts struct {
+	requests, requestBytes   int
+	responses, responseBytes int
+}
+
+func (c *messageCounts) addRequest(m any) {
+	c.requests++
+	c.requestBytes += messageSize(m)
+}
+
+func (c *messageCounts) addResponse(m any) {
+	c.responses++
+	c.responseBytes += messageSize(m)
+}
+
+func messageSize(m any) int {
+	if pm, ok := m.(proto.Message); ok {
+		return proto.Size(pm)
+	}
+	return 0
+}
+
+func (a *accessLog) sampled() bool {
+	return a.sampleRate > 0 && a.sample() < a.sampleRate
+}
+
+func (a *accessLog) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !a.sampled() {
+		return handler(ctx, req)
+	}
+	start := time.Now()
+	var counts messageCounts
+	counts.addRequest(req)
+	resp, err := handler(ctx, req)
+	if err == nil {
+		counts.addResponse(resp)
+	}
+	a.log(ctx, info.FullMethod, start, counts, err)
+	return resp, err
+}
+
+func (a *accessLog) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !a.sampled() {
+		return handler(srv, ss)
+	}
+	start := time.Now()
+	counting := &countingServerStream{ServerStream: ss}
+	err := handler(srv, counting)
+	a.log(ss.Context(), info.FullMethod, start, counting.counts, err)
+	return err
+}
+
+func (a *accessLog) log(ctx context.Context, method string, start time.Time, counts messageCounts, err error) {
+	fields := logrus.Fields{
+		"grpc.method":            method,
+		"grpc.code":              status.Code(err).String(),
+		"grpc.time_ms":           time.Since(start).Milliseconds(),
+		"grpc.request.messages":  counts.requests,
+		"grpc.request.bytes":     counts.requestBytes,
+		"grpc.response.messages": counts.responses,
+		"grpc.response.bytes":    counts.responseBytes,
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields["peer.address"] = p.Addr.String()
+	}
+	a.logger.WithFields(fields).Info("access")
+}
+
+// countingServerStream counts the messages received and sent on a stream.
+type countingServerStream struct {
+	grpc.ServerStream
+	counts messageCounts
+}
+
+func (s *countingServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.counts.addRequest(m)
+	return nil
+}
+
+func (s *countingServerStream) SendMsg(m any) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+	s.counts.addResponse(m)
+	return nil
+}
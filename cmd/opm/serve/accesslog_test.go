@@ -0,0 +1,99 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+)
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	recv []proto.Message
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+func (s *fakeServerStream) SendMsg(any) error        { return nil }
+func (s *fakeServerStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), s.recv[0])
+	s.recv = s.recv[1:]
+	return nil
+}
+
+func TestAccessLog(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	a, err := newAccessLog(logrus.NewEntry(logger), 1)
+	require.NoError(t, err)
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234}})
+
+	req := &api.GetPackageRequest{Name: "etcd"}
+	resp := &api.Package{Name: "etcd", DefaultChannelName: "alpha"}
+	_, err = a.unaryInterceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/api.Registry/GetPackage"}, func(context.Context, any) (any, error) {
+		return resp, nil
+	})
+	require.NoError(t, err)
+	entry := hook.LastEntry()
+	require.Equal(t, "access", entry.Message)
+	require.Equal(t, "/api.Registry/GetPackage", entry.Data["grpc.method"])
+	require.Equal(t, "OK", entry.Data["grpc.code"])
+	require.Equal(t, "10.0.0.1:1234", entry.Data["peer.address"])
+	require.Equal(t, 1, entry.Data["grpc.request.messages"])
+	require.Equal(t, proto.Size(req), entry.Data["grpc.request.bytes"])
+	require.Equal(t, 1, entry.Data["grpc.response.messages"])
+	require.Equal(t, proto.Size(resp), entry.Data["grpc.response.bytes"])
+
+	bundles := []*api.Bundle{{CsvName: "etcdoperator.v0.9.0"}, {CsvName: "etcdoperator.v0.9.2", CsvJson: "{}"}}
+	ss := &fakeServerStream{ctx: ctx, recv: []proto.Message{&api.ListBundlesRequest{}}}
+	err = a.streamInterceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: "/api.Registry/ListBundles"}, func(_ any, stream grpc.ServerStream) error {
+		if err := stream.RecvMsg(&api.ListBundlesRequest{}); err != nil {
+			return err
+		}
+		for _, b := range bundles {
+			if err := stream.SendMsg(b); err != nil {
+				return err
+			}
+		}
+		return errors.New("interrupted")
+	})
+	require.EqualError(t, err, "interrupted")
+	entry = hook.LastEntry()
+	require.Equal(t, "/api.Registry/ListBundles", entry.Data["grpc.method"])
+	require.Equal(t, "Unknown", entry.Data["grpc.code"])
+	require.Equal(t, 1, entry.Data["grpc.request.messages"])
+	require.Equal(t, 0, entry.Data["grpc.request.bytes"])
+	require.Equal(t, 2, entry.Data["grpc.response.messages"])
+	require.Equal(t, proto.Size(bundles[0])+proto.Size(bundles[1]), entry.Data["grpc.response.bytes"])
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	a, err := newAccessLog(logrus.NewEntry(logger), 0.5)
+	require.NoError(t, err)
+	samples := []float64{0.2, 0.7, 0.4}
+	a.sample = func() float64 {
+		s := samples[0]
+		samples = samples[1:]
+		return s
+	}
+	handler := func(context.Context, any) (any, error) { return &api.Package{}, nil }
+	for range 3 {
+		_, err := a.unaryInterceptor(context.Background(), &api.GetPackageRequest{}, &grpc.UnaryServerInfo{}, handler)
+		require.NoError(t, err)
+	}
+	require.Len(t, hook.AllEntries(), 2)
+
+	for _, rate := range []float64{-0.1, 1.5} {
+		_, err := newAccessLog(logrus.NewEntry(logger), rate)
+		require.ErrorContains(t, err, "must be between 0 and 1")
+	}
+}
@@ -0,0 +1,117 @@
+package serve
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+const zstdName = "zstd"
+
+// Registering a compressor makes the server advertise it to clients in the
+// grpc-accept-encoding header and accept requests compressed with it. gzip
+// registers itself on import.
+func init() {
+	encoding.RegisterCompressor(newZstdCompressor())
+}
+
+// streamCompressors are the values accepted by --stream-compression.
+var streamCompressors = []string{gzip.Name, zstdName}
+
+// compressionInterceptor returns a stream interceptor that compresses the
+// responses of streaming calls with the named compressor, for clients that
+// advertise support for it. By default, the server only compresses responses
+// when the client sent a compressed request.
+func compressionInterceptor(name string) (grpc.StreamServerInterceptor, error) {
+	if !slices.Contains(streamCompressors, name) {
+		return nil, fmt.Errorf("unsupported stream compression %q, expected one of %v", name, streamCompressors)
+	}
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		supported, err := grpc.ClientSupportedCompressors(ctx)
+		if err == nil && slices.Contains(supported, name) {
+			if err := grpc.SetSendCompressor(ctx, name); err != nil {
+				return err
+			}
+		}
+		return handler(srv, ss)
+	}, nil
+}
+
+// zstdCompressor implements encoding.Compressor. Encoders and decoders are
+// expensive to create, so they are pooled and reset for each message.
+type zstdCompressor struct {
+	encoders sync.Pool
+	decoders sync.Pool
+}
+
+func newZstdCompressor() *zstdCompressor {
+	return &zstdCompressor{}
+}
+
+func (c *zstdCompressor) Name() string {
+	return zstdName
+}
+
+func (c *zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	if enc, ok := c.encoders.Get().(*zstd.Encoder); ok {
+		enc.Reset(w)
+		return &zstdWriter{Encoder: enc, pool: &c.encoders}, nil
+	}
+	enc, err := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdWriter{Encoder: enc, pool: &c.encoders}, nil
+}
+
+func (c *zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	if dec, ok := c.decoders.Get().(*zstd.Decoder); ok {
+		if err := dec.Reset(r); err != nil {
+			c.decoders.Put(dec)
+			return nil, err
+		}
+		return &zstdReader{Decoder: dec, pool: &c.decoders}, nil
+	}
+	dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdReader{Decoder: dec, pool: &c.decoders}, nil
+}
+
+type zstdWriter struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+func (w *zstdWriter) Close() error {
+	err := w.Encoder.Close()
+	w.pool.Put(w.Encoder)
+	return err
+}
+
+type zstdReader struct {
+	*zstd.Decoder
+	pool *sync.Pool
+}
+
+// Read returns the decoder to the pool once the message has been read in full.
+func (r *zstdReader) Read(p []byte) (int, error) {
+	if r.Decoder == nil {
+		return 0, io.EOF
+	}
+	n, err := r.Decoder.Read(p)
+	if errors.Is(err, io.EOF) {
+		r.pool.Put(r.Decoder)
+		r.Decoder = nil
+	}
+	return n, err
+}
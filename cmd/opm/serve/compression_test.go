@@ -0,0 +1,57 @@
+package serve
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/encoding"
+)
+
+func TestZstdCompressor(t *testing.T) {
+	c := encoding.GetCompressor(zstdName)
+	require.NotNil(t, c)
+
+	msg := bytes.Repeat([]byte(`{"csvJson":"large and repetitive"}`), 1000)
+
+	// Run twice so that pooled encoders and decoders are reused.
+	for i := 0; i < 2; i++ {
+		var buf bytes.Buffer
+		w, err := c.Compress(&buf)
+		require.NoError(t, err)
+		_, err = w.Write(msg)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		require.Less(t, buf.Len(), len(msg))
+
+		r, err := c.Decompress(&buf)
+		require.NoError(t, err)
+		actual, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, msg, actual)
+	}
+}
+
+func TestCompressionInterceptor(t *testing.T) {
+	type spec struct {
+		name      string
+		expectErr bool
+	}
+	specs := []spec{
+		{name: "gzip"},
+		{name: "zstd"},
+		{name: "snappy", expectErr: true},
+	}
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			i, err := compressionInterceptor(s.name)
+			if s.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, i)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package serve
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listen returns the listener of the server for address, which is one of:
+//
+//	tcp://[HOST]:PORT  a TCP address
+//	unix://PATH        a unix domain socket, replacing any stale socket at PATH
+//	fd://[NAME]        a socket passed by systemd socket activation, the first
+//	                   one or the one named NAME with FileDescriptorName=
+func listen(address string) (net.Listener, error) {
+	scheme, rest, ok := strings.Cut(address, "://")
+	if !ok {
+		return nil, fmt.Errorf("invalid listen address %q: expected tcp://, unix:// or fd://", address)
+	}
+	switch scheme {
+	case "tcp":
+		return net.Listen("tcp", rest)
+	case "unix":
+		if rest == "" {
+			return nil, fmt.Errorf("invalid listen address %q: missing socket path", address)
+		}
+		if err := removeStaleSocket(rest); err != nil {
+			return nil, err
+		}
+		return net.Listen("unix", rest)
+	case "fd":
+		return activatedListener(rest)
+	}
+	return nil, fmt.Errorf("invalid listen address %q: unsupported scheme %q", address, scheme)
+}
+
+// removeStaleSocket removes the socket left at path by a server that did not
+// shut down cleanly. Files that are not sockets are left alone, so that Listen
+// reports them instead of them being deleted by mistake.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode().Type() != fs.ModeSocket {
+		return nil
+	}
+	return os.Remove(path)
+}
+
+// activationFD is a file descriptor passed by systemd socket activation.
+type activationFD struct {
+	fd   int
+	name string
+}
+
+// listenFDsStart is the first file descriptor passed by socket activation, as
+// defined by sd_listen_fds(3).
+const listenFDsStart = 3
+
+// activationFDs returns the file descriptors passed to the process with pid by
+// socket activation, as described by the LISTEN_PID, LISTEN_FDS and
+// LISTEN_FDNAMES variables of getenv.
+func activationFDs(getenv func(string) string, pid int) ([]activationFD, error) {
+	if getenv("LISTEN_PID") == "" || getenv("LISTEN_FDS") == "" {
+		return nil, errors.New("no sockets were passed by socket activation: LISTEN_PID and LISTEN_FDS are not set")
+	}
+	listenPID, err := strconv.Atoi(getenv("LISTEN_PID"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LISTEN_PID: %v", err)
+	}
+	if listenPID != pid {
+		return nil, fmt.Errorf("sockets passed by socket activation are for process %d, not %d", listenPID, pid)
+	}
+	n, err := strconv.Atoi(getenv("LISTEN_FDS"))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", getenv("LISTEN_FDS"))
+	}
+	var names []string
+	if v := getenv("LISTEN_FDNAMES"); v != "" {
+		names = strings.Split(v, ":")
+	}
+	fds := make([]activationFD, 0, n)
+	for i := 0; i < n; i++ {
+		fd := activationFD{fd: listenFDsStart + i}
+		if i < len(names) {
+			fd.name = names[i]
+		}
+		fds = append(fds, fd)
+	}
+	return fds, nil
+}
+
+// activatedListener returns a listener on the socket named name passed by
+// socket activation, or on the first one if name is empty.
+func activatedListener(name string) (net.Listener, error) {
+	fds, err := activationFDs(os.Getenv, os.Getpid())
+	if err != nil {
+		return nil, err
+	}
+	// The sockets must not be inherited by the children of the process, as
+	// they are meant for this process only.
+	for _, v := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+		_ = os.Unsetenv(v)
+	}
+	for _, fd := range fds {
+		if name != "" && fd.name != name {
+			continue
+		}
+		f := os.NewFile(uintptr(fd.fd), fd.name)
+		// FileListener duplicates the file descriptor, so f is closed either way.
+		lis, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("listen on activated socket %d: %v", fd.fd, err)
+		}
+		return lis, nil
+	}
+	if name != "" {
+		return nil, fmt.Errorf("no socket named %q was passed by socket activation", name)
+	}
+	return nil, errors.New("no sockets were passed by socket activation")
+}
@@ -0,0 +1,109 @@
+package serve
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListen(t *testing.T) {
+	t.Run("TCP", func(t *testing.T) {
+		lis, err := listen("tcp://127.0.0.1:0")
+		require.NoError(t, err)
+		defer lis.Close()
+		require.Equal(t, "tcp", lis.Addr().Network())
+	})
+
+	t.Run("Unix", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "opm.sock")
+		lis, err := listen("unix://" + path)
+		require.NoError(t, err)
+		require.Equal(t, "unix", lis.Addr().Network())
+
+		conn, err := net.Dial("unix", path)
+		require.NoError(t, err)
+		_ = conn.Close()
+		require.NoError(t, lis.Close())
+	})
+
+	t.Run("UnixStaleSocket", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "opm.sock")
+		stale, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		// Keep the socket file, as a server that did not shut down cleanly
+		// would.
+		stale.(*net.UnixListener).SetUnlinkOnClose(false)
+		require.NoError(t, stale.Close())
+
+		lis, err := listen("unix://" + path)
+		require.NoError(t, err)
+		require.NoError(t, lis.Close())
+	})
+
+	t.Run("UnixNotASocket", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "opm.sock")
+		require.NoError(t, os.WriteFile(path, nil, 0600))
+		_, err := listen("unix://" + path)
+		require.Error(t, err)
+		require.FileExists(t, path)
+	})
+
+	for _, tt := range []struct {
+		address     string
+		expectedErr string
+	}{
+		{address: ":50051", expectedErr: `invalid listen address ":50051": expected tcp://, unix:// or fd://`},
+		{address: "udp://:50051", expectedErr: `invalid listen address "udp://:50051": unsupported scheme "udp"`},
+		{address: "unix://", expectedErr: `invalid listen address "unix://": missing socket path`},
+	} {
+		_, err := listen(tt.address)
+		require.EqualError(t, err, tt.expectedErr)
+	}
+}
+
+func TestActivationFDs(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		env         map[string]string
+		expected    []activationFD
+		expectedErr string
+	}{
+		{
+			name:        "NotActivated",
+			expectedErr: "no sockets were passed by socket activation: LISTEN_PID and LISTEN_FDS are not set",
+		},
+		{
+			name:        "OtherProcess",
+			env:         map[string]string{"LISTEN_PID": "41", "LISTEN_FDS": "1"},
+			expectedErr: "sockets passed by socket activation are for process 41, not 42",
+		},
+		{
+			name:        "InvalidCount",
+			env:         map[string]string{"LISTEN_PID": "42", "LISTEN_FDS": "-1"},
+			expectedErr: `invalid LISTEN_FDS "-1"`,
+		},
+		{
+			name:     "Unnamed",
+			env:      map[string]string{"LISTEN_PID": "42", "LISTEN_FDS": "2"},
+			expected: []activationFD{{fd: 3}, {fd: 4}},
+		},
+		{
+			name:     "Named",
+			env:      map[string]string{"LISTEN_PID": "42", "LISTEN_FDS": "2", "LISTEN_FDNAMES": "grpc:metrics"},
+			expected: []activationFD{{fd: 3, name: "grpc"}, {fd: 4, name: "metrics"}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fds, err := activationFDs(func(k string) string { return tt.env[k] }, 42)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, fds)
+		})
+	}
+}
@@ -3,13 +3,19 @@ package serve
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io/fs"
 	"net"
 	"net/http"
 	endpoint "net/http/pprof"
 	"os"
+	"path/filepath"
 	"runtime/pprof"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,25 +23,52 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
-	health "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
-	"google.golang.org/grpc/reflection"
 
-	"github.com/operator-framework/operator-registry/pkg/api"
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
+	"github.com/operator-framework/operator-registry/cmd/opm/version"
 	"github.com/operator-framework/operator-registry/pkg/cache"
+	"github.com/operator-framework/operator-registry/pkg/containertools"
+	"github.com/operator-framework/operator-registry/pkg/image"
 	"github.com/operator-framework/operator-registry/pkg/lib/dns"
 	"github.com/operator-framework/operator-registry/pkg/lib/log"
+	"github.com/operator-framework/operator-registry/pkg/registry"
 	"github.com/operator-framework/operator-registry/pkg/server"
 )
 
 type serve struct {
-	configDir             string
+	configDirs            []string
+	imageRefs             []string
+	registry              image.Registry
 	cacheDir              string
+	cacheFormat           string
+	cacheBuildConcurrency int
+	cacheFragmentDir      string
 	cacheOnly             bool
 	cacheEnforceIntegrity bool
+	checkReproducible     bool
 
-	port           string
-	terminationLog string
+	port              string
+	listen            string
+	terminationLog    string
+	streamCompression string
+	grpcMaxRecvSize   int
+	grpcMaxSendSize   int
+	accessLogSample   float64
+
+	tlsCertFile     string
+	tlsKeyFile      string
+	tlsClientCAFile string
+	authzPolicyFile string
+	authzWebhookURL string
+
+	excludedPropertyTypes []string
+	syntheticReplaces     []string
+
+	streamInterceptors []grpc.StreamServerInterceptor
+	unaryInterceptors  []grpc.UnaryServerInterceptor
 
 	debug           bool
 	pprofAddr       string
@@ -48,23 +81,69 @@ const (
 	defaultCPUStartupPath string = "/debug/pprof/startup/cpu"
 )
 
-func NewCmd() *cobra.Command {
+// Option customizes the command returned by NewCmd.
+type Option func(*serve)
+
+// WithStreamInterceptors adds interceptors, e.g. for authentication, quotas or
+// tracing, to the streaming calls of the server. They are chained, in order,
+// after the interceptors of the command, which log calls and compress
+// responses.
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) Option {
+	return func(s *serve) {
+		s.streamInterceptors = append(s.streamInterceptors, interceptors...)
+	}
+}
+
+// WithUnaryInterceptors adds interceptors to the unary calls of the server.
+// They are chained, in order, after the interceptors of the command, which
+// log calls.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(s *serve) {
+		s.unaryInterceptors = append(s.unaryInterceptors, interceptors...)
+	}
+}
+
+// NewCmd returns the serve command. Programs that extend opm can customize
+// the server it runs with opts; programs that only need to serve a catalog
+// can use server.Run instead.
+func NewCmd(opts ...Option) *cobra.Command {
 	logger := logrus.New()
 	s := serve{
 		logger: logrus.NewEntry(logger),
 	}
+	for _, opt := range opts {
+		opt(&s)
+	}
 	cmd := &cobra.Command{
-		Use:   "serve <source_path>",
+		Use:   "serve [<source_path>...]",
 		Short: "serve declarative configs",
 		Long: `This command serves declarative configs via a GRPC server.
 
-NOTE: The declarative config directory is loaded by the serve command at
+If multiple declarative config directories are provided, they are merged into
+a single catalog. Each package must be defined in exactly one of the
+directories.
+
+A sqlite database file can be served in place of a declarative config
+directory. It is converted to a declarative config at startup, as with
+"opm migrate", so that it is served from the same cache as declarative configs.
+
+File-based catalog images can be served with --image instead of, or in addition
+to, source paths. Each image is pulled and the declarative config directory
+named by its ` + containertools.ConfigsLocationLabel + ` label is
+extracted and served.
+
+NOTE: The declarative config directories are loaded by the serve command at
 startup. Changes made to the declarative config after the this command starts
 will not be reflected in the served content.
 `,
-		Args: cobra.ExactArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && len(s.imageRefs) == 0 {
+				return fmt.Errorf("requires at least 1 source path or --image")
+			}
+			return nil
+		},
 		PreRun: func(_ *cobra.Command, args []string) {
-			s.configDir = args[0]
+			s.configDirs = args
 			if s.debug {
 				logger.SetLevel(logrus.DebugLevel)
 			}
@@ -73,6 +152,16 @@ will not be reflected in the served content.
 			if !cmd.Flags().Changed("cache-enforce-integrity") {
 				s.cacheEnforceIntegrity = s.cacheDir != "" && !s.cacheOnly
 			}
+			if len(s.imageRefs) > 0 {
+				reg, err := util.CreateCLIRegistry(cmd)
+				if err != nil {
+					logger.Fatal(err)
+				}
+				defer func() {
+					_ = reg.Destroy()
+				}()
+				s.registry = reg
+			}
 			if err := s.run(cmd.Context()); err != nil {
 				logger.Fatal(err)
 			}
@@ -80,13 +169,31 @@ will not be reflected in the served content.
 	}
 
 	cmd.Flags().BoolVar(&s.debug, "debug", false, "enable debug logging")
+	cmd.Flags().StringArrayVar(&s.imageRefs, "image", nil, "serve the declarative configs of this file-based catalog image. May be repeated, and combined with source paths")
 	cmd.Flags().StringVarP(&s.terminationLog, "termination-log", "t", "/dev/termination-log", "path to a container termination log file")
 	cmd.Flags().StringVarP(&s.port, "port", "p", "50051", "port number to serve on")
+	cmd.Flags().StringVar(&s.listen, "listen", "", "address to serve on instead of --port: tcp://[HOST]:PORT, unix://PATH for a unix domain socket, or fd://[NAME] for a socket passed by systemd socket activation")
+	cmd.MarkFlagsMutuallyExclusive("port", "listen")
+	cmd.Flags().StringVar(&s.streamCompression, "stream-compression", "", fmt.Sprintf("compress the responses of streaming calls for clients that support it, even if their requests are uncompressed (%s)", strings.Join(streamCompressors, "|")))
+	cmd.Flags().IntVar(&s.grpcMaxRecvSize, "grpc-max-recv-size", 0, "maximum size in bytes of the messages the server receives (default: 4MiB)")
+	cmd.Flags().IntVar(&s.grpcMaxSendSize, "grpc-max-send-size", 0, "maximum size in bytes of the messages the server sends (default: 2GiB)")
+	cmd.Flags().StringVar(&s.tlsCertFile, "tls-cert-file", "", "serve TLS with the certificate in this file. Requires --tls-key-file")
+	cmd.Flags().StringVar(&s.tlsKeyFile, "tls-key-file", "", "private key of the certificate of --tls-cert-file")
+	cmd.Flags().StringVar(&s.tlsClientCAFile, "tls-client-ca-file", "", "verify the certificates that clients present against the CA certificates in this file, so that --authz-policy-file and --authz-webhook-url can identify clients by the common names of their certificates")
+	cmd.Flags().StringVar(&s.authzPolicyFile, "authz-policy-file", "", "restrict the packages each client may list and fetch, by the common name of its certificate or its bearer token, with the authorization policy in this YAML file")
+	cmd.Flags().StringVar(&s.authzWebhookURL, "authz-webhook-url", "", "restrict the packages each client may list and fetch with the authorization webhook at this URL, which is posted the identity of the client of each call")
+	cmd.Flags().StringSliceVar(&s.excludedPropertyTypes, "exclude-property-types", nil, "remove the properties of these types from the bundles that are served. Properties of other types, including types unknown to opm, are served as they are written in the catalog")
+	cmd.Flags().StringSliceVar(&s.syntheticReplaces, "synthetic-replaces", []string{registry.UpgradeEdgeSkips}, fmt.Sprintf("the edges, besides replaces, that GetBundleThatReplaces follows to find the bundle that replaces another one (%s|%s). Bundles that are only skipped, or in the skip range of other bundles, are replaced by them", registry.UpgradeEdgeSkips, registry.UpgradeEdgeSkipRange))
+	cmd.Flags().Float64Var(&s.accessLogSample, "access-log-sample-rate", 1, "fraction, between 0 and 1, of the calls that are logged with the number and size of their request and response messages. 0 disables the access log")
 	cmd.Flags().StringVar(&s.pprofAddr, "pprof-addr", "localhost:6060", "address of startup profiling endpoint (addr:port format)")
 	cmd.Flags().BoolVar(&s.captureProfiles, "pprof-capture-profiles", false, "capture pprof CPU profiles")
 	cmd.Flags().StringVar(&s.cacheDir, "cache-dir", "", "if set, sync and persist server cache directory")
+	cmd.Flags().StringVar(&s.cacheFormat, "cache-format", "", fmt.Sprintf("format of a newly built server cache (%s|%s|%s). mmap.v1 keeps bundles out of the heap until they are requested (default: pogreb.v1)", cache.FormatPogrebV1, cache.FormatJSON, cache.FormatMMapV1))
+	cmd.Flags().IntVar(&s.cacheBuildConcurrency, "cache-build-concurrency", 0, "number of catalog files parsed, and of packages loaded, concurrently when building the server cache (default: number of CPUs)")
+	cmd.Flags().StringVar(&s.cacheFragmentDir, "cache-fragment-dir", "", "if set, reuse the server cache content of packages built before from this directory, which may be shared by builds of many catalogs, and add the content of new packages to it")
 	cmd.Flags().BoolVar(&s.cacheOnly, "cache-only", false, "sync the serve cache and exit without serving")
 	cmd.Flags().BoolVar(&s.cacheEnforceIntegrity, "cache-enforce-integrity", false, "exit with error if cache is not present or has been invalidated. (default: true when --cache-dir is set and --cache-only is false, false otherwise), ")
+	cmd.Flags().BoolVar(&s.checkReproducible, "check-reproducible", false, "build the cache twice in temporary directories and exit with error if the builds differ, without serving. json and mmap.v1 caches are compared file by file, pogreb.v1 caches by digest")
 	return cmd
 }
 
@@ -116,72 +223,308 @@ func (s *serve) run(ctx context.Context) error {
 		mainLogger.WithError(err).Warn("unable to write default nsswitch config")
 	}
 
+	streamInterceptors, unaryInterceptors, err := s.interceptors()
+	if err != nil {
+		return err
+	}
+	serverOpts, err := s.serverOptions()
+	if err != nil {
+		return err
+	}
+	tlsConfig, err := s.tlsConfig()
+	if err != nil {
+		return err
+	}
+	authorizer, err := s.authorizer()
+	if err != nil {
+		return err
+	}
+
 	if s.cacheDir == "" && s.cacheEnforceIntegrity {
 		return fmt.Errorf("--cache-dir must be specified with --cache-enforce-integrity")
 	}
-
-	if s.cacheDir == "" {
-		s.cacheDir, err = os.MkdirTemp("", "opm-serve-cache-")
-		if err != nil {
-			return err
-		}
-		defer os.RemoveAll(s.cacheDir)
+	replacementPolicy, err := registry.ParseReplacementPolicy(s.syntheticReplaces)
+	if err != nil {
+		return fmt.Errorf("--synthetic-replaces: %v", err)
 	}
-	mainLogger = mainLogger.WithFields(logrus.Fields{
-		"configs": s.configDir,
-		"cache":   s.cacheDir,
-	})
+	mainLogger = mainLogger.WithField("configs", strings.Join(append(slices.Clone(s.configDirs), s.imageRefs...), ","))
 
-	store, err := cache.New(s.cacheDir, cache.WithLog(mainLogger))
+	cleanup, err := s.convertSqliteSources(ctx)
 	if err != nil {
 		return err
 	}
-	defer store.Close()
-	if s.cacheEnforceIntegrity {
-		if err := store.CheckIntegrity(ctx, os.DirFS(s.configDir)); err != nil {
-			return fmt.Errorf("integrity check failed: %v", err)
+	defer cleanup()
+	cleanupImages, err := s.unpackImageSources(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanupImages()
+	fbcFsys, err := s.configsFS(ctx)
+	if err != nil {
+		return err
+	}
+
+	cacheOpts := []cache.CacheOption{
+		cache.WithFormat(s.cacheFormat),
+		cache.WithOpmVersion(version.OpmVersion()),
+		cache.WithConcurrency(s.cacheBuildConcurrency),
+		cache.WithFragmentDir(s.cacheFragmentDir),
+		cache.WithReplacementPolicy(replacementPolicy),
+	}
+	if s.cacheOnly {
+		cacheOpts = append(cacheOpts, cache.WithBuildProgress(logBuildProgress(mainLogger)))
+	}
+	if s.checkReproducible {
+		if err := cache.CheckReproducible(ctx, fbcFsys, append(cacheOpts, cache.WithLog(mainLogger))...); err != nil {
+			return err
 		}
-		if err := store.Load(ctx); err != nil {
-			return fmt.Errorf("failed to load cache: %v", err)
+		mainLogger.Info("cache build is reproducible")
+		return nil
+	}
+
+	var lis net.Listener
+	if !s.cacheOnly {
+		if s.listen != "" {
+			lis, err = listen(s.listen)
+		} else {
+			lis, err = net.Listen("tcp", ":"+s.port)
 		}
-	} else {
-		if err := cache.LoadOrRebuild(ctx, store, os.DirFS(s.configDir)); err != nil {
-			return fmt.Errorf("failed to load or rebuild cache: %v", err)
+		if err != nil {
+			return fmt.Errorf("failed to listen: %s", err)
 		}
 	}
 
-	if s.cacheOnly {
-		return nil
+	return server.Run(ctx, server.Options{
+		ConfigFS:              fbcFsys,
+		CacheDir:              s.cacheDir,
+		CacheOptions:          cacheOpts,
+		CacheEnforceIntegrity: s.cacheEnforceIntegrity,
+		CacheOnly:             s.cacheOnly,
+		Listener:              lis,
+		StreamInterceptors:    streamInterceptors,
+		UnaryInterceptors:     unaryInterceptors,
+		ServerOptions:         serverOpts,
+		TLS:                   tlsConfig,
+		Authorizer:            authorizer,
+		OpmVersion:            version.OpmVersion(),
+		RegistryServerOptions: s.registryServerOptions(),
+		OnReady: func() {
+			p.stopCPUProfileCache()
+			go func() {
+				<-ctx.Done()
+				if err := p.stopEndpoint(ctx); err != nil {
+					mainLogger.Warnf("error shutting down pprof server: %v", err)
+				}
+			}()
+		},
+		Log: mainLogger,
+	})
+}
+
+// interceptors returns the interceptors of the server: those of the command,
+// which log calls, write the access log and compress responses, followed by
+// those added with the options of NewCmd.
+func (s *serve) interceptors() ([]grpc.StreamServerInterceptor, []grpc.UnaryServerInterceptor, error) {
+	streamLogger, unaryLogger := loggingInterceptors(s.logger.Dup())
+	stream := []grpc.StreamServerInterceptor{streamLogger}
+	unary := []grpc.UnaryServerInterceptor{unaryLogger}
+	if s.accessLogSample != 0 {
+		access, err := newAccessLog(s.logger.Dup(), s.accessLogSample)
+		if err != nil {
+			return nil, nil, err
+		}
+		stream = append(stream, access.streamInterceptor)
+		unary = append(unary, access.unaryInterceptor)
+	}
+	if s.streamCompression != "" {
+		compress, err := compressionInterceptor(s.streamCompression)
+		if err != nil {
+			return nil, nil, err
+		}
+		stream = append(stream, compress)
 	}
+	stream = append(stream, s.streamInterceptors...)
+	unary = append(unary, s.unaryInterceptors...)
+	return stream, unary, nil
+}
 
-	mainLogger = mainLogger.WithFields(logrus.Fields{"port": s.port})
+// serverOptions returns the options of the server that are set by flags.
+// registryServerOptions returns the options of the registry service.
+func (s *serve) registryServerOptions() []server.RegistryServerOption {
+	var opts []server.RegistryServerOption
+	if len(s.excludedPropertyTypes) > 0 {
+		opts = append(opts, server.WithExcludedPropertyTypes(s.excludedPropertyTypes...))
+	}
+	return opts
+}
+
+func (s *serve) serverOptions() ([]grpc.ServerOption, error) {
+	var opts []grpc.ServerOption
+	if s.grpcMaxRecvSize < 0 {
+		return nil, fmt.Errorf("--grpc-max-recv-size must not be negative")
+	}
+	if s.grpcMaxRecvSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(s.grpcMaxRecvSize))
+	}
+	if s.grpcMaxSendSize < 0 {
+		return nil, fmt.Errorf("--grpc-max-send-size must not be negative")
+	}
+	if s.grpcMaxSendSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(s.grpcMaxSendSize))
+	}
+	return opts, nil
+}
 
-	lis, err := net.Listen("tcp", ":"+s.port)
+// tlsConfig returns the TLS configuration of the server, or nil if it does not
+// serve TLS.
+func (s *serve) tlsConfig() (*tls.Config, error) {
+	if s.tlsCertFile == "" && s.tlsKeyFile == "" {
+		if s.tlsClientCAFile != "" {
+			return nil, fmt.Errorf("--tls-client-ca-file requires --tls-cert-file and --tls-key-file")
+		}
+		return nil, nil
+	}
+	if s.tlsCertFile == "" || s.tlsKeyFile == "" {
+		return nil, fmt.Errorf("--tls-cert-file and --tls-key-file must be specified together")
+	}
+	cert, err := tls.LoadX509KeyPair(s.tlsCertFile, s.tlsKeyFile)
 	if err != nil {
-		return fmt.Errorf("failed to listen: %s", err)
+		return nil, fmt.Errorf("load TLS certificate: %v", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if s.tlsClientCAFile != "" {
+		data, err := os.ReadFile(s.tlsClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS client CA: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("load TLS client CA: no certificates found in %q", s.tlsClientCAFile)
+		}
+		// Clients without certificates are still served, and may be
+		// identified by their tokens.
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
 	}
+	return config, nil
+}
 
-	streamLogger, unaryLogger := loggingInterceptors(s.logger.Dup())
-	grpcServer := grpc.NewServer(
-		grpc.ChainStreamInterceptor(streamLogger),
-		grpc.ChainUnaryInterceptor(unaryLogger),
-	)
-	api.RegisterRegistryServer(grpcServer, server.NewRegistryServer(store))
-	health.RegisterHealthServer(grpcServer, server.NewHealthServer())
-	reflection.Register(grpcServer)
-	mainLogger.Info("serving registry")
-	p.stopCPUProfileCache()
+// authorizer returns the package authorizer of the server, or nil if every
+// client may access every package.
+func (s *serve) authorizer() (server.PackageAuthorizer, error) {
+	switch {
+	case s.authzPolicyFile != "" && s.authzWebhookURL != "":
+		return nil, fmt.Errorf("only one of --authz-policy-file and --authz-webhook-url may be specified")
+	case s.authzPolicyFile != "":
+		return server.LoadAuthorizationPolicy(s.authzPolicyFile)
+	case s.authzWebhookURL != "":
+		return server.NewAuthorizationWebhook(s.authzWebhookURL), nil
+	}
+	return nil, nil
+}
 
-	go func() {
-		<-ctx.Done()
-		mainLogger.Info("shutting down server")
-		grpcServer.GracefulStop()
-		if err := p.stopEndpoint(ctx); err != nil {
-			mainLogger.Warnf("error shutting down pprof server: %v", err)
+// convertSqliteSources converts the sqlite database files among the served
+// sources to declarative configs in temporary directories, and replaces them
+// with these directories. The returned function removes the directories.
+func (s *serve) convertSqliteSources(ctx context.Context) (func(), error) {
+	var tmpDirs []string
+	cleanup := func() {
+		for _, dir := range tmpDirs {
+			os.RemoveAll(dir)
 		}
-	}()
+	}
+	for i, src := range s.configDirs {
+		stat, err := os.Stat(src)
+		if err != nil || stat.IsDir() {
+			continue
+		}
+		tmpDir, err := os.MkdirTemp("", "opm-serve-sqlite-")
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		tmpDirs = append(tmpDirs, tmpDir)
+
+		s.logger.WithField("database", src).Info("converting sqlite database to declarative config")
+		migrate := action.Migrate{
+			CatalogRef: src,
+			OutputDir:  tmpDir,
+			WriteFunc:  declcfg.WriteJSON,
+			FileExt:    ".json",
+		}
+		if err := migrate.Run(ctx); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("convert sqlite database %q: %v", src, err)
+		}
+		s.configDirs[i] = tmpDir
+	}
+	return cleanup, nil
+}
+
+// unpackImageSources pulls the images of --image and adds the declarative
+// config directory of each, named by its configs label, to the served config
+// directories. It returns a function that removes the unpacked images.
+func (s *serve) unpackImageSources(ctx context.Context) (func(), error) {
+	var tmpDirs []string
+	cleanup := func() {
+		for _, dir := range tmpDirs {
+			os.RemoveAll(dir)
+		}
+	}
+	for _, src := range s.imageRefs {
+		tmpDir, err := os.MkdirTemp("", "opm-serve-image-")
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		tmpDirs = append(tmpDirs, tmpDir)
+
+		s.logger.WithField("image", src).Info("unpacking declarative configs of image")
+		configsDir, err := unpackImageConfigs(ctx, s.registry, image.SimpleReference(src), tmpDir)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		s.configDirs = append(s.configDirs, configsDir)
+	}
+	return cleanup, nil
+}
 
-	return grpcServer.Serve(lis)
+// unpackImageConfigs unpacks the file-based catalog image ref into dir and
+// returns the path of its declarative config directory.
+func unpackImageConfigs(ctx context.Context, reg image.Registry, ref image.Reference, dir string) (string, error) {
+	if err := reg.Pull(ctx, ref); err != nil {
+		return "", fmt.Errorf("failed to pull image %q: %v", ref, err)
+	}
+	labels, err := reg.Labels(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to get labels for image %q: %v", ref, err)
+	}
+	configsDir, ok := labels[containertools.ConfigsLocationLabel]
+	if !ok {
+		return "", fmt.Errorf("image %q is not a file-based catalog image: label %q not found", ref, containertools.ConfigsLocationLabel)
+	}
+	if err := reg.Unpack(ctx, ref, dir); err != nil {
+		return "", fmt.Errorf("failed to unpack image %q: %v", ref, err)
+	}
+	return filepath.Join(dir, configsDir), nil
+}
+
+// configsFS returns the filesystem containing the served declarative configs.
+// Multiple config directories are merged into one filesystem, which fails if
+// a package is defined in more than one directory.
+func (s *serve) configsFS(ctx context.Context) (fs.FS, error) {
+	if len(s.configDirs) == 1 {
+		return os.DirFS(s.configDirs[0]), nil
+	}
+	roots := make([]declcfg.NamedFS, 0, len(s.configDirs))
+	for _, dir := range s.configDirs {
+		roots = append(roots, declcfg.NamedFS{Name: dir, FS: os.DirFS(dir)})
+	}
+	return declcfg.MergeFS(ctx, roots...)
 }
 
 // manages an HTTP pprof endpoint served by `server`,
@@ -351,3 +694,17 @@ func interceptorLogger(l *logrus.Entry) logging.Logger {
 		}
 	})
 }
+
+// logBuildProgress returns a cache build progress function that logs each time
+// another tenth of the packages of the catalog has been stored.
+func logBuildProgress(logger *logrus.Entry) func(cache.BuildProgress) {
+	var lastStep int
+	return func(p cache.BuildProgress) {
+		step := p.Packages * 10 / p.TotalPackages
+		if step == lastStep {
+			return
+		}
+		lastStep = step
+		logger.WithField("packages", p.Packages).WithField("totalPackages", p.TotalPackages).Infof("built cache for %d%% of packages", step*10)
+	}
+}
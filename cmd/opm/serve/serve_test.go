@@ -0,0 +1,243 @@
+package serve
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/cache"
+	"github.com/operator-framework/operator-registry/pkg/containertools"
+	"github.com/operator-framework/operator-registry/pkg/image"
+	"github.com/operator-framework/operator-registry/pkg/lib/dns"
+	"github.com/operator-framework/operator-registry/pkg/lib/log"
+)
+
+func TestConvertSqliteSources(t *testing.T) {
+	// Rendering a database migrates it, so a copy of it is served.
+	data, err := os.ReadFile("../../../pkg/lib/indexer/testdata/bundles.db")
+	require.NoError(t, err)
+	dbFile := filepath.Join(t.TempDir(), "bundles.db")
+	require.NoError(t, os.WriteFile(dbFile, data, 0600))
+
+	fbcDir := t.TempDir()
+	s := serve{
+		configDirs: []string{dbFile, fbcDir},
+		logger:     log.Null(),
+	}
+	cleanup, err := s.convertSqliteSources(context.Background())
+	require.NoError(t, err)
+
+	converted := s.configDirs[0]
+	require.NotEqual(t, dbFile, converted)
+	require.Equal(t, fbcDir, s.configDirs[1])
+	cfg, err := declcfg.LoadFS(context.Background(), os.DirFS(converted))
+	require.NoError(t, err)
+	require.NotEmpty(t, cfg.Packages)
+	require.NotEmpty(t, cfg.Bundles)
+
+	cleanup()
+	require.NoDirExists(t, converted)
+}
+
+func TestConvertSqliteSourcesInvalidFile(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "index.db")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	s := serve{configDirs: []string{f.Name()}, logger: log.Null()}
+	_, err = s.convertSqliteSources(context.Background())
+	require.ErrorContains(t, err, `convert sqlite database "`+f.Name()+`"`)
+}
+
+func TestUnpackImageSources(t *testing.T) {
+	reg := &image.MockRegistry{RemoteImages: map[image.Reference]*image.MockImage{
+		image.SimpleReference("test.registry/catalog:fbc"): {
+			Labels: map[string]string{containertools.ConfigsLocationLabel: "/configs"},
+			FS: fstest.MapFS{
+				"configs/foo/catalog.json": &fstest.MapFile{Data: []byte(`{"schema": "olm.package", "name": "foo"}`)},
+			},
+		},
+		image.SimpleReference("test.registry/catalog:sqlite"): {
+			Labels: map[string]string{containertools.DbLocationLabel: "/database/index.db"},
+			FS:     fstest.MapFS{"database/index.db": &fstest.MapFile{}},
+		},
+	}}
+
+	t.Run("FBC", func(t *testing.T) {
+		fbcDir := t.TempDir()
+		s := serve{
+			configDirs: []string{fbcDir},
+			imageRefs:  []string{"test.registry/catalog:fbc"},
+			registry:   reg,
+			logger:     log.Null(),
+		}
+		cleanup, err := s.unpackImageSources(context.Background())
+		require.NoError(t, err)
+
+		require.Len(t, s.configDirs, 2)
+		require.Equal(t, fbcDir, s.configDirs[0])
+		unpacked := s.configDirs[1]
+		require.Equal(t, "configs", filepath.Base(unpacked))
+		cfg, err := declcfg.LoadFS(context.Background(), os.DirFS(unpacked))
+		require.NoError(t, err)
+		require.Len(t, cfg.Packages, 1)
+		require.Equal(t, "foo", cfg.Packages[0].Name)
+
+		cleanup()
+		require.NoDirExists(t, unpacked)
+	})
+	t.Run("NotFBC", func(t *testing.T) {
+		s := serve{imageRefs: []string{"test.registry/catalog:sqlite"}, registry: reg, logger: log.Null()}
+		_, err := s.unpackImageSources(context.Background())
+		require.EqualError(t, err, `image "test.registry/catalog:sqlite" is not a file-based catalog image: label "`+containertools.ConfigsLocationLabel+`" not found`)
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		s := serve{imageRefs: []string{"test.registry/catalog:missing"}, registry: reg, logger: log.Null()}
+		_, err := s.unpackImageSources(context.Background())
+		require.ErrorContains(t, err, `failed to pull image "test.registry/catalog:missing"`)
+	})
+}
+
+func TestRunMergedPackageCollision(t *testing.T) {
+	// The package foo is split across two directories. A cache built from
+	// their merged layout without checking for collisions, e.g. by another
+	// tool, passes the integrity check, so the collision must be detected
+	// before the cache is loaded.
+	const (
+		fooPackage = `{"schema": "olm.package", "name": "foo", "defaultChannel": "stable"}
+{"schema": "olm.channel", "package": "foo", "name": "stable", "entries": [{"name": "foo.v0.1.0"}, {"name": "foo.v0.2.0", "replaces": "foo.v0.1.0"}]}
+{"schema": "olm.bundle", "package": "foo", "name": "foo.v0.1.0", "image": "test.registry/foo-bundle:v0.1.0", "properties": [{"type": "olm.package", "value": {"packageName": "foo", "version": "0.1.0"}}]}
+`
+		fooBundle = `{"schema": "olm.bundle", "package": "foo", "name": "foo.v0.2.0", "image": "test.registry/foo-bundle:v0.2.0", "properties": [{"type": "olm.package", "value": {"packageName": "foo", "version": "0.2.0"}}]}
+`
+	)
+	dirA, dirB := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dirA, "catalog.json"), []byte(fooPackage), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dirB, "catalog.json"), []byte(fooBundle), 0600))
+
+	cacheDir := t.TempDir()
+	c, err := cache.New(cacheDir, cache.WithLog(log.Null()))
+	require.NoError(t, err)
+	require.NoError(t, c.Build(context.Background(), fstest.MapFS{
+		"0/catalog.json": &fstest.MapFile{Data: []byte(fooPackage)},
+		"1/catalog.json": &fstest.MapFile{Data: []byte(fooBundle)},
+	}))
+	require.NoError(t, c.Close())
+
+	nsswitchFilename := dns.NsswitchFilename
+	dns.NsswitchFilename = filepath.Join(t.TempDir(), "nsswitch.conf")
+	defer func() { dns.NsswitchFilename = nsswitchFilename }()
+
+	s := serve{
+		configDirs:            []string{dirA, dirB},
+		cacheDir:              cacheDir,
+		cacheEnforceIntegrity: true,
+		cacheOnly:             true,
+		terminationLog:        filepath.Join(t.TempDir(), "termination-log"),
+		logger:                log.Null(),
+	}
+	require.EqualError(t, s.run(context.Background()), fmt.Sprintf("package %q is defined in both %q and %q", "foo", dirA, dirB))
+}
+
+func TestInterceptors(t *testing.T) {
+	var called []string
+	s := serve{streamCompression: "gzip", accessLogSample: 1, logger: log.Null()}
+	WithUnaryInterceptors(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		called = append(called, "unary")
+		return handler(ctx, req)
+	})(&s)
+	WithStreamInterceptors(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		called = append(called, "stream")
+		return handler(srv, ss)
+	})(&s)
+
+	stream, unary, err := s.interceptors()
+	require.NoError(t, err)
+	// The interceptors of the options follow those that log, write the access
+	// log and compress.
+	require.Len(t, stream, 4)
+	require.Len(t, unary, 3)
+
+	_, err = unary[2](context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) { return nil, nil })
+	require.NoError(t, err)
+	require.NoError(t, stream[3](nil, nil, &grpc.StreamServerInfo{}, func(any, grpc.ServerStream) error { return nil }))
+	require.Equal(t, []string{"unary", "stream"}, called)
+
+	s.streamCompression = "lz4"
+	_, _, err = s.interceptors()
+	require.ErrorContains(t, err, `unsupported stream compression "lz4"`)
+}
+
+func TestServerOptions(t *testing.T) {
+	opts, err := (&serve{}).serverOptions()
+	require.NoError(t, err)
+	require.Empty(t, opts)
+
+	opts, err = (&serve{grpcMaxRecvSize: 16 << 20, grpcMaxSendSize: 16 << 20}).serverOptions()
+	require.NoError(t, err)
+	require.Len(t, opts, 2)
+
+	_, err = (&serve{grpcMaxRecvSize: -1}).serverOptions()
+	require.EqualError(t, err, "--grpc-max-recv-size must not be negative")
+	_, err = (&serve{grpcMaxSendSize: -1}).serverOptions()
+	require.EqualError(t, err, "--grpc-max-send-size must not be negative")
+}
+
+func TestTLSConfig(t *testing.T) {
+	config, err := (&serve{}).tlsConfig()
+	require.NoError(t, err)
+	require.Nil(t, config)
+
+	_, err = (&serve{tlsCertFile: "tls.crt"}).tlsConfig()
+	require.EqualError(t, err, "--tls-cert-file and --tls-key-file must be specified together")
+	_, err = (&serve{tlsClientCAFile: "ca.crt"}).tlsConfig()
+	require.EqualError(t, err, "--tls-client-ca-file requires --tls-cert-file and --tls-key-file")
+}
+
+func TestAuthorizer(t *testing.T) {
+	authorizer, err := (&serve{}).authorizer()
+	require.NoError(t, err)
+	require.Nil(t, authorizer)
+
+	_, err = (&serve{authzPolicyFile: "policy.yaml", authzWebhookURL: "http://localhost"}).authorizer()
+	require.EqualError(t, err, "only one of --authz-policy-file and --authz-webhook-url may be specified")
+
+	policyFile := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(policyFile, []byte(`public: ["etcd"]`), 0600))
+	authorizer, err = (&serve{authzPolicyFile: policyFile}).authorizer()
+	require.NoError(t, err)
+	require.NotNil(t, authorizer)
+}
+
+func TestLogBuildProgress(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	progress := logBuildProgress(logrus.NewEntry(logger))
+	for i := 1; i <= 25; i++ {
+		progress(cache.BuildProgress{Packages: i, TotalPackages: 25})
+	}
+	var messages []string
+	for _, entry := range hook.AllEntries() {
+		messages = append(messages, entry.Message)
+	}
+	require.Equal(t, []string{
+		"built cache for 10% of packages",
+		"built cache for 20% of packages",
+		"built cache for 30% of packages",
+		"built cache for 40% of packages",
+		"built cache for 50% of packages",
+		"built cache for 60% of packages",
+		"built cache for 70% of packages",
+		"built cache for 80% of packages",
+		"built cache for 90% of packages",
+		"built cache for 100% of packages",
+	}, messages)
+}
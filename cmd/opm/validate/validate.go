@@ -4,19 +4,38 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/containers/image/v5/types"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/operator-framework/operator-registry/cmd/opm/internal/util"
 	"github.com/operator-framework/operator-registry/pkg/lib/config"
 )
 
 func NewCmd() *cobra.Command {
 	logger := logrus.New()
+	var (
+		checkImages           bool
+		strict                bool
+		imageCheckConcurrency int
+		loadConcurrency       int
+	)
 	validate := &cobra.Command{
 		Use:   "validate <directory>",
 		Short: "Validate the declarative index config",
-		Long:  "Validate the declarative config JSON file(s) in a given directory",
-		Args:  cobra.ExactArgs(1),
+		Long: `Validate the declarative config JSON file(s) in a given directory. The files
+are parsed concurrently, and every file that can't be parsed is reported with
+the line of the offending object.
+
+With --strict, blobs of unknown schemas, properties of unknown types and
+duplicate properties are reported as errors instead of being passed through,
+to catch typos like "olm.packge".
+
+With --check-images, the manifest of every bundle image and related image is
+also queried from its registry, with the credentials used by podman and docker,
+to report references that can't be pulled, and references with both a tag and
+a digest whose tag no longer resolves to the digest.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(c *cobra.Command, args []string) error {
 			directory := args[0]
 			s, err := os.Stat(directory)
@@ -27,12 +46,31 @@ func NewCmd() *cobra.Command {
 				return fmt.Errorf("%q is not a directory", directory)
 			}
 
-			if err := config.Validate(c.Context(), os.DirFS(directory)); err != nil {
+			opts := []config.ValidateOption{
+				config.WithLog(logrus.NewEntry(logger)),
+				config.WithLoadConcurrency(loadConcurrency),
+			}
+			if strict {
+				opts = append(opts, config.WithStrict())
+			}
+			if checkImages {
+				skipTLSVerify, useHTTP, err := util.GetTLSOptions(c)
+				if err != nil {
+					return err
+				}
+				sysCtx := &types.SystemContext{DockerInsecureSkipTLSVerify: types.NewOptionalBool(skipTLSVerify || useHTTP)}
+				opts = append(opts, config.WithImageCheck(sysCtx, imageCheckConcurrency))
+			}
+			if err := config.Validate(c.Context(), os.DirFS(directory), opts...); err != nil {
 				logger.Fatal(err)
 			}
 			return nil
 		},
 	}
 
+	validate.Flags().BoolVar(&strict, "strict", false, "fail on unknown schemas, unknown property types and duplicate properties")
+	validate.Flags().BoolVar(&checkImages, "check-images", false, "check that the images referenced by the catalog can be pulled")
+	validate.Flags().IntVar(&imageCheckConcurrency, "image-check-concurrency", 10, "maximum number of images checked concurrently")
+	validate.Flags().IntVar(&loadConcurrency, "load-concurrency", 0, "number of catalog files parsed concurrently (default: number of CPUs)")
 	return validate
 }
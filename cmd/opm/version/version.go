@@ -1,10 +1,15 @@
 package version
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"runtime"
 
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-registry/pkg/server"
 )
 
 var (
@@ -23,16 +28,39 @@ type Version struct {
 	BuildDate  string `json:"buildDate"`
 	GoOs       string `json:"goOs"`
 	GoArch     string `json:"goArch"`
+
+	// APIVersion and APIMethods are the version and the methods of the
+	// Registry API that "opm serve" serves.
+	APIVersion string   `json:"apiVersion"`
+	APIMethods []string `json:"apiMethods"`
+	// Schemas are the declarative config schemas that opm supports.
+	Schemas []string `json:"schemas"`
+	// Migrations are the tokens of the migrations of "opm migrate".
+	Migrations []string `json:"migrations"`
 }
 
 func getVersion() Version {
+	info := server.NewServerInfo(OpmVersion())
 	return Version{
 		OpmVersion: opmVersion,
 		GitCommit:  gitCommit,
 		BuildDate:  buildDate,
 		GoOs:       runtime.GOOS,
 		GoArch:     runtime.GOARCH,
+		APIVersion: info.ApiVersion,
+		APIMethods: info.Methods,
+		Schemas:    info.Schemas,
+		Migrations: info.Migrations,
+	}
+}
+
+// OpmVersion returns the version of the opm binary, or an empty string if it
+// was not set during the build.
+func OpmVersion() string {
+	if opmVersion == "unknown" {
+		return ""
 	}
+	return opmVersion
 }
 
 func (v Version) Print() {
@@ -40,17 +68,40 @@ func (v Version) Print() {
 }
 
 func AddCommand(parent *cobra.Command) {
+	var output string
 	cmd := &cobra.Command{
-		Use:     "version",
-		Short:   "Print the opm version",
-		Long:    `Print the opm version`,
-		Example: `kubebuilder version`,
-		Run:     runVersion,
+		Use:   "version",
+		Short: "Print the opm version",
+		Long: `Print the opm version, with the version and methods of the Registry API it
+serves, the declarative config schemas it supports and the migrations it can
+apply, so that automation can verify the compatibility of its toolchain.`,
+		Example: `opm version -o json`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return printVersion(cmd.OutOrStdout(), getVersion(), output)
+		},
 	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format (json|yaml). By default, the version is printed as a Go value")
 
 	parent.AddCommand(cmd)
 }
 
-func runVersion(_ *cobra.Command, _ []string) {
-	getVersion().Print()
+func printVersion(w io.Writer, v Version, output string) error {
+	switch output {
+	case "":
+		_, err := fmt.Fprintf(w, "Version: %#v\n", v)
+		return err
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "    ")
+		return enc.Encode(v)
+	case "yaml":
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	return fmt.Errorf("invalid output format %q", output)
 }
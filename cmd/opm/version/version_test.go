@@ -0,0 +1,35 @@
+package version
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+)
+
+func TestPrintVersion(t *testing.T) {
+	v := getVersion()
+	require.Equal(t, api.Version, v.APIVersion)
+	require.Contains(t, v.APIMethods, "GetServerInfo")
+	require.Contains(t, v.Schemas, "olm.package")
+	require.Contains(t, v.Migrations, "bundle-object-to-csv-metadata")
+
+	var buf bytes.Buffer
+	require.NoError(t, printVersion(&buf, v, "json"))
+	var printed Version
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &printed))
+	require.Equal(t, v, printed)
+
+	buf.Reset()
+	require.NoError(t, printVersion(&buf, v, "yaml"))
+	require.Contains(t, buf.String(), "apiVersion: "+api.Version)
+
+	buf.Reset()
+	require.NoError(t, printVersion(&buf, v, ""))
+	require.Contains(t, buf.String(), "Version: version.Version{")
+
+	require.EqualError(t, printVersion(&buf, v, "table"), `invalid output format "table"`)
+}
@@ -0,0 +1,210 @@
+// Package apitest provides an implementation of api.RegistryClient that serves
+// a declarative config in-process, for unit tests of registry clients that
+// should not depend on a running registry.
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"testing/fstest"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/api"
+	"github.com/operator-framework/operator-registry/pkg/cache"
+	"github.com/operator-framework/operator-registry/pkg/server"
+)
+
+// Client is an api.RegistryClient that answers requests by calling a registry
+// server directly, without a gRPC connection. Like a gRPC client, it returns
+// the errors of the server as status errors. Call options are ignored.
+type Client struct {
+	store    cache.Cache
+	server   *server.RegistryServer
+	cacheDir string
+}
+
+var _ api.RegistryClient = &Client{}
+
+// NewClient returns a Client that serves cfg. The config is indexed as by
+// "opm serve", in a temporary cache directory that is removed by Close.
+func NewClient(ctx context.Context, cfg declcfg.DeclarativeConfig) (*Client, error) {
+	var buf bytes.Buffer
+	if err := declcfg.WriteJSON(cfg, &buf); err != nil {
+		return nil, fmt.Errorf("write declarative config: %v", err)
+	}
+	catalogFS := fstest.MapFS{"catalog.json": &fstest.MapFile{Data: buf.Bytes()}}
+
+	cacheDir, err := os.MkdirTemp("", "apitest-cache-")
+	if err != nil {
+		return nil, err
+	}
+	store, err := cache.New(cacheDir)
+	if err != nil {
+		_ = os.RemoveAll(cacheDir)
+		return nil, err
+	}
+	c := &Client{store: store, server: server.NewRegistryServer(store), cacheDir: cacheDir}
+	if err := store.Build(ctx, catalogFS); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("build cache: %v", err)
+	}
+	if err := store.Load(ctx); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("load cache: %v", err)
+	}
+	return c, nil
+}
+
+// Close closes the cache of c and removes its directory.
+func (c *Client) Close() error {
+	err := c.store.Close()
+	if rerr := os.RemoveAll(c.cacheDir); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+func (c *Client) ListPackages(ctx context.Context, in *api.ListPackageRequest, _ ...grpc.CallOption) (api.Registry_ListPackagesClient, error) {
+	s := &serverStream[*api.PackageName]{ctx: ctx}
+	return clientStreamOf(ctx, s, c.server.ListPackages(in, s))
+}
+
+func (c *Client) GetPackage(ctx context.Context, in *api.GetPackageRequest, _ ...grpc.CallOption) (*api.Package, error) {
+	return response(c.server.GetPackage(ctx, in))
+}
+
+func (c *Client) GetBundle(ctx context.Context, in *api.GetBundleRequest, _ ...grpc.CallOption) (*api.Bundle, error) {
+	return response(c.server.GetBundle(ctx, in))
+}
+
+func (c *Client) GetBundleForChannel(ctx context.Context, in *api.GetBundleInChannelRequest, _ ...grpc.CallOption) (*api.Bundle, error) {
+	return response(c.server.GetBundleForChannel(ctx, in))
+}
+
+func (c *Client) GetChannelEntriesThatReplace(ctx context.Context, in *api.GetAllReplacementsRequest, _ ...grpc.CallOption) (api.Registry_GetChannelEntriesThatReplaceClient, error) {
+	s := &serverStream[*api.ChannelEntry]{ctx: ctx}
+	return clientStreamOf(ctx, s, c.server.GetChannelEntriesThatReplace(in, s))
+}
+
+func (c *Client) GetBundleThatReplaces(ctx context.Context, in *api.GetReplacementRequest, _ ...grpc.CallOption) (*api.Bundle, error) {
+	return response(c.server.GetBundleThatReplaces(ctx, in))
+}
+
+func (c *Client) GetChannelEntriesThatProvide(ctx context.Context, in *api.GetAllProvidersRequest, _ ...grpc.CallOption) (api.Registry_GetChannelEntriesThatProvideClient, error) {
+	s := &serverStream[*api.ChannelEntry]{ctx: ctx}
+	return clientStreamOf(ctx, s, c.server.GetChannelEntriesThatProvide(in, s))
+}
+
+func (c *Client) GetLatestChannelEntriesThatProvide(ctx context.Context, in *api.GetLatestProvidersRequest, _ ...grpc.CallOption) (api.Registry_GetLatestChannelEntriesThatProvideClient, error) {
+	s := &serverStream[*api.ChannelEntry]{ctx: ctx}
+	return clientStreamOf(ctx, s, c.server.GetLatestChannelEntriesThatProvide(in, s))
+}
+
+func (c *Client) GetDefaultBundleThatProvides(ctx context.Context, in *api.GetDefaultProviderRequest, _ ...grpc.CallOption) (*api.Bundle, error) {
+	return response(c.server.GetDefaultBundleThatProvides(ctx, in))
+}
+
+func (c *Client) ListBundles(ctx context.Context, in *api.ListBundlesRequest, _ ...grpc.CallOption) (api.Registry_ListBundlesClient, error) {
+	s := &serverStream[*api.Bundle]{ctx: ctx}
+	return clientStreamOf(ctx, s, c.server.ListBundles(in, s))
+}
+
+func (c *Client) GetCatalogInfo(ctx context.Context, in *api.GetCatalogInfoRequest, _ ...grpc.CallOption) (*api.CatalogInfo, error) {
+	return response(c.server.GetCatalogInfo(ctx, in))
+}
+
+func (c *Client) GetUpgradeGraph(ctx context.Context, in *api.GetUpgradeGraphRequest, _ ...grpc.CallOption) (*api.UpgradeGraph, error) {
+	return response(c.server.GetUpgradeGraph(ctx, in))
+}
+
+func (c *Client) GetServerInfo(ctx context.Context, in *api.GetServerInfoRequest, _ ...grpc.CallOption) (*api.ServerInfo, error) {
+	return response(c.server.GetServerInfo(ctx, in))
+}
+
+func (c *Client) ListPackageSummaries(ctx context.Context, in *api.ListPackageSummariesRequest, _ ...grpc.CallOption) (api.Registry_ListPackageSummariesClient, error) {
+	s := &serverStream[*api.PackageSummary]{ctx: ctx}
+	return clientStreamOf(ctx, s, c.server.ListPackageSummaries(in, s))
+}
+
+func (c *Client) GetCatalogStats(ctx context.Context, in *api.GetCatalogStatsRequest, _ ...grpc.CallOption) (*api.CatalogStats, error) {
+	return response(c.server.GetCatalogStats(ctx, in))
+}
+
+// response returns the response of a unary call, or its error as a status
+// error, as a gRPC client would.
+func response[M proto.Message](m M, err error) (M, error) {
+	if err != nil {
+		var zero M
+		return zero, status.Convert(err).Err()
+	}
+	return m, nil
+}
+
+// serverStream collects the messages sent by a server method.
+type serverStream[M proto.Message] struct {
+	grpc.ServerStream
+	ctx  context.Context
+	msgs []M
+}
+
+func (s *serverStream[M]) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream[M]) Send(m M) error {
+	s.msgs = append(s.msgs, m)
+	return nil
+}
+
+// clientStreamOf returns a client stream that receives the messages collected
+// by s. Like a gRPC client stream, it returns the error of the server method,
+// if any, once the messages are received.
+func clientStreamOf[M proto.Message](ctx context.Context, s *serverStream[M], err error) (*clientStream[M], error) {
+	if err != nil {
+		err = status.Convert(err).Err()
+	}
+	return &clientStream[M]{ctx: ctx, msgs: s.msgs, err: err}, nil
+}
+
+type clientStream[M proto.Message] struct {
+	ctx  context.Context
+	msgs []M
+	err  error
+}
+
+func (s *clientStream[M]) Recv() (M, error) {
+	if len(s.msgs) == 0 {
+		var zero M
+		if s.err != nil {
+			return zero, s.err
+		}
+		return zero, io.EOF
+	}
+	m := s.msgs[0]
+	s.msgs = s.msgs[1:]
+	return m, nil
+}
+
+func (s *clientStream[M]) RecvMsg(m interface{}) error {
+	next, err := s.Recv()
+	if err != nil {
+		return err
+	}
+	proto.Reset(m.(proto.Message))
+	proto.Merge(m.(proto.Message), next)
+	return nil
+}
+
+func (s *clientStream[M]) Header() (metadata.MD, error) { return metadata.MD{}, nil }
+func (s *clientStream[M]) Trailer() metadata.MD         { return metadata.MD{} }
+func (s *clientStream[M]) CloseSend() error             { return nil }
+func (s *clientStream[M]) Context() context.Context     { return s.ctx }
+func (s *clientStream[M]) SendMsg(interface{}) error    { return nil }
@@ -0,0 +1,97 @@
+package apitest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/api"
+	"github.com/operator-framework/operator-registry/pkg/registry/registrytest"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	cfg, err := declcfg.LoadFS(ctx, registrytest.Catalog())
+	require.NoError(t, err)
+
+	c, err := NewClient(ctx, *cfg)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, c.Close())
+	}()
+
+	t.Run("ListPackages", func(t *testing.T) {
+		stream, err := c.ListPackages(ctx, &api.ListPackageRequest{})
+		require.NoError(t, err)
+		var names []string
+		for {
+			p, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			names = append(names, p.GetName())
+		}
+		require.ElementsMatch(t, []string{"etcd", "prometheus"}, names)
+	})
+
+	t.Run("GetPackage", func(t *testing.T) {
+		pkg, err := c.GetPackage(ctx, &api.GetPackageRequest{Name: "etcd"})
+		require.NoError(t, err)
+		require.Equal(t, "alpha", pkg.GetDefaultChannelName())
+		require.Len(t, pkg.GetChannels(), 3)
+
+		_, err = c.GetPackage(ctx, &api.GetPackageRequest{Name: "missing"})
+		require.Error(t, err)
+		_, ok := status.FromError(err)
+		require.True(t, ok, "%v is not a status error", err)
+	})
+
+	t.Run("GetBundle", func(t *testing.T) {
+		b, err := c.GetBundle(ctx, &api.GetBundleRequest{PkgName: "etcd", ChannelName: "beta", CsvName: "etcdoperator.v0.9.0"})
+		require.NoError(t, err)
+		require.Equal(t, "quay.io/coreos/etcd-operator-bundle:v0.9.0", b.GetBundlePath())
+	})
+
+	t.Run("ListBundles", func(t *testing.T) {
+		stream, err := c.ListBundles(ctx, &api.ListBundlesRequest{})
+		require.NoError(t, err)
+		count := 0
+		for {
+			var b api.Bundle
+			err := stream.RecvMsg(&b)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, b.GetCsvName())
+			count++
+		}
+		require.Equal(t, 9, count)
+	})
+
+	t.Run("GetUpgradeGraph", func(t *testing.T) {
+		_, err := c.GetUpgradeGraph(ctx, &api.GetUpgradeGraphRequest{PkgName: "missing"})
+		require.Error(t, err)
+		require.NotEqual(t, codes.OK, status.Code(err))
+	})
+
+	t.Run("GetCatalogInfo", func(t *testing.T) {
+		info, err := c.GetCatalogInfo(ctx, &api.GetCatalogInfoRequest{})
+		require.NoError(t, err)
+		require.NotEmpty(t, info.GetDigest())
+	})
+
+	t.Run("GetCatalogStats", func(t *testing.T) {
+		stats, err := c.GetCatalogStats(ctx, &api.GetCatalogStatsRequest{})
+		require.NoError(t, err)
+		require.Equal(t, int32(len(cfg.Packages)), stats.GetPackages())
+		require.Equal(t, int32(len(cfg.Bundles)), stats.GetBundles())
+	})
+}
@@ -4,8 +4,11 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"strings"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
 
 	"github.com/operator-framework/api/pkg/lib/version"
 	"github.com/operator-framework/api/pkg/operators"
@@ -21,8 +24,21 @@ func ConvertModelBundleToAPIBundle(b model.Bundle) (*Bundle, error) {
 		return nil, fmt.Errorf("parse properties: %v", err)
 	}
 
+	size, err := bundleSize(b, props)
+	if err != nil {
+		return nil, fmt.Errorf("compute bundle size: %v", err)
+	}
+
+	var mediaType string
+	if len(props.BundleMediaTypes) > 0 {
+		mediaType = string(props.BundleMediaTypes[0])
+	}
+
+	// Only registry+v1 bundles have a CSV, so none is synthesized for the
+	// bundles of other media types.
+	isRegistryV1 := mediaType == "" || mediaType == string(property.MediaTypeRegistryV1)
 	csvJSON := b.CsvJSON
-	if csvJSON == "" && len(props.CSVMetadatas) == 1 {
+	if csvJSON == "" && len(props.CSVMetadatas) == 1 && isRegistryV1 {
 		var icons []v1alpha1.Icon
 		if b.Package.Icon != nil {
 			icons = []v1alpha1.Icon{{
@@ -64,13 +80,23 @@ func ConvertModelBundleToAPIBundle(b model.Bundle) (*Bundle, error) {
 	if err != nil {
 		return nil, fmt.Errorf("convert model properties to api dependencies: %v", err)
 	}
+
+	plurals := apiPlurals(b.Objects, props.CSVMetadatas)
+
+	var minKubeVersion, maxOpenShiftVersion string
+	if len(props.MinKubeVersions) > 0 {
+		minKubeVersion = string(props.MinKubeVersions[0])
+	}
+	if len(props.MaxOpenShiftVersions) > 0 {
+		maxOpenShiftVersion = string(props.MaxOpenShiftVersions[0])
+	}
 	return &Bundle{
 		CsvName:      b.Name,
 		PackageName:  b.Package.Name,
 		ChannelName:  b.Channel.Name,
 		BundlePath:   b.Image,
-		ProvidedApis: gvksProvidedtoAPIGVKs(props.GVKs),
-		RequiredApis: gvksRequirestoAPIGVKs(props.GVKsRequired),
+		ProvidedApis: gvksProvidedtoAPIGVKs(props.GVKs, plurals),
+		RequiredApis: gvksRequirestoAPIGVKs(props.GVKsRequired, plurals),
 		Version:      props.Packages[0].Version,
 		SkipRange:    b.SkipRange,
 		Dependencies: apiDeps,
@@ -80,6 +106,34 @@ func ConvertModelBundleToAPIBundle(b model.Bundle) (*Bundle, error) {
 		CsvJson:      csvJSON,
 		Object:       b.Objects,
 		Deprecation:  deprecation,
+		Size:         size,
+
+		MinKubeVersion:      minKubeVersion,
+		MaxOpenShiftVersion: maxOpenShiftVersion,
+		MediaType:           mediaType,
+	}, nil
+}
+
+// bundleSize returns the size of the bundle from its olm.bundle.size property
+// if it has one, and otherwise computes it from the bundle's objects. It
+// returns nil if the bundle has no objects to compute the size from.
+func bundleSize(b model.Bundle, props *property.Properties) (*BundleSize, error) {
+	var size *property.BundleSize
+	switch {
+	case len(props.BundleSizes) > 0:
+		size = &props.BundleSizes[0]
+	case len(b.Objects) > 0:
+		var err error
+		if size, err = property.ComputeBundleSize(b.Objects); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+	return &BundleSize{
+		ManifestBytes: size.ManifestBytes,
+		Objects:       int32(size.Objects),
+		Crds:          int32(size.CRDs),
 	}, nil
 }
 
@@ -127,7 +181,7 @@ func csvMetadataToCsv(m property.CSVMetadata) v1alpha1.ClusterServiceVersion {
 	}
 }
 
-func gvksProvidedtoAPIGVKs(in []property.GVK) []*GroupVersionKind {
+func gvksProvidedtoAPIGVKs(in []property.GVK, plurals map[property.GVK]string) []*GroupVersionKind {
 	// nolint:prealloc
 	var out []*GroupVersionKind
 	for _, gvk := range in {
@@ -135,11 +189,12 @@ func gvksProvidedtoAPIGVKs(in []property.GVK) []*GroupVersionKind {
 			Group:   gvk.Group,
 			Version: gvk.Version,
 			Kind:    gvk.Kind,
+			Plural:  plurals[gvk],
 		})
 	}
 	return out
 }
-func gvksRequirestoAPIGVKs(in []property.GVKRequired) []*GroupVersionKind {
+func gvksRequirestoAPIGVKs(in []property.GVKRequired, plurals map[property.GVK]string) []*GroupVersionKind {
 	// nolint:prealloc
 	var out []*GroupVersionKind
 	for _, gvk := range in {
@@ -147,11 +202,76 @@ func gvksRequirestoAPIGVKs(in []property.GVKRequired) []*GroupVersionKind {
 			Group:   gvk.Group,
 			Version: gvk.Version,
 			Kind:    gvk.Kind,
+			Plural:  plurals[property.GVK(gvk)],
 		})
 	}
 	return out
 }
 
+// apiPlurals returns the plural resource names of the APIs that a bundle
+// provides and requires, as the sqlite database records them: from the
+// bundle's CRDs, and from the names of the CRD and API service descriptions of
+// its CSV, either in its objects or in its olm.csv.metadata property. The
+// plurals of the CRDs take precedence.
+func apiPlurals(objects []string, csvMetadatas []property.CSVMetadata) map[property.GVK]string {
+	plurals := map[property.GVK]string{}
+	addDescriptions := func(crds v1alpha1.CustomResourceDefinitions, apis v1alpha1.APIServiceDefinitions) {
+		for _, crd := range append(crds.Owned, crds.Required...) {
+			plural, group, ok := strings.Cut(crd.Name, ".")
+			if !ok {
+				continue
+			}
+			plurals[property.GVK{Group: group, Version: crd.Version, Kind: crd.Kind}] = plural
+		}
+		for _, api := range append(apis.Owned, apis.Required...) {
+			plurals[property.GVK{Group: api.Group, Version: api.Version, Kind: api.Kind}] = api.Name
+		}
+	}
+	for _, m := range csvMetadatas {
+		addDescriptions(m.CustomResourceDefinitions, m.APIServiceDefinitions)
+	}
+
+	// Objects that can't be parsed have no plurals. They fail the
+	// computation of the bundle's size instead.
+	var crds []apiextensionsv1.CustomResourceDefinition
+	for _, obj := range objects {
+		var meta metav1.TypeMeta
+		if err := yaml.Unmarshal([]byte(obj), &meta); err != nil {
+			continue
+		}
+		switch meta.Kind {
+		case operators.ClusterServiceVersionKind:
+			var csv v1alpha1.ClusterServiceVersion
+			if err := yaml.Unmarshal([]byte(obj), &csv); err != nil {
+				continue
+			}
+			addDescriptions(csv.Spec.CustomResourceDefinitions, csv.Spec.APIServiceDefinitions)
+		case "CustomResourceDefinition":
+			var crd apiextensionsv1.CustomResourceDefinition
+			if err := yaml.Unmarshal([]byte(obj), &crd); err != nil {
+				continue
+			}
+			// v1beta1 CRDs may declare a single version in spec.version,
+			// which v1 CRDs do not have.
+			var v1beta1 struct {
+				Spec struct {
+					Version string `json:"version"`
+				} `json:"spec"`
+			}
+			if err := yaml.Unmarshal([]byte(obj), &v1beta1); err == nil && v1beta1.Spec.Version != "" {
+				crd.Spec.Versions = append(crd.Spec.Versions, apiextensionsv1.CustomResourceDefinitionVersion{Name: v1beta1.Spec.Version})
+			}
+			crds = append(crds, crd)
+		}
+	}
+	for _, crd := range crds {
+		for _, v := range crd.Spec.Versions {
+			plurals[property.GVK{Group: crd.Spec.Group, Version: v.Name, Kind: crd.Spec.Names.Kind}] = crd.Spec.Names.Plural
+		}
+	}
+	return plurals
+}
+
 func convertModelPropertiesToAPIProperties(props []property.Property) []*Property {
 	// nolint:prealloc
 	var out []*Property
@@ -194,6 +314,11 @@ func convertModelPropertiesToAPIDependencies(props []property.Property) ([]*Depe
 				Type:  pkg.Type,
 				Value: string(pkg.Value),
 			})
+		case property.TypeConstraint:
+			out = append(out, &Dependency{
+				Type:  property.TypeConstraint,
+				Value: string(prop.Value),
+			})
 		}
 	}
 	return out, nil
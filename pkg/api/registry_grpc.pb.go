@@ -29,6 +29,11 @@ const (
 	Registry_GetLatestChannelEntriesThatProvide_FullMethodName = "/api.Registry/GetLatestChannelEntriesThatProvide"
 	Registry_GetDefaultBundleThatProvides_FullMethodName       = "/api.Registry/GetDefaultBundleThatProvides"
 	Registry_ListBundles_FullMethodName                        = "/api.Registry/ListBundles"
+	Registry_GetCatalogInfo_FullMethodName                     = "/api.Registry/GetCatalogInfo"
+	Registry_GetUpgradeGraph_FullMethodName                    = "/api.Registry/GetUpgradeGraph"
+	Registry_GetServerInfo_FullMethodName                      = "/api.Registry/GetServerInfo"
+	Registry_ListPackageSummaries_FullMethodName               = "/api.Registry/ListPackageSummaries"
+	Registry_GetCatalogStats_FullMethodName                    = "/api.Registry/GetCatalogStats"
 )
 
 // RegistryClient is the client API for Registry service.
@@ -46,6 +51,11 @@ type RegistryClient interface {
 	GetLatestChannelEntriesThatProvide(ctx context.Context, in *GetLatestProvidersRequest, opts ...grpc.CallOption) (Registry_GetLatestChannelEntriesThatProvideClient, error)
 	GetDefaultBundleThatProvides(ctx context.Context, in *GetDefaultProviderRequest, opts ...grpc.CallOption) (*Bundle, error)
 	ListBundles(ctx context.Context, in *ListBundlesRequest, opts ...grpc.CallOption) (Registry_ListBundlesClient, error)
+	GetCatalogInfo(ctx context.Context, in *GetCatalogInfoRequest, opts ...grpc.CallOption) (*CatalogInfo, error)
+	GetUpgradeGraph(ctx context.Context, in *GetUpgradeGraphRequest, opts ...grpc.CallOption) (*UpgradeGraph, error)
+	GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*ServerInfo, error)
+	ListPackageSummaries(ctx context.Context, in *ListPackageSummariesRequest, opts ...grpc.CallOption) (Registry_ListPackageSummariesClient, error)
+	GetCatalogStats(ctx context.Context, in *GetCatalogStatsRequest, opts ...grpc.CallOption) (*CatalogStats, error)
 }
 
 type registryClient struct {
@@ -262,6 +272,74 @@ func (x *registryListBundlesClient) Recv() (*Bundle, error) {
 	return m, nil
 }
 
+func (c *registryClient) GetCatalogInfo(ctx context.Context, in *GetCatalogInfoRequest, opts ...grpc.CallOption) (*CatalogInfo, error) {
+	out := new(CatalogInfo)
+	err := c.cc.Invoke(ctx, Registry_GetCatalogInfo_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registryClient) GetUpgradeGraph(ctx context.Context, in *GetUpgradeGraphRequest, opts ...grpc.CallOption) (*UpgradeGraph, error) {
+	out := new(UpgradeGraph)
+	err := c.cc.Invoke(ctx, Registry_GetUpgradeGraph_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registryClient) GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*ServerInfo, error) {
+	out := new(ServerInfo)
+	err := c.cc.Invoke(ctx, Registry_GetServerInfo_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registryClient) ListPackageSummaries(ctx context.Context, in *ListPackageSummariesRequest, opts ...grpc.CallOption) (Registry_ListPackageSummariesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Registry_ServiceDesc.Streams[5], Registry_ListPackageSummaries_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &registryListPackageSummariesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Registry_ListPackageSummariesClient interface {
+	Recv() (*PackageSummary, error)
+	grpc.ClientStream
+}
+
+type registryListPackageSummariesClient struct {
+	grpc.ClientStream
+}
+
+func (x *registryListPackageSummariesClient) Recv() (*PackageSummary, error) {
+	m := new(PackageSummary)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *registryClient) GetCatalogStats(ctx context.Context, in *GetCatalogStatsRequest, opts ...grpc.CallOption) (*CatalogStats, error) {
+	out := new(CatalogStats)
+	err := c.cc.Invoke(ctx, Registry_GetCatalogStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RegistryServer is the server API for Registry service.
 // All implementations must embed UnimplementedRegistryServer
 // for forward compatibility
@@ -277,6 +355,11 @@ type RegistryServer interface {
 	GetLatestChannelEntriesThatProvide(*GetLatestProvidersRequest, Registry_GetLatestChannelEntriesThatProvideServer) error
 	GetDefaultBundleThatProvides(context.Context, *GetDefaultProviderRequest) (*Bundle, error)
 	ListBundles(*ListBundlesRequest, Registry_ListBundlesServer) error
+	GetCatalogInfo(context.Context, *GetCatalogInfoRequest) (*CatalogInfo, error)
+	GetUpgradeGraph(context.Context, *GetUpgradeGraphRequest) (*UpgradeGraph, error)
+	GetServerInfo(context.Context, *GetServerInfoRequest) (*ServerInfo, error)
+	ListPackageSummaries(*ListPackageSummariesRequest, Registry_ListPackageSummariesServer) error
+	GetCatalogStats(context.Context, *GetCatalogStatsRequest) (*CatalogStats, error)
 	mustEmbedUnimplementedRegistryServer()
 }
 
@@ -314,6 +397,21 @@ func (UnimplementedRegistryServer) GetDefaultBundleThatProvides(context.Context,
 func (UnimplementedRegistryServer) ListBundles(*ListBundlesRequest, Registry_ListBundlesServer) error {
 	return status.Errorf(codes.Unimplemented, "method ListBundles not implemented")
 }
+func (UnimplementedRegistryServer) GetCatalogInfo(context.Context, *GetCatalogInfoRequest) (*CatalogInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCatalogInfo not implemented")
+}
+func (UnimplementedRegistryServer) GetUpgradeGraph(context.Context, *GetUpgradeGraphRequest) (*UpgradeGraph, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUpgradeGraph not implemented")
+}
+func (UnimplementedRegistryServer) GetServerInfo(context.Context, *GetServerInfoRequest) (*ServerInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServerInfo not implemented")
+}
+func (UnimplementedRegistryServer) ListPackageSummaries(*ListPackageSummariesRequest, Registry_ListPackageSummariesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListPackageSummaries not implemented")
+}
+func (UnimplementedRegistryServer) GetCatalogStats(context.Context, *GetCatalogStatsRequest) (*CatalogStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCatalogStats not implemented")
+}
 func (UnimplementedRegistryServer) mustEmbedUnimplementedRegistryServer() {}
 
 // UnsafeRegistryServer may be embedded to opt out of forward compatibility for this service.
@@ -522,6 +620,99 @@ func (x *registryListBundlesServer) Send(m *Bundle) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Registry_GetCatalogInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCatalogInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).GetCatalogInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Registry_GetCatalogInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).GetCatalogInfo(ctx, req.(*GetCatalogInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Registry_GetUpgradeGraph_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUpgradeGraphRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).GetUpgradeGraph(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Registry_GetUpgradeGraph_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).GetUpgradeGraph(ctx, req.(*GetUpgradeGraphRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Registry_GetServerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServerInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).GetServerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Registry_GetServerInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).GetServerInfo(ctx, req.(*GetServerInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Registry_ListPackageSummaries_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListPackageSummariesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RegistryServer).ListPackageSummaries(m, &registryListPackageSummariesServer{stream})
+}
+
+type Registry_ListPackageSummariesServer interface {
+	Send(*PackageSummary) error
+	grpc.ServerStream
+}
+
+type registryListPackageSummariesServer struct {
+	grpc.ServerStream
+}
+
+func (x *registryListPackageSummariesServer) Send(m *PackageSummary) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Registry_GetCatalogStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCatalogStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).GetCatalogStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Registry_GetCatalogStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).GetCatalogStats(ctx, req.(*GetCatalogStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Registry_ServiceDesc is the grpc.ServiceDesc for Registry service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -549,6 +740,22 @@ var Registry_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetDefaultBundleThatProvides",
 			Handler:    _Registry_GetDefaultBundleThatProvides_Handler,
 		},
+		{
+			MethodName: "GetCatalogInfo",
+			Handler:    _Registry_GetCatalogInfo_Handler,
+		},
+		{
+			MethodName: "GetUpgradeGraph",
+			Handler:    _Registry_GetUpgradeGraph_Handler,
+		},
+		{
+			MethodName: "GetServerInfo",
+			Handler:    _Registry_GetServerInfo_Handler,
+		},
+		{
+			MethodName: "GetCatalogStats",
+			Handler:    _Registry_GetCatalogStats_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -576,6 +783,11 @@ var Registry_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Registry_ListBundles_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ListPackageSummaries",
+			Handler:       _Registry_ListPackageSummaries_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "registry.proto",
 }
@@ -0,0 +1,17 @@
+package api
+
+// Version is the version of the Registry service of registry.proto, reported
+// by GetServerInfo. Its minor version is incremented when methods or message
+// fields are added, and its major version when they are changed or removed.
+const Version = "1.5.0"
+
+// RegistryMethods returns the names of the methods of the Registry service, in
+// the order of their declaration.
+func RegistryMethods() []string {
+	methods := File_registry_proto.Services().ByName("Registry").Methods()
+	names := make([]string, 0, methods.Len())
+	for i := 0; i < methods.Len(); i++ {
+		names = append(names, string(methods.Get(i).Name()))
+	}
+	return names
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+// apiIndex maps each GVK provided by a bundle in the catalog to the bundles
+// that provide it, so that provider queries do not have to visit every bundle.
+// Bundles are keyed per channel, so a bundle in several channels appears once
+// for each of them.
+type apiIndex struct {
+	Provided map[string][]bundleKey `json:"provided"`
+}
+
+func gvkKey(group, version, kind string) string {
+	return group + "/" + version + "/" + kind
+}
+
+func newAPIIndex(pkgs packageIndex) *apiIndex {
+	idx := &apiIndex{Provided: map[string][]bundleKey{}}
+	for _, pkg := range pkgs {
+		for _, ch := range pkg.Channels {
+			for _, b := range ch.Bundles {
+				for _, p := range b.Properties {
+					if p.Type != property.TypeGVK {
+						continue
+					}
+					var gvk property.GVK
+					if err := json.Unmarshal(p.Value, &gvk); err != nil {
+						// The package index only contains bundles that
+						// were already converted to the model, whose
+						// properties have been parsed successfully.
+						continue
+					}
+					k := gvkKey(gvk.Group, gvk.Version, gvk.Kind)
+					idx.Provided[k] = append(idx.Provided[k], bundleKey{b.Package, b.Channel, b.Name})
+				}
+			}
+		}
+	}
+	for _, keys := range idx.Provided {
+		sort.Slice(keys, func(i, j int) bool { return bundleKeyComparator(keys[i], keys[j]) })
+	}
+	return idx
+}
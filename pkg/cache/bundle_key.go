@@ -38,6 +38,7 @@ func (b bundleKeys) Len() int {
 
 func (b bundleKeys) Walk(f func(k bundleKey) error) error {
 	it := b.t.Iter()
+	defer it.Release()
 	for it.Next() {
 		if err := f(it.Item()); err != nil {
 			return err
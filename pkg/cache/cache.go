@@ -2,25 +2,37 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/model"
+	"github.com/operator-framework/operator-registry/alpha/property"
 	"github.com/operator-framework/operator-registry/pkg/api"
 	"github.com/operator-framework/operator-registry/pkg/lib/log"
+	"github.com/operator-framework/operator-registry/pkg/lib/tracing"
 	"github.com/operator-framework/operator-registry/pkg/registry"
 )
 
+var tracer = otel.Tracer("github.com/operator-framework/operator-registry/pkg/cache")
+
 type Cache interface {
 	registry.GRPCQuery
 
@@ -28,6 +40,23 @@ type Cache interface {
 	Build(ctx context.Context, fbc fs.FS) error
 	Load(ctc context.Context) error
 	Close() error
+
+	// Digest returns the digest of the catalog content that the cache was
+	// built from. It changes only when the catalog content (or the cache
+	// format) changes, so it can be used to detect whether rebuilt catalog
+	// images serve different content.
+	Digest(ctx context.Context) (string, error)
+
+	// CatalogMetadata returns the olm.catalog blob of the catalog that the
+	// cache was built from, or nil if the catalog does not have one.
+	CatalogMetadata(ctx context.Context) (*declcfg.Catalog, error)
+
+	// GetBundleProperties returns the properties of type propertyType for
+	// which match returns true, across all bundles in all channels. A nil
+	// match returns every property of that type. Like the properties served
+	// by the registry API, olm.bundle.object and olm.csv.metadata properties
+	// are not included.
+	GetBundleProperties(ctx context.Context, propertyType string, match PropertyMatchFunc) ([]BundleProperty, error)
 }
 
 type backend interface {
@@ -41,9 +70,21 @@ type backend interface {
 	GetPackageIndex(context.Context) (packageIndex, error)
 	PutPackageIndex(context.Context, packageIndex) error
 
+	// SendBundles, GetBundle and PutBundle handle bundles without their
+	// manifests (CsvJson and Object), which are stored separately by
+	// PutBundleManifests so that they are only read when they are needed.
+	// GetBundleManifests returns nil if the bundle has no stored manifests.
 	SendBundles(context.Context, registry.BundleSender) error
 	GetBundle(context.Context, bundleKey) (*api.Bundle, error)
 	PutBundle(context.Context, bundleKey, *api.Bundle) error
+	GetBundleManifests(context.Context, bundleKey) (*api.Bundle, error)
+	PutBundleManifests(context.Context, bundleKey, *api.Bundle) error
+
+	GetCatalog(context.Context) (*declcfg.Catalog, error)
+	PutCatalog(context.Context, *declcfg.Catalog) error
+
+	GetAPIIndex(context.Context) (*apiIndex, error)
+	PutAPIIndex(context.Context, *apiIndex) error
 
 	GetDigest(context.Context) (string, error)
 	ComputeDigest(context.Context, fs.FS) (string, error)
@@ -51,8 +92,14 @@ type backend interface {
 }
 
 type CacheOptions struct {
-	Log    *logrus.Entry
-	Format string
+	Log         *logrus.Entry
+	Format      string
+	OpmVersion  string
+	Concurrency int
+	FragmentDir string
+	Progress    func(BuildProgress)
+
+	ReplacementPolicy *registry.ReplacementPolicy
 }
 
 func WithLog(log *logrus.Entry) CacheOption {
@@ -67,6 +114,58 @@ func WithFormat(format string) CacheOption {
 	}
 }
 
+// WithOpmVersion sets the version of the opm binary that builds and reads the
+// cache. Caches built by a different opm version fail their integrity check.
+func WithOpmVersion(version string) CacheOption {
+	return func(o *CacheOptions) {
+		o.OpmVersion = version
+	}
+}
+
+// WithConcurrency sets the number of catalog files parsed, and of packages
+// loaded, concurrently when the cache is built. It defaults to the number of
+// CPUs.
+func WithConcurrency(concurrency int) CacheOption {
+	return func(o *CacheOptions) {
+		o.Concurrency = concurrency
+	}
+}
+
+// WithFragmentDir sets a directory, which may be shared by many caches, in
+// which the content built for each package is kept, keyed by the digest of the
+// package's blobs. Builds of catalogs that share packages with catalogs built
+// before reuse their content instead of loading the packages again.
+func WithFragmentDir(dir string) CacheOption {
+	return func(o *CacheOptions) {
+		o.FragmentDir = dir
+	}
+}
+
+// BuildProgress reports the progress of a cache build.
+type BuildProgress struct {
+	// Packages is the number of packages stored so far.
+	Packages int
+	// TotalPackages is the number of packages of the catalog.
+	TotalPackages int
+}
+
+// WithBuildProgress sets a function that is called each time the content of a
+// package has been stored while the cache is built. It is never called
+// concurrently.
+func WithBuildProgress(progress func(BuildProgress)) CacheOption {
+	return func(o *CacheOptions) {
+		o.Progress = progress
+	}
+}
+
+// WithReplacementPolicy sets the upgrade edges that GetBundleThatReplaces
+// follows. It defaults to registry.DefaultReplacementPolicy.
+func WithReplacementPolicy(p registry.ReplacementPolicy) CacheOption {
+	return func(o *CacheOptions) {
+		o.ReplacementPolicy = &p
+	}
+}
+
 type CacheOption func(*CacheOptions)
 
 // New creates a new Cache. It chooses a cache implementation based
@@ -80,6 +179,9 @@ func New(cacheDir string, cacheOpts ...CacheOption) (Cache, error) {
 	for _, opt := range cacheOpts {
 		opt(opts)
 	}
+	if err := recoverCacheDir(cacheDir, opts.Log); err != nil {
+		return nil, fmt.Errorf("recover cache directory: %v", err)
+	}
 	cacheBackend, err := getBackend(cacheDir, opts.Format, opts.Log)
 	if err != nil {
 		return nil, err
@@ -88,7 +190,32 @@ func New(cacheDir string, cacheOpts ...CacheOption) (Cache, error) {
 	if err := cacheBackend.Open(); err != nil {
 		return nil, fmt.Errorf("open cache: %v", err)
 	}
-	return &cache{backend: cacheBackend, log: opts.Log}, nil
+	replacementPolicy := registry.DefaultReplacementPolicy()
+	if opts.ReplacementPolicy != nil {
+		replacementPolicy = *opts.ReplacementPolicy
+	}
+	return &cache{backend: cacheBackend, log: opts.Log, cacheDir: cacheDir, opmVersion: opts.OpmVersion, concurrency: opts.Concurrency, fragmentDir: opts.FragmentDir, progress: opts.Progress, replacementPolicy: replacementPolicy}, nil
+}
+
+// newBackends returns a backend of each cache format that stores its content
+// in cacheDir, in order of preference.
+func newBackends(cacheDir string) []backend {
+	return []backend{
+		newPogrebV1Backend(cacheDir),
+		newJSONBackend(cacheDir),
+		newMMapV1Backend(cacheDir),
+	}
+}
+
+// newBackend returns a backend of the named cache format that stores its
+// content in cacheDir.
+func newBackend(cacheDir string, name string) (backend, error) {
+	for _, b := range newBackends(cacheDir) {
+		if b.Name() == name {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown cache format %q", name)
 }
 
 func getBackend(cacheDir string, backendName string, log *logrus.Entry) (backend, error) {
@@ -97,10 +224,7 @@ func getBackend(cacheDir string, backendName string, log *logrus.Entry) (backend
 		return nil, fmt.Errorf("detect cache format: read cache directory: %v", err)
 	}
 
-	backends := []backend{
-		newPogrebV1Backend(cacheDir),
-		newJSONBackend(cacheDir),
-	}
+	backends := newBackends(cacheDir)
 
 	if len(entries) == 0 {
 		if backendName == "" {
@@ -146,19 +270,30 @@ func LoadOrRebuild(ctx context.Context, c Cache, fbc fs.FS) error {
 var _ Cache = &cache{}
 
 type cache struct {
-	backend backend
-	log     *logrus.Entry
+	backend     backend
+	log         *logrus.Entry
+	cacheDir    string
+	opmVersion  string
+	concurrency int
+	fragmentDir string
+	progress    func(BuildProgress)
+
+	replacementPolicy registry.ReplacementPolicy
 	packageIndex
+	propertyIndex propertyIndex
+	apiIndex      *apiIndex
 }
 
-type bundleStreamTransformer func(*api.Bundle)
+type bundleStreamTransformer func(*api.Bundle) error
 type transformingBundleSender struct {
 	stream      registry.BundleSender
 	transformer bundleStreamTransformer
 }
 
 func (t *transformingBundleSender) Send(b *api.Bundle) error {
-	t.transformer(b)
+	if err := t.transformer(b); err != nil {
+		return err
+	}
 	return t.stream.Send(b)
 }
 
@@ -169,8 +304,10 @@ func (s *sliceBundleSender) Send(b *api.Bundle) error {
 	return nil
 }
 
-func (c *cache) SendBundles(ctx context.Context, stream registry.BundleSender) error {
-	transform := func(bundle *api.Bundle) {
+func (c *cache) SendBundles(ctx context.Context, stream registry.BundleSender) (err error) {
+	ctx, span := tracer.Start(ctx, "cache.SendBundles")
+	defer func() { tracing.End(span, err) }()
+	transform := func(bundle *api.Bundle) error {
 		if bundle.BundlePath != "" {
 			// The SQLite-based server
 			// configures its querier to
@@ -178,11 +315,53 @@ func (c *cache) SendBundles(ctx context.Context, stream registry.BundleSender) e
 			// key path is set.
 			bundle.CsvJson = ""
 			bundle.Object = nil
+			return nil
 		}
+		return c.loadBundleManifests(ctx, bundleKey{bundle.PackageName, bundle.ChannelName, bundle.CsvName}, bundle)
 	}
 	return c.backend.SendBundles(ctx, &transformingBundleSender{stream, transform})
 }
 
+// SendBundlesWithEdges sends the bundles of the cache like SendBundles. The
+// cache stores the replaces and skips of every channel entry, so its bundles
+// are always sent with their edges.
+func (c *cache) SendBundlesWithEdges(ctx context.Context, stream registry.BundleSender) error {
+	return c.SendBundles(ctx, stream)
+}
+
+// getBundle returns the bundle with the given key, including its manifests.
+func (c *cache) getBundle(ctx context.Context, key bundleKey) (_ *api.Bundle, err error) {
+	ctx, span := tracer.Start(ctx, "cache.getBundle", trace.WithAttributes(
+		attribute.String("package", key.PackageName),
+		attribute.String("channel", key.ChannelName),
+		attribute.String("bundle", key.Name),
+	))
+	defer func() { tracing.End(span, err) }()
+	apiBundle, err := c.backend.GetBundle(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.loadBundleManifests(ctx, key, apiBundle); err != nil {
+		return nil, err
+	}
+	return apiBundle, nil
+}
+
+// loadBundleManifests sets the CSV and objects of b from the manifests stored
+// for the bundle with the given key. Caches built before manifests were
+// stored separately keep them in the bundle itself, which is left unchanged.
+func (c *cache) loadBundleManifests(ctx context.Context, key bundleKey, b *api.Bundle) error {
+	manifests, err := c.backend.GetBundleManifests(ctx, key)
+	if err != nil {
+		return fmt.Errorf("get manifests for bundle %q: %v", key.Name, err)
+	}
+	if manifests != nil {
+		b.CsvJson = manifests.CsvJson
+		b.Object = manifests.Object
+	}
+	return nil
+}
+
 func (c *cache) ListBundles(ctx context.Context) ([]*api.Bundle, error) {
 	var bundleSender sliceBundleSender
 	if err := c.SendBundles(ctx, &bundleSender); err != nil {
@@ -192,7 +371,7 @@ func (c *cache) ListBundles(ctx context.Context) ([]*api.Bundle, error) {
 }
 
 func (c *cache) getTrimmedBundle(ctx context.Context, key bundleKey) (*api.Bundle, error) {
-	apiBundle, err := c.backend.GetBundle(ctx, key)
+	apiBundle, err := c.getBundle(ctx, key)
 	if err != nil {
 		return nil, err
 	}
@@ -222,26 +401,82 @@ func (c *cache) GetBundleForChannel(ctx context.Context, pkgName string, channel
 }
 
 func (c *cache) GetBundleThatReplaces(ctx context.Context, name, pkgName, channelName string) (*api.Bundle, error) {
-	return c.packageIndex.GetBundleThatReplaces(ctx, c.getTrimmedBundle, name, pkgName, channelName)
+	return c.packageIndex.GetBundleThatReplaces(ctx, c.getTrimmedBundle, c.replacementPolicy, name, pkgName, channelName)
 }
 
 func (c *cache) GetChannelEntriesThatProvide(ctx context.Context, group, version, kind string) ([]*registry.ChannelEntry, error) {
-	return c.packageIndex.GetChannelEntriesThatProvide(ctx, c.backend.GetBundle, group, version, kind)
+	providers, err := c.bundlesThatProvide(ctx, group, version, kind)
+	if err != nil {
+		return nil, err
+	}
+	return c.packageIndex.GetChannelEntriesThatProvide(providers, group, version, kind)
 }
 
 func (c *cache) GetLatestChannelEntriesThatProvide(ctx context.Context, group, version, kind string) ([]*registry.ChannelEntry, error) {
-	return c.packageIndex.GetLatestChannelEntriesThatProvide(ctx, c.backend.GetBundle, group, version, kind)
+	providers, err := c.bundlesThatProvide(ctx, group, version, kind)
+	if err != nil {
+		return nil, err
+	}
+	return c.packageIndex.GetLatestChannelEntriesThatProvide(providers, group, version, kind)
+}
+
+// bundlesThatProvide returns the keys of the bundles that provide the given
+// GVK. It uses the API index when the cache has one, and otherwise falls back
+// to querying bundle properties.
+func (c *cache) bundlesThatProvide(ctx context.Context, group, version, kind string) ([]bundleKey, error) {
+	if c.apiIndex != nil {
+		return c.apiIndex.Provided[gvkKey(group, version, kind)], nil
+	}
+	props, err := c.GetBundleProperties(ctx, property.TypeGVK, func(p property.Property) bool {
+		var gvk property.GVK
+		if err := json.Unmarshal(p.Value, &gvk); err != nil {
+			return false
+		}
+		return gvk.Group == group && gvk.Version == version && gvk.Kind == kind
+	})
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]bundleKey, 0, len(props))
+	for _, p := range props {
+		keys = append(keys, bundleKey{p.PackageName, p.ChannelName, p.BundleName})
+	}
+	return keys, nil
 }
 
 func (c *cache) GetBundleThatProvides(ctx context.Context, group, version, kind string) (*api.Bundle, error) {
 	return c.packageIndex.GetBundleThatProvides(ctx, c, group, version, kind)
 }
 
-func (c *cache) CheckIntegrity(ctx context.Context, fbc fs.FS) error {
+func (c *cache) GetUpgradeGraph(ctx context.Context, pkgName string) (*api.UpgradeGraph, error) {
+	pkg, ok := c.packageIndex[pkgName]
+	if !ok {
+		return nil, fmt.Errorf("package %q not found", pkgName)
+	}
+	var bundles []*api.Bundle
+	for _, ch := range pkg.Channels {
+		for _, b := range ch.Bundles {
+			// The manifests of the bundles are not needed for the graph.
+			apiBundle, err := c.backend.GetBundle(ctx, bundleKey{pkg.Name, ch.Name, b.Name})
+			if err != nil {
+				return nil, err
+			}
+			bundles = append(bundles, apiBundle)
+		}
+	}
+	return registry.NewUpgradeGraph(pkgName, bundles), nil
+}
+
+func (c *cache) CheckIntegrity(ctx context.Context, fbc fs.FS) (err error) {
+	ctx, span := tracer.Start(ctx, "cache.CheckIntegrity")
+	defer func() { tracing.End(span, err) }()
 	existingDigest, err := c.backend.GetDigest(ctx)
 	if err != nil {
 		return fmt.Errorf("read existing cache digest: %v", err)
 	}
+	if err := c.checkMetadata(); err != nil {
+		return err
+	}
 	computedDigest, err := c.backend.ComputeDigest(ctx, fbc)
 	if err != nil {
 		return fmt.Errorf("compute digest: %v", err)
@@ -253,14 +488,51 @@ func (c *cache) CheckIntegrity(ctx context.Context, fbc fs.FS) error {
 	return nil
 }
 
-func (c *cache) Build(ctx context.Context, fbcFsys fs.FS) error {
+// Build builds the cache from fbcFsys. The cache is built in a staging
+// directory and only replaces the content of the cache directory once it is
+// complete, so if the build fails or ctx is canceled, the previous cache is
+// left as it was.
+func (c *cache) Build(ctx context.Context, fbcFsys fs.FS) (err error) {
+	ctx, span := tracer.Start(ctx, "cache.Build")
+	defer func() { tracing.End(span, err) }()
 	// ensure that generated cache is available to all future users
 	oldUmask := umask(000)
 	defer umask(oldUmask)
 
 	c.log.Info("building cache")
 
-	if err := c.backend.Init(); err != nil {
+	if err := os.MkdirAll(c.cacheDir, cacheModeDir); err != nil {
+		return fmt.Errorf("create cache directory: %v", err)
+	}
+	stagingDir, err := os.MkdirTemp(c.cacheDir, stagingDirPrefix)
+	if err != nil {
+		return fmt.Errorf("create staging directory: %v", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	staging, err := newBackend(stagingDir, c.backend.Name())
+	if err != nil {
+		return err
+	}
+	if err := c.build(ctx, staging, stagingDir, fbcFsys); err != nil {
+		_ = staging.Close()
+		return err
+	}
+	if err := staging.Close(); err != nil {
+		return fmt.Errorf("close staging cache: %v", err)
+	}
+	if err := c.commit(stagingDir); err != nil {
+		return fmt.Errorf("replace cache: %v", err)
+	}
+	if err := resetModTimes(c.cacheDir); err != nil {
+		return fmt.Errorf("reset modification times: %v", err)
+	}
+	return nil
+}
+
+// build builds the cache from fbcFsys into b, which stores its content in dir.
+func (c *cache) build(ctx context.Context, b backend, dir string, fbcFsys fs.FS) error {
+	if err := b.Init(); err != nil {
 		return fmt.Errorf("init cache: %v", err)
 	}
 
@@ -274,11 +546,14 @@ func (c *cache) Build(ctx context.Context, fbcFsys fs.FS) error {
 	}()
 
 	var (
-		concurrency      = runtime.NumCPU()
-		byPackageReaders = map[string][]io.Reader{}
+		concurrency      = c.concurrency
+		byPackageReaders = map[string][]*io.SectionReader{}
 		walkMu           sync.Mutex
 		offset           int64
 	)
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
 	if err := declcfg.WalkMetasFS(ctx, fbcFsys, func(path string, meta *declcfg.Meta, err error) error {
 		if err != nil {
 			return err
@@ -304,23 +579,38 @@ func (c *cache) Build(ctx context.Context, fbcFsys fs.FS) error {
 		return err
 	}
 
+	// Packages are loaded concurrently, but stored one at a time in package
+	// name order, so that backends that append to a file write identical
+	// caches for identical catalogs. Each package waits for the previous one
+	// to be stored before storing its own content.
+	pkgNames := slices.Sorted(maps.Keys(byPackageReaders))
+	progress := BuildProgress{TotalPackages: len(pkgNames)}
+	if _, ok := byPackageReaders[""]; ok {
+		progress.TotalPackages--
+	}
+	turns := make([]chan struct{}, len(pkgNames)+1)
+	for i := range turns {
+		turns[i] = make(chan struct{})
+	}
+	close(turns[0])
+
 	eg, egCtx := errgroup.WithContext(ctx)
-	pkgNameChan := make(chan string, concurrency)
+	pkgChan := make(chan int, concurrency)
 	eg.Go(func() error {
-		defer close(pkgNameChan)
-		for pkgName := range byPackageReaders {
+		defer close(pkgChan)
+		for i := range pkgNames {
 			select {
 			case <-egCtx.Done():
 				return egCtx.Err()
-			case pkgNameChan <- pkgName:
+			case pkgChan <- i:
 			}
 		}
 		return nil
 	})
 
 	var (
-		pkgs   = packageIndex{}
-		pkgsMu sync.Mutex
+		pkgs            = packageIndex{}
+		reusedFragments atomic.Int64
 	)
 	for i := 0; i < concurrency; i++ {
 		eg.Go(func() error {
@@ -328,18 +618,38 @@ func (c *cache) Build(ctx context.Context, fbcFsys fs.FS) error {
 				select {
 				case <-egCtx.Done():
 					return egCtx.Err()
-				case pkgName, ok := <-pkgNameChan:
+				case i, ok := <-pkgChan:
 					if !ok {
 						return nil
 					}
-					pkgIndex, err := c.processPackage(egCtx, io.MultiReader(byPackageReaders[pkgName]...))
+					pkgName := pkgNames[i]
+					fragment, reused, err := c.getPackageFragment(byPackageReaders[pkgName])
 					if err != nil {
 						return fmt.Errorf("process package %q: %v", pkgName, err)
 					}
+					if reused {
+						reusedFragments.Add(1)
+					}
+					select {
+					case <-egCtx.Done():
+						return egCtx.Err()
+					case <-turns[i]:
+					}
+					if err := c.storeFragment(egCtx, b, fragment); err != nil {
+						return fmt.Errorf("process package %q: %v", pkgName, err)
+					}
 
-					pkgsMu.Lock()
-					pkgs[pkgName] = pkgIndex[pkgName]
-					pkgsMu.Unlock()
+					// Blobs that do not belong to a package, like
+					// olm.catalog, are grouped under the empty package
+					// name, which has no entry in the index.
+					if p, ok := fragment.Packages[pkgName]; ok {
+						pkgs[pkgName] = p
+					}
+					if pkgName != "" && c.progress != nil {
+						progress.Packages++
+						c.progress(progress)
+					}
+					close(turns[i+1])
 				}
 			}
 		})
@@ -347,56 +657,115 @@ func (c *cache) Build(ctx context.Context, fbcFsys fs.FS) error {
 	if err := eg.Wait(); err != nil {
 		return fmt.Errorf("build package index: %v", err)
 	}
+	if c.fragmentDir != "" {
+		c.log.WithField("reused", reusedFragments.Load()).WithField("packages", len(pkgNames)).Info("reused cache fragments")
+	}
 
-	if err := c.backend.PutPackageIndex(ctx, pkgs); err != nil {
+	if err := b.PutPackageIndex(ctx, pkgs); err != nil {
 		return fmt.Errorf("store package index: %v", err)
 	}
+	if err := b.PutAPIIndex(ctx, newAPIIndex(pkgs)); err != nil {
+		return fmt.Errorf("store API index: %v", err)
+	}
 
-	digest, err := c.backend.ComputeDigest(ctx, fbcFsys)
+	digest, err := b.ComputeDigest(ctx, fbcFsys)
 	if err != nil {
 		return fmt.Errorf("compute digest: %v", err)
 	}
-	if err := c.backend.PutDigest(ctx, digest); err != nil {
+	// A canceled build must not replace the previous cache, even if all of
+	// its packages were stored.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := b.PutDigest(ctx, digest); err != nil {
 		return fmt.Errorf("store digest: %v", err)
 	}
+	if err := writeMetadata(dir, c.metadata()); err != nil {
+		return fmt.Errorf("store metadata: %v", err)
+	}
 	return nil
 }
 
-func (c *cache) processPackage(ctx context.Context, reader io.Reader) (packageIndex, error) {
+func loadPackage(reader io.Reader) (*declcfg.DeclarativeConfig, model.Model, error) {
 	pkgFbc, err := declcfg.LoadReader(reader)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	pkgModel, err := declcfg.ConvertToModel(*pkgFbc)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	return pkgFbc, pkgModel, nil
+}
+
+// newPackageFragment converts the content of a package to what the cache
+// stores for it. Channels and bundles are listed in name order, so that the
+// cache content does not depend on map iteration order.
+func newPackageFragment(pkgFbc *declcfg.DeclarativeConfig, pkgModel model.Model) (*packageFragment, error) {
 	pkgIndex, err := packagesFromModel(pkgModel)
 	if err != nil {
 		return nil, err
 	}
-	for _, p := range pkgModel {
-		for _, ch := range p.Channels {
-			for _, b := range ch.Bundles {
+	f := &packageFragment{Catalogs: pkgFbc.Catalogs, Packages: pkgIndex}
+	for _, pkgName := range slices.Sorted(maps.Keys(pkgModel)) {
+		p := pkgModel[pkgName]
+		for _, chName := range slices.Sorted(maps.Keys(p.Channels)) {
+			ch := p.Channels[chName]
+			for _, bName := range slices.Sorted(maps.Keys(ch.Bundles)) {
+				b := ch.Bundles[bName]
 				apiBundle, err := api.ConvertModelBundleToAPIBundle(*b)
 				if err != nil {
 					return nil, err
 				}
-				if err := c.backend.PutBundle(ctx, bundleKey{p.Name, ch.Name, b.Name}, apiBundle); err != nil {
-					return nil, fmt.Errorf("store bundle %q: %v", b.Name, err)
+				fb := fragmentBundle{Key: bundleKey{p.Name, ch.Name, b.Name}, Bundle: apiBundle}
+				if apiBundle.CsvJson != "" || len(apiBundle.Object) > 0 {
+					fb.Manifests = &api.Bundle{CsvJson: apiBundle.CsvJson, Object: apiBundle.Object}
+					apiBundle.CsvJson, apiBundle.Object = "", nil
 				}
+				f.Bundles = append(f.Bundles, fb)
 			}
 		}
 	}
-	return pkgIndex, nil
+	return f, nil
 }
 
-func (c *cache) Load(ctx context.Context) error {
+// storeFragment stores the content of a package in b.
+func (c *cache) storeFragment(ctx context.Context, b backend, f *packageFragment) error {
+	for i := range f.Catalogs {
+		if err := b.PutCatalog(ctx, &f.Catalogs[i]); err != nil {
+			return fmt.Errorf("store catalog metadata: %v", err)
+		}
+	}
+	for _, fb := range f.Bundles {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if fb.Manifests != nil {
+			if err := b.PutBundleManifests(ctx, fb.Key, fb.Manifests); err != nil {
+				return fmt.Errorf("store manifests for bundle %q: %v", fb.Key.Name, err)
+			}
+		}
+		if err := b.PutBundle(ctx, fb.Key, fb.Bundle); err != nil {
+			return fmt.Errorf("store bundle %q: %v", fb.Key.Name, err)
+		}
+	}
+	return nil
+}
+
+func (c *cache) Load(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "cache.Load")
+	defer func() { tracing.End(span, err) }()
 	pi, err := c.backend.GetPackageIndex(ctx)
 	if err != nil {
 		return fmt.Errorf("get package index: %v", err)
 	}
+	ai, err := c.backend.GetAPIIndex(ctx)
+	if err != nil {
+		return fmt.Errorf("get API index: %v", err)
+	}
 	c.packageIndex = pi
+	c.propertyIndex = newPropertyIndex(pi)
+	c.apiIndex = ai
 	return nil
 }
 
@@ -404,6 +773,14 @@ func (c *cache) Close() error {
 	return c.backend.Close()
 }
 
+func (c *cache) Digest(ctx context.Context) (string, error) {
+	return c.backend.GetDigest(ctx)
+}
+
+func (c *cache) CatalogMetadata(ctx context.Context) (*declcfg.Catalog, error) {
+	return c.backend.GetCatalog(ctx)
+}
+
 func ensureEmptyDir(dir string, mode os.FileMode) error {
 	if err := os.MkdirAll(dir, mode); err != nil {
 		return err
@@ -431,16 +808,3 @@ func readDigestFile(digestFile string) (string, error) {
 func writeDigestFile(file string, digest string, mode os.FileMode) error {
 	return os.WriteFile(file, []byte(digest), mode)
 }
-
-func doesBundleProvide(ctx context.Context, getBundle getBundleFunc, pkgName, chName, bundleName, group, version, kind string) (bool, error) {
-	apiBundle, err := getBundle(ctx, bundleKey{pkgName, chName, bundleName})
-	if err != nil {
-		return false, fmt.Errorf("get bundle %q: %v", bundleName, err)
-	}
-	for _, gvk := range apiBundle.ProvidedApis {
-		if gvk.Group == group && gvk.Version == version && gvk.Kind == kind {
-			return true, nil
-		}
-	}
-	return false, nil
-}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/pkg/lib/log"
+)
+
+// BenchmarkCache_GetChannelEntriesThatProvide compares provider lookups served
+// from the API index with lookups that read the properties of each bundle, on
+// a catalog with 5000 bundles.
+func BenchmarkCache_GetChannelEntriesThatProvide(b *testing.B) {
+	const (
+		numPackages       = 100
+		bundlesPerPackage = 50
+	)
+	fbcDir := b.TempDir()
+	if err := declcfg.WriteFS(*generateProviderFBC(numPackages, bundlesPerPackage), fbcDir, declcfg.WriteJSON, ".json"); err != nil {
+		b.Fatal(err)
+	}
+
+	for _, format := range []string{FormatJSON, FormatPogrebV1, FormatMMapV1} {
+		c, err := New(b.TempDir(), WithFormat(format), WithLog(log.Null()))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := LoadOrRebuild(context.Background(), c, os.DirFS(fbcDir)); err != nil {
+			b.Fatal(err)
+		}
+		indexed := c.(*cache)
+		unindexed := &cache{backend: indexed.backend, log: indexed.log, packageIndex: indexed.packageIndex}
+
+		for name, q := range map[string]*cache{"indexed": indexed, "unindexed": unindexed} {
+			b.Run(fmt.Sprintf("%s/%s", format, name), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := q.GetChannelEntriesThatProvide(context.Background(), "example.com", "v1", fmt.Sprintf("Kind%d", i%numPackages)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+		if err := c.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// generateProviderFBC returns a catalog in which every bundle of package N
+// provides example.com/v1, Kind=KindN.
+func generateProviderFBC(numPackages, bundlesPerPackage int) *declcfg.DeclarativeConfig {
+	fbc := &declcfg.DeclarativeConfig{}
+	for p := 0; p < numPackages; p++ {
+		pkgName := fmt.Sprintf("package-%d", p)
+		fbc.Packages = append(fbc.Packages, declcfg.Package{
+			Schema:         declcfg.SchemaPackage,
+			Name:           pkgName,
+			DefaultChannel: "stable",
+		})
+		ch := declcfg.Channel{Schema: declcfg.SchemaChannel, Package: pkgName, Name: "stable"}
+		for v := 0; v < bundlesPerPackage; v++ {
+			version := fmt.Sprintf("0.%d.0", v)
+			bundleName := fmt.Sprintf("%s.v%s", pkgName, version)
+			entry := declcfg.ChannelEntry{Name: bundleName}
+			if v > 0 {
+				entry.Replaces = fmt.Sprintf("%s.v0.%d.0", pkgName, v-1)
+			}
+			ch.Entries = append(ch.Entries, entry)
+			fbc.Bundles = append(fbc.Bundles, declcfg.Bundle{
+				Schema:  declcfg.SchemaBundle,
+				Package: pkgName,
+				Name:    bundleName,
+				Image:   fmt.Sprintf("example.com/%s:v%s", pkgName, version),
+				Properties: []property.Property{
+					property.MustBuildPackage(pkgName, version),
+					property.MustBuildGVK("example.com", "v1", fmt.Sprintf("Kind%d", p)),
+				},
+			})
+		}
+		fbc.Channels = append(fbc.Channels, ch)
+	}
+	return fbc
+}
@@ -1,13 +1,25 @@
 package cache
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
 	"testing/fstest"
 
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/pkg/api"
 	"github.com/operator-framework/operator-registry/pkg/lib/log"
 	"github.com/operator-framework/operator-registry/pkg/registry"
 )
@@ -174,25 +186,63 @@ func TestCache_GetPackage(t *testing.T) {
 					{
 						Name:           "singlenamespace-alpha",
 						CurrentCSVName: "etcdoperator.v0.9.4",
+						HeadVersion:    "0.9.4",
+						BundleCount:    3,
 					},
 					{
 						Name:           "clusterwide-alpha",
 						CurrentCSVName: "etcdoperator.v0.9.4-clusterwide",
+						HeadVersion:    "0.9.4-clusterwide",
+						BundleCount:    3,
 					},
 					{
 						Name:           "alpha",
 						CurrentCSVName: "etcdoperator-community.v0.6.1",
+						HeadVersion:    "0.6.1",
+						BundleCount:    1,
 					},
 				},
 			}
 
 			require.ElementsMatch(t, expected.Channels, p.Channels)
 			expected.Channels, p.Channels = nil, nil
+
+			require.NotNil(t, p.Icon)
+			require.Equal(t, "image/svg+xml", p.Icon.MediaType)
+			require.NotEmpty(t, p.Icon.Base64data)
+			p.Icon = nil
+
 			require.Equal(t, expected, p)
 		})
 	}
 }
 
+func TestCache_GetPackageAndChannelProperties(t *testing.T) {
+	fbcFS := fstest.MapFS{
+		"catalog.json": &fstest.MapFile{Data: []byte(`{"schema": "olm.package", "name": "foo", "defaultChannel": "stable", "properties": [{"type": "example.com/owner", "value": {"team": "storage"}}]}
+{"schema": "olm.channel", "package": "foo", "name": "stable", "entries": [{"name": "foo.v0.1.0"}], "properties": [{"type": "example.com/support", "value": {"until": "2027-01-01"}}]}
+{"schema": "olm.bundle", "package": "foo", "name": "foo.v0.1.0", "image": "quay.io/example/foo:v0.1.0", "properties": [{"type": "olm.package", "value": {"packageName": "foo", "version": "0.1.0"}}]}`)},
+	}
+	for name, testQuerier := range genTestCaches(t, fbcFS) {
+		t.Run(name, func(t *testing.T) {
+			p, err := testQuerier.GetPackage(context.TODO(), "foo")
+			require.NoError(t, err)
+			require.Equal(t, []registry.Property{{Type: "example.com/owner", Value: json.RawMessage(`{"team":"storage"}`)}}, p.Properties)
+
+			apiPkg := registry.PackageManifestToAPIPackage(p)
+			require.Len(t, apiPkg.GetProperties(), 1)
+			require.Equal(t, "example.com/owner", apiPkg.GetProperties()[0].GetType())
+			require.JSONEq(t, `{"team":"storage"}`, apiPkg.GetProperties()[0].GetValue())
+
+			require.Len(t, p.Channels, 1)
+			require.Equal(t, []registry.Property{{Type: "example.com/support", Value: json.RawMessage(`{"until":"2027-01-01"}`)}}, p.Channels[0].Properties)
+			require.Len(t, apiPkg.GetChannels(), 1)
+			require.Len(t, apiPkg.GetChannels()[0].GetProperties(), 1)
+			require.Equal(t, "example.com/support", apiPkg.GetChannels()[0].GetProperties()[0].GetType())
+		})
+	}
+}
+
 func TestCache_ListBundles(t *testing.T) {
 	for name, testQuerier := range genTestCaches(t, validFS) {
 		t.Run(name, func(t *testing.T) {
@@ -219,11 +269,377 @@ func TestCache_ListPackages(t *testing.T) {
 	}
 }
 
+func TestCache_GetBundleProperties(t *testing.T) {
+	for name, testQuerier := range genTestCaches(t, validFS) {
+		t.Run(name, func(t *testing.T) {
+			props, err := testQuerier.GetBundleProperties(context.TODO(), property.TypeGVK, MatchGVK("etcd.database.coreos.com", "v1beta2", "EtcdRestore"))
+			require.NoError(t, err)
+			require.Len(t, props, 1)
+			require.Equal(t, "etcd", props[0].PackageName)
+			require.Equal(t, "singlenamespace-alpha", props[0].ChannelName)
+			require.Equal(t, "etcdoperator.v0.9.2", props[0].BundleName)
+			require.Equal(t, property.TypeGVK, props[0].Property.Type)
+
+			groupProps, err := testQuerier.GetBundleProperties(context.TODO(), property.TypeGVK, MatchGVK("etcd.database.coreos.com", "", ""))
+			require.NoError(t, err)
+			require.Greater(t, len(groupProps), len(props))
+
+			none, err := testQuerier.GetBundleProperties(context.TODO(), property.TypeGVK, MatchGVK("foo.example.com", "", ""))
+			require.NoError(t, err)
+			require.Empty(t, none)
+
+			objects, err := testQuerier.GetBundleProperties(context.TODO(), property.TypeBundleObject, nil)
+			require.NoError(t, err)
+			require.Empty(t, objects)
+
+			// Caches built before properties were indexed are answered by
+			// reading each bundle, with the same result.
+			c := testQuerier.(*cache)
+			c.propertyIndex = nil
+			scanned, err := testQuerier.GetBundleProperties(context.TODO(), property.TypeGVK, MatchGVK("etcd.database.coreos.com", "", ""))
+			require.NoError(t, err)
+			require.Equal(t, groupProps, scanned)
+		})
+	}
+}
+
+func TestCache_APIIndexFallback(t *testing.T) {
+	for name, testQuerier := range genTestCaches(t, validFS) {
+		t.Run(name, func(t *testing.T) {
+			c := testQuerier.(*cache)
+			require.NotNil(t, c.apiIndex)
+
+			indexed, err := c.GetChannelEntriesThatProvide(context.TODO(), "etcd.database.coreos.com", "v1beta2", "EtcdBackup")
+			require.NoError(t, err)
+			indexedLatest, err := c.GetLatestChannelEntriesThatProvide(context.TODO(), "etcd.database.coreos.com", "v1beta2", "EtcdBackup")
+			require.NoError(t, err)
+
+			// Caches built before the API index existed are answered from
+			// the bundle properties instead, with the same result.
+			c.apiIndex = nil
+			scanned, err := c.GetChannelEntriesThatProvide(context.TODO(), "etcd.database.coreos.com", "v1beta2", "EtcdBackup")
+			require.NoError(t, err)
+			require.ElementsMatch(t, indexed, scanned)
+			scannedLatest, err := c.GetLatestChannelEntriesThatProvide(context.TODO(), "etcd.database.coreos.com", "v1beta2", "EtcdBackup")
+			require.NoError(t, err)
+			require.ElementsMatch(t, indexedLatest, scannedLatest)
+
+			_, err = c.GetChannelEntriesThatProvide(context.TODO(), "", "v1beta2", "EtcdBackup")
+			require.ErrorContains(t, err, "no channel entries found")
+		})
+	}
+}
+
+type manifestCountingBackend struct {
+	backend
+	manifestReads int
+}
+
+func (b *manifestCountingBackend) GetBundleManifests(ctx context.Context, key bundleKey) (*api.Bundle, error) {
+	b.manifestReads++
+	return b.backend.GetBundleManifests(ctx, key)
+}
+
+func TestCache_LazyManifests(t *testing.T) {
+	csv := v1alpha1.ClusterServiceVersion{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: v1alpha1.ClusterServiceVersionKind},
+		ObjectMeta: metav1.ObjectMeta{Name: "foo.v0.2.0"},
+	}
+	csvJSON, err := json.Marshal(csv)
+	require.NoError(t, err)
+
+	fbc := declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{{Schema: declcfg.SchemaPackage, Name: "foo", DefaultChannel: "stable"}},
+		Channels: []declcfg.Channel{{Schema: declcfg.SchemaChannel, Package: "foo", Name: "stable", Entries: []declcfg.ChannelEntry{
+			{Name: "foo.v0.1.0"},
+			{Name: "foo.v0.2.0", Replaces: "foo.v0.1.0"},
+		}}},
+		Bundles: []declcfg.Bundle{
+			{
+				Schema:  declcfg.SchemaBundle,
+				Package: "foo",
+				Name:    "foo.v0.1.0",
+				Image:   "example.com/foo:v0.1.0",
+				Properties: []property.Property{
+					property.MustBuildPackage("foo", "0.1.0"),
+					property.MustBuildCSVMetadata(csv),
+				},
+			},
+			{
+				Schema:  declcfg.SchemaBundle,
+				Package: "foo",
+				Name:    "foo.v0.2.0",
+				Properties: []property.Property{
+					property.MustBuildPackage("foo", "0.2.0"),
+					property.MustBuildBundleObject(csvJSON),
+				},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, declcfg.WriteJSON(fbc, &buf))
+	fbcFS := fstest.MapFS{"catalog.json": &fstest.MapFile{Data: buf.Bytes()}}
+
+	for name, testQuerier := range genTestCaches(t, fbcFS) {
+		t.Run(name, func(t *testing.T) {
+			c := testQuerier.(*cache)
+			counter := &manifestCountingBackend{backend: c.backend}
+			c.backend = counter
+
+			// Bundles with an image are listed without their manifests,
+			// which are then never read.
+			bundles, err := c.ListBundles(context.TODO())
+			require.NoError(t, err)
+			require.Len(t, bundles, 2)
+			for _, b := range bundles {
+				switch b.CsvName {
+				case "foo.v0.1.0":
+					require.Empty(t, b.CsvJson)
+					require.Empty(t, b.Object)
+				case "foo.v0.2.0":
+					require.NotEmpty(t, b.CsvJson)
+					require.Len(t, b.Object, 1)
+				}
+			}
+			require.Equal(t, 1, counter.manifestReads)
+
+			b, err := c.GetBundle(context.TODO(), "foo", "stable", "foo.v0.1.0")
+			require.NoError(t, err)
+			require.Contains(t, b.CsvJson, `"name":"foo.v0.1.0"`)
+			require.Len(t, b.Object, 1)
+			require.Equal(t, 2, counter.manifestReads)
+		})
+	}
+}
+
+func TestCache_BuildMergedFS(t *testing.T) {
+	cockroachdbFS := fstest.MapFS{"cockroachdb.json": validFS["cockroachdb.json"]}
+	etcdFS := fstest.MapFS{"etcd.json": validFS["etcd.json"]}
+
+	for _, format := range []string{FormatJSON, FormatPogrebV1, FormatMMapV1} {
+		t.Run(format, func(t *testing.T) {
+			c, err := New(t.TempDir(), WithFormat(format), WithLog(log.Null()))
+			require.NoError(t, err)
+			merged, err := declcfg.MergeFS(context.Background(), declcfg.NamedFS{Name: "a", FS: cockroachdbFS}, declcfg.NamedFS{Name: "b", FS: etcdFS})
+			require.NoError(t, err)
+			require.NoError(t, c.Build(context.Background(), merged))
+			require.NoError(t, c.Load(context.Background()))
+			packages, err := c.ListPackages(context.TODO())
+			require.NoError(t, err)
+			require.ElementsMatch(t, []string{"cockroachdb", "etcd"}, packages)
+		})
+	}
+}
+
+func TestCache_BuildShardedFS(t *testing.T) {
+	cfg, err := declcfg.LoadFS(context.Background(), validFS)
+	require.NoError(t, err)
+	shardedDir := t.TempDir()
+	require.NoError(t, declcfg.WriteFS(*cfg, shardedDir, declcfg.WriteJSON, ".json",
+		declcfg.WithBundleShards(declcfg.ShardByMinorVersion), declcfg.WithBundlesPerFile(1)))
+
+	expected := genTestCaches(t, validFS)
+	for name, c := range genTestCaches(t, os.DirFS(shardedDir)) {
+		t.Run(name, func(t *testing.T) {
+			expectedBundles, err := expected[name].ListBundles(context.TODO())
+			require.NoError(t, err)
+			bundles, err := c.ListBundles(context.TODO())
+			require.NoError(t, err)
+			require.ElementsMatch(t, expectedBundles, bundles)
+
+			expectedPkg, err := expected[name].GetPackage(context.TODO(), "etcd")
+			require.NoError(t, err)
+			pkg, err := c.GetPackage(context.TODO(), "etcd")
+			require.NoError(t, err)
+			require.Equal(t, expectedPkg, pkg)
+		})
+	}
+}
+
+func TestCache_BuildGzip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(validFS["etcd.json"].Data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	fbcFS := fstest.MapFS{
+		"cockroachdb.json": validFS["cockroachdb.json"],
+		"etcd.json.gz":     &fstest.MapFile{Data: buf.Bytes()},
+	}
+
+	for _, format := range []string{FormatJSON, FormatPogrebV1, FormatMMapV1} {
+		t.Run(format, func(t *testing.T) {
+			c, err := New(t.TempDir(), WithFormat(format), WithLog(log.Null()))
+			require.NoError(t, err)
+			require.NoError(t, c.Build(context.Background(), fbcFS))
+			require.NoError(t, c.Load(context.Background()))
+			packages, err := c.ListPackages(context.TODO())
+			require.NoError(t, err)
+			require.ElementsMatch(t, []string{"cockroachdb", "etcd"}, packages)
+		})
+	}
+}
+
+func TestCache_CheckIntegrityMetadata(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatPogrebV1, FormatMMapV1} {
+		t.Run(format, func(t *testing.T) {
+			open := func(t *testing.T, dir, opmVersion string) Cache {
+				c, err := New(dir, WithOpmVersion(opmVersion), WithLog(log.Null()))
+				require.NoError(t, err)
+				t.Cleanup(func() { _ = c.Close() })
+				return c
+			}
+			build := func(t *testing.T, opmVersion string) string {
+				dir := t.TempDir()
+				c, err := New(dir, WithFormat(format), WithOpmVersion(opmVersion), WithLog(log.Null()))
+				require.NoError(t, err)
+				require.NoError(t, c.Build(context.Background(), validFS))
+				require.NoError(t, c.Close())
+				return dir
+			}
+
+			t.Run("SameOpmVersion", func(t *testing.T) {
+				dir := build(t, "v1.0.0")
+				require.NoError(t, open(t, dir, "v1.0.0").CheckIntegrity(context.Background(), validFS))
+			})
+			t.Run("UnknownOpmVersion", func(t *testing.T) {
+				dir := build(t, "")
+				require.NoError(t, open(t, dir, "v1.0.0").CheckIntegrity(context.Background(), validFS))
+				dir = build(t, "v1.0.0")
+				require.NoError(t, open(t, dir, "").CheckIntegrity(context.Background(), validFS))
+			})
+			t.Run("DifferentOpmVersion", func(t *testing.T) {
+				dir := build(t, "v1.0.0")
+				c := open(t, dir, "v1.1.0")
+				require.EqualError(t, c.CheckIntegrity(context.Background(), validFS), "cache requires rebuild: cache was built by opm v1.0.0, but this is opm v1.1.0")
+
+				// The cache is rebuilt with the current version.
+				require.NoError(t, LoadOrRebuild(context.Background(), c, validFS))
+				require.NoError(t, c.CheckIntegrity(context.Background(), validFS))
+			})
+			t.Run("DifferentFormatVersion", func(t *testing.T) {
+				dir := build(t, "v1.0.0")
+				require.NoError(t, writeMetadata(dir, metadata{OpmVersion: "v1.0.0", Format: format, FormatVersion: FormatVersion - 1}))
+				require.EqualError(t, open(t, dir, "v1.0.0").CheckIntegrity(context.Background(), validFS), fmt.Sprintf("cache requires rebuild: cache has format %s version %d, but current format is %s version %d", format, FormatVersion-1, format, FormatVersion))
+			})
+			t.Run("NoMetadata", func(t *testing.T) {
+				dir := build(t, "v1.0.0")
+				require.NoError(t, os.Remove(filepath.Join(dir, metadataFile)))
+				require.EqualError(t, open(t, dir, "v1.0.0").CheckIntegrity(context.Background(), validFS), "cache requires rebuild: cache has no metadata, it was built by an older opm version")
+			})
+		})
+	}
+}
+
+func TestCache_BuildProgress(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatPogrebV1, FormatMMapV1} {
+		t.Run(format, func(t *testing.T) {
+			var progress []BuildProgress
+			c, err := New(t.TempDir(), WithFormat(format), WithLog(log.Null()), WithBuildProgress(func(p BuildProgress) {
+				progress = append(progress, p)
+			}))
+			require.NoError(t, err)
+			defer c.Close()
+			require.NoError(t, c.Build(context.Background(), validFS))
+			require.Equal(t, []BuildProgress{{Packages: 1, TotalPackages: 2}, {Packages: 2, TotalPackages: 2}}, progress)
+		})
+	}
+}
+
+func TestCache_BuildCanceled(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatPogrebV1, FormatMMapV1} {
+		t.Run(format, func(t *testing.T) {
+			dir := t.TempDir()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			c, err := New(dir, WithFormat(format), WithLog(log.Null()), WithConcurrency(1), WithBuildProgress(func(BuildProgress) {
+				cancel()
+			}))
+			require.NoError(t, err)
+			defer c.Close()
+
+			// A canceled rebuild leaves the previous cache as it was.
+			require.NoError(t, c.Build(context.Background(), validFS))
+			require.NoError(t, c.CheckIntegrity(context.Background(), validFS))
+			require.ErrorContains(t, c.Build(ctx, validFS), context.Canceled.Error())
+			require.Empty(t, stagingDirs(t, dir))
+			require.NoError(t, c.CheckIntegrity(context.Background(), validFS))
+
+			require.NoError(t, LoadOrRebuild(context.Background(), c, validFS))
+			packages, err := c.ListPackages(context.Background())
+			require.NoError(t, err)
+			require.ElementsMatch(t, []string{"cockroachdb", "etcd"}, packages)
+		})
+	}
+}
+
+func TestCache_RecoverInterruptedBuild(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatPogrebV1, FormatMMapV1} {
+		t.Run(format, func(t *testing.T) {
+			build := func(t *testing.T) string {
+				dir := t.TempDir()
+				c, err := New(dir, WithFormat(format), WithLog(log.Null()))
+				require.NoError(t, err)
+				require.NoError(t, c.Build(context.Background(), validFS))
+				require.NoError(t, c.Close())
+				return dir
+			}
+			t.Run("StagingDir", func(t *testing.T) {
+				dir := build(t)
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, stagingDirPrefix+"123", "cache"), 0750))
+
+				// Staging directories of interrupted builds are removed,
+				// but the cache is kept.
+				c, err := New(dir, WithFormat(format), WithLog(log.Null()))
+				require.NoError(t, err)
+				defer c.Close()
+				require.Empty(t, stagingDirs(t, dir))
+				require.NoError(t, c.CheckIntegrity(context.Background(), validFS))
+			})
+			t.Run("IncompleteCache", func(t *testing.T) {
+				dir := build(t)
+				require.NoError(t, os.WriteFile(filepath.Join(dir, incompleteMarkerFile), nil, 0600))
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, stagingDirPrefix+"123"), 0750))
+
+				// A cache whose content was being replaced is discarded
+				// and rebuilt.
+				c, err := New(dir, WithFormat(format), WithLog(log.Null()))
+				require.NoError(t, err)
+				defer c.Close()
+				require.NoFileExists(t, filepath.Join(dir, incompleteMarkerFile))
+				require.NoFileExists(t, filepath.Join(dir, metadataFile))
+				require.Empty(t, stagingDirs(t, dir))
+				require.Error(t, c.CheckIntegrity(context.Background(), validFS))
+
+				require.NoError(t, LoadOrRebuild(context.Background(), c, validFS))
+				packages, err := c.ListPackages(context.Background())
+				require.NoError(t, err)
+				require.ElementsMatch(t, []string{"cockroachdb", "etcd"}, packages)
+			})
+		})
+	}
+}
+
+func stagingDirs(t *testing.T, dir string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, stagingDirPrefix+"*"))
+	require.NoError(t, err)
+	return matches
+}
+
+func TestCheckReproducible(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatPogrebV1, FormatMMapV1} {
+		t.Run(format, func(t *testing.T) {
+			require.NoError(t, CheckReproducible(context.Background(), validFS, WithFormat(format), WithLog(log.Null())))
+		})
+	}
+}
+
 func genTestCaches(t *testing.T, fbcFS fs.FS) map[string]Cache {
 	t.Helper()
 
 	caches := make(map[string]Cache)
-	for _, format := range []string{FormatJSON, FormatPogrebV1} {
+	for _, format := range []string{FormatJSON, FormatPogrebV1, FormatMMapV1} {
 		c, err := New(t.TempDir(), WithFormat(format), WithLog(log.Null()))
 		require.NoError(t, err)
 		caches[format] = c
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cacheModeDir = 0770
+
+	// stagingDirPrefix prefixes the names of the directories, in the cache
+	// directory, that caches are built in.
+	stagingDirPrefix = ".build-"
+
+	// incompleteMarkerFile is present in the cache directory while its
+	// content is being replaced by a newly built cache. If a build is
+	// interrupted while it is present, the content of the cache directory is
+	// a mix of the previous and the new cache.
+	incompleteMarkerFile = ".incomplete"
+)
+
+// commit replaces the content of the cache directory with the cache built in
+// stagingDir, and opens it. The cache directory is marked as incomplete while
+// its content is replaced, so that if commit is interrupted, the next cache
+// opened in the directory discards it rather than serving it.
+func (c *cache) commit(stagingDir string) error {
+	marker := filepath.Join(c.cacheDir, incompleteMarkerFile)
+	if err := writeIncompleteMarker(marker); err != nil {
+		return fmt.Errorf("mark cache as incomplete: %v", err)
+	}
+	if err := c.backend.Close(); err != nil {
+		return fmt.Errorf("close cache: %v", err)
+	}
+
+	entries, err := os.ReadDir(c.cacheDir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		path := filepath.Join(c.cacheDir, e.Name())
+		if path == stagingDir || e.Name() == incompleteMarkerFile {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+	staged, err := os.ReadDir(stagingDir)
+	if err != nil {
+		return err
+	}
+	for _, e := range staged {
+		if err := os.Rename(filepath.Join(stagingDir, e.Name()), filepath.Join(c.cacheDir, e.Name())); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(stagingDir); err != nil {
+		return err
+	}
+	if err := os.Remove(marker); err != nil {
+		return fmt.Errorf("mark cache as complete: %v", err)
+	}
+
+	b, err := newBackend(c.cacheDir, c.backend.Name())
+	if err != nil {
+		return err
+	}
+	if err := b.Open(); err != nil {
+		return fmt.Errorf("open cache: %v", err)
+	}
+	c.backend = b
+	return nil
+}
+
+// writeIncompleteMarker creates the marker file and syncs it, so that it is
+// on disk before any of the content of the cache directory is replaced.
+func writeIncompleteMarker(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// recoverCacheDir cleans up after builds that were interrupted, e.g. by a
+// crash or a restart. It removes the staging directories they left behind
+// and, if the content of the cache directory was being replaced, all of that
+// content, so that the cache fails integrity checks and is rebuilt rather
+// than served partially written.
+func recoverCacheDir(cacheDir string, log *logrus.Entry) error {
+	entries, err := os.ReadDir(cacheDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	incomplete := false
+	for _, e := range entries {
+		if e.Name() == incompleteMarkerFile {
+			incomplete = true
+			break
+		}
+	}
+	if incomplete {
+		log.Warn("cache directory was left partially written by an interrupted build, discarding its content")
+	}
+	for _, e := range entries {
+		if e.Name() == incompleteMarkerFile {
+			continue
+		}
+		if !incomplete && !strings.HasPrefix(e.Name(), stagingDirPrefix) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(cacheDir, e.Name())); err != nil {
+			return err
+		}
+	}
+	// The marker is removed last, so that recovery is retried if it is
+	// interrupted too.
+	if incomplete {
+		return os.Remove(filepath.Join(cacheDir, incompleteMarkerFile))
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+	"github.com/operator-framework/operator-registry/pkg/lib/log"
+)
+
+// Convert writes the content of the existing cache in srcDir to a new cache
+// in dstDir that uses the given format, without parsing the catalog again.
+// fbc must be the catalog that the source cache was built from: it is used to
+// verify the source cache and to compute the digest of the new cache.
+func Convert(ctx context.Context, srcDir, dstDir, format string, fbc fs.FS) error {
+	src, err := getBackend(srcDir, "", log.Null())
+	if err != nil {
+		return fmt.Errorf("detect source cache format: %v", err)
+	}
+	if !src.IsCachePresent() {
+		return fmt.Errorf("no cache found in %q", srcDir)
+	}
+	if err := src.Open(); err != nil {
+		return fmt.Errorf("open source cache: %v", err)
+	}
+	defer src.Close()
+
+	existingDigest, err := src.GetDigest(ctx)
+	if err != nil {
+		return fmt.Errorf("read source cache digest: %v", err)
+	}
+	computedDigest, err := src.ComputeDigest(ctx, fbc)
+	if err != nil {
+		return fmt.Errorf("compute source cache digest: %v", err)
+	}
+	if existingDigest != computedDigest {
+		return fmt.Errorf("source cache was not built from the provided catalog: cache reports digest as %q, but computed digest is %q", existingDigest, computedDigest)
+	}
+
+	dst, err := getBackend(dstDir, format, log.Null())
+	if err != nil {
+		return err
+	}
+
+	// ensure that generated cache is available to all future users
+	oldUmask := umask(000)
+	defer umask(oldUmask)
+
+	if err := dst.Init(); err != nil {
+		return fmt.Errorf("init cache: %v", err)
+	}
+	defer dst.Close()
+
+	pi, err := src.GetPackageIndex(ctx)
+	if err != nil {
+		return fmt.Errorf("get package index: %v", err)
+	}
+	if err := src.SendBundles(ctx, bundleSenderFunc(func(b *api.Bundle) error {
+		key := bundleKey{b.PackageName, b.ChannelName, b.CsvName}
+		manifests, err := src.GetBundleManifests(ctx, key)
+		if err != nil {
+			return err
+		}
+		if manifests == nil && (b.CsvJson != "" || len(b.Object) > 0) {
+			// Caches built before manifests were stored separately keep
+			// them in the bundle itself.
+			manifests = &api.Bundle{CsvJson: b.CsvJson, Object: b.Object}
+			b.CsvJson, b.Object = "", nil
+		}
+		if manifests != nil {
+			if err := dst.PutBundleManifests(ctx, key, manifests); err != nil {
+				return err
+			}
+		}
+		return dst.PutBundle(ctx, key, b)
+	})); err != nil {
+		return fmt.Errorf("copy bundles: %v", err)
+	}
+	if err := dst.PutPackageIndex(ctx, pi); err != nil {
+		return fmt.Errorf("store package index: %v", err)
+	}
+
+	catalog, err := src.GetCatalog(ctx)
+	if err != nil {
+		return fmt.Errorf("get catalog metadata: %v", err)
+	}
+	if catalog != nil {
+		if err := dst.PutCatalog(ctx, catalog); err != nil {
+			return fmt.Errorf("store catalog metadata: %v", err)
+		}
+	}
+
+	apis, err := src.GetAPIIndex(ctx)
+	if err != nil {
+		return fmt.Errorf("get API index: %v", err)
+	}
+	if apis == nil {
+		apis = newAPIIndex(pi)
+	}
+	if err := dst.PutAPIIndex(ctx, apis); err != nil {
+		return fmt.Errorf("store API index: %v", err)
+	}
+
+	digest, err := dst.ComputeDigest(ctx, fbc)
+	if err != nil {
+		return fmt.Errorf("compute digest: %v", err)
+	}
+	if err := dst.PutDigest(ctx, digest); err != nil {
+		return fmt.Errorf("store digest: %v", err)
+	}
+
+	// The converted cache has the same content as the source cache, so it
+	// keeps the opm version that built the source cache.
+	m := metadata{Format: dst.Name(), FormatVersion: FormatVersion}
+	srcMetadata, err := readMetadata(srcDir)
+	if err != nil {
+		return fmt.Errorf("read source cache metadata: %v", err)
+	}
+	if srcMetadata != nil {
+		m.OpmVersion = srcMetadata.OpmVersion
+	}
+	if err := writeMetadata(dstDir, m); err != nil {
+		return fmt.Errorf("store metadata: %v", err)
+	}
+	return nil
+}
+
+type bundleSenderFunc func(*api.Bundle) error
+
+func (f bundleSenderFunc) Send(b *api.Bundle) error {
+	return f(b)
+}
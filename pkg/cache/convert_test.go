@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+	"github.com/operator-framework/operator-registry/pkg/lib/log"
+)
+
+func TestConvert(t *testing.T) {
+	for _, srcFormat := range []string{FormatJSON, FormatPogrebV1} {
+		t.Run(srcFormat, func(t *testing.T) {
+			srcDir, dstDir := t.TempDir(), t.TempDir()
+			src, err := New(srcDir, WithFormat(srcFormat), WithLog(log.Null()))
+			require.NoError(t, err)
+			require.NoError(t, LoadOrRebuild(context.Background(), src, validFS))
+			expectedBundles, err := src.ListBundles(context.Background())
+			require.NoError(t, err)
+			require.NoError(t, src.Close())
+
+			require.ErrorContains(t, Convert(context.Background(), srcDir, dstDir, FormatMMapV1, badBundleFS), "source cache was not built from the provided catalog")
+			require.NoError(t, Convert(context.Background(), srcDir, dstDir, FormatMMapV1, validFS))
+
+			dst, err := New(dstDir, WithFormat(FormatMMapV1), WithLog(log.Null()))
+			require.NoError(t, err)
+			defer dst.Close()
+			require.NoError(t, dst.CheckIntegrity(context.Background(), validFS))
+			require.NoError(t, dst.Load(context.Background()))
+
+			actualBundles, err := dst.ListBundles(context.Background())
+			require.NoError(t, err)
+			require.Len(t, actualBundles, len(expectedBundles))
+			expected := map[bundleKey]*api.Bundle{}
+			for _, b := range expectedBundles {
+				expected[bundleKey{b.PackageName, b.ChannelName, b.CsvName}] = b
+			}
+			for _, b := range actualBundles {
+				require.True(t, proto.Equal(expected[bundleKey{b.PackageName, b.ChannelName, b.CsvName}], b), "bundle %q in channel %q differs", b.CsvName, b.ChannelName)
+			}
+
+			entries, err := dst.GetChannelEntriesThatProvide(context.Background(), "etcd.database.coreos.com", "v1beta2", "EtcdBackup")
+			require.NoError(t, err)
+			require.NotEmpty(t, entries)
+		})
+	}
+}
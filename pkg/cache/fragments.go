@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/api"
+)
+
+const fragmentModeFile = 0660
+
+// packageFragment is the content that a cache stores for a package: its
+// package index entry, its bundles and their manifests, and the olm.catalog
+// blobs that are grouped with it. Fragments are kept in a fragment directory
+// that may be shared by many caches, keyed by the digest of the package's
+// blobs, so that builds of catalogs that share packages reuse them.
+type packageFragment struct {
+	Catalogs []declcfg.Catalog `json:"catalogs,omitempty"`
+	Packages packageIndex      `json:"packages,omitempty"`
+	Bundles  []fragmentBundle  `json:"bundles,omitempty"`
+}
+
+type fragmentBundle struct {
+	Key       bundleKey   `json:"key"`
+	Bundle    *api.Bundle `json:"bundle"`
+	Manifests *api.Bundle `json:"manifests,omitempty"`
+}
+
+// getPackageFragment returns the fragment of the package whose blobs are read
+// by blobs, and whether it was reused from the fragment directory. Fragments
+// that are not in the fragment directory are built from the blobs and added
+// to it. Fragments that can't be read or written are rebuilt, so that a
+// damaged fragment directory only slows builds down.
+func (c *cache) getPackageFragment(blobs []*io.SectionReader) (*packageFragment, bool, error) {
+	if c.fragmentDir == "" {
+		f, err := buildPackageFragment(blobs)
+		return f, false, err
+	}
+
+	digest, err := fragmentDigest(blobs, c.opmVersion)
+	if err != nil {
+		return nil, false, err
+	}
+	log := c.log.WithField("fragment", digest)
+	f, err := readFragment(c.fragmentDir, digest)
+	if err != nil {
+		log.WithError(err).Warn("ignoring unreadable cache fragment")
+	} else if f != nil {
+		log.Debug("reusing cache fragment")
+		return f, true, nil
+	}
+
+	if f, err = buildPackageFragment(blobs); err != nil {
+		return nil, false, err
+	}
+	if err := writeFragment(c.fragmentDir, digest, f); err != nil {
+		log.WithError(err).Warn("failed to store cache fragment")
+	}
+	return f, false, nil
+}
+
+func buildPackageFragment(blobs []*io.SectionReader) (*packageFragment, error) {
+	readers := make([]io.Reader, 0, len(blobs))
+	for _, b := range blobs {
+		readers = append(readers, io.NewSectionReader(b, 0, b.Size()))
+	}
+	pkgFbc, pkgModel, err := loadPackage(io.MultiReader(readers...))
+	if err != nil {
+		return nil, err
+	}
+	return newPackageFragment(pkgFbc, pkgModel)
+}
+
+// fragmentDigest returns the key of the fragment of a package. It is a digest
+// of the package's blobs, regardless of their order, which depends on how
+// catalog files are read, of the cache format version, and of the version of
+// opm, which determines how the blobs are converted.
+func fragmentDigest(blobs []*io.SectionReader, opmVersion string) (string, error) {
+	blobDigests := make([]string, 0, len(blobs))
+	for _, b := range blobs {
+		h := sha256.New()
+		if _, err := io.Copy(h, io.NewSectionReader(b, 0, b.Size())); err != nil {
+			return "", err
+		}
+		blobDigests = append(blobDigests, hex.EncodeToString(h.Sum(nil)))
+	}
+	slices.Sort(blobDigests)
+
+	h := sha256.New()
+	_, _ = io.WriteString(h, strconv.Itoa(FormatVersion)+"\n"+opmVersion+"\n")
+	for _, d := range blobDigests {
+		_, _ = io.WriteString(h, d+"\n")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func fragmentFile(dir, digest string) string {
+	return filepath.Join(dir, digest[:2], digest+".json")
+}
+
+// readFragment returns the fragment with the given digest, or nil if the
+// fragment directory does not have it.
+func readFragment(dir, digest string) (*packageFragment, error) {
+	data, err := os.ReadFile(fragmentFile(dir, digest))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f packageFragment
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse fragment: %v", err)
+	}
+	return &f, nil
+}
+
+// writeFragment adds a fragment to the fragment directory. The fragment is
+// written to a temporary file that is renamed into place, so that concurrent
+// builds sharing the directory never read partially written fragments.
+func writeFragment(dir, digest string, f *packageFragment) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	file := fragmentFile(dir, digest)
+	if err := os.MkdirAll(filepath.Dir(file), 0770); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".fragment-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), fragmentModeFile); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/pkg/lib/log"
+)
+
+func TestCache_BuildWithFragments(t *testing.T) {
+	build := func(t *testing.T, format string, fbc fs.FS, opts ...CacheOption) (Cache, string) {
+		dir := t.TempDir()
+		c, err := New(dir, append([]CacheOption{WithFormat(format), WithLog(log.Null())}, opts...)...)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = c.Close() })
+		require.NoError(t, c.Build(context.Background(), fbc))
+		require.NoError(t, c.Load(context.Background()))
+		return c, dir
+	}
+	filesDigest := func(t *testing.T, c Cache, dir string) string {
+		require.NoError(t, c.Close())
+		h := sha256.New()
+		require.NoError(t, fsToTar(h, os.DirFS(dir), nil))
+		return fmt.Sprintf("%x", h.Sum(nil))
+	}
+	fragmentFiles := func(t *testing.T, dir string) []string {
+		files, err := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+		require.NoError(t, err)
+		return files
+	}
+
+	for _, format := range []string{FormatJSON, FormatPogrebV1, FormatMMapV1} {
+		t.Run(format, func(t *testing.T) {
+			t.Run("SameContent", func(t *testing.T) {
+				fragmentDir := t.TempDir()
+				expected, expectedDir := build(t, format, validFS)
+				cold, coldDir := build(t, format, validFS, WithFragmentDir(fragmentDir))
+				require.Len(t, fragmentFiles(t, fragmentDir), 2)
+				warm, warmDir := build(t, format, validFS, WithFragmentDir(fragmentDir))
+				require.Len(t, fragmentFiles(t, fragmentDir), 2)
+
+				expectedBundles, err := expected.ListBundles(context.Background())
+				require.NoError(t, err)
+				for _, c := range []Cache{cold, warm} {
+					bundles, err := c.ListBundles(context.Background())
+					require.NoError(t, err)
+					require.ElementsMatch(t, expectedBundles, bundles)
+				}
+				if byteReproducibleFormats[format] {
+					expectedFiles := filesDigest(t, expected, expectedDir)
+					require.Equal(t, expectedFiles, filesDigest(t, cold, coldDir))
+					require.Equal(t, expectedFiles, filesDigest(t, warm, warmDir))
+				}
+			})
+
+			t.Run("SharedPackage", func(t *testing.T) {
+				fragmentDir := t.TempDir()
+				build(t, format, validFS, WithFragmentDir(fragmentDir))
+				files := fragmentFiles(t, fragmentDir)
+				require.Len(t, files, 2)
+
+				// The fragment of etcd is reused by a catalog that only
+				// has etcd, as the tampered bundle version shows.
+				var tampered int
+				for _, file := range files {
+					digest := filepath.Base(file[:len(file)-len(".json")])
+					f, err := readFragment(fragmentDir, digest)
+					require.NoError(t, err)
+					if _, ok := f.Packages["etcd"]; !ok {
+						continue
+					}
+					for i := range f.Bundles {
+						f.Bundles[i].Bundle.Version = "0.0.0-tampered"
+					}
+					require.NoError(t, writeFragment(fragmentDir, digest, f))
+					tampered++
+				}
+				require.Equal(t, 1, tampered)
+
+				c, _ := build(t, format, fstest.MapFS{"etcd.json": validFS["etcd.json"]}, WithFragmentDir(fragmentDir))
+				require.Len(t, fragmentFiles(t, fragmentDir), 2)
+				bundles, err := c.ListBundles(context.Background())
+				require.NoError(t, err)
+				require.NotEmpty(t, bundles)
+				for _, b := range bundles {
+					require.Equal(t, "0.0.0-tampered", b.Version)
+				}
+			})
+
+			t.Run("DifferentOpmVersion", func(t *testing.T) {
+				fragmentDir := t.TempDir()
+				build(t, format, validFS, WithFragmentDir(fragmentDir), WithOpmVersion("v1.0.0"))
+				build(t, format, validFS, WithFragmentDir(fragmentDir), WithOpmVersion("v1.1.0"))
+				require.Len(t, fragmentFiles(t, fragmentDir), 4)
+			})
+
+			t.Run("UnreadableFragment", func(t *testing.T) {
+				fragmentDir := t.TempDir()
+				build(t, format, validFS, WithFragmentDir(fragmentDir))
+				files := fragmentFiles(t, fragmentDir)
+				for _, file := range files {
+					require.NoError(t, os.WriteFile(file, []byte("{"), 0600))
+				}
+
+				c, _ := build(t, format, validFS, WithFragmentDir(fragmentDir))
+				packages, err := c.ListPackages(context.Background())
+				require.NoError(t, err)
+				require.ElementsMatch(t, []string{"cockroachdb", "etcd"}, packages)
+
+				// The unreadable fragments are replaced.
+				for _, file := range files {
+					digest := filepath.Base(file[:len(file)-len(".json")])
+					_, err := readFragment(fragmentDir, digest)
+					require.NoError(t, err)
+				}
+			})
+		})
+	}
+}
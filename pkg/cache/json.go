@@ -13,6 +13,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
 	"github.com/operator-framework/operator-registry/pkg/api"
 	"github.com/operator-framework/operator-registry/pkg/registry"
 )
@@ -33,6 +34,9 @@ const (
 	jsonDigestFile   = "digest"
 	jsonDir          = "cache"
 	jsonPackagesFile = jsonDir + string(filepath.Separator) + "packages.json"
+	jsonCatalogFile  = jsonDir + string(filepath.Separator) + "catalog.json"
+	jsonAPIsFile     = jsonDir + string(filepath.Separator) + "apis.json"
+	jsonManifestsDir = jsonDir + string(filepath.Separator) + "manifests"
 )
 
 type jsonBackend struct {
@@ -67,6 +71,9 @@ func (q *jsonBackend) Init() error {
 	if err := ensureEmptyDir(filepath.Join(q.baseDir, jsonDir), jsonCacheModeDir); err != nil {
 		return fmt.Errorf("failed to ensure JSON cache directory: %v", err)
 	}
+	if err := os.MkdirAll(filepath.Join(q.baseDir, jsonManifestsDir), jsonCacheModeDir); err != nil {
+		return fmt.Errorf("failed to create JSON cache manifests directory: %v", err)
+	}
 	if err := os.RemoveAll(filepath.Join(q.baseDir, jsonDigestFile)); err != nil {
 		return fmt.Errorf("failed to remove existing JSON digest file: %v", err)
 	}
@@ -140,6 +147,79 @@ func (q *jsonBackend) PutBundle(_ context.Context, key bundleKey, bundle *api.Bu
 	return nil
 }
 
+func (q *jsonBackend) manifestsFile(in bundleKey) string {
+	return filepath.Join(q.baseDir, jsonManifestsDir, fmt.Sprintf("%s_%s_%s.json", in.PackageName, in.ChannelName, in.Name))
+}
+
+func (q *jsonBackend) GetBundleManifests(_ context.Context, key bundleKey) (*api.Bundle, error) {
+	d, err := os.ReadFile(q.manifestsFile(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var b api.Bundle
+	if err := json.Unmarshal(d, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (q *jsonBackend) PutBundleManifests(_ context.Context, key bundleKey, manifests *api.Bundle) error {
+	d, err := json.Marshal(manifests)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(q.manifestsFile(key), d, jsonCacheModeFile)
+}
+
+func (q *jsonBackend) GetCatalog(_ context.Context) (*declcfg.Catalog, error) {
+	d, err := os.ReadFile(filepath.Join(q.baseDir, jsonCatalogFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c declcfg.Catalog
+	if err := json.Unmarshal(d, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (q *jsonBackend) PutCatalog(_ context.Context, c *declcfg.Catalog) error {
+	d, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(q.baseDir, jsonCatalogFile), d, jsonCacheModeFile)
+}
+
+func (q *jsonBackend) GetAPIIndex(_ context.Context) (*apiIndex, error) {
+	d, err := os.ReadFile(filepath.Join(q.baseDir, jsonAPIsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var idx apiIndex
+	if err := json.Unmarshal(d, &idx); err != nil {
+		return nil, err
+	}
+	return &idx, nil
+}
+
+func (q *jsonBackend) PutAPIIndex(_ context.Context, idx *apiIndex) error {
+	d, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(q.baseDir, jsonAPIsFile), d, jsonCacheModeFile)
+}
+
 func (q *jsonBackend) GetDigest(_ context.Context) (string, error) {
 	return readDigestFile(filepath.Join(q.baseDir, jsonDigestFile))
 }
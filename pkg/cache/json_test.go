@@ -14,7 +14,7 @@ import (
 
 func TestJSON_StableDigest(t *testing.T) {
 	cacheDir := t.TempDir()
-	c := &cache{backend: newJSONBackend(cacheDir), log: log.Null()}
+	c := &cache{backend: newJSONBackend(cacheDir), log: log.Null(), cacheDir: cacheDir}
 	require.NoError(t, c.Build(context.Background(), validFS))
 
 	actualDigest, err := c.backend.GetDigest(context.Background())
@@ -28,7 +28,7 @@ func TestJSON_StableDigest(t *testing.T) {
 	//
 	// If validFS needs to change DO NOT CHANGE the json cache implementation
 	// in the same pull request.
-	require.Equal(t, "9adad9ff6cf54e4f", actualDigest)
+	require.Equal(t, "758a000be5a5f1f9", actualDigest)
 }
 
 func TestJSON_CheckIntegrity(t *testing.T) {
@@ -96,7 +96,7 @@ func TestJSON_CheckIntegrity(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			cacheDir := t.TempDir()
-			c := &cache{backend: newJSONBackend(cacheDir), log: log.Null()}
+			c := &cache{backend: newJSONBackend(cacheDir), log: log.Null(), cacheDir: cacheDir}
 
 			if tc.build {
 				require.NoError(t, c.Build(context.Background(), tc.fbcFS))
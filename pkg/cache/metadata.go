@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// FormatVersion is the version of the representation of catalog content
+	// in caches, shared by all cache formats. It must be incremented whenever
+	// the content of newly built caches changes for the same catalog, so that
+	// existing caches are rebuilt.
+	FormatVersion = 4
+
+	metadataFile     = "metadata.json"
+	metadataModeFile = 0660
+)
+
+// metadata records what built a cache. Caches whose metadata does not match
+// the current opm binary fail their integrity check, so that they are rebuilt
+// rather than served with content that may differ from what this binary would
+// build.
+type metadata struct {
+	OpmVersion    string `json:"opmVersion,omitempty"`
+	Format        string `json:"format"`
+	FormatVersion int    `json:"formatVersion"`
+}
+
+// readMetadata returns the metadata of the cache in cacheDir, or nil if the
+// cache has none, as is the case for caches built before metadata was
+// recorded.
+func readMetadata(cacheDir string) (*metadata, error) {
+	data, err := os.ReadFile(filepath.Join(cacheDir, metadataFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m metadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse %s: %v", metadataFile, err)
+	}
+	return &m, nil
+}
+
+func writeMetadata(cacheDir string, m metadata) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(cacheDir, metadataFile), data, metadataModeFile)
+}
+
+// checkMetadata returns an error if the cache was built by a different opm
+// version or with a different format version. Opm versions are only compared
+// if both the cache and this binary know theirs, so that caches built by
+// tools that do not record it can still be used.
+func (c *cache) checkMetadata() error {
+	existing, err := readMetadata(c.cacheDir)
+	if err != nil {
+		return fmt.Errorf("read cache metadata: %v", err)
+	}
+	current := c.metadata()
+	if existing == nil {
+		c.log.WithField("formatVersion", current.FormatVersion).Warn("cache requires rebuild: cache has no metadata, it was built by an older opm version")
+		return errors.New("cache requires rebuild: cache has no metadata, it was built by an older opm version")
+	}
+	if existing.Format != current.Format || existing.FormatVersion != current.FormatVersion {
+		c.log.WithField("existingFormat", fmt.Sprintf("%s/%d", existing.Format, existing.FormatVersion)).WithField("currentFormat", fmt.Sprintf("%s/%d", current.Format, current.FormatVersion)).Warn("cache requires rebuild: cache format changed")
+		return fmt.Errorf("cache requires rebuild: cache has format %s version %d, but current format is %s version %d", existing.Format, existing.FormatVersion, current.Format, current.FormatVersion)
+	}
+	if existing.OpmVersion != "" && current.OpmVersion != "" && existing.OpmVersion != current.OpmVersion {
+		c.log.WithField("existingOpmVersion", existing.OpmVersion).WithField("currentOpmVersion", current.OpmVersion).Warn("cache requires rebuild: cache was built by a different opm version")
+		return fmt.Errorf("cache requires rebuild: cache was built by opm %s, but this is opm %s", existing.OpmVersion, current.OpmVersion)
+	}
+	return nil
+}
+
+func (c *cache) metadata() metadata {
+	return metadata{
+		OpmVersion:    c.opmVersion,
+		Format:        c.backend.Name(),
+		FormatVersion: FormatVersion,
+	}
+}
@@ -0,0 +1,473 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	pogrebfs "github.com/akrylysov/pogreb/fs"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/api"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+var _ backend = &mmapV1Backend{}
+
+func newMMapV1Backend(baseDir string) *mmapV1Backend {
+	return &mmapV1Backend{
+		baseDir: baseDir,
+		bundles: newBundleKeys(),
+		records: map[bundleKey]mmapV1Record{},
+	}
+}
+
+const (
+	FormatMMapV1 = "mmap.v1"
+
+	mmapV1CacheModeDir  = 0750
+	mmapV1CacheModeFile = 0640
+
+	mmapV1CacheDir    = FormatMMapV1
+	mmapV1DigestFile  = mmapV1CacheDir + "/digest"
+	mmapV1BundlesFile = mmapV1CacheDir + "/bundles.bin"
+	mmapV1IndexFile   = mmapV1CacheDir + "/index.json"
+)
+
+// mmapV1Backend stores all bundles and their manifests in a single file of
+// concatenated protobuf records, which is memory-mapped read-only when the
+// cache is served. Records are decoded only when they are requested, so the
+// heap only holds the package, API and record indexes, which are kept in a
+// small JSON file next to the bundle records.
+type mmapV1Backend struct {
+	baseDir string
+	bundles bundleKeys
+
+	mu      sync.RWMutex
+	index   mmapV1Index
+	records map[bundleKey]mmapV1Record
+	writer  *os.File
+	size    int64
+	dirty   bool
+	data    pogrebfs.File
+}
+
+type mmapV1Index struct {
+	Packages packageIndex     `json:"packages"`
+	Catalog  *declcfg.Catalog `json:"catalog,omitempty"`
+	APIs     *apiIndex        `json:"apis,omitempty"`
+	Records  []mmapV1Record   `json:"records"`
+}
+
+// mmapV1Record locates the protobuf records of a bundle and of its manifests
+// in the bundle record file.
+type mmapV1Record struct {
+	Key       bundleKey   `json:"key"`
+	Bundle    mmapV1Span  `json:"bundle"`
+	Manifests *mmapV1Span `json:"manifests,omitempty"`
+}
+
+type mmapV1Span struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+}
+
+func (q *mmapV1Backend) Name() string {
+	return FormatMMapV1
+}
+
+func (q *mmapV1Backend) IsCachePresent() bool {
+	entries, err := os.ReadDir(q.baseDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() == mmapV1CacheDir {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *mmapV1Backend) Init() error {
+	if err := q.Close(); err != nil {
+		return fmt.Errorf("failed to close existing cache: %v", err)
+	}
+	if err := ensureEmptyDir(filepath.Join(q.baseDir, mmapV1CacheDir), mmapV1CacheModeDir); err != nil {
+		return fmt.Errorf("ensure empty cache directory: %v", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.bundles = newBundleKeys()
+	q.records = map[bundleKey]mmapV1Record{}
+	q.index = mmapV1Index{}
+	q.size = 0
+	q.dirty = true
+	return q.openWriter()
+}
+
+func (q *mmapV1Backend) openWriter() error {
+	w, err := os.OpenFile(filepath.Join(q.baseDir, mmapV1BundlesFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, mmapV1CacheModeFile)
+	if err != nil {
+		return err
+	}
+	q.writer = w
+	return nil
+}
+
+func (q *mmapV1Backend) Open() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	indexData, err := os.ReadFile(filepath.Join(q.baseDir, mmapV1IndexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		// The cache has not been built yet.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var index mmapV1Index
+	if err := json.Unmarshal(indexData, &index); err != nil {
+		return fmt.Errorf("decode cache index: %v", err)
+	}
+
+	q.bundles = newBundleKeys()
+	q.records = make(map[bundleKey]mmapV1Record, len(index.Records))
+	for _, r := range index.Records {
+		q.bundles.Set(r.Key)
+		q.records[r.Key] = r
+		for _, span := range []*mmapV1Span{&r.Bundle, r.Manifests} {
+			if span != nil && span.Offset+span.Length > q.size {
+				q.size = span.Offset + span.Length
+			}
+		}
+	}
+	index.Records = nil
+	q.index = index
+	return q.openData()
+}
+
+func (q *mmapV1Backend) openData() error {
+	data, err := pogrebfs.OSMMap.OpenFile(filepath.Join(q.baseDir, mmapV1BundlesFile), os.O_RDONLY, 0)
+	if err != nil {
+		return fmt.Errorf("map bundle records: %v", err)
+	}
+	q.data = data
+	return nil
+}
+
+func (q *mmapV1Backend) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.flush(); err != nil {
+		return err
+	}
+	if q.data != nil {
+		if err := q.data.Close(); err != nil {
+			return err
+		}
+		q.data = nil
+	}
+	return nil
+}
+
+// flush finishes any pending writes: it closes the bundle record file, writes
+// the index, and re-maps the bundle records so they can be read. It must be
+// called with q.mu held.
+func (q *mmapV1Backend) flush() error {
+	if !q.dirty {
+		return nil
+	}
+	if q.writer != nil {
+		if err := q.writer.Sync(); err != nil {
+			return err
+		}
+		if err := q.writer.Close(); err != nil {
+			return err
+		}
+		q.writer = nil
+	}
+
+	index := q.index
+	index.Records = make([]mmapV1Record, 0, len(q.records))
+	if err := q.bundles.Walk(func(k bundleKey) error {
+		index.Records = append(index.Records, q.records[k])
+		return nil
+	}); err != nil {
+		return err
+	}
+	indexData, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(q.baseDir, mmapV1IndexFile), indexData, mmapV1CacheModeFile); err != nil {
+		return err
+	}
+
+	if q.data != nil {
+		if err := q.data.Close(); err != nil {
+			return err
+		}
+		q.data = nil
+	}
+	if err := q.openData(); err != nil {
+		return err
+	}
+	q.dirty = false
+	return nil
+}
+
+func (q *mmapV1Backend) GetPackageIndex(_ context.Context) (packageIndex, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.index.Packages == nil {
+		return nil, fmt.Errorf("package index not found")
+	}
+	return q.index.Packages, nil
+}
+
+func (q *mmapV1Backend) PutPackageIndex(_ context.Context, pi packageIndex) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.index.Packages = pi
+	q.dirty = true
+	return nil
+}
+
+func (q *mmapV1Backend) SendBundles(ctx context.Context, s registry.BundleSender) error {
+	return q.bundles.Walk(func(key bundleKey) error {
+		bundle, err := q.GetBundle(ctx, key)
+		if err != nil {
+			return err
+		}
+		return s.Send(bundle)
+	})
+}
+
+// withRecord calls fn with the record of the bundle with the given key, after
+// flushing any pending writes. Slices returned by q.slice are only valid until
+// fn returns, because the mapping is replaced when pending writes are flushed.
+func (q *mmapV1Backend) withRecord(key bundleKey, fn func(mmapV1Record) error) error {
+	q.mu.RLock()
+	if q.dirty {
+		q.mu.RUnlock()
+		q.mu.Lock()
+		err := q.flush()
+		q.mu.Unlock()
+		if err != nil {
+			return err
+		}
+		q.mu.RLock()
+	}
+	defer q.mu.RUnlock()
+
+	r, ok := q.records[key]
+	if !ok {
+		return fmt.Errorf("bundle not found")
+	}
+	return fn(r)
+}
+
+// slice returns the bytes of span in the mapped bundle record file. It must be
+// called with q.mu held.
+func (q *mmapV1Backend) slice(span mmapV1Span) ([]byte, error) {
+	if q.data == nil {
+		return nil, fmt.Errorf("cache is not open")
+	}
+	return q.data.Slice(span.Offset, span.Offset+span.Length)
+}
+
+// append writes d to the end of the bundle record file and returns its span.
+// It must be called with q.mu held.
+func (q *mmapV1Backend) append(d []byte) (mmapV1Span, error) {
+	if q.writer == nil {
+		if err := q.openWriter(); err != nil {
+			return mmapV1Span{}, err
+		}
+	}
+	if _, err := q.writer.Write(d); err != nil {
+		return mmapV1Span{}, err
+	}
+	span := mmapV1Span{Offset: q.size, Length: int64(len(d))}
+	q.size += int64(len(d))
+	q.dirty = true
+	return span, nil
+}
+
+func (q *mmapV1Backend) GetBundle(_ context.Context, key bundleKey) (*api.Bundle, error) {
+	var b api.Bundle
+	if err := q.withRecord(key, func(r mmapV1Record) error {
+		d, err := q.slice(r.Bundle)
+		if err != nil {
+			return err
+		}
+		return proto.Unmarshal(d, &b)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to get data for package %q, channel %q, key %q: %w", key.PackageName, key.ChannelName, key.Name, err)
+	}
+	return &b, nil
+}
+
+func (q *mmapV1Backend) PutBundle(_ context.Context, key bundleKey, bundle *api.Bundle) error {
+	d, err := proto.Marshal(bundle)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	span, err := q.append(d)
+	if err != nil {
+		return err
+	}
+	r := q.records[key]
+	r.Key, r.Bundle = key, span
+	q.records[key] = r
+	q.bundles.Set(key)
+	return nil
+}
+
+func (q *mmapV1Backend) GetBundleManifests(_ context.Context, key bundleKey) (*api.Bundle, error) {
+	var b *api.Bundle
+	if err := q.withRecord(key, func(r mmapV1Record) error {
+		if r.Manifests == nil {
+			return nil
+		}
+		d, err := q.slice(*r.Manifests)
+		if err != nil {
+			return err
+		}
+		b = &api.Bundle{}
+		return proto.Unmarshal(d, b)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to get manifests for package %q, channel %q, key %q: %w", key.PackageName, key.ChannelName, key.Name, err)
+	}
+	return b, nil
+}
+
+func (q *mmapV1Backend) PutBundleManifests(_ context.Context, key bundleKey, manifests *api.Bundle) error {
+	d, err := proto.Marshal(manifests)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	span, err := q.append(d)
+	if err != nil {
+		return err
+	}
+	r := q.records[key]
+	r.Key, r.Manifests = key, &span
+	q.records[key] = r
+	return nil
+}
+
+func (q *mmapV1Backend) GetCatalog(_ context.Context) (*declcfg.Catalog, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.index.Catalog, nil
+}
+
+func (q *mmapV1Backend) PutCatalog(_ context.Context, c *declcfg.Catalog) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.index.Catalog = c
+	q.dirty = true
+	return nil
+}
+
+func (q *mmapV1Backend) GetAPIIndex(_ context.Context) (*apiIndex, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.index.APIs, nil
+}
+
+func (q *mmapV1Backend) PutAPIIndex(_ context.Context, idx *apiIndex) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.index.APIs = idx
+	q.dirty = true
+	return nil
+}
+
+func (q *mmapV1Backend) GetDigest(_ context.Context) (string, error) {
+	return readDigestFile(filepath.Join(q.baseDir, mmapV1DigestFile))
+}
+
+// ComputeDigest hashes the FBC content and the cache content. Bundle records
+// are written in the order in which bundles are processed, which varies
+// between builds, so they are hashed in bundle key order rather than as the
+// raw contents of the record file.
+func (q *mmapV1Backend) ComputeDigest(ctx context.Context, fbcFsys fs.FS) (string, error) {
+	computedHasher := fnv.New64a()
+
+	// Use concurrency=1 to ensure deterministic ordering of meta blobs.
+	loadOpts := []declcfg.LoadOption{declcfg.WithConcurrency(1)}
+	if err := declcfg.WalkMetasFS(ctx, fbcFsys, func(path string, meta *declcfg.Meta, err error) error {
+		if err != nil {
+			return err
+		}
+		if _, err := computedHasher.Write(meta.Blob); err != nil {
+			return err
+		}
+		return nil
+	}, loadOpts...); err != nil {
+		return "", err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.flush(); err != nil {
+		return "", err
+	}
+	if q.data == nil {
+		// Nothing has been cached yet.
+		return fmt.Sprintf("%x", computedHasher.Sum(nil)), nil
+	}
+
+	index := q.index
+	index.Records = nil
+	indexData, err := json.Marshal(index)
+	if err != nil {
+		return "", err
+	}
+	if _, err := computedHasher.Write(indexData); err != nil {
+		return "", err
+	}
+	if err := q.bundles.Walk(func(k bundleKey) error {
+		r := q.records[k]
+		if _, err := fmt.Fprintf(computedHasher, "%s/%s/%s", k.PackageName, k.ChannelName, k.Name); err != nil {
+			return err
+		}
+		for _, span := range []*mmapV1Span{&r.Bundle, r.Manifests} {
+			if span == nil {
+				continue
+			}
+			d, err := q.slice(*span)
+			if err != nil {
+				return err
+			}
+			if _, err := computedHasher.Write(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("compute hash: %v", err)
+	}
+	return fmt.Sprintf("%x", computedHasher.Sum(nil)), nil
+}
+
+func (q *mmapV1Backend) PutDigest(_ context.Context, digest string) error {
+	return writeDigestFile(filepath.Join(q.baseDir, mmapV1DigestFile), digest, mmapV1CacheModeFile)
+}
@@ -2,11 +2,15 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
 
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
 	"github.com/operator-framework/operator-registry/alpha/model"
+	"github.com/operator-framework/operator-registry/alpha/property"
 	"github.com/operator-framework/operator-registry/pkg/api"
 	"github.com/operator-framework/operator-registry/pkg/registry"
 )
@@ -22,6 +26,17 @@ func (pkgs packageIndex) ListPackages(_ context.Context) ([]string, error) {
 	return packages, nil
 }
 
+// SearchPackages lists the packages that match filter.
+func (pkgs packageIndex) SearchPackages(_ context.Context, filter registry.PackageFilter) ([]string, error) {
+	packages := []string{}
+	for pkgName, pkg := range pkgs {
+		if filter.Matches(pkgName, pkg.Keywords, pkg.Categories) {
+			packages = append(packages, pkgName)
+		}
+	}
+	return packages, nil
+}
+
 func (pkgs packageIndex) GetPackage(_ context.Context, name string) (*registry.PackageManifest, error) {
 	pkg, ok := pkgs[name]
 	if !ok {
@@ -39,6 +54,10 @@ func (pkgs packageIndex) GetPackage(_ context.Context, name string) (*registry.P
 			Name:           ch.Name,
 			CurrentCSVName: ch.Head,
 			Deprecation:    deprecation,
+			HeadVersion:    bundleVersion(ch.Bundles[ch.Head]),
+			BundleCount:    len(ch.Bundles),
+			Priority:       ch.Priority,
+			Properties:     registryProperties(ch.Properties),
 		})
 	}
 	sort.Slice(channels, func(i, j int) bool { return strings.Compare(channels[i].Name, channels[j].Name) < 0 })
@@ -50,9 +69,22 @@ func (pkgs packageIndex) GetPackage(_ context.Context, name string) (*registry.P
 	if pkg.Deprecation != nil {
 		registryPackage.Deprecation = &registry.Deprecation{Message: pkg.Deprecation.Message}
 	}
+	if pkg.Icon != nil {
+		registryPackage.Icon = &registry.Icon{Base64data: pkg.Icon.Data, MediaType: pkg.Icon.MediaType}
+	}
+	registryPackage.Properties = registryProperties(pkg.Properties)
 	return registryPackage, nil
 }
 
+func registryProperties(props []property.Property) []registry.Property {
+	// nolint:prealloc
+	var out []registry.Property
+	for _, p := range props {
+		out = append(out, registry.Property{Type: p.Type, Value: p.Value})
+	}
+	return out
+}
+
 func (pkgs packageIndex) GetChannelEntriesThatReplace(_ context.Context, name string) ([]*registry.ChannelEntry, error) {
 	var entries []*registry.ChannelEntry
 
@@ -83,7 +115,7 @@ func (pkgs packageIndex) GetBundleForChannel(ctx context.Context, getBundle getB
 	return getBundle(ctx, bundleKey{pkg.Name, ch.Name, ch.Head})
 }
 
-func (pkgs packageIndex) GetBundleThatReplaces(ctx context.Context, getBundle getBundleFunc, name, pkgName, channelName string) (*api.Bundle, error) {
+func (pkgs packageIndex) GetBundleThatReplaces(ctx context.Context, getBundle getBundleFunc, policy registry.ReplacementPolicy, name, pkgName, channelName string) (*api.Bundle, error) {
 	pkg, ok := pkgs[pkgName]
 	if !ok {
 		return nil, fmt.Errorf("package %s not found", pkgName)
@@ -93,39 +125,52 @@ func (pkgs packageIndex) GetBundleThatReplaces(ctx context.Context, getBundle ge
 		return nil, fmt.Errorf("package %q, channel %q not found", pkgName, channelName)
 	}
 
-	// NOTE: iterating over a map is non-deterministic in Go, so if multiple bundles replace this one,
-	//       the bundle returned by this function is also non-deterministic. The sqlite implementation
-	//       is ALSO non-deterministic because it doesn't use ORDER BY, so its probably okay for this
-	//       implementation to be non-deterministic as well.
+	candidates := make([]registry.ReplacementCandidate, 0, len(ch.Bundles))
 	for _, b := range ch.Bundles {
-		if bundleReplaces(b, name) {
-			return getBundle(ctx, bundleKey{pkg.Name, ch.Name, b.Name})
+		candidates = append(candidates, registry.ReplacementCandidate{
+			Name:     b.Name,
+			Version:  bundleVersion(b),
+			Replaces: b.Replaces,
+			Skips:    b.Skips,
+		})
+	}
+	// Skip ranges are not in the package index, but in the stored bundles.
+	// A bundle that replaces or skips this one wins over any whose skip range
+	// includes it, so the bundles are only read when there is none.
+	withoutSkipRange := policy
+	withoutSkipRange.SkipRange = false
+	replacement, ok := withoutSkipRange.BundleThatReplaces(name, candidates)
+	if !ok && policy.SkipRange {
+		for i := range candidates {
+			b, err := getBundle(ctx, bundleKey{pkg.Name, ch.Name, candidates[i].Name})
+			if err != nil {
+				return nil, err
+			}
+			candidates[i].SkipRange = b.SkipRange
 		}
+		replacement, ok = policy.BundleThatReplaces(name, candidates)
 	}
-	return nil, fmt.Errorf("no entry found for package %q, channel %q", pkgName, channelName)
+	if !ok {
+		return nil, fmt.Errorf("no entry found for package %q, channel %q", pkgName, channelName)
+	}
+	return getBundle(ctx, bundleKey{pkg.Name, ch.Name, replacement})
 }
 
-func (pkgs packageIndex) GetChannelEntriesThatProvide(ctx context.Context, getBundle getBundleFunc, group, version, kind string) ([]*registry.ChannelEntry, error) {
+func (pkgs packageIndex) GetChannelEntriesThatProvide(providers []bundleKey, group, version, kind string) ([]*registry.ChannelEntry, error) {
 	var entries []*registry.ChannelEntry
 
-	for _, pkg := range pkgs {
-		for _, ch := range pkg.Channels {
-			for _, b := range ch.Bundles {
-				provides, err := doesBundleProvide(ctx, getBundle, b.Package, b.Channel, b.Name, group, version, kind)
-				if err != nil {
-					return nil, err
-				}
-				if provides {
-					// TODO(joelanford): It seems like the SQLite query returns
-					//   invalid entries (i.e. where bundle `Replaces` isn't actually
-					//   in channel `ChannelName`). Is that a bug? For now, this mimics
-					//   the sqlite server and returns seemingly invalid channel entries.
-					//      Don't worry about this. Not used anymore.
-
-					entries = append(entries, pkgs.channelEntriesForBundle(b, true)...)
-				}
-			}
+	for _, p := range providers {
+		b, ok := pkgs[p.PackageName].Channels[p.ChannelName].Bundles[p.Name]
+		if !ok {
+			continue
 		}
+		// TODO(joelanford): It seems like the SQLite query returns
+		//   invalid entries (i.e. where bundle `Replaces` isn't actually
+		//   in channel `ChannelName`). Is that a bug? For now, this mimics
+		//   the sqlite server and returns seemingly invalid channel entries.
+		//      Don't worry about this. Not used anymore.
+
+		entries = append(entries, pkgs.channelEntriesForBundle(b, true)...)
 	}
 	if len(entries) == 0 {
 		return nil, fmt.Errorf("no channel entries found that provide group:%q version:%q kind:%q", group, version, kind)
@@ -141,20 +186,15 @@ func (pkgs packageIndex) GetChannelEntriesThatProvide(ctx context.Context, getBu
 //	---
 //	Separate, but possibly related, I noticed there are several channels in the channel entry
 //	table who's minimum depth is 1. What causes 1 to be minimum depth in some cases and 0 in others?
-func (pkgs packageIndex) GetLatestChannelEntriesThatProvide(ctx context.Context, getBundle getBundleFunc, group, version, kind string) ([]*registry.ChannelEntry, error) {
+func (pkgs packageIndex) GetLatestChannelEntriesThatProvide(providers []bundleKey, group, version, kind string) ([]*registry.ChannelEntry, error) {
 	var entries []*registry.ChannelEntry
 
-	for _, pkg := range pkgs {
-		for _, ch := range pkg.Channels {
-			b := ch.Bundles[ch.Head]
-			provides, err := doesBundleProvide(ctx, getBundle, b.Package, b.Channel, b.Name, group, version, kind)
-			if err != nil {
-				return nil, err
-			}
-			if provides {
-				entries = append(entries, pkgs.channelEntriesForBundle(b, false)...)
-			}
+	for _, p := range providers {
+		ch, ok := pkgs[p.PackageName].Channels[p.ChannelName]
+		if !ok || ch.Head != p.Name {
+			continue
 		}
+		entries = append(entries, pkgs.channelEntriesForBundle(ch.Bundles[ch.Head], false)...)
 	}
 	if len(entries) == 0 {
 		return nil, fmt.Errorf("no channel entries found that provide group:%q version:%q kind:%q", group, version, kind)
@@ -195,22 +235,30 @@ type cPkg struct {
 	Icon           *model.Icon `json:"icon"`
 	DefaultChannel string      `json:"defaultChannel"`
 	Channels       map[string]cChannel
-	Deprecation    *model.Deprecation `json:"deprecation,omitempty"`
+	Deprecation    *model.Deprecation  `json:"deprecation,omitempty"`
+	Properties     []property.Property `json:"properties,omitempty"`
+	// Keywords and Categories are those of the CSV at the head of the
+	// default channel, which packages are searched by.
+	Keywords   []string `json:"keywords,omitempty"`
+	Categories []string `json:"categories,omitempty"`
 }
 
 type cChannel struct {
 	Name        string
 	Head        string
+	Priority    int `json:"priority,omitempty"`
 	Bundles     map[string]cBundle
-	Deprecation *model.Deprecation `json:"deprecation,omitempty"`
+	Deprecation *model.Deprecation  `json:"deprecation,omitempty"`
+	Properties  []property.Property `json:"properties,omitempty"`
 }
 
 type cBundle struct {
-	Package  string   `json:"package"`
-	Channel  string   `json:"channel"`
-	Name     string   `json:"name"`
-	Replaces string   `json:"replaces"`
-	Skips    []string `json:"skips"`
+	Package    string              `json:"package"`
+	Channel    string              `json:"channel"`
+	Name       string              `json:"name"`
+	Replaces   string              `json:"replaces"`
+	Skips      []string            `json:"skips"`
+	Properties []property.Property `json:"properties,omitempty"`
 }
 
 func packagesFromModel(m model.Model) (map[string]cPkg, error) {
@@ -223,7 +271,9 @@ func packagesFromModel(m model.Model) (map[string]cPkg, error) {
 			DefaultChannel: p.DefaultChannel.Name,
 			Channels:       map[string]cChannel{},
 			Deprecation:    p.Deprecation,
+			Properties:     p.Properties,
 		}
+		newP.Keywords, newP.Categories = searchTerms(p)
 		for _, ch := range p.Channels {
 			head, err := ch.Head()
 			if err != nil {
@@ -232,16 +282,19 @@ func packagesFromModel(m model.Model) (map[string]cPkg, error) {
 			newCh := cChannel{
 				Name:        ch.Name,
 				Head:        head.Name,
+				Priority:    ch.Priority,
 				Bundles:     map[string]cBundle{},
 				Deprecation: ch.Deprecation,
+				Properties:  ch.Properties,
 			}
 			for _, b := range ch.Bundles {
 				newB := cBundle{
-					Package:  b.Package.Name,
-					Channel:  b.Channel.Name,
-					Name:     b.Name,
-					Replaces: b.Replaces,
-					Skips:    b.Skips,
+					Package:    b.Package.Name,
+					Channel:    b.Channel.Name,
+					Name:       b.Name,
+					Replaces:   b.Replaces,
+					Skips:      b.Skips,
+					Properties: indexedProperties(b.Properties),
 				}
 				newCh.Bundles[b.Name] = newB
 			}
@@ -252,6 +305,51 @@ func packagesFromModel(m model.Model) (map[string]cPkg, error) {
 	return pkgs, nil
 }
 
+// searchTerms returns the keywords and categories of the CSV at the head of
+// the default channel of p, from its CSV or its olm.csv.metadata property.
+func searchTerms(p *model.Package) ([]string, []string) {
+	if p.DefaultChannel == nil {
+		return nil, nil
+	}
+	head, err := p.DefaultChannel.Head()
+	if err != nil || head == nil {
+		return nil, nil
+	}
+	if head.CsvJSON != "" {
+		var csv v1alpha1.ClusterServiceVersion
+		if err := json.Unmarshal([]byte(head.CsvJSON), &csv); err == nil {
+			return csv.Spec.Keywords, registry.ParseCategories(csv.GetAnnotations()[registry.CategoriesAnnotation])
+		}
+	}
+	for _, prop := range head.Properties {
+		if prop.Type != property.TypeCSVMetadata {
+			continue
+		}
+		var m property.CSVMetadata
+		if err := json.Unmarshal(prop.Value, &m); err != nil {
+			return nil, nil
+		}
+		return m.Keywords, registry.ParseCategories(m.Annotations[registry.CategoriesAnnotation])
+	}
+	return nil, nil
+}
+
+// bundleVersion returns the version of the bundle's olm.package property, or
+// an empty string if the bundle has no indexed properties.
+func bundleVersion(b cBundle) string {
+	for _, p := range b.Properties {
+		if p.Type != property.TypePackage {
+			continue
+		}
+		var pkg property.Package
+		if err := json.Unmarshal(p.Value, &pkg); err != nil {
+			return ""
+		}
+		return pkg.Version
+	}
+	return ""
+}
+
 func bundleReplaces(b cBundle, name string) bool {
 	if b.Replaces == name {
 		return true
@@ -163,6 +163,79 @@ func (q *pogrebV1Backend) PutBundle(_ context.Context, key bundleKey, bundle *ap
 	return nil
 }
 
+func (q *pogrebV1Backend) manifestsDBKey(in bundleKey) []byte {
+	return []byte(fmt.Sprintf("manifests/%s/%s/%s", in.PackageName, in.ChannelName, in.Name))
+}
+
+func (q *pogrebV1Backend) GetBundleManifests(_ context.Context, key bundleKey) (*api.Bundle, error) {
+	d, err := q.db.Get(q.manifestsDBKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, nil
+	}
+	var b api.Bundle
+	if err := proto.Unmarshal(d, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (q *pogrebV1Backend) PutBundleManifests(_ context.Context, key bundleKey, manifests *api.Bundle) error {
+	d, err := proto.Marshal(manifests)
+	if err != nil {
+		return err
+	}
+	return q.db.Put(q.manifestsDBKey(key), d)
+}
+
+func (q *pogrebV1Backend) GetCatalog(_ context.Context) (*declcfg.Catalog, error) {
+	d, err := q.db.Get([]byte("catalog.json"))
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, nil
+	}
+	var c declcfg.Catalog
+	if err := json.Unmarshal(d, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (q *pogrebV1Backend) PutCatalog(_ context.Context, c *declcfg.Catalog) error {
+	d, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return q.db.Put([]byte("catalog.json"), d)
+}
+
+func (q *pogrebV1Backend) GetAPIIndex(_ context.Context) (*apiIndex, error) {
+	d, err := q.db.Get([]byte("apis.json"))
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, nil
+	}
+	var idx apiIndex
+	if err := json.Unmarshal(d, &idx); err != nil {
+		return nil, err
+	}
+	return &idx, nil
+}
+
+func (q *pogrebV1Backend) PutAPIIndex(_ context.Context, idx *apiIndex) error {
+	d, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	return q.db.Put([]byte("apis.json"), d)
+}
+
 func (q *pogrebV1Backend) GetDigest(_ context.Context) (string, error) {
 	return readDigestFile(filepath.Join(q.baseDir, pogrebDigestFile))
 }
@@ -14,7 +14,7 @@ import (
 
 func TestPogrebV1_StableDigest(t *testing.T) {
 	cacheDir := t.TempDir()
-	c := &cache{backend: newPogrebV1Backend(cacheDir), log: log.Null()}
+	c := &cache{backend: newPogrebV1Backend(cacheDir), log: log.Null(), cacheDir: cacheDir}
 	require.NoError(t, c.Build(context.Background(), validFS))
 
 	actualDigest, err := c.backend.GetDigest(context.Background())
@@ -28,7 +28,7 @@ func TestPogrebV1_StableDigest(t *testing.T) {
 	//
 	// If validFS needs to change DO NOT CHANGE the json cache implementation
 	// in the same pull request.
-	require.Equal(t, "485a767449dd66d4", actualDigest)
+	require.Equal(t, "b2891b7020a16d05", actualDigest)
 }
 
 func TestPogrebV1_CheckIntegrity(t *testing.T) {
@@ -95,7 +95,7 @@ func TestPogrebV1_CheckIntegrity(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			cacheDir := t.TempDir()
-			c := &cache{backend: newPogrebV1Backend(cacheDir), log: log.Null()}
+			c := &cache{backend: newPogrebV1Backend(cacheDir), log: log.Null(), cacheDir: cacheDir}
 
 			if tc.build {
 				require.NoError(t, c.Build(context.Background(), tc.fbcFS))
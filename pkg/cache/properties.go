@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+// BundleProperty is a property of a bundle in a channel, as returned by
+// Cache.GetBundleProperties.
+type BundleProperty struct {
+	PackageName string
+	ChannelName string
+	BundleName  string
+	Property    property.Property
+}
+
+// PropertyMatchFunc reports whether a property should be included in the
+// result of Cache.GetBundleProperties.
+type PropertyMatchFunc func(property.Property) bool
+
+// MatchGVK returns a PropertyMatchFunc that matches olm.gvk properties with
+// the given group, version and kind. Empty fields match any value, so
+// MatchGVK("foo", "", "") matches every GVK in group "foo".
+func MatchGVK(group, version, kind string) PropertyMatchFunc {
+	return func(p property.Property) bool {
+		var gvk property.GVK
+		if err := json.Unmarshal(p.Value, &gvk); err != nil {
+			return false
+		}
+		return (group == "" || gvk.Group == group) &&
+			(version == "" || gvk.Version == version) &&
+			(kind == "" || gvk.Kind == kind)
+	}
+}
+
+// isIndexedProperty returns whether properties of type typ are kept in the
+// package index. Bundle objects and CSV metadata are excluded because they are
+// large, and because they are also omitted from API bundle properties.
+func isIndexedProperty(typ string) bool {
+	return typ != property.TypeBundleObject && typ != property.TypeCSVMetadata
+}
+
+func indexedProperties(props []property.Property) []property.Property {
+	// nolint:prealloc
+	var out []property.Property
+	for _, p := range props {
+		if isIndexedProperty(p.Type) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+type indexedProperty struct {
+	key      bundleKey
+	property property.Property
+}
+
+// propertyIndex maps property types to the bundle properties of that type,
+// ordered by bundle key.
+type propertyIndex map[string][]indexedProperty
+
+// newPropertyIndex builds a property index from the properties stored in the
+// package index. It returns nil if any bundle is missing its properties,
+// which is the case for caches built before properties were indexed.
+func newPropertyIndex(pkgs packageIndex) propertyIndex {
+	idx := propertyIndex{}
+	for _, pkg := range pkgs {
+		for _, ch := range pkg.Channels {
+			for _, b := range ch.Bundles {
+				if len(b.Properties) == 0 {
+					return nil
+				}
+				key := bundleKey{b.Package, b.Channel, b.Name}
+				for _, p := range b.Properties {
+					idx[p.Type] = append(idx[p.Type], indexedProperty{key, p})
+				}
+			}
+		}
+	}
+	for _, props := range idx {
+		sort.SliceStable(props, func(i, j int) bool {
+			return bundleKeyComparator(props[i].key, props[j].key)
+		})
+	}
+	return idx
+}
+
+func (c *cache) GetBundleProperties(ctx context.Context, propertyType string, match PropertyMatchFunc) ([]BundleProperty, error) {
+	if !isIndexedProperty(propertyType) {
+		return nil, nil
+	}
+	if c.propertyIndex == nil {
+		return c.scanBundleProperties(ctx, propertyType, match)
+	}
+	// nolint:prealloc
+	var out []BundleProperty
+	for _, p := range c.propertyIndex[propertyType] {
+		if match != nil && !match(p.property) {
+			continue
+		}
+		out = append(out, BundleProperty{
+			PackageName: p.key.PackageName,
+			ChannelName: p.key.ChannelName,
+			BundleName:  p.key.Name,
+			Property:    p.property,
+		})
+	}
+	return out, nil
+}
+
+// scanBundleProperties serves property queries for caches that do not have a
+// property index by reading the properties of every bundle from the backend.
+func (c *cache) scanBundleProperties(ctx context.Context, propertyType string, match PropertyMatchFunc) ([]BundleProperty, error) {
+	var keys []bundleKey
+	for _, pkg := range c.packageIndex {
+		for _, ch := range pkg.Channels {
+			for _, b := range ch.Bundles {
+				keys = append(keys, bundleKey{b.Package, b.Channel, b.Name})
+			}
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return bundleKeyComparator(keys[i], keys[j]) })
+
+	// nolint:prealloc
+	var out []BundleProperty
+	for _, key := range keys {
+		apiBundle, err := c.backend.GetBundle(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range apiBundle.Properties {
+			prop := property.Property{Type: p.Type, Value: json.RawMessage(p.Value)}
+			if prop.Type != propertyType || (match != nil && !match(prop)) {
+				continue
+			}
+			out = append(out, BundleProperty{
+				PackageName: key.PackageName,
+				ChannelName: key.ChannelName,
+				BundleName:  key.Name,
+				Property:    prop,
+			})
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// reproducibleModTime is the modification time of the files of built caches,
+// so that copying a cache into an image layer does not record when it was
+// built.
+var reproducibleModTime = time.Unix(0, 0)
+
+// byteReproducibleFormats are the cache formats whose files are identical
+// for identical catalogs. pogreb.v1 databases embed a random hash seed, so
+// only the digest of pogreb.v1 caches is reproducible.
+var byteReproducibleFormats = map[string]bool{
+	FormatJSON:   true,
+	FormatMMapV1: true,
+}
+
+func resetModTimes(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&os.ModeSymlink != 0 {
+			return nil
+		}
+		return os.Chtimes(path, reproducibleModTime, reproducibleModTime)
+	})
+}
+
+// CheckReproducible builds the cache of fbc twice, in temporary directories,
+// and returns an error if the two builds have different digests. For formats
+// whose files are identical for identical catalogs, it also returns an error
+// if the cache files differ.
+func CheckReproducible(ctx context.Context, fbc fs.FS, cacheOpts ...CacheOption) error {
+	type build struct {
+		format      string
+		digest      string
+		filesDigest string
+	}
+	buildOnce := func() (*build, error) {
+		dir, err := os.MkdirTemp("", "opm-cache-reproducible-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+
+		c, err := New(dir, cacheOpts...)
+		if err != nil {
+			return nil, err
+		}
+		defer c.Close()
+		if err := c.Build(ctx, fbc); err != nil {
+			return nil, err
+		}
+		digest, err := c.Digest(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("read cache digest: %v", err)
+		}
+		b := &build{format: c.(*cache).backend.Name(), digest: digest}
+		if !byteReproducibleFormats[b.format] {
+			return b, nil
+		}
+		if err := c.Close(); err != nil {
+			return nil, err
+		}
+		h := sha256.New()
+		if err := fsToTar(h, os.DirFS(dir), nil); err != nil {
+			return nil, fmt.Errorf("hash cache files: %v", err)
+		}
+		b.filesDigest = fmt.Sprintf("%x", h.Sum(nil))
+		return b, nil
+	}
+
+	first, err := buildOnce()
+	if err != nil {
+		return fmt.Errorf("first build: %v", err)
+	}
+	second, err := buildOnce()
+	if err != nil {
+		return fmt.Errorf("second build: %v", err)
+	}
+	if first.digest != second.digest {
+		return fmt.Errorf("cache build is not reproducible: first build has digest %q, second build has digest %q", first.digest, second.digest)
+	}
+	if first.filesDigest != second.filesDigest {
+		return fmt.Errorf("cache build is not reproducible: %s cache files differ between builds with digest %q", first.format, first.digest)
+	}
+	return nil
+}
@@ -2,24 +2,34 @@ package client
 
 import (
 	"context"
-	"errors"
-	"io"
+	"fmt"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 
 	"github.com/operator-framework/operator-registry/pkg/api"
 )
 
 type Interface interface {
 	GetBundle(ctx context.Context, packageName, channelName, csvName string) (*api.Bundle, error)
+	GetBundleByVersion(ctx context.Context, packageName, channelName, version string) (*api.Bundle, error)
 	GetBundleInPackageChannel(ctx context.Context, packageName, channelName string) (*api.Bundle, error)
 	GetReplacementBundleInPackageChannel(ctx context.Context, currentName, packageName, channelName string) (*api.Bundle, error)
 	GetBundleThatProvides(ctx context.Context, group, version, kind string) (*api.Bundle, error)
 	ListBundles(ctx context.Context) (*BundleIterator, error)
+	ListPackages(ctx context.Context) (*Iterator[*api.PackageName], error)
+	ListPackageSummaries(ctx context.Context) (*Iterator[*api.PackageSummary], error)
+	GetChannelEntriesThatReplace(ctx context.Context, csvName string) (*Iterator[*api.ChannelEntry], error)
+	GetChannelEntriesThatProvide(ctx context.Context, group, version, kind string) (*Iterator[*api.ChannelEntry], error)
+	GetLatestChannelEntriesThatProvide(ctx context.Context, group, version, kind string) (*Iterator[*api.ChannelEntry], error)
 	GetPackage(ctx context.Context, packageName string) (*api.Package, error)
+	GetChannelHead(ctx context.Context, packageName, channelName string) (*api.Bundle, error)
+	GetUpgradeGraph(ctx context.Context, packageName string) (*api.UpgradeGraph, error)
+	WalkUpgradeGraph(ctx context.Context, packageName, channelName, from string, fn UpgradeGraphWalkFunc) error
 	HealthCheck(ctx context.Context, reconnectTimeout time.Duration) (bool, error)
 	Close() error
 }
@@ -32,41 +42,17 @@ type Client struct {
 
 var _ Interface = &Client{}
 
-type BundleStream interface {
-	Recv() (*api.Bundle, error)
-}
-
-type BundleIterator struct {
-	stream BundleStream
-	error  error
-}
-
-func NewBundleIterator(stream BundleStream) *BundleIterator {
-	return &BundleIterator{stream: stream}
-}
-
-func (it *BundleIterator) Next() *api.Bundle {
-	if it.error != nil {
-		return nil
-	}
-	next, err := it.stream.Recv()
-	if errors.Is(err, io.EOF) {
-		return nil
-	}
-	if err != nil {
-		it.error = err
-	}
-	return next
-}
-
-func (it *BundleIterator) Error() error {
-	return it.error
-}
-
 func (c *Client) GetBundle(ctx context.Context, packageName, channelName, csvName string) (*api.Bundle, error) {
 	return c.Registry.GetBundle(ctx, &api.GetBundleRequest{PkgName: packageName, ChannelName: channelName, CsvName: csvName})
 }
 
+// GetBundleByVersion returns the bundle of a channel of a package that has
+// version, or, if version is a semver range, the bundle of the channel with
+// the highest version in the range.
+func (c *Client) GetBundleByVersion(ctx context.Context, packageName, channelName, version string) (*api.Bundle, error) {
+	return c.Registry.GetBundle(ctx, &api.GetBundleRequest{PkgName: packageName, ChannelName: channelName, Version: version})
+}
+
 func (c *Client) GetBundleInPackageChannel(ctx context.Context, packageName, channelName string) (*api.Bundle, error) {
 	// nolint:staticcheck
 	return c.Registry.GetBundleForChannel(ctx, &api.GetBundleInChannelRequest{PkgName: packageName, ChannelName: channelName})
@@ -88,10 +74,105 @@ func (c *Client) ListBundles(ctx context.Context) (*BundleIterator, error) {
 	return NewBundleIterator(stream), nil
 }
 
+func (c *Client) ListPackages(ctx context.Context) (*Iterator[*api.PackageName], error) {
+	stream, err := c.Registry.ListPackages(ctx, &api.ListPackageRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return NewIterator[*api.PackageName](stream), nil
+}
+
+func (c *Client) ListPackageSummaries(ctx context.Context) (*Iterator[*api.PackageSummary], error) {
+	stream, err := c.Registry.ListPackageSummaries(ctx, &api.ListPackageSummariesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return NewIterator[*api.PackageSummary](stream), nil
+}
+
+func (c *Client) GetChannelEntriesThatReplace(ctx context.Context, csvName string) (*Iterator[*api.ChannelEntry], error) {
+	stream, err := c.Registry.GetChannelEntriesThatReplace(ctx, &api.GetAllReplacementsRequest{CsvName: csvName})
+	if err != nil {
+		return nil, err
+	}
+	return NewIterator[*api.ChannelEntry](stream), nil
+}
+
+func (c *Client) GetChannelEntriesThatProvide(ctx context.Context, group, version, kind string) (*Iterator[*api.ChannelEntry], error) {
+	stream, err := c.Registry.GetChannelEntriesThatProvide(ctx, &api.GetAllProvidersRequest{Group: group, Version: version, Kind: kind})
+	if err != nil {
+		return nil, err
+	}
+	return NewIterator[*api.ChannelEntry](stream), nil
+}
+
+func (c *Client) GetLatestChannelEntriesThatProvide(ctx context.Context, group, version, kind string) (*Iterator[*api.ChannelEntry], error) {
+	stream, err := c.Registry.GetLatestChannelEntriesThatProvide(ctx, &api.GetLatestProvidersRequest{Group: group, Version: version, Kind: kind})
+	if err != nil {
+		return nil, err
+	}
+	return NewIterator[*api.ChannelEntry](stream), nil
+}
+
 func (c *Client) GetPackage(ctx context.Context, packageName string) (*api.Package, error) {
 	return c.Registry.GetPackage(ctx, &api.GetPackageRequest{Name: packageName})
 }
 
+// GetChannelHead returns the bundle at the head of a channel of a package.
+func (c *Client) GetChannelHead(ctx context.Context, packageName, channelName string) (*api.Bundle, error) {
+	pkg, err := c.GetPackage(ctx, packageName)
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range pkg.GetChannels() {
+		if ch.GetName() == channelName {
+			return c.GetBundle(ctx, packageName, channelName, ch.GetCsvName())
+		}
+	}
+	return nil, fmt.Errorf("package %q has no channel %q", packageName, channelName)
+}
+
+func (c *Client) GetUpgradeGraph(ctx context.Context, packageName string) (*api.UpgradeGraph, error) {
+	return c.Registry.GetUpgradeGraph(ctx, &api.GetUpgradeGraphRequest{PkgName: packageName})
+}
+
+// UpgradeGraphWalkFunc is called by WalkUpgradeGraph for each edge that it
+// walks. If it returns an error, the walk stops and WalkUpgradeGraph returns
+// the error.
+type UpgradeGraphWalkFunc func(edge *api.UpgradeGraphEdge) error
+
+// WalkUpgradeGraph walks the upgrades of a channel of a package that start
+// from the bundle from, breadth-first, and calls fn for each edge that it
+// walks. The edges from each bundle are walked once, so fn is called once for
+// each edge that is reachable from the bundle. The bundle need not be in the
+// channel anymore, since bundles that were removed can still be installed.
+func (c *Client) WalkUpgradeGraph(ctx context.Context, packageName, channelName, from string, fn UpgradeGraphWalkFunc) error {
+	graph, err := c.GetUpgradeGraph(ctx, packageName)
+	if err != nil {
+		return err
+	}
+	edges := map[string][]*api.UpgradeGraphEdge{}
+	for _, e := range graph.GetEdges() {
+		if e.GetChannelName() == channelName {
+			edges[e.GetFrom()] = append(edges[e.GetFrom()], e)
+		}
+	}
+
+	visited := map[string]struct{}{from: {}}
+	for queue := []string{from}; len(queue) > 0; queue = queue[1:] {
+		for _, e := range edges[queue[0]] {
+			if err := fn(e); err != nil {
+				return err
+			}
+			if _, ok := visited[e.GetTo()]; !ok {
+				visited[e.GetTo()] = struct{}{}
+				queue = append(queue, e.GetTo())
+			}
+		}
+	}
+	return nil
+}
+
 func (c *Client) Close() error {
 	if c.Conn == nil {
 		return nil
@@ -117,9 +198,85 @@ func (c *Client) HealthCheck(ctx context.Context, reconnectTimeout time.Duration
 	return true, nil
 }
 
-func NewClient(address string) (*Client, error) {
+// ClientOption configures the connection of a Client created by NewClient.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	backoff     *backoff.Config
+	keepalive   *keepalive.ClientParameters
+	maxAttempts int
+	dialOptions []grpc.DialOption
+}
+
+// WithConnectBackoff sets the backoff between the attempts to connect to the
+// registry, which defaults to that of gRPC.
+func WithConnectBackoff(config backoff.Config) ClientOption {
+	return func(opts *clientOptions) {
+		opts.backoff = &config
+	}
+}
+
+// WithKeepalive pings the registry as params set, so that broken connections
+// are detected while the registry is idle. Registries close the connections
+// of clients that ping more often than every 5 minutes, unless they are
+// configured to allow it.
+func WithKeepalive(params keepalive.ClientParameters) ClientOption {
+	return func(opts *clientOptions) {
+		opts.keepalive = &params
+	}
+}
+
+// WithRetries retries the calls that fail because the registry is unavailable,
+// with exponential backoff, so that calls are made at most maxAttempts times.
+// gRPC makes calls at most 5 times, whatever maxAttempts is.
+// Streaming calls are only retried until they receive their first message.
+func WithRetries(maxAttempts int) ClientOption {
+	return func(opts *clientOptions) {
+		opts.maxAttempts = maxAttempts
+	}
+}
+
+// WithDialOptions adds options to those that the connection is created with.
+func WithDialOptions(dialOptions ...grpc.DialOption) ClientOption {
+	return func(opts *clientOptions) {
+		opts.dialOptions = append(opts.dialOptions, dialOptions...)
+	}
+}
+
+// retryServiceConfig is the service config that retries the calls of the
+// registry API that fail with codes.Unavailable.
+const retryServiceConfig = `{"methodConfig": [{
+	"name": [{"service": "api.Registry"}],
+	"retryPolicy": {
+		"maxAttempts": %d,
+		"initialBackoff": "0.1s",
+		"maxBackoff": "2s",
+		"backoffMultiplier": 2,
+		"retryableStatusCodes": ["UNAVAILABLE"]
+	}
+}]}`
+
+func NewClient(address string, opts ...ClientOption) (*Client, error) {
+	var options clientOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// nolint:staticcheck
+	dialOptions := []grpc.DialOption{grpc.WithInsecure()}
+	if options.backoff != nil {
+		dialOptions = append(dialOptions, grpc.WithConnectParams(grpc.ConnectParams{Backoff: *options.backoff}))
+	}
+	if options.keepalive != nil {
+		dialOptions = append(dialOptions, grpc.WithKeepaliveParams(*options.keepalive))
+	}
+	if options.maxAttempts > 1 {
+		dialOptions = append(dialOptions, grpc.WithDefaultServiceConfig(fmt.Sprintf(retryServiceConfig, options.maxAttempts)))
+	}
+	dialOptions = append(dialOptions, options.dialOptions...)
+
 	// nolint:staticcheck
-	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	conn, err := grpc.Dial(address, dialOptions...)
 	if err != nil {
 		return nil, err
 	}
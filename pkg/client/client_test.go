@@ -3,12 +3,18 @@ package client
 import (
 	"context"
 	"errors"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 
 	"github.com/operator-framework/operator-registry/pkg/api"
 )
@@ -17,6 +23,9 @@ type RegistryClientStub struct {
 	ListBundlesClient api.Registry_ListBundlesClient
 	PackageName       string
 	Package           *api.Package
+	BundleRequest     *api.GetBundleRequest
+	Bundle            *api.Bundle
+	UpgradeGraph      *api.UpgradeGraph
 	Error             error
 }
 
@@ -34,7 +43,8 @@ func (s *RegistryClientStub) GetPackage(ctx context.Context, in *api.GetPackageR
 }
 
 func (s *RegistryClientStub) GetBundle(ctx context.Context, in *api.GetBundleRequest, opts ...grpc.CallOption) (*api.Bundle, error) {
-	return nil, nil
+	s.BundleRequest = in
+	return s.Bundle, nil
 }
 
 func (s *RegistryClientStub) GetBundleForChannel(ctx context.Context, in *api.GetBundleInChannelRequest, opts ...grpc.CallOption) (*api.Bundle, error) {
@@ -65,6 +75,26 @@ func (s *RegistryClientStub) ListBundles(ctx context.Context, in *api.ListBundle
 	return s.ListBundlesClient, s.Error
 }
 
+func (s *RegistryClientStub) GetCatalogInfo(ctx context.Context, in *api.GetCatalogInfoRequest, opts ...grpc.CallOption) (*api.CatalogInfo, error) {
+	return nil, nil
+}
+
+func (s *RegistryClientStub) GetUpgradeGraph(ctx context.Context, in *api.GetUpgradeGraphRequest, opts ...grpc.CallOption) (*api.UpgradeGraph, error) {
+	return s.UpgradeGraph, s.Error
+}
+
+func (s *RegistryClientStub) GetServerInfo(ctx context.Context, in *api.GetServerInfoRequest, opts ...grpc.CallOption) (*api.ServerInfo, error) {
+	return nil, nil
+}
+
+func (s *RegistryClientStub) ListPackageSummaries(ctx context.Context, in *api.ListPackageSummariesRequest, opts ...grpc.CallOption) (api.Registry_ListPackageSummariesClient, error) {
+	return nil, nil
+}
+
+func (s *RegistryClientStub) GetCatalogStats(ctx context.Context, in *api.GetCatalogStatsRequest, opts ...grpc.CallOption) (*api.CatalogStats, error) {
+	return nil, nil
+}
+
 func (s *RegistryClientStub) Check(ctx context.Context, in *grpc_health_v1.HealthCheckRequest, opts ...grpc.CallOption) (*grpc_health_v1.HealthCheckResponse, error) {
 	return nil, nil
 }
@@ -178,3 +208,126 @@ func TestGetPackage(t *testing.T) {
 		})
 	}
 }
+
+func TestGetBundleByVersion(t *testing.T) {
+	stub := &RegistryClientStub{Bundle: &api.Bundle{CsvName: "etcdoperator.v0.9.4"}}
+	c := Client{Registry: stub, Health: stub}
+
+	b, err := c.GetBundleByVersion(context.TODO(), "etcd", "stable", ">=0.9.0")
+	require.NoError(t, err)
+	require.Equal(t, stub.Bundle, b)
+	require.Equal(t, &api.GetBundleRequest{PkgName: "etcd", ChannelName: "stable", Version: ">=0.9.0"}, stub.BundleRequest)
+}
+
+func TestGetChannelHead(t *testing.T) {
+	stub := &RegistryClientStub{
+		Package: &api.Package{
+			Name: "etcd",
+			Channels: []*api.Channel{
+				{Name: "alpha", CsvName: "etcdoperator.v0.9.2"},
+				{Name: "stable", CsvName: "etcdoperator.v0.9.4"},
+			},
+		},
+		Bundle: &api.Bundle{CsvName: "etcdoperator.v0.9.4"},
+	}
+	c := Client{Registry: stub, Health: stub}
+
+	head, err := c.GetChannelHead(context.TODO(), "etcd", "stable")
+	require.NoError(t, err)
+	require.Equal(t, stub.Bundle, head)
+	require.Equal(t, &api.GetBundleRequest{PkgName: "etcd", ChannelName: "stable", CsvName: "etcdoperator.v0.9.4"}, stub.BundleRequest)
+
+	_, err = c.GetChannelHead(context.TODO(), "etcd", "beta")
+	require.EqualError(t, err, `package "etcd" has no channel "beta"`)
+}
+
+func TestWalkUpgradeGraph(t *testing.T) {
+	stub := &RegistryClientStub{
+		UpgradeGraph: &api.UpgradeGraph{
+			PackageName: "etcd",
+			Edges: []*api.UpgradeGraphEdge{
+				{ChannelName: "alpha", From: "etcd.v0.9.0", To: "etcd.v0.9.1", Type: "replaces"},
+				{ChannelName: "stable", From: "etcd.v0.9.0", To: "etcd.v0.9.1", Type: "replaces"},
+				{ChannelName: "stable", From: "etcd.v0.9.0", To: "etcd.v0.9.2", Type: "skips"},
+				{ChannelName: "stable", From: "etcd.v0.9.1", To: "etcd.v0.9.2", Type: "replaces"},
+				{ChannelName: "stable", From: "etcd.v0.9.2", To: "etcd.v0.9.3", Type: "replaces"},
+			},
+		},
+	}
+	c := Client{Registry: stub, Health: stub}
+
+	var walked []string
+	err := c.WalkUpgradeGraph(context.TODO(), "etcd", "stable", "etcd.v0.9.0", func(e *api.UpgradeGraphEdge) error {
+		walked = append(walked, e.GetFrom()+"->"+e.GetTo())
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"etcd.v0.9.0->etcd.v0.9.1",
+		"etcd.v0.9.0->etcd.v0.9.2",
+		"etcd.v0.9.1->etcd.v0.9.2",
+		"etcd.v0.9.2->etcd.v0.9.3",
+	}, walked)
+
+	stop := errors.New("stop")
+	walked = nil
+	err = c.WalkUpgradeGraph(context.TODO(), "etcd", "stable", "etcd.v0.9.1", func(e *api.UpgradeGraphEdge) error {
+		walked = append(walked, e.GetFrom()+"->"+e.GetTo())
+		return stop
+	})
+	require.Equal(t, stop, err)
+	require.Equal(t, []string{"etcd.v0.9.1->etcd.v0.9.2"}, walked)
+}
+
+type unavailableRegistryServer struct {
+	api.UnimplementedRegistryServer
+	failures int
+	calls    int
+}
+
+func (s *unavailableRegistryServer) GetPackage(_ context.Context, req *api.GetPackageRequest) (*api.Package, error) {
+	s.calls++
+	if s.calls <= s.failures {
+		return nil, status.Error(codes.Unavailable, "unavailable")
+	}
+	return &api.Package{Name: req.GetName()}, nil
+}
+
+func TestNewClientRetries(t *testing.T) {
+	for _, tt := range []struct {
+		Name      string
+		Options   []ClientOption
+		Calls     int
+		Assertion require.ErrorAssertionFunc
+	}{
+		{
+			Name:      "NoRetries",
+			Calls:     1,
+			Assertion: require.Error,
+		},
+		{
+			Name:      "Retries",
+			Options:   []ClientOption{WithRetries(3), WithConnectBackoff(backoff.DefaultConfig), WithKeepalive(keepalive.ClientParameters{Time: 10 * time.Minute})},
+			Calls:     3,
+			Assertion: require.NoError,
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			registry := &unavailableRegistryServer{failures: 2}
+			s := grpc.NewServer()
+			api.RegisterRegistryServer(s, registry)
+			go func() { _ = s.Serve(lis) }()
+			defer s.Stop()
+
+			c, err := NewClient(lis.Addr().String(), tt.Options...)
+			require.NoError(t, err)
+			defer c.Close()
+
+			_, err = c.GetPackage(context.TODO(), "etcd")
+			tt.Assertion(t, err)
+			require.Equal(t, tt.Calls, registry.calls)
+		})
+	}
+}
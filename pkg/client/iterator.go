@@ -0,0 +1,52 @@
+package client
+
+import (
+	"errors"
+	"io"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+)
+
+// Stream is a stream of messages sent by the registry.
+type Stream[T any] interface {
+	Recv() (T, error)
+}
+
+// Iterator iterates over the messages of a stream.
+type Iterator[T any] struct {
+	stream Stream[T]
+	error  error
+}
+
+func NewIterator[T any](stream Stream[T]) *Iterator[T] {
+	return &Iterator[T]{stream: stream}
+}
+
+// Next returns the next message of the stream, or the zero value of T when
+// the stream has ended or failed, in which case Error returns the failure.
+func (it *Iterator[T]) Next() T {
+	var zero T
+	if it.error != nil {
+		return zero
+	}
+	next, err := it.stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return zero
+	}
+	if err != nil {
+		it.error = err
+	}
+	return next
+}
+
+func (it *Iterator[T]) Error() error {
+	return it.error
+}
+
+type BundleStream = Stream[*api.Bundle]
+
+type BundleIterator = Iterator[*api.Bundle]
+
+func NewBundleIterator(stream BundleStream) *BundleIterator {
+	return NewIterator(stream)
+}
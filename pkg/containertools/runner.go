@@ -100,12 +100,18 @@ func (r *ContainerCommandRunner) Pull(image string) error {
 
 // Build takes a dockerfile and a tag and builds a container image
 func (r *ContainerCommandRunner) Build(dockerfile, tag string) error {
+	return r.BuildDir(".", dockerfile, tag)
+}
+
+// BuildDir builds a container image from a dockerfile and a tag, like Build,
+// with contextDir as the build context instead of the working directory.
+func (r *ContainerCommandRunner) BuildDir(contextDir, dockerfile, tag string) error {
 	o := DefaultBuildOptions()
 	if tag != "" {
 		o.AddTag(tag)
 	}
 	o.SetDockerfile(dockerfile)
-	o.SetContext(".")
+	o.SetContext(contextDir)
 	command, err := r.containerTool.CommandFactory().BuildCommand(o)
 	if err != nil {
 		return fmt.Errorf("unable to perform build: %v", err)
@@ -123,6 +129,25 @@ func (r *ContainerCommandRunner) Build(dockerfile, tag string) error {
 	return nil
 }
 
+// Push takes a local container image and runs the push command to upload it
+// to its container registry
+func (r *ContainerCommandRunner) Push(image string) error {
+	args := r.argsForCmd("push", image)
+
+	// nolint:gosec
+	command := exec.Command(r.containerTool.String(), args...)
+
+	r.logger.Infof("running %s", command.String())
+
+	out, err := command.CombinedOutput()
+	if err != nil {
+		r.logger.Error(string(out))
+		return fmt.Errorf("error pushing image: %s. %v", string(out), err)
+	}
+
+	return nil
+}
+
 // Unpack copies a directory from a local container image to a directory in the local filesystem.
 func (r *ContainerCommandRunner) Unpack(image, src, dst string) error {
 	args := r.argsForCmd("create", image, "")
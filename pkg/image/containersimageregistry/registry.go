@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/containerd/containerd/archive"
 	"github.com/containers/common/pkg/auth"
@@ -29,8 +30,14 @@ var _ orimage.Registry = (*Registry)(nil)
 type Registry struct {
 	sourceCtx *types.SystemContext
 	cache     *cacheConfig
+	offline   bool
 }
 
+// OCILayoutRefPrefix is the prefix of the references to images of local OCI
+// layouts, as oci:<path>[:<tag>], e.g. as written by "skopeo copy". These
+// images are read in place, rather than copied to the cache.
+const OCILayoutRefPrefix = "oci:"
+
 var DefaultSystemContext = &types.SystemContext{OSChoice: "linux"}
 
 func New(sourceCtx *types.SystemContext, opts ...Option) (orimage.Registry, error) {
@@ -115,6 +122,33 @@ func WithTemporaryImageCache() Option {
 	}
 }
 
+// WithImageCacheDir stores pulled images in dir, which is preserved by
+// Destroy, instead of a temporary directory. Images are stored in an OCI
+// layout, whose blobs are addressed by digest, so that the layers that images
+// share are stored once, and images that are pulled again, e.g. by later opm
+// runs in CI, only download the blobs that are not in the cache yet. Images
+// that are pulled by digest and are in the cache are not pulled again at all.
+func WithImageCacheDir(dir string) Option {
+	return func(r *Registry) error {
+		if dir == "" {
+			return fmt.Errorf("image cache directory must not be empty")
+		}
+		r.cache = newCacheConfig(dir, true)
+		return nil
+	}
+}
+
+// WithOffline forbids Pull from accessing the network: images are only read
+// from the image cache, e.g. one set with WithImageCacheDir and populated by
+// earlier runs, or from OCI layouts, and Pull fails with an error wrapping
+// orimage.ErrNotCached for the images that aren't there.
+func WithOffline() Option {
+	return func(r *Registry) error {
+		r.offline = true
+		return nil
+	}
+}
+
 func WithInsecureSkipTLSVerify(insecureSkipTLSVerify bool) Option {
 	return func(r *Registry) error {
 		r.sourceCtx.DockerDaemonInsecureSkipTLSVerify = insecureSkipTLSVerify
@@ -125,6 +159,17 @@ func WithInsecureSkipTLSVerify(insecureSkipTLSVerify bool) Option {
 }
 
 func (r *Registry) Pull(ctx context.Context, ref orimage.Reference) error {
+	if strings.HasPrefix(ref.String(), OCILayoutRefPrefix) {
+		ociLayoutRef, err := r.layoutReference(ref)
+		if err != nil {
+			return err
+		}
+		if !r.isCached(ctx, ociLayoutRef) {
+			return fmt.Errorf("image %q not found in OCI layout", ref)
+		}
+		return nil
+	}
+
 	namedRef, err := reference.ParseNamed(ref.String())
 	if err != nil {
 		return err
@@ -137,11 +182,23 @@ func (r *Registry) Pull(ctx context.Context, ref orimage.Reference) error {
 	if err := os.MkdirAll(r.cache.ociLayoutDir(), 0700); err != nil {
 		return err
 	}
-	ociLayoutRef, err := layout.NewReference(r.cache.ociLayoutDir(), ref.String())
+	ociLayoutRef, err := r.layoutReference(ref)
 	if err != nil {
 		return err
 	}
 
+	// The content of a digest reference never changes, so it needs no
+	// registry round trip once it is cached. Tags are resolved again, to
+	// find out whether they moved, but only the new blobs are pulled,
+	// unless the registry is offline, in which case they are used as cached.
+	_, digested := namedRef.(reference.Digested)
+	if (digested || r.offline) && r.isCached(ctx, ociLayoutRef) {
+		return nil
+	}
+	if r.offline {
+		return fmt.Errorf("image %q: %w", ref, orimage.ErrNotCached)
+	}
+
 	policy, err := signature.DefaultPolicy(r.sourceCtx)
 	if err != nil {
 		return err
@@ -174,8 +231,30 @@ func (r *Registry) Pull(ctx context.Context, ref orimage.Reference) error {
 	return nil
 }
 
+// layoutReference returns the reference to the image of ref in the OCI
+// layout that it is read from: the layout of the reference itself for
+// references with OCILayoutRefPrefix, or the layout of the cache.
+func (r *Registry) layoutReference(ref orimage.Reference) (types.ImageReference, error) {
+	if s, ok := strings.CutPrefix(ref.String(), OCILayoutRefPrefix); ok {
+		return layout.ParseReference(s)
+	}
+	return layout.NewReference(r.cache.ociLayoutDir(), ref.String())
+}
+
+// isCached returns whether the image of ref and its manifest are in its OCI
+// layout.
+func (r *Registry) isCached(ctx context.Context, ref types.ImageReference) bool {
+	src, err := ref.NewImageSource(ctx, r.cache.getSystemContext())
+	if err != nil {
+		return false
+	}
+	defer src.Close()
+	_, _, err = src.GetManifest(ctx, nil)
+	return err == nil
+}
+
 func (r *Registry) Unpack(ctx context.Context, ref orimage.Reference, unpackDir string) error {
-	ociLayoutRef, err := layout.NewReference(r.cache.ociLayoutDir(), ref.String())
+	ociLayoutRef, err := r.layoutReference(ref)
 	if err != nil {
 		return fmt.Errorf("could not create oci layout reference: %w", err)
 	}
@@ -231,7 +310,7 @@ func (r *Registry) Unpack(ctx context.Context, ref orimage.Reference, unpackDir
 }
 
 func (r *Registry) Labels(ctx context.Context, ref orimage.Reference) (map[string]string, error) {
-	ociLayoutRef, err := layout.NewReference(r.cache.ociLayoutDir(), ref.String())
+	ociLayoutRef, err := r.layoutReference(ref)
 	if err != nil {
 		return nil, fmt.Errorf("could not create oci layout reference: %w", err)
 	}
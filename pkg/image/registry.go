@@ -2,8 +2,13 @@ package image
 
 import (
 	"context"
+	"errors"
 )
 
+// ErrNotCached is returned, wrapped, by Pull when a registry that may not
+// access the network doesn't have the image stored locally.
+var ErrNotCached = errors.New("not in the local image cache")
+
 // Registry knows how to Pull and Unpack Operator Bundle images to the filesystem.
 // Note: In the future, Registry will know how to Build and Push Operator Bundle images as well.
 type Registry interface {
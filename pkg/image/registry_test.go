@@ -120,6 +120,95 @@ func TestRegistries(t *testing.T) {
 	}
 }
 
+func TestImageCacheDir(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dockerServer := libimage.RunDockerRegistry(ctx, "testdata/golden")
+	caDir := caDirForCert(t, dockerServer.Certificate())
+	defer os.RemoveAll(caDir)
+	sourceCtx := &types.SystemContext{
+		OCICertPath:              caDir,
+		DockerCertPath:           caDir,
+		DockerPerHostCertDirPath: caDir,
+		SignaturePolicyPath:      createSignaturePolicyFile(t),
+	}
+	url, err := url.Parse(dockerServer.URL)
+	require.NoError(t, err)
+	byDigest := image.SimpleReference(url.Host + "/olmtest/kiali@sha256:a1bec450c104ceddbb25b252275eb59f1f1e6ca68e0ced76462042f72f7057d8")
+	byTag := image.SimpleReference(url.Host + "/olmtest/kiali:1.4.2")
+
+	cacheDir := t.TempDir()
+	r, err := containersimageregistry.New(sourceCtx, containersimageregistry.WithImageCacheDir(cacheDir))
+	require.NoError(t, err)
+	require.NoError(t, r.Pull(ctx, byDigest))
+	require.NoError(t, r.Destroy())
+	require.DirExists(t, cacheDir)
+
+	// Once the registry is gone, images that are pulled by digest are still
+	// read from the cache, but tags can't be resolved anymore.
+	dockerServer.Close()
+	r, err = containersimageregistry.New(sourceCtx, containersimageregistry.WithImageCacheDir(cacheDir))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, r.Destroy()) }()
+	require.NoError(t, r.Pull(ctx, byDigest))
+	require.Error(t, r.Pull(ctx, byTag))
+
+	dir := filepath.Join(t.TempDir(), "kiali-unpacked")
+	require.NoError(t, r.Unpack(ctx, byDigest, dir))
+	require.Equal(t, dirChecksum(t, "testdata/golden/bundles/kiali"), dirChecksum(t, dir))
+
+	_, err = containersimageregistry.New(sourceCtx, containersimageregistry.WithImageCacheDir(""))
+	require.Error(t, err)
+}
+
+func TestOffline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dockerServer := libimage.RunDockerRegistry(ctx, "testdata/golden")
+	caDir := caDirForCert(t, dockerServer.Certificate())
+	defer os.RemoveAll(caDir)
+	sourceCtx := &types.SystemContext{
+		OCICertPath:              caDir,
+		DockerCertPath:           caDir,
+		DockerPerHostCertDirPath: caDir,
+		SignaturePolicyPath:      createSignaturePolicyFile(t),
+	}
+	url, err := url.Parse(dockerServer.URL)
+	require.NoError(t, err)
+	byTag := image.SimpleReference(url.Host + "/olmtest/kiali:1.4.2")
+	expectedChecksum := dirChecksum(t, "testdata/golden/bundles/kiali")
+
+	cacheDir := t.TempDir()
+	r, err := containersimageregistry.New(sourceCtx, containersimageregistry.WithImageCacheDir(cacheDir))
+	require.NoError(t, err)
+	require.NoError(t, r.Pull(ctx, byTag))
+	require.NoError(t, r.Destroy())
+	dockerServer.Close()
+
+	r, err = containersimageregistry.New(sourceCtx, containersimageregistry.WithImageCacheDir(cacheDir), containersimageregistry.WithOffline())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, r.Destroy()) }()
+
+	// Offline, tags are not resolved again, and images that aren't cached
+	// are not pulled.
+	require.NoError(t, r.Pull(ctx, byTag))
+	require.ErrorIs(t, r.Pull(ctx, image.SimpleReference(url.Host+"/olmtest/kiali:1.4.3")), image.ErrNotCached)
+
+	// The image cache is an OCI layout, so its images are read in place with
+	// OCI layout references too.
+	byLayout := image.SimpleReference(containersimageregistry.OCILayoutRefPrefix + filepath.Join(cacheDir, "oci-layout") + ":" + byTag.String())
+	require.NoError(t, r.Pull(ctx, byLayout))
+	labels, err := r.Labels(ctx, byLayout)
+	require.NoError(t, err)
+	require.Equal(t, "kiali", labels["operators.operatorframework.io.bundle.package.v1"])
+	dir := filepath.Join(t.TempDir(), "kiali-unpacked")
+	require.NoError(t, r.Unpack(ctx, byLayout, dir))
+	require.Equal(t, expectedChecksum, dirChecksum(t, dir))
+	require.Error(t, r.Pull(ctx, image.SimpleReference(containersimageregistry.OCILayoutRefPrefix+filepath.Join(cacheDir, "oci-layout")+":missing")))
+}
+
 type httpError struct {
 	statusCode int
 	error      error
@@ -0,0 +1,60 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"sigs.k8s.io/yaml"
+)
+
+const chartfileName = "Chart.yaml"
+
+// ChartArchiveMetadata reads the metadata of a Helm chart archive, as created
+// by `helm package`: the Chart.yaml file of the chart's directory at the root
+// of a gzipped tarball. The chart must have a name and a semver version.
+func ChartArchiveMetadata(r io.Reader) (*Metadata, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("chart archive is not gzipped: %v", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("chart archive has no %s file", chartfileName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read chart archive: %v", err)
+		}
+		// The Chart.yaml files of subcharts, in the charts directory of the
+		// chart, are skipped.
+		dir, file := path.Split(path.Clean(hdr.Name))
+		if file != chartfileName || strings.Count(dir, "/") != 1 {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %v", hdr.Name, err)
+		}
+		var metadata Metadata
+		if err := yaml.Unmarshal(data, &metadata); err != nil {
+			return nil, fmt.Errorf("parse %s: %v", hdr.Name, err)
+		}
+		if metadata.Name == "" {
+			return nil, fmt.Errorf("invalid chart (%s): name must not be empty", chartfileName)
+		}
+		if _, err := semver.Parse(metadata.Version); err != nil {
+			return nil, fmt.Errorf("invalid chart (%s): version %q is not semver: %v", chartfileName, metadata.Version, err)
+		}
+		return &metadata, nil
+	}
+}
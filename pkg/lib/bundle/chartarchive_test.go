@@ -0,0 +1,81 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func chartArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data))}))
+		_, err := tw.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestChartArchiveMetadata(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		archive   []byte
+		expected  *Metadata
+		expectErr string
+	}{
+		{
+			name: "Success",
+			archive: chartArchive(t, map[string]string{
+				"foo/charts/bar/Chart.yaml": "apiVersion: v2\nname: bar\nversion: 0.1.0\n",
+				"foo/Chart.yaml":            "apiVersion: v2\nname: foo\nversion: 1.2.3\nappVersion: \"4.5\"\nkubeVersion: \">=1.25.0-0\"\n",
+				"foo/values.yaml":           "replicas: 1\n",
+			}),
+			expected: &Metadata{APIVersion: "v2", Name: "foo", Version: "1.2.3", AppVersion: "4.5", KubeVersion: ">=1.25.0-0"},
+		},
+		{
+			name:      "NotGzipped",
+			archive:   []byte("apiVersion: v2\nname: foo\nversion: 1.2.3\n"),
+			expectErr: "chart archive is not gzipped",
+		},
+		{
+			name: "NoChartfile",
+			archive: chartArchive(t, map[string]string{
+				"foo/values.yaml":           "replicas: 1\n",
+				"foo/charts/bar/Chart.yaml": "apiVersion: v2\nname: bar\nversion: 0.1.0\n",
+			}),
+			expectErr: "chart archive has no Chart.yaml file",
+		},
+		{
+			name: "NoName",
+			archive: chartArchive(t, map[string]string{
+				"foo/Chart.yaml": "apiVersion: v2\nversion: 1.2.3\n",
+			}),
+			expectErr: "invalid chart (Chart.yaml): name must not be empty",
+		},
+		{
+			name: "InvalidVersion",
+			archive: chartArchive(t, map[string]string{
+				"foo/Chart.yaml": "apiVersion: v2\nname: foo\nversion: latest\n",
+			}),
+			expectErr: `invalid chart (Chart.yaml): version "latest" is not semver`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := ChartArchiveMetadata(bytes.NewReader(tt.archive))
+			if tt.expectErr != "" {
+				require.ErrorContains(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
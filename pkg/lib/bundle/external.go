@@ -0,0 +1,159 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/api/pkg/validation/errors"
+)
+
+// ExternalValidator validates bundles with checks that are not built into opm,
+// such as those of a catalog pipeline.
+type ExternalValidator interface {
+	// Name identifies the validator in validation reports.
+	Name() string
+	// Validate validates the bundle whose manifests and metadata directories
+	// are in directory. Issues found in the bundle are reported as results,
+	// while the returned error reports that the validator itself failed.
+	Validate(ctx context.Context, directory string) ([]errors.ManifestResult, error)
+}
+
+// ValidateExternal validates the bundle in directory with validators, and
+// aggregates the errors they report, or their failures, into a
+// ValidationError. The warnings they report are logged.
+func ValidateExternal(ctx context.Context, logger *logrus.Entry, directory string, validators ...ExternalValidator) error {
+	var validationErrors []error
+	for _, v := range validators {
+		logger.Debugf("Performing external validation with %s", v.Name())
+		results, err := v.Validate(ctx, directory)
+		if err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("external validator %s failed: %v", v.Name(), err))
+			continue
+		}
+		for _, result := range results {
+			for _, e := range result.Errors {
+				validationErrors = append(validationErrors, fmt.Errorf("%s: %w", v.Name(), e))
+			}
+			for _, w := range result.Warnings {
+				logger.Warnf("%s: %s", v.Name(), w.Error())
+			}
+		}
+	}
+	if len(validationErrors) > 0 {
+		return NewValidationError(validationErrors)
+	}
+	return nil
+}
+
+// ExternalValidationProtocolVersion is the version of the protocol between opm
+// and exec validators, sent in each ExternalValidationRequest.
+const ExternalValidationProtocolVersion = "v1alpha1"
+
+// ExternalValidationRequest is written as JSON to the standard input of exec
+// validators.
+type ExternalValidationRequest struct {
+	ProtocolVersion string `json:"protocolVersion"`
+	// BundleDir is the directory of the unpacked bundle, which contains its
+	// manifests and metadata directories.
+	BundleDir    string `json:"bundleDir"`
+	ManifestsDir string `json:"manifestsDir"`
+	MetadataDir  string `json:"metadataDir"`
+}
+
+// ExternalValidationResponse is read as JSON from the standard output of exec
+// validators.
+type ExternalValidationResponse struct {
+	Results []ExternalValidationResult `json:"results"`
+}
+
+// ExternalValidationResult reports the issues found in a bundle object, as
+// errors.ManifestResult does.
+type ExternalValidationResult struct {
+	// Name identifies the object, e.g. the name of a CSV.
+	Name     string                    `json:"name"`
+	Errors   []ExternalValidationIssue `json:"errors,omitempty"`
+	Warnings []ExternalValidationIssue `json:"warnings,omitempty"`
+}
+
+// ExternalValidationIssue is an error or a warning of an
+// ExternalValidationResult, as errors.Error is.
+type ExternalValidationIssue struct {
+	// Type is the kind of issue, e.g. "FieldValueInvalid", and defaults to
+	// "ValidationFailed".
+	Type     string      `json:"type,omitempty"`
+	Field    string      `json:"field,omitempty"`
+	BadValue interface{} `json:"badValue,omitempty"`
+	Detail   string      `json:"detail"`
+}
+
+// ExecValidator is an ExternalValidator that runs an executable, which reads
+// an ExternalValidationRequest from its standard input and writes an
+// ExternalValidationResponse to its standard output. The executable exits with
+// a non-zero status only if it fails to validate the bundle, and reports the
+// issues it finds in its response.
+type ExecValidator struct {
+	Path string
+	Args []string
+}
+
+func (v ExecValidator) Name() string {
+	return filepath.Base(v.Path)
+}
+
+func (v ExecValidator) Validate(ctx context.Context, directory string) ([]errors.ManifestResult, error) {
+	req, err := json.Marshal(ExternalValidationRequest{
+		ProtocolVersion: ExternalValidationProtocolVersion,
+		BundleDir:       directory,
+		ManifestsDir:    filepath.Join(directory, ManifestsDir),
+		MetadataDir:     filepath.Join(directory, MetadataDir),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, v.Path, v.Args...)
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%v: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	var resp ExternalValidationResponse
+	dec := json.NewDecoder(&stdout)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&resp); err != nil {
+		return nil, fmt.Errorf("decode response: %v", err)
+	}
+	results := make([]errors.ManifestResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		result := errors.ManifestResult{Name: r.Name}
+		for _, e := range r.Errors {
+			result.Add(e.toError(errors.LevelError))
+		}
+		for _, w := range r.Warnings {
+			result.Add(w.toError(errors.LevelWarn))
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (i ExternalValidationIssue) toError(level errors.Level) errors.Error {
+	errType := errors.ErrorType(i.Type)
+	if errType == "" {
+		errType = errors.ErrorFailedValidation
+	}
+	return errors.Error{Type: errType, Level: level, Field: i.Field, BadValue: i.BadValue, Detail: i.Detail}
+}
@@ -0,0 +1,119 @@
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/api/pkg/validation/errors"
+)
+
+// writeValidator writes an executable shell script that runs script after
+// saving its standard input to the request file of dir.
+func writeValidator(t *testing.T, dir, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, "validator")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\ncat > "+filepath.Join(dir, "request")+"\n"+script+"\n"), 0755))
+	return path
+}
+
+func TestExecValidator(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("exec validators are tested with shell scripts")
+	}
+
+	for _, tt := range []struct {
+		name    string
+		script  string
+		results []errors.ManifestResult
+		err     string
+	}{
+		{
+			name:    "NoIssues",
+			script:  `echo '{"results": []}'`,
+			results: []errors.ManifestResult{},
+		},
+		{
+			name:   "Issues",
+			script: `echo '{"results": [{"name": "etcdoperator.v0.9.4", "errors": [{"type": "FieldNotFound", "field": "spec.icon", "detail": "icon is required"}], "warnings": [{"detail": "description is short"}]}]}'`,
+			results: []errors.ManifestResult{{
+				Name:     "etcdoperator.v0.9.4",
+				Errors:   []errors.Error{{Type: errors.ErrorFieldMissing, Level: errors.LevelError, Field: "spec.icon", Detail: "icon is required"}},
+				Warnings: []errors.Error{{Type: errors.ErrorFailedValidation, Level: errors.LevelWarn, Detail: "description is short"}},
+			}},
+		},
+		{
+			name:   "Failure",
+			script: "echo 'no such bundle' >&2; exit 3",
+			err:    "exit status 3: no such bundle",
+		},
+		{
+			name:   "InvalidResponse",
+			script: `echo '{"issues": []}'`,
+			err:    `decode response: json: unknown field "issues"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			validator := ExecValidator{Path: writeValidator(t, dir, tt.script)}
+			require.Equal(t, "validator", validator.Name())
+
+			results, err := validator.Validate(context.Background(), "/bundle")
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.results, results)
+
+			data, err := os.ReadFile(filepath.Join(dir, "request"))
+			require.NoError(t, err)
+			var req ExternalValidationRequest
+			require.NoError(t, json.Unmarshal(data, &req))
+			require.Equal(t, ExternalValidationRequest{
+				ProtocolVersion: ExternalValidationProtocolVersion,
+				BundleDir:       "/bundle",
+				ManifestsDir:    "/bundle/manifests",
+				MetadataDir:     "/bundle/metadata",
+			}, req)
+		})
+	}
+}
+
+type stubValidator struct {
+	name    string
+	results []errors.ManifestResult
+	err     error
+}
+
+func (v stubValidator) Name() string { return v.name }
+
+func (v stubValidator) Validate(context.Context, string) ([]errors.ManifestResult, error) {
+	return v.results, v.err
+}
+
+func TestValidateExternal(t *testing.T) {
+	logger := logrus.NewEntry(logrus.New())
+
+	require.NoError(t, ValidateExternal(context.Background(), logger, "/bundle"))
+	require.NoError(t, ValidateExternal(context.Background(), logger, "/bundle", stubValidator{
+		name:    "warns",
+		results: []errors.ManifestResult{{Warnings: []errors.Error{errors.WarnFailedValidation("description is short", nil)}}},
+	}))
+
+	err := ValidateExternal(context.Background(), logger, "/bundle",
+		stubValidator{name: "fails", err: os.ErrNotExist},
+		stubValidator{name: "reports", results: []errors.ManifestResult{{Errors: []errors.Error{errors.ErrFailedValidation("icon is required", nil)}}}},
+	)
+	var verr ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Len(t, verr.Errors, 2)
+	require.EqualError(t, verr.Errors[0], "external validator fails failed: file does not exist")
+	require.EqualError(t, verr.Errors[1], "reports: Error: : icon is required")
+}
@@ -18,6 +18,7 @@ const (
 	DefaultPermission   = 0644
 	RegistryV1Type      = "registry+v1"
 	PlainType           = "plain"
+	PlainV0Type         = "plain+v0"
 	HelmType            = "helm"
 	AnnotationsFile     = "annotations.yaml"
 	DockerFile          = "bundle.Dockerfile"
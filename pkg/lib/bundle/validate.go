@@ -196,7 +196,8 @@ func validateAnnotations(mediaType string, fileAnnotations *AnnotationMetadata)
 
 		switch label {
 		case MediatypeLabel:
-			if item != val {
+			// Plain bundles may also declare the versioned plain+v0 media type.
+			if item != val && (item != PlainType || val != PlainV0Type) {
 				// nolint:stylecheck
 				aErr := fmt.Errorf("Expecting annotation %q to have value %q instead of %q", label, item, val)
 				validationErrors = append(validationErrors, aErr)
@@ -403,10 +404,8 @@ func (i imageValidator) ValidateBundleContent(manifestDir string) error {
 	if _, ok := optionalValidators[validateOperatorHubKey]; ok {
 		i.logger.Debug("Performing operatorhub validation")
 		bundle := &manifests.Bundle{Name: csvName, CSV: csv}
-		// nolint:staticcheck
-		results := v.OperatorHubValidator.Validate(bundle)
-		if len(results) > 0 {
-			for _, err := range results[0].Errors {
+		for _, result := range validation.OperatorHubValidators.Validate(bundle) {
+			for _, err := range result.Errors {
 				validationErrors = append(validationErrors, err)
 			}
 		}
@@ -101,6 +101,22 @@ func TestValidateBundle_InvalidRegistryVersion(t *testing.T) {
 	require.Len(t, validationError.Errors, 1)
 }
 
+func TestValidateAnnotations_PlainMediaType(t *testing.T) {
+	annotations := func(mediaType string) *AnnotationMetadata {
+		return &AnnotationMetadata{Annotations: map[string]string{
+			MediatypeLabel: mediaType,
+			ManifestsLabel: ManifestsDir,
+			MetadataLabel:  MetadataDir,
+			PackageLabel:   "foo",
+			ChannelsLabel:  "stable",
+		}}
+	}
+
+	require.Empty(t, validateAnnotations(PlainType, annotations(PlainType)))
+	require.Empty(t, validateAnnotations(PlainType, annotations(PlainV0Type)))
+	require.Len(t, validateAnnotations(RegistryV1Type, annotations(PlainV0Type)), 1)
+}
+
 func TestValidateBundleContent(t *testing.T) {
 	logger := logrus.NewEntry(logrus.New())
 
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/types"
+	"golang.org/x/sync/errgroup"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+const defaultImageCheckConcurrency = 10
+
+// WithImageCheck enables checking that every bundle image and related image
+// of the catalog can be pulled. The image manifests are queried with HEAD
+// requests, up to concurrency at a time, using the registry configuration and
+// credentials of sysCtx. References that have both a tag and a digest are
+// also reported if the tag no longer resolves to the digest.
+func WithImageCheck(sysCtx *types.SystemContext, concurrency int) ValidateOption {
+	return func(o *ValidateOptions) {
+		if concurrency <= 0 {
+			concurrency = defaultImageCheckConcurrency
+		}
+		o.CheckImages = true
+		o.ImageCheckSystemContext = sysCtx
+		o.ImageCheckConcurrency = concurrency
+	}
+}
+
+// checkImages returns an error for every image referenced by cfg that can't
+// be pulled or whose tag moved.
+func checkImages(ctx context.Context, cfg declcfg.DeclarativeConfig, sysCtx *types.SystemContext, concurrency int) error {
+	bundlesByImage := map[string]sets.Set[string]{}
+	addImage := func(img, bundle string) {
+		if img == "" {
+			return
+		}
+		if _, ok := bundlesByImage[img]; !ok {
+			bundlesByImage[img] = sets.New[string]()
+		}
+		bundlesByImage[img].Insert(bundle)
+	}
+	for _, b := range cfg.Bundles {
+		addImage(b.Image, b.Name)
+		for _, ri := range b.RelatedImages {
+			addImage(ri.Image, b.Name)
+		}
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.SetLimit(concurrency)
+	for img, bundles := range bundlesByImage {
+		eg.Go(func() error {
+			if err := checkImage(ctx, sysCtx, img); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, fmt.Errorf("image %q referenced by bundles [%s]: %v", img, strings.Join(sets.List(bundles), ", "), err))
+			}
+			return nil
+		})
+	}
+	_ = eg.Wait()
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+	return utilerrors.NewAggregate(errs)
+}
+
+// checkImage queries the manifest of img, and if img has both a tag and a
+// digest, checks that the tag still resolves to the digest.
+func checkImage(ctx context.Context, sysCtx *types.SystemContext, img string) error {
+	named, err := reference.ParseNormalizedNamed(img)
+	if err != nil {
+		return fmt.Errorf("invalid reference: %v", err)
+	}
+	tagged, isTagged := named.(reference.Tagged)
+	canonical, isCanonical := named.(reference.Canonical)
+
+	var refs []reference.Named
+	switch {
+	case isTagged && isCanonical:
+		// Docker references can't have both a tag and a digest, so the
+		// digest and the tag are queried separately.
+		byTag, err := reference.WithTag(reference.TrimNamed(named), tagged.Tag())
+		if err != nil {
+			return err
+		}
+		byDigest, err := reference.WithDigest(reference.TrimNamed(named), canonical.Digest())
+		if err != nil {
+			return err
+		}
+		refs = []reference.Named{byDigest, byTag}
+	default:
+		refs = []reference.Named{reference.TagNameOnly(named)}
+	}
+
+	for _, r := range refs {
+		ref, err := docker.NewReference(r)
+		if err != nil {
+			return err
+		}
+		dgst, err := docker.GetDigest(ctx, sysCtx, ref)
+		if err != nil {
+			return fmt.Errorf("unreachable: %v", err)
+		}
+		if t, ok := r.(reference.Tagged); ok && isCanonical && dgst != canonical.Digest() {
+			return fmt.Errorf("tag %q moved: it resolves to %s", t.Tag(), dgst)
+		}
+	}
+	return nil
+}
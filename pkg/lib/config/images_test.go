@@ -0,0 +1,60 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/containers/image/v5/types"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/operator-framework/operator-registry/internal/testutil/image"
+)
+
+func TestValidateImageCheck(t *testing.T) {
+	ctx := context.Background()
+	server := image.RunDockerRegistry(ctx, "")
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	// Push the images of foo.v0.1.0 to the registry.
+	store := memory.New()
+	repo, err := remote.NewRepository(host + "/example/foo")
+	require.NoError(t, err)
+	repo.Client = &auth.Client{Client: server.Client()}
+	pushed := map[string]string{}
+	for _, tag := range []string{"v0.1.0", "v0.1.1"} {
+		desc, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, "application/vnd.example."+tag, oras.PackManifestOptions{})
+		require.NoError(t, err)
+		require.NoError(t, store.Tag(ctx, desc, tag))
+		_, err = oras.Copy(ctx, store, tag, repo, tag, oras.DefaultCopyOptions)
+		require.NoError(t, err)
+		pushed[tag] = desc.Digest.String()
+	}
+
+	catalog := strings.ReplaceAll(validPackage, "quay.io", host)
+	catalog = strings.Replace(catalog, "properties:", `relatedImages:
+  - name: operator
+    image: `+host+`/example/foo:v0.1.0@`+pushed["v0.1.1"]+`
+  - name: pinned
+    image: `+host+`/example/foo@`+pushed["v0.1.1"]+`
+properties:`, 1)
+	fsys := fstest.MapFS{"foo.yaml": &fstest.MapFile{Data: []byte(catalog)}}
+
+	sysCtx := &types.SystemContext{DockerInsecureSkipTLSVerify: types.OptionalBoolTrue}
+	err = Validate(ctx, fsys, WithImageCheck(sysCtx, 2))
+	require.Error(t, err)
+	msg := err.Error()
+	require.Contains(t, msg, `image "`+host+`/example/foo:v0.2.0" referenced by bundles [foo.v0.2.0]: unreachable`)
+	require.Contains(t, msg, `image "`+host+`/example/foo:v0.1.0@`+pushed["v0.1.1"]+`" referenced by bundles [foo.v0.1.0]: tag "v0.1.0" moved: it resolves to `+pushed["v0.1.0"])
+	require.NotContains(t, msg, `image "`+host+`/example/foo:v0.1.0" referenced`)
+	require.NotContains(t, msg, `image "`+host+`/example/foo@`)
+
+	// Without the image check, the catalog is valid.
+	require.NoError(t, Validate(ctx, fsys))
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+// WithStrict makes validation fail on blobs of unknown schemas, properties of
+// unknown types, and duplicate properties, which are otherwise passed through
+// as is. Schemas registered with declcfg.RegisterSchemaExtension and property
+// types registered with property.AddToScheme are known.
+func WithStrict() ValidateOption {
+	return func(o *ValidateOptions) {
+		o.Strict = true
+	}
+}
+
+// validateStrict returns an error for every blob of cfg with an unknown
+// schema, and for every unknown or duplicate property.
+func validateStrict(cfg declcfg.DeclarativeConfig) error {
+	knownSchemas := sets.New(
+		declcfg.SchemaPackage,
+		declcfg.SchemaChannel,
+		declcfg.SchemaBundle,
+		declcfg.SchemaDeprecation,
+		declcfg.SchemaIcon,
+		declcfg.SchemaCatalog,
+	)
+	knownSchemas.Insert(declcfg.RegisteredSchemaExtensions()...)
+	knownTypes := sets.New(property.RegisteredTypes()...)
+
+	var errs []error
+	for _, o := range cfg.Others {
+		errs = append(errs, fmt.Errorf("%s: unknown schema %q%s", describeMeta(o), o.Schema, suggestion(o.Schema, knownSchemas)))
+	}
+	checkProperties := func(object string, props []property.Property) {
+		seen := sets.New[string]()
+		for _, p := range props {
+			if !knownTypes.Has(p.Type) {
+				errs = append(errs, fmt.Errorf("%s: unknown property type %q%s", object, p.Type, suggestion(p.Type, knownTypes)))
+			}
+			var value bytes.Buffer
+			if err := json.Compact(&value, p.Value); err != nil {
+				value.Reset()
+				value.Write(p.Value)
+			}
+			key := p.Type + "\x00" + value.String()
+			if seen.Has(key) {
+				errs = append(errs, fmt.Errorf("%s: duplicate %q property with value %s", object, p.Type, value.String()))
+			}
+			seen.Insert(key)
+		}
+	}
+	for _, p := range cfg.Packages {
+		checkProperties(fmt.Sprintf("package %q", p.Name), p.Properties)
+	}
+	for _, c := range cfg.Channels {
+		checkProperties(fmt.Sprintf("package %q, channel %q", c.Package, c.Name), c.Properties)
+	}
+	for _, b := range cfg.Bundles {
+		checkProperties(fmt.Sprintf("package %q, bundle %q", b.Package, b.Name), b.Properties)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func describeMeta(m declcfg.Meta) string {
+	var parts []string
+	if m.Package != "" {
+		parts = append(parts, fmt.Sprintf("package %q", m.Package))
+	}
+	if m.Name != "" {
+		parts = append(parts, fmt.Sprintf("blob %q", m.Name))
+	}
+	if len(parts) == 0 {
+		return "blob"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// suggestion returns a hint naming the known values closest to value, if any
+// of them is within two edits of it.
+func suggestion(value string, known sets.Set[string]) string {
+	const maxDistance = 2
+	var closest []string
+	best := maxDistance + 1
+	for k := range known {
+		switch d := editDistance(value, k); {
+		case d < best:
+			best, closest = d, []string{k}
+		case d == best:
+			closest = append(closest, k)
+		}
+	}
+	if len(closest) == 0 {
+		return ""
+	}
+	sort.Strings(closest)
+	for i := range closest {
+		closest[i] = fmt.Sprintf("%q", closest[i])
+	}
+	return fmt.Sprintf(": did you mean %s?", strings.Join(closest, " or "))
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
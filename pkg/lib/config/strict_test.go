@@ -0,0 +1,107 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestValidateStrict(t *testing.T) {
+	type spec struct {
+		name        string
+		extra       string
+		errContains []string
+	}
+
+	specs := []spec{
+		{
+			name: "Valid",
+		},
+		{
+			name: "UnknownSchema",
+			extra: `---
+schema: olm.packge
+name: bar
+---
+schema: example.com.custom
+package: foo
+`,
+			errContains: []string{
+				`blob "bar": unknown schema "olm.packge": did you mean "olm.package"?`,
+				`package "foo": unknown schema "example.com.custom"`,
+			},
+		},
+		{
+			name: "UnknownPropertyType",
+			extra: `---
+schema: olm.bundle
+package: foo
+name: foo.v0.3.0
+image: quay.io/example/foo:v0.3.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.3.0
+  - type: olm.gvk.requird
+    value:
+      group: example.com
+      kind: Foo
+      version: v1
+`,
+			errContains: []string{
+				`package "foo", bundle "foo.v0.3.0": unknown property type "olm.gvk.requird": did you mean "olm.gvk.required"?`,
+			},
+		},
+		{
+			name: "DuplicateProperty",
+			extra: `---
+schema: olm.bundle
+package: foo
+name: foo.v0.3.0
+image: quay.io/example/foo:v0.3.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.3.0
+  - type: olm.package
+    value: {"packageName": "foo", "version": "0.3.0"}
+`,
+			errContains: []string{
+				`package "foo", bundle "foo.v0.3.0": duplicate "olm.package" property with value {"packageName":"foo","version":"0.3.0"}`,
+			},
+		},
+	}
+
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"foo.yaml": &fstest.MapFile{Data: []byte(validPackage)},
+			}
+			if s.extra != "" {
+				fsys["extra.yaml"] = &fstest.MapFile{Data: []byte(s.extra)}
+			}
+
+			err := Validate(context.Background(), fsys, WithStrict())
+			if len(s.errContains) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, msg := range s.errContains {
+				require.ErrorContains(t, err, msg)
+			}
+		})
+	}
+}
+
+func TestSuggestion(t *testing.T) {
+	known := sets.New("olm.package", "olm.channel", "olm.bundle")
+	require.Equal(t, `: did you mean "olm.package"?`, suggestion("olm.packge", known))
+	require.Equal(t, `: did you mean "olm.bundle"?`, suggestion("olm.Bundle", known))
+	require.Empty(t, suggestion("example.com.custom", known))
+}
@@ -2,31 +2,196 @@ package config
 
 import (
 	"context"
+	"fmt"
 	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/containers/image/v5/types"
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/model"
+	"github.com/operator-framework/operator-registry/pkg/lib/log"
 )
 
+type ValidateOptions struct {
+	Log *logrus.Entry
+
+	LoadConcurrency int
+
+	Strict bool
+
+	CheckImages             bool
+	ImageCheckSystemContext *types.SystemContext
+	ImageCheckConcurrency   int
+}
+
+type ValidateOption func(*ValidateOptions)
+
+// WithLoadConcurrency sets the number of catalog files parsed concurrently.
+// It defaults to the number of CPUs.
+func WithLoadConcurrency(concurrency int) ValidateOption {
+	return func(o *ValidateOptions) {
+		o.LoadConcurrency = concurrency
+	}
+}
+
+// WithLog configures the logger used to report validation warnings.
+func WithLog(log *logrus.Entry) ValidateOption {
+	return func(o *ValidateOptions) {
+		o.Log = log
+	}
+}
+
 // Validate takes a filesystem containing the declarative config file(s)
 // 1. Validate if declarative config file(s) are valid based on specified schema
-// 2. Validate the `replaces` chains of the upgrade graph
+// 2. Optionally, reject unknown schemas, unknown property types and duplicate properties
+// 3. Validate that olm.deprecations blobs reference existing catalog content
+// 4. Validate the `replaces` chains of the upgrade graph
+// 5. Optionally, check that the images referenced by bundles can be pulled
 // Inputs:
 // directory: a filesystem where declarative config file(s) exist
 // Outputs:
 // error: a wrapped error that contains a tree of error strings
-func Validate(ctx context.Context, root fs.FS) error {
+func Validate(ctx context.Context, root fs.FS, opts ...ValidateOption) error {
+	options := ValidateOptions{
+		Log: log.Null(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	// Load config files and convert them to declcfg objects
-	cfg, err := declcfg.LoadFS(ctx, root)
+	cfg, err := declcfg.LoadFS(ctx, root, declcfg.WithConcurrency(options.LoadConcurrency))
 	if err != nil {
 		return err
 	}
+	// In strict mode, unknown schemas and property types are reported before
+	// the model is built, since a typo like "olm.packge" would otherwise
+	// surface as a confusing model validation error, if at all.
+	if options.Strict {
+		if err := validateStrict(*cfg); err != nil {
+			return err
+		}
+	}
+	// Validate all deprecation references up front, so that every invalid
+	// reference is reported rather than just the first one encountered
+	// during model conversion.
+	if err := validateDeprecations(*cfg); err != nil {
+		return err
+	}
 	// Validate the config using model validation:
 	// This will convert declcfg objects to intermediate model objects that are
 	// also used for serve and add commands. The conversion process will run
 	// validation for the model objects and ensure they are valid.
-	_, err = declcfg.ConvertToModel(*cfg)
+	m, err := declcfg.ConvertToModel(*cfg)
 	if err != nil {
 		return err
 	}
+	for _, warning := range deprecatedChannelHeads(m) {
+		options.Log.Warn(warning)
+	}
+	if options.CheckImages {
+		return checkImages(ctx, *cfg, options.ImageCheckSystemContext, options.ImageCheckConcurrency)
+	}
 	return nil
 }
+
+func validateDeprecations(cfg declcfg.DeclarativeConfig) error {
+	channels := map[string]sets.Set[string]{}
+	bundles := map[string]sets.Set[string]{}
+	for _, p := range cfg.Packages {
+		channels[p.Name] = sets.New[string]()
+		bundles[p.Name] = sets.New[string]()
+	}
+	for _, ch := range cfg.Channels {
+		if _, ok := channels[ch.Package]; ok {
+			channels[ch.Package].Insert(ch.Name)
+		}
+	}
+	for _, b := range cfg.Bundles {
+		if _, ok := bundles[b.Package]; ok {
+			bundles[b.Package].Insert(b.Name)
+		}
+	}
+
+	var errs []error
+	blobsPerPackage := map[string]int{}
+	for i, d := range cfg.Deprecations {
+		if d.Package == "" {
+			errs = append(errs, fmt.Errorf("%s blob at index %d: package name must be set", declcfg.SchemaDeprecation, i))
+			continue
+		}
+		blobsPerPackage[d.Package]++
+		if _, ok := channels[d.Package]; !ok {
+			errs = append(errs, fmt.Errorf("%s for package %q: package not found: add an %s blob for %q or remove the deprecation", declcfg.SchemaDeprecation, d.Package, declcfg.SchemaPackage, d.Package))
+			continue
+		}
+
+		for j, entry := range d.Entries {
+			ref := entry.Reference
+			prefix := fmt.Sprintf("%s for package %q: entry %d", declcfg.SchemaDeprecation, d.Package, j)
+			switch ref.Schema {
+			case declcfg.SchemaPackage:
+				if ref.Name != "" {
+					errs = append(errs, fmt.Errorf("%s: %s references must not set a name (found %q): the package is implied by the deprecation's package field", prefix, declcfg.SchemaPackage, ref.Name))
+				}
+			case declcfg.SchemaChannel:
+				if !channels[d.Package].Has(ref.Name) {
+					errs = append(errs, fmt.Errorf("%s: channel %q not found: %s", prefix, ref.Name, knownNames("channels", channels[d.Package])))
+				}
+			case declcfg.SchemaBundle:
+				if !bundles[d.Package].Has(ref.Name) {
+					errs = append(errs, fmt.Errorf("%s: bundle %q not found: %s", prefix, ref.Name, knownNames("bundles", bundles[d.Package])))
+				}
+			case "":
+				errs = append(errs, fmt.Errorf("%s: reference schema must be set to one of %s, %s, or %s", prefix, declcfg.SchemaPackage, declcfg.SchemaChannel, declcfg.SchemaBundle))
+			default:
+				errs = append(errs, fmt.Errorf("%s: unknown reference schema %q: must be one of %s, %s, or %s", prefix, ref.Schema, declcfg.SchemaPackage, declcfg.SchemaChannel, declcfg.SchemaBundle))
+			}
+		}
+	}
+
+	pkgNames := make([]string, 0, len(blobsPerPackage))
+	for pkgName := range blobsPerPackage {
+		pkgNames = append(pkgNames, pkgName)
+	}
+	sort.Strings(pkgNames)
+	for _, pkgName := range pkgNames {
+		if n := blobsPerPackage[pkgName]; n > 1 {
+			errs = append(errs, fmt.Errorf("package %q has %d %s blobs, expected at most one: merge their entries into a single blob", pkgName, n, declcfg.SchemaDeprecation))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func knownNames(kind string, names sets.Set[string]) string {
+	if names.Len() == 0 {
+		return fmt.Sprintf("package has no %s", kind)
+	}
+	return fmt.Sprintf("known %s are [%s]", kind, strings.Join(sets.List(names), ", "))
+}
+
+// deprecatedChannelHeads returns a warning for every channel whose head bundle
+// is deprecated while the channel itself is not. Subscriptions to such a channel
+// have no non-deprecated version to upgrade to.
+func deprecatedChannelHeads(m model.Model) []string {
+	var warnings []string
+	for _, pkg := range m {
+		for _, ch := range pkg.Channels {
+			if ch.Deprecation != nil {
+				continue
+			}
+			head, err := ch.Head()
+			if err != nil || head.Deprecation == nil {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("package %q: deprecated bundle %q is the head of channel %q: subscribers of this channel have no non-deprecated upgrade target; publish a newer bundle or deprecate the channel instead", pkg.Name, head.Name, ch.Name))
+		}
+	}
+	sort.Strings(warnings)
+	return warnings
+}
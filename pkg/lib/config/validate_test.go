@@ -0,0 +1,194 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	validPackage = `---
+schema: olm.package
+name: foo
+defaultChannel: stable
+---
+schema: olm.channel
+package: foo
+name: stable
+entries:
+  - name: foo.v0.1.0
+  - name: foo.v0.2.0
+    replaces: foo.v0.1.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.1.0
+image: quay.io/example/foo:v0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.1.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.2.0
+image: quay.io/example/foo:v0.2.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.2.0
+`
+)
+
+func TestValidate(t *testing.T) {
+	type spec struct {
+		name         string
+		deprecations string
+		assertion    require.ErrorAssertionFunc
+		errContains  []string
+		warnings     []string
+	}
+
+	specs := []spec{
+		{
+			name:      "NoDeprecations",
+			assertion: require.NoError,
+		},
+		{
+			name: "ValidDeprecations",
+			deprecations: `---
+schema: olm.deprecations
+package: foo
+entries:
+  - reference:
+      schema: olm.package
+    message: foo is deprecated
+  - reference:
+      schema: olm.bundle
+      name: foo.v0.1.0
+    message: foo.v0.1.0 is deprecated
+`,
+			assertion: require.NoError,
+		},
+		{
+			name: "UnknownReferencesAreAllReported",
+			deprecations: `---
+schema: olm.deprecations
+package: foo
+entries:
+  - reference:
+      schema: olm.channel
+      name: fast
+    message: fast is deprecated
+  - reference:
+      schema: olm.bundle
+      name: foo.v9.9.9
+    message: foo.v9.9.9 is deprecated
+  - reference:
+      schema: olm.operator
+      name: foo
+    message: foo is deprecated
+---
+schema: olm.deprecations
+package: bar
+entries:
+  - reference:
+      schema: olm.package
+    message: bar is deprecated
+`,
+			assertion: require.Error,
+			errContains: []string{
+				`olm.deprecations for package "foo": entry 0: channel "fast" not found: known channels are [stable]`,
+				`olm.deprecations for package "foo": entry 1: bundle "foo.v9.9.9" not found: known bundles are [foo.v0.1.0, foo.v0.2.0]`,
+				`olm.deprecations for package "foo": entry 2: unknown reference schema "olm.operator"`,
+				`olm.deprecations for package "bar": package not found`,
+			},
+		},
+		{
+			name: "MultipleBlobsPerPackage",
+			deprecations: `---
+schema: olm.deprecations
+package: foo
+entries:
+  - reference:
+      schema: olm.package
+    message: foo is deprecated
+---
+schema: olm.deprecations
+package: foo
+entries:
+  - reference:
+      schema: olm.bundle
+      name: foo.v0.1.0
+    message: foo.v0.1.0 is deprecated
+`,
+			assertion:   require.Error,
+			errContains: []string{`package "foo" has 2 olm.deprecations blobs, expected at most one`},
+		},
+		{
+			name: "DeprecatedChannelHead",
+			deprecations: `---
+schema: olm.deprecations
+package: foo
+entries:
+  - reference:
+      schema: olm.bundle
+      name: foo.v0.2.0
+    message: foo.v0.2.0 is deprecated
+`,
+			assertion: require.NoError,
+			warnings:  []string{`package "foo": deprecated bundle "foo.v0.2.0" is the head of channel "stable"`},
+		},
+		{
+			name: "DeprecatedChannelHeadInDeprecatedChannel",
+			deprecations: `---
+schema: olm.deprecations
+package: foo
+entries:
+  - reference:
+      schema: olm.channel
+      name: stable
+    message: stable is deprecated
+  - reference:
+      schema: olm.bundle
+      name: foo.v0.2.0
+    message: foo.v0.2.0 is deprecated
+`,
+			assertion: require.NoError,
+		},
+	}
+
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"foo.yaml": &fstest.MapFile{Data: []byte(validPackage)},
+			}
+			if s.deprecations != "" {
+				fsys["deprecations.yaml"] = &fstest.MapFile{Data: []byte(s.deprecations)}
+			}
+
+			logger, hook := test.NewNullLogger()
+			err := Validate(context.Background(), fsys, WithLog(logrus.NewEntry(logger)))
+			s.assertion(t, err)
+			for _, msg := range s.errContains {
+				require.ErrorContains(t, err, msg)
+			}
+
+			var warnings []string
+			for _, e := range hook.AllEntries() {
+				require.Equal(t, logrus.WarnLevel, e.Level)
+				warnings = append(warnings, e.Message)
+			}
+			require.Len(t, warnings, len(s.warnings))
+			for i, w := range s.warnings {
+				require.Contains(t, warnings[i], w)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/otiai10/copy"
+	"github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/containertools"
+	"github.com/operator-framework/operator-registry/pkg/image"
+	pregistry "github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+const defaultConfigsFolder = "configs"
+
+// extractedIndex is the content of an index image copied into a build
+// directory. Exactly one of databasePath and configsDir is set.
+type extractedIndex struct {
+	databasePath string
+	configsDir   string
+}
+
+// extractIndex copies the content of fromIndex into buildDir. sqlite-based
+// indexes are copied to the database folder, and file-based catalogs to the
+// configs folder. If fromIndex is empty, a new sqlite database is used.
+func (i ImageIndexer) extractIndex(buildDir, fromIndex, caFile string, skipTLSVerify, plainHTTP bool) (*extractedIndex, error) {
+	if fromIndex == "" {
+		databasePath, err := i.ExtractDatabase(buildDir, fromIndex, caFile, skipTLSVerify, plainHTTP)
+		if err != nil {
+			return nil, err
+		}
+		return &extractedIndex{databasePath: databasePath}, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("./", tmpDirPrefix)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	databaseFile, configsDir, err := i.unpackIndex(tmpDir, fromIndex, caFile, skipTLSVerify, plainHTTP, true)
+	if err != nil {
+		return nil, err
+	}
+	if configsDir == "" {
+		databasePath, err := copyDatabaseTo(databaseFile, filepath.Join(buildDir, defaultDatabaseFolder))
+		if err != nil {
+			return nil, err
+		}
+		return &extractedIndex{databasePath: databasePath}, nil
+	}
+
+	i.Logger.Infof("Index image %s is a file-based catalog", fromIndex)
+	targetDir := filepath.Join(buildDir, defaultConfigsFolder)
+	if err := copy.Copy(configsDir, targetDir); err != nil {
+		return nil, fmt.Errorf("copy file-based catalog: %v", err)
+	}
+	return &extractedIndex{configsDir: targetDir}, nil
+}
+
+// dockerfile returns a dockerfile that builds an index image with the
+// extracted index content.
+func (i ImageIndexer) dockerfile(binarySourceImage string, index *extractedIndex) (string, error) {
+	if index.configsDir == "" {
+		return i.DockerfileGenerator.GenerateIndexDockerfile(binarySourceImage, index.databasePath), nil
+	}
+	if binarySourceImage == "" {
+		binarySourceImage = containertools.DefaultBinarySourceImage
+	}
+	var buf bytes.Buffer
+	gen := action.GenerateDockerfile{
+		BaseImage:    binarySourceImage,
+		BuilderImage: binarySourceImage,
+		IndexDir:     index.configsDir,
+		Writer:       &buf,
+	}
+	if err := gen.Run(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// addToFBC adds the requested bundles to the file-based catalog in
+// configsDir. Each bundle is added to the channels listed in its annotations,
+// and its upgrade edges are read from its CSV, as they are for sqlite-based
+// indexes in replaces mode.
+func (i ImageIndexer) addToFBC(configsDir string, request AddToIndexRequest) error {
+	if request.Mode != pregistry.ReplacesMode {
+		return fmt.Errorf("file-based catalogs only support the replaces graph update mode")
+	}
+	if request.Overwrite {
+		return fmt.Errorf("overwriting the latest bundles is not supported for file-based catalogs")
+	}
+
+	reg, err := i.newRegistry(request.CaFile, request.SkipTLSVerify, request.PlainHTTP)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := reg.Destroy(); err != nil {
+			i.Logger.WithError(err).Warn("error destroying local cache")
+		}
+	}()
+
+	ctx := context.TODO()
+	for _, ref := range request.Bundles {
+		if err := i.addBundleToFBC(ctx, reg, configsDir, ref); err != nil {
+			if !request.Permissive {
+				return err
+			}
+			i.Logger.WithError(err).Warnf("permissive mode enabled, skipping bundle %s", ref)
+		}
+	}
+	return nil
+}
+
+func (i ImageIndexer) addBundleToFBC(ctx context.Context, reg image.Registry, configsDir, ref string) error {
+	channels, err := bundleChannels(ctx, reg, ref)
+	if err != nil {
+		return err
+	}
+	add := action.AddBundle{
+		CatalogDir: configsDir,
+		BundleRef:  ref,
+		Channels:   channels,
+		Registry:   reg,
+	}
+	return add.Run(ctx)
+}
+
+// bundleChannels returns the channels listed in the annotations of the bundle
+// image ref. The default channel, if any, is listed first so that it becomes
+// the default channel of a new package.
+func bundleChannels(ctx context.Context, reg image.Registry, ref string) ([]string, error) {
+	imageRef := image.SimpleReference(ref)
+	if err := reg.Pull(ctx, imageRef); err != nil {
+		return nil, err
+	}
+	tmpDir, err := os.MkdirTemp("", "bundle-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := reg.Unpack(ctx, imageRef, tmpDir); err != nil {
+		return nil, err
+	}
+	img, err := pregistry.NewImageInput(imageRef, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	var defaultChannel string
+	if img.Bundle.Annotations != nil {
+		defaultChannel = img.Bundle.Annotations.DefaultChannelName
+	}
+	return orderChannels(img.Bundle.Channels, defaultChannel), nil
+}
+
+func orderChannels(channels []string, defaultChannel string) []string {
+	ordered := make([]string, 0, len(channels))
+	if defaultChannel != "" {
+		ordered = append(ordered, defaultChannel)
+	}
+	for _, ch := range channels {
+		if ch != "" && ch != defaultChannel {
+			ordered = append(ordered, ch)
+		}
+	}
+	return ordered
+}
+
+// deleteFromFBC removes the requested packages from the file-based catalog
+// in configsDir.
+func deleteFromFBC(configsDir string, request DeleteFromIndexRequest) error {
+	for _, pkg := range request.Operators {
+		rm := action.Remove{
+			CatalogDir: configsDir,
+			Package:    pkg,
+			Force:      request.Permissive,
+		}
+		if err := rm.Run(context.TODO()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneFBC replaces the file-based catalog in configsDir with one that only
+// contains the requested packages.
+func pruneFBC(configsDir string, request PruneFromIndexRequest) error {
+	outDir, err := os.MkdirTemp(filepath.Dir(configsDir), "pruned-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(outDir)
+
+	filters := make([]action.PackageFilter, 0, len(request.Packages))
+	for _, pkg := range request.Packages {
+		filters = append(filters, action.PackageFilter{Name: pkg})
+	}
+	prune := action.Prune{
+		CatalogRef: configsDir,
+		Packages:   filters,
+		OutputDir:  outDir,
+		WriteFunc:  declcfg.WriteJSON,
+		FileExt:    ".json",
+	}
+	if err := prune.Run(context.TODO()); err != nil {
+		return err
+	}
+
+	// The temporary directory is only accessible by its owner, but the
+	// catalog must be readable by the user that serves the index image.
+	if err := os.Chmod(outDir, 0755); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(configsDir); err != nil {
+		return err
+	}
+	return os.Rename(outDir, configsDir)
+}
+
+// pruneStrandedFromFBC removes the bundles of the file-based catalog in
+// configsDir that are not in any channel.
+func (i ImageIndexer) pruneStrandedFromFBC(configsDir string) error {
+	prune := action.PruneStranded{CatalogDir: configsDir}
+	stranded, err := prune.Run(context.TODO())
+	if err != nil {
+		return err
+	}
+	for _, b := range stranded {
+		i.Logger.WithFields(logrus.Fields{"package": b.Package, "bundle": b.Name, "image": b.Image}).Info("removed stranded bundle")
+	}
+	return nil
+}
+
+// deprecateFromFBC deprecates the requested bundles of the file-based catalog
+// in configsDir and truncates the upgrade graph below them.
+func (i ImageIndexer) deprecateFromFBC(configsDir string, request DeprecateFromIndexRequest) error {
+	deprecate := action.DeprecateTruncate{
+		CatalogDir:          configsDir,
+		Bundles:             request.Bundles,
+		AllowPackageRemoval: request.AllowPackageRemoval,
+	}
+	result, err := deprecate.Run(context.TODO())
+	if err != nil {
+		return err
+	}
+	for _, pkg := range result.RemovedPackages {
+		i.Logger.WithField("package", pkg).Info("removed package")
+	}
+	for _, b := range result.Deprecated {
+		i.Logger.WithFields(logrus.Fields{"package": b.Package, "bundle": b.Name, "image": b.Image}).Info("deprecated bundle")
+	}
+	for _, b := range result.Removed {
+		i.Logger.WithFields(logrus.Fields{"package": b.Package, "bundle": b.Name, "image": b.Image}).Info("removed bundle")
+	}
+	return nil
+}
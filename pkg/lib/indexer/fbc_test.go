@@ -0,0 +1,194 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/containertools"
+	"github.com/operator-framework/operator-registry/pkg/image"
+	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	pregistry "github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+const testFBC = `---
+schema: olm.package
+name: foo
+defaultChannel: stable
+---
+schema: olm.channel
+package: foo
+name: stable
+entries:
+  - name: foo.v0.1.0
+---
+schema: olm.bundle
+package: foo
+name: foo.v0.1.0
+image: quay.io/example/foo-bundle:v0.1.0
+properties:
+  - type: olm.package
+    value:
+      packageName: foo
+      version: 0.1.0
+---
+schema: olm.package
+name: bar
+defaultChannel: alpha
+---
+schema: olm.channel
+package: bar
+name: alpha
+entries:
+  - name: bar.v1.0.0
+---
+schema: olm.bundle
+package: bar
+name: bar.v1.0.0
+image: quay.io/example/bar-bundle:v1.0.0
+properties:
+  - type: olm.package
+    value:
+      packageName: bar
+      version: 1.0.0
+`
+
+func writeTestFBC(t *testing.T) string {
+	configsDir := filepath.Join(t.TempDir(), defaultConfigsFolder)
+	require.NoError(t, os.MkdirAll(configsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configsDir, "catalog.yaml"), []byte(testFBC), 0600))
+	return configsDir
+}
+
+func packageNames(t *testing.T, configsDir string) []string {
+	cfg, err := declcfg.LoadFS(context.Background(), os.DirFS(configsDir))
+	require.NoError(t, err)
+	var names []string
+	for _, p := range cfg.Packages {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func TestDeleteFromFBC(t *testing.T) {
+	configsDir := writeTestFBC(t)
+	require.NoError(t, deleteFromFBC(configsDir, DeleteFromIndexRequest{Operators: []string{"foo"}}))
+	require.Equal(t, []string{"bar"}, packageNames(t, configsDir))
+
+	require.Error(t, deleteFromFBC(configsDir, DeleteFromIndexRequest{Operators: []string{"baz"}}))
+}
+
+func TestPruneFBC(t *testing.T) {
+	configsDir := writeTestFBC(t)
+	require.NoError(t, pruneFBC(configsDir, PruneFromIndexRequest{Packages: []string{"foo"}}))
+	require.Equal(t, []string{"foo"}, packageNames(t, configsDir))
+
+	info, err := os.Stat(configsDir)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0755), info.Mode().Perm())
+}
+
+const testBazCSV = `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: baz.v0.1.0
+spec:
+  version: 0.1.0
+`
+
+const testBazAnnotations = `annotations:
+  operators.operatorframework.io.bundle.package.v1: baz
+  operators.operatorframework.io.bundle.channels.v1: stable
+  operators.operatorframework.io.bundle.channel.default.v1: stable
+`
+
+func TestAddToIndexWithRegistry(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	indexRef := image.SimpleReference("quay.io/example/index:latest")
+	bundleRef := image.SimpleReference("quay.io/example/baz-bundle:v0.1.0")
+	reg := &image.MockRegistry{
+		RemoteImages: map[image.Reference]*image.MockImage{
+			indexRef: {
+				Labels: map[string]string{containertools.ConfigsLocationLabel: "/configs"},
+				FS: fstest.MapFS{
+					"configs/catalog.yaml": &fstest.MapFile{Data: []byte(testFBC)},
+				},
+			},
+			bundleRef: {
+				Labels: map[string]string{bundle.PackageLabel: "baz"},
+				FS: fstest.MapFS{
+					"manifests/baz.csv.yaml":    &fstest.MapFile{Data: []byte(testBazCSV)},
+					"metadata/annotations.yaml": &fstest.MapFile{Data: []byte(testBazAnnotations)},
+				},
+			},
+		},
+	}
+
+	i := ImageIndexer{
+		DockerfileGenerator: containertools.NewDockerfileGenerator(logrus.NewEntry(logrus.New())),
+		Logger:              logrus.NewEntry(logrus.New()),
+	}
+	require.NoError(t, i.AddToIndex(AddToIndexRequest{
+		Generate:  true,
+		FromIndex: indexRef.String(),
+		Bundles:   []string{bundleRef.String()},
+		Mode:      pregistry.ReplacesMode,
+		Registry:  reg,
+	}))
+	require.ElementsMatch(t, []string{"foo", "bar", "baz"}, packageNames(t, defaultConfigsFolder))
+
+	// The registry of the request is left for the caller to destroy.
+	_, err := reg.Labels(context.Background(), indexRef)
+	require.NoError(t, err)
+}
+
+func TestOrderChannels(t *testing.T) {
+	type spec struct {
+		name           string
+		channels       []string
+		defaultChannel string
+		expected       []string
+	}
+	specs := []spec{
+		{
+			name:     "NoDefault",
+			channels: []string{"alpha", "beta"},
+			expected: []string{"alpha", "beta"},
+		},
+		{
+			name:           "DefaultFirst",
+			channels:       []string{"alpha", "beta", "stable"},
+			defaultChannel: "stable",
+			expected:       []string{"stable", "alpha", "beta"},
+		},
+		{
+			name:           "EmptyChannels",
+			channels:       []string{""},
+			defaultChannel: "stable",
+			expected:       []string{"stable"},
+		},
+	}
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			require.Equal(t, s.expected, orderChannels(s.channels, s.defaultChannel))
+		})
+	}
+}
+
+func TestDockerfileFBC(t *testing.T) {
+	i := ImageIndexer{
+		DockerfileGenerator: containertools.NewDockerfileGenerator(logrus.NewEntry(logrus.New())),
+	}
+	dockerfile, err := i.dockerfile("", &extractedIndex{configsDir: "build/configs"})
+	require.NoError(t, err)
+	require.Contains(t, dockerfile, "ADD build/configs /configs")
+	require.Contains(t, dockerfile, "FROM "+containertools.DefaultBinarySourceImage)
+	require.Contains(t, dockerfile, "LABEL "+containertools.ConfigsLocationLabel+"=/configs")
+}
@@ -38,7 +38,7 @@ const (
 )
 
 // nolint:stylecheck
-var ErrFileBasedCatalogPrune = errors.New("`opm index prune` only supports sqlite-based catalogs. See https://github.com/redhat-openshift-ecosystem/community-operators-prod/issues/793 for instructions on pruning a plaintext files backed catalog.")
+var ErrFileBasedCatalogPrune = errors.New("this command only supports sqlite-based catalogs. See https://github.com/redhat-openshift-ecosystem/community-operators-prod/issues/793 for instructions on pruning a plaintext files backed catalog.")
 
 // ImageIndexer is a struct implementation of the Indexer interface
 type ImageIndexer struct {
@@ -53,6 +53,9 @@ type ImageIndexer struct {
 	BuildTool              containertools.ContainerTool
 	PullTool               containertools.ContainerTool
 	Logger                 *logrus.Entry
+
+	// registry is the Registry of the request being run, if any.
+	registry image.Registry
 }
 
 // AddToIndexRequest defines the parameters to send to the AddToIndex API
@@ -70,43 +73,39 @@ type AddToIndexRequest struct {
 	PlainHTTP         bool
 	Overwrite         bool
 	EnableAlpha       bool
+	// Registry, if set, pulls the images instead of a registry of the pull
+	// tool. It is owned by the caller and is not destroyed.
+	Registry image.Registry
 }
 
 // AddToIndex is an aggregate API used to generate a registry index image with additional bundles
 func (i ImageIndexer) AddToIndex(request AddToIndexRequest) error {
+	i.registry = request.Registry
 	buildDir, outDockerfile, cleanup, err := buildContext(request.Generate, request.OutDockerfile)
 	defer cleanup()
 	if err != nil {
 		return err
 	}
 
-	databasePath, err := i.ExtractDatabase(buildDir, request.FromIndex, request.CaFile, request.SkipTLSVerify, request.PlainHTTP)
+	index, err := i.extractIndex(buildDir, request.FromIndex, request.CaFile, request.SkipTLSVerify, request.PlainHTTP)
 	if err != nil {
 		return err
 	}
 
-	// Run opm registry add on the database
-	addToRegistryReq := registry.AddToRegistryRequest{
-		Bundles:       request.Bundles,
-		InputDatabase: databasePath,
-		Permissive:    request.Permissive,
-		Mode:          request.Mode,
-		SkipTLSVerify: request.SkipTLSVerify,
-		PlainHTTP:     request.PlainHTTP,
-		ContainerTool: i.PullTool,
-		Overwrite:     request.Overwrite,
-		EnableAlpha:   request.EnableAlpha,
+	if index.configsDir != "" {
+		err = i.addToFBC(index.configsDir, request)
+	} else {
+		err = i.addToDatabase(index.databasePath, request)
 	}
-
-	// Add the bundles to the registry
-	err = i.RegistryAdder.AddToRegistry(addToRegistryReq)
 	if err != nil {
-		i.Logger.WithError(err).Debugf("unable to add bundle to registry")
 		return err
 	}
 
 	// generate the dockerfile
-	dockerfile := i.DockerfileGenerator.GenerateIndexDockerfile(request.BinarySourceImage, databasePath)
+	dockerfile, err := i.dockerfile(request.BinarySourceImage, index)
+	if err != nil {
+		return err
+	}
 	err = write(dockerfile, outDockerfile, i.Logger)
 	if err != nil {
 		return err
@@ -125,6 +124,30 @@ func (i ImageIndexer) AddToIndex(request AddToIndexRequest) error {
 	return nil
 }
 
+func (i ImageIndexer) addToDatabase(databasePath string, request AddToIndexRequest) error {
+	// Run opm registry add on the database
+	addToRegistryReq := registry.AddToRegistryRequest{
+		Bundles:       request.Bundles,
+		InputDatabase: databasePath,
+		Permissive:    request.Permissive,
+		Mode:          request.Mode,
+		SkipTLSVerify: request.SkipTLSVerify,
+		PlainHTTP:     request.PlainHTTP,
+		ContainerTool: i.PullTool,
+		Registry:      i.registry,
+		Overwrite:     request.Overwrite,
+		EnableAlpha:   request.EnableAlpha,
+	}
+
+	// Add the bundles to the registry
+	err := i.RegistryAdder.AddToRegistry(addToRegistryReq)
+	if err != nil {
+		i.Logger.WithError(err).Debugf("unable to add bundle to registry")
+		return err
+	}
+	return nil
+}
+
 // DeleteFromIndexRequest defines the parameters to send to the DeleteFromIndex API
 type DeleteFromIndexRequest struct {
 	Generate          bool
@@ -137,37 +160,48 @@ type DeleteFromIndexRequest struct {
 	SkipTLSVerify     bool
 	PlainHTTP         bool
 	CaFile            string
+	// Registry, if set, pulls the images instead of a registry of the pull
+	// tool. It is owned by the caller and is not destroyed.
+	Registry image.Registry
 }
 
 // DeleteFromIndex is an aggregate API used to generate a registry index image
 // without specific operators
 func (i ImageIndexer) DeleteFromIndex(request DeleteFromIndexRequest) error {
+	i.registry = request.Registry
 	buildDir, outDockerfile, cleanup, err := buildContext(request.Generate, request.OutDockerfile)
 	defer cleanup()
 	if err != nil {
 		return err
 	}
 
-	databasePath, err := i.ExtractDatabase(buildDir, request.FromIndex, request.CaFile, request.SkipTLSVerify, request.PlainHTTP)
+	index, err := i.extractIndex(buildDir, request.FromIndex, request.CaFile, request.SkipTLSVerify, request.PlainHTTP)
 	if err != nil {
 		return err
 	}
 
-	// Run opm registry delete on the database
-	deleteFromRegistryReq := registry.DeleteFromRegistryRequest{
-		Packages:      request.Operators,
-		InputDatabase: databasePath,
-		Permissive:    request.Permissive,
-	}
+	if index.configsDir != "" {
+		err = deleteFromFBC(index.configsDir, request)
+	} else {
+		// Run opm registry delete on the database
+		deleteFromRegistryReq := registry.DeleteFromRegistryRequest{
+			Packages:      request.Operators,
+			InputDatabase: index.databasePath,
+			Permissive:    request.Permissive,
+		}
 
-	// Delete the bundles from the registry
-	err = i.RegistryDeleter.DeleteFromRegistry(deleteFromRegistryReq)
+		// Delete the bundles from the registry
+		err = i.RegistryDeleter.DeleteFromRegistry(deleteFromRegistryReq)
+	}
 	if err != nil {
 		return err
 	}
 
 	// generate the dockerfile
-	dockerfile := i.DockerfileGenerator.GenerateIndexDockerfile(request.BinarySourceImage, databasePath)
+	dockerfile, err := i.dockerfile(request.BinarySourceImage, index)
+	if err != nil {
+		return err
+	}
 	err = write(dockerfile, outDockerfile, i.Logger)
 	if err != nil {
 		return err
@@ -196,35 +230,46 @@ type PruneStrandedFromIndexRequest struct {
 	CaFile            string
 	SkipTLSVerify     bool
 	PlainHTTP         bool
+	// Registry, if set, pulls the images instead of a registry of the pull
+	// tool. It is owned by the caller and is not destroyed.
+	Registry image.Registry
 }
 
 // PruneStrandedFromIndex is an aggregate API used to generate a registry index image
 // that has removed stranded bundles from the index
 func (i ImageIndexer) PruneStrandedFromIndex(request PruneStrandedFromIndexRequest) error {
+	i.registry = request.Registry
 	buildDir, outDockerfile, cleanup, err := buildContext(request.Generate, request.OutDockerfile)
 	defer cleanup()
 	if err != nil {
 		return err
 	}
 
-	databasePath, err := i.ExtractDatabase(buildDir, request.FromIndex, request.CaFile, request.SkipTLSVerify, request.PlainHTTP)
+	index, err := i.extractIndex(buildDir, request.FromIndex, request.CaFile, request.SkipTLSVerify, request.PlainHTTP)
 	if err != nil {
 		return err
 	}
 
-	// Run opm registry prune-stranded on the database
-	pruneStrandedFromRegistryReq := registry.PruneStrandedFromRegistryRequest{
-		InputDatabase: databasePath,
-	}
+	if index.configsDir != "" {
+		err = i.pruneStrandedFromFBC(index.configsDir)
+	} else {
+		// Run opm registry prune-stranded on the database
+		pruneStrandedFromRegistryReq := registry.PruneStrandedFromRegistryRequest{
+			InputDatabase: index.databasePath,
+		}
 
-	// Delete the stranded bundles from the registry
-	err = i.RegistryStrandedPruner.PruneStrandedFromRegistry(pruneStrandedFromRegistryReq)
+		// Delete the stranded bundles from the registry
+		err = i.RegistryStrandedPruner.PruneStrandedFromRegistry(pruneStrandedFromRegistryReq)
+	}
 	if err != nil {
 		return err
 	}
 
 	// generate the dockerfile
-	dockerfile := i.DockerfileGenerator.GenerateIndexDockerfile(request.BinarySourceImage, databasePath)
+	dockerfile, err := i.dockerfile(request.BinarySourceImage, index)
+	if err != nil {
+		return err
+	}
 	err = write(dockerfile, outDockerfile, i.Logger)
 	if err != nil {
 		return err
@@ -254,35 +299,46 @@ type PruneFromIndexRequest struct {
 	CaFile            string
 	SkipTLSVerify     bool
 	PlainHTTP         bool
+	// Registry, if set, pulls the images instead of a registry of the pull
+	// tool. It is owned by the caller and is not destroyed.
+	Registry image.Registry
 }
 
 func (i ImageIndexer) PruneFromIndex(request PruneFromIndexRequest) error {
+	i.registry = request.Registry
 	buildDir, outDockerfile, cleanup, err := buildContext(request.Generate, request.OutDockerfile)
 	defer cleanup()
 	if err != nil {
 		return err
 	}
 
-	databasePath, err := i.ExtractDatabase(buildDir, request.FromIndex, request.CaFile, request.SkipTLSVerify, request.PlainHTTP)
+	index, err := i.extractIndex(buildDir, request.FromIndex, request.CaFile, request.SkipTLSVerify, request.PlainHTTP)
 	if err != nil {
 		return err
 	}
 
-	// Run opm registry prune on the database
-	pruneFromRegistryReq := registry.PruneFromRegistryRequest{
-		Packages:      request.Packages,
-		InputDatabase: databasePath,
-		Permissive:    request.Permissive,
-	}
+	if index.configsDir != "" {
+		err = pruneFBC(index.configsDir, request)
+	} else {
+		// Run opm registry prune on the database
+		pruneFromRegistryReq := registry.PruneFromRegistryRequest{
+			Packages:      request.Packages,
+			InputDatabase: index.databasePath,
+			Permissive:    request.Permissive,
+		}
 
-	// Prune the bundles from the registry
-	err = i.RegistryPruner.PruneFromRegistry(pruneFromRegistryReq)
+		// Prune the bundles from the registry
+		err = i.RegistryPruner.PruneFromRegistry(pruneFromRegistryReq)
+	}
 	if err != nil {
 		return err
 	}
 
 	// generate the dockerfile
-	dockerfile := i.DockerfileGenerator.GenerateIndexDockerfile(request.BinarySourceImage, databasePath)
+	dockerfile, err := i.dockerfile(request.BinarySourceImage, index)
+	if err != nil {
+		return err
+	}
 	err = write(dockerfile, outDockerfile, i.Logger)
 	if err != nil {
 		return err
@@ -322,29 +378,20 @@ func (i ImageIndexer) getDatabaseFile(workingDir, fromIndex, caFile string, skip
 		return path.Join(workingDir, defaultDatabaseFile), nil
 	}
 
+	databaseFile, _, err := i.unpackIndex(workingDir, fromIndex, caFile, skipTLSVerify, plainHTTP, false)
+	return databaseFile, err
+}
+
+// unpackIndex pulls fromIndex and unpacks it into workingDir. It returns the
+// path of the index database for sqlite-based indexes, or the path of the
+// configs directory for file-based catalogs if allowFBC is set.
+func (i ImageIndexer) unpackIndex(workingDir, fromIndex, caFile string, skipTLSVerify, plainHTTP, allowFBC bool) (string, string, error) {
 	// Pull the fromIndex
 	i.Logger.Infof("Pulling previous image %s to get metadata", fromIndex)
 
-	var reg image.Registry
-	var rerr error
-	switch i.PullTool {
-	case containertools.NoneTool:
-		rootCAs, err := certs.RootCAs(caFile)
-		if err != nil {
-			return "", fmt.Errorf("failed to get RootCAs: %v", err)
-		}
-		reg, rerr = containerdregistry.NewRegistry(
-			containerdregistry.SkipTLSVerify(skipTLSVerify),
-			containerdregistry.WithPlainHTTP(plainHTTP),
-			containerdregistry.WithLog(i.Logger),
-			containerdregistry.WithRootCAs(rootCAs))
-	case containertools.PodmanTool:
-		fallthrough
-	case containertools.DockerTool:
-		reg, rerr = execregistry.NewRegistry(i.PullTool, i.Logger, containertools.SkipTLS(plainHTTP))
-	}
-	if rerr != nil {
-		return "", rerr
+	reg, err := i.newRegistry(caFile, skipTLSVerify, plainHTTP)
+	if err != nil {
+		return "", "", err
 	}
 	defer func() {
 		if err := reg.Destroy(); err != nil {
@@ -355,28 +402,66 @@ func (i ImageIndexer) getDatabaseFile(workingDir, fromIndex, caFile string, skip
 	imageRef := image.SimpleReference(fromIndex)
 
 	if err := reg.Pull(context.TODO(), imageRef); err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	// Get the old index image's dbLocationLabel to find this path
 	labels, err := reg.Labels(context.TODO(), imageRef)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	dbLocation, ok := labels[containertools.DbLocationLabel]
-	if !ok {
-		if _, ok := labels[containertools.ConfigsLocationLabel]; ok {
-			return "", ErrFileBasedCatalogPrune
-		}
-		return "", fmt.Errorf("index image %s missing label %s", fromIndex, containertools.DbLocationLabel)
+	dbLocation, isSqlite := labels[containertools.DbLocationLabel]
+	configsLocation, isFBC := labels[containertools.ConfigsLocationLabel]
+	switch {
+	case isSqlite:
+	case isFBC && !allowFBC:
+		return "", "", ErrFileBasedCatalogPrune
+	case !isFBC:
+		return "", "", fmt.Errorf("index image %s missing label %s", fromIndex, containertools.DbLocationLabel)
 	}
 
 	if err := reg.Unpack(context.TODO(), imageRef, workingDir); err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	return path.Join(workingDir, dbLocation), nil
+	if isSqlite {
+		return path.Join(workingDir, dbLocation), "", nil
+	}
+	return "", path.Join(workingDir, configsLocation), nil
+}
+
+func (i ImageIndexer) newRegistry(caFile string, skipTLSVerify, plainHTTP bool) (image.Registry, error) {
+	if i.registry != nil {
+		return sharedRegistry{i.registry}, nil
+	}
+	switch i.PullTool {
+	case containertools.NoneTool:
+		rootCAs, err := certs.RootCAs(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get RootCAs: %v", err)
+		}
+		return containerdregistry.NewRegistry(
+			containerdregistry.SkipTLSVerify(skipTLSVerify),
+			containerdregistry.WithPlainHTTP(plainHTTP),
+			containerdregistry.WithLog(i.Logger),
+			containerdregistry.WithRootCAs(rootCAs))
+	case containertools.PodmanTool:
+		fallthrough
+	case containertools.DockerTool:
+		return execregistry.NewRegistry(i.PullTool, i.Logger, containertools.SkipTLS(plainHTTP))
+	}
+	return nil, fmt.Errorf("unsupported pull tool %q", i.PullTool)
+}
+
+// sharedRegistry is a registry given by the caller, which is left in place
+// when the indexer is done with it.
+type sharedRegistry struct {
+	image.Registry
+}
+
+func (sharedRegistry) Destroy() error {
+	return nil
 }
 
 func copyDatabaseTo(databaseFile, targetDir string) (string, error) {
@@ -672,37 +757,48 @@ type DeprecateFromIndexRequest struct {
 	SkipTLSVerify       bool
 	PlainHTTP           bool
 	AllowPackageRemoval bool
+	// Registry, if set, pulls the images instead of a registry of the pull
+	// tool. It is owned by the caller and is not destroyed.
+	Registry image.Registry
 }
 
 // DeprecateFromIndex takes a DeprecateFromIndexRequest and deprecates the requested
 // bundles.
 func (i ImageIndexer) DeprecateFromIndex(request DeprecateFromIndexRequest) error {
+	i.registry = request.Registry
 	buildDir, outDockerfile, cleanup, err := buildContext(request.Generate, request.OutDockerfile)
 	defer cleanup()
 	if err != nil {
 		return err
 	}
 
-	databasePath, err := i.ExtractDatabase(buildDir, request.FromIndex, request.CaFile, request.SkipTLSVerify, request.PlainHTTP)
+	index, err := i.extractIndex(buildDir, request.FromIndex, request.CaFile, request.SkipTLSVerify, request.PlainHTTP)
 	if err != nil {
 		return err
 	}
 
-	deprecateFromRegistryReq := registry.DeprecateFromRegistryRequest{
-		Bundles:             request.Bundles,
-		InputDatabase:       databasePath,
-		Permissive:          request.Permissive,
-		AllowPackageRemoval: request.AllowPackageRemoval,
-	}
+	if index.configsDir != "" {
+		err = i.deprecateFromFBC(index.configsDir, request)
+	} else {
+		deprecateFromRegistryReq := registry.DeprecateFromRegistryRequest{
+			Bundles:             request.Bundles,
+			InputDatabase:       index.databasePath,
+			Permissive:          request.Permissive,
+			AllowPackageRemoval: request.AllowPackageRemoval,
+		}
 
-	// Deprecate the bundles from the registry
-	err = i.RegistryDeprecator.DeprecateFromRegistry(deprecateFromRegistryReq)
+		// Deprecate the bundles from the registry
+		err = i.RegistryDeprecator.DeprecateFromRegistry(deprecateFromRegistryReq)
+	}
 	if err != nil {
 		return err
 	}
 
 	// generate the dockerfile
-	dockerfile := i.DockerfileGenerator.GenerateIndexDockerfile(request.BinarySourceImage, databasePath)
+	dockerfile, err := i.dockerfile(request.BinarySourceImage, index)
+	if err != nil {
+		return err
+	}
 	err = write(dockerfile, outDockerfile, i.Logger)
 	if err != nil {
 		return err
@@ -30,9 +30,15 @@ type AddToRegistryRequest struct {
 	InputDatabase string
 	Bundles       []string
 	Mode          registry.Mode
+	// GraphStrategy, if set, builds the upgrade graphs of the channels of the
+	// bundles instead of Mode.
+	GraphStrategy registry.GraphStrategy
 	ContainerTool containertools.ContainerTool
-	Overwrite     bool
-	EnableAlpha   bool
+	// Registry, if set, pulls the bundles instead of a registry of
+	// ContainerTool. It is owned by the caller and is not destroyed.
+	Registry    image.Registry
+	Overwrite   bool
+	EnableAlpha bool
 }
 
 func (r RegistryUpdater) AddToRegistry(request AddToRegistryRequest) error {
@@ -59,10 +65,11 @@ func (r RegistryUpdater) AddToRegistry(request AddToRegistryRequest) error {
 
 	// add custom ca certs to resolver
 
-	var reg image.Registry
+	reg := request.Registry
 	var rerr error
-	switch request.ContainerTool {
-	case containertools.NoneTool:
+	switch {
+	case reg != nil:
+	case request.ContainerTool == containertools.NoneTool:
 		rootCAs, err := certs.RootCAs(request.CaFile)
 		if err != nil {
 			return fmt.Errorf("failed to get RootCAs: %v", err)
@@ -72,26 +79,26 @@ func (r RegistryUpdater) AddToRegistry(request AddToRegistryRequest) error {
 			containerdregistry.WithPlainHTTP(request.PlainHTTP),
 			containerdregistry.WithRootCAs(rootCAs),
 		)
-	case containertools.PodmanTool:
-		fallthrough
-	case containertools.DockerTool:
+	case request.ContainerTool == containertools.PodmanTool, request.ContainerTool == containertools.DockerTool:
 		reg, rerr = execregistry.NewRegistry(request.ContainerTool, r.Logger, containertools.SkipTLS(request.PlainHTTP))
 	}
 	if rerr != nil {
 		return rerr
 	}
-	defer func() {
-		if err := reg.Destroy(); err != nil {
-			r.Logger.WithError(err).Warn("error destroying local cache")
-		}
-	}()
+	if request.Registry == nil {
+		defer func() {
+			if err := reg.Destroy(); err != nil {
+				r.Logger.WithError(err).Warn("error destroying local cache")
+			}
+		}()
+	}
 
 	simpleRefs := make([]image.Reference, 0)
 	for _, ref := range request.Bundles {
 		simpleRefs = append(simpleRefs, image.SimpleReference(ref))
 	}
 
-	if err := populate(context.TODO(), dbLoader, graphLoader, dbQuerier, reg, simpleRefs, request.Mode, request.Overwrite); err != nil {
+	if err := populate(context.TODO(), dbLoader, graphLoader, dbQuerier, reg, simpleRefs, request.Mode, request.GraphStrategy, request.Overwrite); err != nil {
 		r.Logger.Debugf("unable to populate database: %s", err)
 
 		if !request.Permissive {
@@ -131,7 +138,7 @@ func unpackImage(ctx context.Context, reg image.Registry, ref image.Reference) (
 	return ref, workingDir, cleanup, nil
 }
 
-func populate(ctx context.Context, loader registry.Load, graphLoader registry.GraphLoader, querier registry.Query, reg image.Registry, refs []image.Reference, mode registry.Mode, overwrite bool) error {
+func populate(ctx context.Context, loader registry.Load, graphLoader registry.GraphLoader, querier registry.Query, reg image.Registry, refs []image.Reference, mode registry.Mode, strategy registry.GraphStrategy, overwrite bool) error {
 	unpackedImageMap := make(map[image.Reference]string, 0)
 	overwrittenBundles := map[string][]string{}
 	// nolint:prealloc
@@ -167,7 +174,13 @@ func populate(ctx context.Context, loader registry.Load, graphLoader registry.Gr
 
 	populator := registry.NewDirectoryPopulator(loader, graphLoader, querier, unpackedImageMap, overwrittenBundles)
 
-	if err := populator.Populate(mode); err != nil {
+	var err error
+	if strategy != nil {
+		err = populator.PopulateWithStrategy(strategy)
+	} else {
+		err = populator.Populate(mode)
+	}
+	if err != nil {
 		return err
 	}
 	return checkForBundles(ctx, querier.(*sqlite.SQLQuerier), graphLoader, imagesToAdd)
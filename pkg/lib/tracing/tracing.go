@@ -0,0 +1,81 @@
+// Package tracing configures OpenTelemetry tracing for opm from the standard
+// OpenTelemetry environment variables.
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/contrib/exporters/autoexport"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// envVars are the environment variables that enable tracing when set.
+var envVars = []string{
+	"OTEL_TRACES_EXPORTER",
+	"OTEL_EXPORTER_OTLP_ENDPOINT",
+	"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+}
+
+// Setup installs a global tracer provider that exports spans as configured by
+// the standard OpenTelemetry environment variables, e.g. to an OTLP collector
+// at OTEL_EXPORTER_OTLP_ENDPOINT, with the protocol of
+// OTEL_EXPORTER_OTLP_PROTOCOL. Spans are attributed to serviceName, unless
+// OTEL_SERVICE_NAME is set. The trace context of requests is propagated in
+// the W3C format.
+//
+// Tracing is only enabled if OTEL_TRACES_EXPORTER, other than "none", or an
+// OTLP endpoint is set; otherwise, Setup does nothing. The returned function
+// flushes the exported spans and stops the exporter.
+func Setup(ctx context.Context, serviceName string) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if !enabled() {
+		return noop, nil
+	}
+	exporter, err := autoexport.NewSpanExporter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if autoexport.IsNoneSpanExporter(exporter) {
+		return noop, nil
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+func enabled() bool {
+	for _, v := range envVars {
+		if os.Getenv(v) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// End records err, if any, on span, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -34,6 +34,9 @@ func validateBundle(bundle *registry.Bundle) errors.ManifestResult {
 	}
 
 	result = validateOwnedCRDs(bundle, csv)
+	install := validateCSVInstall(bundle, csv)
+	result.Add(install.Errors...)
+	result.Add(install.Warnings...)
 
 	if result.Name, err = csv.GetVersion(); err != nil {
 		result.Add(errors.ErrInvalidParse("error getting bundle CSV version", err))
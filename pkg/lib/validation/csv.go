@@ -0,0 +1,168 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	v1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	"github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+// targetNamespacesAnnotation lists the namespaces a CSV is meant to be
+// installed for, as OLM sets it on the CSVs of operator groups: an empty
+// value for all namespaces, or a comma separated list.
+const targetNamespacesAnnotation = "olm.targetNamespaces"
+
+// defaultServiceAccount is the service account of pods that do not set one,
+// which exists in every namespace.
+const defaultServiceAccount = "default"
+
+// validateCSVInstall checks that the install modes, the permissions, the
+// deployments and the webhooks of the CSV of bundle are consistent, as OLM
+// would otherwise fail to install the bundle, or install it unlike intended.
+func validateCSVInstall(bundle *registry.Bundle, csv *registry.ClusterServiceVersion) errors.ManifestResult {
+	var result errors.ManifestResult
+	var spec v1alpha1.ClusterServiceVersionSpec
+	if err := json.Unmarshal(csv.Spec, &spec); err != nil {
+		result.Add(errors.ErrInvalidParse("error parsing CSV spec", err))
+		return result
+	}
+
+	result.Add(validateInstallModes(csv, spec.InstallModes)...)
+	result.Add(validatePermissions(csv.GetName(), "permissions", spec.InstallStrategy.StrategySpec.Permissions)...)
+	result.Add(validatePermissions(csv.GetName(), "clusterPermissions", spec.InstallStrategy.StrategySpec.ClusterPermissions)...)
+	result.Add(validateServiceAccounts(bundle, csv.GetName(), spec.InstallStrategy.StrategySpec)...)
+	result.Add(validateWebhooks(csv.GetName(), spec.InstallStrategy.StrategySpec, spec.WebhookDefinitions)...)
+	return result
+}
+
+func validateInstallModes(csv *registry.ClusterServiceVersion, modes []v1alpha1.InstallMode) []errors.Error {
+	var errs []errors.Error
+	supported := map[v1alpha1.InstallModeType]bool{}
+	for _, mode := range modes {
+		switch mode.Type {
+		case v1alpha1.InstallModeTypeOwnNamespace, v1alpha1.InstallModeTypeSingleNamespace, v1alpha1.InstallModeTypeMultiNamespace, v1alpha1.InstallModeTypeAllNamespaces:
+		default:
+			errs = append(errs, errors.ErrInvalidCSV(fmt.Sprintf("unknown install mode type %q", mode.Type), csv.GetName()))
+			continue
+		}
+		if _, ok := supported[mode.Type]; ok {
+			errs = append(errs, errors.ErrInvalidCSV(fmt.Sprintf("install mode %q is defined more than once", mode.Type), csv.GetName()))
+			continue
+		}
+		supported[mode.Type] = mode.Supported
+	}
+	anySupported := false
+	for _, ok := range supported {
+		anySupported = anySupported || ok
+	}
+	if len(modes) > 0 && !anySupported {
+		errs = append(errs, errors.ErrInvalidCSV("no install mode is supported", csv.GetName()))
+	}
+
+	targetNamespaces, ok := csv.GetAnnotations()[targetNamespacesAnnotation]
+	if !ok {
+		return errs
+	}
+	var required []v1alpha1.InstallModeType
+	switch namespaces := strings.Split(targetNamespaces, ","); {
+	case targetNamespaces == "":
+		required = []v1alpha1.InstallModeType{v1alpha1.InstallModeTypeAllNamespaces}
+	case len(namespaces) == 1 && namespaces[0] == csv.GetNamespace():
+		required = []v1alpha1.InstallModeType{v1alpha1.InstallModeTypeOwnNamespace, v1alpha1.InstallModeTypeSingleNamespace}
+	case len(namespaces) == 1:
+		required = []v1alpha1.InstallModeType{v1alpha1.InstallModeTypeSingleNamespace}
+	default:
+		required = []v1alpha1.InstallModeType{v1alpha1.InstallModeTypeMultiNamespace}
+	}
+	for _, mode := range required {
+		if supported[mode] {
+			return errs
+		}
+	}
+	return append(errs, errors.ErrInvalidCSV(fmt.Sprintf("annotation %s=%q requires one of the unsupported install modes %v", targetNamespacesAnnotation, targetNamespaces, required), csv.GetName()))
+}
+
+// validatePermissions warns about the service accounts listed more than once
+// in the permissions of field, and about the rules repeated for a service
+// account, which are redundant.
+func validatePermissions(csvName, field string, permissions []v1alpha1.StrategyDeploymentPermissions) []errors.Error {
+	var errs []errors.Error
+	seen := map[string]struct{}{}
+	for _, p := range permissions {
+		if _, ok := seen[p.ServiceAccountName]; ok {
+			errs = append(errs, errors.WarnInvalidCSV(fmt.Sprintf("service account %q is listed more than once in %s", p.ServiceAccountName, field), csvName))
+		}
+		seen[p.ServiceAccountName] = struct{}{}
+		for i := range p.Rules {
+			for j := 0; j < i; j++ {
+				if reflect.DeepEqual(p.Rules[i], p.Rules[j]) {
+					errs = append(errs, errors.WarnInvalidCSV(fmt.Sprintf("rule %d of service account %q in %s duplicates rule %d", i, p.ServiceAccountName, field, j), csvName))
+					break
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// validateServiceAccounts checks that the service accounts of the deployments
+// of the CSV exist when they are installed: OLM creates the service accounts
+// of the permissions of the CSV, and those of the bundle.
+func validateServiceAccounts(bundle *registry.Bundle, csvName string, strategy v1alpha1.StrategyDetailsDeployment) []errors.Error {
+	accounts := map[string]struct{}{defaultServiceAccount: {}}
+	for _, p := range strategy.Permissions {
+		accounts[p.ServiceAccountName] = struct{}{}
+	}
+	for _, p := range strategy.ClusterPermissions {
+		accounts[p.ServiceAccountName] = struct{}{}
+	}
+	for _, obj := range bundle.Objects {
+		if obj.GetKind() == "ServiceAccount" {
+			accounts[obj.GetName()] = struct{}{}
+		}
+	}
+
+	var errs []errors.Error
+	for _, d := range strategy.DeploymentSpecs {
+		account := d.Spec.Template.Spec.ServiceAccountName
+		if account == "" {
+			// nolint:staticcheck
+			account = d.Spec.Template.Spec.DeprecatedServiceAccount
+		}
+		if account == "" {
+			continue
+		}
+		if _, ok := accounts[account]; !ok {
+			errs = append(errs, errors.ErrInvalidCSV(fmt.Sprintf("deployment %q uses service account %q, which is neither in the permissions of the CSV nor in the bundle", d.Name, account), csvName))
+		}
+	}
+	return errs
+}
+
+// validateWebhooks checks that the webhooks of the CSV are served on valid
+// ports by deployments of the CSV.
+func validateWebhooks(csvName string, strategy v1alpha1.StrategyDetailsDeployment, webhooks []v1alpha1.WebhookDescription) []errors.Error {
+	deployments := map[string]struct{}{}
+	for _, d := range strategy.DeploymentSpecs {
+		deployments[d.Name] = struct{}{}
+	}
+
+	var errs []errors.Error
+	for _, w := range webhooks {
+		switch {
+		case w.ContainerPort == 0:
+			errs = append(errs, errors.WarnInvalidCSV(fmt.Sprintf("webhook %q has no containerPort, so port 443 is used", w.GenerateName), csvName))
+		case w.ContainerPort < 0 || w.ContainerPort > 65535:
+			errs = append(errs, errors.ErrInvalidCSV(fmt.Sprintf("webhook %q has invalid containerPort %d", w.GenerateName, w.ContainerPort), csvName))
+		}
+		if _, ok := deployments[w.DeploymentName]; !ok {
+			errs = append(errs, errors.ErrInvalidCSV(fmt.Sprintf("webhook %q is served by deployment %q, which is not in the install strategy", w.GenerateName, w.DeploymentName), csvName))
+		}
+	}
+	return errs
+}
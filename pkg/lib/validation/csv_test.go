@@ -0,0 +1,183 @@
+package validation
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	"github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+func loadTestCSV(t *testing.T) *unstructured.Unstructured {
+	t.Helper()
+	data, err := os.ReadFile("./testdata/valid_bundle/etcdoperator.v0.9.4.clusterserviceversion.yaml")
+	require.NoError(t, err)
+	csv := &unstructured.Unstructured{}
+	require.NoError(t, k8syaml.NewYAMLOrJSONDecoder(strings.NewReader(string(data)), 30).Decode(csv))
+	return csv
+}
+
+func setField(t *testing.T, obj *unstructured.Unstructured, value interface{}, fields ...string) {
+	t.Helper()
+	require.NoError(t, unstructured.SetNestedField(obj.Object, value, fields...))
+}
+
+func TestValidateCSVInstall(t *testing.T) {
+	permission := func(account string, rules ...interface{}) interface{} {
+		return map[string]interface{}{"serviceAccountName": account, "rules": rules}
+	}
+	rule := map[string]interface{}{"apiGroups": []interface{}{""}, "resources": []interface{}{"pods"}, "verbs": []interface{}{"get"}}
+	installModes := func(supported ...string) []interface{} {
+		var modes []interface{}
+		for _, mode := range []string{"OwnNamespace", "SingleNamespace", "MultiNamespace", "AllNamespaces"} {
+			isSupported := false
+			for _, s := range supported {
+				isSupported = isSupported || s == mode
+			}
+			modes = append(modes, map[string]interface{}{"type": mode, "supported": isSupported})
+		}
+		return modes
+	}
+	webhook := func(port int64, deployment string) interface{} {
+		w := map[string]interface{}{
+			"generateName":            "vetcdcluster.kb.io",
+			"type":                    "ValidatingAdmissionWebhook",
+			"deploymentName":          deployment,
+			"sideEffects":             "None",
+			"admissionReviewVersions": []interface{}{"v1"},
+		}
+		if port != 0 {
+			w["containerPort"] = port
+		}
+		return w
+	}
+	serviceAccount := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ServiceAccount",
+		"metadata":   map[string]interface{}{"name": "etcd-backup"},
+	}}
+
+	for _, tt := range []struct {
+		name     string
+		mutate   func(*unstructured.Unstructured)
+		objs     []*unstructured.Unstructured
+		errors   []string
+		warnings []string
+	}{
+		{
+			name:   "Valid",
+			mutate: func(*unstructured.Unstructured) {},
+		},
+		{
+			name: "NoSupportedInstallMode",
+			mutate: func(csv *unstructured.Unstructured) {
+				setField(t, csv, installModes(), "spec", "installModes")
+			},
+			errors: []string{"no install mode is supported"},
+		},
+		{
+			name: "DuplicateInstallMode",
+			mutate: func(csv *unstructured.Unstructured) {
+				setField(t, csv, append(installModes("OwnNamespace"), map[string]interface{}{"type": "OwnNamespace", "supported": false}), "spec", "installModes")
+			},
+			errors: []string{`install mode "OwnNamespace" is defined more than once`},
+		},
+		{
+			name: "UnknownInstallMode",
+			mutate: func(csv *unstructured.Unstructured) {
+				setField(t, csv, append(installModes("OwnNamespace"), map[string]interface{}{"type": "ClusterWide", "supported": true}), "spec", "installModes")
+			},
+			errors: []string{`unknown install mode type "ClusterWide"`},
+		},
+		{
+			name: "TargetNamespacesSupported",
+			mutate: func(csv *unstructured.Unstructured) {
+				setField(t, csv, "placeholder", "metadata", "annotations", "olm.targetNamespaces")
+			},
+		},
+		{
+			name: "TargetNamespacesAllNamespacesUnsupported",
+			mutate: func(csv *unstructured.Unstructured) {
+				setField(t, csv, "", "metadata", "annotations", "olm.targetNamespaces")
+			},
+			errors: []string{`annotation olm.targetNamespaces="" requires one of the unsupported install modes [AllNamespaces]`},
+		},
+		{
+			name: "TargetNamespacesMultiNamespaceUnsupported",
+			mutate: func(csv *unstructured.Unstructured) {
+				setField(t, csv, "a,b", "metadata", "annotations", "olm.targetNamespaces")
+			},
+			errors: []string{`annotation olm.targetNamespaces="a,b" requires one of the unsupported install modes [MultiNamespace]`},
+		},
+		{
+			name: "DuplicatePermissions",
+			mutate: func(csv *unstructured.Unstructured) {
+				setField(t, csv, []interface{}{permission("etcd-operator", rule, rule), permission("etcd-operator", rule)}, "spec", "install", "spec", "permissions")
+			},
+			warnings: []string{
+				`rule 1 of service account "etcd-operator" in permissions duplicates rule 0`,
+				`service account "etcd-operator" is listed more than once in permissions`,
+			},
+		},
+		{
+			name: "MissingServiceAccount",
+			mutate: func(csv *unstructured.Unstructured) {
+				setField(t, csv, []interface{}{permission("etcd", rule)}, "spec", "install", "spec", "permissions")
+			},
+			errors: []string{`deployment "etcd-operator" uses service account "etcd-operator", which is neither in the permissions of the CSV nor in the bundle`},
+		},
+		{
+			name: "ServiceAccountInBundle",
+			mutate: func(csv *unstructured.Unstructured) {
+				deployments, _, _ := unstructured.NestedSlice(csv.Object, "spec", "install", "spec", "deployments")
+				require.NoError(t, unstructured.SetNestedField(deployments[0].(map[string]interface{}), "etcd-backup", "spec", "template", "spec", "serviceAccountName"))
+				setField(t, csv, deployments, "spec", "install", "spec", "deployments")
+			},
+			objs: []*unstructured.Unstructured{serviceAccount},
+		},
+		{
+			name: "WebhookWithoutPort",
+			mutate: func(csv *unstructured.Unstructured) {
+				setField(t, csv, []interface{}{webhook(0, "etcd-operator")}, "spec", "webhookdefinitions")
+			},
+			warnings: []string{`webhook "vetcdcluster.kb.io" has no containerPort, so port 443 is used`},
+		},
+		{
+			name: "WebhookInvalid",
+			mutate: func(csv *unstructured.Unstructured) {
+				setField(t, csv, []interface{}{webhook(70000, "etcd")}, "spec", "webhookdefinitions")
+			},
+			errors: []string{
+				`webhook "vetcdcluster.kb.io" has invalid containerPort 70000`,
+				`webhook "vetcdcluster.kb.io" is served by deployment "etcd", which is not in the install strategy`,
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := loadTestCSV(t)
+			tt.mutate(obj)
+			bundle := registry.NewBundle("test", &registry.Annotations{}, append([]*unstructured.Unstructured{obj}, tt.objs...)...)
+			csv, err := bundle.ClusterServiceVersion()
+			require.NoError(t, err)
+
+			result := validateCSVInstall(bundle, csv)
+			var errs, warnings []string
+			for _, e := range result.Errors {
+				require.Equal(t, errors.ErrorInvalidCSV, e.Type)
+				errs = append(errs, strings.TrimPrefix(e.Detail, "(etcdoperator.v0.9.4) "))
+			}
+			for _, e := range result.Warnings {
+				require.Equal(t, errors.ErrorInvalidCSV, e.Type)
+				warnings = append(warnings, strings.TrimPrefix(e.Detail, "(etcdoperator.v0.9.4) "))
+			}
+			require.ElementsMatch(t, tt.errors, errs)
+			require.ElementsMatch(t, tt.warnings, warnings)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	// Register the decoders of the raster icon media types.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	v1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apivalidation "github.com/operator-framework/api/pkg/validation"
+	"github.com/operator-framework/api/pkg/validation/errors"
+	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
+)
+
+const (
+	// maxIconSize is the maximum size in bytes of the decoded icon of a CSV.
+	maxIconSize = 100 << 10
+	// minDescriptionLength is the minimum length of the description of a
+	// CSV, below which it is unlikely to describe the operator usefully.
+	minDescriptionLength = 100
+	// maxShortDescriptionLength is the maximum length of the description
+	// annotation of a CSV, which is shown on catalog tiles.
+	maxShortDescriptionLength = 135
+)
+
+// OperatorHubValidators implement the operatorhub.io metadata ruleset: the
+// checks of the operatorhub/v2, standard capabilities and standard categories
+// validators of operator-framework/api, and OperatorHubMetadataValidator.
+// They validate *manifests.Bundle objects.
+var OperatorHubValidators = interfaces.Validators{
+	apivalidation.OperatorHubV2Validator,
+	apivalidation.StandardCapabilitiesValidator,
+	apivalidation.StandardCategoriesValidator,
+	OperatorHubMetadataValidator,
+}
+
+// OperatorHubMetadataValidator checks the CSV metadata that operatorhub.io
+// requires beyond the validators of operator-framework/api: the capability
+// level and categories annotations, the size and the content of the icon, and
+// the length of the descriptions.
+var OperatorHubMetadataValidator interfaces.Validator = interfaces.ValidatorFunc(validateOperatorHubMetadata)
+
+func validateOperatorHubMetadata(objs ...interface{}) []errors.ManifestResult {
+	var results []errors.ManifestResult
+	for _, obj := range objs {
+		if b, ok := obj.(*manifests.Bundle); ok && b.CSV != nil {
+			results = append(results, validateCSVMetadata(b.Name, b.CSV))
+		}
+	}
+	return results
+}
+
+func validateCSVMetadata(name string, csv *v1alpha1.ClusterServiceVersion) errors.ManifestResult {
+	result := errors.ManifestResult{Name: name}
+	annotations := csv.GetAnnotations()
+	if annotations["capabilities"] == "" {
+		result.Add(errors.ErrInvalidCSV(`csv.Metadata.Annotations["capabilities"] must be set to the capability level of the operator`, csv.GetName()))
+	}
+	if strings.TrimSpace(annotations["categories"]) == "" {
+		result.Add(errors.WarnInvalidCSV(`csv.Metadata.Annotations["categories"] is not set`, csv.GetName()))
+	}
+
+	switch description := strings.TrimSpace(csv.Spec.Description); {
+	case description == "":
+		result.Add(errors.ErrInvalidCSV("csv.Spec.Description must be set", csv.GetName()))
+	case len(description) < minDescriptionLength:
+		result.Add(errors.WarnInvalidCSV(fmt.Sprintf("csv.Spec.Description is %d characters long, fewer than the recommended %d", len(description), minDescriptionLength), csv.GetName()))
+	}
+	switch short := annotations["description"]; {
+	case short == "":
+		result.Add(errors.WarnInvalidCSV(`csv.Metadata.Annotations["description"] is not set`, csv.GetName()))
+	case len(short) > maxShortDescriptionLength:
+		result.Add(errors.ErrInvalidCSV(fmt.Sprintf(`csv.Metadata.Annotations["description"] is %d characters long, more than the maximum of %d`, len(short), maxShortDescriptionLength), csv.GetName()))
+	}
+
+	for _, icon := range csv.Spec.Icon {
+		if err := validateIcon(icon); err != nil {
+			result.Add(errors.ErrInvalidCSV(fmt.Sprintf("csv.Spec.Icon: %v", err), csv.GetName()))
+		}
+	}
+	return result
+}
+
+// validateIcon checks that icon is at most maxIconSize bytes long, and that
+// raster icons are images of their media type. The media type itself is
+// checked by the operatorhub/v2 validator.
+func validateIcon(icon v1alpha1.Icon) error {
+	data, err := base64.StdEncoding.DecodeString(icon.Data)
+	if err != nil {
+		return fmt.Errorf("data is not valid base64: %v", err)
+	}
+	if len(data) > maxIconSize {
+		return fmt.Errorf("icon is %d bytes long, more than the maximum of %d", len(data), maxIconSize)
+	}
+	format, ok := map[string]string{"image/gif": "gif", "image/jpeg": "jpeg", "image/png": "png"}[icon.MediaType]
+	if !ok {
+		return nil
+	}
+	_, decoded, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("data is not a valid %s image: %v", icon.MediaType, err)
+	}
+	if decoded != format {
+		return fmt.Errorf("data is a %s image, not %s", decoded, icon.MediaType)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	v1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+func TestOperatorHubMetadataValidator(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 80, 40))))
+	pngIcon := v1alpha1.Icon{MediaType: "image/png", Data: base64.StdEncoding.EncodeToString(buf.Bytes())}
+	description := strings.Repeat("Manages etcd clusters. ", 5)
+
+	validCSV := func() *v1alpha1.ClusterServiceVersion {
+		return &v1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "etcdoperator.v0.9.4",
+				Annotations: map[string]string{
+					"capabilities": "Full Lifecycle",
+					"categories":   "Database",
+					"description":  "Create and maintain highly-available etcd clusters on Kubernetes",
+				},
+			},
+			Spec: v1alpha1.ClusterServiceVersionSpec{
+				Description: description,
+				Icon:        []v1alpha1.Icon{pngIcon},
+			},
+		}
+	}
+
+	for _, tt := range []struct {
+		name     string
+		mutate   func(*v1alpha1.ClusterServiceVersion)
+		errors   []string
+		warnings []string
+	}{
+		{
+			name:   "Valid",
+			mutate: func(*v1alpha1.ClusterServiceVersion) {},
+		},
+		{
+			name: "MissingAnnotations",
+			mutate: func(csv *v1alpha1.ClusterServiceVersion) {
+				csv.Annotations = nil
+			},
+			errors: []string{`csv.Metadata.Annotations["capabilities"] must be set to the capability level of the operator`},
+			warnings: []string{
+				`csv.Metadata.Annotations["categories"] is not set`,
+				`csv.Metadata.Annotations["description"] is not set`,
+			},
+		},
+		{
+			name: "MissingDescription",
+			mutate: func(csv *v1alpha1.ClusterServiceVersion) {
+				csv.Spec.Description = " "
+			},
+			errors: []string{"csv.Spec.Description must be set"},
+		},
+		{
+			name: "ShortDescription",
+			mutate: func(csv *v1alpha1.ClusterServiceVersion) {
+				csv.Spec.Description = "Manages etcd."
+			},
+			warnings: []string{"csv.Spec.Description is 13 characters long, fewer than the recommended 100"},
+		},
+		{
+			name: "LongShortDescription",
+			mutate: func(csv *v1alpha1.ClusterServiceVersion) {
+				csv.Annotations["description"] = description + description
+			},
+			errors: []string{`csv.Metadata.Annotations["description"] is 230 characters long, more than the maximum of 135`},
+		},
+		{
+			name: "IconNotBase64",
+			mutate: func(csv *v1alpha1.ClusterServiceVersion) {
+				csv.Spec.Icon[0].Data = "not base64!"
+			},
+			errors: []string{"csv.Spec.Icon: data is not valid base64: illegal base64 data at input byte 3"},
+		},
+		{
+			name: "IconTooLarge",
+			mutate: func(csv *v1alpha1.ClusterServiceVersion) {
+				csv.Spec.Icon[0] = v1alpha1.Icon{MediaType: "image/svg+xml", Data: base64.StdEncoding.EncodeToString(make([]byte, maxIconSize+1))}
+			},
+			errors: []string{"csv.Spec.Icon: icon is 102401 bytes long, more than the maximum of 102400"},
+		},
+		{
+			name: "IconMediaTypeMismatch",
+			mutate: func(csv *v1alpha1.ClusterServiceVersion) {
+				csv.Spec.Icon[0].MediaType = "image/gif"
+			},
+			errors: []string{"csv.Spec.Icon: data is a png image, not image/gif"},
+		},
+		{
+			name: "IconInvalidImage",
+			mutate: func(csv *v1alpha1.ClusterServiceVersion) {
+				csv.Spec.Icon[0].Data = base64.StdEncoding.EncodeToString([]byte("<svg/>"))
+			},
+			errors: []string{"csv.Spec.Icon: data is not a valid image/png image: image: unknown format"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			csv := validCSV()
+			tt.mutate(csv)
+			results := OperatorHubMetadataValidator.Validate(&manifests.Bundle{Name: csv.Name, CSV: csv})
+			require.Len(t, results, 1)
+
+			var errs, warnings []string
+			for _, e := range results[0].Errors {
+				errs = append(errs, strings.TrimPrefix(e.Detail, "(etcdoperator.v0.9.4) "))
+			}
+			for _, e := range results[0].Warnings {
+				warnings = append(warnings, strings.TrimPrefix(e.Detail, "(etcdoperator.v0.9.4) "))
+			}
+			require.ElementsMatch(t, tt.errors, errs)
+			require.ElementsMatch(t, tt.warnings, warnings)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package mirror
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Mapping maps the images of a source registry or repository prefix to a
+// mirror.
+type Mapping struct {
+	Source string
+	Mirror string
+}
+
+// Mappings is an ordered list of mappings. When several mappings match an
+// image, the mapping with the longest source wins.
+type Mappings []Mapping
+
+// ParseMappings parses mappings of the form "source=mirror", such as
+// "quay.io/example=mirror.example.com/example".
+func ParseMappings(entries []string) (Mappings, error) {
+	var m Mappings
+	for _, e := range entries {
+		source, mirror, ok := strings.Cut(e, "=")
+		source, mirror = strings.TrimSuffix(source, "/"), strings.TrimSuffix(mirror, "/")
+		if !ok || source == "" || mirror == "" {
+			return nil, fmt.Errorf("invalid mirror mapping %q, expected <source>=<mirror>", e)
+		}
+		m = append(m, Mapping{Source: source, Mirror: mirror})
+	}
+	return m, nil
+}
+
+// Mirror returns the reference of img in its mirror, and whether a mapping
+// matched img. Sources match whole path components of the repository of img,
+// so that "quay.io/foo" matches "quay.io/foo/bar:v1" but not "quay.io/foobar:v1".
+func (m Mappings) Mirror(img string) (string, bool) {
+	repo, suffix := splitRepository(img)
+	var match *Mapping
+	for i := range m {
+		if repo != m[i].Source && !strings.HasPrefix(repo, m[i].Source+"/") {
+			continue
+		}
+		if match == nil || len(m[i].Source) > len(match.Source) {
+			match = &m[i]
+		}
+	}
+	if match == nil {
+		return img, false
+	}
+	return match.Mirror + strings.TrimPrefix(repo, match.Source) + suffix, true
+}
+
+// splitRepository splits an image reference into its repository and its
+// ":<tag>", "@<digest>" or ":<tag>@<digest>" suffix.
+func splitRepository(img string) (string, string) {
+	repo, suffix := img, ""
+	if i := strings.Index(repo, "@"); i >= 0 {
+		repo, suffix = repo[:i], repo[i:]
+	}
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo, suffix = repo[:i], repo[i:]+suffix
+	}
+	return repo, suffix
+}
+
+// repositoryMirror is the format of the repositoryDigestMirrors entries of
+// ImageContentSourcePolicies and of the imageDigestMirrors entries of
+// ImageDigestMirrorSets.
+type repositoryMirror struct {
+	Source  string   `json:"source"`
+	Mirrors []string `json:"mirrors"`
+}
+
+// objectMeta is used rather than metav1.ObjectMeta, which would add a null
+// creationTimestamp to the generated manifests.
+type objectMeta struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type imageContentSourcePolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        objectMeta `json:"metadata"`
+	Spec            struct {
+		RepositoryDigestMirrors []repositoryMirror `json:"repositoryDigestMirrors"`
+	} `json:"spec"`
+}
+
+type imageDigestMirrorSet struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        objectMeta `json:"metadata"`
+	Spec            struct {
+		ImageDigestMirrors []repositoryMirror `json:"imageDigestMirrors"`
+	} `json:"spec"`
+}
+
+// catalogLabel is the label that oc sets on the ImageContentSourcePolicies
+// it generates for catalogs.
+const catalogLabel = "operators.openshift.org/catalog"
+
+// GenerateICSP generates an ImageContentSourcePolicy, in the format of
+// `oc adm catalog mirror`, that mirrors the repositories of images.
+func (m Mappings) GenerateICSP(name string, images []string) ([]byte, error) {
+	icsp := imageContentSourcePolicy{
+		TypeMeta: metav1.TypeMeta{APIVersion: "operator.openshift.io/v1alpha1", Kind: "ImageContentSourcePolicy"},
+		Metadata: objectMeta{Name: name, Labels: map[string]string{catalogLabel: "true"}},
+	}
+	icsp.Spec.RepositoryDigestMirrors = m.repositoryMirrors(images)
+	return yaml.Marshal(icsp)
+}
+
+// GenerateIDMS generates an ImageDigestMirrorSet that mirrors the
+// repositories of images.
+func (m Mappings) GenerateIDMS(name string, images []string) ([]byte, error) {
+	idms := imageDigestMirrorSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "config.openshift.io/v1", Kind: "ImageDigestMirrorSet"},
+		Metadata: objectMeta{Name: name, Labels: map[string]string{catalogLabel: "true"}},
+	}
+	idms.Spec.ImageDigestMirrors = m.repositoryMirrors(images)
+	return yaml.Marshal(idms)
+}
+
+// repositoryMirrors returns the mirror of the repository of each image in
+// images that a mapping matches.
+func (m Mappings) repositoryMirrors(images []string) []repositoryMirror {
+	mirrors := map[string]string{}
+	for _, img := range images {
+		repo, _ := splitRepository(img)
+		if mirror, ok := m.Mirror(repo); ok {
+			mirrors[repo] = mirror
+		}
+	}
+	entries := make([]repositoryMirror, 0, len(mirrors))
+	for source, mirror := range mirrors {
+		entries = append(entries, repositoryMirror{Source: source, Mirrors: []string{mirror}})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Source < entries[j].Source
+	})
+	return entries
+}
@@ -0,0 +1,76 @@
+package mirror
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMappings(t *testing.T) {
+	m, err := ParseMappings([]string{"quay.io/example=mirror.example.com/example/", "registry.example.com=localhost:5000"})
+	require.NoError(t, err)
+	require.Equal(t, Mappings{
+		{Source: "quay.io/example", Mirror: "mirror.example.com/example"},
+		{Source: "registry.example.com", Mirror: "localhost:5000"},
+	}, m)
+
+	_, err = ParseMappings([]string{"quay.io/example"})
+	require.EqualError(t, err, `invalid mirror mapping "quay.io/example", expected <source>=<mirror>`)
+	_, err = ParseMappings([]string{"=mirror.example.com"})
+	require.EqualError(t, err, `invalid mirror mapping "=mirror.example.com", expected <source>=<mirror>`)
+}
+
+func TestMappingsMirror(t *testing.T) {
+	m := Mappings{
+		{Source: "quay.io/example", Mirror: "mirror.example.com/example"},
+		{Source: "quay.io/example/operator", Mirror: "mirror.example.com/operator"},
+		{Source: "localhost:5000", Mirror: "mirror.example.com/local"},
+	}
+	type spec struct {
+		image    string
+		expected string
+		matched  bool
+	}
+	specs := []spec{
+		{image: "quay.io/example/bundle:v1", expected: "mirror.example.com/example/bundle:v1", matched: true},
+		{image: "quay.io/example/bundle@sha256:0123", expected: "mirror.example.com/example/bundle@sha256:0123", matched: true},
+		{image: "quay.io/example/operator:v1@sha256:0123", expected: "mirror.example.com/operator:v1@sha256:0123", matched: true},
+		{image: "quay.io/example/operator/sub:v1", expected: "mirror.example.com/operator/sub:v1", matched: true},
+		{image: "localhost:5000/bundle:v1", expected: "mirror.example.com/local/bundle:v1", matched: true},
+		{image: "quay.io/examples/bundle:v1", expected: "quay.io/examples/bundle:v1"},
+		{image: "docker.io/library/busybox", expected: "docker.io/library/busybox"},
+	}
+	for _, s := range specs {
+		t.Run(s.image, func(t *testing.T) {
+			actual, matched := m.Mirror(s.image)
+			require.Equal(t, s.expected, actual)
+			require.Equal(t, s.matched, matched)
+		})
+	}
+}
+
+func TestMappingsGenerateICSP(t *testing.T) {
+	m := Mappings{{Source: "quay.io/example", Mirror: "mirror.example.com/example"}}
+	icsp, err := m.GenerateICSP("example-catalog", []string{
+		"quay.io/example/operator@sha256:0123",
+		"quay.io/example/bundle:v1",
+		"quay.io/example/bundle:v2",
+		"quay.io/other/operator:v1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, `apiVersion: operator.openshift.io/v1alpha1
+kind: ImageContentSourcePolicy
+metadata:
+  labels:
+    operators.openshift.org/catalog: "true"
+  name: example-catalog
+spec:
+  repositoryDigestMirrors:
+  - mirrors:
+    - mirror.example.com/example/bundle
+    source: quay.io/example/bundle
+  - mirrors:
+    - mirror.example.com/example/operator
+    source: quay.io/example/operator
+`, string(icsp))
+}
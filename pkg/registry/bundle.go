@@ -12,12 +12,18 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
 )
 
 const (
 	v1beta1CRDVersion = "v1beta1"
 	v1CRDVersion      = "v1"
 	CRDKind           = "CustomResourceDefinition"
+
+	// plainMediaType is the media type that `opm alpha bundle generate` sets
+	// on bundles without a ClusterServiceVersion.
+	plainMediaType = "plain"
 )
 
 // Scheme is the default instance of runtime.Scheme to which types in the Kubernetes API are already registered.
@@ -106,6 +112,20 @@ func (b *Bundle) Add(obj *unstructured.Unstructured) {
 	b.cacheStale = true
 }
 
+// IsPlain returns true if the bundle's annotations declare a plain media
+// type: its manifests are plain Kubernetes objects, without a
+// ClusterServiceVersion.
+func (b *Bundle) IsPlain() bool {
+	if b.Annotations == nil {
+		return false
+	}
+	switch b.Annotations.MediaType {
+	case plainMediaType, string(property.MediaTypePlainV0):
+		return true
+	}
+	return false
+}
+
 func (b *Bundle) ClusterServiceVersion() (*ClusterServiceVersion, error) {
 	if err := b.cache(); err != nil {
 		return nil, err
@@ -209,6 +229,9 @@ func (b *Bundle) ProvidedAPIs() (map[APIKey]struct{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	if csv == nil {
+		return provided, nil
+	}
 
 	ownedAPIs, _, err := csv.GetApiServiceDefinitions()
 	if err != nil {
@@ -226,6 +249,9 @@ func (b *Bundle) RequiredAPIs() (map[APIKey]struct{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	if csv == nil {
+		return required, nil
+	}
 
 	_, requiredCRDs, err := csv.GetCustomResourceDefintions()
 	if err != nil {
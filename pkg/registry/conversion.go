@@ -22,11 +22,35 @@ func PackageManifestToAPIPackage(manifest *PackageManifest) *api.Package {
 			Message: manifest.Deprecation.Message,
 		}
 	}
+	var icon *api.Icon
+	if manifest.Icon != nil {
+		icon = &api.Icon{
+			Base64Data: manifest.Icon.Base64data,
+			Mediatype:  manifest.Icon.MediaType,
+		}
+	}
 	return &api.Package{
 		Name:               manifest.PackageName,
 		DefaultChannelName: manifest.DefaultChannelName,
 		Channels:           channels,
 		Deprecation:        deprecation,
+		Icon:               icon,
+		Properties:         propertiesToAPIProperties(manifest.Properties),
+	}
+}
+
+// PackageManifestToAPIPackageSummary converts manifest to a package summary,
+// which omits the icon of the package.
+func PackageManifestToAPIPackageSummary(manifest *PackageManifest) *api.PackageSummary {
+	channels := []*api.Channel{}
+	for _, c := range manifest.Channels {
+		channels = append(channels, PackageChannelToAPIChannel(&c))
+	}
+	return &api.PackageSummary{
+		Name:               manifest.PackageName,
+		DefaultChannelName: manifest.DefaultChannelName,
+		Channels:           channels,
+		Deprecated:         manifest.Deprecation != nil,
 	}
 }
 
@@ -41,7 +65,23 @@ func PackageChannelToAPIChannel(channel *PackageChannel) *api.Channel {
 		Name:        channel.Name,
 		CsvName:     channel.CurrentCSVName,
 		Deprecation: deprecation,
+		HeadVersion: channel.HeadVersion,
+		BundleCount: int32(channel.BundleCount),
+		Priority:    int32(channel.Priority),
+		Properties:  propertiesToAPIProperties(channel.Properties),
+	}
+}
+
+func propertiesToAPIProperties(props []Property) []*api.Property {
+	// nolint:prealloc
+	var out []*api.Property
+	for _, p := range props {
+		out = append(out, &api.Property{
+			Type:  p.Type,
+			Value: string(p.Value),
+		})
 	}
+	return out
 }
 
 func ChannelEntryToAPIChannelEntry(entry *ChannelEntry) *api.ChannelEntry {
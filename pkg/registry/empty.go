@@ -60,6 +60,10 @@ func (EmptyQuery) GetBundleThatProvides(ctx context.Context, group, version, kin
 	return nil, errors.New("empty querier: cannot get bundle that provides")
 }
 
+func (EmptyQuery) GetUpgradeGraph(ctx context.Context, pkgName string) (*api.UpgradeGraph, error) {
+	return nil, errors.New("empty querier: cannot get upgrade graph")
+}
+
 func (EmptyQuery) ListImages(ctx context.Context) ([]string, error) {
 	return nil, errors.New("empty querier: cannot get image list")
 }
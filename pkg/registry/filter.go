@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"strings"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+)
+
+// CategoriesAnnotation is the CSV annotation that lists the categories of an
+// operator, separated by commas.
+const CategoriesAnnotation = "categories"
+
+// PackageFilter selects the packages listed by a search. Packages are matched
+// against the keywords and categories of the CSV at the head of their default
+// channel. A package matches if it matches every field that is set.
+type PackageFilter struct {
+	// NameContains matches the packages whose name contains it, ignoring
+	// case.
+	NameContains string
+	// Keywords match the packages that have all of them as keywords,
+	// ignoring case.
+	Keywords []string
+	// Categories match the packages that are in all of them, ignoring case.
+	Categories []string
+}
+
+// PackageFilterFromAPI converts the filter of a ListPackages request.
+func PackageFilterFromAPI(filter *api.PackageFilter) PackageFilter {
+	return PackageFilter{
+		NameContains: filter.GetNameContains(),
+		Keywords:     filter.GetKeywords(),
+		Categories:   filter.GetCategories(),
+	}
+}
+
+// IsEmpty returns true if f matches every package.
+func (f PackageFilter) IsEmpty() bool {
+	return f.NameContains == "" && len(f.Keywords) == 0 && len(f.Categories) == 0
+}
+
+// Matches returns true if the package name, whose head CSV has keywords and
+// categories, matches f.
+func (f PackageFilter) Matches(name string, keywords, categories []string) bool {
+	if !strings.Contains(strings.ToLower(name), strings.ToLower(f.NameContains)) {
+		return false
+	}
+	return containsAllFold(keywords, f.Keywords) && containsAllFold(categories, f.Categories)
+}
+
+// ParseCategories returns the categories listed by the value of the
+// CategoriesAnnotation of a CSV.
+func ParseCategories(annotation string) []string {
+	var categories []string
+	for _, c := range strings.Split(annotation, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			categories = append(categories, c)
+		}
+	}
+	return categories
+}
+
+func containsAllFold(values, wanted []string) bool {
+	for _, w := range wanted {
+		found := false
+		for _, v := range values {
+			if strings.EqualFold(v, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageFilterMatches(t *testing.T) {
+	var (
+		keywords   = []string{"etcd", "key value", "database"}
+		categories = ParseCategories("Database, Big Data,")
+	)
+	require.Equal(t, []string{"Database", "Big Data"}, categories)
+
+	for _, tt := range []struct {
+		name    string
+		filter  PackageFilter
+		matches bool
+	}{
+		{name: "Empty", filter: PackageFilter{}, matches: true},
+		{name: "NameContains", filter: PackageFilter{NameContains: "CD"}, matches: true},
+		{name: "NameDoesNotContain", filter: PackageFilter{NameContains: "kafka"}, matches: false},
+		{name: "AllKeywords", filter: PackageFilter{Keywords: []string{"Key Value", "etcd"}}, matches: true},
+		{name: "MissingKeyword", filter: PackageFilter{Keywords: []string{"etcd", "monitoring"}}, matches: false},
+		{name: "PartialKeyword", filter: PackageFilter{Keywords: []string{"key"}}, matches: false},
+		{name: "Categories", filter: PackageFilter{Categories: []string{"big data"}}, matches: true},
+		{name: "MissingCategory", filter: PackageFilter{NameContains: "etcd", Categories: []string{"Monitoring"}}, matches: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.matches, tt.filter.Matches("etcd", keywords, categories))
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/blang/semver/v4"
+
+	libsemver "github.com/operator-framework/operator-registry/pkg/lib/semver"
+)
+
+// GraphStrategy computes the upgrade edges between the bundles of a channel.
+// It lets the upgrade graphs built when bundles are added to an index database
+// by a DirectoryPopulator, and when the channels of a semver template are
+// rendered, follow rules other than the built-in ones, such as ordering
+// bundles by build number.
+type GraphStrategy interface {
+	// Edges returns the edges of the bundles of a channel, keyed by bundle
+	// name. Bundles are sorted by ascending version, with versions that only
+	// differ by build metadata ordered by their build-ids. Bundles that have
+	// no edges may be left out.
+	Edges(channel string, bundles []GraphBundle) (map[string]GraphEdges, error)
+}
+
+// GraphStrategyFunc is a GraphStrategy that is a function.
+type GraphStrategyFunc func(channel string, bundles []GraphBundle) (map[string]GraphEdges, error)
+
+func (f GraphStrategyFunc) Edges(channel string, bundles []GraphBundle) (map[string]GraphEdges, error) {
+	return f(channel, bundles)
+}
+
+// GraphBundle is a bundle of a channel whose edges a GraphStrategy computes.
+type GraphBundle struct {
+	Name    string
+	Version semver.Version
+}
+
+// GraphEdges are the upgrade edges of a bundle: the bundle it replaces and the
+// bundles it skips.
+type GraphEdges struct {
+	Replaces string
+	Skips    []string
+}
+
+// ChannelEdges sorts the bundles of a channel and returns the edges that
+// strategy computes for them. It fails if strategy returns edges of bundles
+// that are not in the channel or edges to them.
+func ChannelEdges(strategy GraphStrategy, channel string, bundles []GraphBundle) (map[string]GraphEdges, error) {
+	sorted := make([]GraphBundle, len(bundles))
+	copy(sorted, bundles)
+	var sortErr error
+	sort.SliceStable(sorted, func(i, j int) bool {
+		c, err := libsemver.BuildIdCompare(sorted[i].Version, sorted[j].Version)
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+		return c < 0
+	})
+	if sortErr != nil {
+		return nil, fmt.Errorf("sort bundles of channel %q: %v", channel, sortErr)
+	}
+
+	edges, err := strategy.Edges(channel, sorted)
+	if err != nil {
+		return nil, fmt.Errorf("compute edges of channel %q: %v", channel, err)
+	}
+
+	names := make(map[string]struct{}, len(sorted))
+	for _, b := range sorted {
+		names[b.Name] = struct{}{}
+	}
+	for name, e := range edges {
+		if _, ok := names[name]; !ok {
+			return nil, fmt.Errorf("channel %q: edges of unknown bundle %q", channel, name)
+		}
+		for _, to := range append([]string{e.Replaces}, e.Skips...) {
+			if to == "" {
+				continue
+			}
+			if _, ok := names[to]; !ok {
+				return nil, fmt.Errorf("channel %q: bundle %q has an edge to unknown bundle %q", channel, name, to)
+			}
+			if to == name {
+				return nil, fmt.Errorf("channel %q: bundle %q has an edge to itself", channel, name)
+			}
+		}
+	}
+	return edges, nil
+}
+
+// channelFromEdges builds the graph of a channel from the edges of its
+// bundles. Its head is the only bundle that no other bundle replaces or skips,
+// and its nodes are the bundles of the head's replaces chain. Bundles that are
+// skipped but not in the chain are left out of the nodes, so that they are
+// added to the channel as replaced by the bundles that skip them.
+func channelFromEdges(channel string, keys map[string]BundleKey, edges map[string]GraphEdges) (Channel, error) {
+	heads := make(map[string]struct{}, len(keys))
+	for name := range keys {
+		heads[name] = struct{}{}
+	}
+	for _, e := range edges {
+		delete(heads, e.Replaces)
+		for _, s := range e.Skips {
+			delete(heads, s)
+		}
+	}
+	if len(heads) != 1 {
+		names := make([]string, 0, len(heads))
+		for name := range heads {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return Channel{}, fmt.Errorf("channel %q must have exactly one head, found %v", channel, names)
+	}
+	var head string
+	for name := range heads {
+		head = name
+	}
+
+	chain := map[string]struct{}{}
+	for name := head; name != ""; name = edges[name].Replaces {
+		if _, ok := chain[name]; ok {
+			return Channel{}, fmt.Errorf("channel %q: replaces chain of %q has a cycle", channel, head)
+		}
+		chain[name] = struct{}{}
+	}
+
+	c := Channel{
+		Head:  keys[head],
+		Nodes: make(map[BundleKey]map[BundleKey]struct{}, len(chain)),
+	}
+	for name := range chain {
+		replaces := map[BundleKey]struct{}{}
+		e := edges[name]
+		if e.Replaces != "" {
+			replaces[keys[e.Replaces]] = struct{}{}
+		}
+		for _, s := range e.Skips {
+			if _, ok := chain[s]; ok && s != e.Replaces {
+				return Channel{}, fmt.Errorf("channel %q: bundle %q skips %q, which is in the replaces chain of the channel", channel, name, s)
+			}
+			replaces[keys[s]] = struct{}{}
+		}
+		c.Nodes[keys[name]] = replaces
+	}
+	for name := range keys {
+		if _, ok := chain[name]; ok {
+			continue
+		}
+		skipped := false
+		for _, e := range edges {
+			for _, s := range e.Skips {
+				if s == name {
+					skipped = true
+				}
+			}
+		}
+		if !skipped {
+			return Channel{}, fmt.Errorf("channel %q: bundle %q is not in the replaces chain of %q and is not skipped", channel, name, head)
+		}
+	}
+	return c, nil
+}
@@ -57,6 +57,9 @@ type GRPCQuery interface {
 
 	// Get the the latest bundle that provides the API in a default channel
 	GetBundleThatProvides(ctx context.Context, group, version, kind string) (*api.Bundle, error)
+
+	// Get the upgrade graph of a package
+	GetUpgradeGraph(ctx context.Context, pkgName string) (*api.UpgradeGraph, error)
 }
 
 type Query interface {
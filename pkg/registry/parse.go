@@ -10,6 +10,8 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
 )
 
 type bundleParser struct {
@@ -28,20 +30,22 @@ func (b *bundleParser) Parse(root fs.FS) (*Bundle, error) {
 		return nil, fmt.Errorf("filesystem is nil")
 	}
 
+	// The metadata is added first, since the media type it declares
+	// determines how the manifests are parsed.
 	bundle := &Bundle{}
-	manifests, err := fs.Sub(root, "manifests")
+	metadata, err := fs.Sub(root, "metadata")
 	if err != nil {
-		return nil, fmt.Errorf("error opening manifests directory: %s", err)
+		return nil, fmt.Errorf("error opening metadata directory: %s", err)
 	}
-	if err := b.addManifests(manifests, bundle); err != nil {
+	if err := b.addMetadata(metadata, bundle); err != nil {
 		return nil, err
 	}
 
-	metadata, err := fs.Sub(root, "metadata")
+	manifests, err := fs.Sub(root, "manifests")
 	if err != nil {
-		return nil, fmt.Errorf("error opening metadata directory: %s", err)
+		return nil, fmt.Errorf("error opening manifests directory: %s", err)
 	}
-	if err := b.addMetadata(metadata, bundle); err != nil {
+	if err := b.addManifests(manifests, bundle); err != nil {
 		return nil, err
 	}
 
@@ -96,6 +100,10 @@ func (b *bundleParser) addManifests(manifests fs.FS, bundle *Bundle) error {
 		return fmt.Errorf("no bundle objects found")
 	}
 
+	if bundle.IsPlain() {
+		return setPlainBundleVersion(bundle)
+	}
+
 	csv, err := bundle.ClusterServiceVersion()
 	if err != nil {
 		return err
@@ -112,6 +120,28 @@ func (b *bundleParser) addManifests(manifests fs.FS, bundle *Bundle) error {
 	return nil
 }
 
+// setPlainBundleVersion sets the name and version of a plain bundle, which has
+// no CSV to take them from, from the olm.package property of its properties
+// file for the bundle's package. The bundle is named <package>.v<version>.
+func setPlainBundleVersion(bundle *Bundle) error {
+	for _, p := range bundle.Properties {
+		if p.Type != PackageType {
+			continue
+		}
+		var pkg PackageProperty
+		if err := json.Unmarshal(p.Value, &pkg); err != nil {
+			return fmt.Errorf("error parsing %s property of plain bundle: %s", PackageType, err)
+		}
+		if pkg.PackageName != bundle.Package || pkg.Version == "" {
+			continue
+		}
+		bundle.version = pkg.Version
+		bundle.Name = fmt.Sprintf("%s.v%s", bundle.Package, pkg.Version)
+		return nil
+	}
+	return fmt.Errorf("plain bundle has no %s property declaring the version of package %q", PackageType, bundle.Package)
+}
+
 // addManifests adds the result of parsing the metadata directory to a bundle.
 func (b *bundleParser) addMetadata(metadata fs.FS, bundle *Bundle) error {
 	files, err := fs.ReadDir(metadata, ".")
@@ -177,22 +207,30 @@ func (b *bundleParser) addMetadata(metadata fs.FS, bundle *Bundle) error {
 }
 
 func (b *bundleParser) derivedProperties(bundle *Bundle) ([]Property, error) {
-	// Add properties from CSV annotations
-	csv, err := bundle.ClusterServiceVersion()
-	if err != nil {
-		return nil, fmt.Errorf("error getting csv: %s", err)
-	}
-	if csv == nil {
-		return nil, fmt.Errorf("bundle missing csv")
-	}
-
 	// nolint:prealloc
 	var derived []Property
-	if len(csv.GetAnnotations()) > 0 {
-		properties, ok := csv.GetAnnotations()[PropertyKey]
-		if ok {
-			if err := json.Unmarshal([]byte(properties), &derived); err != nil {
-				b.log.Warnf("failed to unmarshal csv annotation properties: %s", err)
+	if bundle.IsPlain() {
+		value, err := json.Marshal(property.MediaTypePlainV0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal mediatype property: %s", err)
+		}
+		derived = append(derived, Property{Type: property.TypeBundleMediaType, Value: value})
+	} else {
+		// Add properties from CSV annotations
+		csv, err := bundle.ClusterServiceVersion()
+		if err != nil {
+			return nil, fmt.Errorf("error getting csv: %s", err)
+		}
+		if csv == nil {
+			return nil, fmt.Errorf("bundle missing csv")
+		}
+
+		if len(csv.GetAnnotations()) > 0 {
+			properties, ok := csv.GetAnnotations()[PropertyKey]
+			if ok {
+				if err := json.Unmarshal([]byte(properties), &derived); err != nil {
+					b.log.Warnf("failed to unmarshal csv annotation properties: %s", err)
+				}
 			}
 		}
 	}
@@ -374,6 +374,89 @@ properties:
 				},
 			},
 		},
+		{
+			name: "PlainBundle",
+			root: func() fstest.MapFS {
+				r := bundleFS()
+				delete(r, "manifests/csv.yaml")
+				r["manifests/configmap.yaml"] = &fstest.MapFile{
+					Data: format(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+					`),
+				}
+				r["metadata/annotations.yaml"] = &fstest.MapFile{
+					Data: format(`
+annotations:
+  operators.operatorframework.io.bundle.mediatype.v1: "plain+v0"
+  operators.operatorframework.io.bundle.package.v1: "foo"
+  operators.operatorframework.io.bundle.channels.v1: "alpha,stable"
+					`),
+				}
+				r["metadata/properties.yaml"] = &fstest.MapFile{
+					Data: format(`
+properties:
+- type: olm.package
+  value:
+    packageName: foo
+    version: 1.0.0
+					`),
+				}
+
+				return r
+			}(),
+			bundle: &Bundle{
+				Name:     "foo.v1.0.0",
+				Package:  "foo",
+				Channels: []string{"alpha", "stable"},
+				Properties: []Property{
+					{
+						Type: PackageType,
+						Value: mustMarshal(t, PackageProperty{
+							PackageName: "foo",
+							Version:     "1.0.0",
+						}),
+					},
+					{
+						Type: GVKType,
+						Value: mustMarshal(t, GVKProperty{
+							Group:   "test.io",
+							Version: "v1",
+							Kind:    "Foo",
+						}),
+					},
+					{
+						Type:  "olm.bundle.mediatype",
+						Value: mustMarshal(t, "plain+v0"),
+					},
+				},
+				Annotations: &Annotations{
+					PackageName: "foo",
+					Channels:    "alpha,stable",
+					MediaType:   "plain+v0",
+				},
+			},
+		},
+		{
+			name: "PlainBundleWithoutPackageProperty",
+			root: func() fstest.MapFS {
+				r := bundleFS()
+				delete(r, "manifests/csv.yaml")
+				r["metadata/annotations.yaml"] = &fstest.MapFile{
+					Data: format(`
+annotations:
+  operators.operatorframework.io.bundle.mediatype.v1: "plain"
+  operators.operatorframework.io.bundle.package.v1: "foo"
+  operators.operatorframework.io.bundle.channels.v1: "alpha,stable"
+					`),
+				}
+
+				return r
+			}(),
+			err: true,
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			parser := newBundleParser(logrus.NewEntry(logrus.StandardLogger()))
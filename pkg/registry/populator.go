@@ -39,6 +39,45 @@ func NewDirectoryPopulator(loader Load, graphLoader GraphLoader, querier Query,
 }
 
 func (i *DirectoryPopulator) Populate(mode Mode) error {
+	imagesToAdd, err := i.imageInputs()
+	if err != nil {
+		return err
+	}
+
+	err = i.loadManifests(imagesToAdd, mode)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PopulateWithStrategy loads the bundles like Populate, but builds the upgrade
+// graphs of their channels with strategy rather than with a built-in mode.
+func (i *DirectoryPopulator) PopulateWithStrategy(strategy GraphStrategy) error {
+	imagesToAdd, err := i.imageInputs()
+	if err != nil {
+		return err
+	}
+
+	if err := i.globalSanityCheck(imagesToAdd); err != nil {
+		return err
+	}
+
+	for _, image := range imagesToAdd {
+		if err := i.loadManifestsStrategy(image.Bundle, strategy); err != nil {
+			return err
+		}
+	}
+
+	if err := i.loader.ClearNonHeadBundles(); err != nil {
+		return fmt.Errorf("Error deleting previous bundles: %s", err)
+	}
+
+	return nil
+}
+
+func (i *DirectoryPopulator) imageInputs() ([]*ImageInput, error) {
 	var errs []error
 	imagesToAdd := make([]*ImageInput, 0)
 	for to, from := range i.imageDirMap {
@@ -52,15 +91,9 @@ func (i *DirectoryPopulator) Populate(mode Mode) error {
 	}
 
 	if len(errs) > 0 {
-		return utilerrors.NewAggregate(errs)
+		return nil, utilerrors.NewAggregate(errs)
 	}
-
-	err := i.loadManifests(imagesToAdd, mode)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return imagesToAdd, nil
 }
 
 func (i *DirectoryPopulator) globalSanityCheck(imagesToAdd []*ImageInput) error {
@@ -264,6 +297,83 @@ func (i *DirectoryPopulator) loadManifestsSemver(bundle *Bundle, skippatch bool)
 	return nil
 }
 
+// loadManifestsStrategy adds bundle to the graph of its package and rebuilds
+// the graphs of its channels from the edges that strategy computes for them.
+func (i *DirectoryPopulator) loadManifestsStrategy(bundle *Bundle, strategy GraphStrategy) error {
+	graph, err := i.graphLoader.Generate(bundle.Package)
+	if err != nil && !errors.Is(err, ErrPackageNotInDatabase) {
+		return err
+	}
+	if graph.Name == "" {
+		graph.Name = bundle.Package
+	}
+	if graph.Channels == nil {
+		graph.Channels = map[string]Channel{}
+	}
+
+	rawVersion, err := bundle.Version()
+	if err != nil {
+		return fmt.Errorf("unable to extract bundle version from bundle %s: %v", bundle.BundleImage, err)
+	}
+	version, err := semver.Parse(rawVersion)
+	if err != nil {
+		return fmt.Errorf("bundle version %s is not valid: %v", rawVersion, err)
+	}
+	bundleKey := BundleKey{
+		CsvName:    bundle.Name,
+		Version:    version.String(),
+		BundlePath: bundle.BundleImage,
+	}
+
+	if bundle.Annotations != nil {
+		if bundle.Annotations.DefaultChannelName != "" {
+			graph.DefaultChannel = bundle.Annotations.DefaultChannelName
+		}
+		if graph.DefaultChannel == "" {
+			graph.DefaultChannel = bundle.Annotations.SelectDefaultChannel()
+		}
+	}
+	if graph.DefaultChannel == "" {
+		return fmt.Errorf("default channel is missing and can't be inferred")
+	}
+
+	for _, channel := range bundle.Channels {
+		keys := map[string]BundleKey{}
+		for node, replaces := range graph.Channels[channel].Nodes {
+			keys[node.CsvName] = node
+			for r := range replaces {
+				if _, ok := keys[r.CsvName]; !ok && r.Version != "" {
+					keys[r.CsvName] = r
+				}
+			}
+		}
+		keys[bundle.Name] = bundleKey
+
+		bundles := make([]GraphBundle, 0, len(keys))
+		for name, key := range keys {
+			v, err := semver.Parse(key.Version)
+			if err != nil {
+				return fmt.Errorf("unable to parse version %s of bundle %s: %v", key.Version, name, err)
+			}
+			bundles = append(bundles, GraphBundle{Name: name, Version: v})
+		}
+
+		edges, err := ChannelEdges(strategy, channel, bundles)
+		if err != nil {
+			return err
+		}
+		if graph.Channels[channel], err = channelFromEdges(channel, keys, edges); err != nil {
+			return err
+		}
+	}
+
+	if err := i.loader.AddBundleSemver(graph, bundle); err != nil {
+		return fmt.Errorf("error loading bundle %s into db: %s", bundle.Name, err)
+	}
+
+	return nil
+}
+
 // loadOperatorBundle adds the package information to the loader's store
 // nolint:unused
 func (i *DirectoryPopulator) loadOperatorBundle(manifest PackageManifest, bundle *Bundle) error {
@@ -119,14 +119,20 @@ func TestQuerierForImage(t *testing.T) {
 			{
 				Name:           "alpha",
 				CurrentCSVName: "etcdoperator.v0.9.2",
+				HeadVersion:    "0.9.2",
+				BundleCount:    2,
 			},
 			{
 				Name:           "beta",
 				CurrentCSVName: "etcdoperator.v0.9.0",
+				HeadVersion:    "0.9.0",
+				BundleCount:    1,
 			},
 			{
 				Name:           "stable",
 				CurrentCSVName: "etcdoperator.v0.9.2",
+				HeadVersion:    "0.9.2",
+				BundleCount:    2,
 			},
 		},
 	}, etcdPackage)
@@ -753,6 +759,88 @@ func TestDirectoryPopulator(t *testing.T) {
 	require.Contains(t, err.Error(), fmt.Sprintf("Invalid bundle %s, replaces nonexistent bundle %s", "prometheusoperator.0.22.2", "prometheusoperator.0.15.0"))
 }
 
+func TestDirectoryPopulatorWithStrategy(t *testing.T) {
+	// headSkipsAll makes the bundle with the highest version the head of the
+	// channel, skipping every other bundle
+	headSkipsAll := registry.GraphStrategyFunc(func(_ string, bundles []registry.GraphBundle) (map[string]registry.GraphEdges, error) {
+		head := bundles[len(bundles)-1]
+		var skips []string
+		for _, b := range bundles[:len(bundles)-1] {
+			skips = append(skips, b.Name)
+		}
+		return map[string]registry.GraphEdges{head.Name: {Skips: skips}}, nil
+	})
+	unknownEdge := registry.GraphStrategyFunc(func(_ string, bundles []registry.GraphBundle) (map[string]registry.GraphEdges, error) {
+		return map[string]registry.GraphEdges{bundles[0].Name: {Replaces: "missing"}}, nil
+	})
+
+	for _, tt := range []struct {
+		name      string
+		strategy  registry.GraphStrategy
+		expected  []registry.ChannelEntry
+		expectErr string
+	}{
+		{
+			name:     "HeadSkipsAll",
+			strategy: headSkipsAll,
+			expected: []registry.ChannelEntry{
+				{PackageName: "prometheus", ChannelName: "preview", BundleName: "prometheusoperator.0.22.2"},
+				{PackageName: "prometheus", ChannelName: "preview", BundleName: "prometheusoperator.0.22.2", Replaces: "prometheusoperator.0.14.0"},
+				{PackageName: "prometheus", ChannelName: "preview", BundleName: "prometheusoperator.0.22.2", Replaces: "prometheusoperator.0.15.0"},
+				{PackageName: "prometheus", ChannelName: "preview", BundleName: "prometheusoperator.0.14.0"},
+				{PackageName: "prometheus", ChannelName: "preview", BundleName: "prometheusoperator.0.15.0"},
+				{PackageName: "prometheus", ChannelName: "stable", BundleName: "prometheusoperator.0.15.0"},
+			},
+		},
+		{
+			name:      "UnknownBundle",
+			strategy:  unknownEdge,
+			expectErr: `channel "preview": bundle "prometheusoperator.0.14.0" has an edge to unknown bundle "missing"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db, cleanup := CreateTestDB(t)
+			defer cleanup()
+
+			loader, err := sqlite.NewSQLLiteLoader(db)
+			require.NoError(t, err)
+			require.NoError(t, loader.Migrate(context.TODO()))
+
+			graphLoader, err := sqlite.NewSQLGraphLoaderFromDB(db)
+			require.NoError(t, err)
+
+			query := sqlite.NewSQLLiteQuerierFromDb(db)
+
+			for _, name := range []string{"prometheus.0.14.0", "prometheus.0.15.0", "prometheus.0.22.2"} {
+				err = registry.NewDirectoryPopulator(
+					loader,
+					graphLoader,
+					query,
+					map[image.Reference]string{image.SimpleReference("quay.io/test/" + name): "../../bundles/" + name},
+					nil).PopulateWithStrategy(tt.strategy)
+				if tt.expectErr != "" {
+					require.EqualError(t, err, tt.expectErr)
+					return
+				}
+				require.NoError(t, err)
+			}
+
+			entries, err := query.GetChannelEntriesFromPackage(context.TODO(), "prometheus")
+			require.NoError(t, err)
+			actual := make([]registry.ChannelEntry, 0, len(entries))
+			for _, e := range entries {
+				actual = append(actual, registry.ChannelEntry{
+					PackageName: e.PackageName,
+					ChannelName: e.ChannelName,
+					BundleName:  e.BundleName,
+					Replaces:    e.Replaces,
+				})
+			}
+			require.ElementsMatch(t, tt.expected, actual)
+		})
+	}
+}
+
 func TestDeprecateBundle(t *testing.T) {
 	type args struct {
 		bundles []string
@@ -0,0 +1,346 @@
+// Package registrytest provides a conformance suite for implementations of
+// registry.GRPCQuery, like custom caches or proxies, to check that they answer
+// queries with the same semantics as the sqlite and file-based catalog
+// queriers.
+package registrytest
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+//go:embed catalog
+var catalogFS embed.FS
+
+// Catalog returns the file-based catalog that the querier passed to
+// Conformance must serve. It holds an etcd package, whose channels exercise
+// replaces, skips and skip ranges, and a prometheus package that depends on
+// it.
+func Catalog() fs.FS {
+	sub, err := fs.Sub(catalogFS, "catalog")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+const (
+	etcdGroup   = "etcd.database.coreos.com"
+	etcdVersion = "v1beta2"
+	etcdCluster = "EtcdCluster"
+)
+
+// Conformance runs, as subtests of t, the queries of registry.GRPCQuery
+// against querier, which must serve the catalog returned by Catalog, and
+// checks that their results match those of the sqlite and file-based catalog
+// queriers.
+//
+// Only the bundle fields that all queriers populate are compared: manifests,
+// CSV JSON and the properties of bundles are not.
+//
+// GetBundleThatReplaces is checked with registry.DefaultReplacementPolicy,
+// which querier must use.
+func Conformance(t *testing.T, querier registry.GRPCQuery) {
+	ctx := context.Background()
+
+	t.Run("ListPackages", func(t *testing.T) {
+		packages, err := querier.ListPackages(ctx)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"etcd", "prometheus"}, packages)
+	})
+
+	t.Run("GetPackage", func(t *testing.T) {
+		pkg, err := querier.GetPackage(ctx, "etcd")
+		require.NoError(t, err)
+		require.Equal(t, "etcd", pkg.PackageName)
+		require.Equal(t, "alpha", pkg.DefaultChannelName)
+		require.ElementsMatch(t, []registry.PackageChannel{
+			{Name: "alpha", CurrentCSVName: "etcdoperator.v0.9.2", HeadVersion: "0.9.2", BundleCount: 3},
+			{Name: "beta", CurrentCSVName: "etcdoperator.v0.9.0", HeadVersion: "0.9.0", BundleCount: 2},
+			{Name: "stable", CurrentCSVName: "etcdoperator.v0.9.2", HeadVersion: "0.9.2", BundleCount: 3},
+		}, pkg.Channels)
+
+		_, err = querier.GetPackage(ctx, "missing")
+		require.Error(t, err)
+	})
+
+	t.Run("GetBundle", func(t *testing.T) {
+		for _, expected := range []*api.Bundle{
+			etcdV0_9_2("alpha"),
+			etcdV0_9_2("stable"),
+			etcdV0_9_0("beta"),
+			prometheusV0_22_2(),
+		} {
+			b, err := querier.GetBundle(ctx, expected.PackageName, expected.ChannelName, expected.CsvName)
+			require.NoError(t, err)
+			requireBundle(t, expected, b)
+		}
+
+		_, err := querier.GetBundle(ctx, "etcd", "beta", "etcdoperator.v0.9.2")
+		require.Error(t, err)
+		_, err = querier.GetBundle(ctx, "missing", "alpha", "etcdoperator.v0.9.2")
+		require.Error(t, err)
+	})
+
+	t.Run("GetBundleForChannel", func(t *testing.T) {
+		b, err := querier.GetBundleForChannel(ctx, "etcd", "beta")
+		require.NoError(t, err)
+		require.Equal(t, "etcdoperator.v0.9.0", b.CsvName)
+
+		_, err = querier.GetBundleForChannel(ctx, "etcd", "missing")
+		require.Error(t, err)
+	})
+
+	t.Run("GetChannelEntriesThatReplace", func(t *testing.T) {
+		entries, err := querier.GetChannelEntriesThatReplace(ctx, "etcdoperator.v0.6.1")
+		require.NoError(t, err)
+		requireChannelEntries(t, []registry.ChannelEntry{
+			{PackageName: "etcd", ChannelName: "alpha", BundleName: "etcdoperator.v0.9.0", Replaces: "etcdoperator.v0.6.1"},
+			{PackageName: "etcd", ChannelName: "beta", BundleName: "etcdoperator.v0.9.0", Replaces: "etcdoperator.v0.6.1"},
+			{PackageName: "etcd", ChannelName: "stable", BundleName: "etcdoperator.v0.9.0", Replaces: "etcdoperator.v0.6.1"},
+		}, entries)
+	})
+
+	t.Run("GetBundleThatReplaces", func(t *testing.T) {
+		b, err := querier.GetBundleThatReplaces(ctx, "etcdoperator.v0.9.0", "etcd", "alpha")
+		require.NoError(t, err)
+		requireBundle(t, etcdV0_9_2("alpha"), b)
+
+		// etcdoperator.v0.9.1 is not a bundle of the catalog, but is skipped
+		// by etcdoperator.v0.9.2.
+		b, err = querier.GetBundleThatReplaces(ctx, "etcdoperator.v0.9.1", "etcd", "alpha")
+		require.NoError(t, err)
+		requireBundle(t, etcdV0_9_2("alpha"), b)
+
+		_, err = querier.GetBundleThatReplaces(ctx, "etcdoperator.v0.9.0", "etcd", "beta")
+		require.Error(t, err)
+	})
+
+	t.Run("GetChannelEntriesThatProvide", func(t *testing.T) {
+		entries, err := querier.GetChannelEntriesThatProvide(ctx, etcdGroup, etcdVersion, etcdCluster)
+		require.NoError(t, err)
+		var expected []registry.ChannelEntry
+		for _, channel := range []string{"alpha", "beta", "stable"} {
+			expected = append(expected,
+				registry.ChannelEntry{PackageName: "etcd", ChannelName: channel, BundleName: "etcdoperator.v0.6.1"},
+				registry.ChannelEntry{PackageName: "etcd", ChannelName: channel, BundleName: "etcdoperator.v0.9.0", Replaces: "etcdoperator.v0.6.1"},
+			)
+			if channel != "beta" {
+				expected = append(expected,
+					registry.ChannelEntry{PackageName: "etcd", ChannelName: channel, BundleName: "etcdoperator.v0.9.2", Replaces: "etcdoperator.v0.9.0"},
+					registry.ChannelEntry{PackageName: "etcd", ChannelName: channel, BundleName: "etcdoperator.v0.9.2", Replaces: "etcdoperator.v0.9.1"},
+				)
+			}
+		}
+		requireChannelEntries(t, expected, entries)
+	})
+
+	t.Run("GetLatestChannelEntriesThatProvide", func(t *testing.T) {
+		entries, err := querier.GetLatestChannelEntriesThatProvide(ctx, etcdGroup, etcdVersion, etcdCluster)
+		require.NoError(t, err)
+		requireChannelEntries(t, []registry.ChannelEntry{
+			{PackageName: "etcd", ChannelName: "alpha", BundleName: "etcdoperator.v0.9.2", Replaces: "etcdoperator.v0.9.0"},
+			{PackageName: "etcd", ChannelName: "beta", BundleName: "etcdoperator.v0.9.0", Replaces: "etcdoperator.v0.6.1"},
+			{PackageName: "etcd", ChannelName: "stable", BundleName: "etcdoperator.v0.9.2", Replaces: "etcdoperator.v0.9.0"},
+		}, entries)
+	})
+
+	t.Run("GetBundleThatProvides", func(t *testing.T) {
+		b, err := querier.GetBundleThatProvides(ctx, etcdGroup, etcdVersion, etcdCluster)
+		require.NoError(t, err)
+		requireBundle(t, etcdV0_9_2("alpha"), b)
+
+		_, err = querier.GetBundleThatProvides(ctx, etcdGroup, etcdVersion, "Missing")
+		require.Error(t, err)
+	})
+
+	expectedBundles := []string{
+		"alpha/etcdoperator.v0.6.1",
+		"alpha/etcdoperator.v0.9.0",
+		"alpha/etcdoperator.v0.9.2",
+		"beta/etcdoperator.v0.6.1",
+		"beta/etcdoperator.v0.9.0",
+		"preview/prometheusoperator.0.22.2",
+		"stable/etcdoperator.v0.6.1",
+		"stable/etcdoperator.v0.9.0",
+		"stable/etcdoperator.v0.9.2",
+	}
+
+	t.Run("ListBundles", func(t *testing.T) {
+		bundles, err := querier.ListBundles(ctx)
+		require.NoError(t, err)
+		require.ElementsMatch(t, expectedBundles, channelBundles(bundles))
+		requireBundleIn(t, etcdV0_9_2("stable"), bundles)
+	})
+
+	t.Run("SendBundles", func(t *testing.T) {
+		var sender bundleSender
+		require.NoError(t, querier.SendBundles(ctx, &sender))
+		require.ElementsMatch(t, expectedBundles, channelBundles(sender))
+		requireBundleIn(t, etcdV0_9_2("stable"), sender)
+	})
+
+	t.Run("GetUpgradeGraph", func(t *testing.T) {
+		graph, err := querier.GetUpgradeGraph(ctx, "etcd")
+		require.NoError(t, err)
+		expected := &api.UpgradeGraph{
+			PackageName: "etcd",
+			Nodes: []*api.UpgradeGraphNode{
+				{BundleName: "etcdoperator.v0.6.1", Version: "0.6.1", Channels: []string{"alpha", "beta", "stable"}},
+				{BundleName: "etcdoperator.v0.9.0", Version: "0.9.0", Channels: []string{"alpha", "beta", "stable"}},
+				{BundleName: "etcdoperator.v0.9.2", Version: "0.9.2", Channels: []string{"alpha", "stable"}},
+			},
+			Edges: []*api.UpgradeGraphEdge{
+				{ChannelName: "alpha", From: "etcdoperator.v0.6.1", To: "etcdoperator.v0.9.0", Type: registry.UpgradeEdgeReplaces},
+				{ChannelName: "alpha", From: "etcdoperator.v0.9.0", To: "etcdoperator.v0.9.2", Type: registry.UpgradeEdgeReplaces},
+				{ChannelName: "alpha", From: "etcdoperator.v0.9.1", To: "etcdoperator.v0.9.2", Type: registry.UpgradeEdgeSkips},
+				{ChannelName: "beta", From: "etcdoperator.v0.6.1", To: "etcdoperator.v0.9.0", Type: registry.UpgradeEdgeReplaces},
+				{ChannelName: "stable", From: "etcdoperator.v0.6.1", To: "etcdoperator.v0.9.0", Type: registry.UpgradeEdgeReplaces},
+				{ChannelName: "stable", From: "etcdoperator.v0.9.0", To: "etcdoperator.v0.9.2", Type: registry.UpgradeEdgeReplaces},
+				{ChannelName: "stable", From: "etcdoperator.v0.9.1", To: "etcdoperator.v0.9.2", Type: registry.UpgradeEdgeSkips},
+			},
+		}
+		opts := []cmp.Option{
+			cmpopts.IgnoreUnexported(api.UpgradeGraph{}, api.UpgradeGraphNode{}, api.UpgradeGraphEdge{}),
+			cmpopts.SortSlices(func(x, y *api.UpgradeGraphNode) bool { return x.BundleName < y.BundleName }),
+			cmpopts.SortSlices(func(x, y *api.UpgradeGraphEdge) bool {
+				return x.ChannelName+"/"+x.From+"/"+x.To < y.ChannelName+"/"+y.From+"/"+y.To
+			}),
+			cmpopts.SortSlices(func(x, y string) bool { return x < y }),
+		}
+		require.True(t, cmp.Equal(expected, graph, opts...), cmp.Diff(expected, graph, opts...))
+
+		_, err = querier.GetUpgradeGraph(ctx, "missing")
+		require.Error(t, err)
+	})
+}
+
+func etcdBundle(channel, name, version string, kinds ...string) *api.Bundle {
+	b := &api.Bundle{
+		CsvName:     name,
+		PackageName: "etcd",
+		ChannelName: channel,
+		BundlePath:  "quay.io/coreos/etcd-operator-bundle:v" + version,
+		Version:     version,
+	}
+	for _, kind := range kinds {
+		b.ProvidedApis = append(b.ProvidedApis, &api.GroupVersionKind{Group: etcdGroup, Version: etcdVersion, Kind: kind})
+	}
+	return b
+}
+
+func etcdV0_9_0(channel string) *api.Bundle {
+	b := etcdBundle(channel, "etcdoperator.v0.9.0", "0.9.0", etcdCluster, "EtcdBackup")
+	b.Replaces = "etcdoperator.v0.6.1"
+	return b
+}
+
+func etcdV0_9_2(channel string) *api.Bundle {
+	b := etcdBundle(channel, "etcdoperator.v0.9.2", "0.9.2", etcdCluster, "EtcdBackup", "EtcdRestore")
+	b.Replaces = "etcdoperator.v0.9.0"
+	b.Skips = []string{"etcdoperator.v0.9.1"}
+	b.SkipRange = "< 0.6.0"
+	b.RequiredApis = []*api.GroupVersionKind{{Group: etcdGroup, Version: etcdVersion, Kind: etcdCluster}}
+	b.Dependencies = []*api.Dependency{{
+		Type:  "olm.gvk",
+		Value: `{"group":"etcd.database.coreos.com","kind":"EtcdCluster","version":"v1beta2"}`,
+	}}
+	return b
+}
+
+func prometheusV0_22_2() *api.Bundle {
+	return &api.Bundle{
+		CsvName:      "prometheusoperator.0.22.2",
+		PackageName:  "prometheus",
+		ChannelName:  "preview",
+		BundlePath:   "quay.io/coreos/prometheus-operator-bundle:v0.22.2",
+		Version:      "0.22.2",
+		ProvidedApis: []*api.GroupVersionKind{{Group: "monitoring.coreos.com", Version: "v1", Kind: "Prometheus"}},
+		Dependencies: []*api.Dependency{{
+			Type:  "olm.package",
+			Value: `{"packageName":"etcd","version":">=0.9.0"}`,
+		}},
+	}
+}
+
+// requireBundle compares the fields of actual that all queriers populate with
+// those of expected. The order of lists is ignored, and dependency values are
+// compared as JSON. Replaces and skips, which are only populated by the
+// queries that list bundles, are compared by requireBundleIn.
+func requireBundle(t *testing.T, expected, actual *api.Bundle) {
+	t.Helper()
+	require.NotNil(t, actual)
+	require.Equal(t, expected.CsvName, actual.CsvName)
+	require.Equal(t, expected.PackageName, actual.PackageName)
+	require.Equal(t, expected.ChannelName, actual.ChannelName)
+	require.Equal(t, expected.BundlePath, actual.BundlePath)
+	require.Equal(t, expected.Version, actual.Version)
+	require.Equal(t, expected.SkipRange, actual.SkipRange, "skipRange of %s", expected.CsvName)
+	require.ElementsMatch(t, gvks(expected.ProvidedApis), gvks(actual.ProvidedApis), "provided APIs of %s", expected.CsvName)
+	require.ElementsMatch(t, gvks(expected.RequiredApis), gvks(actual.RequiredApis), "required APIs of %s", expected.CsvName)
+
+	require.Len(t, actual.Dependencies, len(expected.Dependencies), "dependencies of %s", expected.CsvName)
+	deps := append([]*api.Dependency(nil), actual.Dependencies...)
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Type < deps[j].Type })
+	for i, d := range expected.Dependencies {
+		require.Equal(t, d.Type, deps[i].Type, "dependencies of %s", expected.CsvName)
+		require.JSONEq(t, d.Value, deps[i].Value, "dependencies of %s", expected.CsvName)
+	}
+}
+
+// requireBundleIn finds the bundle of bundles in the channel of expected, and
+// compares it with requireBundle and by its replaces and skips.
+func requireBundleIn(t *testing.T, expected *api.Bundle, bundles []*api.Bundle) {
+	t.Helper()
+	for _, b := range bundles {
+		if b.CsvName == expected.CsvName && b.ChannelName == expected.ChannelName {
+			requireBundle(t, expected, b)
+			require.Equal(t, expected.Replaces, b.Replaces, "replaces of %s", expected.CsvName)
+			require.ElementsMatch(t, expected.Skips, b.Skips, "skips of %s", expected.CsvName)
+			return
+		}
+	}
+	t.Fatalf("bundle %q not found in channel %q", expected.CsvName, expected.ChannelName)
+}
+
+func requireChannelEntries(t *testing.T, expected []registry.ChannelEntry, actual []*registry.ChannelEntry) {
+	t.Helper()
+	entries := make([]registry.ChannelEntry, 0, len(actual))
+	for _, e := range actual {
+		entries = append(entries, *e)
+	}
+	require.ElementsMatch(t, expected, entries)
+}
+
+func gvks(apis []*api.GroupVersionKind) []string {
+	var out []string
+	for _, gvk := range apis {
+		out = append(out, gvk.Group+"/"+gvk.Version+"/"+gvk.Kind)
+	}
+	return out
+}
+
+func channelBundles(bundles []*api.Bundle) []string {
+	var out []string
+	for _, b := range bundles {
+		out = append(out, b.ChannelName+"/"+b.CsvName)
+	}
+	return out
+}
+
+type bundleSender []*api.Bundle
+
+func (s *bundleSender) Send(b *api.Bundle) error {
+	*s = append(*s, b)
+	return nil
+}
@@ -0,0 +1,134 @@
+package registry
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/blang/semver/v4"
+
+	libsemver "github.com/operator-framework/operator-registry/pkg/lib/semver"
+)
+
+// ReplacementPolicy configures which upgrade edges GetBundleThatReplaces
+// follows to find the bundle of a channel that replaces another bundle.
+//
+// Replaces edges are always followed. A bundle that is only skipped or in the
+// skip range of other bundles is "synthetically" replaced by them, which lets
+// clients upgrade from bundles that are not in the channel anymore, or that
+// never were.
+type ReplacementPolicy struct {
+	// Skips follows skips edges: a bundle is replaced by the bundles that
+	// skip it, whether or not it is in the channel.
+	Skips bool
+	// SkipRange follows skip range edges: a bundle of the channel is
+	// replaced by the bundles whose skip range includes its version.
+	SkipRange bool
+}
+
+// DefaultReplacementPolicy follows replaces and skips edges, which is what
+// the sqlite and file-based catalog queriers have always done.
+func DefaultReplacementPolicy() ReplacementPolicy {
+	return ReplacementPolicy{Skips: true}
+}
+
+// ParseReplacementPolicy returns the policy that follows replaces edges and
+// the named edge types, UpgradeEdgeSkips or UpgradeEdgeSkipRange.
+func ParseReplacementPolicy(edgeTypes []string) (ReplacementPolicy, error) {
+	var p ReplacementPolicy
+	for _, t := range edgeTypes {
+		switch t {
+		case UpgradeEdgeSkips:
+			p.Skips = true
+		case UpgradeEdgeSkipRange:
+			p.SkipRange = true
+		default:
+			return ReplacementPolicy{}, fmt.Errorf("unknown replacement edge type %q: must be %q or %q", t, UpgradeEdgeSkips, UpgradeEdgeSkipRange)
+		}
+	}
+	return p, nil
+}
+
+// ReplacementCandidate is a bundle of a channel, with the upgrade edges that
+// its entry in the channel declares.
+type ReplacementCandidate struct {
+	Name      string
+	Version   string
+	Replaces  string
+	Skips     []string
+	SkipRange string
+}
+
+// BundleThatReplaces returns the name of the candidate that replaces the
+// bundle named name, from the bundles of a channel, and false if none does.
+//
+// When several candidates replace the bundle, the choice is deterministic: a
+// candidate that replaces it wins over one that skips it, which wins over one
+// whose skip range includes it, and among candidates with edges of the same
+// type, the one with the highest version wins, then the one with the lowest
+// name. Skip ranges only apply to bundles of the channel, whose version is
+// known.
+func (p ReplacementPolicy) BundleThatReplaces(name string, candidates []ReplacementCandidate) (string, bool) {
+	var version *semver.Version
+	if p.SkipRange {
+		for _, c := range candidates {
+			if c.Name != name {
+				continue
+			}
+			if v, err := semver.Parse(c.Version); err == nil {
+				version = &v
+			}
+		}
+	}
+
+	type match struct {
+		name    string
+		rank    int
+		version *semver.Version
+	}
+	var matches []match
+	for _, c := range candidates {
+		if c.Name == name {
+			continue
+		}
+		rank := -1
+		switch {
+		case c.Replaces == name:
+			rank = 0
+		case p.Skips && slices.Contains(c.Skips, name):
+			rank = 1
+		case version != nil && c.SkipRange != "":
+			if r, err := semver.ParseRange(c.SkipRange); err == nil && r(*version) {
+				rank = 2
+			}
+		}
+		if rank < 0 {
+			continue
+		}
+		m := match{name: c.Name, rank: rank}
+		if v, err := semver.Parse(c.Version); err == nil {
+			m.version = &v
+		}
+		matches = append(matches, m)
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if (a.version == nil) != (b.version == nil) {
+			return a.version != nil
+		}
+		if a.version != nil {
+			if c, err := libsemver.BuildIdCompare(*a.version, *b.version); err == nil && c != 0 {
+				return c > 0
+			}
+		}
+		return a.name < b.name
+	})
+	return matches[0].name, true
+}
@@ -0,0 +1,55 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplacementPolicyBundleThatReplaces(t *testing.T) {
+	candidates := []ReplacementCandidate{
+		{Name: "foo.v0.1.0", Version: "0.1.0"},
+		{Name: "foo.v0.1.5", Version: "0.1.5"},
+		{Name: "foo.v0.2.0", Version: "0.2.0", Replaces: "foo.v0.1.0", Skips: []string{"foo.v0.1.1"}},
+		{Name: "foo.v0.3.0", Version: "0.3.0", Replaces: "foo.v0.2.0", Skips: []string{"foo.v0.1.0", "foo.v0.1.1"}, SkipRange: "<0.3.0"},
+		{Name: "foo.v0.3.0-b", Version: "0.3.0", Skips: []string{"foo.v0.2.1"}},
+		{Name: "foo.v0.3.0-a", Version: "0.3.0", Skips: []string{"foo.v0.2.1"}},
+		{Name: "foo.v0.4.0", Version: "0.4.0", SkipRange: "not a range"},
+	}
+
+	type spec struct {
+		name     string
+		policy   ReplacementPolicy
+		bundle   string
+		expected string
+	}
+	for _, s := range []spec{
+		{name: "ReplacesWinsOverSkips", policy: DefaultReplacementPolicy(), bundle: "foo.v0.1.0", expected: "foo.v0.2.0"},
+		{name: "HighestVersionWins", policy: DefaultReplacementPolicy(), bundle: "foo.v0.1.1", expected: "foo.v0.3.0"},
+		{name: "LowestNameWins", policy: DefaultReplacementPolicy(), bundle: "foo.v0.2.1", expected: "foo.v0.3.0-a"},
+		{name: "SkipsNotFollowed", policy: ReplacementPolicy{}, bundle: "foo.v0.1.1"},
+		{name: "SkipRangeNotFollowed", policy: DefaultReplacementPolicy(), bundle: "foo.v0.1.5"},
+		{name: "SkipRange", policy: ReplacementPolicy{SkipRange: true}, bundle: "foo.v0.1.5", expected: "foo.v0.3.0"},
+		{name: "SkipRangeNotInChannel", policy: ReplacementPolicy{SkipRange: true}, bundle: "foo.v0.1.1"},
+		{name: "Head", policy: ReplacementPolicy{Skips: true, SkipRange: true}, bundle: "foo.v0.4.0"},
+	} {
+		t.Run(s.name, func(t *testing.T) {
+			actual, ok := s.policy.BundleThatReplaces(s.bundle, candidates)
+			require.Equal(t, s.expected != "", ok)
+			require.Equal(t, s.expected, actual)
+		})
+	}
+}
+
+func TestParseReplacementPolicy(t *testing.T) {
+	p, err := ParseReplacementPolicy(nil)
+	require.NoError(t, err)
+	require.Equal(t, ReplacementPolicy{}, p)
+
+	p, err = ParseReplacementPolicy([]string{UpgradeEdgeSkips, UpgradeEdgeSkipRange})
+	require.NoError(t, err)
+	require.Equal(t, ReplacementPolicy{Skips: true, SkipRange: true}, p)
+
+	_, err = ParseReplacementPolicy([]string{UpgradeEdgeReplaces})
+	require.Error(t, err)
+}
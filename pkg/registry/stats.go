@@ -0,0 +1,128 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/pkg/api"
+)
+
+// DefaultLargestBundles is the number of largest bundles reported by catalog
+// statistics when no number is requested.
+const DefaultLargestBundles = 10
+
+// CatalogStatsBuilder accumulates the statistics of a catalog: the number of
+// its packages, channels and bundles, the size of the bundles' manifests, its
+// largest bundles and how many of its packages, channels and bundles are
+// deprecated. It is a BundleSender, so that the bundles of a GRPCQuery can be
+// sent to it.
+type CatalogStatsBuilder struct {
+	largest      int
+	packages     int32
+	channels     int32
+	deprecations api.DeprecationStats
+	bundles      map[bundleStatsKey]*api.BundleStats
+}
+
+type bundleStatsKey struct {
+	pkg, name string
+}
+
+// NewCatalogStatsBuilder returns a CatalogStatsBuilder that reports the
+// largest bundles of the catalog, or DefaultLargestBundles if largest is not
+// positive.
+func NewCatalogStatsBuilder(largest int) *CatalogStatsBuilder {
+	if largest <= 0 {
+		largest = DefaultLargestBundles
+	}
+	return &CatalogStatsBuilder{
+		largest: largest,
+		bundles: map[bundleStatsKey]*api.BundleStats{},
+	}
+}
+
+// AddPackage records pkg and its channels.
+func (b *CatalogStatsBuilder) AddPackage(pkg *api.Package) {
+	b.packages++
+	if pkg.GetDeprecation() != nil {
+		b.deprecations.Packages++
+	}
+	for _, ch := range pkg.GetChannels() {
+		b.channels++
+		if ch.GetDeprecation() != nil {
+			b.deprecations.Channels++
+		}
+	}
+}
+
+// Send records bundle. Bundles are sent once for each of the channels they are
+// in, but are only counted once.
+func (b *CatalogStatsBuilder) Send(bundle *api.Bundle) error {
+	key := bundleStatsKey{bundle.GetPackageName(), bundle.GetCsvName()}
+	if _, ok := b.bundles[key]; ok {
+		return nil
+	}
+	manifestBytes, err := bundleManifestBytes(bundle)
+	if err != nil {
+		return fmt.Errorf("bundle %q: %v", bundle.GetCsvName(), err)
+	}
+	b.bundles[key] = &api.BundleStats{
+		PackageName:   key.pkg,
+		CsvName:       key.name,
+		ManifestBytes: manifestBytes,
+	}
+	if bundle.GetDeprecation() != nil {
+		b.deprecations.Bundles++
+	}
+	return nil
+}
+
+// Stats returns the statistics of the packages and bundles recorded so far.
+func (b *CatalogStatsBuilder) Stats() *api.CatalogStats {
+	stats := &api.CatalogStats{
+		Packages: b.packages,
+		Channels: b.channels,
+		Bundles:  int32(len(b.bundles)),
+		Deprecations: &api.DeprecationStats{
+			Packages: b.deprecations.Packages,
+			Channels: b.deprecations.Channels,
+			Bundles:  b.deprecations.Bundles,
+		},
+	}
+	bundles := make([]*api.BundleStats, 0, len(b.bundles))
+	for _, bundle := range b.bundles {
+		stats.ManifestBytes += bundle.ManifestBytes
+		bundles = append(bundles, bundle)
+	}
+	sort.Slice(bundles, func(i, j int) bool {
+		if bundles[i].ManifestBytes != bundles[j].ManifestBytes {
+			return bundles[i].ManifestBytes > bundles[j].ManifestBytes
+		}
+		if bundles[i].PackageName != bundles[j].PackageName {
+			return bundles[i].PackageName < bundles[j].PackageName
+		}
+		return bundles[i].CsvName < bundles[j].CsvName
+	})
+	if len(bundles) > b.largest {
+		bundles = bundles[:b.largest]
+	}
+	stats.LargestBundles = bundles
+	return stats
+}
+
+// bundleManifestBytes returns the size of the manifests of bundle, from its
+// size metadata if it has any, and otherwise from its objects.
+func bundleManifestBytes(bundle *api.Bundle) (int64, error) {
+	if bundle.GetSize() != nil {
+		return bundle.GetSize().GetManifestBytes(), nil
+	}
+	if len(bundle.GetObject()) == 0 {
+		return 0, nil
+	}
+	size, err := property.ComputeBundleSize(bundle.GetObject())
+	if err != nil {
+		return 0, err
+	}
+	return size.ManifestBytes, nil
+}
@@ -0,0 +1,52 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+)
+
+func TestCatalogStatsBuilder(t *testing.T) {
+	b := NewCatalogStatsBuilder(2)
+	b.AddPackage(&api.Package{
+		Name:        "foo",
+		Deprecation: &api.Deprecation{Message: "foo is deprecated"},
+		Channels: []*api.Channel{
+			{Name: "alpha", Deprecation: &api.Deprecation{Message: "alpha is deprecated"}},
+			{Name: "stable"},
+		},
+	})
+	b.AddPackage(&api.Package{Name: "bar", Channels: []*api.Channel{{Name: "stable"}}})
+
+	for _, bundle := range []*api.Bundle{
+		{PackageName: "foo", ChannelName: "alpha", CsvName: "foo.v1", Size: &api.BundleSize{ManifestBytes: 100}, Deprecation: &api.Deprecation{}},
+		{PackageName: "foo", ChannelName: "stable", CsvName: "foo.v1", Size: &api.BundleSize{ManifestBytes: 100}, Deprecation: &api.Deprecation{}},
+		{PackageName: "foo", ChannelName: "stable", CsvName: "foo.v2", Object: []string{`{"kind":"ConfigMap"}`}},
+		{PackageName: "bar", ChannelName: "stable", CsvName: "bar.v1", Size: &api.BundleSize{ManifestBytes: 300}},
+		{PackageName: "bar", ChannelName: "stable", CsvName: "bar.v0"},
+	} {
+		require.NoError(t, b.Send(bundle))
+	}
+
+	stats := b.Stats()
+	require.Equal(t, int32(2), stats.GetPackages())
+	require.Equal(t, int32(3), stats.GetChannels())
+	require.Equal(t, int32(4), stats.GetBundles())
+	require.Equal(t, int64(100+20+300), stats.GetManifestBytes())
+	require.Equal(t, int32(1), stats.GetDeprecations().GetPackages())
+	require.Equal(t, int32(1), stats.GetDeprecations().GetChannels())
+	require.Equal(t, int32(1), stats.GetDeprecations().GetBundles())
+
+	var largest []string
+	for _, bundle := range stats.GetLargestBundles() {
+		largest = append(largest, bundle.GetCsvName())
+	}
+	require.Equal(t, []string{"bar.v1", "foo.v1"}, largest)
+}
+
+func TestCatalogStatsBuilderInvalidObject(t *testing.T) {
+	b := NewCatalogStatsBuilder(0)
+	require.Error(t, b.Send(&api.Bundle{PackageName: "foo", CsvName: "foo.v1", Object: []string{"{"}}))
+}
@@ -98,6 +98,13 @@ type PackageManifest struct {
 	// has a single channel, then that channel is implicitly the default.
 	DefaultChannelName string       `json:"defaultChannel" yaml:"defaultChannel"`
 	Deprecation        *Deprecation `json:"deprecation,omitempty" yaml:"deprecation,omitempty"`
+
+	// Icon is, if specified, the icon of the package.
+	Icon *Icon `json:"icon,omitempty" yaml:"icon,omitempty"`
+
+	// Properties are the properties of the package. Only file-based catalogs
+	// declare package properties, so packages of sqlite databases have none.
+	Properties []Property `json:"properties,omitempty" yaml:"properties,omitempty"`
 }
 
 // GetDefaultChannel gets the default channel or returns the only one if there's only one. returns empty string if it
@@ -122,6 +129,19 @@ type PackageChannel struct {
 	// for the channel.
 	CurrentCSVName string       `json:"currentCSV" yaml:"currentCSV"`
 	Deprecation    *Deprecation `json:"deprecation,omitempty" yaml:"deprecation,omitempty"`
+
+	// HeadVersion is the version of the channel's head bundle, and BundleCount the number
+	// of bundles in the channel. They are computed by the querier, and are not part of the
+	// package manifest format.
+	HeadVersion string `json:"-" yaml:"-"`
+	BundleCount int    `json:"-" yaml:"-"`
+
+	// Priority orders the channels of the package, as declared by file-based catalogs. Channels
+	// with a higher priority come first, and channels with the same priority are ordered by name.
+	Priority int `json:"-" yaml:"-"`
+
+	// Properties are the properties of the channel, as declared by file-based catalogs.
+	Properties []Property `json:"-" yaml:"-"`
 }
 
 // IsDefaultChannel returns true if the PackageChennel is the default for the PackageManifest
@@ -167,6 +187,10 @@ type Annotations struct {
 	// default channel will be installed if no other channel is explicitly given. If the package
 	// has a single channel, then that channel is implicitly the default.
 	DefaultChannelName string `json:"operators.operatorframework.io.bundle.channel.default.v1" yaml:"operators.operatorframework.io.bundle.channel.default.v1"`
+
+	// MediaType is the format of the bundle's manifests, ala `registry+v1` or `plain+v0`. Bundles
+	// that don't declare it are registry+v1 bundles.
+	MediaType string `json:"operators.operatorframework.io.bundle.mediatype.v1,omitempty" yaml:"operators.operatorframework.io.bundle.mediatype.v1,omitempty"`
 }
 
 // DependenciesFile holds dependency information about a bundle.
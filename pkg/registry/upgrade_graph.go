@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"sort"
+
+	"github.com/blang/semver/v4"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+)
+
+// The types of the edges of an upgrade graph, named after the channel entry
+// field that declares them.
+const (
+	UpgradeEdgeReplaces  = "replaces"
+	UpgradeEdgeSkips     = "skips"
+	UpgradeEdgeSkipRange = "skipRange"
+)
+
+// NewUpgradeGraph builds the upgrade graph of a package from its bundles, with
+// one bundle for each channel that the bundle is in, as returned by
+// ListBundles.
+//
+// The graph has a node for each bundle, and an edge for each upgrade declared
+// by a channel entry. Replaces and skips edges may start from bundles that
+// are not in the package anymore, since they can still be installed on a
+// cluster. Skip ranges only produce edges from the bundles of the channel
+// whose version is in the range, and invalid skip ranges are ignored.
+func NewUpgradeGraph(pkgName string, bundles []*api.Bundle) *api.UpgradeGraph {
+	nodes := map[string]*api.UpgradeGraphNode{}
+	channels := map[string][]*api.Bundle{}
+	for _, b := range bundles {
+		n, ok := nodes[b.CsvName]
+		if !ok {
+			n = &api.UpgradeGraphNode{BundleName: b.CsvName, Version: b.Version}
+			nodes[b.CsvName] = n
+		}
+		n.Channels = append(n.Channels, b.ChannelName)
+		channels[b.ChannelName] = append(channels[b.ChannelName], b)
+	}
+
+	graph := &api.UpgradeGraph{PackageName: pkgName}
+	for _, n := range nodes {
+		sort.Strings(n.Channels)
+		graph.Nodes = append(graph.Nodes, n)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].BundleName < graph.Nodes[j].BundleName })
+
+	for chName, chBundles := range channels {
+		for _, b := range chBundles {
+			if b.Replaces != "" {
+				graph.Edges = append(graph.Edges, &api.UpgradeGraphEdge{ChannelName: chName, From: b.Replaces, To: b.CsvName, Type: UpgradeEdgeReplaces})
+			}
+			for _, skip := range b.Skips {
+				if skip == "" {
+					continue
+				}
+				graph.Edges = append(graph.Edges, &api.UpgradeGraphEdge{ChannelName: chName, From: skip, To: b.CsvName, Type: UpgradeEdgeSkips})
+			}
+			if b.SkipRange == "" {
+				continue
+			}
+			skipRange, err := semver.ParseRange(b.SkipRange)
+			if err != nil {
+				continue
+			}
+			for _, other := range chBundles {
+				if other.CsvName == b.CsvName {
+					continue
+				}
+				v, err := semver.Parse(other.Version)
+				if err != nil || !skipRange(v) {
+					continue
+				}
+				graph.Edges = append(graph.Edges, &api.UpgradeGraphEdge{ChannelName: chName, From: other.CsvName, To: b.CsvName, Type: UpgradeEdgeSkipRange})
+			}
+		}
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.ChannelName != b.ChannelName {
+			return a.ChannelName < b.ChannelName
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.Type < b.Type
+	})
+	return graph
+}
@@ -0,0 +1,50 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+)
+
+func TestNewUpgradeGraph(t *testing.T) {
+	bundles := []*api.Bundle{
+		{CsvName: "foo.v0.1.0", ChannelName: "stable", Version: "0.1.0"},
+		{CsvName: "foo.v0.2.0", ChannelName: "stable", Version: "0.2.0", Replaces: "foo.v0.1.0"},
+		{CsvName: "foo.v0.3.0", ChannelName: "stable", Version: "0.3.0", Replaces: "foo.v0.2.0", Skips: []string{"foo.v0.2.1"}, SkipRange: "<0.3.0"},
+		{CsvName: "foo.v0.3.0", ChannelName: "fast", Version: "0.3.0", SkipRange: "<0.3.0"},
+		{CsvName: "foo.v0.4.0", ChannelName: "fast", Version: "0.4.0", SkipRange: "not a range"},
+	}
+
+	graph := NewUpgradeGraph("foo", bundles)
+	require.Equal(t, "foo", graph.GetPackageName())
+
+	type node struct {
+		name, version string
+		channels      []string
+	}
+	var nodes []node
+	for _, n := range graph.GetNodes() {
+		nodes = append(nodes, node{n.GetBundleName(), n.GetVersion(), n.GetChannels()})
+	}
+	require.Equal(t, []node{
+		{"foo.v0.1.0", "0.1.0", []string{"stable"}},
+		{"foo.v0.2.0", "0.2.0", []string{"stable"}},
+		{"foo.v0.3.0", "0.3.0", []string{"fast", "stable"}},
+		{"foo.v0.4.0", "0.4.0", []string{"fast"}},
+	}, nodes)
+
+	type edge struct{ channel, from, to, typ string }
+	var edges []edge
+	for _, e := range graph.GetEdges() {
+		edges = append(edges, edge{e.GetChannelName(), e.GetFrom(), e.GetTo(), e.GetType()})
+	}
+	require.Equal(t, []edge{
+		{"stable", "foo.v0.1.0", "foo.v0.2.0", UpgradeEdgeReplaces},
+		{"stable", "foo.v0.1.0", "foo.v0.3.0", UpgradeEdgeSkipRange},
+		{"stable", "foo.v0.2.0", "foo.v0.3.0", UpgradeEdgeReplaces},
+		{"stable", "foo.v0.2.0", "foo.v0.3.0", UpgradeEdgeSkipRange},
+		{"stable", "foo.v0.2.1", "foo.v0.3.0", UpgradeEdgeSkips},
+	}, edges)
+}
@@ -0,0 +1,286 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/api"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+// Identity identifies the client of a call.
+type Identity struct {
+	// CommonName is the common name of the verified client certificate of
+	// the call, if it was made over mutual TLS.
+	CommonName string `json:"commonName,omitempty"`
+	// Token is the bearer token of the authorization metadata of the call.
+	Token string `json:"token,omitempty"`
+}
+
+// IdentityFromContext returns the identity of the client of the call of ctx.
+// Its fields are empty if the client did not present a verified certificate
+// or a token.
+func IdentityFromContext(ctx context.Context) Identity {
+	var id Identity
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 && len(info.State.VerifiedChains[0]) > 0 {
+			id.CommonName = info.State.VerifiedChains[0][0].Subject.CommonName
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, v := range md.Get("authorization") {
+			if token, ok := strings.CutPrefix(v, "Bearer "); ok {
+				id.Token = token
+				break
+			}
+		}
+	}
+	return id
+}
+
+// PackageAuthorizer restricts the packages that clients may list and fetch.
+type PackageAuthorizer interface {
+	// Authorize returns a function that reports whether the client with
+	// identity id may access a package.
+	Authorize(ctx context.Context, id Identity) (func(pkg string) bool, error)
+}
+
+// NewAuthorizedStore returns a store that serves the content of store that
+// the client of each query is authorized to access by authorizer. Packages
+// that a client may not access are reported as not found, and left out of
+// listings, so that clients cannot tell them from missing packages.
+func NewAuthorizedStore(store registry.GRPCQuery, authorizer PackageAuthorizer) registry.GRPCQuery {
+	return &authorizedStore{store: store, authorizer: authorizer}
+}
+
+type authorizedStore struct {
+	store      registry.GRPCQuery
+	authorizer PackageAuthorizer
+}
+
+func (s *authorizedStore) allowed(ctx context.Context) (func(string) bool, error) {
+	allowed, err := s.authorizer.Authorize(ctx, IdentityFromContext(ctx))
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "authorize client: %v", err)
+	}
+	return allowed, nil
+}
+
+func (s *authorizedStore) checkPackage(ctx context.Context, pkgName string) error {
+	allowed, err := s.allowed(ctx)
+	if err != nil {
+		return err
+	}
+	if !allowed(pkgName) {
+		return status.Errorf(codes.NotFound, "package %q not found", pkgName)
+	}
+	return nil
+}
+
+func (s *authorizedStore) filterEntries(ctx context.Context, entries []*registry.ChannelEntry, err error) ([]*registry.ChannelEntry, error) {
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := s.allowed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []*registry.ChannelEntry
+	for _, e := range entries {
+		if allowed(e.PackageName) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (s *authorizedStore) ListPackages(ctx context.Context) ([]string, error) {
+	allowed, err := s.allowed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pkgs, err := s.store.ListPackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, pkg := range pkgs {
+		if allowed(pkg) {
+			out = append(out, pkg)
+		}
+	}
+	return out, nil
+}
+
+func (s *authorizedStore) SearchPackages(ctx context.Context, filter registry.PackageFilter) ([]string, error) {
+	searcher, ok := s.store.(packageSearcher)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "package filters are not supported by this registry")
+	}
+	allowed, err := s.allowed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pkgs, err := searcher.SearchPackages(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, pkg := range pkgs {
+		if allowed(pkg) {
+			out = append(out, pkg)
+		}
+	}
+	return out, nil
+}
+
+func (s *authorizedStore) SendBundles(ctx context.Context, stream registry.BundleSender) error {
+	allowed, err := s.allowed(ctx)
+	if err != nil {
+		return err
+	}
+	return s.store.SendBundles(ctx, &filteringBundleSender{stream, allowed})
+}
+
+func (s *authorizedStore) SendBundlesWithEdges(ctx context.Context, stream registry.BundleSender) error {
+	sender, ok := s.store.(edgeBundleSender)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "listing bundles with their edges is not supported by this registry")
+	}
+	allowed, err := s.allowed(ctx)
+	if err != nil {
+		return err
+	}
+	return sender.SendBundlesWithEdges(ctx, &filteringBundleSender{stream, allowed})
+}
+
+func (s *authorizedStore) ListBundles(ctx context.Context) ([]*api.Bundle, error) {
+	allowed, err := s.allowed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bundles, err := s.store.ListBundles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []*api.Bundle
+	for _, b := range bundles {
+		if allowed(b.PackageName) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (s *authorizedStore) GetPackage(ctx context.Context, name string) (*registry.PackageManifest, error) {
+	if err := s.checkPackage(ctx, name); err != nil {
+		return nil, err
+	}
+	return s.store.GetPackage(ctx, name)
+}
+
+func (s *authorizedStore) GetBundle(ctx context.Context, pkgName, channelName, csvName string) (*api.Bundle, error) {
+	if err := s.checkPackage(ctx, pkgName); err != nil {
+		return nil, err
+	}
+	return s.store.GetBundle(ctx, pkgName, channelName, csvName)
+}
+
+func (s *authorizedStore) GetBundleForChannel(ctx context.Context, pkgName string, channelName string) (*api.Bundle, error) {
+	if err := s.checkPackage(ctx, pkgName); err != nil {
+		return nil, err
+	}
+	return s.store.GetBundleForChannel(ctx, pkgName, channelName)
+}
+
+func (s *authorizedStore) GetChannelEntriesThatReplace(ctx context.Context, name string) ([]*registry.ChannelEntry, error) {
+	entries, err := s.store.GetChannelEntriesThatReplace(ctx, name)
+	return s.filterEntries(ctx, entries, err)
+}
+
+func (s *authorizedStore) GetBundleThatReplaces(ctx context.Context, name, pkgName, channelName string) (*api.Bundle, error) {
+	if err := s.checkPackage(ctx, pkgName); err != nil {
+		return nil, err
+	}
+	return s.store.GetBundleThatReplaces(ctx, name, pkgName, channelName)
+}
+
+func (s *authorizedStore) GetChannelEntriesThatProvide(ctx context.Context, group, version, kind string) ([]*registry.ChannelEntry, error) {
+	entries, err := s.store.GetChannelEntriesThatProvide(ctx, group, version, kind)
+	return s.filterEntries(ctx, entries, err)
+}
+
+func (s *authorizedStore) GetLatestChannelEntriesThatProvide(ctx context.Context, group, version, kind string) ([]*registry.ChannelEntry, error) {
+	entries, err := s.store.GetLatestChannelEntriesThatProvide(ctx, group, version, kind)
+	return s.filterEntries(ctx, entries, err)
+}
+
+// GetBundleThatProvides chooses, among the packages the client may access,
+// the bundle that the store would choose among all packages: the latest
+// bundle that provides the API in the default channel of its package, from
+// the first such package by name.
+func (s *authorizedStore) GetBundleThatProvides(ctx context.Context, group, version, kind string) (*api.Bundle, error) {
+	entries, err := s.GetLatestChannelEntriesThatProvide(ctx, group, version, kind)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].PackageName < entries[j].PackageName
+	})
+	for _, e := range entries {
+		pkg, err := s.store.GetPackage(ctx, e.PackageName)
+		if err != nil {
+			return nil, err
+		}
+		if e.ChannelName == pkg.DefaultChannelName {
+			return s.store.GetBundle(ctx, e.PackageName, e.ChannelName, e.BundleName)
+		}
+	}
+	return nil, fmt.Errorf("no entry found that provides group:%q version:%q kind:%q", group, version, kind)
+}
+
+func (s *authorizedStore) GetUpgradeGraph(ctx context.Context, pkgName string) (*api.UpgradeGraph, error) {
+	if err := s.checkPackage(ctx, pkgName); err != nil {
+		return nil, err
+	}
+	return s.store.GetUpgradeGraph(ctx, pkgName)
+}
+
+// Digest and CatalogMetadata report the catalog info of the store, which does
+// not depend on the packages a client may access.
+func (s *authorizedStore) Digest(ctx context.Context) (string, error) {
+	d, ok := s.store.(catalogDigester)
+	if !ok {
+		return "", status.Errorf(codes.Unimplemented, "catalog info is not available from this registry")
+	}
+	return d.Digest(ctx)
+}
+
+func (s *authorizedStore) CatalogMetadata(ctx context.Context) (*declcfg.Catalog, error) {
+	m, ok := s.store.(catalogMetadataGetter)
+	if !ok {
+		return nil, nil
+	}
+	return m.CatalogMetadata(ctx)
+}
+
+type filteringBundleSender struct {
+	registry.BundleSender
+	allowed func(string) bool
+}
+
+func (s *filteringBundleSender) Send(b *api.Bundle) error {
+	if !s.allowed(b.PackageName) {
+		return nil
+	}
+	return s.BundleSender.Send(b)
+}
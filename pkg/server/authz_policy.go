@@ -0,0 +1,158 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// AuthorizationPolicy is a PackageAuthorizer that grants access to packages
+// by client identity. A client may access the packages matched by Public and
+// by the rules that match its identity. Packages are matched by the patterns
+// of path.Match, e.g. "*" matches every package.
+type AuthorizationPolicy struct {
+	// Public are the packages any client, even unidentified, may access.
+	Public []string            `json:"public,omitempty"`
+	Rules  []AuthorizationRule `json:"rules,omitempty"`
+}
+
+// AuthorizationRule grants access to packages to the clients whose identity
+// matches one of its common names or tokens.
+type AuthorizationRule struct {
+	CommonNames []string `json:"commonNames,omitempty"`
+	Tokens      []string `json:"tokens,omitempty"`
+	Packages    []string `json:"packages"`
+}
+
+// LoadAuthorizationPolicy reads and validates the YAML or JSON authorization
+// policy in file.
+func LoadAuthorizationPolicy(file string) (*AuthorizationPolicy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var p AuthorizationPolicy
+	if err := yaml.UnmarshalStrict(data, &p); err != nil {
+		return nil, fmt.Errorf("parse authorization policy %q: %v", file, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid authorization policy %q: %v", file, err)
+	}
+	return &p, nil
+}
+
+// Validate checks that the package patterns of p are valid, and that each
+// rule matches some identity.
+func (p AuthorizationPolicy) Validate() error {
+	if err := validatePatterns(p.Public); err != nil {
+		return err
+	}
+	for i, r := range p.Rules {
+		if len(r.CommonNames) == 0 && len(r.Tokens) == 0 {
+			return fmt.Errorf("rule %d has neither common names nor tokens", i)
+		}
+		if err := validatePatterns(r.Packages); err != nil {
+			return fmt.Errorf("rule %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func (p AuthorizationPolicy) Authorize(_ context.Context, id Identity) (func(string) bool, error) {
+	patterns := append([]string(nil), p.Public...)
+	for _, r := range p.Rules {
+		if r.matches(id) {
+			patterns = append(patterns, r.Packages...)
+		}
+	}
+	return matchPatterns(patterns), nil
+}
+
+func (r AuthorizationRule) matches(id Identity) bool {
+	for _, cn := range r.CommonNames {
+		if id.CommonName != "" && id.CommonName == cn {
+			return true
+		}
+	}
+	for _, token := range r.Tokens {
+		if id.Token != "" && subtle.ConstantTimeCompare([]byte(id.Token), []byte(token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid package pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+func matchPatterns(patterns []string) func(string) bool {
+	return func(pkg string) bool {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, pkg); ok {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AuthorizationWebhook is a PackageAuthorizer that asks an external service
+// which packages a client may access. For each call, the identity of the
+// client is posted as JSON to URL, which responds with the package patterns
+// of the client, as in AuthorizationPolicy:
+//
+//	{"packages": ["etcd", "prometheus-*"]}
+type AuthorizationWebhook struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewAuthorizationWebhook returns a webhook authorizer for url, whose
+// requests time out after 10 seconds.
+func NewAuthorizationWebhook(url string) *AuthorizationWebhook {
+	return &AuthorizationWebhook{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (w *AuthorizationWebhook) Authorize(ctx context.Context, id Identity) (func(string) bool, error) {
+	body, err := json.Marshal(id)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("authorization webhook responded with status %s", resp.Status)
+	}
+	var result struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode authorization webhook response: %v", err)
+	}
+	if err := validatePatterns(result.Packages); err != nil {
+		return nil, fmt.Errorf("authorization webhook response: %v", err)
+	}
+	return matchPatterns(result.Packages), nil
+}
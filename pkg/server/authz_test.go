@@ -0,0 +1,198 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+	"github.com/operator-framework/operator-registry/pkg/registry/registrytest"
+)
+
+func withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestAuthorizedStore(t *testing.T) {
+	cache, err := fbcCacheFromFs(registrytest.Catalog(), t.TempDir())
+	require.NoError(t, err)
+	store := NewAuthorizedStore(cache, AuthorizationPolicy{
+		Public: []string{"prom*"},
+		Rules: []AuthorizationRule{
+			{Tokens: []string{"tenant-a"}, Packages: []string{"etcd"}},
+		},
+	})
+
+	t.Run("Unidentified", func(t *testing.T) {
+		ctx := context.Background()
+		pkgs, err := store.ListPackages(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"prometheus"}, pkgs)
+
+		_, err = store.GetPackage(ctx, "etcd")
+		require.Equal(t, codes.NotFound, status.Code(err))
+		_, err = store.GetBundle(ctx, "etcd", "alpha", "etcdoperator.v0.9.2")
+		require.Equal(t, codes.NotFound, status.Code(err))
+		_, err = store.GetUpgradeGraph(ctx, "etcd")
+		require.Equal(t, codes.NotFound, status.Code(err))
+
+		entries, err := store.GetChannelEntriesThatReplace(ctx, "etcdoperator.v0.6.1")
+		require.NoError(t, err)
+		require.Empty(t, entries)
+		_, err = store.GetBundleThatProvides(ctx, "etcd.database.coreos.com", "v1beta2", "EtcdCluster")
+		require.Error(t, err)
+
+		bundles, err := store.ListBundles(ctx)
+		require.NoError(t, err)
+		require.Len(t, bundles, 1)
+		require.Equal(t, "prometheus", bundles[0].PackageName)
+	})
+
+	t.Run("Token", func(t *testing.T) {
+		ctx := withToken("tenant-a")
+		pkgs, err := store.ListPackages(ctx)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"etcd", "prometheus"}, pkgs)
+
+		b, err := store.GetBundleThatProvides(ctx, "etcd.database.coreos.com", "v1beta2", "EtcdCluster")
+		require.NoError(t, err)
+		require.Equal(t, "etcdoperator.v0.9.2", b.CsvName)
+		require.Equal(t, "alpha", b.ChannelName)
+
+		var sent []*api.Bundle
+		require.NoError(t, store.SendBundles(ctx, bundleSenderFunc(func(b *api.Bundle) error {
+			sent = append(sent, b)
+			return nil
+		})))
+		require.Len(t, sent, 9)
+	})
+
+	t.Run("WrongToken", func(t *testing.T) {
+		_, err := store.GetPackage(withToken("tenant-b"), "etcd")
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+type bundleSenderFunc func(*api.Bundle) error
+
+func (f bundleSenderFunc) Send(b *api.Bundle) error {
+	return f(b)
+}
+
+func TestIdentityFromContext(t *testing.T) {
+	require.Equal(t, Identity{}, IdentityFromContext(context.Background()))
+	require.Equal(t, Identity{Token: "secret"}, IdentityFromContext(withToken("secret")))
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "tenant-a"}}}},
+	}}})
+	require.Equal(t, Identity{CommonName: "tenant-a"}, IdentityFromContext(ctx))
+
+	// Unverified certificates do not identify clients.
+	ctx = peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "tenant-a"}}},
+	}}})
+	require.Equal(t, Identity{}, IdentityFromContext(ctx))
+}
+
+func TestLoadAuthorizationPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		policy      string
+		expectedErr string
+	}{
+		{
+			name: "Valid",
+			policy: `public: ["prometheus"]
+rules:
+- commonNames: ["tenant-a"]
+  packages: ["etcd*"]
+`,
+		},
+		{
+			name:        "UnknownField",
+			policy:      `packages: ["etcd"]`,
+			expectedErr: "parse authorization policy",
+		},
+		{
+			name:        "InvalidPattern",
+			policy:      `public: ["["]`,
+			expectedErr: `invalid package pattern "["`,
+		},
+		{
+			name: "NoIdentities",
+			policy: `rules:
+- packages: ["etcd"]
+`,
+			expectedErr: "rule 0 has neither common names nor tokens",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "policy.yaml")
+			require.NoError(t, os.WriteFile(file, []byte(tt.policy), 0600))
+			p, err := LoadAuthorizationPolicy(file)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			allowed, err := p.Authorize(context.Background(), Identity{CommonName: "tenant-a"})
+			require.NoError(t, err)
+			require.True(t, allowed("etcd"))
+			require.True(t, allowed("prometheus"))
+			require.False(t, allowed("strimzi"))
+		})
+	}
+}
+
+func TestAuthorizationWebhook(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var id Identity
+		if err := json.NewDecoder(r.Body).Decode(&id); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		switch id.Token {
+		case "tenant-a":
+			_, _ = w.Write([]byte(`{"packages":["etcd"]}`))
+		case "":
+			_, _ = w.Write([]byte(`{"packages":[]}`))
+		default:
+			http.Error(w, "unknown token", http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+	w := NewAuthorizationWebhook(srv.URL)
+
+	allowed, err := w.Authorize(context.Background(), Identity{Token: "tenant-a"})
+	require.NoError(t, err)
+	require.True(t, allowed("etcd"))
+	require.False(t, allowed("prometheus"))
+
+	allowed, err = w.Authorize(context.Background(), Identity{})
+	require.NoError(t, err)
+	require.False(t, allowed("etcd"))
+
+	_, err = w.Authorize(context.Background(), Identity{Token: "tenant-b"})
+	require.ErrorContains(t, err, "403 Forbidden")
+
+	// Errors of the authorizer make calls fail, instead of serving or hiding
+	// packages.
+	store := NewAuthorizedStore(nil, w)
+	_, err = store.ListPackages(withToken("tenant-b"))
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+
+	"github.com/blang/semver/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	libsemver "github.com/operator-framework/operator-registry/pkg/lib/semver"
+)
+
+// bundleNameForVersion returns the name of the bundle of a channel of a
+// package that version selects. A version that a bundle of the channel has
+// selects it, and otherwise version is parsed as a semver range, which
+// selects the bundle of the channel with the highest version in the range.
+// Bundles are looked up in the upgrade graph of the package, which every
+// store serves.
+func (s *RegistryServer) bundleNameForVersion(ctx context.Context, pkgName, channelName, version string) (string, error) {
+	graph, err := s.store.GetUpgradeGraph(ctx, pkgName)
+	if err != nil {
+		return "", err
+	}
+
+	type candidate struct {
+		name    string
+		version semver.Version
+	}
+	var candidates []candidate
+	for _, n := range graph.GetNodes() {
+		inChannel := false
+		for _, ch := range n.GetChannels() {
+			inChannel = inChannel || ch == channelName
+		}
+		if !inChannel {
+			continue
+		}
+		if n.GetVersion() == version {
+			return n.GetBundleName(), nil
+		}
+		if v, err := semver.Parse(n.GetVersion()); err == nil {
+			candidates = append(candidates, candidate{n.GetBundleName(), v})
+		}
+	}
+
+	versionRange, err := semver.ParseRange(version)
+	if err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "invalid version or version range %q: %v", version, err)
+	}
+	var selected *candidate
+	for i, c := range candidates {
+		if !versionRange(c.version) {
+			continue
+		}
+		if selected != nil {
+			if cmp, err := libsemver.BuildIdCompare(c.version, selected.version); err != nil || cmp <= 0 {
+				continue
+			}
+		}
+		selected = &candidates[i]
+	}
+	if selected == nil {
+		return "", status.Errorf(codes.NotFound, "no bundle of channel %q of package %q matches version %q", channelName, pkgName, version)
+	}
+	return selected.name, nil
+}
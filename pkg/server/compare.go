@@ -0,0 +1,274 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/testing/protocmp"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+const inProcessBufferSize = 1024 * 1024
+
+// Compare serves the expected and actual stores in-process and issues every
+// registry RPC against both of them, using the content of both stores to
+// build the set of requests. It returns a human-readable description of each
+// RPC whose responses (or errors) differ. An empty result means that the two
+// stores are indistinguishable to a registry client.
+func Compare(ctx context.Context, expected, actual registry.GRPCQuery) ([]string, error) {
+	expectedClient, stopExpected, err := serveInProcess(expected)
+	if err != nil {
+		return nil, fmt.Errorf("serve expected store: %v", err)
+	}
+	defer stopExpected()
+
+	actualClient, stopActual, err := serveInProcess(actual)
+	if err != nil {
+		return nil, fmt.Errorf("serve actual store: %v", err)
+	}
+	defer stopActual()
+
+	c := &comparison{expected: expectedClient, actual: actualClient}
+	if err := c.run(ctx); err != nil {
+		return nil, err
+	}
+	return c.diffs, nil
+}
+
+func serveInProcess(store registry.GRPCQuery) (api.RegistryClient, func(), error) {
+	lis := bufconn.Listen(inProcessBufferSize)
+	s := grpc.NewServer()
+	api.RegisterRegistryServer(s, NewRegistryServer(store))
+	go func() {
+		_ = s.Serve(lis)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		s.Stop()
+		return nil, nil, err
+	}
+	return api.NewRegistryClient(conn), func() {
+		_ = conn.Close()
+		s.Stop()
+	}, nil
+}
+
+type comparison struct {
+	expected api.RegistryClient
+	actual   api.RegistryClient
+	diffs    []string
+}
+
+func (c *comparison) run(ctx context.Context) error {
+	expectedPkgs, expectedErr := recvAll(c.expected.ListPackages(ctx, &api.ListPackageRequest{}))
+	actualPkgs, actualErr := recvAll(c.actual.ListPackages(ctx, &api.ListPackageRequest{}))
+	sortPackageNames(expectedPkgs)
+	sortPackageNames(actualPkgs)
+	c.check("ListPackages", expectedPkgs, actualPkgs, expectedErr, actualErr)
+
+	pkgNames := sets.New[string]()
+	for _, p := range append(expectedPkgs, actualPkgs...) {
+		pkgNames.Insert(p.GetName())
+	}
+
+	type channelKey struct{ pkg, channel string }
+	channels := sets.New[channelKey]()
+	for _, pkgName := range sets.List(pkgNames) {
+		req := &api.GetPackageRequest{Name: pkgName}
+		expectedPkg, expectedErr := c.expected.GetPackage(ctx, req)
+		actualPkg, actualErr := c.actual.GetPackage(ctx, req)
+		c.check(fmt.Sprintf("GetPackage(%q)", pkgName), expectedPkg, actualPkg, expectedErr, actualErr)
+
+		graphReq := &api.GetUpgradeGraphRequest{PkgName: pkgName}
+		expectedGraph, expectedErr := c.expected.GetUpgradeGraph(ctx, graphReq)
+		actualGraph, actualErr := c.actual.GetUpgradeGraph(ctx, graphReq)
+		c.check(fmt.Sprintf("GetUpgradeGraph(%q)", pkgName), expectedGraph, actualGraph, expectedErr, actualErr)
+		for _, p := range []*api.Package{expectedPkg, actualPkg} {
+			for _, ch := range p.GetChannels() {
+				channels.Insert(channelKey{pkgName, ch.GetName()})
+			}
+		}
+	}
+
+	for _, ch := range sortedKeys(channels.UnsortedList(), func(k channelKey) string { return k.pkg + "/" + k.channel }) {
+		req := &api.GetBundleInChannelRequest{PkgName: ch.pkg, ChannelName: ch.channel}
+		// nolint:staticcheck
+		expectedBundle, expectedErr := c.expected.GetBundleForChannel(ctx, req)
+		// nolint:staticcheck
+		actualBundle, actualErr := c.actual.GetBundleForChannel(ctx, req)
+		c.check(fmt.Sprintf("GetBundleForChannel(%q, %q)", ch.pkg, ch.channel), expectedBundle, actualBundle, expectedErr, actualErr)
+	}
+
+	expectedBundles, expectedErr := recvAll(c.expected.ListBundles(ctx, &api.ListBundlesRequest{}))
+	actualBundles, actualErr := recvAll(c.actual.ListBundles(ctx, &api.ListBundlesRequest{}))
+	sortBundles(expectedBundles)
+	sortBundles(actualBundles)
+	c.check("ListBundles", expectedBundles, actualBundles, expectedErr, actualErr)
+
+	type bundleKey struct{ pkg, channel, name string }
+	bundles := sets.New[bundleKey]()
+	csvNames := sets.New[string]()
+	gvks := map[string]*api.GroupVersionKind{}
+	for _, b := range append(expectedBundles, actualBundles...) {
+		bundles.Insert(bundleKey{b.GetPackageName(), b.GetChannelName(), b.GetCsvName()})
+		csvNames.Insert(b.GetCsvName())
+		for _, gvk := range b.GetProvidedApis() {
+			gvks[gvkString(gvk)] = gvk
+		}
+	}
+
+	for _, b := range sortedKeys(bundles.UnsortedList(), func(k bundleKey) string { return k.pkg + "/" + k.channel + "/" + k.name }) {
+		getReq := &api.GetBundleRequest{PkgName: b.pkg, ChannelName: b.channel, CsvName: b.name}
+		expectedBundle, expectedErr := c.expected.GetBundle(ctx, getReq)
+		actualBundle, actualErr := c.actual.GetBundle(ctx, getReq)
+		c.check(fmt.Sprintf("GetBundle(%q, %q, %q)", b.pkg, b.channel, b.name), expectedBundle, actualBundle, expectedErr, actualErr)
+
+		replaceReq := &api.GetReplacementRequest{CsvName: b.name, PkgName: b.pkg, ChannelName: b.channel}
+		expectedBundle, expectedErr = c.expected.GetBundleThatReplaces(ctx, replaceReq)
+		actualBundle, actualErr = c.actual.GetBundleThatReplaces(ctx, replaceReq)
+		c.check(fmt.Sprintf("GetBundleThatReplaces(%q, %q, %q)", b.name, b.pkg, b.channel), expectedBundle, actualBundle, expectedErr, actualErr)
+	}
+
+	for _, csvName := range sets.List(csvNames) {
+		req := &api.GetAllReplacementsRequest{CsvName: csvName}
+		expectedEntries, expectedErr := recvAll(c.expected.GetChannelEntriesThatReplace(ctx, req))
+		actualEntries, actualErr := recvAll(c.actual.GetChannelEntriesThatReplace(ctx, req))
+		sortChannelEntries(expectedEntries)
+		sortChannelEntries(actualEntries)
+		c.check(fmt.Sprintf("GetChannelEntriesThatReplace(%q)", csvName), expectedEntries, actualEntries, expectedErr, actualErr)
+	}
+
+	gvkNames := make([]string, 0, len(gvks))
+	for name := range gvks {
+		gvkNames = append(gvkNames, name)
+	}
+	sort.Strings(gvkNames)
+	for _, name := range gvkNames {
+		gvk := gvks[name]
+
+		allReq := &api.GetAllProvidersRequest{Group: gvk.GetGroup(), Version: gvk.GetVersion(), Kind: gvk.GetKind(), Plural: gvk.GetPlural()}
+		expectedEntries, expectedErr := recvAll(c.expected.GetChannelEntriesThatProvide(ctx, allReq))
+		actualEntries, actualErr := recvAll(c.actual.GetChannelEntriesThatProvide(ctx, allReq))
+		sortChannelEntries(expectedEntries)
+		sortChannelEntries(actualEntries)
+		c.check(fmt.Sprintf("GetChannelEntriesThatProvide(%s)", name), expectedEntries, actualEntries, expectedErr, actualErr)
+
+		latestReq := &api.GetLatestProvidersRequest{Group: gvk.GetGroup(), Version: gvk.GetVersion(), Kind: gvk.GetKind(), Plural: gvk.GetPlural()}
+		expectedEntries, expectedErr = recvAll(c.expected.GetLatestChannelEntriesThatProvide(ctx, latestReq))
+		actualEntries, actualErr = recvAll(c.actual.GetLatestChannelEntriesThatProvide(ctx, latestReq))
+		sortChannelEntries(expectedEntries)
+		sortChannelEntries(actualEntries)
+		c.check(fmt.Sprintf("GetLatestChannelEntriesThatProvide(%s)", name), expectedEntries, actualEntries, expectedErr, actualErr)
+
+		defaultReq := &api.GetDefaultProviderRequest{Group: gvk.GetGroup(), Version: gvk.GetVersion(), Kind: gvk.GetKind(), Plural: gvk.GetPlural()}
+		expectedBundle, expectedErr := c.expected.GetDefaultBundleThatProvides(ctx, defaultReq)
+		actualBundle, actualErr := c.actual.GetDefaultBundleThatProvides(ctx, defaultReq)
+		c.check(fmt.Sprintf("GetDefaultBundleThatProvides(%s)", name), expectedBundle, actualBundle, expectedErr, actualErr)
+	}
+
+	c.checkCatalogInfo(ctx)
+
+	return ctx.Err()
+}
+
+// checkCatalogInfo compares the catalog metadata reported by GetCatalogInfo.
+// The digest is not compared: it depends on how each store represents the
+// catalog, and differences in content are already reported by the other RPCs.
+// Stores that do not implement GetCatalogInfo, like the sqlite store, are
+// skipped.
+func (c *comparison) checkCatalogInfo(ctx context.Context) {
+	expectedInfo, expectedErr := c.expected.GetCatalogInfo(ctx, &api.GetCatalogInfoRequest{})
+	actualInfo, actualErr := c.actual.GetCatalogInfo(ctx, &api.GetCatalogInfoRequest{})
+	if status.Code(expectedErr) == codes.Unimplemented || status.Code(actualErr) == codes.Unimplemented {
+		return
+	}
+	for _, info := range []*api.CatalogInfo{expectedInfo, actualInfo} {
+		if info != nil {
+			info.Digest = ""
+		}
+	}
+	c.check("GetCatalogInfo", expectedInfo, actualInfo, expectedErr, actualErr)
+}
+
+func (c *comparison) check(call string, expected, actual any, expectedErr, actualErr error) {
+	if expectedErr != nil || actualErr != nil {
+		expectedStatus, actualStatus := status.Convert(expectedErr), status.Convert(actualErr)
+		if expectedStatus.Code() != actualStatus.Code() || expectedStatus.Message() != actualStatus.Message() {
+			c.diffs = append(c.diffs, fmt.Sprintf("%s: expected error %q, got %q", call, errString(expectedErr), errString(actualErr)))
+		}
+		return
+	}
+	if diff := cmp.Diff(expected, actual, protocmp.Transform()); diff != "" {
+		c.diffs = append(c.diffs, fmt.Sprintf("%s: responses differ (-expected +actual):\n%s", call, diff))
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return status.Convert(err).Message()
+}
+
+func recvAll[T any](stream interface{ Recv() (T, error) }, err error) ([]T, error) {
+	if err != nil {
+		return nil, err
+	}
+	var out []T
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, msg)
+	}
+}
+
+func gvkString(gvk *api.GroupVersionKind) string {
+	return fmt.Sprintf("%s/%s, Kind=%s", gvk.GetGroup(), gvk.GetVersion(), gvk.GetKind())
+}
+
+func sortedKeys[K any](keys []K, keyFunc func(K) string) []K {
+	sort.Slice(keys, func(i, j int) bool { return keyFunc(keys[i]) < keyFunc(keys[j]) })
+	return keys
+}
+
+func sortPackageNames(pkgs []*api.PackageName) {
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].GetName() < pkgs[j].GetName() })
+}
+
+func sortBundles(bundles []*api.Bundle) {
+	key := func(b *api.Bundle) string {
+		return b.GetPackageName() + "/" + b.GetChannelName() + "/" + b.GetCsvName()
+	}
+	sort.Slice(bundles, func(i, j int) bool { return key(bundles[i]) < key(bundles[j]) })
+}
+
+func sortChannelEntries(entries []*api.ChannelEntry) {
+	key := func(e *api.ChannelEntry) string {
+		return e.GetPackageName() + "/" + e.GetChannelName() + "/" + e.GetBundleName() + "/" + e.GetReplaces()
+	}
+	sort.Slice(entries, func(i, j int) bool { return key(entries[i]) < key(entries[j]) })
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	expected, err := fbcCacheFromFs(validFS, t.TempDir())
+	require.NoError(t, err)
+	defer expected.Close()
+
+	t.Run("Identical", func(t *testing.T) {
+		actual, err := fbcCacheFromFs(validFS, t.TempDir())
+		require.NoError(t, err)
+		defer actual.Close()
+
+		diffs, err := Compare(context.Background(), expected, actual)
+		require.NoError(t, err)
+		require.Empty(t, diffs)
+	})
+
+	t.Run("MissingDeprecations", func(t *testing.T) {
+		actual, err := fbcCacheFromFs(fstest.MapFS{"cockroachdb.json": cockroachdb}, t.TempDir())
+		require.NoError(t, err)
+		defer actual.Close()
+
+		diffs, err := Compare(context.Background(), expected, actual)
+		require.NoError(t, err)
+		require.NotEmpty(t, diffs)
+		require.Contains(t, diffs[0], `GetPackage("cockroachdb"): responses differ`)
+	})
+
+	t.Run("MissingPackage", func(t *testing.T) {
+		actual, err := fbcCacheFromFs(fstest.MapFS{}, t.TempDir())
+		require.NoError(t, err)
+		defer actual.Close()
+
+		diffs, err := Compare(context.Background(), expected, actual)
+		require.NoError(t, err)
+		require.Contains(t, diffs, `GetPackage("cockroachdb"): expected error "<nil>", got "package \"cockroachdb\" not found"`)
+	})
+
+	t.Run("MissingCatalogMetadata", func(t *testing.T) {
+		actual, err := fbcCacheFromFs(fstest.MapFS{"cockroachdb.json": cockroachdb, "deprecations.yaml": deprecations, "icons.yaml": icons}, t.TempDir())
+		require.NoError(t, err)
+		defer actual.Close()
+
+		diffs, err := Compare(context.Background(), expected, actual)
+		require.NoError(t, err)
+		require.Len(t, diffs, 1)
+		require.Contains(t, diffs[0], "GetCatalogInfo: responses differ")
+	})
+}
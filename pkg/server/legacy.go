@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"google.golang.org/grpc"
+)
+
+// LegacyCallsMetric counts the calls served from deprecated sqlite databases,
+// by method.
+const LegacyCallsMetric = "opm.registry.sqlite.calls"
+
+var meter = otel.Meter("github.com/operator-framework/operator-registry/pkg/server")
+
+// LegacyUsage records the calls of a server that serves a deprecated sqlite
+// database, so that its remaining clients can be found before the database is
+// migrated to a file-based catalog. Each call is counted in the
+// LegacyCallsMetric counter, which is exported if a meter provider is
+// installed, and logged: the first call of each method is logged as a
+// warning, the following ones at debug level.
+type LegacyUsage struct {
+	logger  *logrus.Entry
+	calls   metric.Int64Counter
+	methods sync.Map
+}
+
+func NewLegacyUsage(logger *logrus.Entry) *LegacyUsage {
+	calls, err := meter.Int64Counter(LegacyCallsMetric,
+		metric.WithDescription("Number of calls served from a deprecated sqlite database"),
+		metric.WithUnit("{call}"),
+	)
+	if err != nil {
+		logger.WithError(err).Warn("unable to create legacy usage metric")
+		calls = noop.Int64Counter{}
+	}
+	return &LegacyUsage{logger: logger, calls: calls}
+}
+
+// UnaryInterceptor and StreamInterceptor record each call of the server.
+func (u *LegacyUsage) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	u.record(ctx, info.FullMethod)
+	return handler(ctx, req)
+}
+
+func (u *LegacyUsage) StreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	u.record(ss.Context(), info.FullMethod)
+	return handler(srv, ss)
+}
+
+func (u *LegacyUsage) record(ctx context.Context, method string) {
+	u.calls.Add(ctx, 1, metric.WithAttributes(attribute.String("rpc.method", method)))
+	logger := u.logger.WithField("method", method)
+	if _, seen := u.methods.LoadOrStore(method, struct{}{}); seen {
+		logger.Debug("served call from deprecated sqlite database")
+		return
+	}
+	logger.Warn("served call from deprecated sqlite database; further calls of this method are logged at debug level")
+}
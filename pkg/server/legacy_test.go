@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/grpc"
+)
+
+func TestLegacyUsage(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	logger, hook := logtest.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+	usage := NewLegacyUsage(logrus.NewEntry(logger))
+
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+	for _, method := range []string{"/api.Registry/GetPackage", "/api.Registry/GetPackage", "/api.Registry/GetBundle"} {
+		resp, err := usage.UnaryInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		require.NoError(t, err)
+		require.Equal(t, "ok", resp)
+	}
+
+	levels := map[string][]logrus.Level{}
+	for _, entry := range hook.AllEntries() {
+		method := entry.Data["method"].(string)
+		levels[method] = append(levels[method], entry.Level)
+	}
+	require.Equal(t, map[string][]logrus.Level{
+		"/api.Registry/GetPackage": {logrus.WarnLevel, logrus.DebugLevel},
+		"/api.Registry/GetBundle":  {logrus.WarnLevel},
+	}, levels)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	require.Equal(t, LegacyCallsMetric, rm.ScopeMetrics[0].Metrics[0].Name)
+	calls := map[string]int64{}
+	for _, dp := range rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64]).DataPoints {
+		method, _ := dp.Attributes.Value(attribute.Key("rpc.method"))
+		calls[method.AsString()] = dp.Value
+	}
+	require.Equal(t, map[string]int64{"/api.Registry/GetPackage": 2, "/api.Registry/GetBundle": 1}, calls)
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/pkg/api"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+// normalizeProperties adds to b the properties that file-based catalogs
+// derive its dependencies from, if it does not have them: an olm.gvk.required
+// property for each olm.gvk dependency, an olm.package.required property for
+// each olm.package dependency and an olm.constraint property for each
+// olm.constraint dependency. sqlite databases only record the dependencies,
+// so bundles served from them otherwise lack these properties.
+func normalizeProperties(b *api.Bundle) error {
+	have := map[string]struct{}{}
+	for _, p := range b.GetProperties() {
+		have[propertyKey(p.GetType(), p.GetValue())] = struct{}{}
+	}
+	for _, d := range b.GetDependencies() {
+		var p property.Property
+		switch d.GetType() {
+		case property.TypeGVK:
+			var v property.GVKRequired
+			if err := json.Unmarshal([]byte(d.GetValue()), &v); err != nil {
+				return fmt.Errorf("parse %s dependency of bundle %q: %v", d.GetType(), b.GetCsvName(), err)
+			}
+			p = property.MustBuildGVKRequired(v.Group, v.Version, v.Kind)
+		case property.TypePackage:
+			var v property.Package
+			if err := json.Unmarshal([]byte(d.GetValue()), &v); err != nil {
+				return fmt.Errorf("parse %s dependency of bundle %q: %v", d.GetType(), b.GetCsvName(), err)
+			}
+			p = property.MustBuildPackageRequired(v.PackageName, v.Version)
+		case property.TypeConstraint:
+			p = property.Property{Type: property.TypeConstraint, Value: json.RawMessage(d.GetValue())}
+		default:
+			continue
+		}
+		key := propertyKey(p.Type, string(p.Value))
+		if _, ok := have[key]; ok {
+			continue
+		}
+		have[key] = struct{}{}
+		b.Properties = append(b.Properties, &api.Property{Type: p.Type, Value: string(p.Value)})
+	}
+	return nil
+}
+
+// propertyKey identifies a property regardless of how its value is
+// formatted, so that the properties that normalizeProperties adds are not
+// duplicates of properties that are formatted differently.
+func propertyKey(typ, value string) string {
+	var v interface{}
+	switch typ {
+	case property.TypeGVKRequired:
+		v = &property.GVKRequired{}
+	case property.TypePackageRequired:
+		v = &property.PackageRequired{}
+	}
+	if v != nil && json.Unmarshal([]byte(value), v) == nil {
+		if data, err := json.Marshal(v); err == nil {
+			return typ + "/" + string(data)
+		}
+	}
+	var buf bytes.Buffer
+	if json.Compact(&buf, []byte(value)) == nil {
+		return typ + "/" + buf.String()
+	}
+	return typ + "/" + value
+}
+
+// excludeProperties removes the properties of the given types from b.
+func excludeProperties(b *api.Bundle, types map[string]struct{}) {
+	props := make([]*api.Property, 0, len(b.Properties))
+	for _, p := range b.Properties {
+		if _, ok := types[p.GetType()]; !ok {
+			props = append(props, p)
+		}
+	}
+	b.Properties = props
+}
+
+// transformingBundleSender transforms the bundles it sends with transform.
+type transformingBundleSender struct {
+	registry.BundleSender
+	transform func(*api.Bundle) error
+}
+
+func (s transformingBundleSender) Send(b *api.Bundle) error {
+	if err := s.transform(b); err != nil {
+		return err
+	}
+	return s.BundleSender.Send(b)
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+)
+
+func TestNormalizeProperties(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		bundle    *api.Bundle
+		expected  []*api.Property
+		expectErr string
+	}{
+		{
+			name: "AddsRequiredProperties",
+			bundle: &api.Bundle{
+				Dependencies: []*api.Dependency{
+					{Type: "olm.gvk", Value: `{"group":"g","kind":"K","version":"v1"}`},
+					{Type: "olm.package", Value: `{"packageName":"p","version":">=1.0.0"}`},
+					{Type: "olm.constraint", Value: `{"failureMessage":"f","cel":{"rule":"true"}}`},
+					{Type: "olm.label", Value: `{"label":"l"}`},
+				},
+				Properties: []*api.Property{{Type: "olm.package", Value: `{"packageName":"a","version":"1.0.0"}`}},
+			},
+			expected: []*api.Property{
+				{Type: "olm.package", Value: `{"packageName":"a","version":"1.0.0"}`},
+				{Type: "olm.gvk.required", Value: `{"group":"g","kind":"K","version":"v1"}`},
+				{Type: "olm.package.required", Value: `{"packageName":"p","versionRange":">=1.0.0"}`},
+				{Type: "olm.constraint", Value: `{"failureMessage":"f","cel":{"rule":"true"}}`},
+			},
+		},
+		{
+			name: "KeepsExistingProperties",
+			bundle: &api.Bundle{
+				Dependencies: []*api.Dependency{
+					{Type: "olm.gvk", Value: `{"group":"g","kind":"K","version":"v1"}`},
+					{Type: "olm.gvk", Value: `{"group":"g","kind":"K","version":"v1"}`},
+					{Type: "olm.constraint", Value: `{"cel":{"rule":"true"}}`},
+				},
+				Properties: []*api.Property{
+					{Type: "olm.gvk.required", Value: `{"version":"v1", "kind":"K", "group":"g"}`},
+					{Type: "olm.constraint", Value: `{ "cel": { "rule": "true" } }`},
+				},
+			},
+			expected: []*api.Property{
+				{Type: "olm.gvk.required", Value: `{"version":"v1", "kind":"K", "group":"g"}`},
+				{Type: "olm.constraint", Value: `{ "cel": { "rule": "true" } }`},
+			},
+		},
+		{
+			name: "InvalidDependency",
+			bundle: &api.Bundle{
+				CsvName:      "a.v1",
+				Dependencies: []*api.Dependency{{Type: "olm.gvk", Value: `{`}},
+			},
+			expectErr: `parse olm.gvk dependency of bundle "a.v1": unexpected end of JSON input`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := normalizeProperties(tt.bundle)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, len(tt.expected), len(tt.bundle.Properties))
+			for i, p := range tt.expected {
+				require.Equal(t, p.Type, tt.bundle.Properties[i].Type)
+				require.Equal(t, p.Value, tt.bundle.Properties[i].Value)
+			}
+		})
+	}
+}
+
+func TestRegistryServerUnknownProperties(t *testing.T) {
+	const experimental = `{"rollout":"canary","replicas":12345678901234567890,"weight":1.50,"note":"<a & b>"}`
+	catalogFS := fstest.MapFS{
+		"catalog.json": &fstest.MapFile{Data: []byte(`{"schema":"olm.package","name":"foo","defaultChannel":"stable"}
+{"schema":"olm.channel","package":"foo","name":"stable","entries":[{"name":"foo.v1.0.0"}]}
+{"schema":"olm.bundle","package":"foo","name":"foo.v1.0.0","image":"foo-bundle:v1.0.0","properties":[
+	{"type":"olm.package","value":{"packageName":"foo","version":"1.0.0"}},
+	{"type":"example.com/experimental","value": { "rollout": "canary", "replicas": 12345678901234567890, "weight": 1.50, "note": "<a & b>" } },
+	{"type":"example.com/internal","value":{"team":"foo"}}
+]}`)},
+	}
+	store, err := fbcCacheFromFs(catalogFS, t.TempDir())
+	require.NoError(t, err)
+	req := &api.GetBundleRequest{PkgName: "foo", ChannelName: "stable", CsvName: "foo.v1.0.0"}
+
+	// Values of unknown property types are served as written, apart from
+	// insignificant whitespace: their key order, numbers and escapes are kept.
+	b, err := NewRegistryServer(store).GetBundle(context.Background(), req)
+	require.NoError(t, err)
+	values := map[string]string{}
+	for _, p := range b.GetProperties() {
+		values[p.GetType()] = p.GetValue()
+	}
+	require.Equal(t, experimental, values["example.com/experimental"])
+
+	b, err = NewRegistryServer(store, WithExcludedPropertyTypes("example.com/internal")).GetBundle(context.Background(), req)
+	require.NoError(t, err)
+	var types []string
+	for _, p := range b.GetProperties() {
+		types = append(types, p.GetType())
+	}
+	require.ElementsMatch(t, []string{"olm.package", "example.com/experimental"}, types)
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	health "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+	"github.com/operator-framework/operator-registry/pkg/cache"
+	"github.com/operator-framework/operator-registry/pkg/lib/log"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+// Options configure the catalog server started by Run.
+type Options struct {
+	// ConfigDir is the directory of the served declarative config.
+	ConfigDir string
+	// ConfigFS, if set, is served instead of ConfigDir, e.g. to serve
+	// several declarative config directories merged with declcfg.MergeFS.
+	ConfigFS fs.FS
+
+	// CacheDir is the directory of the server cache. If unset, the cache is
+	// built in a temporary directory that is removed when Run returns.
+	CacheDir string
+	// CacheOptions configure the server cache, e.g. its format.
+	CacheOptions []cache.CacheOption
+	// CacheEnforceIntegrity fails Run if the cache in CacheDir is missing or
+	// was not built from the served config, instead of rebuilding it.
+	CacheEnforceIntegrity bool
+	// CacheOnly makes Run return once the cache is loaded, without serving.
+	CacheOnly bool
+
+	// Listener is the listener the registry is served on. It is required
+	// unless CacheOnly is set, and is closed when Run returns.
+	Listener net.Listener
+	// TLS, if set, configures the server to serve TLS.
+	TLS *tls.Config
+	// StreamInterceptors and UnaryInterceptors are chained, in order, on the
+	// calls of the server, after an interceptor that traces each call.
+	StreamInterceptors []grpc.StreamServerInterceptor
+	UnaryInterceptors  []grpc.UnaryServerInterceptor
+	// ServerOptions are additional options of the gRPC server.
+	ServerOptions []grpc.ServerOption
+
+	// Authorizer, if set, restricts the packages each client may list and
+	// fetch, as described by NewAuthorizedStore. Client identities are read
+	// from their TLS certificates, which requires TLS to verify them, or from
+	// their bearer tokens.
+	Authorizer PackageAuthorizer
+
+	// OpmVersion is the opm version reported by the GetServerInfo method.
+	OpmVersion string
+	// RegistryServerOptions are additional options of the registry service,
+	// e.g. WithExcludedPropertyTypes.
+	RegistryServerOptions []RegistryServerOption
+
+	// OnReady, if set, is called once the catalog is loaded, before the
+	// server starts serving.
+	OnReady func()
+
+	Log *logrus.Entry
+}
+
+// Run serves the declarative config of opts over gRPC, with the registry,
+// health and reflection services, as "opm serve" does. It builds or loads the
+// server cache, then serves until ctx is done, when the server is stopped
+// gracefully and Run returns nil.
+func Run(ctx context.Context, opts Options) error {
+	logger := opts.Log
+	if logger == nil {
+		logger = log.Null()
+	}
+
+	fbcFsys := opts.ConfigFS
+	if fbcFsys == nil {
+		if opts.ConfigDir == "" {
+			return errors.New("either a config directory or a config filesystem must be set")
+		}
+		fbcFsys = os.DirFS(opts.ConfigDir)
+	}
+	if opts.Listener == nil && !opts.CacheOnly {
+		return errors.New("a listener must be set to serve the catalog")
+	}
+	if opts.CacheDir == "" && opts.CacheEnforceIntegrity {
+		return errors.New("a cache directory must be set to enforce cache integrity")
+	}
+
+	cacheDir := opts.CacheDir
+	if cacheDir == "" {
+		var err error
+		cacheDir, err = os.MkdirTemp("", "opm-serve-cache-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(cacheDir)
+	}
+	logger = logger.WithField("cache", cacheDir)
+
+	store, err := cache.New(cacheDir, append([]cache.CacheOption{cache.WithLog(logger)}, opts.CacheOptions...)...)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if opts.CacheEnforceIntegrity {
+		if err := store.CheckIntegrity(ctx, fbcFsys); err != nil {
+			return fmt.Errorf("integrity check failed: %v", err)
+		}
+		if err := store.Load(ctx); err != nil {
+			return fmt.Errorf("failed to load cache: %v", err)
+		}
+	} else {
+		if err := cache.LoadOrRebuild(ctx, store, fbcFsys); err != nil {
+			return fmt.Errorf("failed to load or rebuild cache: %v", err)
+		}
+	}
+
+	digest, err := store.Digest(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get catalog digest: %v", err)
+	}
+	logger = logger.WithField("digest", digest)
+	logger.Info("loaded catalog")
+
+	if opts.CacheOnly {
+		return nil
+	}
+
+	serverOpts := append([]grpc.ServerOption{
+		grpc.ChainStreamInterceptor(append([]grpc.StreamServerInterceptor{tracingStreamInterceptor}, opts.StreamInterceptors...)...),
+		grpc.ChainUnaryInterceptor(append([]grpc.UnaryServerInterceptor{tracingUnaryInterceptor}, opts.UnaryInterceptors...)...),
+	}, opts.ServerOptions...)
+	if opts.TLS != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(opts.TLS)))
+	}
+	var querier registry.GRPCQuery = store
+	if opts.Authorizer != nil {
+		querier = NewAuthorizedStore(store, opts.Authorizer)
+	}
+	grpcServer := grpc.NewServer(serverOpts...)
+	api.RegisterRegistryServer(grpcServer, NewRegistryServer(querier, append([]RegistryServerOption{WithOpmVersion(opts.OpmVersion)}, opts.RegistryServerOptions...)...))
+	health.RegisterHealthServer(grpcServer, NewHealthServer())
+	reflection.Register(grpcServer)
+
+	logger = logger.WithField("address", opts.Listener.Addr().String())
+	logger.Info("serving registry")
+	if opts.OnReady != nil {
+		opts.OnReady()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		logger.Info("shutting down server")
+		grpcServer.GracefulStop()
+	}()
+	return grpcServer.Serve(opts.Listener)
+}
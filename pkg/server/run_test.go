@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+	"github.com/operator-framework/operator-registry/pkg/registry/registrytest"
+)
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	ready := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- Run(ctx, Options{
+			ConfigFS: registrytest.Catalog(),
+			Listener: lis,
+			OnReady:  func() { close(ready) },
+		})
+	}()
+	select {
+	case <-ready:
+	case err := <-done:
+		t.Fatalf("Run returned before serving: %v", err)
+	}
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	pkg, err := api.NewRegistryClient(conn).GetPackage(ctx, &api.GetPackageRequest{Name: "etcd"})
+	require.NoError(t, err)
+	require.Equal(t, "alpha", pkg.GetDefaultChannelName())
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestRunCacheOnly(t *testing.T) {
+	ctx := context.Background()
+	cacheDir := t.TempDir()
+
+	err := Run(ctx, Options{ConfigFS: registrytest.Catalog(), CacheDir: cacheDir, CacheOnly: true})
+	require.NoError(t, err)
+	err = Run(ctx, Options{ConfigFS: registrytest.Catalog(), CacheDir: cacheDir, CacheOnly: true, CacheEnforceIntegrity: true})
+	require.NoError(t, err)
+	err = Run(ctx, Options{ConfigDir: t.TempDir(), CacheDir: cacheDir, CacheOnly: true, CacheEnforceIntegrity: true})
+	require.ErrorContains(t, err, "integrity check failed")
+}
+
+func TestRunInvalidOptions(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		opts        Options
+		expectedErr string
+	}{
+		{
+			name:        "NoConfig",
+			opts:        Options{CacheOnly: true},
+			expectedErr: "either a config directory or a config filesystem must be set",
+		},
+		{
+			name:        "NoListener",
+			opts:        Options{ConfigDir: "testdata"},
+			expectedErr: "a listener must be set to serve the catalog",
+		},
+		{
+			name:        "EnforceIntegrityWithoutCacheDir",
+			opts:        Options{ConfigDir: "testdata", CacheOnly: true, CacheEnforceIntegrity: true},
+			expectedErr: "a cache directory must be set to enforce cache integrity",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, Run(context.Background(), tt.opts), tt.expectedErr)
+		})
+	}
+}
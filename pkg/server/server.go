@@ -2,24 +2,92 @@ package server
 
 import (
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/operator-framework/operator-registry/alpha/action/migrations"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
 	"github.com/operator-framework/operator-registry/pkg/api"
 	"github.com/operator-framework/operator-registry/pkg/registry"
 )
 
 type RegistryServer struct {
 	api.UnimplementedRegistryServer
-	store registry.GRPCQuery
+	store      registry.GRPCQuery
+	opmVersion string
+	// normalize makes the properties of served bundles independent of the
+	// store they are served from.
+	normalize bool
+	// excludedPropertyTypes are the types of the properties that are
+	// removed from served bundles.
+	excludedPropertyTypes map[string]struct{}
 }
 
 var _ api.RegistryServer = &RegistryServer{}
 
-func NewRegistryServer(store registry.GRPCQuery) *RegistryServer {
-	return &RegistryServer{UnimplementedRegistryServer: api.UnimplementedRegistryServer{}, store: store}
+// RegistryServerOption configures a RegistryServer.
+type RegistryServerOption func(*RegistryServer)
+
+// WithOpmVersion sets the opm version reported by GetServerInfo.
+func WithOpmVersion(version string) RegistryServerOption {
+	return func(s *RegistryServer) {
+		s.opmVersion = version
+	}
+}
+
+// WithNormalizedProperties makes the server add to the bundles it serves the
+// properties that file-based catalogs derive their dependencies from, e.g.
+// olm.gvk.required, if they do not have them, so that bundles served from
+// sqlite databases have the same properties as bundles served from
+// file-based catalogs. It is not enabled by default, so that the responses of
+// existing sqlite registries do not change.
+func WithNormalizedProperties() RegistryServerOption {
+	return func(s *RegistryServer) {
+		s.normalize = true
+	}
+}
+
+// WithExcludedPropertyTypes makes the server remove the properties of the
+// given types from the bundles it serves, e.g. to keep experimental properties
+// from clients. Properties of other types, including types that opm does not
+// know, are served as they are stored.
+func WithExcludedPropertyTypes(types ...string) RegistryServerOption {
+	return func(s *RegistryServer) {
+		if s.excludedPropertyTypes == nil {
+			s.excludedPropertyTypes = map[string]struct{}{}
+		}
+		for _, t := range types {
+			s.excludedPropertyTypes[t] = struct{}{}
+		}
+	}
+}
+
+func NewRegistryServer(store registry.GRPCQuery, opts ...RegistryServerOption) *RegistryServer {
+	s := &RegistryServer{UnimplementedRegistryServer: api.UnimplementedRegistryServer{}, store: store}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// packageSearcher is implemented by stores that can filter the packages they
+// list.
+type packageSearcher interface {
+	SearchPackages(ctx context.Context, filter registry.PackageFilter) ([]string, error)
 }
 
 func (s *RegistryServer) ListPackages(req *api.ListPackageRequest, stream api.Registry_ListPackagesServer) error {
-	packageNames, err := s.store.ListPackages(stream.Context())
+	var (
+		packageNames []string
+		err          error
+	)
+	if filter := registry.PackageFilterFromAPI(req.GetFilter()); filter.IsEmpty() {
+		packageNames, err = s.store.ListPackages(stream.Context())
+	} else if searcher, ok := s.store.(packageSearcher); ok {
+		packageNames, err = searcher.SearchPackages(stream.Context(), filter)
+	} else {
+		return status.Errorf(codes.Unimplemented, "package filters are not supported by this registry")
+	}
 	if err != nil {
 		return err
 	}
@@ -32,8 +100,45 @@ func (s *RegistryServer) ListPackages(req *api.ListPackageRequest, stream api.Re
 	return nil
 }
 
+// ListPackageSummaries sends the summary of each package, so that clients can
+// list the default channels and channel heads of the catalog in one call.
+func (s *RegistryServer) ListPackageSummaries(req *api.ListPackageSummariesRequest, stream api.Registry_ListPackageSummariesServer) error {
+	packageNames, err := s.store.ListPackages(stream.Context())
+	if err != nil {
+		return err
+	}
+	for _, p := range packageNames {
+		packageManifest, err := s.store.GetPackage(stream.Context(), p)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(registry.PackageManifestToAPIPackageSummary(packageManifest)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// edgeBundleSender is implemented by stores that can send every bundle with
+// the replaces and skips of its channel entry, including bundles that are not
+// in the replaces chain of their channel's head.
+type edgeBundleSender interface {
+	SendBundlesWithEdges(ctx context.Context, stream registry.BundleSender) error
+}
+
 func (s *RegistryServer) ListBundles(req *api.ListBundlesRequest, stream api.Registry_ListBundlesServer) error {
-	return s.store.SendBundles(stream.Context(), stream)
+	var bundles registry.BundleSender = stream
+	if s.transformsBundles() {
+		bundles = transformingBundleSender{bundles, s.transformBundle}
+	}
+	if !req.GetIncludeEdges() {
+		return s.store.SendBundles(stream.Context(), bundles)
+	}
+	sender, ok := s.store.(edgeBundleSender)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "listing bundles with their edges is not supported by this registry")
+	}
+	return sender.SendBundlesWithEdges(stream.Context(), bundles)
 }
 
 func (s *RegistryServer) GetPackage(ctx context.Context, req *api.GetPackageRequest) (*api.Package, error) {
@@ -44,12 +149,21 @@ func (s *RegistryServer) GetPackage(ctx context.Context, req *api.GetPackageRequ
 	return registry.PackageManifestToAPIPackage(packageManifest), nil
 }
 
+// GetBundle returns the bundle named csvName of a channel of a package, or,
+// when csvName is not set, the bundle of the channel that version selects.
 func (s *RegistryServer) GetBundle(ctx context.Context, req *api.GetBundleRequest) (*api.Bundle, error) {
-	return s.store.GetBundle(ctx, req.GetPkgName(), req.GetChannelName(), req.GetCsvName())
+	csvName := req.GetCsvName()
+	if csvName == "" && req.GetVersion() != "" {
+		var err error
+		if csvName, err = s.bundleNameForVersion(ctx, req.GetPkgName(), req.GetChannelName(), req.GetVersion()); err != nil {
+			return nil, err
+		}
+	}
+	return s.served(s.store.GetBundle(ctx, req.GetPkgName(), req.GetChannelName(), csvName))
 }
 
 func (s *RegistryServer) GetBundleForChannel(ctx context.Context, req *api.GetBundleInChannelRequest) (*api.Bundle, error) {
-	return s.store.GetBundleForChannel(ctx, req.GetPkgName(), req.GetChannelName())
+	return s.served(s.store.GetBundleForChannel(ctx, req.GetPkgName(), req.GetChannelName()))
 }
 
 func (s *RegistryServer) GetChannelEntriesThatReplace(req *api.GetAllReplacementsRequest, stream api.Registry_GetChannelEntriesThatReplaceServer) error {
@@ -66,7 +180,7 @@ func (s *RegistryServer) GetChannelEntriesThatReplace(req *api.GetAllReplacement
 }
 
 func (s *RegistryServer) GetBundleThatReplaces(ctx context.Context, req *api.GetReplacementRequest) (*api.Bundle, error) {
-	return s.store.GetBundleThatReplaces(ctx, req.GetCsvName(), req.GetPkgName(), req.GetChannelName())
+	return s.served(s.store.GetBundleThatReplaces(ctx, req.GetCsvName(), req.GetPkgName(), req.GetChannelName()))
 }
 
 func (s *RegistryServer) GetChannelEntriesThatProvide(req *api.GetAllProvidersRequest, stream api.Registry_GetChannelEntriesThatProvideServer) error {
@@ -96,5 +210,120 @@ func (s *RegistryServer) GetLatestChannelEntriesThatProvide(req *api.GetLatestPr
 }
 
 func (s *RegistryServer) GetDefaultBundleThatProvides(ctx context.Context, req *api.GetDefaultProviderRequest) (*api.Bundle, error) {
-	return s.store.GetBundleThatProvides(ctx, req.GetGroup(), req.GetVersion(), req.GetKind())
+	return s.served(s.store.GetBundleThatProvides(ctx, req.GetGroup(), req.GetVersion(), req.GetKind()))
+}
+
+// served transforms a bundle returned by the store as the server's options
+// require before it is served.
+func (s *RegistryServer) served(b *api.Bundle, err error) (*api.Bundle, error) {
+	if err != nil || !s.transformsBundles() || b == nil {
+		return b, err
+	}
+	if err := s.transformBundle(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *RegistryServer) transformsBundles() bool {
+	return s.normalize || len(s.excludedPropertyTypes) > 0
+}
+
+// transformBundle normalizes the properties of b, if the server normalizes
+// properties, and then removes those of excluded types.
+func (s *RegistryServer) transformBundle(b *api.Bundle) error {
+	if s.normalize {
+		if err := normalizeProperties(b); err != nil {
+			return err
+		}
+	}
+	if len(s.excludedPropertyTypes) > 0 {
+		excludeProperties(b, s.excludedPropertyTypes)
+	}
+	return nil
+}
+
+func (s *RegistryServer) GetUpgradeGraph(ctx context.Context, req *api.GetUpgradeGraphRequest) (*api.UpgradeGraph, error) {
+	return s.store.GetUpgradeGraph(ctx, req.GetPkgName())
+}
+
+// catalogDigester is implemented by stores that can report a digest of the
+// catalog content they serve.
+type catalogDigester interface {
+	Digest(ctx context.Context) (string, error)
+}
+
+// catalogMetadataGetter is implemented by stores that can report the olm.catalog
+// metadata of the catalog they serve.
+type catalogMetadataGetter interface {
+	CatalogMetadata(ctx context.Context) (*declcfg.Catalog, error)
+}
+
+func (s *RegistryServer) GetCatalogInfo(ctx context.Context, req *api.GetCatalogInfoRequest) (*api.CatalogInfo, error) {
+	d, ok := s.store.(catalogDigester)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "catalog info is not available from this registry")
+	}
+	digest, err := d.Digest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info := &api.CatalogInfo{Digest: digest}
+
+	if m, ok := s.store.(catalogMetadataGetter); ok {
+		c, err := m.CatalogMetadata(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			info.Name = c.Name
+			info.DisplayName = c.DisplayName
+			info.Publisher = c.Publisher
+			info.BuildTime = c.BuildTime
+			info.SourceRefs = c.SourceRefs
+		}
+	}
+	return info, nil
+}
+
+func (s *RegistryServer) GetServerInfo(_ context.Context, _ *api.GetServerInfoRequest) (*api.ServerInfo, error) {
+	return NewServerInfo(s.opmVersion), nil
+}
+
+// NewServerInfo returns the compatibility info of this build of the registry:
+// the version of the Registry API and its methods, the schemas of the
+// declarative configs it serves, and the migrations it can apply to them.
+func NewServerInfo(opmVersion string) *api.ServerInfo {
+	info := &api.ServerInfo{
+		OpmVersion: opmVersion,
+		ApiVersion: api.Version,
+		Methods:    api.RegistryMethods(),
+		Schemas:    append(declcfg.BuiltinSchemas(), declcfg.RegisteredSchemaExtensions()...),
+	}
+	for _, m := range migrations.Describe() {
+		info.Migrations = append(info.Migrations, string(m.Token))
+	}
+	return info
+}
+
+// GetCatalogStats reports the statistics of the catalog, computed from the
+// packages and bundles of the store, so that they only cover the packages a
+// caller is authorized to list.
+func (s *RegistryServer) GetCatalogStats(ctx context.Context, req *api.GetCatalogStatsRequest) (*api.CatalogStats, error) {
+	stats := registry.NewCatalogStatsBuilder(int(req.GetLargestBundles()))
+	packageNames, err := s.store.ListPackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range packageNames {
+		packageManifest, err := s.store.GetPackage(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		stats.AddPackage(registry.PackageManifestToAPIPackage(packageManifest))
+	}
+	if err := s.store.SendBundles(ctx, stats); err != nil {
+		return nil, err
+	}
+	return stats.Stats(), nil
 }
@@ -8,6 +8,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"testing"
 	"testing/fstest"
@@ -19,13 +20,17 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/operator-framework/operator-registry/alpha/action"
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
 	"github.com/operator-framework/operator-registry/pkg/api"
 	fbccache "github.com/operator-framework/operator-registry/pkg/cache"
 	"github.com/operator-framework/operator-registry/pkg/registry"
+	"github.com/operator-framework/operator-registry/pkg/registry/registrytest"
 	"github.com/operator-framework/operator-registry/pkg/sqlite"
 )
 
@@ -39,6 +44,9 @@ const (
 
 	deprecationCachePort    = ":50054"
 	deprecationCacheAddress = "localhost" + deprecationCachePort
+
+	normalizedDBPort    = ":50055"
+	normalizedDBAddress = "localhost" + normalizedDBPort
 )
 
 func createDBStore(dbPath string) *sqlite.SQLQuerier {
@@ -131,6 +139,8 @@ func TestMain(m *testing.M) {
 	}
 
 	grpcServer := server(dbStore)
+	normalizedDBServer := grpc.NewServer()
+	api.RegisterRegistryServer(normalizedDBServer, NewRegistryServer(dbStore, WithNormalizedProperties()))
 
 	fbcStore, err := fbcCache(fbcDir, filepath.Join(tmpDir, "cache"))
 	if err != nil {
@@ -145,7 +155,7 @@ func TestMain(m *testing.M) {
 	fbcServerDeprecations := server(fbcDeprecationStore)
 
 	var wg sync.WaitGroup
-	wg.Add(3)
+	wg.Add(4)
 	go func() {
 		lis, err := net.Listen("tcp", fmt.Sprintf("localhost%s", dbPort))
 		if err != nil {
@@ -176,6 +186,16 @@ func TestMain(m *testing.M) {
 			logrus.Fatalf("failed to serve fbc cache: %v", err)
 		}
 	}()
+	go func() {
+		lis, err := net.Listen("tcp", normalizedDBAddress)
+		if err != nil {
+			logrus.Fatalf("failed to listen: %v", err)
+		}
+		wg.Done()
+		if err := normalizedDBServer.Serve(lis); err != nil {
+			logrus.Fatalf("failed to serve db: %v", err)
+		}
+	}()
 	wg.Wait()
 	exit := m.Run()
 	os.Exit(exit)
@@ -194,6 +214,12 @@ func client(t *testing.T, address string) (api.RegistryClient, *grpc.ClientConn)
 	return api.NewRegistryClient(conn), conn
 }
 
+func TestConformance(t *testing.T) {
+	store, err := fbcCacheFromFs(registrytest.Catalog(), t.TempDir())
+	require.NoError(t, err)
+	registrytest.Conformance(t, store)
+}
+
 func TestListPackages(t *testing.T) {
 	var (
 		listPackagesExpected    = []string{"etcd", "prometheus", "strimzi-kafka-operator"}
@@ -232,22 +258,64 @@ func testListPackages(addr string, expected []string) func(*testing.T) {
 	}
 }
 
+func TestListPackagesFilter(t *testing.T) {
+	for name, addr := range map[string]string{"Sqlite": dbAddress, "FBCCache": cacheAddress} {
+		t.Run(name, func(t *testing.T) {
+			for _, tt := range []struct {
+				name     string
+				filter   *api.PackageFilter
+				expected []string
+			}{
+				{name: "NameContains", filter: &api.PackageFilter{NameContains: "KAFKA"}, expected: []string{"strimzi-kafka-operator"}},
+				{name: "Keywords", filter: &api.PackageFilter{Keywords: []string{"Monitoring", "tsdb"}}, expected: []string{"prometheus"}},
+				{name: "NameAndKeywords", filter: &api.PackageFilter{NameContains: "etc", Keywords: []string{"monitoring"}}, expected: []string{}},
+				{name: "Categories", filter: &api.PackageFilter{Categories: []string{"Database"}}, expected: []string{}},
+			} {
+				t.Run(tt.name, func(t *testing.T) {
+					c, conn := client(t, addr)
+					defer conn.Close()
+
+					stream, err := c.ListPackages(context.TODO(), &api.ListPackageRequest{Filter: tt.filter})
+					require.NoError(t, err)
+
+					packages := []string{}
+					for {
+						in, err := stream.Recv()
+						if errors.Is(err, io.EOF) {
+							break
+						}
+						require.NoError(t, err)
+						packages = append(packages, in.Name)
+					}
+					require.ElementsMatch(t, tt.expected, packages)
+				})
+			}
+		})
+	}
+}
+
 func TestGetPackage(t *testing.T) {
 	var (
 		getPackageExpected = &api.Package{
 			Name: "etcd",
 			Channels: []*api.Channel{
 				{
-					Name:    "alpha",
-					CsvName: "etcdoperator.v0.9.2",
+					Name:        "alpha",
+					CsvName:     "etcdoperator.v0.9.2",
+					HeadVersion: "0.9.2",
+					BundleCount: 3,
 				},
 				{
-					Name:    "beta",
-					CsvName: "etcdoperator.v0.9.0",
+					Name:        "beta",
+					CsvName:     "etcdoperator.v0.9.0",
+					HeadVersion: "0.9.0",
+					BundleCount: 2,
 				},
 				{
-					Name:    "stable",
-					CsvName: "etcdoperator.v0.9.2",
+					Name:        "stable",
+					CsvName:     "etcdoperator.v0.9.2",
+					HeadVersion: "0.9.2",
+					BundleCount: 3,
 				},
 			},
 			DefaultChannelName: "alpha",
@@ -257,16 +325,20 @@ func TestGetPackage(t *testing.T) {
 			Name: "cockroachdb",
 			Channels: []*api.Channel{
 				{
-					Name:    "stable-5.x",
-					CsvName: "cockroachdb.v5.0.4",
+					Name:        "stable-5.x",
+					CsvName:     "cockroachdb.v5.0.4",
+					HeadVersion: "5.0.4",
+					BundleCount: 2,
 					Deprecation: &api.Deprecation{
 						Message: `channel stable-5.x is no longer supported.  Please switch to channel 'stable-6.x'.
 `,
 					},
 				},
 				{
-					Name:    "stable-v6.x",
-					CsvName: "cockroachdb.v6.0.0",
+					Name:        "stable-v6.x",
+					CsvName:     "cockroachdb.v6.0.0",
+					HeadVersion: "6.0.0",
+					BundleCount: 1,
 				},
 			},
 			DefaultChannelName: "stable-v6.x",
@@ -274,10 +346,26 @@ func TestGetPackage(t *testing.T) {
 				Message: `package cockroachdb is end of life.  Please use 'nouveau-cockroachdb' package for support.
 `,
 			},
+			Icon: &api.Icon{
+				Base64Data: []byte(`<svg viewBox="0 0 100 100"><circle cx="50" cy="50" r="50"/></svg>`),
+				Mediatype:  "image/svg+xml",
+			},
 		}
 	)
 	t.Run("Sqlite", testGetPackage(dbAddress, getPackageExpected))
-	t.Run("FBCCache", testGetPackage(cacheAddress, getPackageExpected))
+	t.Run("FBCCache", func(t *testing.T) {
+		// The sqlite database does not define package icons, but migrating
+		// it to FBC sets the icon of each package from its head bundle.
+		expected := proto.Clone(getPackageExpected).(*api.Package)
+		c, conn := client(t, cacheAddress)
+		defer conn.Close()
+		pkg, err := c.GetPackage(context.TODO(), &api.GetPackageRequest{Name: expected.Name})
+		require.NoError(t, err)
+		require.NotEmpty(t, pkg.GetIcon().GetBase64Data())
+		require.NotEmpty(t, pkg.GetIcon().GetMediatype())
+		expected.Icon = pkg.GetIcon()
+		testGetPackage(cacheAddress, expected)(t)
+	})
 	t.Run("FBCCacheWithDeprecations", testGetPackage(deprecationCacheAddress, getPackageExpectedDep))
 }
 
@@ -293,6 +381,7 @@ func testGetPackage(addr string, expected *api.Package) func(*testing.T) {
 			cmpopts.IgnoreUnexported(api.Package{}),
 			cmpopts.IgnoreUnexported(api.Channel{}),
 			cmpopts.IgnoreUnexported(api.Deprecation{}),
+			cmpopts.IgnoreUnexported(api.Icon{}),
 			cmpopts.SortSlices(func(x, y *api.Channel) bool {
 				return x.Name < y.Name
 			}),
@@ -345,6 +434,43 @@ func testGetBundle(addr string, expected *api.Bundle) func(*testing.T) {
 	}
 }
 
+func TestGetBundleByVersion(t *testing.T) {
+	t.Run("Sqlite", testGetBundleByVersion(dbAddress))
+	t.Run("FBCCache", testGetBundleByVersion(cacheAddress))
+}
+
+func testGetBundleByVersion(addr string) func(*testing.T) {
+	return func(t *testing.T) {
+		c, conn := client(t, addr)
+		defer conn.Close()
+
+		for _, tt := range []struct {
+			name     string
+			channel  string
+			version  string
+			expected string
+			code     codes.Code
+		}{
+			{name: "Exact", channel: "stable", version: "0.9.0", expected: "etcdoperator.v0.9.0"},
+			{name: "Range", channel: "stable", version: ">=0.6.0 <0.9.2", expected: "etcdoperator.v0.9.0"},
+			{name: "RangeHighest", channel: "alpha", version: ">=0.6.0", expected: "etcdoperator.v0.9.2"},
+			{name: "NotInChannel", channel: "beta", version: "0.9.2", code: codes.NotFound},
+			{name: "InvalidRange", channel: "stable", version: "not-a-version", code: codes.InvalidArgument},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				bundle, err := c.GetBundle(context.TODO(), &api.GetBundleRequest{PkgName: "etcd", ChannelName: tt.channel, Version: tt.version})
+				if tt.code != codes.OK {
+					require.Equal(t, tt.code, status.Code(err), err)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, bundle.GetCsvName())
+				require.Equal(t, tt.channel, bundle.GetChannelName())
+			})
+		}
+	}
+}
+
 func TestGetBundleForChannel(t *testing.T) {
 	{
 		b := etcdoperatorV0_9_2("alpha", false, false, includeManifestsAll)
@@ -490,6 +616,86 @@ func testGetBundleThatReplacesSynthetic(addr string, expected *api.Bundle) func(
 	}
 }
 
+// TestGetBundleThatReplacesPolicy checks that both stores select the same
+// replacements, for each replacement policy, from a channel where several
+// bundles replace the same bundle with edges of different types.
+func TestGetBundleThatReplacesPolicy(t *testing.T) {
+	dir := t.TempDir()
+	dbFile := filepath.Join(dir, "replacements.db")
+	db, err := sqlite.Open(dbFile)
+	require.NoError(t, err)
+	load, err := sqlite.NewSQLLiteLoader(db)
+	require.NoError(t, err)
+	require.NoError(t, load.Migrate(context.TODO()))
+	require.NoError(t, sqlite.NewSQLLoaderForDirectory(load, "testdata/replacements").Populate())
+	require.NoError(t, db.Close())
+
+	fbcDir := filepath.Join(dir, "fbc")
+	require.NoError(t, action.Migrate{CatalogRef: dbFile, OutputDir: fbcDir, WriteFunc: declcfg.WriteJSON, FileExt: ".json"}.Run(context.TODO()))
+
+	// An empty expected replacement means that none is found.
+	type spec struct {
+		name     string
+		expected string
+	}
+	for _, policy := range []struct {
+		name   string
+		policy registry.ReplacementPolicy
+		specs  []spec
+	}{
+		{
+			name:   "Default",
+			policy: registry.DefaultReplacementPolicy(),
+			specs: []spec{
+				{name: "replacement.v1.0.0", expected: "replacement.v1.1.0"},
+				{name: "replacement.v1.0.5", expected: "replacement.v1.3.0"},
+				{name: "replacement.v1.1.1", expected: "replacement.v1.3.0"},
+				{name: "replacement.v1.2.0", expected: "replacement.v1.3.0"},
+				{name: "replacement.v1.3.0"},
+			},
+		},
+		{
+			name: "ReplacesOnly",
+			specs: []spec{
+				{name: "replacement.v1.0.0", expected: "replacement.v1.1.0"},
+				{name: "replacement.v1.0.5"},
+				{name: "replacement.v1.1.1"},
+			},
+		},
+		{
+			name:   "SkipRange",
+			policy: registry.ReplacementPolicy{SkipRange: true},
+			specs: []spec{
+				{name: "replacement.v1.0.0", expected: "replacement.v1.1.0"},
+				{name: "replacement.v1.0.5", expected: "replacement.v1.3.0"},
+				// skip ranges only apply to bundles of the channel
+				{name: "replacement.v1.1.1"},
+			},
+		},
+	} {
+		t.Run(policy.name, func(t *testing.T) {
+			dbStore, err := sqlite.NewSQLLiteQuerier(dbFile, sqlite.WithReplacementPolicy(policy.policy))
+			require.NoError(t, err)
+			cacheStore, err := fbccache.New(t.TempDir(), fbccache.WithReplacementPolicy(policy.policy))
+			require.NoError(t, err)
+			require.NoError(t, cacheStore.Build(context.TODO(), os.DirFS(fbcDir)))
+			require.NoError(t, cacheStore.Load(context.TODO()))
+
+			for _, s := range policy.specs {
+				for storeName, store := range map[string]registry.GRPCQuery{"Sqlite": dbStore, "FBCCache": cacheStore} {
+					b, err := store.GetBundleThatReplaces(context.TODO(), s.name, "replacement", "stable")
+					if s.expected == "" {
+						require.Error(t, err, "%s: %s", storeName, s.name)
+						continue
+					}
+					require.NoError(t, err, "%s: %s", storeName, s.name)
+					require.Equal(t, s.expected, b.CsvName, "%s: %s", storeName, s.name)
+				}
+			}
+		})
+	}
+}
+
 func TestGetChannelEntriesThatProvide(t *testing.T) {
 	t.Run("Sqlite", testGetChannelEntriesThatProvide(dbAddress))
 	t.Run("FBCCache", testGetChannelEntriesThatProvide(cacheAddress))
@@ -699,10 +905,187 @@ func testGetDefaultBundleThatProvides(addr string, expected *api.Bundle) func(*t
 	}
 }
 
+func TestListPackageSummaries(t *testing.T) {
+	t.Run("Sqlite", testListPackageSummaries(dbAddress, map[string]bool{"etcd": false, "prometheus": false, "strimzi-kafka-operator": false}))
+	t.Run("FBCCache", testListPackageSummaries(cacheAddress, map[string]bool{"etcd": false, "prometheus": false, "strimzi-kafka-operator": false}))
+	t.Run("FBCCacheWithDeprecations", testListPackageSummaries(deprecationCacheAddress, map[string]bool{"cockroachdb": true}))
+}
+
+// testListPackageSummaries checks that the summaries of the packages served
+// at addr are consistent with their GetPackage responses, and which packages
+// are deprecated.
+func testListPackageSummaries(addr string, expectedDeprecated map[string]bool) func(*testing.T) {
+	return func(t *testing.T) {
+		c, conn := client(t, addr)
+		defer conn.Close()
+
+		stream, err := c.ListPackageSummaries(context.TODO(), &api.ListPackageSummariesRequest{})
+		require.NoError(t, err)
+
+		deprecated := map[string]bool{}
+		for {
+			summary, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			deprecated[summary.GetName()] = summary.GetDeprecated()
+
+			pkg, err := c.GetPackage(context.TODO(), &api.GetPackageRequest{Name: summary.GetName()})
+			require.NoError(t, err)
+			require.Equal(t, pkg.GetDefaultChannelName(), summary.GetDefaultChannelName())
+			require.Equal(t, pkg.GetDeprecation() != nil, summary.GetDeprecated())
+			opts := []cmp.Option{
+				cmpopts.IgnoreUnexported(api.Channel{}),
+				cmpopts.IgnoreUnexported(api.Deprecation{}),
+			}
+			require.True(t, cmp.Equal(pkg.GetChannels(), summary.GetChannels(), opts...), cmp.Diff(pkg.GetChannels(), summary.GetChannels(), opts...))
+		}
+		require.Equal(t, expectedDeprecated, deprecated)
+	}
+}
+
+func TestGetCatalogInfo(t *testing.T) {
+	t.Run("Sqlite", func(t *testing.T) {
+		c, conn := client(t, dbAddress)
+		defer conn.Close()
+
+		_, err := c.GetCatalogInfo(context.TODO(), &api.GetCatalogInfoRequest{})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+	t.Run("FBCCache", func(t *testing.T) {
+		c, conn := client(t, cacheAddress)
+		defer conn.Close()
+
+		info, err := c.GetCatalogInfo(context.TODO(), &api.GetCatalogInfoRequest{})
+		require.NoError(t, err)
+		require.NotEmpty(t, info.GetDigest())
+
+		// a catalog with different content must report a different digest
+		dc, dconn := client(t, deprecationCacheAddress)
+		defer dconn.Close()
+
+		other, err := dc.GetCatalogInfo(context.TODO(), &api.GetCatalogInfoRequest{})
+		require.NoError(t, err)
+		require.NotEmpty(t, other.GetDigest())
+		require.NotEqual(t, info.GetDigest(), other.GetDigest())
+
+		// only the catalog with an olm.catalog blob reports metadata
+		require.Empty(t, info.GetName())
+		require.Equal(t, "community-operators", other.GetName())
+		require.Equal(t, "Community Operators", other.GetDisplayName())
+		require.Equal(t, "Operator Framework", other.GetPublisher())
+		require.Equal(t, "2024-01-02T15:04:05Z", other.GetBuildTime())
+		require.Equal(t, []string{"https://github.com/operator-framework/community-operators"}, other.GetSourceRefs())
+	})
+}
+
+func TestGetCatalogStats(t *testing.T) {
+	largestBundles := []*api.BundleStats{
+		{PackageName: "prometheus", CsvName: "prometheusoperator.0.22.2", ManifestBytes: 213670},
+		{PackageName: "prometheus", CsvName: "prometheusoperator.0.15.0", ManifestBytes: 213593},
+	}
+	t.Run("Sqlite", testGetCatalogStats(dbAddress, &api.CatalogStats{Packages: 3, Channels: 7, Bundles: 10, LargestBundles: largestBundles, Deprecations: &api.DeprecationStats{}}))
+	t.Run("FBCCache", testGetCatalogStats(cacheAddress, &api.CatalogStats{Packages: 3, Channels: 7, Bundles: 10, LargestBundles: largestBundles, Deprecations: &api.DeprecationStats{}}))
+	t.Run("FBCCacheWithDeprecations", testGetCatalogStats(deprecationCacheAddress, &api.CatalogStats{
+		Packages: 1,
+		Channels: 2,
+		Bundles:  3,
+		LargestBundles: []*api.BundleStats{
+			{PackageName: "cockroachdb", CsvName: "cockroachdb.v5.0.3"},
+			{PackageName: "cockroachdb", CsvName: "cockroachdb.v5.0.4"},
+		},
+		Deprecations: &api.DeprecationStats{Packages: 1, Channels: 1, Bundles: 1},
+	}))
+}
+
+// testGetCatalogStats checks the statistics of the catalog served at addr,
+// except for the total size of its manifests, which depends on how the store
+// computes the size of bundles without size metadata.
+func testGetCatalogStats(addr string, expected *api.CatalogStats) func(*testing.T) {
+	return func(t *testing.T) {
+		c, conn := client(t, addr)
+		defer conn.Close()
+
+		stats, err := c.GetCatalogStats(context.TODO(), &api.GetCatalogStatsRequest{LargestBundles: 2})
+		require.NoError(t, err)
+		opts := []cmp.Option{
+			cmpopts.IgnoreUnexported(api.CatalogStats{}, api.BundleStats{}, api.DeprecationStats{}),
+			cmpopts.IgnoreFields(api.CatalogStats{}, "ManifestBytes"),
+		}
+		require.True(t, cmp.Equal(expected, stats, opts...), cmp.Diff(expected, stats, opts...))
+		if len(expected.GetLargestBundles()) > 0 && expected.GetLargestBundles()[0].GetManifestBytes() > 0 {
+			require.Greater(t, stats.GetManifestBytes(), int64(0))
+		}
+	}
+}
+
+func TestGetServerInfo(t *testing.T) {
+	c, conn := client(t, cacheAddress)
+	defer conn.Close()
+
+	info, err := c.GetServerInfo(context.TODO(), &api.GetServerInfoRequest{})
+	require.NoError(t, err)
+	require.Equal(t, api.Version, info.GetApiVersion())
+	require.Contains(t, info.GetMethods(), "GetServerInfo")
+	require.Contains(t, info.GetSchemas(), declcfg.SchemaBundle)
+	require.Equal(t, []string{"none", "bundle-object-to-csv-metadata"}, info.GetMigrations())
+
+	info, err = NewRegistryServer(nil, WithOpmVersion("v1.50.0")).GetServerInfo(context.TODO(), &api.GetServerInfoRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "v1.50.0", info.GetOpmVersion())
+}
+
+func TestGetUpgradeGraph(t *testing.T) {
+	var (
+		expected = &api.UpgradeGraph{
+			PackageName: "etcd",
+			Nodes: []*api.UpgradeGraphNode{
+				{BundleName: "etcdoperator.v0.6.1", Version: "0.6.1", Channels: []string{"alpha", "beta", "stable"}},
+				{BundleName: "etcdoperator.v0.9.0", Version: "0.9.0", Channels: []string{"alpha", "beta", "stable"}},
+				{BundleName: "etcdoperator.v0.9.2", Version: "0.9.2", Channels: []string{"alpha", "stable"}},
+			},
+			Edges: []*api.UpgradeGraphEdge{
+				{ChannelName: "alpha", From: "etcdoperator.v0.6.1", To: "etcdoperator.v0.9.0", Type: registry.UpgradeEdgeReplaces},
+				{ChannelName: "alpha", From: "etcdoperator.v0.9.0", To: "etcdoperator.v0.9.2", Type: registry.UpgradeEdgeReplaces},
+				{ChannelName: "alpha", From: "etcdoperator.v0.9.1", To: "etcdoperator.v0.9.2", Type: registry.UpgradeEdgeSkips},
+				{ChannelName: "beta", From: "etcdoperator.v0.6.1", To: "etcdoperator.v0.9.0", Type: registry.UpgradeEdgeReplaces},
+				{ChannelName: "stable", From: "etcdoperator.v0.6.1", To: "etcdoperator.v0.9.0", Type: registry.UpgradeEdgeReplaces},
+				{ChannelName: "stable", From: "etcdoperator.v0.9.0", To: "etcdoperator.v0.9.2", Type: registry.UpgradeEdgeReplaces},
+				{ChannelName: "stable", From: "etcdoperator.v0.9.1", To: "etcdoperator.v0.9.2", Type: registry.UpgradeEdgeSkips},
+			},
+		}
+	)
+	t.Run("Sqlite", testGetUpgradeGraph(dbAddress, expected))
+	t.Run("FBCCache", testGetUpgradeGraph(cacheAddress, expected))
+}
+
+func testGetUpgradeGraph(addr string, expected *api.UpgradeGraph) func(*testing.T) {
+	return func(t *testing.T) {
+		c, conn := client(t, addr)
+		defer conn.Close()
+
+		graph, err := c.GetUpgradeGraph(context.TODO(), &api.GetUpgradeGraphRequest{PkgName: expected.PackageName})
+		require.NoError(t, err)
+
+		opts := []cmp.Option{
+			cmpopts.IgnoreUnexported(api.UpgradeGraph{}),
+			cmpopts.IgnoreUnexported(api.UpgradeGraphNode{}),
+			cmpopts.IgnoreUnexported(api.UpgradeGraphEdge{}),
+		}
+		require.True(t, cmp.Equal(expected, graph, opts...), cmp.Diff(expected, graph, opts...))
+
+		_, err = c.GetUpgradeGraph(context.TODO(), &api.GetUpgradeGraphRequest{PkgName: "missing"})
+		require.Error(t, err)
+	}
+}
+
 func TestListBundles(t *testing.T) {
+	// The sqlite querier lists bundles with the APIs of their properties,
+	// which have no plurals.
 	t.Run("Sqlite", testListBundles(dbAddress,
-		etcdoperatorV0_9_2("alpha", true, false, includeManifestsNone),
-		etcdoperatorV0_9_2("stable", true, false, includeManifestsNone)))
+		withoutPlurals(etcdoperatorV0_9_2("alpha", true, false, includeManifestsNone)),
+		withoutPlurals(etcdoperatorV0_9_2("stable", true, false, includeManifestsNone))))
 	t.Run("FBCCache", testListBundles(cacheAddress,
 		etcdoperatorV0_9_2("alpha", true, true, includeManifestsNone),
 		etcdoperatorV0_9_2("stable", true, true, includeManifestsNone)))
@@ -790,11 +1173,77 @@ func testListBundles(addr string, etcdAlpha *api.Bundle, etcdStable *api.Bundle)
 	}
 }
 
+func TestListBundlesIncludeEdges(t *testing.T) {
+	// Both stores send every bundle with the edges of its channel entry.
+	type edges struct {
+		Replaces  string
+		Skips     []string
+		SkipRange string
+	}
+	listEdges := func(t *testing.T, addr string) map[string]edges {
+		c, conn := client(t, addr)
+		defer conn.Close()
+
+		stream, err := c.ListBundles(context.TODO(), &api.ListBundlesRequest{IncludeEdges: true})
+		require.NoError(t, err)
+		out := map[string]edges{}
+		for {
+			in, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			out[in.PackageName+"/"+in.ChannelName+"/"+in.CsvName] = edges{in.Replaces, in.Skips, in.SkipRange}
+		}
+		return out
+	}
+
+	sqliteEdges, cacheEdges := listEdges(t, dbAddress), listEdges(t, cacheAddress)
+	require.Equal(t, sqliteEdges, cacheEdges)
+	require.Equal(t, edges{Replaces: "etcdoperator.v0.9.0", Skips: []string{"etcdoperator.v0.9.1"}, SkipRange: "< 0.6.0"}, cacheEdges["etcd/alpha/etcdoperator.v0.9.2"])
+	require.Equal(t, edges{Replaces: "etcdoperator.v0.6.1"}, cacheEdges["etcd/beta/etcdoperator.v0.9.0"])
+}
+
+func TestNormalizedProperties(t *testing.T) {
+	// With normalized properties, bundles served from sqlite have the
+	// properties of the same bundles served from a file-based catalog.
+	listProperties := func(t *testing.T, addr string) map[string][]string {
+		c, conn := client(t, addr)
+		defer conn.Close()
+
+		stream, err := c.ListBundles(context.TODO(), &api.ListBundlesRequest{})
+		require.NoError(t, err)
+		out := map[string][]string{}
+		for {
+			in, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			var props []string
+			for _, p := range in.Properties {
+				props = append(props, p.Type+" "+p.Value)
+			}
+			sort.Strings(props)
+			out[in.PackageName+"/"+in.ChannelName+"/"+in.CsvName] = props
+		}
+		return out
+	}
+	require.Equal(t, listProperties(t, cacheAddress), listProperties(t, normalizedDBAddress))
+	require.NotEqual(t, listProperties(t, cacheAddress), listProperties(t, dbAddress))
+
+	c, conn := client(t, normalizedDBAddress)
+	defer conn.Close()
+	bundle, err := c.GetBundle(context.TODO(), &api.GetBundleRequest{PkgName: "etcd", ChannelName: "alpha", CsvName: "etcdoperator.v0.9.2"})
+	require.NoError(t, err)
+	// sqlite bundles have no size metadata.
+	expected := etcdoperatorV0_9_2("alpha", false, true, includeManifestsAll)
+	expected.Size = nil
+	EqualBundles(t, *expected, *bundle)
+}
+
 func EqualBundles(t *testing.T, expected, actual api.Bundle) {
 	t.Helper()
-	stripPlural(actual.ProvidedApis)
-	stripPlural(actual.RequiredApis)
-
 	require.ElementsMatch(t, expected.ProvidedApis, actual.ProvidedApis, "provided apis don't match: %#v\n%#v", expected.ProvidedApis, actual.ProvidedApis)
 	require.ElementsMatch(t, expected.RequiredApis, actual.RequiredApis, "required apis don't match: %#v\n%#v", expected.RequiredApis, actual.RequiredApis)
 	require.ElementsMatch(t, expected.Dependencies, actual.Dependencies, "dependencies don't match: %#v\n%#v", expected.Dependencies, actual.Dependencies)
@@ -810,15 +1259,17 @@ func EqualBundles(t *testing.T, expected, actual api.Bundle) {
 		cmpopts.IgnoreUnexported(api.GroupVersionKind{}),
 		cmpopts.IgnoreUnexported(api.Property{}),
 		cmpopts.IgnoreUnexported(api.Dependency{}),
+		cmpopts.IgnoreUnexported(api.BundleSize{}),
 	}
 
 	require.Truef(t, cmp.Equal(expected, actual, opts...), cmp.Diff(expected, actual, opts...))
 }
 
-func stripPlural(gvks []*api.GroupVersionKind) {
-	for i := range gvks {
-		gvks[i].Plural = ""
+func withoutPlurals(b *api.Bundle) *api.Bundle {
+	for _, gvk := range append(b.ProvidedApis, b.RequiredApis...) {
+		gvk.Plural = ""
 	}
+	return b
 }
 
 type includeManifests string
@@ -872,12 +1323,12 @@ func etcdoperatorV0_9_2(channel string, addSkipsReplaces, addExtraProperties boo
 			},
 		},
 		ProvidedApis: []*api.GroupVersionKind{
-			{Group: "etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdCluster"},
-			{Group: "etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdBackup"},
-			{Group: "etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdRestore"},
+			{Group: "etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdCluster", Plural: "etcdclusters"},
+			{Group: "etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdBackup", Plural: "etcdbackups"},
+			{Group: "etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdRestore", Plural: "etcdrestores"},
 		},
 		RequiredApis: []*api.GroupVersionKind{
-			{Group: "etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdCluster"},
+			{Group: "etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdCluster", Plural: "etcdclusters"},
 		},
 		Version:   "0.9.2",
 		SkipRange: "< 0.6.0",
@@ -890,6 +1341,7 @@ func etcdoperatorV0_9_2(channel string, addSkipsReplaces, addExtraProperties boo
 		b.Properties = append(b.Properties, []*api.Property{
 			{Type: "olm.gvk.required", Value: `{"group":"etcd.database.coreos.com","kind":"EtcdCluster","version":"v1beta2"}`},
 		}...)
+		b.Size = &api.BundleSize{ManifestBytes: 17096, Objects: 4, Crds: 3}
 	}
 	switch includeManifests {
 	case includeManifestsAll:
@@ -1063,8 +1515,29 @@ entries:
        channel stable-5.x is no longer supported.  Please switch to channel 'stable-6.x'.`),
 	}
 
+	catalogMetadata = &fstest.MapFile{
+		Data: []byte(`---
+schema: olm.catalog
+name: community-operators
+displayName: Community Operators
+publisher: Operator Framework
+buildTime: "2024-01-02T15:04:05Z"
+sourceRefs:
+  - https://github.com/operator-framework/community-operators`),
+	}
+
+	icons = &fstest.MapFile{
+		Data: []byte(`---
+schema: olm.icon
+package: cockroachdb
+base64data: PHN2ZyB2aWV3Qm94PSIwIDAgMTAwIDEwMCI+PGNpcmNsZSBjeD0iNTAiIGN5PSI1MCIgcj0iNTAiLz48L3N2Zz4=
+mediatype: image/svg+xml`),
+	}
+
 	validFS = fstest.MapFS{
+		"catalog.yaml":      catalogMetadata,
 		"cockroachdb.json":  cockroachdb,
 		"deprecations.yaml": deprecations,
+		"icons.yaml":        icons,
 	}
 )
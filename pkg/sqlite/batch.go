@@ -0,0 +1,180 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// BatchLoader is implemented by loaders that can group many operations into a
+// single transaction.
+type BatchLoader interface {
+	// Batch runs fn with the operations of the loader grouped into a single
+	// transaction.
+	Batch(ctx context.Context, fn func() error) error
+}
+
+var _ BatchLoader = &sqlLoader{}
+
+// batchSavepoint is the name of the savepoint that isolates each loader
+// operation within a batch.
+const batchSavepoint = "sqlloader_op"
+
+// sqlBatch is the state of a batch in progress.
+type sqlBatch struct {
+	tx *sql.Tx
+	// stmts holds the statements prepared on tx, by query. They are closed
+	// when tx is committed or rolled back.
+	stmts map[string]*sql.Stmt
+}
+
+// loaderTx is the transaction of a single loader operation. Within a batch, it
+// is a savepoint of the batch transaction.
+type loaderTx struct {
+	*sql.Tx
+	savepoint bool
+	done      bool
+}
+
+func (t *loaderTx) Commit() error {
+	if !t.savepoint {
+		return t.Tx.Commit()
+	}
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	_, err := t.Exec("RELEASE " + batchSavepoint)
+	return err
+}
+
+func (t *loaderTx) Rollback() error {
+	if !t.savepoint {
+		return t.Tx.Rollback()
+	}
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	if _, err := t.Exec("ROLLBACK TO " + batchSavepoint); err != nil {
+		return err
+	}
+	_, err := t.Exec("RELEASE " + batchSavepoint)
+	return err
+}
+
+// begin starts the transaction of a loader operation.
+func (s *sqlLoader) begin() (*loaderTx, error) {
+	if s.batch == nil {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return nil, err
+		}
+		return &loaderTx{Tx: tx}, nil
+	}
+	if _, err := s.batch.tx.Exec("SAVEPOINT " + batchSavepoint); err != nil {
+		return nil, err
+	}
+	return &loaderTx{Tx: s.batch.tx, savepoint: true}, nil
+}
+
+// loaderStmt is a prepared statement that may be shared by the operations of a
+// batch, in which case closing it is a no-op.
+type loaderStmt struct {
+	*sql.Stmt
+	shared bool
+}
+
+func (s *loaderStmt) Close() error {
+	if s.shared {
+		return nil
+	}
+	return s.Stmt.Close()
+}
+
+// prepare prepares query on tx. Within a batch, the statement is prepared once
+// and reused by the following operations.
+func (s *sqlLoader) prepare(tx *sql.Tx, query string) (*loaderStmt, error) {
+	if s.batch == nil || s.batch.tx != tx {
+		stmt, err := tx.Prepare(query)
+		if err != nil {
+			return nil, err
+		}
+		return &loaderStmt{Stmt: stmt}, nil
+	}
+	if stmt, ok := s.batch.stmts[query]; ok {
+		return &loaderStmt{Stmt: stmt, shared: true}, nil
+	}
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	s.batch.stmts[query] = stmt
+	return &loaderStmt{Stmt: stmt, shared: true}, nil
+}
+
+// Batch runs fn with the operations of the loader grouped into a single
+// transaction, so that loading many bundles doesn't pay for a commit per
+// operation. While the batch runs, the database uses the WAL journal mode and
+// the statements used to insert bundles are prepared only once. The previous
+// journal mode is restored afterwards, so that the database is left as a single
+// file.
+//
+// Each operation remains atomic: an operation that fails is rolled back without
+// affecting the others. The operations that succeeded are committed when fn
+// returns, even if it returns an error, as they would have been without a
+// batch. Calling Batch from within fn runs fn in the current batch.
+func (s *sqlLoader) Batch(ctx context.Context, fn func() error) (err error) {
+	if s.batch != nil {
+		return fn()
+	}
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var journalMode string
+	var synchronous int
+	if err := conn.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&journalMode); err != nil {
+		return fmt.Errorf("unable to get journal mode: %v", err)
+	}
+	if err := conn.QueryRowContext(ctx, "PRAGMA synchronous").Scan(&synchronous); err != nil {
+		return fmt.Errorf("unable to get synchronous mode: %v", err)
+	}
+	defer func() {
+		// Leaving WAL mode checkpoints the log into the database file and
+		// removes it.
+		if _, rerr := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA journal_mode=%s", journalMode)); rerr != nil && err == nil {
+			err = fmt.Errorf("unable to restore journal mode: %v", rerr)
+		}
+		if _, rerr := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA synchronous=%d", synchronous)); rerr != nil && err == nil {
+			err = fmt.Errorf("unable to restore synchronous mode: %v", rerr)
+		}
+	}()
+	// With WAL, commits only need to sync the log when it is checkpointed,
+	// which is safe with synchronous=NORMAL.
+	if _, err := conn.ExecContext(ctx, "PRAGMA journal_mode=WAL"); err != nil {
+		return fmt.Errorf("unable to set journal mode: %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, "PRAGMA synchronous=NORMAL"); err != nil {
+		return fmt.Errorf("unable to set synchronous mode: %v", err)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	s.batch = &sqlBatch{tx: tx, stmts: map[string]*sql.Stmt{}}
+	defer func() {
+		s.batch = nil
+		_ = tx.Rollback()
+	}()
+
+	fnErr := fn()
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit batch: %v", err)
+	}
+	return fnErr
+}
@@ -0,0 +1,142 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+func TestBatch(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "index.db")
+	db, err := Open(dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+	store, err := NewSQLLiteLoader(db)
+	require.NoError(t, err)
+	require.NoError(t, store.Migrate(context.TODO()))
+
+	fnErr := errors.New("fn failed")
+	err = store.(BatchLoader).Batch(context.TODO(), func() error {
+		var journalMode string
+		require.NoError(t, store.(*sqlLoader).batch.tx.QueryRow("PRAGMA journal_mode").Scan(&journalMode))
+		require.Equal(t, "wal", journalMode)
+
+		require.NoError(t, store.AddOperatorBundle(newBundle(t, "csv-a", "pkg-0", []string{"stable"}, newUnstructuredCSV(t, "csv-a", ""))))
+		// The duplicate bundle is rolled back without affecting the first one.
+		require.Error(t, store.AddOperatorBundle(newBundle(t, "csv-a", "pkg-0", []string{"stable"}, newUnstructuredCSV(t, "csv-a", ""))))
+		require.NoError(t, store.AddOperatorBundle(newBundle(t, "csv-b", "pkg-0", []string{"stable"}, newUnstructuredCSV(t, "csv-b", "csv-a"))))
+		require.Error(t, store.AddPackageChannels(registry.PackageManifest{
+			PackageName:        "pkg-0",
+			Channels:           []registry.PackageChannel{{Name: "stable", CurrentCSVName: "csv-c"}},
+			DefaultChannelName: "stable",
+		}))
+		require.NoError(t, store.AddPackageChannels(registry.PackageManifest{
+			PackageName:        "pkg-0",
+			Channels:           []registry.PackageChannel{{Name: "stable", CurrentCSVName: "csv-b"}},
+			DefaultChannelName: "stable",
+		}))
+		return fnErr
+	})
+	require.ErrorIs(t, err, fnErr)
+	require.NoFileExists(t, dbPath+"-wal")
+
+	var journalMode string
+	require.NoError(t, db.QueryRow("PRAGMA journal_mode").Scan(&journalMode))
+	require.Equal(t, "delete", journalMode)
+
+	querier := NewSQLLiteQuerierFromDb(db)
+	bundles, err := querier.ListBundles(context.TODO())
+	require.NoError(t, err)
+	var names []string
+	for _, b := range bundles {
+		names = append(names, b.CsvName)
+	}
+	require.ElementsMatch(t, []string{"csv-a", "csv-b"}, names)
+}
+
+// BenchmarkAddBundles compares adding bundles one transaction at a time with
+// adding them in a single batch.
+func BenchmarkAddBundles(b *testing.B) {
+	const (
+		numPackages       = 20
+		bundlesPerPackage = 10
+	)
+	type pkgBundles struct {
+		manifest registry.PackageManifest
+		bundles  []*registry.Bundle
+	}
+	var pkgs []pkgBundles
+	for p := 0; p < numPackages; p++ {
+		pkgName := fmt.Sprintf("pkg-%d", p)
+		var bundles []*registry.Bundle
+		replaces := ""
+		for v := 0; v < bundlesPerPackage; v++ {
+			csvName := fmt.Sprintf("%s.v0.%d.0", pkgName, v)
+			bundles = append(bundles, newBundle(b, csvName, pkgName, []string{"stable"}, newUnstructuredCSV(b, csvName, replaces)))
+			replaces = csvName
+		}
+		pkgs = append(pkgs, pkgBundles{
+			manifest: registry.PackageManifest{
+				PackageName:        pkgName,
+				Channels:           []registry.PackageChannel{{Name: "stable", CurrentCSVName: replaces}},
+				DefaultChannelName: "stable",
+			},
+			bundles: bundles,
+		})
+	}
+
+	load := func(store MigratableLoader) error {
+		for _, pkg := range pkgs {
+			for _, bundle := range pkg.bundles {
+				if err := store.AddOperatorBundle(bundle); err != nil {
+					return err
+				}
+			}
+			if err := store.AddPackageChannels(pkg.manifest); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, batched := range []bool{false, true} {
+		b.Run(fmt.Sprintf("batched=%t", batched), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				db, err := Open(filepath.Join(b.TempDir(), "index.db"))
+				if err != nil {
+					b.Fatal(err)
+				}
+				store, err := NewSQLLiteLoader(db)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err := store.Migrate(context.TODO()); err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+
+				if batched {
+					err = store.(BatchLoader).Batch(context.TODO(), func() error { return load(store) })
+				} else {
+					err = load(store)
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				b.StopTimer()
+				if err := db.Close(); err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+			}
+		})
+	}
+}
@@ -126,6 +126,8 @@ func TestQuerierForConfigmap(t *testing.T) {
 			{
 				Name:           "alpha",
 				CurrentCSVName: "etcdoperator.v0.9.2",
+				HeadVersion:    "0.9.2",
+				BundleCount:    3,
 			},
 		},
 	}, etcdPackage)
@@ -1,20 +1,94 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
+type openOptions struct {
+	readOnly       bool
+	integrityCheck bool
+}
+
+// OpenOption configures how Open opens a database.
+type OpenOption func(*openOptions)
+
+// WithReadOnly opens the database immutable, so that it is never written to.
+func WithReadOnly() OpenOption {
+	return func(o *openOptions) {
+		o.readOnly = true
+	}
+}
+
+// WithIntegrityCheck runs `PRAGMA integrity_check` when the database is opened,
+// so that a corrupted database is reported by Open rather than by the first
+// query that reaches the corrupted pages.
+func WithIntegrityCheck() OpenOption {
+	return func(o *openOptions) {
+		o.integrityCheck = true
+	}
+}
+
 // Open opens a connection to a sqlite db. It should be used everywhere instead of sql.Open so that foreign keys are
 // ensured.
-func Open(fileName string) (*sql.DB, error) {
-	return sql.Open("sqlite3", EnableForeignKeys(fileName))
+func Open(fileName string, opts ...OpenOption) (*sql.DB, error) {
+	options := &openOptions{}
+	for _, o := range opts {
+		o(options)
+	}
+
+	dsn := EnableForeignKeys(fileName)
+	if options.readOnly {
+		dsn = EnableImmutable(fileName)
+	}
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if options.integrityCheck {
+		if err := CheckIntegrity(context.TODO(), db); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("database %q: %v", fileName, err)
+		}
+	}
+	return db, nil
 }
 
 // Open opens a connection to a sqlite db. It is
 func OpenReadOnly(fileName string) (*sql.DB, error) {
-	return sql.Open("sqlite3", EnableImmutable(fileName))
+	return Open(fileName, WithReadOnly())
+}
+
+// CheckIntegrity runs `PRAGMA integrity_check` on db and returns an error
+// describing the problems it finds, if any.
+func CheckIntegrity(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return fmt.Errorf("integrity check failed: %v", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return fmt.Errorf("integrity check failed: %v", err)
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("integrity check failed: %v", err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("integrity check failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
 }
 
 // EnableForeignKeys appends the option to enable foreign keys on connections
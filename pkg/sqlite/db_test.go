@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenIntegrityCheck(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "index.db")
+	db, err := Open(dbPath)
+	require.NoError(t, err)
+	store, err := NewSQLLiteLoader(db)
+	require.NoError(t, err)
+	require.NoError(t, store.Migrate(context.TODO()))
+	require.NoError(t, NewSQLLoaderForDirectory(store, "./testdata/loader_data").Populate())
+	require.NoError(t, db.Close())
+
+	db, err = Open(dbPath, WithReadOnly(), WithIntegrityCheck())
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// Overwrite the pages following the schema page
+	f, err := os.OpenFile(dbPath, os.O_RDWR, 0)
+	require.NoError(t, err)
+	garbage := make([]byte, 4096*4)
+	for i := range garbage {
+		garbage[i] = 0xff
+	}
+	_, err = f.WriteAt(garbage, 4096*2)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	_, err = Open(dbPath, WithReadOnly(), WithIntegrityCheck())
+	require.ErrorContains(t, err, dbPath)
+
+	_, err = NewSQLLiteQuerier(dbPath, IntegrityCheck(true))
+	require.Error(t, err)
+
+	db, err = Open(dbPath, WithReadOnly())
+	require.NoError(t, err, "the integrity is not checked by default")
+	require.NoError(t, db.Close())
+}
@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -35,7 +36,16 @@ func NewSQLLoaderForDirectory(store registry.Load, directory string) *DirectoryL
 	}
 }
 
+// Populate loads the bundles and packages of the directory. If the store is a
+// BatchLoader, they are loaded in a single batch.
 func (d *DirectoryLoader) Populate() error {
+	if batcher, ok := d.store.(BatchLoader); ok {
+		return batcher.Batch(context.TODO(), d.populate)
+	}
+	return d.populate()
+}
+
+func (d *DirectoryLoader) populate() error {
 	log := logrus.WithField("dir", d.directory)
 
 	log.Info("loading Bundles")
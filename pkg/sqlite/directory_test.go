@@ -100,14 +100,20 @@ func TestQuerierForDirectory(t *testing.T) {
 			{
 				Name:           "alpha",
 				CurrentCSVName: "etcdoperator.v0.9.2",
+				HeadVersion:    "0.9.2",
+				BundleCount:    3,
 			},
 			{
 				Name:           "beta",
 				CurrentCSVName: "etcdoperator.v0.9.0",
+				HeadVersion:    "0.9.0",
+				BundleCount:    2,
 			},
 			{
 				Name:           "stable",
 				CurrentCSVName: "etcdoperator.v0.9.2",
+				HeadVersion:    "0.9.2",
+				BundleCount:    3,
 			},
 		},
 	}, etcdPackage)
@@ -227,14 +233,20 @@ func TestQuerierForDirectory(t *testing.T) {
 			{
 				Name:           "stable",
 				CurrentCSVName: "strimzi-cluster-operator.v0.11.1",
+				HeadVersion:    "0.11.1",
+				BundleCount:    2,
 			},
 			{
 				Name:           "beta",
 				CurrentCSVName: "strimzi-cluster-operator.v0.12.1",
+				HeadVersion:    "0.12.1",
+				BundleCount:    3,
 			},
 			{
 				Name:           "alpha",
 				CurrentCSVName: "strimzi-cluster-operator.v0.12.2",
+				HeadVersion:    "0.12.2",
+				BundleCount:    4,
 			},
 		},
 	}
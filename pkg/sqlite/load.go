@@ -19,6 +19,9 @@ type sqlLoader struct {
 	db          *sql.DB
 	migrator    Migrator
 	enableAlpha bool
+
+	// batch is set while the operations of the loader are batched.
+	batch *sqlBatch
 }
 
 type MigratableLoader interface {
@@ -64,7 +67,7 @@ func (s *sqlLoader) Migrate(ctx context.Context) error {
 }
 
 func (s *sqlLoader) AddOperatorBundle(bundle *registry.Bundle) error {
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return err
 	}
@@ -72,7 +75,7 @@ func (s *sqlLoader) AddOperatorBundle(bundle *registry.Bundle) error {
 		_ = tx.Rollback()
 	}()
 
-	if err := s.addOperatorBundle(tx, bundle); err != nil {
+	if err := s.addOperatorBundle(tx.Tx, bundle); err != nil {
 		return err
 	}
 
@@ -80,13 +83,13 @@ func (s *sqlLoader) AddOperatorBundle(bundle *registry.Bundle) error {
 }
 
 func (s *sqlLoader) addOperatorBundle(tx *sql.Tx, bundle *registry.Bundle) error {
-	addBundle, err := tx.Prepare("insert into operatorbundle(name, csv, bundle, bundlepath, version, skiprange, replaces, skips, substitutesfor) values(?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	addBundle, err := s.prepare(tx, "insert into operatorbundle(name, csv, bundle, bundlepath, version, skiprange, replaces, skips, substitutesfor) values(?, ?, ?, ?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		return err
 	}
 	defer addBundle.Close()
 
-	addImage, err := tx.Prepare("insert into related_image(image, operatorbundle_name) values(?,?)")
+	addImage, err := s.prepare(tx, "insert into related_image(image, operatorbundle_name) values(?,?)")
 	if err != nil {
 		return fmt.Errorf("failed to insert related image: %s", err)
 	}
@@ -403,7 +406,7 @@ func (s *sqlLoader) appendSkips(tx *sql.Tx, skips []string, csvName string) erro
 }
 
 func (s *sqlLoader) AddPackageChannelsFromGraph(graph *registry.Package) error {
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return fmt.Errorf("unable to start a transaction: %s", err)
 	}
@@ -413,18 +416,18 @@ func (s *sqlLoader) AddPackageChannelsFromGraph(graph *registry.Package) error {
 
 	var errs []error
 
-	if err := addPackageIfNotExists(tx, graph.Name); err != nil {
+	if err := addPackageIfNotExists(tx.Tx, graph.Name); err != nil {
 		errs = append(errs, err)
 	}
 
 	for name, channel := range graph.Channels {
-		if err := addOrUpdateChannel(tx, name, graph.Name, channel.Head.CsvName); err != nil {
+		if err := addOrUpdateChannel(tx.Tx, name, graph.Name, channel.Head.CsvName); err != nil {
 			errs = append(errs, err)
 			continue
 		}
 	}
 
-	if err := updateDefaultChannel(tx, graph.DefaultChannel, graph.Name); err != nil {
+	if err := updateDefaultChannel(tx.Tx, graph.DefaultChannel, graph.Name); err != nil {
 		errs = append(errs, fmt.Errorf("the default channel (%s) does not exist: %s", graph.DefaultChannel, err))
 	}
 
@@ -436,7 +439,7 @@ func (s *sqlLoader) AddPackageChannelsFromGraph(graph *registry.Package) error {
 		var previousNodeID int64
 
 		// first clear the current channel graph
-		err := truncChannelGraph(tx, channelName, graph.Name)
+		err := truncChannelGraph(tx.Tx, channelName, graph.Name)
 		if err != nil {
 			errs = append(errs, err)
 			break
@@ -445,7 +448,7 @@ func (s *sqlLoader) AddPackageChannelsFromGraph(graph *registry.Package) error {
 		// iterate into the replacement chain of the channel to insert or update all entries
 		for {
 			// create real channel entry for node
-			id, err := addChannelEntry(tx, channelName, graph.Name, currentNode.CsvName, depth)
+			id, err := addChannelEntry(tx.Tx, channelName, graph.Name, currentNode.CsvName, depth)
 			if err != nil {
 				errs = append(errs, err)
 				break
@@ -454,7 +457,7 @@ func (s *sqlLoader) AddPackageChannelsFromGraph(graph *registry.Package) error {
 			// If the previous node was created, use the entryId of the current node to update
 			// the replaces for the previous node
 			if previousNodeID != 0 {
-				err := addReplaces(tx, id, previousNodeID)
+				err := addReplaces(tx.Tx, id, previousNodeID)
 				if err != nil {
 					errs = append(errs, err)
 				}
@@ -481,19 +484,19 @@ func (s *sqlLoader) AddPackageChannelsFromGraph(graph *registry.Package) error {
 			// also create channel entry to replace that node
 			syntheticDepth := depth + 1
 			for _, synthetic := range syntheticReplaces {
-				syntheticReplacesID, err := addChannelEntry(tx, channelName, graph.Name, synthetic.CsvName, syntheticDepth)
+				syntheticReplacesID, err := addChannelEntry(tx.Tx, channelName, graph.Name, synthetic.CsvName, syntheticDepth)
 				if err != nil {
 					errs = append(errs, err)
 					break
 				}
 
-				syntheticNodeID, err := addChannelEntry(tx, channelName, graph.Name, currentNode.CsvName, syntheticDepth)
+				syntheticNodeID, err := addChannelEntry(tx.Tx, channelName, graph.Name, currentNode.CsvName, syntheticDepth)
 				if err != nil {
 					errs = append(errs, err)
 					break
 				}
 
-				err = addReplaces(tx, syntheticReplacesID, syntheticNodeID)
+				err = addReplaces(tx.Tx, syntheticReplacesID, syntheticNodeID)
 				if err != nil {
 					errs = append(errs, err)
 				}
@@ -530,7 +533,7 @@ func (s *sqlLoader) AddPackageChannelsFromGraph(graph *registry.Package) error {
 }
 
 func (s *sqlLoader) AddPackageChannels(manifest registry.PackageManifest) error {
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return fmt.Errorf("unable to start a transaction: %s", err)
 	}
@@ -538,11 +541,11 @@ func (s *sqlLoader) AddPackageChannels(manifest registry.PackageManifest) error
 		_ = tx.Rollback()
 	}()
 
-	if err := s.rmPackage(tx, manifest.PackageName); err != nil {
+	if err := s.rmPackage(tx.Tx, manifest.PackageName); err != nil {
 		return err
 	}
 
-	if err := s.addPackageChannels(tx, manifest); err != nil {
+	if err := s.addPackageChannels(tx.Tx, manifest); err != nil {
 		return err
 	}
 
@@ -750,7 +753,7 @@ func (s *sqlLoader) addPackageChannels(tx *sql.Tx, manifest registry.PackageMani
 }
 
 func (s *sqlLoader) ClearNonHeadBundles() error {
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return err
 	}
@@ -860,19 +863,19 @@ func (s *sqlLoader) addAPIs(tx *sql.Tx, bundle *registry.Bundle) error {
 	if bundle.Name == "" {
 		return fmt.Errorf("cannot add apis for bundle with no name: %#v", bundle)
 	}
-	addAPI, err := tx.Prepare("insert or ignore into api(group_name, version, kind, plural) values(?, ?, ?, ?)")
+	addAPI, err := s.prepare(tx, "insert or ignore into api(group_name, version, kind, plural) values(?, ?, ?, ?)")
 	if err != nil {
 		return err
 	}
 	defer addAPI.Close()
 
-	addAPIProvider, err := tx.Prepare("insert into api_provider(group_name, version, kind, operatorbundle_name, operatorbundle_version, operatorbundle_path) values(?, ?, ?, ?, ?, ?)")
+	addAPIProvider, err := s.prepare(tx, "insert into api_provider(group_name, version, kind, operatorbundle_name, operatorbundle_version, operatorbundle_path) values(?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		return err
 	}
 	defer addAPIProvider.Close()
 
-	addAPIRequirer, err := tx.Prepare("insert into api_requirer(group_name, version, kind, operatorbundle_name, operatorbundle_version, operatorbundle_path) values(?, ?, ?, ?, ?, ?)")
+	addAPIRequirer, err := s.prepare(tx, "insert into api_requirer(group_name, version, kind, operatorbundle_name, operatorbundle_version, operatorbundle_path) values(?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		return err
 	}
@@ -954,7 +957,7 @@ func (s *sqlLoader) getCSVNames(tx *sql.Tx, packageName string) ([]string, error
 
 func (s *sqlLoader) RemovePackage(packageName string) error {
 	if err := func() error {
-		tx, err := s.db.Begin()
+		tx, err := s.begin()
 		if err != nil {
 			return err
 		}
@@ -962,7 +965,7 @@ func (s *sqlLoader) RemovePackage(packageName string) error {
 			_ = tx.Rollback()
 		}()
 
-		csvNames, err := s.getCSVNames(tx, packageName)
+		csvNames, err := s.getCSVNames(tx.Tx, packageName)
 		if err != nil {
 			return err
 		}
@@ -970,7 +973,7 @@ func (s *sqlLoader) RemovePackage(packageName string) error {
 			return fmt.Errorf("no package found for packagename %s", packageName)
 		}
 		for _, csvName := range csvNames {
-			if err := s.rmBundle(tx, csvName); err != nil {
+			if err := s.rmBundle(tx.Tx, csvName); err != nil {
 				return err
 			}
 		}
@@ -1063,7 +1066,7 @@ func (s *sqlLoader) AddBundleSemver(graph *registry.Package, bundle *registry.Bu
 }
 
 func (s *sqlLoader) AddBundlePackageChannels(manifest registry.PackageManifest, bundle *registry.Bundle) error {
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return err
 	}
@@ -1071,15 +1074,15 @@ func (s *sqlLoader) AddBundlePackageChannels(manifest registry.PackageManifest,
 		_ = tx.Rollback()
 	}()
 
-	if err := s.addOperatorBundle(tx, bundle); err != nil {
+	if err := s.addOperatorBundle(tx.Tx, bundle); err != nil {
 		return err
 	}
 
-	if err := s.rmPackage(tx, manifest.PackageName); err != nil {
+	if err := s.rmPackage(tx.Tx, manifest.PackageName); err != nil {
 		return err
 	}
 
-	if err := s.addPackageChannels(tx, manifest); err != nil {
+	if err := s.addPackageChannels(tx.Tx, manifest); err != nil {
 		return err
 	}
 
@@ -1122,7 +1125,7 @@ func (s *sqlLoader) rmPackage(tx *sql.Tx, pkg string) error {
 }
 
 func (s *sqlLoader) addDependencies(tx *sql.Tx, bundle *registry.Bundle) error {
-	addDep, err := tx.Prepare("insert into dependencies(type, value, operatorbundle_name, operatorbundle_version, operatorbundle_path) values(?, ?, ?, ?, ?)")
+	addDep, err := s.prepare(tx, "insert into dependencies(type, value, operatorbundle_name, operatorbundle_version, operatorbundle_path) values(?, ?, ?, ?, ?)")
 	if err != nil {
 		return err
 	}
@@ -1167,7 +1170,7 @@ func (s *sqlLoader) addDependencies(tx *sql.Tx, bundle *registry.Bundle) error {
 }
 
 func (s *sqlLoader) addProperty(tx *sql.Tx, propType, value, bundleName, version, path string) error {
-	addProp, err := tx.Prepare("insert into properties(type, value, operatorbundle_name, operatorbundle_version, operatorbundle_path) values(?, ?, ?, ?, ?)")
+	addProp, err := s.prepare(tx, "insert into properties(type, value, operatorbundle_name, operatorbundle_version, operatorbundle_path) values(?, ?, ?, ?, ?)")
 	if err != nil {
 		return err
 	}
@@ -1485,7 +1488,7 @@ func getBundleNameAndVersionForImage(tx *sql.Tx, path string) (string, string, e
 }
 
 func (s *sqlLoader) DeprecateBundle(path string) error {
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return err
 	}
@@ -1493,11 +1496,11 @@ func (s *sqlLoader) DeprecateBundle(path string) error {
 		_ = tx.Rollback()
 	}()
 
-	name, version, err := getBundleNameAndVersionForImage(tx, path)
+	name, version, err := getBundleNameAndVersionForImage(tx.Tx, path)
 	if err != nil {
 		return err
 	}
-	tailBundles, err := getTailFromBundle(tx, name)
+	tailBundles, err := getTailFromBundle(tx.Tx, name)
 	if err != nil {
 		return err
 	}
@@ -1533,7 +1536,7 @@ deprecate:
 		}
 
 		// remove all channel_entries for bundle with same channel as the deprecated one
-		if err := s.rmSharedChannelEntry(tx, bundle, name); err != nil {
+		if err := s.rmSharedChannelEntry(tx.Tx, bundle, name); err != nil {
 			return err
 		}
 
@@ -1556,7 +1559,7 @@ deprecate:
 		}
 
 		// Remove bundle
-		if err := s.rmBundle(tx, bundle); err != nil {
+		if err := s.rmBundle(tx.Tx, bundle); err != nil {
 			return err
 		}
 	}
@@ -1576,7 +1579,7 @@ deprecate:
 	if err != nil {
 		return err
 	}
-	err = s.addProperty(tx, registry.DeprecatedType, string(deprecatedValue), name, version, path)
+	err = s.addProperty(tx.Tx, registry.DeprecatedType, string(deprecatedValue), name, version, path)
 	if err != nil {
 		return err
 	}
@@ -1588,14 +1591,14 @@ deprecate:
 		return err
 	}
 
-	if err := s.rmStrandedDeprecated(tx); err != nil {
+	if err := s.rmStrandedDeprecated(tx.Tx); err != nil {
 		return err
 	}
 	return tx.Commit()
 }
 
 func (s *sqlLoader) RemoveStrandedBundles() error {
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return err
 	}
@@ -1603,11 +1606,11 @@ func (s *sqlLoader) RemoveStrandedBundles() error {
 		_ = tx.Rollback()
 	}()
 
-	if err := s.rmStrandedBundles(tx); err != nil {
+	if err := s.rmStrandedBundles(tx.Tx); err != nil {
 		return err
 	}
 
-	if err := s.rmStrandedDeprecated(tx); err != nil {
+	if err := s.rmStrandedDeprecated(tx.Tx); err != nil {
 		return err
 	}
 	return tx.Commit()
@@ -1745,7 +1748,7 @@ func NewDeprecationAwareLoader(db *sql.DB, opts ...DbOption) (*DeprecationAwareL
 }
 
 func (d *DeprecationAwareLoader) clearLastDeprecatedInPackage(pkg string) error {
-	tx, err := d.db.Begin()
+	tx, err := d.begin()
 	if err != nil {
 		return err
 	}
@@ -1773,7 +1776,7 @@ func (d *DeprecationAwareLoader) RemovePackage(pkg string) error {
 
 // RemoveOverwrittenChannelHead removes a bundle if it is the channel head and has nothing replacing it
 func (s sqlLoader) RemoveOverwrittenChannelHead(pkg, bundle string) error {
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return err
 	}
@@ -1852,7 +1855,7 @@ func (s sqlLoader) RemoveOverwrittenChannelHead(pkg, bundle string) error {
 		}
 	}
 
-	if err := s.rmBundle(tx, bundle); err != nil {
+	if err := s.rmBundle(tx.Tx, bundle); err != nil {
 		return err
 	}
 	// remove from deprecated
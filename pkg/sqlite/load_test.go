@@ -278,7 +278,7 @@ func TestClearNonHeadBundles(t *testing.T) {
 	require.NotEmpty(t, bundle.CsvJson)
 }
 
-func newUnstructuredCSV(t *testing.T, name, replaces string) *unstructured.Unstructured {
+func newUnstructuredCSV(t testing.TB, name, replaces string) *unstructured.Unstructured {
 	csv := &registry.ClusterServiceVersion{}
 	csv.TypeMeta.Kind = "ClusterServiceVersion"
 	csv.SetName(name)
@@ -315,7 +315,7 @@ func newUnstructuredCSVWithVersion(t *testing.T, name, version string) *unstruct
 	return &unstructured.Unstructured{Object: out}
 }
 
-func newBundle(t *testing.T, name, pkgName string, channels []string, objs ...*unstructured.Unstructured) *registry.Bundle {
+func newBundle(t testing.TB, name, pkgName string, channels []string, objs ...*unstructured.Unstructured) *registry.Bundle {
 	bundle := registry.NewBundle(name, &registry.Annotations{
 		PackageName: pkgName,
 		Channels:    strings.Join(channels, ","),
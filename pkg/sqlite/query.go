@@ -7,10 +7,13 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
 	"github.com/operator-framework/operator-registry/pkg/api"
 	"github.com/operator-framework/operator-registry/pkg/registry"
 )
@@ -41,7 +44,9 @@ type SQLQuerier struct {
 var _ registry.Query = &SQLQuerier{}
 
 type querierConfig struct {
-	omitManifests bool
+	omitManifests     bool
+	integrityCheck    bool
+	replacementPolicy *registry.ReplacementPolicy
 }
 
 type SQLiteQuerierOption func(*querierConfig)
@@ -55,8 +60,32 @@ func OmitManifests(b bool) SQLiteQuerierOption {
 	}
 }
 
+// If true, NewSQLLiteQuerier checks the integrity of the database when it
+// opens it, and fails if the database is corrupted.
+func IntegrityCheck(b bool) SQLiteQuerierOption {
+	return func(c *querierConfig) {
+		c.integrityCheck = b
+	}
+}
+
+// WithReplacementPolicy sets the upgrade edges that GetBundleThatReplaces
+// follows. It defaults to registry.DefaultReplacementPolicy.
+func WithReplacementPolicy(p registry.ReplacementPolicy) SQLiteQuerierOption {
+	return func(c *querierConfig) {
+		c.replacementPolicy = &p
+	}
+}
+
 func NewSQLLiteQuerier(dbFilename string, opts ...SQLiteQuerierOption) (*SQLQuerier, error) {
-	db, err := OpenReadOnly(dbFilename)
+	var config querierConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	openOpts := []OpenOption{WithReadOnly()}
+	if config.integrityCheck {
+		openOpts = append(openOpts, WithIntegrityCheck())
+	}
+	db, err := Open(dbFilename, openOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -119,9 +148,48 @@ func (s *SQLQuerier) ListPackages(ctx context.Context) ([]string, error) {
 	return packages, nil
 }
 
+// SearchPackages lists the packages that match filter, by the keywords and
+// categories of the CSV at the head of their default channel.
+func (s *SQLQuerier) SearchPackages(ctx context.Context, filter registry.PackageFilter) ([]string, error) {
+	query := `SELECT DISTINCT package.name, operatorbundle.csv FROM package
+              LEFT OUTER JOIN channel ON channel.package_name=package.name AND channel.name=package.default_channel
+              LEFT OUTER JOIN operatorbundle ON operatorbundle.name=channel.head_operatorbundle_name`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	packages := []string{}
+	for rows.Next() {
+		var pkgName, csvJSON sql.NullString
+		if err := rows.Scan(&pkgName, &csvJSON); err != nil {
+			return nil, err
+		}
+		if !pkgName.Valid {
+			continue
+		}
+		var csv v1alpha1.ClusterServiceVersion
+		if csvJSON.Valid && csvJSON.String != "" {
+			if err := json.Unmarshal([]byte(csvJSON.String), &csv); err != nil {
+				return nil, fmt.Errorf("unable to parse head csv of package %q: %v", pkgName.String, err)
+			}
+		}
+		if filter.Matches(pkgName.String, csv.Spec.Keywords, registry.ParseCategories(csv.GetAnnotations()[registry.CategoriesAnnotation])) {
+			packages = append(packages, pkgName.String)
+		}
+	}
+	return packages, nil
+}
+
 func (s *SQLQuerier) GetPackage(ctx context.Context, name string) (*registry.PackageManifest, error) {
-	query := `SELECT DISTINCT package.name, default_channel, channel.name, channel.head_operatorbundle_name
+	// The channel's bundle count excludes the entries of skipped bundles that aren't in the index.
+	query := `SELECT DISTINCT package.name, default_channel, channel.name, channel.head_operatorbundle_name, operatorbundle.version,
+                (SELECT COUNT(DISTINCT channel_entry.operatorbundle_name)
+                 FROM channel_entry INNER JOIN operatorbundle AS entrybundle ON entrybundle.name=channel_entry.operatorbundle_name
+                 WHERE channel_entry.package_name=package.name AND channel_entry.channel_name=channel.name)
               FROM package INNER JOIN channel ON channel.package_name=package.name
+              LEFT OUTER JOIN operatorbundle ON operatorbundle.name=channel.head_operatorbundle_name
               WHERE package.name=?`
 	rows, err := s.db.QueryContext(ctx, query, name)
 	if err != nil {
@@ -133,10 +201,12 @@ func (s *SQLQuerier) GetPackage(ctx context.Context, name string) (*registry.Pac
 	var defaultChannel sql.NullString
 	var channelName sql.NullString
 	var bundleName sql.NullString
+	var headVersion sql.NullString
+	var bundleCount sql.NullInt64
 	if !rows.Next() {
 		return nil, fmt.Errorf("package %s not found", name)
 	}
-	if err := rows.Scan(&pkgName, &defaultChannel, &channelName, &bundleName); err != nil {
+	if err := rows.Scan(&pkgName, &defaultChannel, &channelName, &bundleName, &headVersion, &bundleCount); err != nil {
 		return nil, err
 	}
 	pkg := &registry.PackageManifest{
@@ -146,15 +216,22 @@ func (s *SQLQuerier) GetPackage(ctx context.Context, name string) (*registry.Pac
 			{
 				Name:           channelName.String,
 				CurrentCSVName: bundleName.String,
+				HeadVersion:    headVersion.String,
+				BundleCount:    int(bundleCount.Int64),
 			},
 		},
 	}
 
 	for rows.Next() {
-		if err := rows.Scan(&pkgName, &defaultChannel, &channelName, &bundleName); err != nil {
+		if err := rows.Scan(&pkgName, &defaultChannel, &channelName, &bundleName, &headVersion, &bundleCount); err != nil {
 			return nil, err
 		}
-		pkg.Channels = append(pkg.Channels, registry.PackageChannel{Name: channelName.String, CurrentCSVName: bundleName.String})
+		pkg.Channels = append(pkg.Channels, registry.PackageChannel{
+			Name:           channelName.String,
+			CurrentCSVName: bundleName.String,
+			HeadVersion:    headVersion.String,
+			BundleCount:    int(bundleCount.Int64),
+		})
 	}
 	return pkg, nil
 }
@@ -351,65 +428,61 @@ func (s *SQLQuerier) GetChannelEntriesThatReplace(ctx context.Context, name stri
 	return entries, nil
 }
 
+// GetBundleThatReplaces returns the bundle of a channel that replaces the
+// bundle named name, as selected by the replacement policy of the querier.
+// The edges of the bundles are read from the channel entries, where skips are
+// synthetic entries that replace the skipped bundles.
 func (s *SQLQuerier) GetBundleThatReplaces(ctx context.Context, name, pkgName, channelName string) (*api.Bundle, error) {
-	query := `SELECT DISTINCT replaces.entry_id, operatorbundle.name, operatorbundle.bundle, operatorbundle.bundlepath, operatorbundle.version, operatorbundle.skiprange
-              FROM channel_entry
-			  LEFT  OUTER JOIN channel_entry replaces ON replaces.replaces = channel_entry.entry_id
-			  INNER JOIN operatorbundle ON replaces.operatorbundle_name = operatorbundle.name
-			  WHERE channel_entry.operatorbundle_name = ? AND channel_entry.package_name = ? AND channel_entry.channel_name = ? LIMIT 1`
-	rows, err := s.db.QueryContext(ctx, query, name, pkgName, channelName)
+	query := `SELECT channel_entry.operatorbundle_name, replaces.operatorbundle_name, operatorbundle.version, operatorbundle.replaces, operatorbundle.skips, operatorbundle.skiprange
+			  FROM channel_entry
+			  INNER JOIN operatorbundle ON channel_entry.operatorbundle_name = operatorbundle.name
+			  LEFT OUTER JOIN channel_entry replaces ON channel_entry.replaces = replaces.entry_id
+			  WHERE channel_entry.package_name = ? AND channel_entry.channel_name = ?`
+	rows, err := s.db.QueryContext(ctx, query, pkgName, channelName)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	if !rows.Next() {
-		return nil, fmt.Errorf("no entry found for %s %s", pkgName, channelName)
-	}
-	var entryID sql.NullInt64
-	var outName sql.NullString
-	var bundle sql.NullString
-	var bundlePath sql.NullString
-	var version sql.NullString
-	var skipRange sql.NullString
-	if err := rows.Scan(&entryID, &outName, &bundle, &bundlePath, &version, &skipRange); err != nil {
-		return nil, err
-	}
-
-	out := &api.Bundle{}
-	if bundle.Valid && bundle.String != "" {
-		out, err = registry.BundleStringToAPIBundle(bundle.String)
-		if err != nil {
+	var candidates []registry.ReplacementCandidate
+	index := map[string]int{}
+	for rows.Next() {
+		var bundleName, replaces, version, declaredReplaces, skips, skipRange sql.NullString
+		if err := rows.Scan(&bundleName, &replaces, &version, &declaredReplaces, &skips, &skipRange); err != nil {
 			return nil, err
 		}
+		i, ok := index[bundleName.String]
+		if !ok {
+			i = len(candidates)
+			index[bundleName.String] = i
+			candidates = append(candidates, registry.ReplacementCandidate{
+				Name:      bundleName.String,
+				Version:   version.String,
+				SkipRange: skipRange.String,
+			})
+		}
+		if !replaces.Valid || replaces.String == "" {
+			continue
+		}
+		// A bundle has an entry for its replaces and for each of its
+		// skips, which are told apart by the edges the bundle declares.
+		c := &candidates[i]
+		if replaces.String != declaredReplaces.String && skips.Valid && slices.Contains(strings.Split(skips.String, ","), replaces.String) {
+			c.Skips = append(c.Skips, replaces.String)
+		} else {
+			c.Replaces = replaces.String
+		}
 	}
-	out.CsvName = outName.String
-	out.PackageName = pkgName
-	out.ChannelName = channelName
-	out.BundlePath = bundlePath.String
-	out.Version = version.String
-	out.SkipRange = skipRange.String
-
-	provided, required, err := s.GetApisForEntry(ctx, entryID.Int64)
-	if err != nil {
-		return nil, err
-	}
-	out.ProvidedApis = provided
-	out.RequiredApis = required
 
-	dependencies, err := s.GetDependenciesForBundle(ctx, outName.String, version.String, bundlePath.String)
-	if err != nil {
-		return nil, err
+	policy := registry.DefaultReplacementPolicy()
+	if s.replacementPolicy != nil {
+		policy = *s.replacementPolicy
 	}
-	out.Dependencies = dependencies
-
-	properties, err := s.GetPropertiesForBundle(ctx, outName.String, version.String, bundlePath.String)
-	if err != nil {
-		return nil, err
+	replacement, ok := policy.BundleThatReplaces(name, candidates)
+	if !ok {
+		return nil, fmt.Errorf("no entry found for %s %s", pkgName, channelName)
 	}
-	out.Properties = properties
-
-	return out, nil
+	return s.GetBundle(ctx, pkgName, channelName, replacement)
 }
 
 func (s *SQLQuerier) GetChannelEntriesThatProvide(ctx context.Context, group, version, kind string) ([]*registry.ChannelEntry, error) {
@@ -997,6 +1070,27 @@ SELECT
       ON operatorbundle.name = merged_properties.bundle_name`
 
 func (s *SQLQuerier) SendBundles(ctx context.Context, stream registry.BundleSender) error {
+	return s.sendBundles(ctx, stream, false)
+}
+
+// SendBundlesWithEdges sends the bundles of the index like SendBundles, but
+// with the replaces and skips that each bundle declares. SendBundles only
+// sends the edges of the channel entries in the replaces chain of the channel
+// heads, so bundles that are only reachable from a head through skips are
+// sent without theirs.
+func (s *SQLQuerier) SendBundlesWithEdges(ctx context.Context, stream registry.BundleSender) error {
+	return s.sendBundles(ctx, stream, true)
+}
+
+func (s *SQLQuerier) sendBundles(ctx context.Context, stream registry.BundleSender, declaredEdges bool) error {
+	var edges map[string]bundleEdges
+	if declaredEdges {
+		var err error
+		if edges, err = s.declaredBundleEdges(ctx); err != nil {
+			return err
+		}
+	}
+
 	rows, err := s.db.QueryContext(ctx, listBundlesQuery, sql.Named("omit_manifests", s.omitManifests))
 	if err != nil {
 		return err
@@ -1044,6 +1138,10 @@ func (s *SQLQuerier) SendBundles(ctx context.Context, stream registry.BundleSend
 		if skips.Valid {
 			out.Skips = strings.Split(skips.String, ",")
 		}
+		if declaredEdges {
+			e := edges[out.CsvName]
+			out.Replaces, out.Skips = e.replaces, e.skips
+		}
 
 		if deps.Valid {
 			if err := json.Unmarshal([]byte(deps.String), &out.Dependencies); err != nil {
@@ -1068,6 +1166,62 @@ func (s *SQLQuerier) SendBundles(ctx context.Context, stream registry.BundleSend
 	return nil
 }
 
+type bundleEdges struct {
+	replaces string
+	skips    []string
+}
+
+// declaredBundleEdges returns the replaces and skips that each bundle of the
+// index declares, by bundle name.
+func (s *SQLQuerier) declaredBundleEdges(ctx context.Context) (map[string]bundleEdges, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT name, replaces, skips FROM operatorbundle`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := map[string]bundleEdges{}
+	for rows.Next() {
+		var name, replaces, skips sql.NullString
+		if err := rows.Scan(&name, &replaces, &skips); err != nil {
+			return nil, err
+		}
+		e := bundleEdges{replaces: replaces.String}
+		if skips.Valid && skips.String != "" {
+			e.skips = strings.Split(skips.String, ",")
+		}
+		edges[name.String] = e
+	}
+	return edges, nil
+}
+
+// GetUpgradeGraph returns the upgrade graph of a package. It lists all of the
+// bundles of the index, since the skips of the channel entries are only
+// aggregated by the query that lists them.
+func (s *SQLQuerier) GetUpgradeGraph(ctx context.Context, pkgName string) (*api.UpgradeGraph, error) {
+	bundles := packageBundleSender{pkgName: pkgName}
+	if err := s.SendBundles(ctx, &bundles); err != nil {
+		return nil, err
+	}
+	if len(bundles.bundles) == 0 {
+		return nil, fmt.Errorf("package %s not found", pkgName)
+	}
+	return registry.NewUpgradeGraph(pkgName, bundles.bundles), nil
+}
+
+// packageBundleSender collects the bundles of a single package.
+type packageBundleSender struct {
+	pkgName string
+	bundles []*api.Bundle
+}
+
+func (s *packageBundleSender) Send(b *api.Bundle) error {
+	if b.PackageName == s.pkgName {
+		s.bundles = append(s.bundles, b)
+	}
+	return nil
+}
+
 type sliceBundleSender []*api.Bundle
 
 func (s *sliceBundleSender) Send(b *api.Bundle) error {
@@ -7,6 +7,9 @@ import (
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/operator-framework/operator-registry/pkg/registry"
 )
 
 func TestListBundlesQuery(t *testing.T) {
@@ -235,3 +238,59 @@ func TestListBundlesQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestSendBundlesWithEdges(t *testing.T) {
+	db, cleanup := CreateTestDB(t)
+	defer cleanup()
+	store, err := NewSQLLiteLoader(db)
+	require.NoError(t, err)
+	require.NoError(t, store.Migrate(context.TODO()))
+
+	// csv-b is only reachable from the channel head through its skips, so
+	// the channel entries do not record that it replaces csv-a.
+	csv := func(name, version, replaces string, skips ...string) *unstructured.Unstructured {
+		c := newUnstructuredCSVWithSkips(t, name, replaces, skips...)
+		spec := c.Object["spec"].(map[string]interface{})
+		spec["version"] = version
+		return c
+	}
+	for _, b := range []*registry.Bundle{
+		newBundle(t, "csv-a", "pkg", []string{"stable"}, csv("csv-a", "1.0.0", "")),
+		newBundle(t, "csv-b", "pkg", []string{"stable"}, csv("csv-b", "1.1.0", "csv-a")),
+		newBundle(t, "csv-c", "pkg", []string{"stable"}, csv("csv-c", "1.2.0", "csv-a", "csv-b")),
+	} {
+		b.BundleImage = "quay.io/pkg/" + b.Name
+		require.NoError(t, store.AddOperatorBundle(b))
+	}
+	require.NoError(t, store.AddPackageChannels(registry.PackageManifest{
+		PackageName:        "pkg",
+		DefaultChannelName: "stable",
+		Channels:           []registry.PackageChannel{{Name: "stable", CurrentCSVName: "csv-c"}},
+	}))
+
+	type edges struct {
+		replaces string
+		skips    []string
+	}
+	listEdges := func(send func(context.Context, registry.BundleSender) error) map[string]edges {
+		var bundles sliceBundleSender
+		require.NoError(t, send(context.TODO(), &bundles))
+		out := map[string]edges{}
+		for _, b := range bundles {
+			out[b.CsvName] = edges{b.Replaces, b.Skips}
+		}
+		return out
+	}
+
+	querier := NewSQLLiteQuerierFromDb(db)
+	require.Equal(t, map[string]edges{
+		"csv-a": {},
+		"csv-b": {},
+		"csv-c": {replaces: "csv-a", skips: []string{"csv-b"}},
+	}, listEdges(querier.SendBundles))
+	require.Equal(t, map[string]edges{
+		"csv-a": {},
+		"csv-b": {replaces: "csv-a"},
+		"csv-c": {replaces: "csv-a", skips: []string{"csv-b"}},
+	}, listEdges(querier.SendBundlesWithEdges))
+}
@@ -368,8 +368,8 @@ var _ = Describe("opm", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			By("pruning a fbc index")
-			err = pruneIndexWith(containerTool, fbcIndexImageTag, indexImage3, fbcPackageName)
-			Expect(err).To(MatchError(indexer.ErrFileBasedCatalogPrune))
+			err = pruneIndexWith(containerTool, fbcIndexImageTag, fbcIndexImageTag+":pruned", fbcPackageName)
+			Expect(err).NotTo(HaveOccurred())
 
 			By("pushing an index")
 			err = pushWith(containerTool, indexImage3)